@@ -2,12 +2,14 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"database/sql"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"sort"
 	"strings"
 	"sync"
 	"syscall"
@@ -16,21 +18,30 @@ import (
 	"github.com/joho/godotenv"
 	"github.com/zlovtnik/gprint/internal/config"
 	"github.com/zlovtnik/gprint/internal/handlers"
+	"github.com/zlovtnik/gprint/internal/mail"
+	"github.com/zlovtnik/gprint/internal/metrics"
+	"github.com/zlovtnik/gprint/internal/middleware"
+	"github.com/zlovtnik/gprint/internal/models"
 	"github.com/zlovtnik/gprint/internal/repository"
 	"github.com/zlovtnik/gprint/internal/router"
+	"github.com/zlovtnik/gprint/internal/scheduler"
 	"github.com/zlovtnik/gprint/internal/service"
+	"github.com/zlovtnik/gprint/internal/storage"
 	"github.com/zlovtnik/gprint/pkg/auth"
+	"github.com/zlovtnik/gprint/pkg/certreload"
+	"github.com/zlovtnik/gprint/pkg/lockout"
+	"github.com/zlovtnik/gprint/pkg/ratelimit"
 )
 
 func main() {
 	// Load .env file if it exists
 	_ = godotenv.Load()
 
-	cfg, logger := loadConfigAndLogger()
+	cfg, logger, logLevel := loadConfigAndLogger()
 
 	db := setupDatabase(cfg, logger)
 
-	repos, err := setupRepositories(db)
+	repos, err := setupRepositories(db, cfg, logger)
 	if err != nil {
 		logger.Error("failed to setup repositories", "error", err)
 		os.Exit(1)
@@ -38,7 +49,9 @@ func main() {
 
 	services := setupServices(repos, cfg, logger)
 
-	handlers := setupHandlers(services, db, cfg)
+	sched := setupScheduler(services, db, cfg, logger)
+
+	handlers := setupHandlers(services, sched, db, cfg)
 
 	r, err := setupRouter(cfg, logger, handlers)
 	if err != nil {
@@ -46,22 +59,55 @@ func main() {
 		os.Exit(1)
 	}
 
-	server := setupServer(cfg, r)
+	server, err := setupServer(cfg, r, logger)
+	if err != nil {
+		logger.Error("failed to setup server", "error", err)
+		os.Exit(1)
+	}
+	metricsServer := setupMetricsServer(cfg)
+
+	live := liveSettings{
+		logLevel:  logLevel,
+		scheduler: sched,
+		queryDB:   repos.queryDB,
+	}
 
-	cancel, bgWg := startBackgroundJobs(services.printSvc, cfg, logger)
+	requeueStalePrintJobs(services.printSvc, logger)
+	cancel, bgWg := startBackgroundJobs(sched)
 
 	serverErrCh := startServer(server, logger)
+	startMetricsServer(metricsServer, logger)
 
-	exitCode := waitForShutdown(server, db, cancel, bgWg, serverErrCh, logger, cfg)
+	exitCode := waitForShutdown(server, metricsServer, db, services.printSvc, cancel, bgWg, serverErrCh, handlers.healthHandler, logger, cfg, live)
 
 	if exitCode != 0 {
 		os.Exit(exitCode)
 	}
 }
 
-func loadConfigAndLogger() (*config.Config, *slog.Logger) {
+func loadConfigAndLogger() (*config.Config, *slog.Logger, *slog.LevelVar) {
 	// Load configuration first so we can use it for logger setup
-	cfg := config.Load()
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load configuration:\n")
+		for _, problem := range strings.Split(err.Error(), "\n") {
+			fmt.Fprintf(os.Stderr, "  - %s\n", problem)
+		}
+		os.Exit(1)
+	}
+
+	// Report every configuration problem at once rather than failing at first use
+	warnings, err := cfg.Validate()
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "WARNING: %s\n", w)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid configuration:\n")
+		for _, problem := range strings.Split(err.Error(), "\n") {
+			fmt.Fprintf(os.Stderr, "  - %s\n", problem)
+		}
+		os.Exit(1)
+	}
 
 	// Parse log level from configuration
 	logLevel, ok := parseLogLevel(cfg.LogLevel)
@@ -69,9 +115,11 @@ func loadConfigAndLogger() (*config.Config, *slog.Logger) {
 		fmt.Fprintf(os.Stderr, "WARNING: unknown log level %q, defaulting to info\n", cfg.LogLevel)
 	}
 
-	// Initialize logger with configurable level
+	// Initialize logger with configurable level; the LevelVar lets SIGHUP change it
+	levelVar := new(slog.LevelVar)
+	levelVar.Set(logLevel)
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: logLevel,
+		Level: levelVar,
 	}))
 	slog.SetDefault(logger)
 
@@ -80,12 +128,22 @@ func loadConfigAndLogger() (*config.Config, *slog.Logger) {
 		"port", cfg.Server.Port,
 	)
 
-	return cfg, logger
+	// Record where each setting came from to help debug precedence surprises
+	keys := make([]string, 0, len(cfg.Sources))
+	for key := range cfg.Sources {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		logger.Debug("config value loaded", "key", key, "source", cfg.Sources[key])
+	}
+
+	return cfg, logger, levelVar
 }
 
 func setupDatabase(cfg *config.Config, logger *slog.Logger) *sql.DB {
 	// Connect to database
-	db, err := config.NewOracleDB(cfg.Database)
+	db, err := config.NewOracleDB(cfg.Database, logger)
 	if err != nil {
 		logger.Error("failed to connect to database", "error", err)
 		os.Exit(1)
@@ -103,6 +161,22 @@ type repositories struct {
 	historyRepo            *repository.HistoryRepository
 	printJobRepo           *repository.PrintJobRepository
 	contractGenerationRepo *repository.ContractGenerationRepository
+	webhookRepo            *repository.WebhookRepository
+	notificationRepo       *repository.NotificationRepository
+	apiClientRepo          *repository.APIClientRepository
+	tenantRepo             *repository.TenantRepository
+	featureFlagRepo        *repository.FeatureFlagRepository
+	invoiceRepo            *repository.InvoiceRepository
+	attachmentRepo         *repository.ContractAttachmentRepository
+	jobLockRepo            *repository.JobLockRepository
+	queryDB                *repository.DB // shared by all repositories
+}
+
+// liveSettings are the handles SIGHUP uses to apply reloaded settings at runtime
+type liveSettings struct {
+	logLevel  *slog.LevelVar
+	scheduler *scheduler.Scheduler // runs the print job loop
+	queryDB   *repository.DB
 }
 
 // services holds all service instances
@@ -112,6 +186,16 @@ type services struct {
 	contractSvc           *service.ContractService
 	printSvc              *service.PrintService
 	contractGenerationSvc *service.ContractGenerationService
+	webhookSvc            *service.WebhookService
+	notificationSvc       *service.NotificationService
+	apiClientSvc          *service.APIClientService
+	tenantSvc             *service.TenantService
+	settingsSvc           *service.TenantSettingsService
+	flagSvc               *service.FlagService
+	searchSvc             *service.SearchService
+	invoiceSvc            *service.InvoiceService
+	attachmentSvc         *service.ContractAttachmentService
+	cleanupSvc            *service.GenerationCleanupService
 }
 
 // handlerSet holds all handler instances
@@ -123,9 +207,27 @@ type handlerSet struct {
 	printHandler              *handlers.PrintHandler
 	healthHandler             *handlers.HealthHandler
 	authHandler               *handlers.AuthHandler
+	webhookHandler            *handlers.WebhookHandler
+	notificationHandler       *handlers.NotificationHandler
+	tenantHandler             *handlers.TenantHandler
+	settingsHandler           *handlers.SettingsHandler
+	featureHandler            *handlers.FeatureHandler
+	searchHandler             *handlers.SearchHandler
+	invoiceHandler            *handlers.InvoiceHandler
+	attachmentHandler         *handlers.ContractAttachmentHandler
+	jobHandler                *handlers.JobHandler
+	metricsHandler            *handlers.MetricsHandler
+	verifier                  *auth.Verifier       // used by the auth middleware
+	features                  *service.FlagService // used to gate routes per tenant
 }
 
-func setupRepositories(db *sql.DB) (repositories, error) {
+func setupRepositories(sqlDB *sql.DB, cfg *config.Config, logger *slog.Logger) (repositories, error) {
+	// Repositories share statement timeout and slow query logging
+	db := repository.NewDB(sqlDB, repository.DBConfig{
+		QueryTimeout:       cfg.Database.QueryTimeout,
+		SlowQueryThreshold: cfg.Database.SlowQueryThreshold,
+	}, logger)
+
 	// Initialize repositories
 	customerRepo, err := repository.NewCustomerRepository(db)
 	if err != nil {
@@ -136,6 +238,27 @@ func setupRepositories(db *sql.DB) (repositories, error) {
 	historyRepo := repository.NewHistoryRepository(db)
 	printJobRepo := repository.NewPrintJobRepository(db)
 	contractGenerationRepo := repository.NewContractGenerationRepository(db)
+	webhookRepo, err := repository.NewWebhookRepository(db)
+	if err != nil {
+		return repositories{}, err
+	}
+	notificationRepo, err := repository.NewNotificationRepository(db)
+	if err != nil {
+		return repositories{}, err
+	}
+
+	apiClientRepo, err := repository.NewAPIClientRepository(db)
+	if err != nil {
+		return repositories{}, err
+	}
+	tenantRepo, err := repository.NewTenantRepository(db)
+	if err != nil {
+		return repositories{}, err
+	}
+	featureFlagRepo, err := repository.NewFeatureFlagRepository(db)
+	if err != nil {
+		return repositories{}, err
+	}
 
 	return repositories{
 		customerRepo:           customerRepo,
@@ -144,20 +267,113 @@ func setupRepositories(db *sql.DB) (repositories, error) {
 		historyRepo:            historyRepo,
 		printJobRepo:           printJobRepo,
 		contractGenerationRepo: contractGenerationRepo,
+		webhookRepo:            webhookRepo,
+		notificationRepo:       notificationRepo,
+		apiClientRepo:          apiClientRepo,
+		tenantRepo:             tenantRepo,
+		featureFlagRepo:        featureFlagRepo,
+		invoiceRepo:            repository.NewInvoiceRepository(db),
+		attachmentRepo:         repository.NewContractAttachmentRepository(db),
+		jobLockRepo:            repository.NewJobLockRepository(db),
+		queryDB:                db,
 	}, nil
 }
 
 func setupServices(repos repositories, cfg *config.Config, logger *slog.Logger) services {
 	// Initialize services
-	customerSvc := service.NewCustomerService(repos.customerRepo)
-	serviceSvc := service.NewServiceService(repos.serviceRepo)
-	contractSvc := service.NewContractService(repos.contractRepo, repos.historyRepo)
-	printSvc, err := service.NewPrintService(repos.printJobRepo, repos.contractRepo, repos.historyRepo, cfg.Print.OutputPath, logger)
+	settingsSvc := service.NewTenantSettingsService(repos.tenantRepo, models.TenantSettings{
+		Currency:              cfg.Tenant.DefaultCurrency,
+		Locale:                cfg.Tenant.DefaultLocale,
+		ContractNumberPattern: cfg.Tenant.ContractNumberPattern,
+		PrintRetentionDays:    cfg.Print.RetentionDays,
+	})
+	customerSvc := service.NewCustomerService(repos.customerRepo, service.CustomerImportConfig{
+		MaxBytes:  int64(cfg.Import.MaxBytes),
+		MaxRows:   cfg.Import.MaxRows,
+		BatchSize: cfg.Import.BatchSize,
+	})
+	serviceSvc := service.NewServiceService(repos.serviceRepo, settingsSvc)
+	flagSvc := service.NewFlagService(repos.featureFlagRepo, cfg.Features.Enabled)
+	webhookSvc := service.NewWebhookService(repos.webhookRepo, service.WebhookServiceConfig{
+		MaxAttempts: cfg.Webhook.MaxAttempts,
+		Timeout:     cfg.Webhook.Timeout,
+		BaseBackoff: cfg.Webhook.RetryBackoff,
+		Flags:       flagSvc,
+	}, logger)
+	contractSvc := service.NewContractService(repos.contractRepo, repos.historyRepo, webhookSvc, settingsSvc)
+	notificationSvc := service.NewNotificationService(repos.notificationRepo)
+	printNotifiers := service.Notifiers{webhookSvc, service.NewInAppNotifier(notificationSvc, logger)}
+	emailNotifier, err := setupEmailNotifier(repos, cfg, logger)
+	if err != nil {
+		logger.Error("failed to configure notification email", "error", err)
+		os.Exit(1)
+	}
+	if emailNotifier != nil {
+		printNotifiers = append(printNotifiers, emailNotifier)
+	}
+	store, err := setupStorage(cfg)
+	if err != nil {
+		logger.Error("failed to configure print storage", "error", err)
+		os.Exit(1)
+	}
+	var signedDownloadTTL time.Duration
+	if cfg.Storage.DownloadMode == "redirect" {
+		signedDownloadTTL = cfg.Storage.SignedURLTTL
+	}
+	renderer := service.HTMLRenderer{}
+	printSvc, err := service.NewPrintService(
+		repos.printJobRepo,
+		repos.contractRepo,
+		repos.historyRepo,
+		repos.contractGenerationRepo,
+		service.PrintServiceConfig{
+			OutputDir:         cfg.Print.OutputPath,
+			Storage:           store,
+			SignedDownloadTTL: signedDownloadTTL,
+			Workers:           cfg.Print.Workers,
+			InstanceID:        cfg.Print.InstanceID,
+			LeaseDuration:     cfg.Print.LeaseTTL,
+			WatermarkAdmins:   cfg.Print.WatermarkAdmins,
+			VerifyBaseURL:     cfg.Print.VerifyBaseURL,
+			VerifyPayload:     cfg.Print.VerifyQRPayload,
+			Notifier:          printNotifiers,
+			Settings:          settingsSvc,
+			Renderer:          renderer,
+			RetryPolicy: service.PrintRetryPolicy{
+				MaxRetries:  cfg.Print.MaxRetries,
+				BaseBackoff: cfg.Print.RetryBackoff,
+			},
+		},
+		logger,
+	)
 	if err != nil {
 		logger.Error("failed to create print service", "error", err)
 		os.Exit(1)
 	}
-	contractGenerationSvc := service.NewContractGenerationService(repos.contractGenerationRepo)
+	if emailNotifier != nil {
+		emailNotifier.SetOutputs(printSvc)
+	}
+	attachmentStore := store
+	if attachmentStore == nil {
+		local, err := storage.NewLocalStorage(cfg.Attachment.Path)
+		if err != nil {
+			logger.Error("failed to configure attachment storage", "error", err)
+			os.Exit(1)
+		}
+		attachmentStore = local
+	}
+	attachmentSvc := service.NewContractAttachmentService(repos.attachmentRepo, repos.contractRepo, repos.historyRepo, service.ContractAttachmentConfig{
+		MaxBytes:     int64(cfg.Attachment.MaxBytes),
+		AllowedTypes: cfg.Attachment.AllowedTypes,
+		Storage:      attachmentStore,
+	})
+	contractGenerationSvc := service.NewContractGenerationService(repos.contractGenerationRepo, repos.contractRepo, repos.customerRepo, settingsSvc, renderer)
+	tenantSvc := service.NewTenantService(repos.tenantRepo, repos.contractGenerationRepo, settingsSvc)
+	cleanupSvc := service.NewGenerationCleanupService(repos.contractGenerationRepo, repos.jobLockRepo, service.GenerationCleanupConfig{
+		Tenants:    cfg.Cleanup.Tenants,
+		InstanceID: cfg.Print.InstanceID,
+		LockTTL:    cfg.Cleanup.LockTTL,
+	})
 
 	return services{
 		customerSvc:           customerSvc,
@@ -165,10 +381,69 @@ func setupServices(repos repositories, cfg *config.Config, logger *slog.Logger)
 		contractSvc:           contractSvc,
 		printSvc:              printSvc,
 		contractGenerationSvc: contractGenerationSvc,
+		webhookSvc:            webhookSvc,
+		notificationSvc:       notificationSvc,
+		apiClientSvc:          service.NewAPIClientService(repos.apiClientRepo),
+		tenantSvc:             tenantSvc,
+		settingsSvc:           settingsSvc,
+		flagSvc:               flagSvc,
+		searchSvc:             service.NewSearchService(repos.customerRepo, repos.contractRepo, repos.serviceRepo),
+		invoiceSvc:            service.NewInvoiceService(repos.invoiceRepo, repos.contractRepo, settingsSvc),
+		attachmentSvc:         attachmentSvc,
+		cleanupSvc:            cleanupSvc,
+	}
+}
+
+// setupEmailNotifier creates the print job notification emailer, or returns
+// nil when no SMTP host is configured
+func setupEmailNotifier(repos repositories, cfg *config.Config, logger *slog.Logger) (*service.EmailNotifier, error) {
+	if cfg.Email.SMTPHost == "" {
+		return nil, nil
+	}
+	sender, err := mail.NewSMTPSender(mail.SMTPConfig{
+		Host:     cfg.Email.SMTPHost,
+		Port:     cfg.Email.SMTPPort,
+		Username: cfg.Email.SMTPUsername,
+		Password: cfg.Email.SMTPPassword,
+		From:     cfg.Email.From,
+		TLSMode:  cfg.Email.TLSMode,
+	})
+	if err != nil {
+		return nil, err
 	}
+	return service.NewEmailNotifier(repos.notificationRepo, sender, service.EmailNotifierConfig{
+		AttachmentMaxBytes: cfg.Email.AttachmentMaxBytes,
+		LinkBaseURL:        cfg.Email.LinkBaseURL,
+		MaxAttempts:        cfg.Email.MaxAttempts,
+		RetryBackoff:       cfg.Email.RetryBackoff,
+	}, logger), nil
 }
 
-func setupHandlers(svcs services, db *sql.DB, cfg *config.Config) handlerSet {
+// setupStorage creates the configured print output backend. A nil Storage
+// selects local storage in the print output directory.
+func setupStorage(cfg *config.Config) (storage.Storage, error) {
+	switch cfg.Storage.Backend {
+	case "", storage.BackendLocal:
+		return nil, nil
+	case storage.BackendS3:
+		s3, err := storage.NewS3Storage(storage.S3Config{
+			Endpoint:        cfg.Storage.S3.Endpoint,
+			Region:          cfg.Storage.S3.Region,
+			Bucket:          cfg.Storage.S3.Bucket,
+			AccessKeyID:     cfg.Storage.S3.AccessKeyID,
+			SecretAccessKey: cfg.Storage.S3.SecretAccessKey,
+			PathStyle:       cfg.Storage.S3.PathStyle,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return s3, nil
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.Storage.Backend)
+	}
+}
+
+func setupHandlers(svcs services, sched *scheduler.Scheduler, db *sql.DB, cfg *config.Config) handlerSet {
 	// Validate Keycloak configuration before creating client
 	if cfg.Keycloak.BaseURL == "" {
 		panic("KEYCLOAK_URL is required for authentication")
@@ -194,8 +469,32 @@ func setupHandlers(svcs services, db *sql.DB, cfg *config.Config) handlerSet {
 	contractHandler := handlers.NewContractHandler(svcs.contractSvc)
 	contractGenerationHandler := handlers.NewContractGenerationHandler(svcs.contractGenerationSvc)
 	printHandler := handlers.NewPrintHandler(svcs.printSvc)
-	healthHandler := handlers.NewHealthHandler(db)
-	authHandler := handlers.NewAuthHandler(keycloakClient, cfg.JWT.Secret)
+	// Readiness checks the output directory only when outputs are stored locally
+	outputPath := cfg.Print.OutputPath
+	if cfg.Storage.Backend == "s3" {
+		outputPath = ""
+	}
+	healthHandler := handlers.NewHealthHandler(db, keycloakClient, outputPath)
+	tokens := tokenConfig(cfg)
+	tokens.Clients = svcs.apiClientSvc
+	healthHandler.AddCheck("jwks", tokens.JWKS.Check)
+	var logins lockout.Tracker
+	if cfg.Auth.LoginMaxFailures > 0 {
+		logins = lockout.NewMemory(lockout.Policy{
+			MaxFailures: cfg.Auth.LoginMaxFailures,
+			Window:      cfg.Auth.LoginFailureWindow,
+			Lockout:     cfg.Auth.LoginLockout,
+		})
+	}
+	authHandler := handlers.NewAuthHandler(keycloakClient, tokens, logins)
+	webhookHandler := handlers.NewWebhookHandler(svcs.webhookSvc)
+	notificationHandler := handlers.NewNotificationHandler(svcs.notificationSvc)
+
+	// Metrics go on the API listener only when no separate listener is configured
+	var metricsHandler *handlers.MetricsHandler
+	if cfg.Metrics.Addr == "" && cfg.Metrics.Token != "" {
+		metricsHandler = handlers.NewMetricsHandler(metrics.Handler(), cfg.Metrics.Token)
+	}
 
 	return handlerSet{
 		customerHandler:           customerHandler,
@@ -205,13 +504,41 @@ func setupHandlers(svcs services, db *sql.DB, cfg *config.Config) handlerSet {
 		printHandler:              printHandler,
 		healthHandler:             healthHandler,
 		authHandler:               authHandler,
+		webhookHandler:            webhookHandler,
+		notificationHandler:       notificationHandler,
+		tenantHandler:             handlers.NewTenantHandler(svcs.tenantSvc),
+		settingsHandler:           handlers.NewSettingsHandler(svcs.settingsSvc),
+		featureHandler:            handlers.NewFeatureHandler(svcs.flagSvc),
+		searchHandler:             handlers.NewSearchHandler(svcs.searchSvc),
+		invoiceHandler:            handlers.NewInvoiceHandler(svcs.invoiceSvc),
+		attachmentHandler:         handlers.NewContractAttachmentHandler(svcs.attachmentSvc, middleware.RoleConfig{Enforce: cfg.Auth.EnforceRoles}),
+		jobHandler:                handlers.NewJobHandler(sched),
+		metricsHandler:            metricsHandler,
+		verifier:                  auth.NewVerifier(tokens),
+		features:                  svcs.flagSvc,
+	}
+}
+
+// tokenConfig describes the access tokens the API accepts and mints
+func tokenConfig(cfg *config.Config) auth.VerifierConfig {
+	return auth.VerifierConfig{
+		JWKS: auth.NewJWKS(cfg.JWT.JWKSURL, nil, auth.JWKSConfig{
+			TTL:        cfg.JWT.JWKSCacheTTL,
+			MinRefresh: cfg.JWT.JWKSMinRefresh,
+		}),
+		Secret:   cfg.JWT.Secret,
+		Issuer:   cfg.JWT.Issuer,
+		Audience: cfg.JWT.Audience,
+		Leeway:   cfg.JWT.ClockSkew,
 	}
 }
 
 func setupRouter(cfg *config.Config, logger *slog.Logger, h handlerSet) (*router.Router, error) {
+	limiter, rateLimit := rateLimitOptions(cfg)
+
 	// Initialize router
 	r, err := router.NewRouter(
-		cfg.JWT.Secret,
+		h.verifier,
 		logger,
 		router.Handlers{
 			Customer:           h.customerHandler,
@@ -221,6 +548,28 @@ func setupRouter(cfg *config.Config, logger *slog.Logger, h handlerSet) (*router
 			Print:              h.printHandler,
 			Health:             h.healthHandler,
 			Auth:               h.authHandler,
+			Webhook:            h.webhookHandler,
+			Notification:       h.notificationHandler,
+			Tenant:             h.tenantHandler,
+			Settings:           h.settingsHandler,
+			Feature:            h.featureHandler,
+			Search:             h.searchHandler,
+			Invoice:            h.invoiceHandler,
+			Attachment:         h.attachmentHandler,
+			Jobs:               h.jobHandler,
+			Metrics:            h.metricsHandler,
+		},
+		router.Options{
+			BodyLimit: middleware.BodyLimitConfig{
+				MaxBytes:       int64(cfg.Server.MaxBodyBytes),
+				MaxUploadBytes: int64(cfg.Server.MaxUploadBytes),
+			},
+			GzipMinBytes: cfg.Server.GzipMinBytes,
+			Limiter:      limiter,
+			RateLimit:    rateLimit,
+			Roles:        middleware.RoleConfig{Enforce: cfg.Auth.EnforceRoles},
+			Security:     securityHeaders(cfg),
+			Features:     h.features,
 		},
 	)
 	if err != nil {
@@ -229,7 +578,32 @@ func setupRouter(cfg *config.Config, logger *slog.Logger, h handlerSet) (*router
 	return r, nil
 }
 
-func setupServer(cfg *config.Config, r *router.Router) *http.Server {
+// securityHeaders maps the security config onto the middleware settings
+func securityHeaders(cfg *config.Config) middleware.SecurityHeadersConfig {
+	headers := middleware.SecurityHeadersConfig{ContentSecurityPolicy: cfg.Security.ContentSecurityPolicy}
+	if cfg.Security.HSTSEnabled {
+		headers.HSTS = cfg.Security.HSTS
+	}
+	return headers
+}
+
+// rateLimitOptions builds the rate limiter; nil disables rate limiting
+func rateLimitOptions(cfg *config.Config) (ratelimit.Limiter, middleware.RateLimitConfig) {
+	rl := cfg.RateLimit
+	if !rl.Enabled {
+		return nil, middleware.RateLimitConfig{}
+	}
+	return ratelimit.NewMemory(), middleware.RateLimitConfig{
+		Default: ratelimit.Limit{Rate: rl.RPS, Burst: rl.Burst},
+		Groups: []middleware.RateLimitGroup{
+			{Name: "auth", PathPrefix: "/api/v1/auth/", Limit: ratelimit.Limit{Rate: rl.AuthRPS, Burst: rl.AuthBurst}},
+			{Name: "integrity_sweep", PathPrefix: "/api/v1/admin/verify-integrity", Limit: ratelimit.Limit{Rate: rl.SweepRPS, Burst: rl.SweepBurst}},
+		},
+		TrustForwardedFor: rl.TrustProxy,
+	}
+}
+
+func setupServer(cfg *config.Config, r *router.Router, logger *slog.Logger) (*http.Server, error) {
 	// Create HTTP server
 	server := &http.Server{
 		Addr:           cfg.Server.Host + ":" + cfg.Server.Port,
@@ -239,62 +613,189 @@ func setupServer(cfg *config.Config, r *router.Router) *http.Server {
 		IdleTimeout:    cfg.Server.IdleTimeout,
 		MaxHeaderBytes: cfg.Server.MaxHeaderBytes,
 	}
-	return server
-}
+	if !cfg.Server.TLSEnabled() {
+		return server, nil
+	}
 
-func startBackgroundJobs(printSvc *service.PrintService, cfg *config.Config, logger *slog.Logger) (context.CancelFunc, *sync.WaitGroup) {
-	// Start background print job processor
-	ctx, cancel := context.WithCancel(context.Background())
+	// Load the certificate now so bad paths fail at startup, not on first handshake
+	reloader, err := certreload.New(cfg.Server.TLSCertFile, cfg.Server.TLSKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	server.TLSConfig = &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.GetCertificate,
+		// TLS 1.3 suites are not configurable; these cover TLS 1.2 with forward
+		// secrecy and AEAD only, including the suite HTTP/2 requires
+		CipherSuites: []uint16{
+			tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+		},
+		NextProtos: []string{"h2", "http/1.1"},
+	}
 
-	// WaitGroup to track the background goroutine for graceful shutdown
-	var wg sync.WaitGroup
+	if cfg.Server.TLSReloadInterval > 0 {
+		watchCtx, stopWatch := context.WithCancel(context.Background())
+		server.RegisterOnShutdown(stopWatch)
+		go reloader.Watch(watchCtx, cfg.Server.TLSReloadInterval, func(err error) {
+			if err != nil {
+				logger.Error("failed to reload TLS certificate, keeping the previous one", "error", err)
+				return
+			}
+			logger.Info("reloaded TLS certificate", "cert_file", cfg.Server.TLSCertFile)
+		})
+	}
+	return server, nil
+}
 
-	// Mutex to prevent overlapping ProcessPendingJobs executions
-	var jobMu sync.Mutex
+// setupMetricsServer creates the dedicated metrics listener, or returns nil
+// when metrics are not served on a separate address
+func setupMetricsServer(cfg *config.Config) *http.Server {
+	if cfg.Metrics.Addr == "" {
+		return nil
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /metrics", handlers.NewMetricsHandler(metrics.Handler(), cfg.Metrics.Token).Metrics)
+	return &http.Server{
+		Addr:              cfg.Metrics.Addr,
+		Handler:           mux,
+		ReadHeaderTimeout: cfg.Server.ReadTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+	}
+}
 
-	wg.Add(1)
-	go func() {
-		defer wg.Done() // Ensure Done runs after any in-flight ProcessPendingJobs completes
+// Background job names, as shown by GET /api/v1/admin/jobs
+const (
+	jobPrintJobs         = "print_jobs"
+	jobOutputRetention   = "output_retention"
+	jobWebhookRetries    = "webhook_retries"
+	jobMetricsSampler    = "metrics_sampler"
+	jobInvoiceDrafts     = "invoice_drafts"
+	jobGenerationCleanup = "generation_cleanup"
+)
 
-		// Process pending jobs immediately on startup
-		jobMu.Lock()
-		if err := printSvc.ProcessPendingJobs(ctx); err != nil {
-			logger.Error("failed to process pending print jobs on startup", "error", err)
+// setupScheduler registers the background jobs. Jobs that sweep every
+// tenant are jittered by a tenth of their interval so instances started
+// together spread their queries.
+func setupScheduler(svcs services, db *sql.DB, cfg *config.Config, logger *slog.Logger) *scheduler.Scheduler {
+	sched := scheduler.New(logger)
+	printSvc := svcs.printSvc
+
+	register := func(err error) {
+		if err != nil {
+			logger.Error("failed to register background job", "error", err)
+			os.Exit(1)
 		}
-		jobMu.Unlock()
-
-		ticker := time.NewTicker(cfg.Print.JobInterval)
-		defer ticker.Stop()
+	}
 
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case <-ticker.C:
-				// Skip this tick if previous job is still running
-				if !jobMu.TryLock() {
-					logger.Debug("skipping print job tick, previous job still running")
-					continue
+	register(sched.Register(jobPrintJobs, cfg.Print.JobInterval, 0,
+		func(ctx context.Context, _ time.Time) error {
+			return printSvc.ProcessPendingJobs(ctx)
+		}, scheduler.Immediately()))
+
+	// Always runs, as tenants may set a retention even when the default keeps outputs forever
+	register(sched.Register(jobOutputRetention, cfg.Print.CleanupInterval, cfg.Print.CleanupInterval/10,
+		func(ctx context.Context, _ time.Time) error {
+			purged, err := printSvc.PurgeExpiredOutputs(ctx)
+			if purged > 0 {
+				logger.Info("purged expired print outputs",
+					"count", purged,
+					"default_retention_days", cfg.Print.RetentionDays,
+				)
+			}
+			return err
+		}, scheduler.Immediately()))
+
+	register(sched.Register(jobWebhookRetries, cfg.Webhook.RetryInterval, cfg.Webhook.RetryInterval/10,
+		func(ctx context.Context, _ time.Time) error {
+			return svcs.webhookSvc.ProcessDueDeliveries(ctx)
+		}))
+
+	// Refreshes the gauges that are sampled rather than updated inline: DB
+	// pool stats and print job counts by status
+	register(sched.Register(jobMetricsSampler, cfg.Metrics.SampleInterval, 0,
+		func(ctx context.Context, _ time.Time) error {
+			metrics.ObserveDBStats(db.Stats())
+			counts, err := printSvc.CountJobsByStatus(ctx)
+			if err != nil {
+				return err
+			}
+			metrics.SetPrintJobCounts(counts)
+			return nil
+		}, scheduler.Immediately()))
+
+	register(sched.Register(jobInvoiceDrafts, cfg.Invoice.JobInterval, cfg.Invoice.JobInterval/10,
+		func(ctx context.Context, _ time.Time) error {
+			created, err := svcs.invoiceSvc.CreateDueDrafts(ctx, time.Now())
+			if created > 0 {
+				logger.Info("created draft invoices", "count", created)
+			}
+			return err
+		}, scheduler.Immediately()))
+
+	// Every instance wakes up at cfg.Cleanup.At, but only the first to claim
+	// the run performs it
+	if cfg.Cleanup.Enabled {
+		at, _ := time.Parse("15:04", cfg.Cleanup.At) // Checked by config validation
+		register(sched.RegisterDaily(jobGenerationCleanup, at,
+			func(ctx context.Context, due time.Time) error {
+				deleted, ran, err := svcs.cleanupSvc.RunDue(ctx, due)
+				if err == nil && !ran {
+					logger.Debug("generation cleanup run claimed by another instance", "due", due)
 				}
-				if err := printSvc.ProcessPendingJobs(ctx); err != nil {
-					logger.Error("failed to process pending print jobs", "error", err)
+				for tenantID, count := range deleted {
+					logger.Info("cleaned up expired generated contracts", "tenant_id", tenantID, "count", count)
 				}
-				jobMu.Unlock()
-			}
-		}
-	}()
+				return err
+			}))
+	}
+
+	return sched
+}
 
+// startBackgroundJobs starts the scheduler's jobs. Cancelling the returned
+// context stops them; the WaitGroup is done once every job has returned.
+func startBackgroundJobs(sched *scheduler.Scheduler) (context.CancelFunc, *sync.WaitGroup) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	sched.Start(ctx, &wg)
 	return cancel, &wg
 }
 
+// requeueStalePrintJobs hands print jobs left PROCESSING by a crashed instance
+// back to the queue before the print job loop starts. A failure is logged;
+// such jobs are still reclaimed once their lease is seen as expired.
+func requeueStalePrintJobs(printSvc *service.PrintService, logger *slog.Logger) {
+	requeued, err := printSvc.RequeueStaleJobs(context.Background())
+	if err != nil {
+		logger.Error("failed to requeue stale print jobs", "error", err)
+		return
+	}
+	if requeued > 0 {
+		logger.Info("requeued print jobs with expired leases", "count", requeued)
+	}
+}
+
 func startServer(server *http.Server, logger *slog.Logger) chan error {
 	// Error channel for server listen errors
 	serverErrCh := make(chan error, 1)
 
 	// Start server in goroutine
 	go func() {
-		logger.Info("server listening", "addr", server.Addr)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		var err error
+		if server.TLSConfig != nil {
+			// Certificates come from TLSConfig.GetCertificate
+			logger.Info("server listening", "addr", server.Addr, "tls", true)
+			err = server.ListenAndServeTLS("", "")
+		} else {
+			logger.Info("server listening", "addr", server.Addr)
+			err = server.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			logger.Error("server error", "error", err)
 			serverErrCh <- err
 		}
@@ -303,36 +804,79 @@ func startServer(server *http.Server, logger *slog.Logger) chan error {
 	return serverErrCh
 }
 
-func waitForShutdown(server *http.Server, db *sql.DB, cancel context.CancelFunc, bgWg *sync.WaitGroup, serverErrCh chan error, logger *slog.Logger, cfg *config.Config) int {
-	// Wait for interrupt signal or server error
+// startMetricsServer serves metrics in the background; a failure is logged but
+// does not take down the API
+func startMetricsServer(server *http.Server, logger *slog.Logger) {
+	if server == nil {
+		return
+	}
+	go func() {
+		logger.Info("metrics server listening", "addr", server.Addr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Error("metrics server error", "error", err)
+		}
+	}()
+}
+
+func waitForShutdown(server, metricsServer *http.Server, db *sql.DB, printSvc *service.PrintService, cancel context.CancelFunc, bgWg *sync.WaitGroup, serverErrCh chan error, health *handlers.HealthHandler, logger *slog.Logger, cfg *config.Config, live liveSettings) int {
+	// Wait for interrupt signal or server error; SIGHUP reloads configuration
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
 
 	exitCode := 0
-	select {
-	case <-quit:
-		logger.Info("received shutdown signal")
-	case err := <-serverErrCh:
-		logger.Error("server listen failed", "error", err)
-		exitCode = 1
+	current := cfg
+wait:
+	for {
+		select {
+		case <-hup:
+			current = reloadConfig(current, live, logger)
+		case <-quit:
+			logger.Info("received shutdown signal")
+			break wait
+		case err := <-serverErrCh:
+			logger.Error("server listen failed", "error", err)
+			exitCode = 1
+			break wait
+		}
 	}
 
 	logger.Info("shutting down server...")
 
-	// Cancel background jobs and wait for them to finish before closing DB
-	cancel()
-	logger.Debug("waiting for background jobs to complete...")
-	bgWg.Wait()
-	logger.Debug("background jobs completed")
-
-	// Graceful shutdown with timeout
-	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
-	defer shutdownCancel()
+	// Stop claiming print jobs right away and let the ones rendering finish
+	// while the server drains; those still running at the deadline go back
+	// to the queue
+	type drainResult struct{ drained, requeued int }
+	printDrained := make(chan drainResult, 1)
+	go func() {
+		drainCtx, drainCancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+		defer drainCancel()
+		drained, requeued := printSvc.Drain(drainCtx)
+		printDrained <- drainResult{drained, requeued}
+	}()
 
-	if err := server.Shutdown(shutdownCtx); err != nil {
+	if err := stopServing(server, health, cfg.Server.ShutdownDrainDelay, cfg.Server.ShutdownTimeout, logger); err != nil {
 		logger.Error("server shutdown error", "error", err)
 		exitCode = 1
 	}
+	if metricsServer != nil {
+		metricsCtx, metricsCancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+		if err := metricsServer.Shutdown(metricsCtx); err != nil {
+			logger.Error("metrics server shutdown error", "error", err)
+		}
+		metricsCancel()
+	}
+
+	result := <-printDrained
+	logger.Info("print processor drained", "drained", result.drained, "requeued", result.requeued)
+
+	// Cancel background jobs once no request can start new work, and wait
+	// for them to finish before closing DB
+	cancel()
+	logger.Debug("waiting for background jobs to complete...")
+	bgWg.Wait()
+	logger.Debug("background jobs completed")
 
 	// Explicitly close database after background jobs have finished using it
 	if err := db.Close(); err != nil {
@@ -344,6 +888,73 @@ func waitForShutdown(server *http.Server, db *sql.DB, cancel context.CancelFunc,
 	return exitCode
 }
 
+// stopServing fails readiness so load balancers stop routing requests here,
+// waits delay for them to notice, then closes the listener and waits up to
+// timeout for requests in flight
+func stopServing(server *http.Server, health *handlers.HealthHandler, delay, timeout time.Duration, logger *slog.Logger) error {
+	health.SetShuttingDown()
+	if delay > 0 {
+		logger.Info("readiness failing, waiting before closing the listener", "delay", delay)
+		time.Sleep(delay)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return server.Shutdown(ctx)
+}
+
+// reloadConfig re-reads the configuration and applies the settings that are
+// safe to change at runtime: the log level, the print job polling interval and
+// the slow query threshold. Other changes are reported as needing a restart.
+// It returns the configuration now in effect.
+func reloadConfig(current *config.Config, live liveSettings, logger *slog.Logger) *config.Config {
+	logger.Info("received SIGHUP, reloading configuration")
+
+	next, err := config.Load()
+	if err == nil {
+		var warnings []string
+		warnings, err = next.Validate()
+		for _, w := range warnings {
+			logger.Warn("configuration warning", "warning", w)
+		}
+	}
+	if err != nil {
+		logger.Error("configuration reload failed, keeping current settings", "error", err)
+		return current
+	}
+
+	applied := *current
+	var changed []any
+	if next.LogLevel != current.LogLevel {
+		if level, ok := parseLogLevel(next.LogLevel); ok {
+			live.logLevel.Set(level)
+			applied.LogLevel = next.LogLevel
+			changed = append(changed, "log_level", next.LogLevel)
+		} else {
+			logger.Warn("unknown log level in reloaded configuration, keeping current level", "log_level", next.LogLevel)
+		}
+	}
+	if next.Print.JobInterval != current.Print.JobInterval {
+		if err := live.scheduler.SetInterval(jobPrintJobs, next.Print.JobInterval); err != nil {
+			logger.Warn("failed to apply reloaded print job interval", "error", err)
+		} else {
+			applied.Print.JobInterval = next.Print.JobInterval
+			changed = append(changed, "print.job_interval", next.Print.JobInterval)
+		}
+	}
+	if next.Database.SlowQueryThreshold != current.Database.SlowQueryThreshold {
+		live.queryDB.SetSlowQueryThreshold(next.Database.SlowQueryThreshold)
+		applied.Database.SlowQueryThreshold = next.Database.SlowQueryThreshold
+		changed = append(changed, "database.slow_query_threshold", next.Database.SlowQueryThreshold)
+	}
+
+	logger.Info("configuration reloaded", changed...)
+	if restart := applied.Changes(next); len(restart) > 0 {
+		logger.Warn("changed settings only take effect after a restart", "settings", restart)
+	}
+	return &applied
+}
+
 // parseLogLevel parses a log level string into slog.Level
 // Returns the level and true if recognized, or LevelInfo and false if unknown
 func parseLogLevel(level string) (slog.Level, bool) {
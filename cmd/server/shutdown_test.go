@@ -0,0 +1,100 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/zlovtnik/gprint/internal/handlers"
+)
+
+// get requests path on a new connection, so a closed listener is seen
+func get(addr, path string) (int, error) {
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}, Timeout: 5 * time.Second}
+	resp, err := client.Get("http://" + addr + path)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode, nil
+}
+
+func TestStopServingFailsReadinessBeforeRefusingConnections(t *testing.T) {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	health := handlers.NewHealthHandler(db, nil, "")
+
+	// /slow is a request in flight when shutdown starts
+	slowStarted, finishSlow := make(chan struct{}), make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /readyz", health.Ready)
+	mux.HandleFunc("GET /slow", func(w http.ResponseWriter, r *http.Request) {
+		close(slowStarted)
+		<-finishSlow
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	server := &http.Server{Handler: mux}
+	served := make(chan error, 1)
+	go func() { served <- server.Serve(ln) }()
+
+	if status, err := get(addr, "/readyz"); err != nil || status != http.StatusOK {
+		t.Fatalf("before shutdown /readyz = %d, %v; want 200", status, err)
+	}
+	slow := make(chan int, 1)
+	go func() {
+		status, _ := get(addr, "/slow")
+		slow <- status
+	}()
+	<-slowStarted
+
+	const delay = 300 * time.Millisecond
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	stopped := make(chan error, 1)
+	shutdownAt := time.Now()
+	go func() { stopped <- stopServing(server, health, delay, 5*time.Second, logger) }()
+
+	// During the delay readiness fails, yet new connections are still served
+	// so requests routed here before the load balancer noticed succeed
+	time.Sleep(delay / 3)
+	if status, err := get(addr, "/readyz"); err != nil || status != http.StatusServiceUnavailable {
+		t.Errorf("during the delay /readyz = %d, %v; want 503 on an accepted connection", status, err)
+	}
+	if time.Since(shutdownAt) >= delay {
+		t.Fatal("the delay passed before readiness was checked; the test is too slow")
+	}
+
+	// The listener closes after the delay while the request in flight is
+	// allowed to finish
+	time.Sleep(delay)
+	if _, err := get(addr, "/readyz"); err == nil {
+		t.Error("a new connection was accepted after the delay")
+	}
+	close(finishSlow)
+	if status := <-slow; status != http.StatusOK {
+		t.Errorf("request in flight finished with %d, want 200", status)
+	}
+
+	if err := <-stopped; err != nil {
+		t.Errorf("stopServing: %v", err)
+	}
+	if time.Since(shutdownAt) < delay {
+		t.Error("stopServing returned before the delay")
+	}
+	if err := <-served; !errors.Is(err, http.ErrServerClosed) {
+		t.Errorf("Serve returned %v, want http.ErrServerClosed", err)
+	}
+}
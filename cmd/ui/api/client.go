@@ -23,12 +23,18 @@ var ErrResponseTooLarge = errors.New("response body too large")
 // ErrInvalidBaseURL is returned when the base URL is empty or malformed
 var ErrInvalidBaseURL = errors.New("invalid base URL: must be non-empty with scheme and host")
 
+// ErrSessionExpired is returned when a request is rejected as unauthorized
+// and the session could not be refreshed
+var ErrSessionExpired = errors.New("session expired, please log in")
+
 // Client is an HTTP client for the GPrint API
 type Client struct {
-	BaseURL    string
-	HTTPClient *http.Client
-	mu         sync.RWMutex
-	token      string
+	BaseURL      string
+	HTTPClient   *http.Client
+	mu           sync.RWMutex
+	token        string
+	refreshToken string
+	refreshMu    sync.Mutex // one refresh at a time
 }
 
 // NewClient creates a new API client.
@@ -61,6 +67,21 @@ func (c *Client) SetToken(token string) {
 	c.token = token
 }
 
+// setSession stores the tokens of a login or refresh
+func (c *Client) setSession(token, refreshToken string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token = token
+	c.refreshToken = refreshToken
+}
+
+// getRefreshToken returns the current refresh token in a thread-safe manner
+func (c *Client) getRefreshToken() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.refreshToken
+}
+
 // getToken returns the current JWT token in a thread-safe manner
 func (c *Client) getToken() string {
 	c.mu.RLock()
@@ -81,6 +102,22 @@ type ErrorResponse struct {
 	Message string `json:"message"`
 }
 
+// HTTPError is returned for a non-2xx response. Code, Message and Data are
+// set when the body is an API error response.
+type HTTPError struct {
+	StatusCode int
+	Code       string
+	Message    string
+	Data       json.RawMessage
+}
+
+func (e *HTTPError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("HTTP %d: %s: %s", e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Message)
+}
+
 // PaginatedResponse wraps paginated data
 type PaginatedResponse struct {
 	Data       json.RawMessage `json:"data"`
@@ -141,10 +178,12 @@ func readResponseBody(body io.ReadCloser) ([]byte, error) {
 func parseErrorResponse(statusCode int, body []byte) error {
 	var errResp Response
 	if err := json.Unmarshal(body, &errResp); err == nil && errResp.Error != nil {
-		if errResp.Error.Code != "" {
-			return fmt.Errorf("HTTP %d: %s: %s", statusCode, errResp.Error.Code, errResp.Error.Message)
+		return &HTTPError{
+			StatusCode: statusCode,
+			Code:       errResp.Error.Code,
+			Message:    errResp.Error.Message,
+			Data:       errResp.Data,
 		}
-		return fmt.Errorf("HTTP %d: %s", statusCode, errResp.Error.Message)
 	}
 	// Fall back to truncated body (rune-safe to avoid splitting UTF-8 characters)
 	errBody := string(body)
@@ -152,24 +191,41 @@ func parseErrorResponse(statusCode int, body []byte) error {
 	if len(runes) > 200 {
 		errBody = string(runes[:200]) + "..."
 	}
-	return fmt.Errorf("HTTP %d: %s", statusCode, errBody)
+	return &HTTPError{StatusCode: statusCode, Message: errBody}
 }
 
-// doRequestWithContext performs an HTTP request with context support
+// doRequestWithContext performs an HTTP request with context support. A
+// 401 on an authenticated request triggers one token refresh and a retry;
+// if the refresh fails too, ErrSessionExpired is returned.
 func (c *Client) doRequestWithContext(ctx context.Context, method, path string, body interface{}) (*Response, error) {
-	reqBody, err := marshalBody(body)
-	if err != nil {
-		return nil, err
-	}
-
 	// Normalize path to ensure leading slash
 	if path != "" && path[0] != '/' {
 		path = "/" + path
 	}
 
+	token := c.getToken()
+	resp, status, err := c.send(ctx, method, path, body, token)
+	if status != http.StatusUnauthorized || token == "" || strings.HasPrefix(path, authPathPrefix) {
+		return resp, err
+	}
+	if err := c.refreshAfter(ctx, token); err != nil {
+		return nil, ErrSessionExpired
+	}
+	resp, _, err = c.send(ctx, method, path, body, c.getToken())
+	return resp, err
+}
+
+// send performs one HTTP request and returns the parsed response along with
+// the HTTP status (0 when no response was received)
+func (c *Client) send(ctx context.Context, method, path string, body interface{}, token string) (*Response, int, error) {
+	reqBody, err := marshalBody(body)
+	if err != nil {
+		return nil, 0, err
+	}
+
 	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, 0, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Always request JSON responses
@@ -177,36 +233,48 @@ func (c *Client) doRequestWithContext(ctx context.Context, method, path string,
 	if body != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
-	if token := c.getToken(); token != "" {
+	if token != "" {
 		req.Header.Set("Authorization", "Bearer "+token)
 	}
 
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return nil, 0, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	respBody, err := readResponseBody(resp.Body)
 	if err != nil {
-		return nil, err
+		return nil, resp.StatusCode, err
 	}
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, parseErrorResponse(resp.StatusCode, respBody)
+		return nil, resp.StatusCode, parseErrorResponse(resp.StatusCode, respBody)
 	}
 
 	// Handle 204 No Content and empty responses
 	if resp.StatusCode == http.StatusNoContent || len(respBody) == 0 {
-		return &Response{Success: true}, nil
+		return &Response{Success: true}, resp.StatusCode, nil
 	}
 
 	var apiResp Response
 	if err := json.Unmarshal(respBody, &apiResp); err != nil {
-		return nil, fmt.Errorf("failed to parse response (HTTP %d): %w", resp.StatusCode, err)
+		return nil, resp.StatusCode, fmt.Errorf("failed to parse response (HTTP %d): %w", resp.StatusCode, err)
 	}
 
-	return &apiResp, nil
+	return &apiResp, resp.StatusCode, nil
+}
+
+// refreshAfter refreshes the session that staleToken belonged to. Concurrent
+// requests rejected with the same token share one refresh.
+func (c *Client) refreshAfter(ctx context.Context, staleToken string) error {
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+	if c.getToken() != staleToken {
+		return nil // another request already refreshed
+	}
+	_, err := c.Refresh(ctx)
+	return err
 }
 
 // Get performs a GET request
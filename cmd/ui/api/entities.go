@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/shopspring/decimal"
@@ -15,17 +17,26 @@ var ErrEmptyResponse = errors.New("empty response data from API")
 
 // API path constants
 const (
-	paginationQueryFmt  = "%s?page=%d&limit=%d"
-	apiErrorFmt         = "API error: %s"
-	loginPath           = "/api/v1/auth/login"
-	customersPath       = "/api/v1/customers"
-	customerByIDPathFmt = "/api/v1/customers/%d"
-	servicesPath        = "/api/v1/services"
-	serviceByIDPathFmt  = "/api/v1/services/%d"
-	contractsPath       = "/api/v1/contracts"
-	contractByIDPathFmt = "/api/v1/contracts/%d"
-	printJobsPath       = "/api/v1/print-jobs"
-	defaultPageLimit    = 20
+	paginationQueryFmt   = "%s?page=%d&page_size=%d"
+	apiErrorFmt          = "API error: %s"
+	authPathPrefix       = "/api/v1/auth/"
+	loginPath            = "/api/v1/auth/login"
+	refreshPath          = "/api/v1/auth/refresh"
+	logoutPath           = "/api/v1/auth/logout"
+	mePath               = "/api/v1/auth/me"
+	featuresPath         = "/api/v1/features"
+	customersPath        = "/api/v1/customers"
+	customerByIDPathFmt  = "/api/v1/customers/%d"
+	servicesPath         = "/api/v1/services"
+	serviceByIDPathFmt   = "/api/v1/services/%d"
+	contractsPath        = "/api/v1/contracts"
+	contractByIDPathFmt  = "/api/v1/contracts/%d"
+	contractItemsPathFmt = "/api/v1/contracts/%d/items"
+	printJobsPath        = "/api/v1/print-jobs"
+	printJobByIDPathFmt  = "/api/v1/print-jobs/%d"
+	notificationsPath    = "/api/v1/notifications"
+	notificationPathFmt  = "/api/v1/notifications/%d"
+	defaultPageLimit     = 20
 )
 
 // LoginRequest represents login credentials
@@ -69,16 +80,122 @@ func (c *Client) Login(ctx context.Context, username, password string) (*LoginRe
 		return nil, fmt.Errorf("failed to parse login response: %w", err)
 	}
 
-	// Auto-set the token for subsequent requests
-	c.SetToken(loginResp.AccessToken)
+	// Auto-set the tokens for subsequent requests
+	c.setSession(loginResp.AccessToken, loginResp.RefreshToken)
 
 	return &loginResp, nil
 }
 
-// ListOptions provides pagination options for list operations
+// Refresh exchanges the stored refresh token for a new token pair. The
+// session is cleared if the refresh is rejected.
+func (c *Client) Refresh(ctx context.Context) (*LoginResponse, error) {
+	refreshToken := c.getRefreshToken()
+	if refreshToken == "" {
+		return nil, ErrSessionExpired
+	}
+
+	resp, status, err := c.send(ctx, "POST", refreshPath, map[string]string{"refresh_token": refreshToken}, "")
+	if err != nil {
+		if status == http.StatusUnauthorized {
+			c.setSession("", "")
+		}
+		return nil, err
+	}
+
+	if !resp.Success {
+		return nil, fmt.Errorf(apiErrorFmt, resp.ErrorString())
+	}
+
+	if len(resp.Data) == 0 {
+		return nil, ErrEmptyResponse
+	}
+
+	var loginResp LoginResponse
+	if err := json.Unmarshal(resp.Data, &loginResp); err != nil {
+		return nil, fmt.Errorf("failed to parse refresh response: %w", err)
+	}
+
+	c.setSession(loginResp.AccessToken, loginResp.RefreshToken)
+	return &loginResp, nil
+}
+
+// Logout revokes the refresh token and clears the session. The session is
+// cleared even when the server cannot be reached.
+func (c *Client) Logout(ctx context.Context) error {
+	refreshToken := c.getRefreshToken()
+	c.setSession("", "")
+	if refreshToken == "" {
+		return nil
+	}
+
+	_, _, err := c.send(ctx, "POST", logoutPath, map[string]string{"refresh_token": refreshToken}, "")
+	return err
+}
+
+// UserProfile is the signed-in user's identity
+type UserProfile struct {
+	User           string   `json:"user"`
+	Username       string   `json:"username"`
+	Email          string   `json:"email,omitempty"`
+	FullName       string   `json:"full_name,omitempty"`
+	Roles          []string `json:"roles"`
+	TenantID       string   `json:"tenant_id"`
+	ServiceAccount bool     `json:"service_account,omitempty"`
+}
+
+// Me returns the signed-in user's identity
+func (c *Client) Me(ctx context.Context) (*UserProfile, error) {
+	resp, err := c.doRequestWithContext(ctx, "GET", mePath, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if !resp.Success {
+		return nil, fmt.Errorf(apiErrorFmt, resp.ErrorString())
+	}
+
+	if len(resp.Data) == 0 {
+		return nil, ErrEmptyResponse
+	}
+
+	var profile UserProfile
+	if err := json.Unmarshal(resp.Data, &profile); err != nil {
+		return nil, fmt.Errorf("failed to parse profile: %w", err)
+	}
+	return &profile, nil
+}
+
+// FeatureFlag is a feature's state for the signed-in user's tenant
+type FeatureFlag struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+	Source  string `json:"source"`
+}
+
+// Features returns the feature flags of the signed-in user's tenant
+func (c *Client) Features(ctx context.Context) ([]FeatureFlag, error) {
+	resp, err := c.doRequestWithContext(ctx, "GET", featuresPath, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if !resp.Success {
+		return nil, fmt.Errorf(apiErrorFmt, resp.ErrorString())
+	}
+
+	var flags []FeatureFlag
+	if err := json.Unmarshal(resp.Data, &flags); err != nil {
+		return nil, fmt.Errorf("failed to parse features: %w", err)
+	}
+	return flags, nil
+}
+
+// ListOptions provides pagination and search options for list operations
 type ListOptions struct {
-	Page  int
-	Limit int
+	Page       int
+	Limit      int
+	Query      string // Sent as the q search parameter when set
+	CustomerID int64  // Sent as customer_id when set; contracts only
 }
 
 // WithDefaults returns a copy of ListOptions with safe defaults applied
@@ -142,23 +259,57 @@ type Contract struct {
 	CustomerID     int64           `json:"customer_id"`
 	StartDate      time.Time       `json:"start_date"`
 	EndDate        *time.Time      `json:"end_date,omitempty"`
+	DiscountAmount decimal.Decimal `json:"discount_amount"`
+	TaxAmount      decimal.Decimal `json:"tax_amount"`
 	TotalValue     decimal.Decimal `json:"total_value"`
 	BillingCycle   string          `json:"billing_cycle"`
 	Status         string          `json:"status"`
 	CreatedAt      time.Time       `json:"created_at"`
+
+	// Items is filled in by GetContractItems for the detail view
+	Items []ContractItem `json:"items,omitempty"`
+}
+
+// ContractItem represents a line item of a contract
+type ContractItem struct {
+	ID          int64           `json:"id"`
+	ServiceID   int64           `json:"service_id"`
+	Quantity    decimal.Decimal `json:"quantity"`
+	UnitPrice   decimal.Decimal `json:"unit_price"`
+	DiscountPct decimal.Decimal `json:"discount_pct"`
+	LineTotal   decimal.Decimal `json:"line_total"`
+	Status      string          `json:"status"`
+	Description string          `json:"description,omitempty"`
 }
 
 // PrintJob represents a print job
 type PrintJob struct {
-	ID          int64      `json:"id"`
-	ContractID  int64      `json:"contract_id"`
-	Status      string     `json:"status"`
-	Format      string     `json:"format"`
-	FileSize    int64      `json:"file_size,omitempty"`
-	PageCount   int        `json:"page_count,omitempty"`
-	QueuedAt    time.Time  `json:"queued_at"`
-	CompletedAt *time.Time `json:"completed_at,omitempty"`
-	RequestedBy string     `json:"requested_by"`
+	ID           int64      `json:"id"`
+	ContractID   int64      `json:"contract_id"`
+	Status       string     `json:"status"`
+	Format       string     `json:"format"`
+	Priority     string     `json:"priority,omitempty"`
+	ProgressPct  int        `json:"progress_pct"`
+	FileSize     int64      `json:"file_size,omitempty"`
+	PageCount    int        `json:"page_count,omitempty"`
+	QueuedAt     time.Time  `json:"queued_at"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty"`
+	RetryCount   int        `json:"retry_count"`
+	NextRetryAt  *time.Time `json:"next_retry_at,omitempty"`
+	ErrorMessage string     `json:"error_message,omitempty"`
+	RequestedBy  string     `json:"requested_by"`
+}
+
+// Notification is a message in the signed-in user's notification list
+type Notification struct {
+	ID         int64      `json:"id"`
+	Type       string     `json:"type"`
+	Title      string     `json:"title"`
+	Body       string     `json:"body,omitempty"`
+	EntityType string     `json:"entity_type,omitempty"`
+	EntityID   *int64     `json:"entity_id,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ReadAt     *time.Time `json:"read_at,omitempty"`
 }
 
 // CreateCustomerRequest is the request payload for creating a customer
@@ -234,6 +385,12 @@ func listItems[T any](c *Client, basePath string, opts *ListOptions) (*ListResul
 func listItemsWithContext[T any](ctx context.Context, c *Client, basePath string, opts *ListOptions) (*ListResult[T], error) {
 	normalized := opts.WithDefaults()
 	path := fmt.Sprintf(paginationQueryFmt, basePath, normalized.Page, normalized.Limit)
+	if normalized.Query != "" {
+		path += "&q=" + url.QueryEscape(normalized.Query)
+	}
+	if normalized.CustomerID > 0 {
+		path += fmt.Sprintf("&customer_id=%d", normalized.CustomerID)
+	}
 
 	resp, err := c.GetWithContext(ctx, path)
 	if err != nil {
@@ -488,6 +645,20 @@ func (c *Client) GetContract(id int64) (*Contract, error) {
 	return &contract, nil
 }
 
+// GetContractItems fetches the items of a contract
+func (c *Client) GetContractItems(contractID int64) ([]ContractItem, error) {
+	return c.GetContractItemsWithContext(context.Background(), contractID)
+}
+
+// GetContractItemsWithContext fetches the items of a contract with context support
+func (c *Client) GetContractItemsWithContext(ctx context.Context, contractID int64) ([]ContractItem, error) {
+	resp, err := c.GetWithContext(ctx, fmt.Sprintf(contractItemsPathFmt, contractID))
+	if err != nil {
+		return nil, err
+	}
+	return parseResponseList[ContractItem](resp)
+}
+
 // CreateContract creates a new contract
 func (c *Client) CreateContract(req *CreateContractRequest) (*Contract, error) {
 	return c.CreateContractWithContext(context.Background(), req)
@@ -560,14 +731,159 @@ func (c *Client) ListPrintJobsWithContext(ctx context.Context, opts *ListOptions
 	return listItemsWithContext[PrintJob](ctx, c, printJobsPath, opts)
 }
 
+// ListNotifications fetches the signed-in user's notifications, unread first
+func (c *Client) ListNotifications(ctx context.Context, opts *ListOptions) (*ListResult[Notification], error) {
+	return listItemsWithContext[Notification](ctx, c, notificationsPath, opts)
+}
+
+// UnreadNotifications returns the number of the signed-in user's unread notifications
+func (c *Client) UnreadNotifications(ctx context.Context) (int, error) {
+	resp, err := c.GetWithContext(ctx, notificationsPath+"/unread-count")
+	if err != nil {
+		return 0, err
+	}
+	count, err := parseResponseData[struct {
+		Unread int `json:"unread"`
+	}](resp)
+	if err != nil {
+		return 0, err
+	}
+	return count.Unread, nil
+}
+
+// MarkNotificationRead marks one of the signed-in user's notifications read
+func (c *Client) MarkNotificationRead(ctx context.Context, id int64) (*Notification, error) {
+	return PostActionWithResultContext[Notification](ctx, c, notificationPathFmt, id, "read", nil)
+}
+
+// MarkAllNotificationsRead marks all of the signed-in user's notifications
+// read and returns how many were unread
+func (c *Client) MarkAllNotificationsRead(ctx context.Context) (int, error) {
+	resp, err := c.PostWithContext(ctx, notificationsPath+"/read-all", nil)
+	if err != nil {
+		return 0, err
+	}
+	result, err := parseResponseData[struct {
+		Marked int `json:"marked"`
+	}](resp)
+	if err != nil {
+		return 0, err
+	}
+	return result.Marked, nil
+}
+
+// StaleGenerationError is returned when printing a contract that changed
+// after its latest generation; print again with regenerate set to generate
+// it first
+type StaleGenerationError struct {
+	ContractID        int64     `json:"contract_id"`
+	ContractUpdatedAt time.Time `json:"contract_updated_at"`
+	GeneratedID       int64     `json:"generated_id"`
+	GeneratedAt       time.Time `json:"generated_at"`
+}
+
+func (e *StaleGenerationError) Error() string {
+	return fmt.Sprintf("contract was updated at %s, after its latest generation at %s",
+		e.ContractUpdatedAt.Format(time.RFC3339), e.GeneratedAt.Format(time.RFC3339))
+}
+
 // CreatePrintJob creates a print job for a contract
-func (c *Client) CreatePrintJob(contractID int64, format string) (*PrintJob, error) {
-	return c.CreatePrintJobWithContext(context.Background(), contractID, format)
+func (c *Client) CreatePrintJob(contractID int64, format string, regenerate bool) (*PrintJob, error) {
+	return c.CreatePrintJobWithContext(context.Background(), contractID, format, regenerate)
+}
+
+// CreatePrintJobWithContext creates a print job for a contract with context
+// support. With regenerate set, a contract changed since its latest
+// generation is generated again first; otherwise printing it fails with a
+// *StaleGenerationError.
+func (c *Client) CreatePrintJobWithContext(ctx context.Context, contractID int64, format string, regenerate bool) (*PrintJob, error) {
+	body := struct {
+		Format     string `json:"format"`
+		Regenerate bool   `json:"regenerate,omitempty"`
+	}{Format: format, Regenerate: regenerate}
+	resp, err := c.doRequestWithContext(ctx, "POST", fmt.Sprintf(contractByIDPathFmt+"/print", contractID), body)
+	if err != nil {
+		var httpErr *HTTPError
+		if errors.As(err, &httpErr) && httpErr.Code == "STALE_GENERATION" {
+			stale := &StaleGenerationError{ContractID: contractID}
+			if len(httpErr.Data) > 0 && json.Unmarshal(httpErr.Data, stale) == nil {
+				return nil, stale
+			}
+		}
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, fmt.Errorf(apiErrorFmt, resp.ErrorString())
+	}
+	if len(resp.Data) == 0 {
+		return nil, ErrEmptyResponse
+	}
+
+	var job PrintJob
+	if err := json.Unmarshal(resp.Data, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// GetPrintJob fetches a print job by ID
+func (c *Client) GetPrintJob(id int64) (*PrintJob, error) {
+	return c.GetPrintJobWithContext(context.Background(), id)
+}
+
+// GetPrintJobWithContext fetches a print job by ID with context support
+func (c *Client) GetPrintJobWithContext(ctx context.Context, id int64) (*PrintJob, error) {
+	resp, err := c.GetWithContext(ctx, fmt.Sprintf(printJobByIDPathFmt, id))
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, fmt.Errorf(apiErrorFmt, resp.ErrorString())
+	}
+	if len(resp.Data) == 0 {
+		return nil, ErrEmptyResponse
+	}
+
+	var job PrintJob
+	if err := json.Unmarshal(resp.Data, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// RetryPrintJob requeues a failed print job
+func (c *Client) RetryPrintJob(id int64) (*PrintJob, error) {
+	return c.RetryPrintJobWithContext(context.Background(), id)
+}
+
+// RetryPrintJobWithContext requeues a failed print job with context support
+func (c *Client) RetryPrintJobWithContext(ctx context.Context, id int64) (*PrintJob, error) {
+	resp, err := c.doRequestWithContext(ctx, "POST", fmt.Sprintf(printJobByIDPathFmt+"/retry", id), nil)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, fmt.Errorf(apiErrorFmt, resp.ErrorString())
+	}
+	if len(resp.Data) == 0 {
+		return nil, ErrEmptyResponse
+	}
+
+	var job PrintJob
+	if err := json.Unmarshal(resp.Data, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// CancelPrintJob cancels a queued, processing or failed print job
+func (c *Client) CancelPrintJob(id int64) (*PrintJob, error) {
+	return c.CancelPrintJobWithContext(context.Background(), id)
 }
 
-// CreatePrintJobWithContext creates a print job for a contract with context support
-func (c *Client) CreatePrintJobWithContext(ctx context.Context, contractID int64, format string) (*PrintJob, error) {
-	resp, err := c.doRequestWithContext(ctx, "POST", fmt.Sprintf(contractByIDPathFmt+"/print", contractID), map[string]string{"format": format})
+// CancelPrintJobWithContext cancels a print job with context support
+func (c *Client) CancelPrintJobWithContext(ctx context.Context, id int64) (*PrintJob, error) {
+	resp, err := c.doRequestWithContext(ctx, "POST", fmt.Sprintf(printJobByIDPathFmt+"/cancel", id), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -2,10 +2,13 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/zlovtnik/gprint/cmd/ui/api"
+	"github.com/zlovtnik/gprint/cmd/ui/ui"
 )
 
 // fetchTimeout is the maximum time to wait for API fetch operations
@@ -14,70 +17,116 @@ const fetchTimeout = 10 * time.Second
 // fetchAllData returns a batch command that fetches all entity data in parallel
 func (m Model) fetchAllData() tea.Cmd {
 	return tea.Batch(
-		m.fetchCustomers(),
-		m.fetchServices(),
-		m.fetchContracts(),
-		m.fetchPrintJobs(),
+		m.fetchCustomers(1),
+		m.fetchServices(1),
+		m.fetchContracts(1),
+		m.fetchPrintJobs(1),
+		m.fetchNotifications(),
 	)
 }
 
 // API fetch commands with timeout support
-func (m Model) fetchCustomers() tea.Cmd {
+func (m Model) fetchProfile() tea.Cmd {
 	client := m.client
 	return func() tea.Msg {
 		ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
 		defer cancel()
 
-		res, err := client.ListCustomersWithContext(ctx, nil)
+		profile, err := client.Me(ctx)
+		return profileMsg{profile: profile, err: err}
+	}
+}
+
+// fetchFeatures loads the tenant's feature flags, which decide the menu entries shown
+func (m Model) fetchFeatures() tea.Cmd {
+	client := m.client
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+		defer cancel()
+
+		flags, err := client.Features(ctx)
+		return featuresMsg{flags: flags, err: err}
+	}
+}
+
+func (m Model) fetchCustomers(page int) tea.Cmd {
+	client := m.client
+	query := m.searchQuery(ui.ViewCustomers)
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+		defer cancel()
+
+		res, err := client.ListCustomersWithContext(ctx, &api.ListOptions{Page: page, Query: query})
 		if err != nil {
 			return errMsg{err}
 		}
-		return fetchCustomersMsg{res.Items}
+		return fetchCustomersMsg{customers: res.Items, page: newListPage(res)}
 	}
 }
 
-func (m Model) fetchServices() tea.Cmd {
+func (m Model) fetchServices(page int) tea.Cmd {
 	client := m.client
+	query := m.searchQuery(ui.ViewServices)
 	return func() tea.Msg {
 		ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
 		defer cancel()
 
-		res, err := client.ListServicesWithContext(ctx, nil)
+		res, err := client.ListServicesWithContext(ctx, &api.ListOptions{Page: page, Query: query})
 		if err != nil {
 			return errMsg{err}
 		}
-		return fetchServicesMsg{res.Items}
+		return fetchServicesMsg{services: res.Items, page: newListPage(res)}
 	}
 }
 
-func (m Model) fetchContracts() tea.Cmd {
+func (m Model) fetchContracts(page int) tea.Cmd {
 	client := m.client
+	query := m.searchQuery(ui.ViewContracts)
 	return func() tea.Msg {
 		ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
 		defer cancel()
 
-		res, err := client.ListContractsWithContext(ctx, nil)
+		res, err := client.ListContractsWithContext(ctx, &api.ListOptions{Page: page, Query: query})
 		if err != nil {
 			return errMsg{err}
 		}
-		return fetchContractsMsg{res.Items}
+		return fetchContractsMsg{contracts: res.Items, page: newListPage(res)}
 	}
 }
 
-func (m Model) fetchPrintJobs() tea.Cmd {
+func (m Model) fetchPrintJobs(page int) tea.Cmd {
 	client := m.client
 	return func() tea.Msg {
 		ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
 		defer cancel()
 
-		res, err := client.ListPrintJobsWithContext(ctx, nil)
+		res, err := client.ListPrintJobsWithContext(ctx, &api.ListOptions{Page: page})
 		if err != nil {
 			return errMsg{err}
 		}
-		return fetchPrintJobsMsg{res.Items}
+		return fetchPrintJobsMsg{jobs: res.Items, page: newListPage(res)}
 	}
 }
 
+// printJobPollInterval is how often an open, running print job is refreshed
+const printJobPollInterval = 2 * time.Second
+
+// pollPrintJob re-fetches a print job after printJobPollInterval; seq ties the
+// result to the poll loop that requested it
+func (m Model) pollPrintJob(id int64, seq int) tea.Cmd {
+	client := m.client
+	return tea.Tick(printJobPollInterval, func(time.Time) tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+		defer cancel()
+
+		job, err := client.GetPrintJobWithContext(ctx, id)
+		if err != nil {
+			return errMsg{err}
+		}
+		return printJobPolledMsg{job: job, seq: seq}
+	})
+}
+
 // Customer CRUD commands with timeout context
 func (m Model) createCustomer(req *api.CreateCustomerRequest) tea.Cmd {
 	client := m.client
@@ -204,14 +253,20 @@ func (m Model) generateContract(id int64) tea.Cmd {
 	}
 }
 
-// createPrintJob creates a print job with the specified format
-func (m Model) createPrintJob(id int64, format string) tea.Cmd {
+// createPrintJob creates a print job with the specified format. With
+// regenerate set, a contract changed since its latest generation is
+// generated again first; otherwise a stale contract yields a stalePrintMsg.
+func (m Model) createPrintJob(id int64, format string, regenerate bool) tea.Cmd {
 	client := m.client
 	return func() tea.Msg {
 		ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
 		defer cancel()
 
-		_, err := client.CreatePrintJobWithContext(ctx, id, format)
+		_, err := client.CreatePrintJobWithContext(ctx, id, format, regenerate)
+		var stale *api.StaleGenerationError
+		if errors.As(err, &stale) {
+			return stalePrintMsg{contractID: id, err: err}
+		}
 		if err != nil {
 			return errMsg{err}
 		}
@@ -219,6 +274,34 @@ func (m Model) createPrintJob(id int64, format string) tea.Cmd {
 	}
 }
 
+// retryPrintJob requeues a failed print job
+func (m Model) retryPrintJob(id int64) tea.Cmd {
+	client := m.client
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+		defer cancel()
+
+		if _, err := client.RetryPrintJobWithContext(ctx, id); err != nil {
+			return errMsg{err}
+		}
+		return successMsg{"Print job requeued"}
+	}
+}
+
+// cancelPrintJob cancels a print job that has not finished
+func (m Model) cancelPrintJob(id int64) tea.Cmd {
+	client := m.client
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+		defer cancel()
+
+		if _, err := client.CancelPrintJobWithContext(ctx, id); err != nil {
+			return errMsg{err}
+		}
+		return successMsg{"Print job cancelled"}
+	}
+}
+
 func (m Model) signContract(id int64) tea.Cmd {
 	client := m.client
 	signer := m.signer
@@ -232,3 +315,68 @@ func (m Model) signContract(id int64) tea.Cmd {
 		return successMsg{"Contract signed"}
 	}
 }
+
+// fetchNotifications loads the first page of the user's notifications,
+// unread first, and the number unread for the sidebar
+func (m Model) fetchNotifications() tea.Cmd {
+	client := m.client
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+		defer cancel()
+
+		res, err := client.ListNotifications(ctx, nil)
+		if err != nil {
+			return errMsg{err}
+		}
+		unread, err := client.UnreadNotifications(ctx)
+		if err != nil {
+			return errMsg{err}
+		}
+		return fetchNotificationsMsg{notifications: res.Items, unread: unread}
+	}
+}
+
+// markNotificationRead marks a notification read; the list was already
+// updated when it was opened, so only failures are reported
+func (m Model) markNotificationRead(id int64) tea.Cmd {
+	client := m.client
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+		defer cancel()
+
+		if _, err := client.MarkNotificationRead(ctx, id); err != nil {
+			return errMsg{err}
+		}
+		return nil
+	}
+}
+
+// markAllNotificationsRead marks all of the user's notifications read
+func (m Model) markAllNotificationsRead() tea.Cmd {
+	client := m.client
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+		defer cancel()
+
+		marked, err := client.MarkAllNotificationsRead(ctx)
+		if err != nil {
+			return errMsg{err}
+		}
+		return successMsg{fmt.Sprintf("Marked %d notifications read", marked)}
+	}
+}
+
+// openPrintJob fetches a print job to show its detail view
+func (m Model) openPrintJob(id int64) tea.Cmd {
+	client := m.client
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+		defer cancel()
+
+		job, err := client.GetPrintJobWithContext(ctx, id)
+		if err != nil {
+			return errMsg{err}
+		}
+		return printJobOpenedMsg{job}
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/zlovtnik/gprint/cmd/ui/api"
+	"github.com/zlovtnik/gprint/cmd/ui/ui"
+)
+
+// Entities a pendingAction can delete
+const (
+	entityCustomer = "customer"
+	entityService  = "service"
+)
+
+// pendingAction is a delete waiting for the user to confirm it in a dialog
+type pendingAction struct {
+	entity string
+	id     int64
+	name   string
+
+	// dependents counts the customer's contracts once fetched; -1 while
+	// loading or when the count failed
+	dependents int
+	countErr   error
+}
+
+type dependentsMsg struct {
+	entity string
+	id     int64
+	count  int
+	err    error
+}
+
+// confirmDelete opens the delete dialog for a record. A customer's
+// contracts are counted in the background so the dialog can show them.
+func (m Model) confirmDelete(entity string, id int64, name string) (tea.Model, tea.Cmd) {
+	m.pendingAction = &pendingAction{entity: entity, id: id, name: name, dependents: -1}
+	if entity == entityCustomer {
+		return m, m.countCustomerContracts(id)
+	}
+	return m, nil
+}
+
+// countCustomerContracts fetches how many contracts refer to a customer
+func (m Model) countCustomerContracts(id int64) tea.Cmd {
+	client := m.client
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+		defer cancel()
+
+		res, err := client.ListContractsWithContext(ctx, &api.ListOptions{Page: 1, Limit: 1, CustomerID: id})
+		if err != nil {
+			return dependentsMsg{entity: entityCustomer, id: id, err: err}
+		}
+		return dependentsMsg{entity: entityCustomer, id: id, count: res.Total}
+	}
+}
+
+// handleDependents fills in the dependency count of the open dialog
+func (m Model) handleDependents(msg dependentsMsg) Model {
+	p := m.pendingAction
+	if p == nil || p.entity != msg.entity || p.id != msg.id {
+		return m
+	}
+	action := *p
+	action.dependents = msg.count
+	action.countErr = msg.err
+	m.pendingAction = &action
+	return m
+}
+
+// handlePendingKey answers the delete dialog: "y" deletes the record and
+// returns to its list, any other key cancels
+func (m Model) handlePendingKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	action := m.pendingAction
+	m.pendingAction = nil
+	if msg.String() != "y" {
+		m.message = "Delete cancelled"
+		m.messageType = ui.MessageTypeInfo
+		return m, nil
+	}
+
+	m.cursor = 0
+	switch action.entity {
+	case entityCustomer:
+		m.view = ui.ViewCustomers
+		m.selectedCustomer = nil
+		return m, m.deleteCustomer(action.id)
+	case entityService:
+		m.view = ui.ViewServices
+		m.selectedService = nil
+		return m, m.deleteService(action.id)
+	}
+	return m, nil
+}
+
+// renderPendingAction renders the delete dialog
+func (m Model) renderPendingAction() string {
+	p := m.pendingAction
+	var b strings.Builder
+	b.WriteString(ui.DialogTitleStyle.Render("Delete "+p.entity) + "\n\n")
+	b.WriteString(fmt.Sprintf("Delete %s %q?\n", p.entity, p.name))
+	if p.entity == entityCustomer {
+		switch {
+		case p.countErr != nil:
+			b.WriteString(ui.WarningStyle.Render("Could not count its contracts: "+p.countErr.Error()) + "\n")
+		case p.dependents < 0:
+			b.WriteString(ui.MenuDisabledStyle.Render("Counting contracts...") + "\n")
+		case p.dependents > 0:
+			b.WriteString(ui.WarningStyle.Render(fmt.Sprintf("%d contract(s) refer to this customer", p.dependents)) + "\n")
+		}
+	}
+	b.WriteString("\n" + ui.FooterKeyStyle.Render("y") + " Delete   " + ui.FooterKeyStyle.Render("any other key") + " Cancel")
+	return ui.DialogStyle.Render(b.String())
+}
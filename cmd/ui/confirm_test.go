@@ -0,0 +1,124 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/zlovtnik/gprint/cmd/ui/api"
+	"github.com/zlovtnik/gprint/cmd/ui/ui"
+)
+
+// deleteAPI serves three contracts of customer 5, fails to count those of
+// customer 6 and accepts deletes
+func deleteAPI(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodDelete:
+			writeData(t, w, nil)
+		case r.URL.Path == "/api/v1/contracts" && r.URL.Query().Get("customer_id") == "5":
+			writePage(t, w, r, []api.Contract{{ID: 1}, {ID: 2}, {ID: 3}})
+		default:
+			http.Error(w, `{"success":false,"error":{"code":"INTERNAL_ERROR","message":"database unavailable"}}`, http.StatusInternalServerError)
+		}
+	}
+}
+
+// customerDetail returns a model showing customer id's detail view
+func customerDetail(t *testing.T, id int64) (Model, *fakeAPI) {
+	t.Helper()
+	m, f := newTestModel(t, deleteAPI(t))
+	m.view = ui.ViewCustomerDetail
+	m.selectedCustomer = &api.Customer{ID: id, Name: "Alice Ltd"}
+	return m, f
+}
+
+func TestDeleteAsksFirst(t *testing.T) {
+	m, f := customerDetail(t, 5)
+	m, cmd := press(t, m, "d")
+	if m.pendingAction == nil || m.view != ui.ViewCustomerDetail {
+		t.Fatal("d deleted without opening the dialog")
+	}
+	if out := m.renderPendingAction(); !strings.Contains(out, `Delete customer "Alice Ltd"?`) || !strings.Contains(out, "Counting contracts...") {
+		t.Errorf("dialog renders:\n%s", out)
+	}
+
+	m = run(t, m, cmd)
+	if want := "GET /api/v1/contracts?page=1&page_size=1&customer_id=5"; f.last() != want {
+		t.Errorf("counted contracts with %q, want %q", f.last(), want)
+	}
+	if out := m.renderPendingAction(); !strings.Contains(out, "3 contract(s) refer to this customer") {
+		t.Errorf("dialog renders:\n%s", out)
+	}
+	if footer := m.renderFooter(120); !strings.Contains(footer, "Cancel") {
+		t.Errorf("footer = %q, want the dialog keys", footer)
+	}
+
+	// Keys answer the dialog rather than acting on the view
+	m, cmd = press(t, m, "q")
+	if cmd != nil || m.pendingAction != nil || m.view != ui.ViewCustomerDetail || m.message != "Delete cancelled" {
+		t.Errorf("q in the dialog: view %v, message %q; want the delete cancelled", m.view, m.message)
+	}
+	if f.last() != "GET /api/v1/contracts?page=1&page_size=1&customer_id=5" {
+		t.Errorf("cancelling sent %q", f.last())
+	}
+}
+
+func TestDeleteConfirmed(t *testing.T) {
+	m, f := customerDetail(t, 5)
+	m.cursor = 1 // Delete
+	m, _ = press(t, m, "enter")
+	if m.pendingAction == nil {
+		t.Fatal("the Delete action deleted without opening the dialog")
+	}
+
+	m, cmd := press(t, m, "y")
+	if m.pendingAction != nil || m.view != ui.ViewCustomers || m.selectedCustomer != nil {
+		t.Errorf("after y: view %v, selected %v; want back on the list", m.view, m.selectedCustomer)
+	}
+	m = run(t, m, cmd)
+	if f.last() != "DELETE /api/v1/customers/5" || m.message != "Customer deleted successfully" {
+		t.Errorf("y sent %q with message %q", f.last(), m.message)
+	}
+}
+
+func TestDeleteDialogCountFails(t *testing.T) {
+	m, _ := customerDetail(t, 6)
+	m, cmd := press(t, m, "d")
+	m = run(t, m, cmd)
+	if out := m.renderPendingAction(); !strings.Contains(out, "Could not count its contracts") {
+		t.Errorf("dialog renders:\n%s", out)
+	}
+	// The delete can still be confirmed
+	if _, cmd := press(t, m, "y"); cmd == nil {
+		t.Error("y after a failed count did not delete")
+	}
+}
+
+func TestDeleteDialogIgnoresOtherCounts(t *testing.T) {
+	m, _ := customerDetail(t, 5)
+	m, _ = press(t, m, "d")
+	m = m.handleDependents(dependentsMsg{entity: entityCustomer, id: 9, count: 4})
+	if m.pendingAction.dependents != -1 {
+		t.Errorf("the count of customer 9 was taken for customer 5: %d", m.pendingAction.dependents)
+	}
+}
+
+func TestDeleteServiceAsksFirst(t *testing.T) {
+	m, f := newTestModel(t, deleteAPI(t))
+	m.view = ui.ViewServiceDetail
+	m.selectedService = &api.Service{ID: 3, Name: "Hosting"}
+
+	m, cmd := press(t, m, "d")
+	if cmd != nil || m.pendingAction == nil {
+		t.Fatal("d on a service did not open the dialog alone")
+	}
+	if out := m.renderPendingAction(); !strings.Contains(out, `Delete service "Hosting"?`) || strings.Contains(out, "contract") {
+		t.Errorf("dialog renders:\n%s", out)
+	}
+	m, cmd = press(t, m, "y")
+	m = run(t, m, cmd)
+	if f.last() != "DELETE /api/v1/services/3" || m.view != ui.ViewServices {
+		t.Errorf("y sent %q, view %v", f.last(), m.view)
+	}
+}
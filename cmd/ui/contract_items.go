@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/shopspring/decimal"
+	"github.com/zlovtnik/gprint/cmd/ui/api"
+	"github.com/zlovtnik/gprint/cmd/ui/ui"
+)
+
+const (
+	// contractDetailChrome is the rows the contract detail view uses above
+	// and below the items table: the card, the actions and the subtotal
+	contractDetailChrome = 44
+	// contractItemsMinRows is the fewest item rows shown however small the window
+	contractItemsMinRows = 3
+)
+
+type contractItemsMsg struct {
+	contractID int64
+	items      []api.ContractItem
+}
+
+// fetchContractItems loads the items of the contract shown in the detail view
+func (m Model) fetchContractItems(contractID int64) tea.Cmd {
+	client := m.client
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+		defer cancel()
+
+		items, err := client.GetContractItemsWithContext(ctx, contractID)
+		if err != nil {
+			return errMsg{err}
+		}
+		return contractItemsMsg{contractID: contractID, items: items}
+	}
+}
+
+// handleContractItems attaches fetched items to the selected contract,
+// unless the user has moved on to another one
+func (m Model) handleContractItems(msg contractItemsMsg) Model {
+	if m.selectedContract == nil || m.selectedContract.ID != msg.contractID {
+		return m
+	}
+	contract := *m.selectedContract
+	contract.Items = msg.items
+	if contract.Items == nil {
+		contract.Items = []api.ContractItem{}
+	}
+	m.selectedContract = &contract
+	m.contractItemOffset = 0
+	return m
+}
+
+// contractItems returns the items of the selected contract
+func (m Model) contractItems() []api.ContractItem {
+	if m.selectedContract == nil {
+		return nil
+	}
+	return m.selectedContract.Items
+}
+
+// contractItemRows returns how many item rows fit in the window
+func (m Model) contractItemRows() int {
+	return max(contractItemsMinRows, m.height-ui.HeaderHeight-ui.FooterHeight-contractDetailChrome)
+}
+
+// followItemCursor scrolls the items table so the cursor, which moves
+// through the items after the detail actions, stays in view
+func (m Model) followItemCursor() Model {
+	if m.view != ui.ViewContractDetail {
+		return m
+	}
+	row := m.cursor - len(contractDetailActions(m))
+	rows := m.contractItemRows()
+	switch {
+	case row < 0:
+		m.contractItemOffset = 0
+	case row < m.contractItemOffset:
+		m.contractItemOffset = row
+	case row >= m.contractItemOffset+rows:
+		m.contractItemOffset = row - rows + 1
+	}
+	return m
+}
+
+// serviceName returns the name of a service from the loaded service list,
+// falling back to the item description and then the service ID
+func (m Model) serviceName(item api.ContractItem) string {
+	for _, s := range m.services {
+		if s.ID == item.ServiceID {
+			return s.Name
+		}
+	}
+	if item.Description != "" {
+		return item.Description
+	}
+	return fmt.Sprintf("#%d", item.ServiceID)
+}
+
+// renderContractItems renders the items table of the contract detail view
+// and a subtotal, warning when the items do not add up to the total value
+func (m Model) renderContractItems() string {
+	c := m.selectedContract
+	var b strings.Builder
+	b.WriteString(ui.CardSectionStyle.Render("☰ Items") + "\n")
+	if c.Items == nil {
+		b.WriteString(ui.MenuDisabledStyle.Render("Loading items...") + "\n")
+		return b.String()
+	}
+	if len(c.Items) == 0 {
+		b.WriteString(ui.MenuDisabledStyle.Render("No items") + "\n")
+	} else {
+		b.WriteString(ui.TableHeaderStyle.Render(fmt.Sprintf("  %-24s %8s %12s %7s %12s",
+			"Service", "Qty", "Unit Price", "Disc %", "Line Total")) + "\n")
+
+		first := len(contractDetailActions(m))
+		rows := m.contractItemRows()
+		end := min(len(c.Items), m.contractItemOffset+rows)
+		if m.contractItemOffset > 0 {
+			b.WriteString(ui.MenuDisabledStyle.Render(fmt.Sprintf("↑ %d more", m.contractItemOffset)) + "\n")
+		}
+		for i := m.contractItemOffset; i < end; i++ {
+			item := c.Items[i]
+			cursor, style := "  ", ui.TableRowStyle
+			if m.cursor == first+i {
+				cursor, style = ui.CursorStyle.Render("▸ "), ui.TableSelectedRowStyle
+			}
+			b.WriteString(cursor + style.Render(fmt.Sprintf("%-24s %8s %12s %7s %12s",
+				truncate(m.serviceName(item), 24),
+				item.Quantity.String(),
+				item.UnitPrice.StringFixed(2),
+				item.DiscountPct.String(),
+				item.LineTotal.StringFixed(2))) + "\n")
+		}
+		if end < len(c.Items) {
+			b.WriteString(ui.MenuDisabledStyle.Render(fmt.Sprintf("↓ %d more", len(c.Items)-end)) + "\n")
+		}
+	}
+
+	// The contract total is the items' subtotal less the contract discount
+	// plus tax
+	subtotal := decimal.Zero
+	for _, item := range c.Items {
+		subtotal = subtotal.Add(item.LineTotal)
+	}
+	total := subtotal.Sub(c.DiscountAmount).Add(c.TaxAmount)
+	line := fmt.Sprintf("  Subtotal %s", subtotal.StringFixed(2))
+	if !c.DiscountAmount.IsZero() || !c.TaxAmount.IsZero() {
+		line += fmt.Sprintf(" · Discount %s · Tax %s", c.DiscountAmount.StringFixed(2), c.TaxAmount.StringFixed(2))
+	}
+	if total.Equal(c.TotalValue) {
+		b.WriteString(ui.InfoStyle.Render(line) + "\n")
+	} else {
+		b.WriteString(ui.WarningStyle.Render(fmt.Sprintf("%s ≠ Total Value %s", line, c.TotalValue.StringFixed(2))) + "\n")
+	}
+	return b.String()
+}
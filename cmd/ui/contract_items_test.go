@@ -0,0 +1,179 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/zlovtnik/gprint/cmd/ui/api"
+	"github.com/zlovtnik/gprint/cmd/ui/ui"
+)
+
+// itemsAPI serves ten items of 100.00 for contract 7 and none for contract 8
+func itemsAPI(t *testing.T) http.HandlerFunc {
+	items := make([]api.ContractItem, 10)
+	for i := range items {
+		items[i] = api.ContractItem{
+			ID:        int64(i + 1),
+			ServiceID: int64(i + 1),
+			Quantity:  decimal.NewFromInt(1),
+			UnitPrice: decimal.NewFromInt(100),
+			LineTotal: decimal.NewFromInt(100),
+		}
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/contracts/7/items":
+			writeData(t, w, items)
+		case "/api/v1/contracts/8/items":
+			writeData(t, w, []api.ContractItem{})
+		default:
+			http.NotFound(w, r)
+		}
+	}
+}
+
+// openContract selects contract id from the contract list and loads its items
+func openContract(t *testing.T, m Model, id int64) Model {
+	t.Helper()
+	m.view = ui.ViewContracts
+	m.contracts = []api.Contract{
+		{ID: 7, ContractNumber: "CTR-0007", TotalValue: decimal.NewFromInt(1000)},
+		{ID: 8, ContractNumber: "CTR-0008"},
+	}
+	m.cursor = firstItemRow(ui.ViewContracts) + int(id-7)
+	m, cmd := press(t, m, "enter")
+	if m.view != ui.ViewContractDetail || m.selectedContract.Items != nil {
+		t.Fatalf("opened view %v with items %v, want the detail view loading its items", m.view, m.selectedContract.Items)
+	}
+	return run(t, m, cmd)
+}
+
+func TestContractDetailLoadsItems(t *testing.T) {
+	m, f := newTestModel(t, itemsAPI(t))
+	m = openContract(t, m, 7)
+	if f.last() != "GET /api/v1/contracts/7/items" || len(m.contractItems()) != 10 {
+		t.Fatalf("requested %q and got %d items, want contract 7's ten", f.last(), len(m.contractItems()))
+	}
+	if got := m.getMaxItems(); got != len(contractDetailActions(m))+10 {
+		t.Errorf("%d rows, want the actions then the items", got)
+	}
+
+	out := m.renderContractItems()
+	if !strings.Contains(out, "Subtotal 1000.00") || strings.Contains(out, "≠") {
+		t.Errorf("items adding up to the total render:\n%s", out)
+	}
+	// Unknown services fall back to their ID
+	if !strings.Contains(out, "#1") {
+		t.Errorf("items table lacks service #1:\n%s", out)
+	}
+
+	m = openContract(t, m, 8)
+	if items := m.contractItems(); items == nil || len(items) != 0 {
+		t.Errorf("items of contract 8 = %v, want loaded and empty", items)
+	}
+	if out := m.renderContractItems(); !strings.Contains(out, "No items") {
+		t.Errorf("empty items table renders:\n%s", out)
+	}
+}
+
+func TestContractItemsOfAnotherContractIgnored(t *testing.T) {
+	m, _ := newTestModel(t, itemsAPI(t))
+	m = openContract(t, m, 8)
+
+	// Contract 7's items arrive after the user moved on to contract 8
+	next, _ := m.Update(m.fetchContractItems(7)())
+	m = next.(Model)
+	if len(m.contractItems()) != 0 || m.selectedContract.ID != 8 {
+		t.Errorf("contract %d shows %d items, want contract 8 without items", m.selectedContract.ID, len(m.contractItems()))
+	}
+}
+
+func TestContractItemsLoadingAndMismatch(t *testing.T) {
+	m, _ := newTestModel(t, itemsAPI(t))
+	m.selectedContract = &api.Contract{ID: 7, TotalValue: decimal.NewFromInt(1000)}
+	if out := m.renderContractItems(); !strings.Contains(out, "Loading items...") {
+		t.Errorf("items not yet loaded render:\n%s", out)
+	}
+
+	// 2 × 100 less a discount of 20 plus tax of 10 is 190, not 200
+	m.services = []api.Service{{ID: 1, Name: "Hosting"}}
+	m.selectedContract = &api.Contract{
+		ID:             7,
+		DiscountAmount: decimal.NewFromInt(20),
+		TaxAmount:      decimal.NewFromInt(10),
+		TotalValue:     decimal.NewFromInt(200),
+		Items: []api.ContractItem{
+			{ServiceID: 1, Quantity: decimal.NewFromInt(1), LineTotal: decimal.NewFromInt(100)},
+			{ServiceID: 2, Quantity: decimal.NewFromInt(1), LineTotal: decimal.NewFromInt(100), Description: "Support"},
+		},
+	}
+	out := m.renderContractItems()
+	if !strings.Contains(out, "Subtotal 200.00 · Discount 20.00 · Tax 10.00 ≠ Total Value 200.00") {
+		t.Errorf("items not adding up render without the warning:\n%s", out)
+	}
+	if !strings.Contains(out, "Hosting") || !strings.Contains(out, "Support") {
+		t.Errorf("items table lacks the service name or description:\n%s", out)
+	}
+
+	m.selectedContract.TotalValue = decimal.NewFromInt(190)
+	if out := m.renderContractItems(); strings.Contains(out, "≠") {
+		t.Errorf("items adding up render a warning:\n%s", out)
+	}
+}
+
+func TestContractItemsScrollWithCursor(t *testing.T) {
+	m, _ := newTestModel(t, itemsAPI(t))
+	m = openContract(t, m, 7)
+	actions := len(contractDetailActions(m))
+	rows := m.contractItemRows()
+	if rows != contractItemsMinRows {
+		t.Fatalf("%d item rows fit in a %d-row window, want %d", rows, m.height, contractItemsMinRows)
+	}
+
+	// Down through the actions and the rows that fit leaves the table still
+	for range actions + rows - 1 {
+		m, _ = press(t, m, "down")
+	}
+	if m.cursor != actions+rows-1 || m.contractItemOffset != 0 {
+		t.Errorf("on the last visible item: cursor %d, offset %d", m.cursor, m.contractItemOffset)
+	}
+	m, _ = press(t, m, "down")
+	if m.contractItemOffset != 1 {
+		t.Errorf("moving past the last visible item scrolled to %d, want 1", m.contractItemOffset)
+	}
+	out := m.renderContractItems()
+	if !strings.Contains(out, "↑ 1 more") || !strings.Contains(out, "↓ 6 more") {
+		t.Errorf("scrolled table renders:\n%s", out)
+	}
+
+	// The cursor stops on the last item, which stays in view
+	for range 20 {
+		m, _ = press(t, m, "down")
+	}
+	if m.cursor != actions+9 || m.contractItemOffset != 10-rows {
+		t.Errorf("at the end: cursor %d, offset %d; want %d, %d", m.cursor, m.contractItemOffset, actions+9, 10-rows)
+	}
+
+	// Moving up above the first visible item scrolls back, and into the
+	// actions shows the top of the table
+	for range rows {
+		m, _ = press(t, m, "up")
+	}
+	if m.contractItemOffset != 10-rows-1 {
+		t.Errorf("moving above the first visible item scrolled to %d, want %d", m.contractItemOffset, 10-rows-1)
+	}
+	for range 20 {
+		m, _ = press(t, m, "up")
+	}
+	if m.cursor != 0 || m.contractItemOffset != 0 {
+		t.Errorf("back on the actions: cursor %d, offset %d", m.cursor, m.contractItemOffset)
+	}
+
+	// A taller window fits more rows
+	m.height = 60
+	if got := m.contractItemRows(); got != 60-ui.HeaderHeight-ui.FooterHeight-contractDetailChrome {
+		t.Errorf("%d item rows fit in a 60-row window", got)
+	}
+}
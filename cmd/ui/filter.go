@@ -0,0 +1,165 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/zlovtnik/gprint/cmd/ui/api"
+	"github.com/zlovtnik/gprint/cmd/ui/ui"
+)
+
+// listFilter is the search bar of a list view, opened with "/". While it is
+// being edited the loaded rows are filtered as the user types; Enter searches
+// on the server, so rows beyond the loaded page are found too.
+type listFilter struct {
+	view    ui.ViewState
+	input   textinput.Model
+	editing bool
+	query   string // search applied on the server; empty when none
+}
+
+// filterable reports whether a list view can be searched on the server
+func filterable(view ui.ViewState) bool {
+	return view == ui.ViewCustomers || view == ui.ViewServices || view == ui.ViewContracts
+}
+
+// filterText returns the filter shown in the current view: the text being
+// typed, or else the search applied
+func (m Model) filterText() string {
+	if m.filter.view != m.view {
+		return ""
+	}
+	if m.filter.editing {
+		return m.filter.input.Value()
+	}
+	return m.filter.query
+}
+
+// searchQuery returns the server search applied to a list view
+func (m Model) searchQuery(view ui.ViewState) string {
+	if m.filter.view != view {
+		return ""
+	}
+	return m.filter.query
+}
+
+// openFilter opens the search bar of the current list, holding the search applied
+func (m Model) openFilter() (tea.Model, tea.Cmd) {
+	if !filterable(m.view) || m.focusOnSidebar {
+		return m, nil
+	}
+	input := textinput.New()
+	input.Placeholder = "Search"
+	input.Prompt = "/ "
+	input.SetValue(m.searchQuery(m.view))
+	input.Focus()
+	m.filter = listFilter{view: m.view, input: input, editing: true, query: m.searchQuery(m.view)}
+	m.cursor = firstItemRow(m.view)
+	return m, textinput.Blink
+}
+
+// handleFilterKey handles keys while the search bar is being edited. Enter
+// searches on the server and Esc clears the filter.
+func (m Model) handleFilterKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		return m.clearFilter()
+	case "enter":
+		query := strings.TrimSpace(m.filter.input.Value())
+		if query == "" {
+			return m.clearFilter()
+		}
+		m.filter.editing = false
+		m.filter.input.Blur()
+		m.filter.query = query
+		m.cursor = firstItemRow(m.view)
+		return m, m.fetchListPage(m.view, 1)
+	}
+
+	var cmd tea.Cmd
+	m.filter.input, cmd = m.filter.input.Update(msg)
+	m.cursor = firstItemRow(m.view)
+	return m, cmd
+}
+
+// clearFilter closes the search bar and, when a search was applied, reloads
+// the unfiltered list from its first page
+func (m Model) clearFilter() (tea.Model, tea.Cmd) {
+	searched := m.filter.query != ""
+	view := m.filter.view
+	m.filter = listFilter{}
+	m.cursor = firstItemRow(m.view)
+	if searched && view == m.view {
+		return m, m.fetchListPage(view, 1)
+	}
+	return m, nil
+}
+
+// renderFilterBar returns the search bar shown above a list, if any
+func (m Model) renderFilterBar() string {
+	if m.filter.view != m.view {
+		return ""
+	}
+	if m.filter.editing {
+		return m.filter.input.View() + "\n" +
+			ui.InfoStyle.Render("Enter searches all pages, Esc clears") + "\n\n"
+	}
+	if m.filter.query != "" {
+		return ui.InfoStyle.Render("Search: "+m.filter.query+" (/ to change, Esc to clear)") + "\n\n"
+	}
+	return ""
+}
+
+// matchesFilter reports whether any of fields contains text, ignoring case
+func matchesFilter(text string, fields ...string) bool {
+	text = strings.ToLower(text)
+	for _, f := range fields {
+		if strings.Contains(strings.ToLower(f), text) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterRows returns the rows matching the text being typed in the search
+// bar of view; rows are returned unchanged when the bar is not being edited
+func filterRows[T any](m Model, view ui.ViewState, rows []T, fields func(T) []string) []T {
+	if m.filter.view != view || !m.filter.editing {
+		return rows
+	}
+	text := strings.TrimSpace(m.filter.input.Value())
+	if text == "" {
+		return rows
+	}
+	var matched []T
+	for _, row := range rows {
+		if matchesFilter(text, fields(row)...) {
+			matched = append(matched, row)
+		}
+	}
+	return matched
+}
+
+// visibleCustomers returns the customer rows shown, narrowed by the search bar
+func (m Model) visibleCustomers() []api.Customer {
+	return filterRows(m, ui.ViewCustomers, m.customers, func(c api.Customer) []string {
+		return []string{c.CustomerCode, c.Name, c.TradeName, c.TaxID, c.Email}
+	})
+}
+
+// visibleServices returns the service rows shown, narrowed by the search bar
+func (m Model) visibleServices() []api.Service {
+	return filterRows(m, ui.ViewServices, m.services, func(s api.Service) []string {
+		return []string{s.ServiceCode, s.Name, s.Category, s.Description}
+	})
+}
+
+// visibleContracts returns the contract rows shown, narrowed by the search bar
+func (m Model) visibleContracts() []api.Contract {
+	return filterRows(m, ui.ViewContracts, m.contracts, func(c api.Contract) []string {
+		return []string{c.ContractNumber, c.ContractType, c.Status}
+	})
+}
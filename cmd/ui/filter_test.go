@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/zlovtnik/gprint/cmd/ui/api"
+	"github.com/zlovtnik/gprint/cmd/ui/ui"
+)
+
+// searchAPI serves 45 numbered customers and three named ones, searching
+// their names for q as the server does
+func searchAPI(t *testing.T) http.HandlerFunc {
+	customers := []api.Customer{
+		{ID: 101, CustomerCode: "ACME", Name: "Alice Ltd"},
+		{ID: 102, CustomerCode: "BOB", Name: "Bob's Garage", Email: "bob@example.com"},
+		{ID: 103, CustomerCode: "MLK", Name: "Malik & Sons"},
+	}
+	for i := 1; i <= 45; i++ {
+		customers = append(customers, api.Customer{ID: int64(i), CustomerCode: fmt.Sprintf("C%03d", i), Name: fmt.Sprintf("Customer %d", i)})
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := strings.ToLower(r.URL.Query().Get("q"))
+		var matched []api.Customer
+		for _, c := range customers {
+			if strings.Contains(strings.ToLower(c.Name), q) {
+				matched = append(matched, c)
+			}
+		}
+		writePage(t, w, r, matched)
+	}
+}
+
+// searchableList returns a model showing the first page of searchAPI's customers
+func searchableList(t *testing.T) (Model, *fakeAPI) {
+	t.Helper()
+	m, f := newTestModel(t, searchAPI(t))
+	m.view = ui.ViewCustomers
+	return run(t, m, m.fetchCustomers(1)), f
+}
+
+func customerNames(customers []api.Customer) []string {
+	var names []string
+	for _, c := range customers {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+func TestFilterNarrowsLoadedRows(t *testing.T) {
+	m, f := searchableList(t)
+	loaded := f.last()
+
+	m, _ = press(t, m, "/", "A", "l", "I")
+	if got := customerNames(m.visibleCustomers()); !slices.Equal(got, []string{"Alice Ltd", "Malik & Sons"}) {
+		t.Errorf("typing ALI shows %q, want the names containing it in any case", got)
+	}
+	if f.last() != loaded {
+		t.Errorf("typing sent %q; the loaded rows should be narrowed without a request", f.last())
+	}
+	if crumbs := m.getBreadcrumb(); crumbs[len(crumbs)-1] != `Filter: "AlI"` {
+		t.Errorf("breadcrumb = %q, want the filter last", crumbs)
+	}
+	if m.cursor != firstItemRow(ui.ViewCustomers) || m.getMaxItems() != 4 {
+		t.Errorf("cursor %d of %d rows, want the first of two items between Create and Back", m.cursor, m.getMaxItems())
+	}
+
+	// Shortcut keys are typed into the search bar
+	m, _ = press(t, m, "backspace", "backspace", "backspace", "b", "o", "b", "@", "n", "d", "r")
+	if m.view != ui.ViewCustomers || m.filter.input.Value() != "bob@ndr" {
+		t.Errorf("view %v with %q in the search bar, want the keys typed", m.view, m.filter.input.Value())
+	}
+	m, _ = press(t, m, "backspace", "backspace", "backspace", "backspace")
+	if got := customerNames(m.visibleCustomers()); !slices.Equal(got, []string{"Bob's Garage"}) {
+		t.Errorf("typing bob shows %q", got)
+	}
+}
+
+func TestFilterEnterSearchesServer(t *testing.T) {
+	m, f := searchableList(t)
+
+	m, cmd := press(t, m, "/", "c", "u", "s", "t", "o", "m", "e", "r", "enter")
+	m = run(t, m, cmd)
+	if want := "GET /api/v1/customers?page=1&page_size=20&q=customer"; f.last() != want {
+		t.Errorf("Enter requested %q, want %q", f.last(), want)
+	}
+	if m.filter.editing || m.searchQuery(ui.ViewCustomers) != "customer" {
+		t.Errorf("filter = %+v, want the search applied and the bar closed", m.filter)
+	}
+	if got := m.pageStatus(); got != "Page 1/3 · 45 items" {
+		t.Errorf("pageStatus = %q, want the matches across pages", got)
+	}
+	if crumbs := m.getBreadcrumb(); crumbs[len(crumbs)-1] != `Filter: "customer"` {
+		t.Errorf("breadcrumb = %q, want the search last", crumbs)
+	}
+
+	// Paging keeps the search
+	m, cmd = press(t, m, "pgdown")
+	m = run(t, m, cmd)
+	if want := "GET /api/v1/customers?page=2&page_size=20&q=customer"; f.last() != want {
+		t.Errorf("PgDn requested %q, want %q", f.last(), want)
+	}
+
+	// "/" reopens the bar holding the search
+	m, _ = press(t, m, "/")
+	if !m.filter.editing || m.filter.input.Value() != "customer" {
+		t.Errorf("reopened search bar holds %q", m.filter.input.Value())
+	}
+}
+
+func TestFilterEscClearsSearch(t *testing.T) {
+	m, f := searchableList(t)
+	m, cmd := press(t, m, "/", "b", "o", "b", "enter")
+	m = run(t, m, cmd)
+	if len(m.customers) != 1 {
+		t.Fatalf("search for bob loaded %d customers, want 1", len(m.customers))
+	}
+
+	m, cmd = press(t, m, "esc")
+	m = run(t, m, cmd)
+	if want := "GET /api/v1/customers?page=1&page_size=20"; f.last() != want {
+		t.Errorf("Esc requested %q, want %q", f.last(), want)
+	}
+	if m.view != ui.ViewCustomers || m.filterText() != "" || len(m.customers) != 20 {
+		t.Errorf("after Esc: view %v, filter %q, %d customers; want the unfiltered first page", m.view, m.filterText(), len(m.customers))
+	}
+	if crumbs := m.getBreadcrumb(); len(crumbs) != 2 {
+		t.Errorf("breadcrumb = %q, want no filter", crumbs)
+	}
+
+	// Esc while typing, with no search applied, only closes the bar
+	m, _ = press(t, m, "/", "x")
+	if m, cmd = press(t, m, "esc"); cmd != nil || m.filter.editing {
+		t.Error("Esc while typing fetched the list again or left the bar open")
+	}
+	if m, _ = press(t, m, "esc"); m.view != ui.ViewMain {
+		t.Errorf("Esc without a filter went to %v, want the main view", m.view)
+	}
+}
+
+func TestFilterDroppedWhenSwitchingViews(t *testing.T) {
+	m, f := searchableList(t)
+	m, cmd := press(t, m, "/", "b", "o", "b", "enter")
+	m = run(t, m, cmd)
+
+	m, _ = press(t, m, "q")
+	m.cursor = slices.IndexFunc(m.mainMenuItems(), func(item ui.MenuItem) bool { return item.View == ui.ViewCustomers })
+	m, cmd = press(t, m, "enter")
+	m = run(t, m, cmd)
+	if want := "GET /api/v1/customers?page=1&page_size=20"; f.last() != want {
+		t.Errorf("reopening customers requested %q, want %q", f.last(), want)
+	}
+	if m.filterText() != "" {
+		t.Errorf("filter %q survived switching views", m.filterText())
+	}
+}
+
+func TestFilterOnlyInSearchableLists(t *testing.T) {
+	m, _ := newTestModel(t, searchAPI(t))
+	for _, view := range []ui.ViewState{ui.ViewPrintJobs, ui.ViewMain, ui.ViewSettings} {
+		m.view = view
+		if got, _ := press(t, m, "/"); got.filter.editing {
+			t.Errorf("/ opened a search bar in view %v", view)
+		}
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/zlovtnik/gprint/cmd/ui/api"
@@ -19,11 +21,24 @@ type loginMsg struct {
 	err  error
 }
 
+// profileMsg is sent when the user's profile has been fetched
+type profileMsg struct {
+	profile *api.UserProfile
+	err     error
+}
+
+// featuresMsg is sent when the tenant's feature flags have been fetched
+type featuresMsg struct {
+	flags []api.FeatureFlag
+	err   error
+}
+
 // SidebarItem represents an item in the sidebar menu
 type SidebarItem struct {
-	Icon  string
-	Title string
-	View  ui.ViewState
+	Icon    string
+	Title   string
+	View    ui.ViewState
+	Feature string // Feature flag the item needs; empty for items always shown
 }
 
 // getSidebarItems returns the sidebar menu items
@@ -34,10 +49,44 @@ func getSidebarItems() []SidebarItem {
 		{Icon: "🛠", Title: "Services", View: ui.ViewServices},
 		{Icon: "📄", Title: "Contracts", View: ui.ViewContracts},
 		{Icon: "🖨", Title: labelPrintJobs, View: ui.ViewPrintJobs},
+		{Icon: "🔔", Title: labelNotifications, View: ui.ViewNotifications},
 		{Icon: "⚙", Title: "Settings", View: ui.ViewSettings},
 	}
 }
 
+// sidebarItems returns the sidebar items of the features enabled for the
+// tenant, with the unread count on Notifications
+func (m Model) sidebarItems() []SidebarItem {
+	var items []SidebarItem
+	for _, item := range getSidebarItems() {
+		if !m.featureEnabled(item.Feature) {
+			continue
+		}
+		if item.View == ui.ViewNotifications && m.unreadNotifications > 0 {
+			item.Title = fmt.Sprintf("%s (%d)", item.Title, m.unreadNotifications)
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// mainMenuItems returns the main menu items of the features enabled for the tenant
+func (m Model) mainMenuItems() []ui.MenuItem {
+	var items []ui.MenuItem
+	for _, item := range ui.GetMainMenuItems() {
+		if m.featureEnabled(item.Feature) {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// featureEnabled reports whether an item needing feature is shown. Gated
+// items stay hidden until the flags have been fetched.
+func (m Model) featureEnabled(feature string) bool {
+	return feature == "" || m.features[feature]
+}
+
 // handleLogin attempts to authenticate with the API
 func (m Model) handleLogin() tea.Cmd {
 	username := m.inputs[0].Value()
@@ -77,7 +126,7 @@ func getParentView(v ui.ViewState) ui.ViewState {
 // getSidebarIndexForView returns the sidebar index for the current view
 func (m Model) getSidebarIndexForView() int {
 	targetView := getParentView(m.view)
-	items := getSidebarItems()
+	items := m.sidebarItems()
 	for i, item := range items {
 		if item.View == targetView {
 			return i
@@ -88,23 +137,26 @@ func (m Model) getSidebarIndexForView() int {
 
 // handleSidebarSelect handles selection from sidebar
 func (m Model) handleSidebarSelect() (tea.Model, tea.Cmd) {
-	items := getSidebarItems()
+	items := m.sidebarItems()
 	if m.sidebarCursor >= 0 && m.sidebarCursor < len(items) {
 		selectedView := items[m.sidebarCursor].View
 		m.view = selectedView
 		m.cursor = 0
 		m.focusOnSidebar = false
+		m.filter = listFilter{}
 
 		// Fetch data for the new view
 		switch selectedView {
 		case ui.ViewCustomers:
-			return m, m.fetchCustomers()
+			return m, m.fetchCustomers(1)
 		case ui.ViewServices:
-			return m, m.fetchServices()
+			return m, m.fetchServices(1)
 		case ui.ViewContracts:
-			return m, m.fetchContracts()
+			return m, m.fetchContracts(1)
 		case ui.ViewPrintJobs:
-			return m, m.fetchPrintJobs()
+			return m, m.fetchPrintJobs(1)
+		case ui.ViewNotifications:
+			return m, m.fetchNotifications()
 		}
 	}
 	return m, nil
@@ -118,7 +170,7 @@ func (m Model) getBreadcrumb() []string {
 	case ui.ViewMain:
 		return []string{"Dashboard"}
 	case ui.ViewCustomers:
-		return []string{"Dashboard", "Customers"}
+		return m.withFilterCrumb("Dashboard", "Customers")
 	case ui.ViewCustomerDetail:
 		if m.selectedCustomer != nil {
 			return []string{"Dashboard", "Customers", m.selectedCustomer.Name}
@@ -129,7 +181,7 @@ func (m Model) getBreadcrumb() []string {
 	case ui.ViewCustomerEdit:
 		return []string{"Dashboard", "Customers", "Edit"}
 	case ui.ViewServices:
-		return []string{"Dashboard", "Services"}
+		return m.withFilterCrumb("Dashboard", "Services")
 	case ui.ViewServiceDetail:
 		if m.selectedService != nil {
 			return []string{"Dashboard", "Services", m.selectedService.Name}
@@ -140,7 +192,7 @@ func (m Model) getBreadcrumb() []string {
 	case ui.ViewServiceEdit:
 		return []string{"Dashboard", "Services", "Edit"}
 	case ui.ViewContracts:
-		return []string{"Dashboard", "Contracts"}
+		return m.withFilterCrumb("Dashboard", "Contracts")
 	case ui.ViewContractDetail:
 		if m.selectedContract != nil {
 			return []string{"Dashboard", "Contracts", m.selectedContract.ContractNumber}
@@ -157,6 +209,8 @@ func (m Model) getBreadcrumb() []string {
 			return []string{"Dashboard", labelPrintJobs, fmt.Sprintf("Job #%d", m.selectedPrintJob.ID)}
 		}
 		return []string{"Dashboard", labelPrintJobs, "Detail"}
+	case ui.ViewNotifications:
+		return []string{"Dashboard", labelNotifications}
 	case ui.ViewSettings:
 		return []string{"Dashboard", "Settings"}
 	default:
@@ -164,6 +218,14 @@ func (m Model) getBreadcrumb() []string {
 	}
 }
 
+// withFilterCrumb appends the list's filter, if any, to a breadcrumb
+func (m Model) withFilterCrumb(crumbs ...string) []string {
+	if text := m.filterText(); text != "" {
+		crumbs = append(crumbs, fmt.Sprintf("Filter: %q", text))
+	}
+	return crumbs
+}
+
 func (m Model) handleEscape() (tea.Model, tea.Cmd) {
 	// Cannot escape from login if not authenticated
 	if m.view == ui.ViewLogin {
@@ -195,7 +257,7 @@ func (m Model) handleEscape() (tea.Model, tea.Cmd) {
 
 func (m Model) handleDown() int {
 	if m.focusOnSidebar {
-		items := getSidebarItems()
+		items := m.sidebarItems()
 		if m.sidebarCursor < len(items)-1 {
 			return m.sidebarCursor + 1
 		}
@@ -224,21 +286,23 @@ func (m Model) handleUp() int {
 func (m Model) getMaxItems() int {
 	switch m.view {
 	case ui.ViewMain:
-		return len(ui.GetMainMenuItems()) + 1 // +1 for Quit
+		return len(m.mainMenuItems()) + 1 // +1 for Quit
 	case ui.ViewCustomers:
-		return len(m.customers) + 2 // +2 for Create and Back
+		return len(m.visibleCustomers()) + 2 // +2 for Create and Back
 	case ui.ViewServices:
-		return len(m.services) + 2
+		return len(m.visibleServices()) + 2
 	case ui.ViewContracts:
-		return len(m.contracts) + 2
+		return len(m.visibleContracts()) + 2
 	case ui.ViewPrintJobs:
 		return len(m.printJobs) + 1 // +1 for Back
+	case ui.ViewNotifications:
+		return len(m.notifications) + 2 // +2 for Mark all read and Back
 	case ui.ViewCustomerDetail, ui.ViewServiceDetail:
 		return 3 // Edit, Delete, Back
 	case ui.ViewContractDetail:
-		return 5 // Edit, Generate, Print, Sign, Back
+		return len(contractDetailActions(m)) + len(m.contractItems()) // Actions, then item rows
 	case ui.ViewPrintJobDetail:
-		return 1 // No actions, just info display
+		return len(printJobDetailActions(m.selectedPrintJob))
 	case ui.ViewCustomerCreate, ui.ViewCustomerEdit,
 		ui.ViewServiceCreate, ui.ViewServiceEdit,
 		ui.ViewContractCreate, ui.ViewContractEdit:
@@ -264,6 +328,8 @@ func (m Model) handleEnter() (tea.Model, tea.Cmd) {
 		return m.handleContractSelect()
 	case ui.ViewPrintJobs:
 		return m.handlePrintJobSelect()
+	case ui.ViewNotifications:
+		return m.handleNotificationSelect()
 	case ui.ViewCustomerCreate, ui.ViewCustomerEdit:
 		return m.handleCustomerFormSubmit()
 	case ui.ViewServiceCreate, ui.ViewServiceEdit:
@@ -276,12 +342,14 @@ func (m Model) handleEnter() (tea.Model, tea.Cmd) {
 		return m.handleServiceDetailAction()
 	case ui.ViewContractDetail:
 		return m.handleContractDetailAction()
+	case ui.ViewPrintJobDetail:
+		return m.handlePrintJobDetailAction()
 	}
 	return m, nil
 }
 
 func (m Model) handleMainMenuSelect() (tea.Model, tea.Cmd) {
-	menuItems := ui.GetMainMenuItems()
+	menuItems := m.mainMenuItems()
 	if m.cursor == len(menuItems) {
 		return m, tea.Quit
 	}
@@ -293,16 +361,17 @@ func (m Model) handleMainMenuSelect() (tea.Model, tea.Cmd) {
 	item := menuItems[m.cursor]
 	m.view = item.View
 	m.cursor = 0
+	m.filter = listFilter{}
 
 	switch item.View {
 	case ui.ViewCustomers:
-		return m, m.fetchCustomers()
+		return m, m.fetchCustomers(1)
 	case ui.ViewServices:
-		return m, m.fetchServices()
+		return m, m.fetchServices(1)
 	case ui.ViewContracts:
-		return m, m.fetchContracts()
+		return m, m.fetchContracts(1)
 	case ui.ViewPrintJobs:
-		return m, m.fetchPrintJobs()
+		return m, m.fetchPrintJobs(1)
 	case ui.ViewSettings:
 		return m, nil
 	}
@@ -313,19 +382,20 @@ func (m Model) handleCustomerSelect() (tea.Model, tea.Cmd) {
 	if m.cursor == 0 {
 		return m.initCustomerForm(nil)
 	}
-	if m.cursor == len(m.customers)+1 {
+	customers := m.visibleCustomers()
+	if m.cursor == len(customers)+1 {
 		m.view = ui.ViewMain
 		m.cursor = 0
 		return m, nil
 	}
 	// Bounds check to prevent panic
 	idx := m.cursor - 1
-	if idx < 0 || idx >= len(m.customers) {
+	if idx < 0 || idx >= len(customers) {
 		m.view = ui.ViewMain
 		m.cursor = 0
 		return m, nil
 	}
-	cust := m.customers[idx]
+	cust := customers[idx]
 	m.selectedCustomer = &cust
 	m.view = ui.ViewCustomerDetail
 	m.cursor = 0
@@ -336,19 +406,20 @@ func (m Model) handleServiceSelect() (tea.Model, tea.Cmd) {
 	if m.cursor == 0 {
 		return m.initServiceForm(nil)
 	}
-	if m.cursor == len(m.services)+1 {
+	services := m.visibleServices()
+	if m.cursor == len(services)+1 {
 		m.view = ui.ViewMain
 		m.cursor = 0
 		return m, nil
 	}
 	// Bounds check to prevent panic
 	idx := m.cursor - 1
-	if idx < 0 || idx >= len(m.services) {
+	if idx < 0 || idx >= len(services) {
 		m.view = ui.ViewMain
 		m.cursor = 0
 		return m, nil
 	}
-	svc := m.services[idx]
+	svc := services[idx]
 	m.selectedService = &svc
 	m.view = ui.ViewServiceDetail
 	m.cursor = 0
@@ -359,23 +430,26 @@ func (m Model) handleContractSelect() (tea.Model, tea.Cmd) {
 	if m.cursor == 0 {
 		return m.initContractForm(nil)
 	}
-	if m.cursor == len(m.contracts)+1 {
+	contracts := m.visibleContracts()
+	if m.cursor == len(contracts)+1 {
 		m.view = ui.ViewMain
 		m.cursor = 0
 		return m, nil
 	}
 	// Bounds check to prevent panic
 	idx := m.cursor - 1
-	if idx < 0 || idx >= len(m.contracts) {
+	if idx < 0 || idx >= len(contracts) {
 		m.view = ui.ViewMain
 		m.cursor = 0
 		return m, nil
 	}
-	contr := m.contracts[idx]
+	contr := contracts[idx]
 	m.selectedContract = &contr
+	m.stalePrintContractID = 0
+	m.contractItemOffset = 0
 	m.view = ui.ViewContractDetail
 	m.cursor = 0
-	return m, nil
+	return m, m.fetchContractItems(contr.ID)
 }
 
 func (m Model) handlePrintJobSelect() (tea.Model, tea.Cmd) {
@@ -390,13 +464,62 @@ func (m Model) handlePrintJobSelect() (tea.Model, tea.Cmd) {
 		m.cursor = 0
 		return m, nil
 	}
-	job := m.printJobs[m.cursor]
+	return m.showPrintJob(m.printJobs[m.cursor])
+}
+
+// showPrintJob opens the detail view of a print job, polling it while it runs
+func (m Model) showPrintJob(job api.PrintJob) (tea.Model, tea.Cmd) {
 	m.selectedPrintJob = &job
 	m.view = ui.ViewPrintJobDetail
 	m.cursor = 0
+	if printJobActive(&job) {
+		m.printJobPollSeq++
+		return m, m.pollPrintJob(job.ID, m.printJobPollSeq)
+	}
 	return m, nil
 }
 
+// handleNotificationSelect marks the chosen notification read and opens the
+// print job it links to; other notifications show their text. The first row
+// marks all notifications read.
+func (m Model) handleNotificationSelect() (tea.Model, tea.Cmd) {
+	if m.cursor == 0 {
+		return m, tea.Sequence(m.markAllNotificationsRead(), m.fetchNotifications())
+	}
+	// Bounds check to prevent panic; the row after the list is Back
+	idx := m.cursor - 1
+	if idx < 0 || idx >= len(m.notifications) {
+		m.view = ui.ViewMain
+		m.cursor = 0
+		return m, nil
+	}
+
+	n := m.notifications[idx]
+	var cmds []tea.Cmd
+	if n.ReadAt == nil {
+		now := time.Now()
+		m.notifications = slices.Clone(m.notifications)
+		m.notifications[idx].ReadAt = &now
+		m.unreadNotifications = max(0, m.unreadNotifications-1)
+		cmds = append(cmds, m.markNotificationRead(n.ID))
+	}
+	if n.EntityType == "print_job" && n.EntityID != nil {
+		cmds = append(cmds, m.openPrintJob(*n.EntityID))
+	} else {
+		m.message = n.Title
+		if n.Body != "" {
+			m.message += ": " + n.Body
+		}
+		m.messageType = ui.MessageTypeInfo
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// printJobActive reports whether a print job is still queued or rendering
+func printJobActive(job *api.PrintJob) bool {
+	return job.Status == "QUEUED" || job.Status == "PROCESSING"
+}
+
 func (m Model) handleCreate() (tea.Model, tea.Cmd) {
 	switch m.view {
 	case ui.ViewCustomers:
@@ -425,11 +548,11 @@ func (m Model) handleDelete() (tea.Model, tea.Cmd) {
 	switch m.view {
 	case ui.ViewCustomerDetail:
 		if m.selectedCustomer != nil {
-			return m, m.deleteCustomer(m.selectedCustomer.ID)
+			return m.confirmDelete(entityCustomer, m.selectedCustomer.ID, m.selectedCustomer.Name)
 		}
 	case ui.ViewServiceDetail:
 		if m.selectedService != nil {
-			return m, m.deleteService(m.selectedService.ID)
+			return m.confirmDelete(entityService, m.selectedService.ID, m.selectedService.Name)
 		}
 	}
 	return m, nil
@@ -438,13 +561,15 @@ func (m Model) handleDelete() (tea.Model, tea.Cmd) {
 func (m Model) handleRefresh() (tea.Model, tea.Cmd) {
 	switch m.view {
 	case ui.ViewCustomers:
-		return m, m.fetchCustomers()
+		return m, m.fetchCustomers(1)
 	case ui.ViewServices:
-		return m, m.fetchServices()
+		return m, m.fetchServices(1)
 	case ui.ViewContracts:
-		return m, m.fetchContracts()
+		return m, m.fetchContracts(1)
 	case ui.ViewPrintJobs:
-		return m, m.fetchPrintJobs()
+		return m, m.fetchPrintJobs(1)
+	case ui.ViewNotifications:
+		return m, m.fetchNotifications()
 	}
 	return m, nil
 }
@@ -466,11 +591,7 @@ func (m Model) handleCustomerDetailAction() (tea.Model, tea.Cmd) {
 	case "Edit":
 		return m.initCustomerForm(m.selectedCustomer)
 	case "Delete":
-		id := m.selectedCustomer.ID
-		m.view = ui.ViewCustomers
-		m.cursor = 0
-		m.selectedCustomer = nil
-		return m, m.deleteCustomer(id)
+		return m.confirmDelete(entityCustomer, m.selectedCustomer.ID, m.selectedCustomer.Name)
 	case "Back":
 		m.view = ui.ViewCustomers
 		m.cursor = 0
@@ -492,11 +613,7 @@ func (m Model) handleServiceDetailAction() (tea.Model, tea.Cmd) {
 		case "Edit":
 			return m.initServiceForm(m.selectedService)
 		case "Delete":
-			id := m.selectedService.ID
-			m.view = ui.ViewServices
-			m.cursor = 0
-			m.selectedService = nil
-			return m, m.deleteService(id)
+			return m.confirmDelete(entityService, m.selectedService.ID, m.selectedService.Name)
 		case "Back":
 			m.view = ui.ViewServices
 			m.cursor = 0
@@ -513,7 +630,7 @@ func (m Model) handleContractDetailAction() (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
-	actions := []string{"Edit", "Generate", "Print", "Sign", "Back"}
+	actions := contractDetailActions(m)
 	if m.cursor < 0 || m.cursor >= len(actions) {
 		return m, nil
 	}
@@ -524,7 +641,13 @@ func (m Model) handleContractDetailAction() (tea.Model, tea.Cmd) {
 	case "Generate":
 		return m, m.generateContract(m.selectedContract.ID)
 	case "Print":
-		return m, m.createPrintJob(m.selectedContract.ID, "PDF")
+		return m, m.createPrintJob(m.selectedContract.ID, "PDF", false)
+	case "Regenerate and print":
+		m.stalePrintContractID = 0
+		if m.cursor > 0 {
+			m.cursor--
+		}
+		return m, m.createPrintJob(m.selectedContract.ID, "PDF", true)
 	case "Sign":
 		return m, m.signContract(m.selectedContract.ID)
 	case "Back":
@@ -534,6 +657,64 @@ func (m Model) handleContractDetailAction() (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// contractDetailActions returns the actions available for the selected
+// contract; "Regenerate and print" follows "Print" after a print was refused
+// because the contract changed since its latest generation
+func contractDetailActions(m Model) []string {
+	if m.selectedContract != nil && m.stalePrintContractID == m.selectedContract.ID {
+		return []string{"Edit", "Generate", "Print", "Regenerate and print", "Sign", "Back"}
+	}
+	return []string{"Edit", "Generate", "Print", "Sign", "Back"}
+}
+
+// printJobDetailActions returns the actions available for a print job in its current status
+func printJobDetailActions(job *api.PrintJob) []string {
+	if job == nil {
+		return []string{"Back"}
+	}
+	switch job.Status {
+	case "FAILED":
+		return []string{"Retry", "Cancel", "Back"}
+	case "QUEUED", "PROCESSING":
+		return []string{"Cancel", "Back"}
+	default:
+		return []string{"Back"}
+	}
+}
+
+func (m Model) handlePrintJobDetailAction() (tea.Model, tea.Cmd) {
+	// Guard against nil selectedPrintJob
+	if m.selectedPrintJob == nil {
+		m.view = ui.ViewPrintJobs
+		m.cursor = 0
+		return m, nil
+	}
+
+	actions := printJobDetailActions(m.selectedPrintJob)
+	if m.cursor < 0 || m.cursor >= len(actions) {
+		return m, nil
+	}
+
+	switch actions[m.cursor] {
+	case "Retry":
+		id := m.selectedPrintJob.ID
+		m.view = ui.ViewPrintJobs
+		m.cursor = 0
+		m.selectedPrintJob = nil
+		return m, tea.Sequence(m.retryPrintJob(id), m.fetchPrintJobs(m.printJobPage.page))
+	case "Cancel":
+		id := m.selectedPrintJob.ID
+		m.view = ui.ViewPrintJobs
+		m.cursor = 0
+		m.selectedPrintJob = nil
+		return m, tea.Sequence(m.cancelPrintJob(id), m.fetchPrintJobs(m.printJobPage.page))
+	case "Back":
+		m.view = ui.ViewPrintJobs
+		m.cursor = 0
+	}
+	return m, nil
+}
+
 // updateInputFocus updates which form input is focused
 func (m Model) updateInputFocus() Model {
 	for i := range m.inputs {
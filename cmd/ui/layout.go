@@ -137,7 +137,7 @@ func (m Model) renderSidebarCollapsed(items []SidebarItem) string {
 
 // renderSidebar renders the collapsible sidebar menu
 func (m Model) renderSidebar(width, height int) string {
-	items := getSidebarItems()
+	items := m.sidebarItems()
 
 	var content string
 	if m.sidebarOpen {
@@ -182,6 +182,8 @@ func (m Model) renderContent(width, height int) string {
 		content = m.renderPrintJobList()
 	case ui.ViewPrintJobDetail:
 		content = m.renderPrintJobDetail()
+	case ui.ViewNotifications:
+		content = m.renderNotificationList()
 	case ui.ViewSettings:
 		content = m.renderSettings()
 	default:
@@ -202,7 +204,11 @@ func (m Model) renderContent(width, height int) string {
 		content += "\n" + msgStyle.Render(m.message)
 	}
 
-	return ui.ContentStyle.Width(width).Height(height).Render(content)
+	rendered := ui.ContentStyle.Width(width).Height(height).Render(content)
+	if m.pendingAction != nil {
+		rendered = ui.RenderOverlay(rendered, m.renderPendingAction())
+	}
+	return rendered
 }
 
 // renderFooter renders the fixed bottom footer with help and status
@@ -216,6 +222,9 @@ func (m Model) renderFooter(width int) string {
 		statusIcon = ui.StatusOfflineStyle.Render("○")
 	}
 	apiInfo := statusIcon + " " + ui.FooterLabelStyle.Render(m.baseURL)
+	if status := m.pageStatus(); status != "" {
+		apiInfo = ui.FooterLabelStyle.Render(status) + ui.FooterHelpStyle.Render(" ║ ") + apiInfo
+	}
 
 	// Calculate spacing
 	spacing := width - lipgloss.Width(help) - lipgloss.Width(apiInfo) - 4
@@ -237,19 +246,31 @@ func (m Model) getContextualHelp() string {
 
 	base := key("Ctrl+B") + " " + lbl("Menu")
 
+	if m.pendingAction != nil {
+		return key("y") + " " + lbl("Delete") + sep + key("Any key") + " " + lbl("Cancel")
+	}
+
 	if m.focusOnSidebar {
 		return base + sep + key("↑↓") + " " + lbl("Nav") + sep + key("Enter") + " " + lbl("Select") + sep + key("→") + " " + lbl("Content")
 	}
 
+	if m.filter.editing && m.filter.view == m.view {
+		return key("Enter") + " " + lbl("Search all pages") + sep + key("Esc") + " " + lbl("Clear filter")
+	}
+
 	switch m.view {
 	case ui.ViewMain:
 		return base + sep + key("←") + " " + lbl("Menu") + sep + key("q") + " " + lbl("Quit")
-	case ui.ViewCustomers, ui.ViewServices, ui.ViewContracts, ui.ViewPrintJobs:
-		return base + sep + key("n") + " " + lbl("New") + sep + key("r") + " " + lbl("Refresh") + sep + key("Esc") + " " + lbl("Back")
+	case ui.ViewCustomers, ui.ViewServices, ui.ViewContracts:
+		return base + sep + key("n") + " " + lbl("New") + sep + key("/") + " " + lbl("Search") + sep + key("PgUp/PgDn") + " " + lbl("Page") + sep + key("r") + " " + lbl("Refresh") + sep + key("Esc") + " " + lbl("Back")
+	case ui.ViewPrintJobs:
+		return base + sep + key("n") + " " + lbl("New") + sep + key("PgUp/PgDn") + " " + lbl("Page") + sep + key("r") + " " + lbl("Refresh") + sep + key("Esc") + " " + lbl("Back")
 	case ui.ViewCustomerDetail, ui.ViewServiceDetail, ui.ViewPrintJobDetail:
 		return base + sep + key("e") + " " + lbl("Edit") + sep + key("d") + " " + lbl("Delete") + sep + key("Esc") + " " + lbl("Back")
 	case ui.ViewContractDetail:
 		return base + sep + key("e") + " " + lbl("Edit") + sep + key("Esc") + " " + lbl("Back")
+	case ui.ViewNotifications:
+		return base + sep + key("Enter") + " " + lbl("Open") + sep + key("r") + " " + lbl("Refresh") + sep + key("Esc") + " " + lbl("Back")
 	case ui.ViewSettings:
 		return base + sep + key("Esc") + " " + lbl("Back")
 	case ui.ViewCustomerCreate, ui.ViewCustomerEdit,
@@ -278,10 +299,10 @@ func (m Model) renderDashboard() string {
 		label string
 		value string
 	}{
-		{"◈", "Customers", fmt.Sprintf("%d", len(m.customers))},
-		{"◇", "Services", fmt.Sprintf("%d", len(m.services))},
-		{"◆", "Contracts", fmt.Sprintf("%d", len(m.contracts))},
-		{"▣", labelPrintJobs, fmt.Sprintf("%d", len(m.printJobs))},
+		{"◈", "Customers", fmt.Sprintf("%d", max(m.customerPage.total, len(m.customers)))},
+		{"◇", "Services", fmt.Sprintf("%d", max(m.servicePage.total, len(m.services)))},
+		{"◆", "Contracts", fmt.Sprintf("%d", max(m.contractPage.total, len(m.contracts)))},
+		{"▣", labelPrintJobs, fmt.Sprintf("%d", max(m.printJobPage.total, len(m.printJobs)))},
 	}
 
 	for _, stat := range stats {
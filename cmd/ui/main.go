@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
@@ -39,12 +40,43 @@ type Model struct {
 	contracts []api.Contract
 	printJobs []api.PrintJob
 
+	// The page each list shows; PgUp/PgDn move between pages, and with
+	// autoPage moving down past the last row opens the next page
+	customerPage listPage
+	servicePage  listPage
+	contractPage listPage
+	printJobPage listPage
+	autoPage     bool
+
+	// filter is the search bar of a list view
+	filter listFilter
+
+	// notifications is the first page of the user's notifications, unread
+	// first; unreadNotifications counts all unread ones
+	notifications       []api.Notification
+	unreadNotifications int
+
 	// Selected items
 	selectedCustomer *api.Customer
 	selectedService  *api.Service
 	selectedContract *api.Contract
 	selectedPrintJob *api.PrintJob
 
+	// stalePrintContractID is the contract whose last print was refused
+	// because it changed after its latest generation; its detail view then
+	// offers "Regenerate and print"
+	stalePrintContractID int64
+
+	// contractItemOffset is the first row of the contract detail items table
+	contractItemOffset int
+
+	// pendingAction is the delete the confirmation dialog asks about; nil
+	// when no dialog is open
+	pendingAction *pendingAction
+
+	// printJobPollSeq identifies the current detail view poll loop so a stale loop stops
+	printJobPollSeq int
+
 	// Form inputs
 	inputs     []textinput.Model
 	focusIndex int
@@ -57,6 +89,8 @@ type Model struct {
 	user     string
 	tenantID string
 	signer   string
+	profile  *api.UserProfile // Fetched after login; nil until then
+	features map[string]bool  // Tenant's feature flags; nil until fetched
 
 	// UI state
 	sidebarOpen    bool
@@ -130,24 +164,50 @@ func initialModel() Model {
 		height:      24,
 		inputs:      inputs,
 		formEntity:  formEntity,
+		autoPage:    autoPageFromEnv(),
 	}
 }
 
 func (m Model) Init() tea.Cmd {
 	// If we already have a token, fetch all data on startup
 	if m.token != "" {
-		return tea.Batch(textinput.Blink, m.fetchAllData())
+		return tea.Batch(textinput.Blink, m.fetchAllData(), m.fetchProfile(), m.fetchFeatures())
 	}
 	return textinput.Blink
 }
 
 // Messages for async operations
-type fetchCustomersMsg struct{ customers []api.Customer }
-type fetchServicesMsg struct{ services []api.Service }
-type fetchContractsMsg struct{ contracts []api.Contract }
-type fetchPrintJobsMsg struct{ jobs []api.PrintJob }
+type fetchCustomersMsg struct {
+	customers []api.Customer
+	page      listPage
+}
+type fetchServicesMsg struct {
+	services []api.Service
+	page     listPage
+}
+type fetchContractsMsg struct {
+	contracts []api.Contract
+	page      listPage
+}
+type fetchPrintJobsMsg struct {
+	jobs []api.PrintJob
+	page listPage
+}
+type fetchNotificationsMsg struct {
+	notifications []api.Notification
+	unread        int
+}
+type printJobOpenedMsg struct{ job *api.PrintJob }
+type printJobPolledMsg struct {
+	job *api.PrintJob
+	seq int
+}
 type errMsg struct{ err error }
 type successMsg struct{ message string }
+type stalePrintMsg struct {
+	contractID int64
+	err        error
+}
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
@@ -167,12 +227,38 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m.handleFetchContracts(msg), nil
 	case fetchPrintJobsMsg:
 		return m.handleFetchPrintJobs(msg), nil
+	case fetchNotificationsMsg:
+		return m.handleFetchNotifications(msg), nil
+	case contractItemsMsg:
+		return m.handleContractItems(msg), nil
+	case dependentsMsg:
+		return m.handleDependents(msg), nil
+	case printJobOpenedMsg:
+		return m.showPrintJob(*msg.job)
+	case printJobPolledMsg:
+		return m.handlePrintJobPolled(msg)
 	case errMsg:
+		if errors.Is(msg.err, api.ErrSessionExpired) {
+			return m.handleSessionExpired(msg)
+		}
 		return m.handleError(msg), nil
 	case successMsg:
 		return m.handleSuccess(msg), nil
+	case stalePrintMsg:
+		return m.handleStalePrint(msg), nil
 	case loginMsg:
 		return m.handleLoginMsgWithCmd(msg)
+	case profileMsg:
+		return m.handleProfileMsg(msg), nil
+	case featuresMsg:
+		return m.handleFeaturesMsg(msg), nil
+	}
+
+	// Keep the search bar's cursor blinking
+	if m.filter.editing {
+		var cmd tea.Cmd
+		m.filter.input, cmd = m.filter.input.Update(msg)
+		return m, cmd
 	}
 
 	// Update text inputs if in form mode
@@ -186,6 +272,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 // handleFetchCustomers processes customer fetch results
 func (m Model) handleFetchCustomers(msg fetchCustomersMsg) Model {
 	m.customers = msg.customers
+	m.customerPage = msg.page
 	m.message = fmt.Sprintf("Loaded %d customers", len(msg.customers))
 	m.messageType = "success"
 	return m
@@ -194,6 +281,7 @@ func (m Model) handleFetchCustomers(msg fetchCustomersMsg) Model {
 // handleFetchServices processes service fetch results
 func (m Model) handleFetchServices(msg fetchServicesMsg) Model {
 	m.services = msg.services
+	m.servicePage = msg.page
 	m.message = fmt.Sprintf("Loaded %d services", len(msg.services))
 	m.messageType = "success"
 	return m
@@ -202,6 +290,7 @@ func (m Model) handleFetchServices(msg fetchServicesMsg) Model {
 // handleFetchContracts processes contract fetch results
 func (m Model) handleFetchContracts(msg fetchContractsMsg) Model {
 	m.contracts = msg.contracts
+	m.contractPage = msg.page
 	m.message = fmt.Sprintf("Loaded %d contracts", len(msg.contracts))
 	m.messageType = "success"
 	return m
@@ -210,11 +299,34 @@ func (m Model) handleFetchContracts(msg fetchContractsMsg) Model {
 // handleFetchPrintJobs processes print job fetch results
 func (m Model) handleFetchPrintJobs(msg fetchPrintJobsMsg) Model {
 	m.printJobs = msg.jobs
+	m.printJobPage = msg.page
 	m.message = fmt.Sprintf("Loaded %d print jobs", len(msg.jobs))
 	m.messageType = "success"
 	return m
 }
 
+// handleFetchNotifications processes notification fetch results
+func (m Model) handleFetchNotifications(msg fetchNotificationsMsg) Model {
+	m.notifications = msg.notifications
+	m.unreadNotifications = msg.unread
+	m.message = fmt.Sprintf("Loaded %d notifications", len(msg.notifications))
+	m.messageType = "success"
+	return m
+}
+
+// handlePrintJobPolled refreshes the open print job detail and keeps polling
+// while the job is still running. Results for a job no longer shown are dropped.
+func (m Model) handlePrintJobPolled(msg printJobPolledMsg) (tea.Model, tea.Cmd) {
+	if msg.seq != m.printJobPollSeq || m.view != ui.ViewPrintJobDetail || m.selectedPrintJob == nil {
+		return m, nil
+	}
+	m.selectedPrintJob = msg.job
+	if printJobActive(msg.job) {
+		return m, m.pollPrintJob(msg.job.ID, msg.seq)
+	}
+	return m, m.fetchPrintJobs(m.printJobPage.page)
+}
+
 // handleError processes error messages
 func (m Model) handleError(msg errMsg) Model {
 	m.message = msg.err.Error()
@@ -222,6 +334,26 @@ func (m Model) handleError(msg errMsg) Model {
 	return m
 }
 
+// handleStalePrint offers "Regenerate and print" after a print was refused
+// because the contract changed after its latest generation
+func (m Model) handleStalePrint(msg stalePrintMsg) Model {
+	m.stalePrintContractID = msg.contractID
+	m.message = msg.err.Error() + "; choose Regenerate and print to print the current version"
+	m.messageType = "error"
+	return m
+}
+
+// handleSessionExpired returns to the login form once the session can no
+// longer be refreshed
+func (m Model) handleSessionExpired(msg errMsg) (tea.Model, tea.Cmd) {
+	m.token = ""
+	model, cmd := m.initLoginForm()
+	m = model.(Model)
+	m.message = msg.err.Error()
+	m.messageType = "error"
+	return m, cmd
+}
+
 // handleSuccess processes success messages
 func (m Model) handleSuccess(msg successMsg) Model {
 	m.message = msg.message
@@ -256,13 +388,46 @@ func (m Model) handleLoginMsg(msg loginMsg) Model {
 // handleLoginMsgWithCmd processes login response and returns a command to fetch all data
 func (m Model) handleLoginMsgWithCmd(msg loginMsg) (tea.Model, tea.Cmd) {
 	m = m.handleLoginMsg(msg)
-	// If login was successful, fetch all data
+	// If login was successful, fetch all data and the user's profile
 	if m.token != "" && m.view == ui.ViewMain {
-		return m, m.fetchAllData()
+		return m, tea.Batch(m.fetchAllData(), m.fetchProfile(), m.fetchFeatures())
 	}
 	return m, nil
 }
 
+// handleProfileMsg stores the user's profile. Contracts are signed with the
+// user's full name when the profile has one. A missing profile only leaves
+// the settings view less detailed, so errors are not shown.
+func (m Model) handleProfileMsg(msg profileMsg) Model {
+	if msg.err != nil || msg.profile == nil {
+		return m
+	}
+	m.profile = msg.profile
+	if msg.profile.Username != "" {
+		m.user = msg.profile.Username
+	}
+	if msg.profile.TenantID != "" {
+		m.tenantID = msg.profile.TenantID
+	}
+	if msg.profile.FullName != "" {
+		m.signer = msg.profile.FullName
+	}
+	return m
+}
+
+// handleFeaturesMsg stores the tenant's feature flags. On failure gated
+// menu entries stay hidden.
+func (m Model) handleFeaturesMsg(msg featuresMsg) Model {
+	if msg.err != nil {
+		return m
+	}
+	m.features = make(map[string]bool, len(msg.flags))
+	for _, f := range msg.flags {
+		m.features[f.Name] = f.Enabled
+	}
+	return m
+}
+
 // handleKeyMsg processes keyboard input
 func (m Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	// Clear message on any key except enter
@@ -270,7 +435,14 @@ func (m Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.message = ""
 	}
 
+	if m.pendingAction != nil && msg.String() != "ctrl+c" {
+		return m.handlePendingKey(msg)
+	}
+
 	inFormMode := len(m.inputs) > 0
+	if m.filter.editing && m.filter.view == m.view {
+		return m.handleFilterKey(msg)
+	}
 
 	switch msg.String() {
 	case "ctrl+c":
@@ -294,6 +466,14 @@ func (m Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if !inFormMode {
 			return m.handleShortcutKey(msg.String())
 		}
+	case "/":
+		if !inFormMode {
+			return m.openFilter()
+		}
+	case "pgup", "[", "pgdown", "]":
+		if !inFormMode {
+			return m.handlePageKey(msg.String())
+		}
 	case "ctrl+b":
 		m.sidebarOpen = !m.sidebarOpen
 		return m, nil
@@ -328,6 +508,9 @@ func (m Model) handleEscKey() (tea.Model, tea.Cmd) {
 	if m.view == ui.ViewLogin {
 		return m, nil
 	}
+	if !m.focusOnSidebar && m.searchQuery(m.view) != "" {
+		return m.clearFilter()
+	}
 	return m.handleEscape()
 }
 
@@ -348,7 +531,7 @@ func (m Model) handleUpKey(key string, inFormMode bool) (tea.Model, tea.Cmd) {
 	} else {
 		m.cursor = m.handleUp()
 	}
-	return m, nil
+	return m.followItemCursor(), nil
 }
 
 // handleDownKey handles down/j keys
@@ -365,10 +548,12 @@ func (m Model) handleDownKey(key string, inFormMode bool) (tea.Model, tea.Cmd) {
 	}
 	if m.focusOnSidebar {
 		m.sidebarCursor = m.handleDown()
+	} else if m.autoPage && m.atPageEnd() {
+		return m.changePage(1)
 	} else {
 		m.cursor = m.handleDown()
 	}
-	return m, nil
+	return m.followItemCursor(), nil
 }
 
 // handleEnterKey handles the enter key
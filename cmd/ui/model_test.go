@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/zlovtnik/gprint/cmd/ui/api"
+)
+
+// fakeAPI records the requests the model sends to a test server
+type fakeAPI struct {
+	mu       sync.Mutex
+	requests []string
+}
+
+func (f *fakeAPI) record(r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, r.Method+" "+r.URL.RequestURI())
+}
+
+// last returns the last request sent, e.g. "GET /api/v1/customers?page=2&page_size=20"
+func (f *fakeAPI) last() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.requests) == 0 {
+		return ""
+	}
+	return f.requests[len(f.requests)-1]
+}
+
+// sent returns the requests sent so far, in order
+func (f *fakeAPI) sent() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.requests)
+}
+
+// newTestModel returns a logged-in model whose client talks to handler
+func newTestModel(t *testing.T, handler http.HandlerFunc) (Model, *fakeAPI) {
+	t.Helper()
+	f := &fakeAPI{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.record(r)
+		handler(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	t.Setenv("GPRINT_API_URL", srv.URL)
+	t.Setenv("GPRINT_TOKEN", "token")
+	t.Setenv("GPRINT_AUTO_PAGE", "")
+	m := initialModel()
+	m.inputs = nil
+	return m, f
+}
+
+// writeData writes a successful API response carrying data
+func writeData(t *testing.T, w http.ResponseWriter, data any) {
+	t.Helper()
+	raw, err := json.Marshal(data)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(api.Response{Success: true, Data: raw})
+}
+
+// writePage writes the page of items asked for by the request's page and
+// page_size parameters
+func writePage[T any](t *testing.T, w http.ResponseWriter, r *http.Request, items []T) {
+	t.Helper()
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	size, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+	page, size = max(page, 1), max(size, 1)
+	start := min((page-1)*size, len(items))
+	end := min(start+size, len(items))
+	rows, err := json.Marshal(items[start:end])
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	writeData(t, w, api.PaginatedResponse{
+		Data:       rows,
+		Page:       page,
+		PageSize:   size,
+		TotalCount: len(items),
+		TotalPages: (len(items) + size - 1) / size,
+	})
+}
+
+// keyMsg returns the key message of a key name as tea reports it, e.g.
+// "pgdown", "enter" or "y"
+func keyMsg(key string) tea.KeyMsg {
+	switch key {
+	case "up":
+		return tea.KeyMsg{Type: tea.KeyUp}
+	case "down":
+		return tea.KeyMsg{Type: tea.KeyDown}
+	case "pgup":
+		return tea.KeyMsg{Type: tea.KeyPgUp}
+	case "pgdown":
+		return tea.KeyMsg{Type: tea.KeyPgDown}
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	case "backspace":
+		return tea.KeyMsg{Type: tea.KeyBackspace}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+}
+
+// press sends keys to m, returning the model and the command of the last key
+func press(t *testing.T, m Model, keys ...string) (Model, tea.Cmd) {
+	t.Helper()
+	var cmd tea.Cmd
+	for _, key := range keys {
+		var next tea.Model
+		next, cmd = m.Update(keyMsg(key))
+		m = next.(Model)
+	}
+	return m, cmd
+}
+
+// run runs cmd, as the program would, and feeds its message back to m
+func run(t *testing.T, m Model, cmd tea.Cmd) Model {
+	t.Helper()
+	if cmd == nil {
+		t.Fatal("no command to run")
+	}
+	next, _ := m.Update(cmd())
+	return next.(Model)
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/zlovtnik/gprint/cmd/ui/api"
+	"github.com/zlovtnik/gprint/cmd/ui/ui"
+)
+
+// notificationsAPI serves an unread notification of print job 9, a read
+// one without a link and an unread count of 1, and accepts marking them read
+func notificationsAPI(t *testing.T) http.HandlerFunc {
+	jobID := int64(9)
+	readAt := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	notifications := []api.Notification{
+		{ID: 42, Type: "print_job.completed", Title: "Print job #9 is ready", EntityType: "print_job", EntityID: &jobID, CreatedAt: readAt},
+		{ID: 41, Type: "print_job.failed", Title: "Print job #8 failed", Body: "Your PDF print of contract 4 could not be completed.", CreatedAt: readAt, ReadAt: &readAt},
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/v1/notifications":
+			writePage(t, w, r, notifications)
+		case "GET /api/v1/notifications/unread-count":
+			writeData(t, w, map[string]int{"unread": 1})
+		case "POST /api/v1/notifications/42/read":
+			n := notifications[0]
+			n.ReadAt = &readAt
+			writeData(t, w, n)
+		case "POST /api/v1/notifications/read-all":
+			writeData(t, w, map[string]int{"marked": 1})
+		case "GET /api/v1/print-jobs/9":
+			writeData(t, w, api.PrintJob{ID: 9, ContractID: 4, Status: "COMPLETED"})
+		default:
+			http.NotFound(w, r)
+		}
+	}
+}
+
+// runAll runs cmd and the commands it batches or sequences, feeding each
+// message back to m
+func runAll(t *testing.T, m Model, cmd tea.Cmd) Model {
+	t.Helper()
+	msg := cmd()
+	if v := reflect.ValueOf(msg); v.Kind() == reflect.Slice && v.Type().Elem() == reflect.TypeFor[tea.Cmd]() {
+		for i := range v.Len() {
+			if c := v.Index(i).Interface().(tea.Cmd); c != nil {
+				m = runAll(t, m, c)
+			}
+		}
+		return m
+	}
+	if msg == nil {
+		return m
+	}
+	next, _ := m.Update(msg)
+	return next.(Model)
+}
+
+// notificationList returns a model showing the loaded notifications
+func notificationList(t *testing.T) (Model, *fakeAPI) {
+	t.Helper()
+	m, f := newTestModel(t, notificationsAPI(t))
+	m.view = ui.ViewNotifications
+	return run(t, m, m.fetchNotifications()), f
+}
+
+func TestNotificationsLoad(t *testing.T) {
+	m, f := notificationList(t)
+	if want := []string{"GET /api/v1/notifications?page=1&page_size=20", "GET /api/v1/notifications/unread-count"}; !slices.Equal(f.sent(), want) {
+		t.Errorf("sent %q, want %q", f.sent(), want)
+	}
+	if len(m.notifications) != 2 || m.unreadNotifications != 1 {
+		t.Fatalf("loaded %d notifications, %d unread; want 2, 1", len(m.notifications), m.unreadNotifications)
+	}
+	if m.getMaxItems() != 4 {
+		t.Errorf("%d rows, want Mark all read, the notifications and Back", m.getMaxItems())
+	}
+
+	out := m.renderNotificationList()
+	if !strings.Contains(out, "Notifications (1 unread)") || strings.Count(out, "●") != 1 {
+		t.Errorf("list renders:\n%s", out)
+	}
+	if i := slices.IndexFunc(m.sidebarItems(), func(item SidebarItem) bool { return item.View == ui.ViewNotifications }); i < 0 || m.sidebarItems()[i].Title != "Notifications (1)" {
+		t.Errorf("sidebar = %+v, want the unread count on Notifications", m.sidebarItems())
+	}
+}
+
+func TestNotificationOpenMarksRead(t *testing.T) {
+	m, f := notificationList(t)
+	m.cursor = 1
+	m, cmd := press(t, m, "enter")
+	if m.notifications[0].ReadAt == nil || m.unreadNotifications != 0 {
+		t.Errorf("opened notification read at %v with %d unread, want it read at once", m.notifications[0].ReadAt, m.unreadNotifications)
+	}
+
+	m = runAll(t, m, cmd)
+	sent := f.sent()[2:]
+	if !slices.Contains(sent, "POST /api/v1/notifications/42/read") || !slices.Contains(sent, "GET /api/v1/print-jobs/9") {
+		t.Errorf("opening sent %q, want it marked read and print job 9 fetched", sent)
+	}
+	if m.view != ui.ViewPrintJobDetail || m.selectedPrintJob == nil || m.selectedPrintJob.ID != 9 {
+		t.Errorf("view %v, want print job 9", m.view)
+	}
+}
+
+func TestNotificationOpenAlreadyRead(t *testing.T) {
+	m, f := notificationList(t)
+	m.cursor = 2
+	m, cmd := press(t, m, "enter")
+	if cmd != nil || len(f.sent()) != 2 {
+		t.Errorf("opening a read notification sent %q", f.sent())
+	}
+	if m.unreadNotifications != 1 || !strings.HasPrefix(m.message, "Print job #8 failed: Your PDF print") {
+		t.Errorf("message %q with %d unread, want the notification text", m.message, m.unreadNotifications)
+	}
+}
+
+func TestNotificationsMarkAllRead(t *testing.T) {
+	m, f := notificationList(t)
+	m, cmd := press(t, m, "enter")
+	m = runAll(t, m, cmd)
+	want := []string{
+		"POST /api/v1/notifications/read-all",
+		"GET /api/v1/notifications?page=1&page_size=20",
+		"GET /api/v1/notifications/unread-count",
+	}
+	if sent := f.sent()[2:]; !slices.Equal(sent, want) {
+		t.Errorf("Mark all read sent %q, want %q", sent, want)
+	}
+	if m.view != ui.ViewNotifications {
+		t.Errorf("view %v, want the list reloaded", m.view)
+	}
+}
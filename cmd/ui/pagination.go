@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/zlovtnik/gprint/cmd/ui/api"
+	"github.com/zlovtnik/gprint/cmd/ui/ui"
+)
+
+// listPage is the page of results a list view shows
+type listPage struct {
+	page       int
+	totalPages int
+	total      int
+}
+
+// newListPage returns the page metadata of a list response
+func newListPage[T any](res *api.ListResult[T]) listPage {
+	return listPage{page: res.Page, totalPages: res.TotalPages, total: res.Total}
+}
+
+// autoPageFromEnv reports whether GPRINT_AUTO_PAGE asks for moving down past
+// the last row of a list to open the next page
+func autoPageFromEnv() bool {
+	on, _ := strconv.ParseBool(os.Getenv("GPRINT_AUTO_PAGE"))
+	return on
+}
+
+// listPageFor returns the page state of a paginated list view, or nil for
+// other views
+func (m *Model) listPageFor(view ui.ViewState) *listPage {
+	switch view {
+	case ui.ViewCustomers:
+		return &m.customerPage
+	case ui.ViewServices:
+		return &m.servicePage
+	case ui.ViewContracts:
+		return &m.contractPage
+	case ui.ViewPrintJobs:
+		return &m.printJobPage
+	}
+	return nil
+}
+
+// listItemCount returns how many rows of the current page a list view shows
+func (m Model) listItemCount() int {
+	switch m.view {
+	case ui.ViewCustomers:
+		return len(m.visibleCustomers())
+	case ui.ViewServices:
+		return len(m.visibleServices())
+	case ui.ViewContracts:
+		return len(m.visibleContracts())
+	case ui.ViewPrintJobs:
+		return len(m.printJobs)
+	}
+	return 0
+}
+
+// firstItemRow returns the cursor row of a list's first item; lists with a
+// create option start one row down
+func firstItemRow(view ui.ViewState) int {
+	if view == ui.ViewPrintJobs {
+		return 0
+	}
+	return 1
+}
+
+// fetchListPage fetches a page of the list shown in view
+func (m Model) fetchListPage(view ui.ViewState, page int) tea.Cmd {
+	switch view {
+	case ui.ViewCustomers:
+		return m.fetchCustomers(page)
+	case ui.ViewServices:
+		return m.fetchServices(page)
+	case ui.ViewContracts:
+		return m.fetchContracts(page)
+	case ui.ViewPrintJobs:
+		return m.fetchPrintJobs(page)
+	}
+	return nil
+}
+
+// handlePageKey handles PgUp/PgDn and [ / ] in list views
+func (m Model) handlePageKey(key string) (tea.Model, tea.Cmd) {
+	if m.focusOnSidebar {
+		return m, nil
+	}
+	if key == "pgup" || key == "[" {
+		return m.changePage(-1)
+	}
+	return m.changePage(1)
+}
+
+// changePage moves the current list delta pages, staying within its pages,
+// and puts the cursor on the first item
+func (m Model) changePage(delta int) (tea.Model, tea.Cmd) {
+	p := m.listPageFor(m.view)
+	if p == nil {
+		return m, nil
+	}
+	target := max(p.page, 1) + delta
+	if target < 1 || target > p.totalPages {
+		return m, nil
+	}
+	m.cursor = firstItemRow(m.view)
+	return m, m.fetchListPage(m.view, target)
+}
+
+// atPageEnd reports whether the cursor is on the last item of a list page
+// that has a next page
+func (m Model) atPageEnd() bool {
+	p := m.listPageFor(m.view)
+	n := m.listItemCount()
+	return p != nil && n > 0 && p.page < p.totalPages && m.cursor == firstItemRow(m.view)+n-1
+}
+
+// pageStatus returns the current list's position, e.g. "Page 2/14 · 273
+// items", or an empty string outside list views
+func (m Model) pageStatus() string {
+	p := m.listPageFor(m.view)
+	if p == nil {
+		return ""
+	}
+	return fmt.Sprintf("Page %d/%d · %d items", max(p.page, 1), max(p.totalPages, 1), max(p.total, m.listItemCount()))
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/zlovtnik/gprint/cmd/ui/api"
+	"github.com/zlovtnik/gprint/cmd/ui/ui"
+)
+
+// customersAPI serves n customers, 20 to a page
+func customersAPI(t *testing.T, n int) http.HandlerFunc {
+	customers := make([]api.Customer, n)
+	for i := range customers {
+		customers[i] = api.Customer{ID: int64(i + 1), CustomerCode: fmt.Sprintf("C%03d", i+1), Name: fmt.Sprintf("Customer %d", i+1)}
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		writePage(t, w, r, customers)
+	}
+}
+
+// customerList returns a model showing the first page of 45 customers
+func customerList(t *testing.T) (Model, *fakeAPI) {
+	t.Helper()
+	m, f := newTestModel(t, customersAPI(t, 45))
+	m.view = ui.ViewCustomers
+	m.cursor = firstItemRow(m.view)
+	return run(t, m, m.fetchCustomers(1)), f
+}
+
+func TestPageKeys(t *testing.T) {
+	m, f := customerList(t)
+	if got := m.pageStatus(); got != "Page 1/3 · 45 items" {
+		t.Errorf("pageStatus = %q", got)
+	}
+
+	steps := []struct {
+		key  string
+		page int
+		want string
+	}{
+		{"pgdown", 2, "Page 2/3 · 45 items"},
+		{"]", 3, "Page 3/3 · 45 items"},
+		{"pgup", 2, "Page 2/3 · 45 items"},
+		{"[", 1, "Page 1/3 · 45 items"},
+	}
+	for _, s := range steps {
+		m.cursor = 4
+		next, cmd := press(t, m, s.key)
+		m = next
+		if m.cursor != firstItemRow(ui.ViewCustomers) {
+			t.Errorf("after %s the cursor is on row %d, want the first item", s.key, m.cursor)
+		}
+		m = run(t, m, cmd)
+		if want := fmt.Sprintf("GET /api/v1/customers?page=%d&page_size=20", s.page); f.last() != want {
+			t.Errorf("after %s requested %q, want %q", s.key, f.last(), want)
+		}
+		if got := m.pageStatus(); got != s.want {
+			t.Errorf("after %s pageStatus = %q, want %q", s.key, got, s.want)
+		}
+	}
+}
+
+func TestPageKeysStayWithinPages(t *testing.T) {
+	m, _ := customerList(t)
+	if _, cmd := press(t, m, "pgup"); cmd != nil {
+		t.Error("PgUp on the first page fetched a page")
+	}
+	m, cmd := press(t, m, "]")
+	m = run(t, m, cmd)
+	m, cmd = press(t, m, "]")
+	m = run(t, m, cmd)
+	if _, cmd := press(t, m, "pgdown"); cmd != nil {
+		t.Error("PgDn on the last page fetched a page")
+	}
+	if len(m.customers) != 5 {
+		t.Errorf("the last page shows %d customers, want 5", len(m.customers))
+	}
+
+	// Page keys do nothing outside list views or with the sidebar focused
+	m.view = ui.ViewSettings
+	if _, cmd := press(t, m, "[", "pgup"); cmd != nil {
+		t.Error("PgUp in settings fetched a page")
+	}
+	m.view = ui.ViewCustomers
+	m.focusOnSidebar = true
+	if _, cmd := press(t, m, "pgup"); cmd != nil {
+		t.Error("PgUp with the sidebar focused fetched a page")
+	}
+}
+
+func TestAutoPage(t *testing.T) {
+	m, f := customerList(t)
+	last := firstItemRow(ui.ViewCustomers) + 19
+
+	// Without auto-paging the cursor moves on to Back
+	m.cursor = last
+	moved, cmd := press(t, m, "down")
+	if cmd != nil || moved.cursor != last+1 {
+		t.Errorf("down past the last row: cursor %d, command %v; want Back and no fetch", moved.cursor, cmd != nil)
+	}
+
+	m.autoPage = true
+	m, cmd = press(t, m, "up", "down")
+	if cmd != nil || m.cursor != last {
+		t.Fatalf("down onto the last row: cursor %d, want %d", m.cursor, last)
+	}
+	m, cmd = press(t, m, "down")
+	m = run(t, m, cmd)
+	if f.last() != "GET /api/v1/customers?page=2&page_size=20" || m.cursor != firstItemRow(ui.ViewCustomers) {
+		t.Errorf("down past the last row requested %q with the cursor on %d; want page 2 from its first item", f.last(), m.cursor)
+	}
+
+	// The last page has no next page, so the cursor moves on to Back
+	m, cmd = press(t, m, "]")
+	m = run(t, m, cmd)
+	m.cursor = firstItemRow(ui.ViewCustomers) + len(m.customers) - 1
+	m, cmd = press(t, m, "down")
+	if cmd != nil || m.cursor != firstItemRow(ui.ViewCustomers)+len(m.customers) {
+		t.Errorf("down past the last row of the last page: cursor %d, want Back", m.cursor)
+	}
+}
+
+func TestRefreshReturnsToFirstPage(t *testing.T) {
+	m, f := customerList(t)
+	m, cmd := press(t, m, "pgdown")
+	m = run(t, m, cmd)
+	m, cmd = press(t, m, "r")
+	m = run(t, m, cmd)
+	if f.last() != "GET /api/v1/customers?page=1&page_size=20" || m.customerPage.page != 1 {
+		t.Errorf("refresh requested %q, showing page %d; want page 1", f.last(), m.customerPage.page)
+	}
+}
+
+func TestPageStatus(t *testing.T) {
+	m, _ := newTestModel(t, customersAPI(t, 0))
+	if got := m.pageStatus(); got != "" {
+		t.Errorf("pageStatus on the main menu = %q, want none", got)
+	}
+	// Before the first fetch, and for an empty list
+	m.view = ui.ViewPrintJobs
+	if got := m.pageStatus(); got != "Page 1/1 · 0 items" {
+		t.Errorf("pageStatus before loading = %q", got)
+	}
+	m.view = ui.ViewCustomers
+	m = run(t, m, m.fetchCustomers(1))
+	if got := m.pageStatus(); got != "Page 1/1 · 0 items" {
+		t.Errorf("pageStatus of an empty list = %q", got)
+	}
+}
@@ -153,6 +153,7 @@ func teaHandler(s ssh.Session) (tea.Model, []tea.ProgramOption) {
 		height:      height,
 		inputs:      inputs,
 		formEntity:  formEntity,
+		autoPage:    autoPageFromEnv(),
 	}
 
 	return m, []tea.ProgramOption{tea.WithAltScreen()}
@@ -1,9 +1,11 @@
 package ui
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 )
 
 // Message type constants for consistent UI messaging
@@ -542,7 +544,7 @@ func FormatStatus(status string) string {
 		return StatusActiveStyle.Render(normalized)
 	case "DRAFT", "PENDING", "QUEUED", "PROCESSING", "BUSY":
 		return StatusPendingStyle.Render(normalized)
-	case "CANCELLED", "FAILED", "SUSPENDED", "ERROR":
+	case "CANCELLED", "FAILED", "DEAD", "SUSPENDED", "ERROR":
 		return StatusInactiveStyle.Render(normalized)
 	case "OFFLINE", "DISABLED", "INACTIVE":
 		return StatusOfflineStyle.Render(normalized)
@@ -641,6 +643,15 @@ func RenderCardSection(s CardSection) string {
 	return b.String()
 }
 
+// RenderProgressBar renders a bar of the given width filled to pct percent, followed by the percentage
+func RenderProgressBar(pct, width int) string {
+	pct = max(0, min(100, pct))
+	filled := width * pct / 100
+	bar := ProgressFillStyle.Render(strings.Repeat(" ", filled)) +
+		ProgressBarStyle.Render(strings.Repeat(" ", width-filled))
+	return bar + " " + ProgressTextStyle.Render(fmt.Sprintf("%3d%%", pct))
+}
+
 // RenderCardDivider renders a horizontal divider
 func RenderCardDivider(width int) string {
 	return CardDividerStyle.Render(strings.Repeat("─", width))
@@ -659,6 +670,29 @@ func RenderCard(header string, sections []CardSection, width int) string {
 	return CardStyle.Width(width).Render(b.String())
 }
 
+// RenderOverlay draws fg centred over bg, keeping the parts of bg's lines
+// left and right of it
+func RenderOverlay(bg, fg string) string {
+	bgLines := strings.Split(bg, "\n")
+	fgLines := strings.Split(fg, "\n")
+	fgWidth := lipgloss.Width(fg)
+	top := max(0, (len(bgLines)-len(fgLines))/2)
+	left := max(0, (lipgloss.Width(bg)-fgWidth)/2)
+
+	for i, line := range fgLines {
+		row := top + i
+		if row >= len(bgLines) {
+			bgLines = append(bgLines, "")
+		}
+		under := bgLines[row]
+		if pad := left - ansi.StringWidth(under); pad > 0 {
+			under += strings.Repeat(" ", pad)
+		}
+		bgLines[row] = ansi.Truncate(under, left, "") + line + ansi.TruncateLeft(under, left+fgWidth, "")
+	}
+	return strings.Join(bgLines, "\n")
+}
+
 // RenderTwoColumnCard renders fields in a 2-column layout
 func RenderTwoColumnCard(header string, leftFields, rightFields []CardField, width int) string {
 	var b strings.Builder
@@ -19,6 +19,7 @@ const (
 	ViewContractEdit
 	ViewPrintJobs
 	ViewPrintJobDetail
+	ViewNotifications
 	ViewSettings
 	ViewLogin
 )
@@ -28,6 +29,7 @@ type MenuItem struct {
 	Title       string
 	Description string
 	View        ViewState
+	Feature     string // Feature flag the item needs; empty for items always shown
 }
 
 // mainMenuItems is the internal slice of menu items
@@ -17,11 +17,13 @@ const (
 	fmtDateTimeDisplay = "2006-01-02 15:04"
 	msgFormSaveCancel  = "Press Enter to save, Esc to cancel"
 	labelPrintJobs     = "Print Jobs"
+	labelNotifications = "Notifications"
 )
 
 // listConfig holds configuration for rendering a list view
 type listConfig struct {
 	title       string
+	header      string // rendered between the title and the rows, e.g. the search bar
 	createLabel string // empty to disable create option
 	itemCount   int
 	cursor      int
@@ -32,6 +34,7 @@ type listConfig struct {
 func renderList(cfg listConfig) string {
 	var b strings.Builder
 	b.WriteString(ui.SubtitleStyle.Render(cfg.title) + "\n\n")
+	b.WriteString(cfg.header)
 
 	offset := 0
 
@@ -169,7 +172,7 @@ func (m Model) renderMainMenu() string {
 	var b strings.Builder
 	b.WriteString(ui.SubtitleStyle.Render("Main Menu") + "\n\n")
 
-	menuItems := ui.GetMainMenuItems()
+	menuItems := m.mainMenuItems()
 	for i, item := range menuItems {
 		cursor := "  "
 		style := ui.MenuItemStyle
@@ -193,13 +196,15 @@ func (m Model) renderMainMenu() string {
 }
 
 func (m Model) renderCustomerList() string {
+	customers := m.visibleCustomers()
 	return renderList(listConfig{
 		title:       "Customers",
+		header:      m.renderFilterBar(),
 		createLabel: "[+] Create New Customer",
-		itemCount:   len(m.customers),
+		itemCount:   len(customers),
 		cursor:      m.cursor,
 		renderRow: func(idx int, selected bool) string {
-			c := m.customers[idx]
+			c := customers[idx]
 			cursor, style := renderCursor(selected)
 			status := ui.FormatBool(c.Active)
 			return fmt.Sprintf("%s%s | %s | %s | %s\n",
@@ -309,13 +314,15 @@ func (m Model) renderCustomerForm() string {
 }
 
 func (m Model) renderServiceList() string {
+	services := m.visibleServices()
 	return renderList(listConfig{
 		title:       "Services",
+		header:      m.renderFilterBar(),
 		createLabel: "[+] Create New Service",
-		itemCount:   len(m.services),
+		itemCount:   len(services),
 		cursor:      m.cursor,
 		renderRow: func(idx int, selected bool) string {
-			s := m.services[idx]
+			s := services[idx]
 			cursor, style := renderCursor(selected)
 			status := ui.FormatBool(s.Active)
 			return fmt.Sprintf("%s%s | %s | %s %s/%s | %s\n",
@@ -426,13 +433,15 @@ func (m Model) renderServiceForm() string {
 }
 
 func (m Model) renderContractList() string {
+	contracts := m.visibleContracts()
 	return renderList(listConfig{
 		title:       "Contracts",
+		header:      m.renderFilterBar(),
 		createLabel: "[+] Create New Contract",
-		itemCount:   len(m.contracts),
+		itemCount:   len(contracts),
 		cursor:      m.cursor,
 		renderRow: func(idx int, selected bool) string {
-			c := m.contracts[idx]
+			c := contracts[idx]
 			cursor, style := renderCursor(selected)
 			status := ui.FormatStatus(c.Status)
 			return fmt.Sprintf("%s%s | %s | %s | %s\n",
@@ -497,26 +506,27 @@ func (m Model) renderContractDetail() string {
 
 	// Actions with icons
 	b.WriteString(ui.CardSectionStyle.Render("⚡ Actions") + "\n")
-	actions := []struct {
-		icon string
-		text string
-	}{
-		{"✎", "Edit"},
-		{"⚙", "Generate"},
-		{"⎙", "Print"},
-		{"✓", "Sign"},
-		{"←", "Back"},
-	}
-	for i, action := range actions {
+	icons := map[string]string{
+		"Edit":                 "✎",
+		"Generate":             "⚙",
+		"Print":                "⎙",
+		"Regenerate and print": "↻",
+		"Sign":                 "✓",
+		"Back":                 "←",
+	}
+	for i, action := range contractDetailActions(m) {
 		cursor := "  "
 		style := ui.MenuItemStyle
 		if m.cursor == i {
 			cursor = ui.CursorStyle.Render("▸ ")
 			style = ui.SelectedMenuItemStyle
 		}
-		b.WriteString(fmt.Sprintf("%s%s %s\n", cursor, action.icon, style.Render(action.text)))
+		b.WriteString(fmt.Sprintf("%s%s %s\n", cursor, icons[action], style.Render(action)))
 	}
 
+	b.WriteString("\n")
+	b.WriteString(m.renderContractItems())
+
 	return b.String()
 }
 
@@ -568,12 +578,54 @@ func (m Model) renderPrintJobList() string {
 			j := m.printJobs[idx]
 			cursor, style := renderCursor(selected)
 			status := ui.FormatStatus(j.Status)
-			return fmt.Sprintf("%s%s | Contract: %d | %s | %s\n",
+			priority := ""
+			if j.Priority == "HIGH" {
+				priority = " " + ui.BadgeWarningStyle.Render("HIGH")
+			}
+			return fmt.Sprintf("%s%s | Contract: %d | %s | %s%s\n",
 				cursor,
 				style.Render(fmt.Sprintf("#%-5d", j.ID)),
 				j.ContractID,
 				j.Format,
-				status)
+				status,
+				priority)
+		},
+	})
+}
+
+// renderNotificationList lists the user's notifications, unread ones marked
+// with a dot
+func (m Model) renderNotificationList() string {
+	if len(m.notifications) == 0 {
+		var b strings.Builder
+		b.WriteString(ui.SubtitleStyle.Render(labelNotifications) + "\n\n")
+		b.WriteString(ui.InfoStyle.Render("No notifications") + "\n\n")
+
+		// Keep the Mark all read row so cursors match getMaxItems
+		cursor, style := renderCursor(m.cursor == 0)
+		b.WriteString(fmt.Sprintf(fmtMenuItemNL, cursor, style.Render("Mark all as read")))
+		cursor, style = renderCursor(m.cursor == 1)
+		b.WriteString(fmt.Sprintf(fmtMenuItemNL, cursor, style.Render(backToMainMenu)))
+		return b.String()
+	}
+
+	return renderList(listConfig{
+		title:       fmt.Sprintf("%s (%d unread)", labelNotifications, m.unreadNotifications),
+		createLabel: "Mark all as read",
+		itemCount:   len(m.notifications),
+		cursor:      m.cursor,
+		renderRow: func(idx int, selected bool) string {
+			n := m.notifications[idx]
+			cursor, style := renderCursor(selected)
+			marker := "  "
+			if n.ReadAt == nil {
+				marker = ui.StatusActiveStyle.Render("●") + " "
+			}
+			return fmt.Sprintf("%s%s%s | %s\n",
+				cursor,
+				marker,
+				style.Render(truncate(n.Title, 48)),
+				n.CreatedAt.Local().Format(fmtDateTimeDisplay))
 		},
 	})
 }
@@ -594,6 +646,16 @@ func (m Model) renderPrintJobDetail() string {
 		completedAt = j.CompletedAt.Format(fmtDateTimeDisplay)
 	}
 
+	nextRetry := "N/A"
+	if j.NextRetryAt != nil {
+		nextRetry = j.NextRetryAt.Format(fmtDateTimeDisplay)
+	}
+
+	lastError := "None"
+	if j.ErrorMessage != "" {
+		lastError = truncate(j.ErrorMessage, 36)
+	}
+
 	// Build sections
 	sections := []ui.CardSection{
 		{
@@ -602,6 +664,7 @@ func (m Model) renderPrintJobDetail() string {
 			Fields: []ui.CardField{
 				{Label: "Contract ID", Value: fmt.Sprintf("%d", j.ContractID)},
 				{Label: "Format", Value: j.Format},
+				{Label: "Priority", Value: j.Priority},
 				{Label: "Requested By", Value: j.RequestedBy},
 			},
 		},
@@ -621,13 +684,44 @@ func (m Model) renderPrintJobDetail() string {
 				{Label: "Completed At", Value: completedAt},
 			},
 		},
+		{
+			Title: "Retries",
+			Icon:  "◈",
+			Fields: []ui.CardField{
+				{Label: "Retry Count", Value: fmt.Sprintf("%d", j.RetryCount)},
+				{Label: "Next Retry", Value: nextRetry},
+				{Label: "Last Error", Value: lastError},
+			},
+		},
 	}
 
 	cardWidth := 52
 	b.WriteString(ui.RenderCard(header, sections, cardWidth))
 	b.WriteString("\n")
 
-	b.WriteString(ui.InfoStyle.Render("Press Esc to go back"))
+	if printJobActive(j) {
+		b.WriteString(ui.RenderProgressBar(j.ProgressPct, cardWidth-8) + "\n\n")
+	}
+
+	// Actions depend on the job status
+	b.WriteString(ui.CardSectionStyle.Render("⚡ Actions") + "\n")
+	for i, action := range printJobDetailActions(j) {
+		cursor := "  "
+		style := ui.MenuItemStyle
+		if m.cursor == i {
+			cursor = ui.CursorStyle.Render("▸ ")
+			style = ui.SelectedMenuItemStyle
+		}
+		icon := "←"
+		switch action {
+		case "Retry":
+			icon = "↻"
+		case "Cancel":
+			icon = "✕"
+		}
+		b.WriteString(fmt.Sprintf("%s%s %s\n", cursor, icon, style.Render(action)))
+	}
+
 	return b.String()
 }
 
@@ -656,6 +750,21 @@ func (m Model) renderSettings() string {
 	header := ui.RenderCardHeader("◆", "Settings")
 
 	// Build sections
+	session := []ui.CardField{
+		{Label: "User", Value: m.user},
+	}
+	if p := m.profile; p != nil {
+		session = append(session,
+			ui.CardField{Label: "Full Name", Value: p.FullName},
+			ui.CardField{Label: "Email", Value: p.Email},
+			ui.CardField{Label: "Roles", Value: strings.Join(p.Roles, ", ")},
+		)
+	}
+	session = append(session,
+		ui.CardField{Label: "Tenant ID", Value: m.tenantID},
+		ui.CardField{Label: "Signer", Value: m.signer},
+		ui.CardField{Label: "Auto Page", Value: ui.FormatBool(m.autoPage)},
+	)
 	sections := []ui.CardSection{
 		{
 			Title: "Connection",
@@ -666,13 +775,9 @@ func (m Model) renderSettings() string {
 			},
 		},
 		{
-			Title: "Session",
-			Icon:  "◈",
-			Fields: []ui.CardField{
-				{Label: "User", Value: m.user},
-				{Label: "Tenant ID", Value: m.tenantID},
-				{Label: "Signer", Value: m.signer},
-			},
+			Title:  "Session",
+			Icon:   "◈",
+			Fields: session,
 		},
 	}
 
@@ -680,7 +785,7 @@ func (m Model) renderSettings() string {
 	b.WriteString(ui.RenderCard(header, sections, cardWidth))
 	b.WriteString("\n")
 
-	b.WriteString(ui.InfoStyle.Render("Set GPRINT_API_URL and GPRINT_TOKEN environment variables"))
+	b.WriteString(ui.InfoStyle.Render("Set GPRINT_API_URL and GPRINT_TOKEN environment variables; GPRINT_AUTO_PAGE=true opens the next page past a list's last row"))
 	return b.String()
 }
 
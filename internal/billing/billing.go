@@ -0,0 +1,194 @@
+// Package billing computes when a contract is invoiced and for how much.
+// It does no I/O: callers pass the contract terms and get the periods back.
+package billing
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/zlovtnik/gprint/internal/models"
+)
+
+// MaxPeriods bounds a schedule; a monthly contract of a century stays under it
+const MaxPeriods = 1200
+
+var (
+	// ErrNoTerm indicates a recurring contract with neither a duration nor
+	// an end date after its start, so there is nothing to divide the total over
+	ErrNoTerm = errors.New("contract has no term to bill over")
+
+	// ErrUnknownCycle indicates a billing cycle the schedule cannot step by
+	ErrUnknownCycle = errors.New("unknown billing cycle")
+
+	// ErrTooManyPeriods indicates a schedule longer than MaxPeriods
+	ErrTooManyPeriods = errors.New("billing schedule has too many periods")
+)
+
+// Terms are the contract fields a billing schedule is computed from
+type Terms struct {
+	Start          time.Time
+	DurationMonths int        // Term length; takes precedence over End when above 0
+	End            *time.Time // Last day of the term, inclusive
+	Cycle          models.BillingCycle
+	Total          decimal.Decimal // Amount invoiced over the whole term
+	NetDays        int             // Days from a period's start to its due date
+	Rounding       models.RoundingMode
+}
+
+// cycleMonths is the length of a period of each recurring cycle
+var cycleMonths = map[models.BillingCycle]int{
+	models.BillingCycleMonthly:   1,
+	models.BillingCycleQuarterly: 3,
+	models.BillingCycleYearly:    12,
+}
+
+// Schedule divides the total into one period per billing cycle from the
+// start date, invoiced in advance: each period is due NetDays after it
+// starts. A period starting on the 31st moves to the last day of shorter
+// months and back to the 31st after them. When the term is not a whole
+// number of cycles, the last period is shorter and billed pro rata by its
+// length in months, counting a part month by its days. Amounts are rounded
+// to cents, the last period taking the remainder so they add up to the
+// total.
+//
+// A ONCE cycle is billed in a single period covering the whole term, or
+// only the start date when the contract has no term.
+func Schedule(t Terms) ([]models.BillingPeriod, error) {
+	start := civilDate(t.Start)
+	end, hasTerm := termEnd(t, start)
+
+	if t.Cycle == models.BillingCycleOnce {
+		last := start
+		if hasTerm {
+			last = end.AddDate(0, 0, -1)
+		}
+		return []models.BillingPeriod{{
+			Number:  1,
+			Start:   start,
+			End:     last,
+			DueDate: start.AddDate(0, 0, t.NetDays),
+			Amount:  t.Total,
+		}}, nil
+	}
+
+	step, ok := cycleMonths[t.Cycle]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownCycle, t.Cycle)
+	}
+	if !hasTerm {
+		return nil, ErrNoTerm
+	}
+
+	var periods []models.BillingPeriod
+	var weights []decimal.Decimal
+	totalWeight := decimal.Zero
+	for n := 0; ; n += step {
+		periodStart := addMonths(start, n)
+		if !periodStart.Before(end) {
+			break
+		}
+		if len(periods) == MaxPeriods {
+			return nil, fmt.Errorf("%w: more than %d", ErrTooManyPeriods, MaxPeriods)
+		}
+		periodEnd := addMonths(start, n+step)
+		weight := decimal.NewFromInt(int64(step))
+		if periodEnd.After(end) {
+			periodEnd = end
+			weight = monthsBetween(start, n, end)
+		}
+		periods = append(periods, models.BillingPeriod{
+			Number:  len(periods) + 1,
+			Start:   periodStart,
+			End:     periodEnd.AddDate(0, 0, -1),
+			DueDate: periodStart.AddDate(0, 0, t.NetDays),
+		})
+		weights = append(weights, weight)
+		totalWeight = totalWeight.Add(weight)
+	}
+
+	billed := decimal.Zero
+	for i := range periods {
+		if i == len(periods)-1 {
+			periods[i].Amount = t.Total.Sub(billed)
+			break
+		}
+		periods[i].Amount = t.Rounding.Round(t.Total.Mul(weights[i]).Div(totalWeight))
+		billed = billed.Add(periods[i].Amount)
+	}
+	return periods, nil
+}
+
+// termEnd returns the day after the term's last day, reporting false when
+// the contract has no term ending after its start
+func termEnd(t Terms, start time.Time) (time.Time, bool) {
+	if t.DurationMonths > 0 {
+		return addMonths(start, t.DurationMonths), true
+	}
+	if t.End != nil {
+		end := civilDate(*t.End).AddDate(0, 0, 1)
+		return end, end.After(start)
+	}
+	return time.Time{}, false
+}
+
+// monthsBetween measures from n months after start to end, which is less
+// than a cycle later: whole months, then the rest as a fraction of the
+// month it falls in
+func monthsBetween(start time.Time, n int, end time.Time) decimal.Decimal {
+	whole := 0
+	for !addMonths(start, n+whole+1).After(end) {
+		whole++
+	}
+	from, to := addMonths(start, n+whole), addMonths(start, n+whole+1)
+	part := decimal.NewFromInt(int64(days(from, end))).Div(decimal.NewFromInt(int64(days(from, to))))
+	return decimal.NewFromInt(int64(whole)).Add(part)
+}
+
+// addMonths returns the date n months after start, on start's day of the
+// month or the last day of shorter months
+func addMonths(start time.Time, n int) time.Time {
+	year, month, day := start.Date()
+	first := time.Date(year, month+time.Month(n), 1, 0, 0, 0, 0, time.UTC)
+	if last := first.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// civilDate drops the time of day and zone, keeping the calendar date
+func civilDate(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// days counts the days from a to b, both civil dates
+func days(a, b time.Time) int {
+	return int(b.Sub(a).Hours() / 24)
+}
+
+var netTerms = regexp.MustCompile(`(?i)^net[\s-]*(\d{1,3})$`)
+
+// ParsePaymentTerms reads the days until payment is due from payment terms
+// such as "NET30", "Net 45" or "NET-15". Empty terms, "DUE ON RECEIPT" and
+// "IMMEDIATE" are due at once. It reports false for anything else.
+func ParsePaymentTerms(terms string) (int, bool) {
+	terms = strings.TrimSpace(terms)
+	switch strings.ToUpper(terms) {
+	case "", "DUE ON RECEIPT", "IMMEDIATE":
+		return 0, true
+	}
+	m := netTerms.FindStringSubmatch(terms)
+	if m == nil {
+		return 0, false
+	}
+	n, err := strconv.Atoi(m[1])
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
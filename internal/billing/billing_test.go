@@ -0,0 +1,185 @@
+package billing
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/zlovtnik/gprint/internal/models"
+)
+
+func date(s string) time.Time {
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// period formats a period as "start..end due amount" for comparison
+func period(p models.BillingPeriod) string {
+	return fmt.Sprintf("%s..%s due %s %s", p.Start.Format(time.DateOnly), p.End.Format(time.DateOnly),
+		p.DueDate.Format(time.DateOnly), p.Amount.StringFixed(2))
+}
+
+func TestSchedule(t *testing.T) {
+	saoPaulo := time.FixedZone("BRT", -3*60*60)
+	end := func(s string) *time.Time { d := date(s); return &d }
+
+	tests := []struct {
+		name  string
+		terms Terms
+		want  []string
+	}{
+		{
+			"monthly from the 31st through a leap February",
+			Terms{Start: date("2024-01-31"), DurationMonths: 3, Cycle: models.BillingCycleMonthly, Total: decimal.NewFromInt(300), NetDays: 30},
+			[]string{
+				"2024-01-31..2024-02-28 due 2024-03-01 100.00",
+				"2024-02-29..2024-03-30 due 2024-03-30 100.00",
+				"2024-03-31..2024-04-29 due 2024-04-30 100.00",
+			},
+		},
+		{
+			"monthly from the 31st through a common February",
+			Terms{Start: date("2023-01-31"), DurationMonths: 2, Cycle: models.BillingCycleMonthly, Total: decimal.NewFromInt(200)},
+			[]string{
+				"2023-01-31..2023-02-27 due 2023-01-31 100.00",
+				"2023-02-28..2023-03-30 due 2023-02-28 100.00",
+			},
+		},
+		{
+			"yearly from a leap day",
+			Terms{Start: date("2024-02-29"), DurationMonths: 24, Cycle: models.BillingCycleYearly, Total: decimal.RequireFromString("1000.01"), Rounding: models.RoundingHalfEven},
+			[]string{
+				"2024-02-29..2025-02-27 due 2024-02-29 500.00",
+				"2025-02-28..2026-02-27 due 2025-02-28 500.01",
+			},
+		},
+		{
+			"yearly half up",
+			Terms{Start: date("2024-02-29"), DurationMonths: 24, Cycle: models.BillingCycleYearly, Total: decimal.RequireFromString("1000.01"), Rounding: models.RoundingHalfUp},
+			[]string{
+				"2024-02-29..2025-02-27 due 2024-02-29 500.01",
+				"2025-02-28..2026-02-27 due 2025-02-28 500.00",
+			},
+		},
+		{
+			"quarterly from mid-month with a short last quarter",
+			Terms{Start: date("2024-01-15"), End: end("2024-08-14"), Cycle: models.BillingCycleQuarterly, Total: decimal.NewFromInt(700), NetDays: 15},
+			[]string{
+				"2024-01-15..2024-04-14 due 2024-01-30 300.00",
+				"2024-04-15..2024-07-14 due 2024-04-30 300.00",
+				"2024-07-15..2024-08-14 due 2024-07-30 100.00",
+			},
+		},
+		{
+			// The last 15 days are billed as 15/31 of the month they fall in
+			"monthly with a part month",
+			Terms{Start: date("2024-02-10"), End: end("2024-03-24"), Cycle: models.BillingCycleMonthly, Total: decimal.NewFromInt(46)},
+			[]string{
+				"2024-02-10..2024-03-09 due 2024-02-10 31.00",
+				"2024-03-10..2024-03-24 due 2024-03-10 15.00",
+			},
+		},
+		{
+			"the last period takes the rounding remainder",
+			Terms{Start: date("2024-05-01"), DurationMonths: 3, Cycle: models.BillingCycleMonthly, Total: decimal.NewFromInt(100)},
+			[]string{
+				"2024-05-01..2024-05-31 due 2024-05-01 33.33",
+				"2024-06-01..2024-06-30 due 2024-06-01 33.33",
+				"2024-07-01..2024-07-31 due 2024-07-01 33.34",
+			},
+		},
+		{
+			"duration takes precedence over the end date",
+			Terms{Start: date("2024-01-01"), DurationMonths: 12, End: end("2024-03-31"), Cycle: models.BillingCycleYearly, Total: decimal.NewFromInt(1200)},
+			[]string{"2024-01-01..2024-12-31 due 2024-01-01 1200.00"},
+		},
+		{
+			"the start's calendar date is kept across zones",
+			Terms{Start: time.Date(2024, 3, 31, 23, 30, 0, 0, saoPaulo), DurationMonths: 1, Cycle: models.BillingCycleMonthly, Total: decimal.NewFromInt(10)},
+			[]string{"2024-03-31..2024-04-29 due 2024-03-31 10.00"},
+		},
+		{
+			"once over the term",
+			Terms{Start: date("2024-03-15"), DurationMonths: 12, Cycle: models.BillingCycleOnce, Total: decimal.RequireFromString("999.99"), NetDays: 10},
+			[]string{"2024-03-15..2025-03-14 due 2024-03-25 999.99"},
+		},
+		{
+			"once without a term",
+			Terms{Start: date("2024-03-15"), Cycle: models.BillingCycleOnce, Total: decimal.NewFromInt(50)},
+			[]string{"2024-03-15..2024-03-15 due 2024-03-15 50.00"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			periods, err := Schedule(tt.terms)
+			if err != nil {
+				t.Fatalf("Schedule: %v", err)
+			}
+			got := make([]string, len(periods))
+			sum := decimal.Zero
+			for i, p := range periods {
+				got[i] = period(p)
+				sum = sum.Add(p.Amount)
+				if p.Number != i+1 {
+					t.Errorf("period %d is numbered %d", i+1, p.Number)
+				}
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("periods:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+			if !sum.Equal(tt.terms.Total) {
+				t.Errorf("periods add up to %s, want the total %s", sum, tt.terms.Total)
+			}
+		})
+	}
+}
+
+func TestScheduleErrors(t *testing.T) {
+	before := date("2023-12-31")
+	tests := []struct {
+		name  string
+		terms Terms
+		want  error
+	}{
+		{"no term", Terms{Start: date("2024-01-01"), Cycle: models.BillingCycleMonthly}, ErrNoTerm},
+		{"end before start", Terms{Start: date("2024-01-01"), End: &before, Cycle: models.BillingCycleMonthly}, ErrNoTerm},
+		{"unknown cycle", Terms{Start: date("2024-01-01"), DurationMonths: 12, Cycle: "WEEKLY"}, ErrUnknownCycle},
+		{"too many periods", Terms{Start: date("2024-01-01"), DurationMonths: MaxPeriods + 1, Cycle: models.BillingCycleMonthly}, ErrTooManyPeriods},
+	}
+	for _, tt := range tests {
+		if _, err := Schedule(tt.terms); !errors.Is(err, tt.want) {
+			t.Errorf("%s: Schedule error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestParsePaymentTerms(t *testing.T) {
+	tests := []struct {
+		terms string
+		days  int
+		ok    bool
+	}{
+		{"", 0, true},
+		{"Due on receipt", 0, true},
+		{"IMMEDIATE", 0, true},
+		{"NET30", 30, true},
+		{"Net 45", 45, true},
+		{" net-15 ", 15, true},
+		{"NET1000", 0, false},
+		{"30 days", 0, false},
+		{"NET", 0, false},
+	}
+	for _, tt := range tests {
+		days, ok := ParsePaymentTerms(tt.terms)
+		if days != tt.days || ok != tt.ok {
+			t.Errorf("ParsePaymentTerms(%q) = %d, %v; want %d, %v", tt.terms, days, ok, tt.days, tt.ok)
+		}
+	}
+}
@@ -1,27 +1,176 @@
 package config
 
 import (
-	"fmt"
 	"os"
-	"strconv"
+	"reflect"
+	"strings"
 	"time"
 )
 
 // Config holds all configuration for the application
 type Config struct {
-	Server   ServerConfig
-	Database OracleConfig
-	JWT      JWTConfig
-	Auth     AuthConfig
-	Keycloak KeycloakConfig
-	Print    PrintConfig
-	LogLevel string
+	Server     ServerConfig
+	Database   OracleConfig
+	JWT        JWTConfig
+	Auth       AuthConfig
+	Keycloak   KeycloakConfig
+	Print      PrintConfig
+	Tenant     TenantConfig
+	Features   FeaturesConfig
+	Import     ImportConfig
+	Storage    StorageConfig
+	Webhook    WebhookConfig
+	Email      EmailConfig
+	Invoice    InvoiceConfig
+	Attachment AttachmentConfig
+	Cleanup    CleanupConfig
+	Metrics    MetricsConfig
+	RateLimit  RateLimitConfig
+	Security   SecurityConfig
+	LogLevel   string
+
+	// Sources records where each setting came from ("env", "file" or
+	// "default"), keyed by its config file path, for debug logging
+	Sources map[string]string
 }
 
 // PrintConfig holds print service configuration
 type PrintConfig struct {
-	OutputPath  string
-	JobInterval time.Duration
+	OutputPath   string
+	JobInterval  time.Duration
+	Workers      int           // Jobs rendered concurrently per polling cycle
+	InstanceID   string        // Identifies this replica on the shared queue; generated when empty
+	LeaseTTL     time.Duration // Lease on a claimed job, renewed while rendering
+	MaxRetries   int           // Automatic retries before a job is marked DEAD (0 disables)
+	RetryBackoff time.Duration // Base delay, doubled after each failed attempt
+	// RetentionDays is how long completed output files are kept (0 keeps them forever)
+	RetentionDays   int
+	CleanupInterval time.Duration
+	// WatermarkAdmins may print unsigned contracts with watermark=false
+	WatermarkAdmins []string
+	// VerifyBaseURL is the public address printed in the verification QR code (empty disables it)
+	VerifyBaseURL string
+	// VerifyQRPayload is the QR content template; {base_url}, {hash} and {contract_id} are substituted
+	VerifyQRPayload string
+}
+
+// TenantConfig holds the settings defaults for tenants that have not
+// overridden them through /api/v1/settings
+type TenantConfig struct {
+	DefaultCurrency       string // ISO 4217 code
+	DefaultLocale         string
+	ContractNumberPattern string // Must contain {SEQ}; may use {YYYY}, {YY} and {MM}
+}
+
+// FeaturesConfig holds the feature flag defaults for tenants that have not
+// set a flag
+type FeaturesConfig struct {
+	Enabled []string // Features on by default; the others are off
+}
+
+// ImportConfig limits bulk imports such as POST /api/v1/customers/import
+type ImportConfig struct {
+	MaxBytes  int // Largest accepted file; uploads are also capped by SERVER_MAX_UPLOAD_BYTES
+	MaxRows   int // Most data rows per file
+	BatchSize int // Rows inserted per transaction
+}
+
+// StorageConfig selects where print output is stored
+type StorageConfig struct {
+	Backend string // "local" (PRINT_OUTPUT_PATH) or "s3"
+	// DownloadMode is "stream" to proxy downloads through the server or
+	// "redirect" to send clients to a signed URL (falls back to stream for local)
+	DownloadMode string
+	SignedURLTTL time.Duration
+	S3           S3Config
+}
+
+// S3Config holds S3-compatible object storage configuration
+type S3Config struct {
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	PathStyle       bool // Required by MinIO and most self-hosted S3 implementations
+}
+
+// WebhookConfig holds webhook delivery configuration
+type WebhookConfig struct {
+	MaxAttempts   int           // Delivery attempts before a delivery is marked DEAD
+	Timeout       time.Duration // Per-attempt HTTP timeout
+	RetryBackoff  time.Duration // Base delay, doubled after each failed attempt
+	RetryInterval time.Duration // How often the background loop retries due deliveries
+}
+
+// EmailConfig holds print job notification email configuration
+type EmailConfig struct {
+	SMTPHost     string // empty disables notification emails
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	From         string
+	TLSMode      string // "none", "starttls" or "tls"
+	// AttachmentMaxBytes is the largest output attached to emails; larger output is linked
+	AttachmentMaxBytes int64
+	LinkBaseURL        string // Public API address used in download links
+	MaxAttempts        int
+	RetryBackoff       time.Duration
+}
+
+// AttachmentConfig holds contract attachment upload configuration. Files go
+// to the storage backend; with the local backend they are kept below Path.
+type AttachmentConfig struct {
+	MaxBytes     int      // Largest accepted file; uploads are also capped by SERVER_MAX_UPLOAD_BYTES
+	AllowedTypes []string // Media types accepted, e.g. application/pdf
+	Path         string
+}
+
+// InvoiceConfig holds draft invoice generation configuration
+type InvoiceConfig struct {
+	JobInterval time.Duration // How often draft invoices are created for started billing periods
+}
+
+// CleanupConfig schedules the nightly removal of expired generated contracts
+type CleanupConfig struct {
+	Enabled bool
+	At      string        // Local time of day the cleanup runs, HH:MM
+	Tenants []string      // Tenants cleaned up; empty means every tenant with expired generations
+	LockTTL time.Duration // How long a run keeps other instances from starting the same run
+}
+
+// MetricsConfig controls the Prometheus /metrics endpoint. It is served on
+// Addr when set, otherwise on the API listener when Token is set, and not at
+// all when neither is configured.
+type MetricsConfig struct {
+	Addr           string        // Separate listen address, e.g. ":9090"
+	Token          string        // Bearer token scrapers must present
+	SampleInterval time.Duration // How often DB pool and print job gauges are refreshed
+}
+
+// RateLimitConfig holds token bucket limits applied per tenant, or per client
+// IP before authentication. Auth endpoints have their own, stricter limit.
+type RateLimitConfig struct {
+	Enabled   bool
+	RPS       float64 // Sustained requests per second
+	Burst     int
+	AuthRPS   float64
+	AuthBurst int
+	// SweepRPS and SweepBurst limit integrity sweeps, which check every
+	// generated contract of a tenant
+	SweepRPS   float64
+	SweepBurst int
+	// TrustProxy takes client IPs from X-Forwarded-For, as set by Kong
+	TrustProxy bool
+}
+
+// SecurityConfig holds security response header settings
+type SecurityConfig struct {
+	ContentSecurityPolicy string
+	// HSTSEnabled sends Strict-Transport-Security with the HSTS value on
+	// HTTPS requests; disable it for HTTP-only on-prem installs
+	HSTSEnabled bool
+	HSTS        string
 }
 
 // ServerConfig holds server-related configuration
@@ -33,17 +182,54 @@ type ServerConfig struct {
 	IdleTimeout     time.Duration
 	MaxHeaderBytes  int
 	ShutdownTimeout time.Duration
+	// ShutdownDrainDelay is how long readiness fails before the listener closes,
+	// giving load balancers time to stop routing requests here
+	ShutdownDrainDelay time.Duration
+	// MaxBodyBytes limits request bodies; multipart uploads get MaxUploadBytes instead
+	MaxBodyBytes   int
+	MaxUploadBytes int
+	// GzipMinBytes is the smallest JSON response that is gzip compressed
+	GzipMinBytes int
+	// TLSCertFile and TLSKeyFile enable HTTPS when both are set
+	TLSCertFile string
+	TLSKeyFile  string
+	// TLSReloadInterval is how often the certificate files are checked for changes (0 disables reloading)
+	TLSReloadInterval time.Duration
+}
+
+// TLSEnabled reports whether the server should serve HTTPS
+func (c ServerConfig) TLSEnabled() bool {
+	return c.TLSCertFile != "" && c.TLSKeyFile != ""
 }
 
 // JWTConfig holds JWT-related configuration
 type JWTConfig struct {
+	// Secret enables HS256 tokens for legacy installs; Keycloak's RS256
+	// tokens are verified against JWKSURL either way
 	Secret     string
 	Expiration time.Duration
+	// Issuer is the iss claim tokens must carry; defaults to the Keycloak realm URL
+	Issuer string
+	// Audience is the client ID tokens must name in aud or azp; defaults to the Keycloak client ID
+	Audience  string
+	ClockSkew time.Duration // Tolerance when checking exp, nbf and iat
+	// JWKSURL is the realm's key set; defaults to the Keycloak certs endpoint
+	JWKSURL        string
+	JWKSCacheTTL   time.Duration // How long fetched keys are used before a refetch
+	JWKSMinRefresh time.Duration // Minimum gap between key set fetches
 }
 
 // AuthConfig holds authentication service configuration
 type AuthConfig struct {
 	BaseURL string
+	// EnforceRoles rejects requests missing a route's role; turn it off
+	// while roles are being assigned in existing deployments
+	EnforceRoles bool
+	// LoginMaxFailures failed logins for a username from one IP within
+	// LoginFailureWindow lock that pair out for LoginLockout; 0 disables
+	LoginMaxFailures   int
+	LoginFailureWindow time.Duration
+	LoginLockout       time.Duration
 }
 
 // KeycloakConfig holds Keycloak OAuth2 configuration
@@ -54,81 +240,213 @@ type KeycloakConfig struct {
 	ClientSecret string
 }
 
-// Load loads configuration from environment variables
-// Panics if required configuration is missing
-func Load() *Config {
-	return &Config{
+// RealmURL returns the realm's base URL, which Keycloak uses as the token issuer
+func (c KeycloakConfig) RealmURL() string {
+	return strings.TrimSuffix(c.BaseURL, "/") + "/realms/" + c.Realm
+}
+
+// Load loads configuration from environment variables and, when GPRINT_CONFIG
+// names a YAML file, from that file. Environment variables take precedence
+// over the file, which takes precedence over defaults. Unknown keys in the
+// file, invalid values in it and missing required settings are errors.
+func Load() (*Config, error) {
+	l, err := newLoader(os.Getenv(ConfigFileEnv))
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{
 		Server: ServerConfig{
-			Host:            getEnvOrDefault("SERVER_HOST", "0.0.0.0"),
-			Port:            getEnvOrDefault("SERVER_PORT", "8080"),
-			ReadTimeout:     getDurationOrDefault("SERVER_READ_TIMEOUT", 15*time.Second),
-			WriteTimeout:    getDurationOrDefault("SERVER_WRITE_TIMEOUT", 15*time.Second),
-			IdleTimeout:     getDurationOrDefault("SERVER_IDLE_TIMEOUT", 60*time.Second),
-			MaxHeaderBytes:  getIntOrDefault("SERVER_MAX_HEADER_BYTES", 1<<20), // 1MB default
-			ShutdownTimeout: getDurationOrDefault("SERVER_SHUTDOWN_TIMEOUT", 30*time.Second),
+			Host:               l.str("SERVER_HOST", "server.host", "0.0.0.0"),
+			Port:               l.str("SERVER_PORT", "server.port", "8080"),
+			ReadTimeout:        l.duration("SERVER_READ_TIMEOUT", "server.read_timeout", 15*time.Second),
+			WriteTimeout:       l.duration("SERVER_WRITE_TIMEOUT", "server.write_timeout", 15*time.Second),
+			IdleTimeout:        l.duration("SERVER_IDLE_TIMEOUT", "server.idle_timeout", 60*time.Second),
+			MaxHeaderBytes:     l.int("SERVER_MAX_HEADER_BYTES", "server.max_header_bytes", 1<<20), // 1MB default
+			ShutdownTimeout:    l.duration("SERVER_SHUTDOWN_TIMEOUT", "server.shutdown_timeout", 30*time.Second),
+			ShutdownDrainDelay: l.duration("SERVER_SHUTDOWN_DRAIN_DELAY", "server.shutdown_drain_delay", 5*time.Second),
+			MaxBodyBytes:       l.int("SERVER_MAX_BODY_BYTES", "server.max_body_bytes", 1<<20),      // 1MB default
+			MaxUploadBytes:     l.int("SERVER_MAX_UPLOAD_BYTES", "server.max_upload_bytes", 32<<20), // 32MB default
+			GzipMinBytes:       l.int("SERVER_GZIP_MIN_BYTES", "server.gzip_min_bytes", 1024),
+			TLSCertFile:        l.str("SERVER_TLS_CERT_FILE", "server.tls_cert_file", ""),
+			TLSKeyFile:         l.str("SERVER_TLS_KEY_FILE", "server.tls_key_file", ""),
+			TLSReloadInterval:  l.duration("SERVER_TLS_RELOAD_INTERVAL", "server.tls_reload_interval", time.Minute),
 		},
 		Database: OracleConfig{
-			Host:         getEnvOrDefault("ORACLE_HOST", "localhost"),
-			Port:         getEnvOrDefault("ORACLE_PORT", "1521"),
-			Service:      getEnvOrDefault("ORACLE_SERVICE", "ORCL"),
-			User:         os.Getenv("ORACLE_USER"),
-			Password:     os.Getenv("ORACLE_PASSWORD"),
-			MaxOpenConns: getIntOrDefault("ORACLE_MAX_OPEN_CONNS", 25),
-			MaxIdleConns: getIntOrDefault("ORACLE_MAX_IDLE_CONNS", 5),
-			WalletPath:   os.Getenv("ORACLE_WALLET_PATH"),
-			TNSAlias:     os.Getenv("ORACLE_TNS_ALIAS"),
+			Host:               l.str("ORACLE_HOST", "database.host", "localhost"),
+			Port:               l.str("ORACLE_PORT", "database.port", "1521"),
+			Service:            l.str("ORACLE_SERVICE", "database.service", "ORCL"),
+			User:               l.str("ORACLE_USER", "database.user", ""),
+			Password:           l.str("ORACLE_PASSWORD", "database.password", ""),
+			MaxOpenConns:       l.int("ORACLE_MAX_OPEN_CONNS", "database.max_open_conns", 25),
+			MaxIdleConns:       l.int("ORACLE_MAX_IDLE_CONNS", "database.max_idle_conns", 5),
+			ConnMaxLifetime:    l.duration("ORACLE_CONN_MAX_LIFETIME", "database.conn_max_lifetime", 5*time.Minute),
+			ConnMaxIdleTime:    l.duration("ORACLE_CONN_MAX_IDLE_TIME", "database.conn_max_idle_time", 2*time.Minute),
+			ConnectAttempts:    l.int("ORACLE_CONNECT_ATTEMPTS", "database.connect_attempts", 5),
+			ConnectBackoff:     l.duration("ORACLE_CONNECT_BACKOFF", "database.connect_backoff", 2*time.Second),
+			QueryTimeout:       l.duration("ORACLE_QUERY_TIMEOUT", "database.query_timeout", 30*time.Second),
+			SlowQueryThreshold: l.duration("ORACLE_SLOW_QUERY_THRESHOLD", "database.slow_query_threshold", 500*time.Millisecond),
+			WalletPath:         l.str("ORACLE_WALLET_PATH", "database.wallet_path", ""),
+			TNSAlias:           l.str("ORACLE_TNS_ALIAS", "database.tns_alias", ""),
 		},
 		JWT: JWTConfig{
-			Secret:     requireEnv("JWT_SECRET"),
-			Expiration: getDurationOrDefault("JWT_EXPIRATION", 24*time.Hour),
+			Secret:         l.str("JWT_SECRET", "jwt.secret", ""),
+			Expiration:     l.duration("JWT_EXPIRATION", "jwt.expiration", 24*time.Hour),
+			Issuer:         l.str("JWT_ISSUER", "jwt.issuer", ""),
+			Audience:       l.str("JWT_AUDIENCE", "jwt.audience", ""),
+			ClockSkew:      l.duration("JWT_CLOCK_SKEW", "jwt.clock_skew", 30*time.Second),
+			JWKSURL:        l.str("JWT_JWKS_URL", "jwt.jwks_url", ""),
+			JWKSCacheTTL:   l.duration("JWT_JWKS_CACHE_TTL", "jwt.jwks_cache_ttl", 10*time.Minute),
+			JWKSMinRefresh: l.duration("JWT_JWKS_MIN_REFRESH", "jwt.jwks_min_refresh", 30*time.Second),
 		},
 		Auth: AuthConfig{
-			BaseURL: getEnvOrDefault("AUTH_SERVICE_URL", "http://localhost:8081"),
+			BaseURL:            l.str("AUTH_SERVICE_URL", "auth.base_url", "http://localhost:8081"),
+			EnforceRoles:       l.bool("AUTH_ENFORCE_ROLES", "auth.enforce_roles", true),
+			LoginMaxFailures:   l.int("AUTH_LOGIN_MAX_FAILURES", "auth.login_max_failures", 5),
+			LoginFailureWindow: l.duration("AUTH_LOGIN_FAILURE_WINDOW", "auth.login_failure_window", 15*time.Minute),
+			LoginLockout:       l.duration("AUTH_LOGIN_LOCKOUT", "auth.login_lockout", 15*time.Minute),
 		},
 		Keycloak: KeycloakConfig{
-			BaseURL:      getEnvOrDefault("KEYCLOAK_URL", "http://localhost:8180"),
-			Realm:        getEnvOrDefault("KEYCLOAK_REALM", "master"),
-			ClientID:     getEnvOrDefault("KEYCLOAK_CLIENT_ID", "gprint"),
-			ClientSecret: os.Getenv("KEYCLOAK_CLIENT_SECRET"),
+			BaseURL:      l.str("KEYCLOAK_URL", "keycloak.base_url", "http://localhost:8180"),
+			Realm:        l.str("KEYCLOAK_REALM", "keycloak.realm", "master"),
+			ClientID:     l.str("KEYCLOAK_CLIENT_ID", "keycloak.client_id", "gprint"),
+			ClientSecret: l.str("KEYCLOAK_CLIENT_SECRET", "keycloak.client_secret", ""),
 		},
 		Print: PrintConfig{
-			OutputPath:  getEnvOrDefault("PRINT_OUTPUT_PATH", "./output"),
-			JobInterval: getDurationOrDefault("PRINT_JOB_INTERVAL", 30*time.Second),
+			OutputPath:      l.str("PRINT_OUTPUT_PATH", "print.output_path", "./output"),
+			JobInterval:     l.duration("PRINT_JOB_INTERVAL", "print.job_interval", 30*time.Second),
+			Workers:         l.int("PRINT_WORKERS", "print.workers", 4),
+			InstanceID:      l.str("PRINT_INSTANCE_ID", "print.instance_id", ""),
+			LeaseTTL:        l.duration("PRINT_LEASE_TTL", "print.lease_ttl", 2*time.Minute),
+			MaxRetries:      l.int("PRINT_MAX_RETRIES", "print.max_retries", 3),
+			RetryBackoff:    l.duration("PRINT_RETRY_BACKOFF", "print.retry_backoff", time.Minute),
+			RetentionDays:   l.int("PRINT_RETENTION_DAYS", "print.retention_days", 0),
+			CleanupInterval: l.duration("PRINT_CLEANUP_INTERVAL", "print.cleanup_interval", time.Hour),
+			WatermarkAdmins: l.list("PRINT_WATERMARK_ADMINS", "print.watermark_admins", nil),
+			VerifyBaseURL:   strings.TrimRight(l.str("PRINT_VERIFY_BASE_URL", "print.verify_base_url", ""), "/"),
+			VerifyQRPayload: l.str("PRINT_VERIFY_QR_PAYLOAD", "print.verify_qr_payload", "{base_url}/api/v1/verify/{hash}?contract={contract_id}"),
+		},
+		Tenant: TenantConfig{
+			DefaultCurrency:       l.str("TENANT_DEFAULT_CURRENCY", "tenant.default_currency", "BRL"),
+			DefaultLocale:         l.str("TENANT_DEFAULT_LOCALE", "tenant.default_locale", "pt-BR"),
+			ContractNumberPattern: l.str("TENANT_CONTRACT_NUMBER_PATTERN", "tenant.contract_number_pattern", "CT-{YYYY}-{SEQ}"),
+		},
+		Features: FeaturesConfig{
+			Enabled: l.list("FEATURES_ENABLED", "features.enabled", []string{"webhooks"}),
+		},
+		Import: ImportConfig{
+			MaxBytes:  l.int("IMPORT_MAX_BYTES", "import.max_bytes", 10<<20), // 10MB default
+			MaxRows:   l.int("IMPORT_MAX_ROWS", "import.max_rows", 10000),
+			BatchSize: l.int("IMPORT_BATCH_SIZE", "import.batch_size", 500),
+		},
+		Storage: StorageConfig{
+			Backend:      l.str("STORAGE_BACKEND", "storage.backend", "local"),
+			DownloadMode: l.str("STORAGE_DOWNLOAD_MODE", "storage.download_mode", "stream"),
+			SignedURLTTL: l.duration("STORAGE_SIGNED_URL_TTL", "storage.signed_url_ttl", 15*time.Minute),
+			S3: S3Config{
+				Endpoint:        l.str("S3_ENDPOINT", "storage.s3.endpoint", ""),
+				Region:          l.str("S3_REGION", "storage.s3.region", "us-east-1"),
+				Bucket:          l.str("S3_BUCKET", "storage.s3.bucket", ""),
+				AccessKeyID:     l.str("S3_ACCESS_KEY_ID", "storage.s3.access_key_id", ""),
+				SecretAccessKey: l.str("S3_SECRET_ACCESS_KEY", "storage.s3.secret_access_key", ""),
+				PathStyle:       l.bool("S3_PATH_STYLE", "storage.s3.path_style", true),
+			},
+		},
+		Webhook: WebhookConfig{
+			MaxAttempts:   l.int("WEBHOOK_MAX_ATTEMPTS", "webhook.max_attempts", 5),
+			Timeout:       l.duration("WEBHOOK_TIMEOUT", "webhook.timeout", 10*time.Second),
+			RetryBackoff:  l.duration("WEBHOOK_RETRY_BACKOFF", "webhook.retry_backoff", time.Minute),
+			RetryInterval: l.duration("WEBHOOK_RETRY_INTERVAL", "webhook.retry_interval", 30*time.Second),
+		},
+		Email: EmailConfig{
+			SMTPHost:           l.str("SMTP_HOST", "email.smtp_host", ""),
+			SMTPPort:           l.int("SMTP_PORT", "email.smtp_port", 587),
+			SMTPUsername:       l.str("SMTP_USERNAME", "email.smtp_username", ""),
+			SMTPPassword:       l.str("SMTP_PASSWORD", "email.smtp_password", ""),
+			From:               l.str("SMTP_FROM", "email.from", ""),
+			TLSMode:            l.str("SMTP_TLS", "email.tls_mode", "starttls"),
+			AttachmentMaxBytes: int64(l.int("EMAIL_ATTACHMENT_MAX_BYTES", "email.attachment_max_bytes", 5<<20)), // 5MB default
+			LinkBaseURL:        l.str("EMAIL_LINK_BASE_URL", "email.link_base_url", ""),
+			MaxAttempts:        l.int("EMAIL_MAX_ATTEMPTS", "email.max_attempts", 3),
+			RetryBackoff:       l.duration("EMAIL_RETRY_BACKOFF", "email.retry_backoff", 30*time.Second),
+		},
+		Attachment: AttachmentConfig{
+			MaxBytes: l.int("ATTACHMENT_MAX_BYTES", "attachment.max_bytes", 25<<20), // 25MB default
+			AllowedTypes: l.list("ATTACHMENT_ALLOWED_TYPES", "attachment.allowed_types",
+				[]string{"application/pdf", "image/png", "image/jpeg", "image/tiff"}),
+			Path: l.str("ATTACHMENT_PATH", "attachment.path", "./attachments"),
+		},
+		Invoice: InvoiceConfig{
+			JobInterval: l.duration("INVOICE_JOB_INTERVAL", "invoice.job_interval", time.Hour),
 		},
-		LogLevel: getEnvOrDefault("LOG_LEVEL", "info"),
+		Cleanup: CleanupConfig{
+			Enabled: l.bool("GENERATION_CLEANUP_ENABLED", "cleanup.enabled", true),
+			At:      l.str("GENERATION_CLEANUP_AT", "cleanup.at", "03:00"),
+			Tenants: l.list("GENERATION_CLEANUP_TENANTS", "cleanup.tenants", nil),
+			LockTTL: l.duration("GENERATION_CLEANUP_LOCK_TTL", "cleanup.lock_ttl", time.Hour),
+		},
+		Metrics: MetricsConfig{
+			Addr:           l.str("METRICS_ADDR", "metrics.addr", ""),
+			Token:          l.str("METRICS_TOKEN", "metrics.token", ""),
+			SampleInterval: l.duration("METRICS_SAMPLE_INTERVAL", "metrics.sample_interval", 15*time.Second),
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:    l.bool("RATE_LIMIT_ENABLED", "rate_limit.enabled", true),
+			RPS:        l.float("RATE_LIMIT_RPS", "rate_limit.rps", 20),
+			Burst:      l.int("RATE_LIMIT_BURST", "rate_limit.burst", 40),
+			AuthRPS:    l.float("RATE_LIMIT_AUTH_RPS", "rate_limit.auth_rps", 0.2),
+			AuthBurst:  l.int("RATE_LIMIT_AUTH_BURST", "rate_limit.auth_burst", 10),
+			SweepRPS:   l.float("RATE_LIMIT_SWEEP_RPS", "rate_limit.sweep_rps", 0.01),
+			SweepBurst: l.int("RATE_LIMIT_SWEEP_BURST", "rate_limit.sweep_burst", 2),
+			TrustProxy: l.bool("RATE_LIMIT_TRUST_PROXY", "rate_limit.trust_proxy", false),
+		},
+		Security: SecurityConfig{
+			ContentSecurityPolicy: l.str("SECURITY_CSP", "security.csp",
+				"default-src 'self'; object-src 'none'; base-uri 'none'; form-action 'self'; frame-ancestors 'none'"),
+			HSTSEnabled: l.bool("SECURITY_HSTS_ENABLED", "security.hsts_enabled", true),
+			HSTS:        l.str("SECURITY_HSTS", "security.hsts", "max-age=31536000; includeSubDomains"),
+		},
+		LogLevel: l.str("LOG_LEVEL", "log_level", "info"),
 	}
-}
-
-// requireEnv returns the value of the environment variable or panics if not set
-func requireEnv(key string) string {
-	val := os.Getenv(key)
-	if val == "" {
-		panic(fmt.Sprintf("required environment variable %s is not set", key))
+	if cfg.JWT.Issuer == "" {
+		cfg.JWT.Issuer = cfg.Keycloak.RealmURL()
 	}
-	return val
-}
+	if cfg.JWT.Audience == "" {
+		cfg.JWT.Audience = cfg.Keycloak.ClientID
+	}
+	if cfg.JWT.JWKSURL == "" {
+		cfg.JWT.JWKSURL = cfg.Keycloak.RealmURL() + "/protocol/openid-connect/certs"
+	}
+	cfg.Sources = l.sources
 
-func getEnvOrDefault(key, defaultVal string) string {
-	if val := os.Getenv(key); val != "" {
-		return val
+	if err := l.err(); err != nil {
+		return nil, err
 	}
-	return defaultVal
+	return cfg, nil
 }
 
-func getIntOrDefault(key string, defaultVal int) int {
-	if val := os.Getenv(key); val != "" {
-		if i, err := strconv.Atoi(val); err == nil {
-			return i
-		}
-	}
-	return defaultVal
+// Changes lists the settings that differ between c and other as field paths
+// such as "Print.JobInterval". Sources is not compared.
+func (c *Config) Changes(other *Config) []string {
+	var changes []string
+	diffFields(reflect.ValueOf(*c), reflect.ValueOf(*other), "", &changes)
+	return changes
 }
 
-func getDurationOrDefault(key string, defaultVal time.Duration) time.Duration {
-	if val := os.Getenv(key); val != "" {
-		if d, err := time.ParseDuration(val); err == nil {
-			return d
+func diffFields(a, b reflect.Value, prefix string, changes *[]string) {
+	t := a.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Name == "Sources" {
+			continue
+		}
+		name := prefix + field.Name
+		if field.Type.Kind() == reflect.Struct {
+			diffFields(a.Field(i), b.Field(i), name+".", changes)
+			continue
+		}
+		if !reflect.DeepEqual(a.Field(i).Interface(), b.Field(i).Interface()) {
+			*changes = append(*changes, name)
 		}
 	}
-	return defaultVal
 }
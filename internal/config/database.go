@@ -3,6 +3,7 @@ package config
 import (
 	"database/sql"
 	"fmt"
+	"log/slog"
 	"strings"
 	"time"
 
@@ -11,13 +12,23 @@ import (
 
 // OracleConfig holds Oracle database configuration
 type OracleConfig struct {
-	Host         string
-	Port         string
-	Service      string
-	User         string
-	Password     string
-	MaxOpenConns int
-	MaxIdleConns int
+	Host            string
+	Port            string
+	Service         string
+	User            string
+	Password        string
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	// ConnectAttempts is how many times the initial ping is tried before giving up
+	ConnectAttempts int
+	// ConnectBackoff is the delay after the first failed ping, doubling on each retry
+	ConnectBackoff time.Duration
+	// QueryTimeout bounds every repository statement; zero disables it
+	QueryTimeout time.Duration
+	// SlowQueryThreshold is the duration above which statements are logged as slow
+	SlowQueryThreshold time.Duration
 	// Wallet configuration for Oracle Cloud (ADB)
 	WalletPath string
 	TNSAlias   string
@@ -51,8 +62,13 @@ func (c OracleConfig) DSN() string {
 		user, password, host, port, service)
 }
 
-// NewOracleDB creates a new Oracle database connection pool
-func NewOracleDB(cfg OracleConfig) (*sql.DB, error) {
+// maxConnectBackoff caps the delay between initial connection attempts
+const maxConnectBackoff = time.Minute
+
+// NewOracleDB creates a new Oracle database connection pool. The initial ping
+// is retried with exponential backoff so the service can start while the
+// database is briefly unavailable, e.g. during maintenance.
+func NewOracleDB(cfg OracleConfig, logger *slog.Logger) (*sql.DB, error) {
 	db, err := sql.Open("godror", cfg.DSN())
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
@@ -60,12 +76,29 @@ func NewOracleDB(cfg OracleConfig) (*sql.DB, error) {
 
 	db.SetMaxOpenConns(cfg.MaxOpenConns)
 	db.SetMaxIdleConns(cfg.MaxIdleConns)
-	db.SetConnMaxLifetime(5 * time.Minute)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
 
-	if err := db.Ping(); err != nil {
-		db.Close()
-		return nil, fmt.Errorf("failed to ping database: %w", err)
+	attempts := max(cfg.ConnectAttempts, 1)
+	backoff := cfg.ConnectBackoff
+	for attempt := 1; ; attempt++ {
+		err = db.Ping()
+		if err == nil {
+			return db, nil
+		}
+		if attempt >= attempts {
+			break
+		}
+		logger.Warn("database not reachable, retrying",
+			"attempt", attempt,
+			"max_attempts", attempts,
+			"retry_in", backoff,
+			"error", err,
+		)
+		time.Sleep(backoff)
+		backoff = min(backoff*2, maxConnectBackoff)
 	}
 
-	return db, nil
+	db.Close()
+	return nil, fmt.Errorf("failed to ping database after %d attempts: %w", attempts, err)
 }
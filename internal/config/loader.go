@@ -0,0 +1,174 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ConfigFileEnv names the environment variable holding the optional config file path
+const ConfigFileEnv = "GPRINT_CONFIG"
+
+// Setting sources recorded in Config.Sources
+const (
+	SourceEnv     = "env"
+	SourceFile    = "file"
+	SourceDefault = "default"
+)
+
+// loader resolves each setting from the environment, then the config file,
+// then the default, remembering where every value came from. Invalid
+// environment values fall back to the default as they always have; invalid
+// file values are errors, since the file is easy to fix and easy to typo.
+type loader struct {
+	file     map[string]string
+	used     map[string]bool
+	sources  map[string]string
+	problems []error
+}
+
+func newLoader(path string) (*loader, error) {
+	l := &loader{
+		used:    make(map[string]bool),
+		sources: make(map[string]string),
+	}
+	if path == "" {
+		return l, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	if l.file, err = parseYAML(data); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return l, nil
+}
+
+// lookup returns the raw value for a setting and records its source
+func (l *loader) lookup(envKey, fileKey string) (string, string) {
+	l.used[fileKey] = true
+	if val := os.Getenv(envKey); val != "" {
+		l.sources[fileKey] = SourceEnv
+		return val, SourceEnv
+	}
+	if val, ok := l.file[fileKey]; ok && val != "" {
+		l.sources[fileKey] = SourceFile
+		return val, SourceFile
+	}
+	l.sources[fileKey] = SourceDefault
+	return "", SourceDefault
+}
+
+// invalid records a bad file value; bad environment values keep the default
+func (l *loader) invalid(fileKey, source, val string, err error) {
+	if source == SourceFile {
+		l.problems = append(l.problems, fmt.Errorf("%s: invalid value %q: %w", fileKey, val, err))
+		return
+	}
+	l.sources[fileKey] = SourceDefault
+}
+
+func (l *loader) str(envKey, fileKey, defaultVal string) string {
+	if val, source := l.lookup(envKey, fileKey); source != SourceDefault {
+		return val
+	}
+	return defaultVal
+}
+
+// required returns a setting that has no default, recording an error when it is missing
+func (l *loader) required(envKey, fileKey string) string {
+	val, source := l.lookup(envKey, fileKey)
+	if source == SourceDefault {
+		l.problems = append(l.problems, fmt.Errorf("%s (or %s in the config file) is required", envKey, fileKey))
+	}
+	return val
+}
+
+func (l *loader) int(envKey, fileKey string, defaultVal int) int {
+	val, source := l.lookup(envKey, fileKey)
+	if source == SourceDefault {
+		return defaultVal
+	}
+	i, err := strconv.Atoi(val)
+	if err != nil {
+		l.invalid(fileKey, source, val, err)
+		return defaultVal
+	}
+	return i
+}
+
+func (l *loader) float(envKey, fileKey string, defaultVal float64) float64 {
+	val, source := l.lookup(envKey, fileKey)
+	if source == SourceDefault {
+		return defaultVal
+	}
+	f, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		l.invalid(fileKey, source, val, err)
+		return defaultVal
+	}
+	return f
+}
+
+func (l *loader) bool(envKey, fileKey string, defaultVal bool) bool {
+	val, source := l.lookup(envKey, fileKey)
+	if source == SourceDefault {
+		return defaultVal
+	}
+	b, err := strconv.ParseBool(val)
+	if err != nil {
+		l.invalid(fileKey, source, val, err)
+		return defaultVal
+	}
+	return b
+}
+
+func (l *loader) duration(envKey, fileKey string, defaultVal time.Duration) time.Duration {
+	val, source := l.lookup(envKey, fileKey)
+	if source == SourceDefault {
+		return defaultVal
+	}
+	d, err := time.ParseDuration(val)
+	if err != nil {
+		l.invalid(fileKey, source, val, err)
+		return defaultVal
+	}
+	return d
+}
+
+// list parses a comma-separated list (or a YAML sequence), dropping empty entries
+func (l *loader) list(envKey, fileKey string, defaultVal []string) []string {
+	val, source := l.lookup(envKey, fileKey)
+	if source == SourceDefault {
+		return defaultVal
+	}
+	var list []string
+	for _, item := range strings.Split(val, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// err reports unknown config file keys, invalid file values and missing
+// required settings together
+func (l *loader) err() error {
+	var unknown []string
+	for key := range l.file {
+		if !l.used[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	problems := l.problems
+	for _, key := range unknown {
+		problems = append(problems, fmt.Errorf("unknown config file key %q", key))
+	}
+	return errors.Join(problems...)
+}
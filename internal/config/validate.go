@@ -0,0 +1,310 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zlovtnik/gprint/internal/models"
+)
+
+// Validation thresholds
+const (
+	minJWTSecretLength = 32
+	minJobInterval     = 5 * time.Second // Shorter print polling intervals hammer the database
+)
+
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// Validate checks the configuration and reports every problem at once.
+// Problems that would break the service are joined into err; suspicious but
+// workable values are returned as warnings.
+func (c *Config) Validate() (warnings []string, err error) {
+	var problems []error
+	fail := func(format string, args ...any) {
+		problems = append(problems, fmt.Errorf(format, args...))
+	}
+	warn := func(format string, args ...any) {
+		warnings = append(warnings, fmt.Sprintf(format, args...))
+	}
+
+	// Server
+	if port, err := strconv.Atoi(c.Server.Port); err != nil || port < 1 || port > 65535 {
+		fail("SERVER_PORT must be a number between 1 and 65535, got %q", c.Server.Port)
+	}
+	requirePositive(fail, "SERVER_READ_TIMEOUT", c.Server.ReadTimeout)
+	requirePositive(fail, "SERVER_WRITE_TIMEOUT", c.Server.WriteTimeout)
+	requirePositive(fail, "SERVER_IDLE_TIMEOUT", c.Server.IdleTimeout)
+	requirePositive(fail, "SERVER_SHUTDOWN_TIMEOUT", c.Server.ShutdownTimeout)
+	if c.Server.ShutdownDrainDelay < 0 {
+		fail("SERVER_SHUTDOWN_DRAIN_DELAY must not be negative, got %s", c.Server.ShutdownDrainDelay)
+	}
+	if c.Server.MaxHeaderBytes <= 0 {
+		fail("SERVER_MAX_HEADER_BYTES must be positive, got %d", c.Server.MaxHeaderBytes)
+	}
+	if c.Server.MaxBodyBytes <= 0 || c.Server.MaxUploadBytes <= 0 {
+		fail("SERVER_MAX_BODY_BYTES and SERVER_MAX_UPLOAD_BYTES must be positive")
+	}
+	if c.Server.GzipMinBytes < 0 {
+		fail("SERVER_GZIP_MIN_BYTES must not be negative, got %d", c.Server.GzipMinBytes)
+	}
+	if (c.Server.TLSCertFile == "") != (c.Server.TLSKeyFile == "") {
+		fail("SERVER_TLS_CERT_FILE and SERVER_TLS_KEY_FILE must be set together")
+	} else if c.Server.TLSEnabled() {
+		if _, err := os.Stat(c.Server.TLSCertFile); err != nil {
+			fail("SERVER_TLS_CERT_FILE is not readable: %v", err)
+		}
+		if _, err := os.Stat(c.Server.TLSKeyFile); err != nil {
+			fail("SERVER_TLS_KEY_FILE is not readable: %v", err)
+		}
+	}
+	if c.Server.TLSReloadInterval < 0 {
+		fail("SERVER_TLS_RELOAD_INTERVAL must not be negative, got %s", c.Server.TLSReloadInterval)
+	}
+
+	// Database
+	if c.Database.User == "" {
+		fail("ORACLE_USER is required")
+	}
+	if c.Database.WalletPath == "" && c.Database.TNSAlias == "" {
+		if port, err := strconv.Atoi(c.Database.Port); err != nil || port < 1 || port > 65535 {
+			fail("ORACLE_PORT must be a number between 1 and 65535, got %q", c.Database.Port)
+		}
+	} else if c.Database.WalletPath == "" || c.Database.TNSAlias == "" {
+		fail("ORACLE_WALLET_PATH and ORACLE_TNS_ALIAS must be set together")
+	}
+	if c.Database.MaxOpenConns < 0 || c.Database.MaxIdleConns < 0 {
+		fail("ORACLE_MAX_OPEN_CONNS and ORACLE_MAX_IDLE_CONNS must not be negative")
+	} else if c.Database.MaxOpenConns > 0 && c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		warn("ORACLE_MAX_IDLE_CONNS (%d) exceeds ORACLE_MAX_OPEN_CONNS (%d) and will be capped",
+			c.Database.MaxIdleConns, c.Database.MaxOpenConns)
+	}
+	if c.Database.ConnectAttempts < 1 {
+		fail("ORACLE_CONNECT_ATTEMPTS must be at least 1, got %d", c.Database.ConnectAttempts)
+	}
+	requirePositive(fail, "ORACLE_CONNECT_BACKOFF", c.Database.ConnectBackoff)
+	if c.Database.QueryTimeout < 0 || c.Database.SlowQueryThreshold < 0 {
+		fail("ORACLE_QUERY_TIMEOUT and ORACLE_SLOW_QUERY_THRESHOLD must not be negative")
+	} else if c.Database.QueryTimeout > 0 && c.Database.SlowQueryThreshold >= c.Database.QueryTimeout {
+		warn("ORACLE_SLOW_QUERY_THRESHOLD (%s) is not below ORACLE_QUERY_TIMEOUT (%s); slow queries will time out before being logged",
+			c.Database.SlowQueryThreshold, c.Database.QueryTimeout)
+	}
+
+	// Authentication
+	if c.JWT.Secret != "" {
+		if len(c.JWT.Secret) < minJWTSecretLength {
+			fail("JWT_SECRET must be at least %d characters, got %d", minJWTSecretLength, len(c.JWT.Secret))
+		}
+		warn("JWT_SECRET is set, so HS256 tokens are accepted alongside Keycloak's RS256 tokens; unset it once legacy clients are gone")
+	}
+	requireHTTPURL(fail, "JWT_JWKS_URL", c.JWT.JWKSURL)
+	requirePositive(fail, "JWT_JWKS_CACHE_TTL", c.JWT.JWKSCacheTTL)
+	requirePositive(fail, "JWT_JWKS_MIN_REFRESH", c.JWT.JWKSMinRefresh)
+	requirePositive(fail, "JWT_EXPIRATION", c.JWT.Expiration)
+	if c.JWT.ClockSkew < 0 {
+		fail("JWT_CLOCK_SKEW must not be negative, got %s", c.JWT.ClockSkew)
+	} else if c.JWT.ClockSkew > 5*time.Minute {
+		warn("JWT_CLOCK_SKEW of %s accepts tokens long after they expire", c.JWT.ClockSkew)
+	}
+	if !c.Auth.EnforceRoles {
+		warn("AUTH_ENFORCE_ROLES is false; every authenticated user can change data and read reports")
+	}
+	if c.Auth.LoginMaxFailures < 0 {
+		fail("AUTH_LOGIN_MAX_FAILURES must not be negative, got %d", c.Auth.LoginMaxFailures)
+	} else if c.Auth.LoginMaxFailures == 0 {
+		warn("AUTH_LOGIN_MAX_FAILURES is 0; failed logins are never locked out")
+	} else {
+		requirePositive(fail, "AUTH_LOGIN_FAILURE_WINDOW", c.Auth.LoginFailureWindow)
+		requirePositive(fail, "AUTH_LOGIN_LOCKOUT", c.Auth.LoginLockout)
+	}
+	requireHTTPURL(fail, "KEYCLOAK_URL", c.Keycloak.BaseURL)
+	if c.Keycloak.Realm == "" {
+		fail("KEYCLOAK_REALM is required")
+	}
+	if c.Keycloak.ClientID == "" {
+		fail("KEYCLOAK_CLIENT_ID is required")
+	}
+
+	// Print
+	requirePositive(fail, "PRINT_JOB_INTERVAL", c.Print.JobInterval)
+	if c.Print.JobInterval > 0 && c.Print.JobInterval < minJobInterval {
+		warn("PRINT_JOB_INTERVAL of %s polls the print queue very often; %s or more is recommended",
+			c.Print.JobInterval, minJobInterval)
+	}
+	if c.Print.Workers < 1 {
+		fail("PRINT_WORKERS must be at least 1, got %d", c.Print.Workers)
+	}
+	requirePositive(fail, "PRINT_LEASE_TTL", c.Print.LeaseTTL)
+	requirePositive(fail, "PRINT_RETRY_BACKOFF", c.Print.RetryBackoff)
+	if c.Print.MaxRetries < 0 {
+		fail("PRINT_MAX_RETRIES must not be negative, got %d", c.Print.MaxRetries)
+	}
+	if c.Print.RetentionDays < 0 {
+		fail("PRINT_RETENTION_DAYS must not be negative, got %d", c.Print.RetentionDays)
+	}
+	requirePositive(fail, "PRINT_CLEANUP_INTERVAL", c.Print.CleanupInterval)
+	if c.Print.VerifyBaseURL != "" {
+		requireHTTPURL(fail, "PRINT_VERIFY_BASE_URL", c.Print.VerifyBaseURL)
+	}
+
+	// Tenant defaults
+	if !currencyCode.MatchString(c.Tenant.DefaultCurrency) {
+		fail("TENANT_DEFAULT_CURRENCY must be a three-letter ISO 4217 code, got %q", c.Tenant.DefaultCurrency)
+	}
+	if c.Tenant.DefaultLocale == "" {
+		fail("TENANT_DEFAULT_LOCALE is required")
+	}
+	if strings.Count(c.Tenant.ContractNumberPattern, "{SEQ}") != 1 {
+		fail("TENANT_CONTRACT_NUMBER_PATTERN must contain {SEQ} exactly once, got %q", c.Tenant.ContractNumberPattern)
+	}
+
+	for _, name := range c.Features.Enabled {
+		if !models.IsFeature(name) {
+			fail("FEATURES_ENABLED names unknown feature %q; known features are %s", name, strings.Join(models.Features, ", "))
+		}
+	}
+
+	// Imports
+	if c.Import.MaxBytes <= 0 || c.Import.MaxRows <= 0 {
+		fail("IMPORT_MAX_BYTES and IMPORT_MAX_ROWS must be positive")
+	} else if c.Import.MaxBytes > c.Server.MaxUploadBytes {
+		warn("IMPORT_MAX_BYTES (%d) exceeds SERVER_MAX_UPLOAD_BYTES (%d), which caps uploads first",
+			c.Import.MaxBytes, c.Server.MaxUploadBytes)
+	}
+	if c.Import.BatchSize <= 0 || c.Import.BatchSize > 1000 {
+		fail("IMPORT_BATCH_SIZE must be between 1 and 1000, got %d", c.Import.BatchSize)
+	}
+
+	// Storage
+	switch c.Storage.Backend {
+	case "", "local":
+		if err := checkWritableDir(c.Print.OutputPath); err != nil {
+			fail("PRINT_OUTPUT_PATH %q is not writable: %v", c.Print.OutputPath, err)
+		}
+	case "s3":
+		if c.Storage.S3.Bucket == "" || c.Storage.S3.AccessKeyID == "" || c.Storage.S3.SecretAccessKey == "" {
+			fail("S3_BUCKET, S3_ACCESS_KEY_ID and S3_SECRET_ACCESS_KEY are required for the s3 storage backend")
+		}
+		if c.Storage.S3.Endpoint != "" {
+			requireHTTPURL(fail, "S3_ENDPOINT", c.Storage.S3.Endpoint)
+		}
+	default:
+		fail("STORAGE_BACKEND must be local or s3, got %q", c.Storage.Backend)
+	}
+	if c.Storage.DownloadMode != "stream" && c.Storage.DownloadMode != "redirect" {
+		fail("STORAGE_DOWNLOAD_MODE must be stream or redirect, got %q", c.Storage.DownloadMode)
+	}
+	requirePositive(fail, "STORAGE_SIGNED_URL_TTL", c.Storage.SignedURLTTL)
+
+	// Webhooks
+	if c.Webhook.MaxAttempts < 1 {
+		fail("WEBHOOK_MAX_ATTEMPTS must be at least 1, got %d", c.Webhook.MaxAttempts)
+	}
+	requirePositive(fail, "WEBHOOK_TIMEOUT", c.Webhook.Timeout)
+	requirePositive(fail, "WEBHOOK_RETRY_BACKOFF", c.Webhook.RetryBackoff)
+	requirePositive(fail, "WEBHOOK_RETRY_INTERVAL", c.Webhook.RetryInterval)
+
+	// Email (only checked when enabled)
+	if c.Email.SMTPHost != "" {
+		if c.Email.From == "" {
+			fail("SMTP_FROM is required when SMTP_HOST is set")
+		}
+		if c.Email.SMTPPort < 1 || c.Email.SMTPPort > 65535 {
+			fail("SMTP_PORT must be between 1 and 65535, got %d", c.Email.SMTPPort)
+		}
+		switch c.Email.TLSMode {
+		case "none":
+			warn("SMTP_TLS=none sends notification emails and SMTP credentials unencrypted")
+		case "starttls", "tls":
+		default:
+			fail("SMTP_TLS must be none, starttls or tls, got %q", c.Email.TLSMode)
+		}
+		if c.Email.MaxAttempts < 1 {
+			fail("EMAIL_MAX_ATTEMPTS must be at least 1, got %d", c.Email.MaxAttempts)
+		}
+		requirePositive(fail, "EMAIL_RETRY_BACKOFF", c.Email.RetryBackoff)
+		if c.Email.LinkBaseURL != "" {
+			requireHTTPURL(fail, "EMAIL_LINK_BASE_URL", c.Email.LinkBaseURL)
+		}
+	}
+
+	// Attachments
+	if c.Attachment.MaxBytes <= 0 {
+		fail("ATTACHMENT_MAX_BYTES must be positive")
+	} else if c.Attachment.MaxBytes > c.Server.MaxUploadBytes {
+		warn("ATTACHMENT_MAX_BYTES (%d) exceeds SERVER_MAX_UPLOAD_BYTES (%d), which caps uploads first",
+			c.Attachment.MaxBytes, c.Server.MaxUploadBytes)
+	}
+	if len(c.Attachment.AllowedTypes) == 0 {
+		fail("ATTACHMENT_ALLOWED_TYPES must list at least one media type")
+	}
+
+	// Invoices
+	requirePositive(fail, "INVOICE_JOB_INTERVAL", c.Invoice.JobInterval)
+
+	// Generation cleanup
+	if c.Cleanup.Enabled {
+		if _, err := time.Parse("15:04", c.Cleanup.At); err != nil {
+			fail("GENERATION_CLEANUP_AT must be a time of day as HH:MM, got %q", c.Cleanup.At)
+		}
+		requirePositive(fail, "GENERATION_CLEANUP_LOCK_TTL", c.Cleanup.LockTTL)
+	}
+
+	// Metrics
+	requirePositive(fail, "METRICS_SAMPLE_INTERVAL", c.Metrics.SampleInterval)
+	if c.Metrics.Addr != "" && c.Metrics.Token == "" {
+		warn("METRICS_ADDR serves /metrics without a token; make sure the address is not publicly reachable")
+	}
+
+	// Security headers
+	if c.Security.HSTSEnabled && c.Security.HSTS == "" {
+		fail("SECURITY_HSTS must be set when SECURITY_HSTS_ENABLED is true")
+	}
+
+	// Rate limiting
+	if c.RateLimit.Enabled {
+		if c.RateLimit.RPS <= 0 || c.RateLimit.AuthRPS <= 0 || c.RateLimit.SweepRPS <= 0 {
+			fail("RATE_LIMIT_RPS, RATE_LIMIT_AUTH_RPS and RATE_LIMIT_SWEEP_RPS must be positive")
+		}
+		if c.RateLimit.Burst < 1 || c.RateLimit.AuthBurst < 1 || c.RateLimit.SweepBurst < 1 {
+			fail("RATE_LIMIT_BURST, RATE_LIMIT_AUTH_BURST and RATE_LIMIT_SWEEP_BURST must be at least 1")
+		}
+	}
+
+	return warnings, errors.Join(problems...)
+}
+
+// requirePositive reports a duration that is zero or negative
+func requirePositive(fail func(string, ...any), name string, d time.Duration) {
+	if d <= 0 {
+		fail("%s must be a positive duration, got %s", name, d)
+	}
+}
+
+// requireHTTPURL reports a value that is not an absolute http(s) URL
+func requireHTTPURL(fail func(string, ...any), name, value string) {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		fail("%s must be an absolute http or https URL, got %q", name, value)
+	}
+}
+
+// checkWritableDir creates dir if needed and verifies a file can be written to it
+func checkWritableDir(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, ".gprint-write-check-*")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+	f.Close()
+	return os.Remove(filepath.Clean(name))
+}
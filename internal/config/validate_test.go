@@ -0,0 +1,244 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// validConfig loads the defaults with the one required setting filled in
+// and local output in a temporary directory
+func validConfig(t *testing.T) *Config {
+	t.Helper()
+	t.Setenv(ConfigFileEnv, "")
+	t.Setenv("ORACLE_USER", "gprint")
+	t.Setenv("PRINT_OUTPUT_PATH", t.TempDir())
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	return cfg
+}
+
+func TestValidateDefaults(t *testing.T) {
+	cfg := validConfig(t)
+	if _, err := cfg.Validate(); err != nil {
+		t.Fatalf("defaults should validate, got: %v", err)
+	}
+}
+
+func TestValidateRules(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		want   string // substring of the reported problem
+	}{
+		{"server port not a number", func(c *Config) { c.Server.Port = "http" }, "SERVER_PORT"},
+		{"server port out of range", func(c *Config) { c.Server.Port = "70000" }, "SERVER_PORT"},
+		{"read timeout", func(c *Config) { c.Server.ReadTimeout = 0 }, "SERVER_READ_TIMEOUT"},
+		{"write timeout", func(c *Config) { c.Server.WriteTimeout = -time.Second }, "SERVER_WRITE_TIMEOUT"},
+		{"idle timeout", func(c *Config) { c.Server.IdleTimeout = 0 }, "SERVER_IDLE_TIMEOUT"},
+		{"shutdown timeout", func(c *Config) { c.Server.ShutdownTimeout = 0 }, "SERVER_SHUTDOWN_TIMEOUT"},
+		{"drain delay", func(c *Config) { c.Server.ShutdownDrainDelay = -time.Second }, "SERVER_SHUTDOWN_DRAIN_DELAY"},
+		{"max header bytes", func(c *Config) { c.Server.MaxHeaderBytes = 0 }, "SERVER_MAX_HEADER_BYTES"},
+		{"max body bytes", func(c *Config) { c.Server.MaxBodyBytes = 0 }, "SERVER_MAX_BODY_BYTES"},
+		{"gzip min bytes", func(c *Config) { c.Server.GzipMinBytes = -1 }, "SERVER_GZIP_MIN_BYTES"},
+		{"tls cert without key", func(c *Config) { c.Server.TLSCertFile = "cert.pem" }, "must be set together"},
+		{"tls cert missing", func(c *Config) {
+			c.Server.TLSCertFile = filepath.Join(t.TempDir(), "cert.pem")
+			c.Server.TLSKeyFile = filepath.Join(t.TempDir(), "key.pem")
+			writeFile(t, c.Server.TLSKeyFile)
+		}, "SERVER_TLS_CERT_FILE is not readable"},
+		{"tls reload interval", func(c *Config) { c.Server.TLSReloadInterval = -time.Second }, "SERVER_TLS_RELOAD_INTERVAL"},
+
+		{"oracle user", func(c *Config) { c.Database.User = "" }, "ORACLE_USER"},
+		{"oracle port", func(c *Config) { c.Database.Port = "0" }, "ORACLE_PORT"},
+		{"wallet without alias", func(c *Config) { c.Database.WalletPath = "/wallet" }, "ORACLE_WALLET_PATH"},
+		{"negative pool size", func(c *Config) { c.Database.MaxOpenConns = -1 }, "ORACLE_MAX_OPEN_CONNS"},
+		{"connect attempts", func(c *Config) { c.Database.ConnectAttempts = 0 }, "ORACLE_CONNECT_ATTEMPTS"},
+		{"connect backoff", func(c *Config) { c.Database.ConnectBackoff = 0 }, "ORACLE_CONNECT_BACKOFF"},
+		{"negative query timeout", func(c *Config) { c.Database.QueryTimeout = -time.Second }, "ORACLE_QUERY_TIMEOUT"},
+
+		{"short jwt secret", func(c *Config) { c.JWT.Secret = "short" }, "JWT_SECRET must be at least"},
+		{"jwks url", func(c *Config) { c.JWT.JWKSURL = "ftp://keys" }, "JWT_JWKS_URL"},
+		{"jwks cache ttl", func(c *Config) { c.JWT.JWKSCacheTTL = 0 }, "JWT_JWKS_CACHE_TTL"},
+		{"jwks min refresh", func(c *Config) { c.JWT.JWKSMinRefresh = 0 }, "JWT_JWKS_MIN_REFRESH"},
+		{"jwt expiration", func(c *Config) { c.JWT.Expiration = 0 }, "JWT_EXPIRATION"},
+		{"clock skew", func(c *Config) { c.JWT.ClockSkew = -time.Second }, "JWT_CLOCK_SKEW"},
+		{"login max failures", func(c *Config) { c.Auth.LoginMaxFailures = -1 }, "AUTH_LOGIN_MAX_FAILURES"},
+		{"login failure window", func(c *Config) { c.Auth.LoginFailureWindow = 0 }, "AUTH_LOGIN_FAILURE_WINDOW"},
+		{"login lockout", func(c *Config) { c.Auth.LoginLockout = 0 }, "AUTH_LOGIN_LOCKOUT"},
+		{"keycloak url", func(c *Config) { c.Keycloak.BaseURL = "keycloak:8180" }, "KEYCLOAK_URL"},
+		{"keycloak realm", func(c *Config) { c.Keycloak.Realm = "" }, "KEYCLOAK_REALM"},
+		{"keycloak client", func(c *Config) { c.Keycloak.ClientID = "" }, "KEYCLOAK_CLIENT_ID"},
+
+		{"print job interval", func(c *Config) { c.Print.JobInterval = 0 }, "PRINT_JOB_INTERVAL"},
+		{"print workers", func(c *Config) { c.Print.Workers = 0 }, "PRINT_WORKERS"},
+		{"print lease ttl", func(c *Config) { c.Print.LeaseTTL = 0 }, "PRINT_LEASE_TTL"},
+		{"print retry backoff", func(c *Config) { c.Print.RetryBackoff = 0 }, "PRINT_RETRY_BACKOFF"},
+		{"print max retries", func(c *Config) { c.Print.MaxRetries = -1 }, "PRINT_MAX_RETRIES"},
+		{"print retention", func(c *Config) { c.Print.RetentionDays = -1 }, "PRINT_RETENTION_DAYS"},
+		{"print cleanup interval", func(c *Config) { c.Print.CleanupInterval = 0 }, "PRINT_CLEANUP_INTERVAL"},
+		{"verify base url", func(c *Config) { c.Print.VerifyBaseURL = "/verify" }, "PRINT_VERIFY_BASE_URL"},
+
+		{"currency", func(c *Config) { c.Tenant.DefaultCurrency = "real" }, "TENANT_DEFAULT_CURRENCY"},
+		{"locale", func(c *Config) { c.Tenant.DefaultLocale = "" }, "TENANT_DEFAULT_LOCALE"},
+		{"number pattern without seq", func(c *Config) { c.Tenant.ContractNumberPattern = "CT-{YYYY}" }, "TENANT_CONTRACT_NUMBER_PATTERN"},
+		{"number pattern with two seqs", func(c *Config) { c.Tenant.ContractNumberPattern = "{SEQ}-{SEQ}" }, "TENANT_CONTRACT_NUMBER_PATTERN"},
+		{"unknown feature", func(c *Config) { c.Features.Enabled = []string{"teleport"} }, `unknown feature "teleport"`},
+
+		{"import limits", func(c *Config) { c.Import.MaxRows = 0 }, "IMPORT_MAX_BYTES and IMPORT_MAX_ROWS"},
+		{"import batch size", func(c *Config) { c.Import.BatchSize = 1001 }, "IMPORT_BATCH_SIZE"},
+
+		{"output path not writable", func(c *Config) {
+			file := filepath.Join(t.TempDir(), "file")
+			writeFile(t, file)
+			c.Print.OutputPath = file
+		}, "PRINT_OUTPUT_PATH"},
+		{"s3 without credentials", func(c *Config) { c.Storage.Backend = "s3" }, "S3_BUCKET"},
+		{"s3 endpoint", func(c *Config) {
+			c.Storage.Backend = "s3"
+			c.Storage.S3 = S3Config{Endpoint: "minio:9000", Bucket: "b", AccessKeyID: "a", SecretAccessKey: "s"}
+		}, "S3_ENDPOINT"},
+		{"storage backend", func(c *Config) { c.Storage.Backend = "ftp" }, "STORAGE_BACKEND"},
+		{"download mode", func(c *Config) { c.Storage.DownloadMode = "inline" }, "STORAGE_DOWNLOAD_MODE"},
+		{"signed url ttl", func(c *Config) { c.Storage.SignedURLTTL = 0 }, "STORAGE_SIGNED_URL_TTL"},
+
+		{"webhook attempts", func(c *Config) { c.Webhook.MaxAttempts = 0 }, "WEBHOOK_MAX_ATTEMPTS"},
+		{"webhook timeout", func(c *Config) { c.Webhook.Timeout = 0 }, "WEBHOOK_TIMEOUT"},
+		{"webhook retry backoff", func(c *Config) { c.Webhook.RetryBackoff = 0 }, "WEBHOOK_RETRY_BACKOFF"},
+		{"webhook retry interval", func(c *Config) { c.Webhook.RetryInterval = 0 }, "WEBHOOK_RETRY_INTERVAL"},
+
+		{"smtp from", withSMTP(func(c *Config) { c.Email.From = "" }), "SMTP_FROM"},
+		{"smtp port", withSMTP(func(c *Config) { c.Email.SMTPPort = 0 }), "SMTP_PORT"},
+		{"smtp tls mode", withSMTP(func(c *Config) { c.Email.TLSMode = "ssl" }), "SMTP_TLS"},
+		{"email attempts", withSMTP(func(c *Config) { c.Email.MaxAttempts = 0 }), "EMAIL_MAX_ATTEMPTS"},
+		{"email retry backoff", withSMTP(func(c *Config) { c.Email.RetryBackoff = 0 }), "EMAIL_RETRY_BACKOFF"},
+		{"email link base url", withSMTP(func(c *Config) { c.Email.LinkBaseURL = "example.com" }), "EMAIL_LINK_BASE_URL"},
+
+		{"attachment max bytes", func(c *Config) { c.Attachment.MaxBytes = 0 }, "ATTACHMENT_MAX_BYTES"},
+		{"attachment types", func(c *Config) { c.Attachment.AllowedTypes = nil }, "ATTACHMENT_ALLOWED_TYPES"},
+		{"invoice job interval", func(c *Config) { c.Invoice.JobInterval = 0 }, "INVOICE_JOB_INTERVAL"},
+		{"cleanup time", func(c *Config) { c.Cleanup.At = "3am" }, "GENERATION_CLEANUP_AT"},
+		{"cleanup lock ttl", func(c *Config) { c.Cleanup.LockTTL = 0 }, "GENERATION_CLEANUP_LOCK_TTL"},
+		{"metrics sample interval", func(c *Config) { c.Metrics.SampleInterval = 0 }, "METRICS_SAMPLE_INTERVAL"},
+		{"hsts value", func(c *Config) { c.Security.HSTS = "" }, "SECURITY_HSTS"},
+		{"rate limit rps", func(c *Config) { c.RateLimit.AuthRPS = 0 }, "RATE_LIMIT_RPS"},
+		{"rate limit burst", func(c *Config) { c.RateLimit.SweepBurst = 0 }, "RATE_LIMIT_BURST"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig(t)
+			tt.modify(cfg)
+			_, err := cfg.Validate()
+			if err == nil {
+				t.Fatalf("expected a problem mentioning %q", tt.want)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error %q does not mention %q", err, tt.want)
+			}
+			if n := len(problems(err)); n != 1 {
+				t.Errorf("got %d problems, want 1: %v", n, err)
+			}
+		})
+	}
+}
+
+func TestValidateSkipsDisabledSections(t *testing.T) {
+	cfg := validConfig(t)
+	cfg.Email.SMTPHost = ""
+	cfg.Email.TLSMode = "bogus"
+	cfg.Cleanup.Enabled = false
+	cfg.Cleanup.At = "bogus"
+	cfg.RateLimit.Enabled = false
+	cfg.RateLimit.RPS = 0
+	cfg.Auth.LoginMaxFailures = 0
+	cfg.Auth.LoginLockout = 0
+	if _, err := cfg.Validate(); err != nil {
+		t.Fatalf("disabled sections should not be checked, got: %v", err)
+	}
+}
+
+func TestValidateWarnings(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		want   string
+	}{
+		{"idle above open conns", func(c *Config) { c.Database.MaxIdleConns = 50 }, "ORACLE_MAX_IDLE_CONNS"},
+		{"slow threshold above timeout", func(c *Config) { c.Database.SlowQueryThreshold = time.Minute }, "ORACLE_SLOW_QUERY_THRESHOLD"},
+		{"jwt secret set", func(c *Config) { c.JWT.Secret = strings.Repeat("s", minJWTSecretLength) }, "HS256"},
+		{"large clock skew", func(c *Config) { c.JWT.ClockSkew = time.Hour }, "JWT_CLOCK_SKEW"},
+		{"roles not enforced", func(c *Config) { c.Auth.EnforceRoles = false }, "AUTH_ENFORCE_ROLES"},
+		{"lockout disabled", func(c *Config) { c.Auth.LoginMaxFailures = 0 }, "AUTH_LOGIN_MAX_FAILURES is 0"},
+		{"fast print polling", func(c *Config) { c.Print.JobInterval = time.Second }, "PRINT_JOB_INTERVAL"},
+		{"import above upload cap", func(c *Config) { c.Import.MaxBytes = c.Server.MaxUploadBytes + 1 }, "IMPORT_MAX_BYTES"},
+		{"plaintext smtp", withSMTP(func(c *Config) { c.Email.TLSMode = "none" }), "SMTP_TLS=none"},
+		{"attachment above upload cap", func(c *Config) { c.Attachment.MaxBytes = c.Server.MaxUploadBytes + 1 }, "ATTACHMENT_MAX_BYTES"},
+		{"open metrics listener", func(c *Config) { c.Metrics.Addr = ":9090" }, "METRICS_ADDR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig(t)
+			tt.modify(cfg)
+			warnings, err := cfg.Validate()
+			if err != nil {
+				t.Fatalf("warnings must not fail validation, got: %v", err)
+			}
+			if !strings.Contains(strings.Join(warnings, "\n"), tt.want) {
+				t.Errorf("warnings %q do not mention %q", warnings, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	cfg := validConfig(t)
+	cfg.Server.Port = "0"
+	cfg.Database.User = ""
+	cfg.Print.Workers = 0
+	cfg.Storage.DownloadMode = "inline"
+	cfg.Webhook.MaxAttempts = 0
+
+	_, err := cfg.Validate()
+	if err == nil {
+		t.Fatal("expected validation to fail")
+	}
+	if n := len(problems(err)); n != 5 {
+		t.Errorf("got %d problems, want 5: %v", n, err)
+	}
+	for _, want := range []string{"SERVER_PORT", "ORACLE_USER", "PRINT_WORKERS", "STORAGE_DOWNLOAD_MODE", "WEBHOOK_MAX_ATTEMPTS"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error does not mention %s: %v", want, err)
+		}
+	}
+}
+
+// withSMTP enables email before applying modify, since email settings are
+// only checked when SMTP_HOST is set
+func withSMTP(modify func(c *Config)) func(c *Config) {
+	return func(c *Config) {
+		c.Email.SMTPHost = "smtp.example.com"
+		c.Email.From = "gprint@example.com"
+		modify(c)
+	}
+}
+
+// problems unpacks the joined validation error
+func problems(err error) []error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		return joined.Unwrap()
+	}
+	return []error{err}
+}
+
+func writeFile(t *testing.T, path string) {
+	t.Helper()
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// parseYAML parses a config file with gopkg.in/yaml.v3 and flattens it to
+// dotted keys such as "server.port". Values are nested mappings, scalars and
+// sequences of scalars; sequences are joined with commas and null values
+// become empty strings. Only one document is allowed.
+func parseYAML(data []byte) (map[string]string, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	var doc yaml.Node
+	if err := dec.Decode(&doc); err != nil {
+		if errors.Is(err, io.EOF) {
+			return map[string]string{}, nil
+		}
+		return nil, err
+	}
+	var extra yaml.Node
+	if err := dec.Decode(&extra); !errors.Is(err, io.EOF) {
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("line %d: only one YAML document is allowed", extra.Line)
+	}
+
+	values := make(map[string]string)
+	if len(doc.Content) == 0 {
+		return values, nil
+	}
+	root := doc.Content[0]
+	if root.Kind == yaml.ScalarNode && root.Tag == "!!null" {
+		return values, nil
+	}
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("line %d: the config file must be a mapping", root.Line)
+	}
+	if err := flattenYAML(root, "", values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// flattenYAML adds the entries of a mapping node to values under prefix
+func flattenYAML(mapping *yaml.Node, prefix string, values map[string]string) error {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		keyNode, valueNode := mapping.Content[i], resolveAlias(mapping.Content[i+1])
+		if keyNode.Kind != yaml.ScalarNode {
+			return fmt.Errorf("line %d: mapping keys must be scalars", keyNode.Line)
+		}
+		key := prefix + keyNode.Value
+		if _, dup := values[key]; dup {
+			return fmt.Errorf("line %d: duplicate key %q", keyNode.Line, key)
+		}
+
+		switch valueNode.Kind {
+		case yaml.MappingNode:
+			if len(valueNode.Content) == 0 {
+				values[key] = ""
+				continue
+			}
+			if err := flattenYAML(valueNode, key+".", values); err != nil {
+				return err
+			}
+		case yaml.SequenceNode:
+			items := make([]string, 0, len(valueNode.Content))
+			for _, item := range valueNode.Content {
+				item = resolveAlias(item)
+				if item.Kind != yaml.ScalarNode {
+					return fmt.Errorf("line %d: %q must be a list of plain values", item.Line, key)
+				}
+				items = append(items, scalarValue(item))
+			}
+			values[key] = strings.Join(items, ",")
+		case yaml.ScalarNode:
+			values[key] = scalarValue(valueNode)
+		default:
+			return fmt.Errorf("line %d: unsupported value for %q", valueNode.Line, key)
+		}
+	}
+	return nil
+}
+
+// resolveAlias returns the node an alias points to
+func resolveAlias(n *yaml.Node) *yaml.Node {
+	for n.Kind == yaml.AliasNode && n.Alias != nil {
+		n = n.Alias
+	}
+	return n
+}
+
+// scalarValue returns a scalar's text, with null as the empty string
+func scalarValue(n *yaml.Node) string {
+	if n.Tag == "!!null" {
+		return ""
+	}
+	return n.Value
+}
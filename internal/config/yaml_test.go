@@ -0,0 +1,132 @@
+package config
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseYAML(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want map[string]string
+	}{
+		{"empty file", "", map[string]string{}},
+		{"comments only", "# nothing here\n", map[string]string{}},
+		{
+			"nested mappings",
+			"server:\n  port: 8080\n  tls:\n    cert_file: /etc/cert.pem\nlog_level: debug\n",
+			map[string]string{"server.port": "8080", "server.tls.cert_file": "/etc/cert.pem", "log_level": "debug"},
+		},
+		{
+			"quoted scalars",
+			`a: "x # not a comment"` + "\n" + `b: 'it''s'` + "\n" + `c: "tab\there"` + "\n",
+			map[string]string{"a": "x # not a comment", "b": "it's", "c": "tab\there"},
+		},
+		{
+			"trailing comments",
+			"server:  # section\n  port: 9090 # override\n",
+			map[string]string{"server.port": "9090"},
+		},
+		{
+			"block sequence",
+			"features:\n  enabled:\n    - webhooks\n    - \"invoices\"\n",
+			map[string]string{"features.enabled": "webhooks,invoices"},
+		},
+		{
+			"flow sequence",
+			"attachment:\n  allowed_types: [application/pdf, 'image/png']\n",
+			map[string]string{"attachment.allowed_types": "application/pdf,image/png"},
+		},
+		{
+			"empty and null values",
+			"jwt:\n  secret:\n  issuer: ~\n  audience: null\nfeatures:\n  enabled: []\n",
+			map[string]string{"jwt.secret": "", "jwt.issuer": "", "jwt.audience": "", "features.enabled": ""},
+		},
+		{
+			"scalars keep their text",
+			"rate_limit:\n  enabled: true\n  rps: 0.5\nserver:\n  read_timeout: 15s\n",
+			map[string]string{"rate_limit.enabled": "true", "rate_limit.rps": "0.5", "server.read_timeout": "15s"},
+		},
+		{
+			"multi-line scalar",
+			"security:\n  csp: >-\n    default-src 'self';\n    object-src 'none'\n",
+			map[string]string{"security.csp": "default-src 'self'; object-src 'none'"},
+		},
+		{
+			"anchors and aliases",
+			"defaults: &timeout 30s\nserver:\n  read_timeout: *timeout\n",
+			map[string]string{"defaults": "30s", "server.read_timeout": "30s"},
+		},
+		{"document marker", "---\nlog_level: warn\n", map[string]string{"log_level": "warn"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseYAML([]byte(tt.in))
+			if err != nil {
+				t.Fatalf("parseYAML: %v", err)
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("parseYAML = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseYAMLErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"duplicate key", "server:\n  port: 1\n  port: 2\n", "port"},
+		{"tab indentation", "server:\n\tport: 1\n", ""},
+		{"unterminated quote", "a: \"open\n", ""},
+		{"sequence of mappings", "features:\n  - name: a\n", "list of plain values"},
+		{"top-level sequence", "- a\n- b\n", "must be a mapping"},
+		{"several documents", "a: 1\n---\nb: 2\n", "one YAML document"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseYAML([]byte(tt.in))
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error %q does not mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gprint.yaml")
+	if err := os.WriteFile(path, []byte("server:\n  port: \"9090\"\nprint:\n  workers: 8\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(ConfigFileEnv, path)
+	t.Setenv("PRINT_WORKERS", "2")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Server.Port != "9090" || cfg.Sources["server.port"] != SourceFile {
+		t.Errorf("server.port = %q from %s, want 9090 from the file", cfg.Server.Port, cfg.Sources["server.port"])
+	}
+	if cfg.Print.Workers != 2 || cfg.Sources["print.workers"] != SourceEnv {
+		t.Errorf("print.workers = %d from %s, want 2 from the environment", cfg.Print.Workers, cfg.Sources["print.workers"])
+	}
+}
+
+func TestLoadExampleConfig(t *testing.T) {
+	t.Setenv(ConfigFileEnv, filepath.Join("..", "..", "config.example.yaml"))
+	if _, err := Load(); err != nil {
+		t.Fatalf("config.example.yaml does not load: %v", err)
+	}
+}
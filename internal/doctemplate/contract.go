@@ -0,0 +1,78 @@
+package doctemplate
+
+import (
+	"time"
+
+	"github.com/zlovtnik/gprint/internal/models"
+)
+
+// FromContract builds merge data from a contract and its customer, which
+// may be nil when it could not be loaded
+func FromContract(c *models.Contract, customer *models.Customer, generatedAt time.Time) *Data {
+	data := &Data{
+		Contract: Contract{
+			Number:          c.ContractNumber,
+			Type:            string(c.ContractType),
+			Status:          string(c.Status),
+			StartDate:       formatDate(&c.StartDate),
+			EndDate:         formatDate(c.EndDate),
+			DurationMonths:  c.DurationMonths,
+			BillingCycle:    string(c.BillingCycle),
+			PaymentTerms:    c.PaymentTerms,
+			Subtotal:        c.Subtotal.StringFixed(2),
+			DiscountPct:     c.DiscountPct.String(),
+			DiscountAmount:  c.DiscountAmount.StringFixed(2),
+			TaxPct:          c.TaxPct.String(),
+			TaxAmount:       c.TaxAmount.StringFixed(2),
+			Total:           c.TotalValue.StringFixed(2),
+			TermsConditions: c.TermsConditions,
+			SignedAt:        formatDate(c.SignedAt),
+			SignedBy:        c.SignedBy,
+		},
+		Items:       make([]Item, 0, len(c.Items)),
+		GeneratedAt: generatedAt.Format("2006-01-02 15:04:05 MST"),
+	}
+	if customer != nil {
+		data.Customer = Customer{
+			Name:      customer.Name,
+			TradeName: customer.TradeName,
+			TaxID:     customer.TaxID,
+			Email:     customer.Email,
+			Phone:     customer.Phone,
+		}
+		if a := customer.Address; a != nil {
+			data.Customer.Address = Address{
+				Street:   a.Street,
+				Number:   a.Number,
+				Comp:     a.Comp,
+				District: a.District,
+				City:     a.City,
+				State:    a.State,
+				Zip:      a.Zip,
+				Country:  a.Country,
+			}
+		}
+	}
+	for _, item := range c.Items {
+		desc := item.Description
+		if desc == "" && item.Service != nil {
+			desc = item.Service.Name
+		}
+		data.Items = append(data.Items, Item{
+			Description: desc,
+			Quantity:    item.Quantity.StringFixed(2),
+			UnitPrice:   item.UnitPrice.StringFixed(2),
+			DiscountPct: item.DiscountPct.String(),
+			Total:       item.LineTotal.StringFixed(2),
+		})
+	}
+	return data
+}
+
+// formatDate formats a date as YYYY-MM-DD, or "" when unset
+func formatDate(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
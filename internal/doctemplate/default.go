@@ -0,0 +1,45 @@
+package doctemplate
+
+// DefaultBody renders contracts whose template has no body of its own, or
+// that were generated without a template
+const DefaultBody = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="UTF-8">
+<title>Contract {{.Contract.Number}}</title>
+<style>
+body { font-family: Arial, sans-serif; margin: 40px; color: #333; }
+h1 { font-size: 22px; }
+table { width: 100%; border-collapse: collapse; margin: 16px 0; }
+th, td { border: 1px solid #ccc; padding: 6px 8px; text-align: left; }
+td.num, th.num { text-align: right; }
+.meta { color: #777; font-size: 12px; }
+</style>
+</head>
+<body>
+<h1>Contract {{.Contract.Number}}</h1>
+<p>{{.Contract.Type}} &middot; {{.Contract.Status}} &middot; {{.Contract.StartDate}}{{with .Contract.EndDate}} to {{.}}{{end}}</p>
+<h2>Customer</h2>
+<p>{{.Customer.Name}}{{with .Customer.TaxID}}<br>{{.}}{{end}}{{with .Customer.Email}}<br>{{.}}{{end}}</p>
+<h2>Items</h2>
+<table>
+<thead>
+<tr><th>Description</th><th class="num">Quantity</th><th class="num">Unit price</th><th class="num">Discount %</th><th class="num">Total</th></tr>
+</thead>
+<tbody>
+{{range .Items}}<tr><td>{{.Description}}</td><td class="num">{{.Quantity}}</td><td class="num">{{.UnitPrice}}</td><td class="num">{{.DiscountPct}}</td><td class="num">{{.Total}}</td></tr>
+{{end}}</tbody>
+</table>
+<table>
+<tr><th>Subtotal</th><td class="num">{{.Contract.Subtotal}}</td></tr>
+<tr><th>Discount</th><td class="num">{{.Contract.DiscountAmount}}</td></tr>
+<tr><th>Tax</th><td class="num">{{.Contract.TaxAmount}}</td></tr>
+<tr><th>Total</th><td class="num">{{.Contract.Total}}</td></tr>
+</table>
+{{with .Contract.TermsConditions}}<h2>Terms and Conditions</h2>
+<p>{{.}}</p>
+{{end}}{{with .Contract.SignedAt}}<p>Signed on {{.}} by {{$.Contract.SignedBy}}</p>
+{{end}}<p class="meta">Generated {{.GeneratedAt}}</p>
+</body>
+</html>
+`
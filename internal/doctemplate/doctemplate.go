@@ -0,0 +1,319 @@
+// Package doctemplate checks and renders contract template bodies. A body is
+// HTML in Go template syntax whose actions may only read the merge fields of
+// Data, such as {{.Contract.Number}} or {{range .Items}}{{.Description}}{{end}}.
+// It does no I/O: callers load the body and the contract.
+package doctemplate
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template/parse"
+)
+
+// MaxBodyBytes bounds a template body
+const MaxBodyBytes = 512 << 10
+
+// Data holds the merge fields a template body can refer to. Amounts and
+// dates are preformatted so templates need no functions to print them.
+type Data struct {
+	Contract    Contract
+	Customer    Customer
+	Items       []Item
+	GeneratedAt string
+}
+
+// Contract holds the contract merge fields
+type Contract struct {
+	Number          string
+	Type            string
+	Status          string
+	StartDate       string // YYYY-MM-DD
+	EndDate         string // YYYY-MM-DD, empty when open-ended
+	DurationMonths  int
+	BillingCycle    string
+	PaymentTerms    string
+	Subtotal        string // Amounts have two decimals
+	DiscountPct     string
+	DiscountAmount  string
+	TaxPct          string
+	TaxAmount       string
+	Total           string
+	TermsConditions string
+	SignedAt        string // YYYY-MM-DD, empty until signed
+	SignedBy        string
+}
+
+// Customer holds the customer merge fields
+type Customer struct {
+	Name      string
+	TradeName string
+	TaxID     string
+	Email     string
+	Phone     string
+	Address   Address
+}
+
+// Address holds the customer address merge fields
+type Address struct {
+	Street   string
+	Number   string
+	Comp     string
+	District string
+	City     string
+	State    string
+	Zip      string
+	Country  string
+}
+
+// Item holds the merge fields of one contract item
+type Item struct {
+	Description string
+	Quantity    string
+	UnitPrice   string
+	DiscountPct string
+	Total       string
+}
+
+// Error describes why a body was rejected, pointing at the line and merge
+// field involved when they are known
+type Error struct {
+	Line    int    // 1-based line in the body; 0 when unknown
+	Field   string // Merge field, e.g. Contract.Number; empty when not about one
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+	}
+	return e.Message
+}
+
+// Template is a checked template body
+type Template struct {
+	tmpl *template.Template
+}
+
+// Parse checks a body and prepares it for rendering. The body must parse,
+// refer only to merge fields of Data, close every element other than void
+// ones, and render against Sample. Problems are returned as *Error.
+func Parse(body string) (*Template, error) {
+	if len(body) > MaxBodyBytes {
+		return nil, &Error{Message: fmt.Sprintf("body must be at most %d bytes", MaxBodyBytes)}
+	}
+	tmpl, err := template.New("body").Parse(body)
+	if err != nil {
+		return nil, templateError(err)
+	}
+	if len(tmpl.Templates()) > 1 {
+		return nil, &Error{Message: "define and block are not supported"}
+	}
+	c := checker{tree: tmpl.Tree, vars: map[string]scope{"$": {t: reflect.TypeOf(Data{})}}}
+	if err := c.list(tmpl.Tree.Root, c.vars["$"]); err != nil {
+		return nil, err
+	}
+	if err := checkHTML(body); err != nil {
+		return nil, err
+	}
+	t := &Template{tmpl: tmpl}
+	if err := t.Render(io.Discard, Sample()); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Render writes the body filled with data, HTML-escaping the merge fields.
+// Failures are returned as *Error.
+func (t *Template) Render(w io.Writer, data *Data) error {
+	if err := t.tmpl.Execute(w, data); err != nil {
+		return templateError(err)
+	}
+	return nil
+}
+
+// templateLocation matches the "template: name:line[:col]: ..." prefix of
+// parse and execution errors, capturing the field an execution failed at
+var templateLocation = regexp.MustCompile(`(?s)^(?:html/)?template: ?body:(\d+)(?::\d+)?: (?:executing "[^"]*" at <\.?([^>]*)>: )?(.*)$`)
+
+// templateError converts a parse, escaping or execution error to *Error
+func templateError(err error) error {
+	var escapeErr *template.Error
+	if errors.As(err, &escapeErr) {
+		return &Error{Line: escapeErr.Line, Message: escapeErr.Description}
+	}
+	m := templateLocation.FindStringSubmatch(err.Error())
+	if m == nil {
+		return &Error{Message: err.Error()}
+	}
+	line, _ := strconv.Atoi(m[1])
+	e := &Error{Line: line, Message: m[3]}
+	if fieldPath.MatchString(m[2]) {
+		e.Field = m[2]
+	} else if m[2] != "" {
+		e.Message = m[2] + ": " + m[3]
+	}
+	return e
+}
+
+// fieldPath matches a merge field reference such as Contract.Number
+var fieldPath = regexp.MustCompile(`^[A-Za-z]\w*(\.[A-Za-z]\w*)*$`)
+
+// scope is the type of a value in the body and the merge field it comes from
+type scope struct {
+	t    reflect.Type // nil when not known, e.g. a function result
+	path string
+}
+
+// checker walks a parsed body and rejects references to fields Data does
+// not have, tracking what dot and each variable hold along the way
+type checker struct {
+	tree *parse.Tree
+	vars map[string]scope
+}
+
+func (c *checker) list(l *parse.ListNode, dot scope) error {
+	if l == nil {
+		return nil
+	}
+	for _, n := range l.Nodes {
+		if err := c.node(n, dot); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *checker) node(n parse.Node, dot scope) error {
+	switch n := n.(type) {
+	case *parse.ActionNode:
+		_, err := c.pipe(n.Pipe, dot)
+		return err
+	case *parse.IfNode:
+		return c.branch(&n.BranchNode, dot, dot)
+	case *parse.WithNode:
+		v, err := c.pipe(n.Pipe, dot)
+		if err != nil {
+			return err
+		}
+		return c.branch(&n.BranchNode, v, dot)
+	case *parse.RangeNode:
+		v, err := c.pipe(n.Pipe, dot)
+		if err != nil {
+			return err
+		}
+		elem := scope{path: v.path}
+		if v.t != nil && (v.t.Kind() == reflect.Slice || v.t.Kind() == reflect.Array) {
+			elem.t = v.t.Elem()
+		}
+		switch len(n.Pipe.Decl) {
+		case 1:
+			c.vars[n.Pipe.Decl[0].Ident[0]] = elem
+		case 2:
+			c.vars[n.Pipe.Decl[0].Ident[0]] = scope{t: reflect.TypeOf(0)}
+			c.vars[n.Pipe.Decl[1].Ident[0]] = elem
+		}
+		return c.branch(&n.BranchNode, elem, dot)
+	case *parse.TemplateNode:
+		return c.errorf(n, "", "template calls are not supported")
+	}
+	return nil
+}
+
+// branch checks the body of an if, with or range with dot set to inner, and
+// its else part with the outer dot
+func (c *checker) branch(b *parse.BranchNode, inner, outer scope) error {
+	if err := c.list(b.List, inner); err != nil {
+		return err
+	}
+	return c.list(b.ElseList, outer)
+}
+
+// pipe checks a pipeline and returns what it evaluates to, recording it in
+// the variables it declares
+func (c *checker) pipe(p *parse.PipeNode, dot scope) (scope, error) {
+	var result scope
+	for _, cmd := range p.Cmds {
+		var err error
+		if result, err = c.command(cmd, dot); err != nil {
+			return scope{}, err
+		}
+	}
+	for _, decl := range p.Decl {
+		c.vars[decl.Ident[0]] = result
+	}
+	return result, nil
+}
+
+// command checks every argument of a command. Only a lone argument has a
+// known result; a function call's result is unknown.
+func (c *checker) command(cmd *parse.CommandNode, dot scope) (scope, error) {
+	var result scope
+	for _, arg := range cmd.Args {
+		var err error
+		if result, err = c.arg(arg, dot); err != nil {
+			return scope{}, err
+		}
+	}
+	if len(cmd.Args) != 1 {
+		return scope{}, nil
+	}
+	return result, nil
+}
+
+func (c *checker) arg(n parse.Node, dot scope) (scope, error) {
+	switch n := n.(type) {
+	case *parse.DotNode:
+		return dot, nil
+	case *parse.FieldNode:
+		return c.fields(n, dot, n.Ident)
+	case *parse.VariableNode:
+		return c.fields(n, c.vars[n.Ident[0]], n.Ident[1:])
+	case *parse.ChainNode:
+		base, err := c.arg(n.Node, dot)
+		if err != nil {
+			return scope{}, err
+		}
+		return c.fields(n, base, n.Field)
+	case *parse.PipeNode:
+		return c.pipe(n, dot)
+	}
+	return scope{}, nil
+}
+
+// fields follows a chain of field names from s, failing on the first one
+// that is not a merge field
+func (c *checker) fields(n parse.Node, s scope, idents []string) (scope, error) {
+	for _, ident := range idents {
+		if s.t == nil {
+			return scope{}, nil
+		}
+		path := ident
+		if s.path != "" {
+			path = s.path + "." + ident
+		}
+		if s.t.Kind() != reflect.Struct {
+			return scope{}, c.errorf(n, path, "unknown merge field %s", path)
+		}
+		f, ok := s.t.FieldByName(ident)
+		if !ok || !f.IsExported() {
+			return scope{}, c.errorf(n, path, "unknown merge field %s", path)
+		}
+		s = scope{t: f.Type, path: path}
+	}
+	return s, nil
+}
+
+func (c *checker) errorf(n parse.Node, field, format string, args ...any) error {
+	location, _ := c.tree.ErrorContext(n)
+	var line int
+	if parts := strings.Split(location, ":"); len(parts) >= 2 {
+		line, _ = strconv.Atoi(parts[1])
+	}
+	return &Error{Line: line, Field: field, Message: fmt.Sprintf(format, args...)}
+}
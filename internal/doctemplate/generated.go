@@ -0,0 +1,141 @@
+package doctemplate
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// generatedJSON is the part of a generated contract snapshot that maps to
+// merge fields; see pkg_contract_generation.build_contract_json
+type generatedJSON struct {
+	Meta struct {
+		GeneratedAt string `json:"generated_at"`
+	} `json:"meta"`
+	Contract struct {
+		Number          string      `json:"contract_number"`
+		Type            string      `json:"contract_type"`
+		Status          string      `json:"status"`
+		StartDate       string      `json:"start_date"`
+		EndDate         string      `json:"end_date"`
+		DurationMonths  int         `json:"duration_months"`
+		BillingCycle    string      `json:"billing_cycle"`
+		PaymentTerms    string      `json:"payment_terms"`
+		Subtotal        json.Number `json:"subtotal"`
+		DiscountPct     json.Number `json:"discount_pct"`
+		DiscountAmount  json.Number `json:"discount_amount"`
+		TaxPct          json.Number `json:"tax_pct"`
+		TaxAmount       json.Number `json:"tax_amount"`
+		Total           json.Number `json:"total_value"`
+		TermsConditions string      `json:"terms_conditions"`
+		SignedAt        string      `json:"signed_at"`
+		SignedBy        string      `json:"signed_by"`
+	} `json:"contract"`
+	Customer struct {
+		Name      string `json:"name"`
+		TradeName string `json:"trade_name"`
+		TaxID     string `json:"tax_id"`
+		Email     string `json:"email"`
+		Phone     string `json:"phone"`
+		Address   struct {
+			Street   string `json:"street"`
+			Number   string `json:"number"`
+			Comp     string `json:"complement"`
+			District string `json:"district"`
+			City     string `json:"city"`
+			State    string `json:"state"`
+			Zip      string `json:"zip"`
+			Country  string `json:"country"`
+		} `json:"address"`
+	} `json:"customer"`
+	Items []struct {
+		ServiceName string      `json:"service_name"`
+		Description string      `json:"description"`
+		Quantity    json.Number `json:"quantity"`
+		UnitPrice   json.Number `json:"unit_price"`
+		DiscountPct json.Number `json:"discount_pct"`
+		LineTotal   json.Number `json:"line_total"`
+	} `json:"items"`
+}
+
+// FromGenerated builds merge data from the JSON snapshot of a generated
+// contract, so a rendering shows exactly what was generated rather than
+// the contract as it is now
+func FromGenerated(contractJSON []byte) (*Data, error) {
+	var g generatedJSON
+	if err := json.Unmarshal(contractJSON, &g); err != nil {
+		return nil, fmt.Errorf("failed to decode generated contract: %w", err)
+	}
+
+	c := g.Contract
+	data := &Data{
+		Contract: Contract{
+			Number:          c.Number,
+			Type:            c.Type,
+			Status:          c.Status,
+			StartDate:       snapshotDate(c.StartDate),
+			EndDate:         snapshotDate(c.EndDate),
+			DurationMonths:  c.DurationMonths,
+			BillingCycle:    c.BillingCycle,
+			PaymentTerms:    c.PaymentTerms,
+			Subtotal:        snapshotNumber(c.Subtotal, 2),
+			DiscountPct:     snapshotNumber(c.DiscountPct, -1),
+			DiscountAmount:  snapshotNumber(c.DiscountAmount, 2),
+			TaxPct:          snapshotNumber(c.TaxPct, -1),
+			TaxAmount:       snapshotNumber(c.TaxAmount, 2),
+			Total:           snapshotNumber(c.Total, 2),
+			TermsConditions: c.TermsConditions,
+			SignedAt:        snapshotDate(c.SignedAt),
+			SignedBy:        c.SignedBy,
+		},
+		Customer: Customer{
+			Name:      g.Customer.Name,
+			TradeName: g.Customer.TradeName,
+			TaxID:     g.Customer.TaxID,
+			Email:     g.Customer.Email,
+			Phone:     g.Customer.Phone,
+			Address:   Address(g.Customer.Address),
+		},
+		Items:       make([]Item, 0, len(g.Items)),
+		GeneratedAt: g.Meta.GeneratedAt,
+	}
+	for _, item := range g.Items {
+		desc := item.Description
+		if desc == "" {
+			desc = item.ServiceName
+		}
+		data.Items = append(data.Items, Item{
+			Description: desc,
+			Quantity:    snapshotNumber(item.Quantity, 2),
+			UnitPrice:   snapshotNumber(item.UnitPrice, 2),
+			DiscountPct: snapshotNumber(item.DiscountPct, -1),
+			Total:       snapshotNumber(item.LineTotal, 2),
+		})
+	}
+	return data, nil
+}
+
+// snapshotDate trims a snapshot date or timestamp to YYYY-MM-DD
+func snapshotDate(s string) string {
+	if len(s) > len("2006-01-02") {
+		return s[:len("2006-01-02")]
+	}
+	return s
+}
+
+// snapshotNumber formats a snapshot number with the given decimals, or as
+// is when places is negative. Missing numbers format as "".
+func snapshotNumber(n json.Number, places int32) string {
+	if n == "" {
+		return ""
+	}
+	d, err := decimal.NewFromString(n.String())
+	if err != nil {
+		return n.String()
+	}
+	if places < 0 {
+		return d.String()
+	}
+	return d.StringFixed(places)
+}
@@ -0,0 +1,63 @@
+package doctemplate
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// checkHTML reports the first element of body that is closed without being
+// open, closed out of order or never closed. Void elements such as <br> need
+// no closing tag; template actions are plain text to it.
+func checkHTML(body string) error {
+	d := xml.NewDecoder(strings.NewReader(body))
+	d.Strict = false
+	d.Entity = xml.HTMLEntity
+
+	type open struct {
+		name string
+		line int
+	}
+	var stack []open
+	for {
+		tok, err := d.RawToken()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var syntaxErr *xml.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				return &Error{Line: syntaxErr.Line, Message: "malformed HTML: " + syntaxErr.Msg}
+			}
+			return &Error{Message: "malformed HTML: " + err.Error()}
+		}
+		line, _ := d.InputPos()
+		switch t := tok.(type) {
+		case xml.StartElement:
+			name := strings.ToLower(t.Name.Local)
+			if !slices.Contains(xml.HTMLAutoClose, name) {
+				stack = append(stack, open{name: name, line: line})
+			}
+		case xml.EndElement:
+			name := strings.ToLower(t.Name.Local)
+			if slices.Contains(xml.HTMLAutoClose, name) {
+				continue
+			}
+			if len(stack) == 0 {
+				return &Error{Line: line, Message: fmt.Sprintf("malformed HTML: </%s> closes no open element", name)}
+			}
+			if top := stack[len(stack)-1]; top.name != name {
+				return &Error{Line: line, Message: fmt.Sprintf("malformed HTML: </%s> found while <%s> from line %d is open", name, top.name, top.line)}
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+	if len(stack) > 0 {
+		top := stack[len(stack)-1]
+		return &Error{Line: top.line, Message: fmt.Sprintf("malformed HTML: <%s> is never closed", top.name)}
+	}
+	return nil
+}
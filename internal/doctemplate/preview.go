@@ -0,0 +1,52 @@
+package doctemplate
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// MaxPreviewBytes bounds the HTML a preview returns
+const MaxPreviewBytes = 1 << 20
+
+// errPreviewTooLarge stops a render once it passes MaxPreviewBytes
+var errPreviewTooLarge = errors.New("preview too large")
+
+var (
+	// scriptElement matches a script element with its content
+	scriptElement = regexp.MustCompile(`(?is)<script\b[^>]*>.*?</script\s*>`)
+	// scriptTag matches a script tag left without its pair
+	scriptTag = regexp.MustCompile(`(?i)</?script\b[^>]*>`)
+)
+
+// Preview renders the body for display, with script elements removed.
+// Output over MaxPreviewBytes fails with an *Error.
+func (t *Template) Preview(data *Data) (string, error) {
+	out := &cappedBuffer{max: MaxPreviewBytes}
+	if err := t.tmpl.Execute(out, data); err != nil {
+		if errors.Is(err, errPreviewTooLarge) {
+			return "", &Error{Message: fmt.Sprintf("rendered output is over %d bytes", MaxPreviewBytes)}
+		}
+		return "", templateError(err)
+	}
+	return StripScripts(out.String()), nil
+}
+
+// StripScripts removes script elements and stray script tags from HTML
+func StripScripts(html string) string {
+	return scriptTag.ReplaceAllString(scriptElement.ReplaceAllString(html, ""), "")
+}
+
+// cappedBuffer is a buffer that refuses writes past max bytes
+type cappedBuffer struct {
+	bytes.Buffer
+	max int
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if b.Len()+len(p) > b.max {
+		return 0, errPreviewTooLarge
+	}
+	return b.Buffer.Write(p)
+}
@@ -0,0 +1,49 @@
+package doctemplate
+
+// Sample returns representative merge data with every field set, used to
+// try bodies out before they are saved
+func Sample() *Data {
+	return &Data{
+		Contract: Contract{
+			Number:          "CTR-2024-0001",
+			Type:            "SERVICE",
+			Status:          "ACTIVE",
+			StartDate:       "2024-01-01",
+			EndDate:         "2024-12-31",
+			DurationMonths:  12,
+			BillingCycle:    "MONTHLY",
+			PaymentTerms:    "Net 30",
+			Subtotal:        "12000.00",
+			DiscountPct:     "5",
+			DiscountAmount:  "600.00",
+			TaxPct:          "10",
+			TaxAmount:       "1140.00",
+			Total:           "12540.00",
+			TermsConditions: "Services are provided as described in the items above.",
+			SignedAt:        "2024-01-02",
+			SignedBy:        "Maria Silva",
+		},
+		Customer: Customer{
+			Name:      "Acme Serviços Ltda",
+			TradeName: "Acme",
+			TaxID:     "12.345.678/0001-90",
+			Email:     "contato@acme.example",
+			Phone:     "+55 11 3000-0000",
+			Address: Address{
+				Street:   "Avenida Paulista",
+				Number:   "1000",
+				Comp:     "Sala 101",
+				District: "Bela Vista",
+				City:     "São Paulo",
+				State:    "SP",
+				Zip:      "01310-100",
+				Country:  "BR",
+			},
+		},
+		Items: []Item{
+			{Description: "Monthly maintenance", Quantity: "12.00", UnitPrice: "800.00", DiscountPct: "0", Total: "9600.00"},
+			{Description: "On-site support hours", Quantity: "20.00", UnitPrice: "120.00", DiscountPct: "0", Total: "2400.00"},
+		},
+		GeneratedAt: "2024-01-02 10:00:00 UTC",
+	}
+}
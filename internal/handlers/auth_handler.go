@@ -2,13 +2,20 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
+	"log"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/zlovtnik/gprint/internal/middleware"
 	"github.com/zlovtnik/gprint/internal/models"
 	"github.com/zlovtnik/gprint/pkg/auth"
+	"github.com/zlovtnik/gprint/pkg/lockout"
+	"github.com/zlovtnik/gprint/pkg/requestctx"
 )
 
 // internalTokenTTL is the time-to-live for internally minted JWT tokens
@@ -25,24 +32,27 @@ const msgInvalidRequestBody = "invalid request body"
 
 // AuthHandler handles authentication endpoints
 type AuthHandler struct {
-	keycloak  *auth.KeycloakClient
-	jwtSecret string
+	keycloak *auth.KeycloakClient
+	tokens   auth.VerifierConfig
+	logins   lockout.Tracker
 }
 
-// NewAuthHandler creates a new auth handler
-func NewAuthHandler(keycloak *auth.KeycloakClient, jwtSecret string) *AuthHandler {
+// NewAuthHandler creates a new auth handler. Without tokens.Secret, login and
+// refresh hand out Keycloak's own access tokens; with it (legacy installs)
+// they mint internal HS256 tokens carrying the issuer and audience in tokens
+// so the auth middleware accepts them. logins tracks failed logins per
+// username and client IP; nil disables lockouts.
+func NewAuthHandler(keycloak *auth.KeycloakClient, tokens auth.VerifierConfig, logins lockout.Tracker) *AuthHandler {
 	if keycloak == nil {
 		panic("keycloak client is required")
 	}
-	if jwtSecret == "" {
-		panic("jwt secret is required")
-	}
-	if len(jwtSecret) < minJWTSecretLen {
+	if tokens.Secret != "" && len(tokens.Secret) < minJWTSecretLen {
 		panic("jwt secret must be at least 32 bytes for HMAC-SHA256 security")
 	}
 	return &AuthHandler{
-		keycloak:  keycloak,
-		jwtSecret: jwtSecret,
+		keycloak: keycloak,
+		tokens:   tokens,
+		logins:   logins,
 	}
 }
 
@@ -62,6 +72,18 @@ type LoginResponse struct {
 	TenantID     string `json:"tenant_id,omitempty"`
 }
 
+// UserProfile is the current user's identity returned by Me
+type UserProfile struct {
+	User           string   `json:"user"` // The user recorded in created_by and updated_by
+	Username       string   `json:"username"`
+	Email          string   `json:"email,omitempty"`
+	FullName       string   `json:"full_name,omitempty"`
+	Roles          []string `json:"roles"`
+	TenantID       string   `json:"tenant_id"`
+	LoginSession   string   `json:"login_session,omitempty"`
+	ServiceAccount bool     `json:"service_account,omitempty"`
+}
+
 // RefreshRequest represents the refresh token request
 type RefreshRequest struct {
 	RefreshToken string `json:"refresh_token"`
@@ -76,12 +98,26 @@ type LogoutRequest struct {
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	var req LoginRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, msgInvalidRequestBody)
+		writeDecodeError(w, err, models.ErrCodeInvalidRequest, msgInvalidRequestBody)
 		return
 	}
 
-	if req.Username == "" || req.Password == "" {
-		writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "username and password are required")
+	var problems []models.FieldError
+	if req.Username == "" {
+		problems = append(problems, requiredField("username"))
+	}
+	if req.Password == "" {
+		problems = append(problems, requiredField("password"))
+	}
+	if len(problems) > 0 {
+		writeValidationError(w, problems...)
+		return
+	}
+
+	// The key is the submitted username, whether or not it exists, so
+	// lockouts do not reveal which accounts are real
+	loginKey := strings.ToLower(req.Username) + "|" + getClientIP(r)
+	if h.loginLocked(w, r, loginKey) {
 		return
 	}
 
@@ -91,13 +127,23 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		// Check for specific Keycloak errors
 		errMsg := err.Error()
 		if strings.Contains(errMsg, "invalid_grant") {
-			writeError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "invalid username or password")
+			if h.loginFailed(w, r, loginKey, req.Username) {
+				return
+			}
+			writeError(w, http.StatusUnauthorized, models.ErrCodeUnauthorized, "invalid username or password")
 			return
 		}
-		writeError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "authentication failed")
+		log.Printf("keycloak login failed: %v", err)
+		writeError(w, http.StatusUnauthorized, models.ErrCodeUnauthorized, "authentication failed")
 		return
 	}
 
+	if h.logins != nil {
+		if err := h.logins.Reset(r.Context(), loginKey); err != nil {
+			log.Printf("failed to reset login failures: %v", err)
+		}
+	}
+
 	// Get user info from Keycloak
 	userInfo, err := h.keycloak.GetUserInfo(r.Context(), tokenResp.AccessToken)
 	if err != nil {
@@ -107,46 +153,81 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Create our own JWT that includes tenant_id for multi-tenant isolation
-	// The tenant_id would typically come from Keycloak user attributes or a separate lookup
-	tenantID := extractTenantID(userInfo)
-
-	// Create internal JWT with required claims
-	internalToken, err := h.createInternalToken(userInfo.PreferredUsername, tenantID, tokenResp.SessionState)
+	response, err := h.sessionResponse(tokenResp, userInfo)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, ErrCodeInternalError, "failed to create session token")
+		writeError(w, http.StatusInternalServerError, models.ErrCodeInternalError, "failed to create session token")
 		return
 	}
 
-	response := LoginResponse{
-		AccessToken:  internalToken,
-		RefreshToken: tokenResp.RefreshToken,
-		ExpiresIn:    int(internalTokenTTL.Seconds()), // Use internal token TTL, not Keycloak's
-		TokenType:    "Bearer",
-		User:         userInfo.PreferredUsername,
-		TenantID:     tenantID,
+	writeJSON(w, http.StatusOK, models.SuccessResponse(response))
+}
+
+// loginLocked writes a 429 and returns true when key is locked out. Tracker
+// errors let the attempt through rather than lock everyone out.
+func (h *AuthHandler) loginLocked(w http.ResponseWriter, r *http.Request, key string) bool {
+	if h.logins == nil {
+		return false
+	}
+	remaining, err := h.logins.Locked(r.Context(), key)
+	if err != nil {
+		log.Printf("failed to check login lockout: %v", err)
+		return false
 	}
+	if remaining <= 0 {
+		return false
+	}
+	writeLoginLocked(w, remaining)
+	return true
+}
 
-	writeJSON(w, http.StatusOK, models.SuccessResponse(response))
+// loginFailed records a failed login and, when it starts a lockout, logs a
+// security event, writes a 429 and returns true
+func (h *AuthHandler) loginFailed(w http.ResponseWriter, r *http.Request, key, username string) bool {
+	if h.logins == nil {
+		return false
+	}
+	locked, err := h.logins.Fail(r.Context(), key)
+	if err != nil {
+		log.Printf("failed to record login failure: %v", err)
+		return false
+	}
+	if locked <= 0 {
+		return false
+	}
+	requestctx.Logger(r.Context()).Warn("security event: login locked out after repeated failures",
+		"event", "login_lockout",
+		"username", username,
+		"client_ip", getClientIP(r),
+		"lockout", locked,
+	)
+	writeLoginLocked(w, locked)
+	return true
+}
+
+func writeLoginLocked(w http.ResponseWriter, lockout time.Duration) {
+	seconds := int(math.Ceil(lockout.Seconds()))
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	writeError(w, http.StatusTooManyRequests, models.ErrCodeLoginLocked,
+		fmt.Sprintf("too many failed login attempts; try again in %d seconds", seconds))
 }
 
 // Refresh exchanges a refresh token for new tokens
 func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
 	var req RefreshRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, msgInvalidRequestBody)
+		writeDecodeError(w, err, models.ErrCodeInvalidRequest, msgInvalidRequestBody)
 		return
 	}
 
 	if req.RefreshToken == "" {
-		writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "refresh_token is required")
+		writeValidationError(w, requiredField("refresh_token"))
 		return
 	}
 
 	// Refresh with Keycloak
 	tokenResp, err := h.keycloak.RefreshToken(r.Context(), req.RefreshToken)
 	if err != nil {
-		writeError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "invalid or expired refresh token")
+		writeError(w, http.StatusUnauthorized, models.ErrCodeUnauthorized, "invalid or expired refresh token")
 		return
 	}
 
@@ -158,24 +239,12 @@ func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
 		userInfo = &auth.UserInfo{}
 	}
 
-	tenantID := extractTenantID(userInfo)
-
-	// Create new internal JWT
-	internalToken, err := h.createInternalToken(userInfo.PreferredUsername, tenantID, tokenResp.SessionState)
+	response, err := h.sessionResponse(tokenResp, userInfo)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, ErrCodeInternalError, "failed to create session token")
+		writeError(w, http.StatusInternalServerError, models.ErrCodeInternalError, "failed to create session token")
 		return
 	}
 
-	response := LoginResponse{
-		AccessToken:  internalToken,
-		RefreshToken: tokenResp.RefreshToken,
-		ExpiresIn:    int(internalTokenTTL.Seconds()), // Use internal token TTL, not Keycloak's
-		TokenType:    "Bearer",
-		User:         userInfo.PreferredUsername,
-		TenantID:     tenantID,
-	}
-
 	writeJSON(w, http.StatusOK, models.SuccessResponse(response))
 }
 
@@ -183,12 +252,12 @@ func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
 func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
 	var req LogoutRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, msgInvalidRequestBody)
+		writeDecodeError(w, err, models.ErrCodeInvalidRequest, msgInvalidRequestBody)
 		return
 	}
 
 	if req.RefreshToken == "" {
-		writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "refresh_token is required")
+		writeValidationError(w, requiredField("refresh_token"))
 		return
 	}
 
@@ -196,6 +265,7 @@ func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
 	if err := h.keycloak.Logout(r.Context(), req.RefreshToken); err != nil {
 		// Log but don't fail - token might already be invalid
 		// Use same message as success to prevent information leakage
+		log.Printf("keycloak logout failed: %v", err)
 	}
 
 	writeJSON(w, http.StatusOK, models.SuccessResponse(map[string]string{
@@ -203,53 +273,133 @@ func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
 	}))
 }
 
-// Me returns the current user's info
+// Me returns the current user's username, email, full name, roles and
+// tenant. Keycloak tokens are completed from the realm's userinfo endpoint.
 func (h *AuthHandler) Me(w http.ResponseWriter, r *http.Request) {
-	// Extract token from Authorization header
-	authHeader := r.Header.Get("Authorization")
-	if authHeader == "" {
-		writeError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "missing authorization header")
+	claims := middleware.GetUserClaims(r.Context())
+	if claims == nil {
+		writeError(w, http.StatusUnauthorized, models.ErrCodeUnauthorized, "missing authorization header")
 		return
 	}
 
-	parts := strings.Split(authHeader, " ")
-	if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
-		writeError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "invalid authorization header")
-		return
+	profile := UserProfile{
+		User:           claims.User,
+		Username:       firstNonEmpty(claims.PreferredUsername, claims.User),
+		Email:          claims.Email,
+		FullName:       claims.Name,
+		Roles:          middleware.GetRoles(r.Context()),
+		TenantID:       claims.TenantID,
+		LoginSession:   claims.LoginSession,
+		ServiceAccount: claims.ServiceClient != nil,
+	}
+	if profile.Roles == nil {
+		profile.Roles = []string{}
 	}
 
-	// Validate our internal token
-	claims, err := auth.ValidateToken(parts[1], h.jwtSecret)
-	if err != nil {
-		writeError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "invalid token")
-		return
+	// Keycloak's own tokens can be exchanged for the current profile;
+	// internal HS256 tokens already carry it and Keycloak would reject them
+	if !profile.ServiceAccount && isKeycloakToken(r) {
+		userInfo, err := h.keycloak.GetUserInfo(r.Context(), bearerToken(r))
+		if err != nil {
+			log.Printf("keycloak userinfo failed, using token claims: %v", err)
+		} else {
+			profile.Username = firstNonEmpty(userInfo.PreferredUsername, profile.Username)
+			profile.Email = firstNonEmpty(userInfo.Email, profile.Email)
+			profile.FullName = firstNonEmpty(userInfo.Name, profile.FullName)
+		}
 	}
 
-	writeJSON(w, http.StatusOK, models.SuccessResponse(map[string]interface{}{
-		"user":          claims.User,
-		"tenant_id":     claims.TenantID,
-		"login_session": claims.LoginSession,
-	}))
+	writeJSON(w, http.StatusOK, models.SuccessResponse(profile))
+}
+
+// bearerToken returns the token from the Authorization header
+func bearerToken(r *http.Request) string {
+	_, token, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+	return token
+}
+
+// isKeycloakToken reports whether the request's token was signed by Keycloak
+// rather than minted by this service
+func isKeycloakToken(r *http.Request) bool {
+	token, err := auth.ParseToken(bearerToken(r))
+	return err == nil && token.Method.Alg() == jwt.SigningMethodRS256.Alg()
+}
+
+// firstNonEmpty returns the first of values that is not empty
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
 }
 
-// createInternalToken creates a JWT token for internal use
-func (h *AuthHandler) createInternalToken(username, tenantID, sessionState string) (string, error) {
+// sessionResponse builds the login and refresh response. Keycloak's access
+// token is returned as is unless an HMAC secret is configured, in which case
+// an internal token is minted with a tenant_id for multi-tenant isolation.
+func (h *AuthHandler) sessionResponse(tokenResp *auth.TokenResponse, userInfo *auth.UserInfo) (LoginResponse, error) {
+	response := LoginResponse{
+		RefreshToken: tokenResp.RefreshToken,
+		TokenType:    "Bearer",
+		User:         userInfo.PreferredUsername,
+	}
+
+	// The token comes straight from Keycloak, so its claims can be read unverified
+	keycloakClaims := &auth.Claims{}
+	if token, err := auth.ParseToken(tokenResp.AccessToken); err == nil {
+		if claims := auth.ClaimsFromToken(token); claims != nil {
+			keycloakClaims = claims
+		}
+	}
+
+	if h.tokens.Secret == "" {
+		// The realm maps tenant_id into its tokens; report what it issued
+		response.AccessToken = tokenResp.AccessToken
+		response.ExpiresIn = tokenResp.ExpiresIn
+		response.TenantID = keycloakClaims.TenantID
+		return response, nil
+	}
+
+	// The tenant_id would typically come from Keycloak user attributes or a separate lookup
+	response.TenantID = extractTenantID(userInfo)
+	internalToken, err := h.createInternalToken(userInfo, response.TenantID, tokenResp.SessionState, keycloakClaims)
+	if err != nil {
+		return LoginResponse{}, err
+	}
+	response.AccessToken = internalToken
+	response.ExpiresIn = int(internalTokenTTL.Seconds()) // Use internal token TTL, not Keycloak's
+	return response, nil
+}
+
+// createInternalToken creates a JWT token for internal use, carrying over
+// the user's profile and the roles Keycloak granted
+func (h *AuthHandler) createInternalToken(userInfo *auth.UserInfo, tenantID, sessionState string, keycloakClaims *auth.Claims) (string, error) {
 	now := time.Now()
+	username := userInfo.PreferredUsername
 	claims := auth.Claims{
-		User:         username,
-		TenantID:     tenantID,
-		LoginSession: sessionState,
+		User:            username,
+		TenantID:        tenantID,
+		LoginSession:    sessionState,
+		AuthorizedParty: h.tokens.Audience,
+		Email:           firstNonEmpty(userInfo.Email, keycloakClaims.Email),
+		Name:            firstNonEmpty(userInfo.Name, keycloakClaims.Name),
+		RealmAccess:     keycloakClaims.RealmAccess,
+		ResourceAccess:  keycloakClaims.ResourceAccess,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(now.Add(internalTokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(now),
 			NotBefore: jwt.NewNumericDate(now),
-			Issuer:    "gprint",
+			Issuer:    h.tokens.Issuer,
 			Subject:   username,
 		},
 	}
+	if h.tokens.Audience != "" {
+		claims.Audience = jwt.ClaimStrings{h.tokens.Audience}
+	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(h.jwtSecret))
+	return token.SignedString([]byte(h.tokens.Secret))
 }
 
 // publicEmailDomains contains domains from public email providers
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zlovtnik/gprint/internal/models"
+	"github.com/zlovtnik/gprint/pkg/auth"
+	"github.com/zlovtnik/gprint/pkg/lockout"
+)
+
+// newLockoutAuthHandler builds an AuthHandler whose Keycloak rejects every
+// password the way Keycloak does, whether or not the user exists, with
+// lockouts read from a fixed clock
+func newLockoutAuthHandler(t *testing.T, policy lockout.Policy) *AuthHandler {
+	t.Helper()
+	keycloak := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error":"invalid_grant","error_description":"Invalid user credentials"}`))
+	}))
+	t.Cleanup(keycloak.Close)
+
+	client := auth.NewKeycloakClientWithHTTPClient(auth.KeycloakConfig{BaseURL: keycloak.URL, Realm: "gprint", ClientID: "gprint-api"}, keycloak.Client())
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	return NewAuthHandler(client, auth.VerifierConfig{}, lockout.NewMemoryWithClock(policy, func() time.Time { return now }))
+}
+
+func login(h *AuthHandler, username string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", strings.NewReader(`{"username":"`+username+`","password":"wrong"}`))
+	req.RemoteAddr = "192.0.2.1:4000"
+	rec := httptest.NewRecorder()
+	h.Login(rec, req)
+	return rec
+}
+
+func TestLoginLockoutDoesNotRevealAccounts(t *testing.T) {
+	policy := lockout.Policy{MaxFailures: 3, Window: 10 * time.Minute, Lockout: 15 * time.Minute}
+	h := newLockoutAuthHandler(t, policy)
+
+	// alice exists and ghost does not; Keycloak answers both the same way,
+	// and so must every response the lockout adds
+	var responses [2][]*httptest.ResponseRecorder
+	for i, username := range []string{"alice", "ghost"} {
+		for attempt := 0; attempt <= policy.MaxFailures; attempt++ {
+			responses[i] = append(responses[i], login(h, username))
+		}
+	}
+
+	for attempt := range responses[0] {
+		known, unknown := responses[0][attempt], responses[1][attempt]
+		if known.Code != unknown.Code || known.Body.String() != unknown.Body.String() || known.Header().Get("Retry-After") != unknown.Header().Get("Retry-After") {
+			t.Errorf("attempt %d: known user got %d %s (Retry-After %q), unknown user got %d %s (Retry-After %q)",
+				attempt+1, known.Code, known.Body, known.Header().Get("Retry-After"),
+				unknown.Code, unknown.Body, unknown.Header().Get("Retry-After"))
+		}
+	}
+
+	for attempt, rec := range responses[1] {
+		wantStatus, wantCode := http.StatusUnauthorized, models.ErrCodeUnauthorized
+		if attempt >= policy.MaxFailures-1 {
+			wantStatus, wantCode = http.StatusTooManyRequests, models.ErrCodeLoginLocked
+		}
+		if rec.Code != wantStatus {
+			t.Fatalf("attempt %d: status = %d, want %d", attempt+1, rec.Code, wantStatus)
+		}
+		if code := decodeError(t, rec).Code; code != wantCode {
+			t.Errorf("attempt %d: error code = %s, want %s", attempt+1, code, wantCode)
+		}
+		if wantStatus == http.StatusTooManyRequests && rec.Header().Get("Retry-After") != "900" {
+			t.Errorf("attempt %d: Retry-After = %q, want 900", attempt+1, rec.Header().Get("Retry-After"))
+		}
+	}
+}
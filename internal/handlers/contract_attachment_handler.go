@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/zlovtnik/gprint/internal/middleware"
+	"github.com/zlovtnik/gprint/internal/models"
+	"github.com/zlovtnik/gprint/internal/service"
+)
+
+// roleContractsOverride allows deleting attachments of signed contracts
+const roleContractsOverride = "contracts:override"
+
+// ContractAttachmentHandler handles contract attachment HTTP requests
+type ContractAttachmentHandler struct {
+	svc   *service.ContractAttachmentService
+	roles middleware.RoleConfig
+}
+
+// NewContractAttachmentHandler creates a new ContractAttachmentHandler
+// Panics if svc is nil to fail fast on misconfiguration
+func NewContractAttachmentHandler(svc *service.ContractAttachmentService, roles middleware.RoleConfig) *ContractAttachmentHandler {
+	if svc == nil {
+		panic("NewContractAttachmentHandler: svc (ContractAttachmentService) must not be nil")
+	}
+	return &ContractAttachmentHandler{svc: svc, roles: roles}
+}
+
+// Upload handles POST /api/v1/contracts/{id}/attachments. The file arrives
+// as the "file" part of a multipart/form-data upload, with its name and
+// Content-Type taken from the part.
+func (h *ContractAttachmentHandler) Upload(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	contractID, err := parseIDFromPath(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidID, MsgInvalidContractID)
+		return
+	}
+
+	mr, err := r.MultipartReader()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidRequest, MsgMultipartRequired)
+		return
+	}
+	var req service.UploadAttachmentRequest
+	for req.Body == nil {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			writeValidationError(w, requiredField("file"))
+			return
+		}
+		if err != nil {
+			writeDecodeError(w, err, models.ErrCodeInvalidRequest, MsgMultipartRequired)
+			return
+		}
+		if part.FormName() == "file" {
+			req = service.UploadAttachmentRequest{
+				TenantID:    tenantID,
+				ContractID:  contractID,
+				FileName:    part.FileName(),
+				ContentType: part.Header.Get("Content-Type"),
+				Body:        part,
+				UploadedBy:  middleware.GetUser(r.Context()),
+			}
+		}
+	}
+
+	attachment, err := h.svc.Upload(r.Context(), req)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.Is(err, service.ErrContractNotFound):
+			writeError(w, http.StatusNotFound, models.ErrCodeNotFound, MsgContractNotFound)
+		case errors.Is(err, service.ErrAttachmentTooLarge):
+			writeError(w, http.StatusRequestEntityTooLarge, models.ErrCodeTooLarge, err.Error())
+		case errors.As(err, &tooLarge):
+			writeError(w, http.StatusRequestEntityTooLarge, models.ErrCodeTooLarge, MsgPayloadTooLarge)
+		case errors.Is(err, service.ErrAttachmentTypeNotAllowed):
+			writeError(w, http.StatusUnsupportedMediaType, models.ErrCodeUnsupportedType, err.Error())
+		case errors.Is(err, service.ErrInvalidAttachment):
+			writeError(w, http.StatusBadRequest, models.ErrCodeInvalidRequest, err.Error())
+		default:
+			log.Printf("failed to upload contract attachment: %v", err)
+			writeServerError(w, err, MsgInternalServerError)
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, models.SuccessResponse(attachment))
+}
+
+// List handles GET /api/v1/contracts/{id}/attachments
+func (h *ContractAttachmentHandler) List(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	contractID, err := parseIDFromPath(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidID, MsgInvalidContractID)
+		return
+	}
+
+	params := parsePagination(r)
+	attachments, total, err := h.svc.List(r.Context(), tenantID, contractID, params)
+	if err != nil {
+		if errors.Is(err, service.ErrContractNotFound) {
+			writeError(w, http.StatusNotFound, models.ErrCodeNotFound, MsgContractNotFound)
+			return
+		}
+		log.Printf("failed to list contract attachments: %v", err)
+		writeServerError(w, err, MsgInternalServerError)
+		return
+	}
+	if attachments == nil {
+		attachments = []models.ContractAttachment{}
+	}
+
+	writeJSON(w, http.StatusOK, models.SuccessResponse(models.NewPaginatedResponse(attachments, params.Page, params.PageSize, total)))
+}
+
+// Download handles GET /api/v1/contracts/{id}/attachments/{attachmentId}.
+// The file is streamed and hashed on the way out; its SHA-256 is sent in
+// Repr-Digest, and when the content read does not match it the connection is
+// dropped instead of ending the response, so clients never get a complete
+// but corrupt file.
+func (h *ContractAttachmentHandler) Download(w http.ResponseWriter, r *http.Request) {
+	contractID, attachmentID, ok := parseAttachmentPath(w, r)
+	if !ok {
+		return
+	}
+
+	download, err := h.svc.Download(r.Context(), middleware.GetTenantID(r.Context()), contractID, attachmentID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrAttachmentNotFound):
+			writeError(w, http.StatusNotFound, models.ErrCodeNotFound, MsgAttachmentNotFound)
+		case errors.Is(err, service.ErrAttachmentFileMissing):
+			writeError(w, http.StatusNotFound, models.ErrCodeFileNotFound, MsgAttachmentMissing)
+		default:
+			log.Printf("failed to download contract attachment: %v", err)
+			writeServerError(w, err, MsgInternalServerError)
+		}
+		return
+	}
+	defer download.Body.Close()
+
+	w.Header().Set("Content-Type", download.Attachment.ContentType)
+	w.Header().Set("Content-Disposition", attachmentDisposition(download.Attachment.FileName))
+	if sum, err := hex.DecodeString(download.Attachment.Checksum); err == nil {
+		w.Header().Set("Repr-Digest", "sha-256=:"+base64.StdEncoding.EncodeToString(sum)+":")
+	}
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, download.Body); err != nil {
+		log.Printf("failed to stream contract attachment (id=%d): %v", attachmentID, err)
+		if errors.Is(err, service.ErrAttachmentChecksumMismatch) {
+			panic(http.ErrAbortHandler)
+		}
+	}
+}
+
+// Delete handles DELETE /api/v1/contracts/{id}/attachments/{attachmentId}
+func (h *ContractAttachmentHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	contractID, attachmentID, ok := parseAttachmentPath(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.svc.Delete(r.Context(), middleware.GetTenantID(r.Context()), contractID, attachmentID,
+		middleware.GetUser(r.Context()), middleware.HasRole(h.roles, r, roleContractsOverride))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrContractNotFound):
+			writeError(w, http.StatusNotFound, models.ErrCodeNotFound, MsgContractNotFound)
+		case errors.Is(err, service.ErrAttachmentNotFound):
+			writeError(w, http.StatusNotFound, models.ErrCodeNotFound, MsgAttachmentNotFound)
+		case errors.Is(err, service.ErrAttachmentOverrideRequired):
+			writeError(w, http.StatusForbidden, models.ErrCodeForbidden, MsgOverrideRequired)
+		default:
+			log.Printf("failed to delete contract attachment: %v", err)
+			writeServerError(w, err, MsgInternalServerError)
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, models.SuccessResponse(nil))
+}
+
+// parseAttachmentPath reads the contract and attachment IDs from the path,
+// writing a 400 when either is malformed
+func parseAttachmentPath(w http.ResponseWriter, r *http.Request) (int64, int64, bool) {
+	contractID, err := parseIDFromPath(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidID, MsgInvalidContractID)
+		return 0, 0, false
+	}
+	attachmentID, err := parseIDFromPath(r, "attachmentId")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidID, MsgInvalidAttachmentID)
+		return 0, 0, false
+	}
+	return contractID, attachmentID, true
+}
@@ -3,10 +3,16 @@ package handlers
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"html"
 	"io"
 	"log"
 	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
 
+	"github.com/zlovtnik/gprint/internal/doctemplate"
 	"github.com/zlovtnik/gprint/internal/middleware"
 	"github.com/zlovtnik/gprint/internal/models"
 	"github.com/zlovtnik/gprint/internal/service"
@@ -32,6 +38,60 @@ func getSessionID(r *http.Request) string {
 	return ""
 }
 
+// contentStream writes generated content as the contract_data field of a
+// success response, copying the JSON as it is read from the database
+// instead of buffering it. Once started, a failure can no longer become an
+// error response, so finish aborts the connection instead.
+type contentStream struct {
+	w       http.ResponseWriter
+	started bool
+}
+
+// write writes the response for meta, which must encode as a JSON object,
+// with contract_data read from content. Empty content is written as null.
+func (s *contentStream) write(meta any, content io.Reader) error {
+	head, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	s.started = true
+	s.w.Header().Set("Content-Type", "application/json")
+	s.w.WriteHeader(http.StatusOK)
+
+	prefix := `{"success":true,"data":` + string(head[:len(head)-1])
+	if len(head) > 2 {
+		prefix += ","
+	}
+	if _, err := io.WriteString(s.w, prefix+`"contract_data":`); err != nil {
+		return err
+	}
+	n, err := io.Copy(s.w, content)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		if _, err := io.WriteString(s.w, "null"); err != nil {
+			return err
+		}
+	}
+	_, err = io.WriteString(s.w, "}}\n")
+	return err
+}
+
+// finish reports whether the response was written. A stream that failed
+// after starting aborts the connection, so the client sees a truncated
+// response rather than a valid one.
+func (s *contentStream) finish(err error) bool {
+	if !s.started {
+		return false
+	}
+	if err != nil {
+		log.Printf("failed to stream generated content: %v", err)
+		panic(http.ErrAbortHandler)
+	}
+	return true
+}
+
 // Generate handles POST /api/v1/contracts/{id}/generate
 // Generates a printable contract document
 func (h *ContractGenerationHandler) Generate(w http.ResponseWriter, r *http.Request) {
@@ -40,14 +100,14 @@ func (h *ContractGenerationHandler) Generate(w http.ResponseWriter, r *http.Requ
 
 	contractID, err := parseIDFromPath(r, "id")
 	if err != nil {
-		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, MsgInvalidContractID)
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidID, MsgInvalidContractID)
 		return
 	}
 
 	// Parse optional request body
 	var req models.GenerateContractRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
-		writeError(w, http.StatusBadRequest, ErrCodeInvalidJSON, "Invalid request body")
+		writeDecodeError(w, err, models.ErrCodeInvalidJSON, "Invalid request body")
 		return
 	}
 
@@ -56,10 +116,22 @@ func (h *ContractGenerationHandler) Generate(w http.ResponseWriter, r *http.Requ
 	sessionID := getSessionID(r)
 
 	// Call service - all sensitive processing happens in database
-	result, err := h.svc.GenerateContract(r.Context(), tenantID, contractID, userID, &req, ipAddress, sessionID)
+	var result *models.GenerateContractResponse
+	stream := &contentStream{w: w}
+	err = h.svc.GenerateContractStream(r.Context(), tenantID, contractID, userID, &req, ipAddress, sessionID,
+		func(res *models.GenerateContractResponse, content io.Reader) error {
+			result = res
+			if !res.Success {
+				return nil
+			}
+			return stream.write(res, content)
+		})
+	if stream.finish(err) {
+		return
+	}
 	if err != nil {
 		log.Printf("failed to generate contract: %v", err)
-		writeError(w, http.StatusInternalServerError, ErrCodeInternalError, MsgInternalServerError)
+		writeServerError(w, err, MsgInternalServerError)
 		return
 	}
 
@@ -73,10 +145,7 @@ func (h *ContractGenerationHandler) Generate(w http.ResponseWriter, r *http.Requ
 			status = http.StatusForbidden
 		}
 		writeError(w, status, result.ErrorCode, result.ErrorMessage)
-		return
 	}
-
-	writeJSON(w, http.StatusOK, models.SuccessResponse(result))
 }
 
 // GetContent handles GET /api/v1/contracts/{id}/generated/{gen_id}
@@ -87,34 +156,38 @@ func (h *ContractGenerationHandler) GetContent(w http.ResponseWriter, r *http.Re
 
 	contractID, err := parseIDFromPath(r, "id")
 	if err != nil {
-		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, MsgInvalidContractID)
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidID, MsgInvalidContractID)
 		return
 	}
 
 	generatedID, err := parseIDFromPath(r, "gen_id")
 	if err != nil {
-		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, MsgInvalidGeneratedID)
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidID, MsgInvalidGeneratedID)
 		return
 	}
 
 	// Validate that the generated contract belongs to this contract (done in PL/SQL too)
 	_ = contractID // Used for route organization; actual validation in PL/SQL
 
-	content, err := h.svc.GetGeneratedContent(r.Context(), tenantID, generatedID, userID)
+	stream := &contentStream{w: w}
+	err = h.svc.StreamGeneratedContent(r.Context(), tenantID, generatedID, userID,
+		func(meta *models.GeneratedContentMeta, content io.Reader) error {
+			return stream.write(meta, content)
+		})
+	if stream.finish(err) {
+		return
+	}
 	if err != nil {
 		switch {
 		case errors.Is(err, service.ErrNotFound):
-			writeError(w, http.StatusNotFound, ErrCodeNotFound, MsgGeneratedNotFound)
+			writeError(w, http.StatusNotFound, models.ErrCodeNotFound, MsgGeneratedNotFound)
 		case errors.Is(err, service.ErrUnauthorized):
-			writeError(w, http.StatusForbidden, ErrCodeUnauthorized, "Access denied to this generated contract")
+			writeError(w, http.StatusForbidden, models.ErrCodeUnauthorized, "Access denied to this generated contract")
 		default:
 			log.Printf("failed to get generated content: %v", err)
-			writeError(w, http.StatusInternalServerError, ErrCodeInternalError, MsgInternalServerError)
+			writeServerError(w, err, MsgInternalServerError)
 		}
-		return
 	}
-
-	writeJSON(w, http.StatusOK, models.SuccessResponse(content))
 }
 
 // GetLatest handles GET /api/v1/contracts/{id}/generated/latest
@@ -125,18 +198,22 @@ func (h *ContractGenerationHandler) GetLatest(w http.ResponseWriter, r *http.Req
 
 	contractID, err := parseIDFromPath(r, "id")
 	if err != nil {
-		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, MsgInvalidContractID)
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidID, MsgInvalidContractID)
 		return
 	}
 
-	content, err := h.svc.GetLatestGenerated(r.Context(), tenantID, contractID, userID)
+	stream := &contentStream{w: w}
+	err = h.svc.StreamLatestGenerated(r.Context(), tenantID, contractID, userID,
+		func(meta *models.GeneratedContentMeta, content io.Reader) error {
+			return stream.write(meta, content)
+		})
+	if stream.finish(err) {
+		return
+	}
 	if err != nil {
 		log.Printf("failed to get latest generated: %v", err)
-		writeError(w, http.StatusNotFound, ErrCodeNotFound, MsgNoGeneratedContract)
-		return
+		writeError(w, http.StatusNotFound, models.ErrCodeNotFound, MsgNoGeneratedContract)
 	}
-
-	writeJSON(w, http.StatusOK, models.SuccessResponse(content))
 }
 
 // ListGenerated handles GET /api/v1/contracts/{id}/generated
@@ -146,7 +223,7 @@ func (h *ContractGenerationHandler) ListGenerated(w http.ResponseWriter, r *http
 
 	contractID, err := parseIDFromPath(r, "id")
 	if err != nil {
-		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, MsgInvalidContractID)
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidID, MsgInvalidContractID)
 		return
 	}
 
@@ -154,7 +231,7 @@ func (h *ContractGenerationHandler) ListGenerated(w http.ResponseWriter, r *http
 	items, total, err := h.svc.ListGeneratedContracts(r.Context(), tenantID, contractID, params.Page, params.PageSize)
 	if err != nil {
 		log.Printf("failed to list generated contracts: %v", err)
-		writeError(w, http.StatusInternalServerError, ErrCodeInternalError, MsgInternalServerError)
+		writeServerError(w, err, MsgInternalServerError)
 		return
 	}
 
@@ -170,13 +247,13 @@ func (h *ContractGenerationHandler) LogDownload(w http.ResponseWriter, r *http.R
 
 	contractID, err := parseIDFromPath(r, "id")
 	if err != nil {
-		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, MsgInvalidContractID)
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidID, MsgInvalidContractID)
 		return
 	}
 
 	generatedID, err := parseIDFromPath(r, "gen_id")
 	if err != nil {
-		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, MsgInvalidGeneratedID)
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidID, MsgInvalidGeneratedID)
 		return
 	}
 
@@ -190,14 +267,18 @@ func (h *ContractGenerationHandler) LogDownload(w http.ResponseWriter, r *http.R
 	}
 
 	// Return the content
-	content, err := h.svc.GetGeneratedContent(r.Context(), tenantID, generatedID, userID)
+	stream := &contentStream{w: w}
+	err = h.svc.StreamGeneratedContent(r.Context(), tenantID, generatedID, userID,
+		func(meta *models.GeneratedContentMeta, content io.Reader) error {
+			return stream.write(meta, content)
+		})
+	if stream.finish(err) {
+		return
+	}
 	if err != nil {
 		log.Printf("failed to get content for download: %v", err)
-		writeError(w, http.StatusNotFound, ErrCodeNotFound, MsgGeneratedNotFound)
-		return
+		writeError(w, http.StatusNotFound, models.ErrCodeNotFound, MsgGeneratedNotFound)
 	}
-
-	writeJSON(w, http.StatusOK, models.SuccessResponse(content))
 }
 
 // LogPrint handles POST /api/v1/contracts/{id}/generated/{gen_id}/print
@@ -208,13 +289,13 @@ func (h *ContractGenerationHandler) LogPrint(w http.ResponseWriter, r *http.Requ
 
 	contractID, err := parseIDFromPath(r, "id")
 	if err != nil {
-		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, MsgInvalidContractID)
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidID, MsgInvalidContractID)
 		return
 	}
 
 	generatedID, err := parseIDFromPath(r, "gen_id")
 	if err != nil {
-		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, MsgInvalidGeneratedID)
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidID, MsgInvalidGeneratedID)
 		return
 	}
 
@@ -223,7 +304,7 @@ func (h *ContractGenerationHandler) LogPrint(w http.ResponseWriter, r *http.Requ
 
 	if err := h.svc.LogPrintAction(r.Context(), tenantID, contractID, generatedID, userID, ipAddress, sessionID); err != nil {
 		log.Printf("failed to log print action for contract %d, generated %d: %v", contractID, generatedID, err)
-		writeError(w, http.StatusInternalServerError, ErrCodeInternalError, "Failed to log print action")
+		writeServerError(w, err, "Failed to log print action")
 		return
 	}
 
@@ -233,39 +314,134 @@ func (h *ContractGenerationHandler) LogPrint(w http.ResponseWriter, r *http.Requ
 	}))
 }
 
-// VerifyIntegrity handles GET /api/v1/contracts/{id}/generated/{gen_id}/verify
-// Verifies the integrity of a generated contract with tenant authorization
-func (h *ContractGenerationHandler) VerifyIntegrity(w http.ResponseWriter, r *http.Request) {
-	tenantID := middleware.GetTenantID(r.Context())
+// CheckIntegrity handles GET /api/v1/contracts/{id}/generated/{gen_id}/verify
+// Checks a generated version against its content hash. Nothing is
+// recorded; POST the same path to log the check.
+func (h *ContractGenerationHandler) CheckIntegrity(w http.ResponseWriter, r *http.Request) {
+	contractID, generatedID, ok := parseGeneratedPath(w, r)
+	if !ok {
+		return
+	}
+	check, err := h.svc.CheckGenerated(r.Context(), middleware.GetTenantID(r.Context()), contractID, generatedID)
+	writeIntegrityCheck(w, check, err)
+}
 
-	// Contract ID from path (for route consistency, validated by tenant check in PL/SQL)
-	_, err := parseIDFromPath(r, "id")
-	if err != nil {
-		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, MsgInvalidContractID)
+// VerifyIntegrity handles POST /api/v1/contracts/{id}/generated/{gen_id}/verify
+// Checks a generated version against its content hash and logs the check.
+// Versions of another tenant get a 403.
+func (h *ContractGenerationHandler) VerifyIntegrity(w http.ResponseWriter, r *http.Request) {
+	contractID, generatedID, ok := parseGeneratedPath(w, r)
+	if !ok {
 		return
 	}
+	check, err := h.svc.VerifyGenerated(r.Context(), middleware.GetTenantID(r.Context()), contractID, generatedID,
+		middleware.GetUser(r.Context()), getClientIP(r), getSessionID(r))
+	writeIntegrityCheck(w, check, err)
+}
 
+// parseGeneratedPath reads the contract and generated version IDs from the
+// path, writing a 400 when either is malformed
+func parseGeneratedPath(w http.ResponseWriter, r *http.Request) (int64, int64, bool) {
+	contractID, err := parseIDFromPath(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidID, MsgInvalidContractID)
+		return 0, 0, false
+	}
 	generatedID, err := parseIDFromPath(r, "gen_id")
 	if err != nil {
-		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, MsgInvalidGeneratedID)
-		return
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidID, MsgInvalidGeneratedID)
+		return 0, 0, false
 	}
+	return contractID, generatedID, true
+}
 
-	isValid, err := h.svc.VerifyContentIntegrity(r.Context(), tenantID, generatedID)
+// writeIntegrityCheck writes the result of an integrity check
+func writeIntegrityCheck(w http.ResponseWriter, check *models.IntegrityCheck, err error) {
 	if err != nil {
 		switch {
 		case errors.Is(err, service.ErrUnauthorized):
-			writeError(w, http.StatusForbidden, ErrCodeUnauthorized, "Access denied to this generated contract")
+			writeError(w, http.StatusForbidden, models.ErrCodeForbidden, MsgGeneratedForbidden)
 		case errors.Is(err, service.ErrNotFound):
-			writeError(w, http.StatusNotFound, ErrCodeNotFound, MsgGeneratedNotFound)
+			writeError(w, http.StatusNotFound, models.ErrCodeNotFound, MsgGeneratedNotFound)
 		default:
 			log.Printf("failed to verify integrity: %v", err)
-			writeError(w, http.StatusInternalServerError, ErrCodeInternalError, MsgInternalServerError)
+			writeServerError(w, err, MsgInternalServerError)
 		}
 		return
 	}
+	writeJSON(w, http.StatusOK, models.SuccessResponse(check))
+}
+
+// SweepIntegrity handles POST /api/v1/admin/verify-integrity
+// Checks a tenant's generated versions against their content hashes and
+// returns the tampered ones. The tenant defaults to the caller's.
+func (h *ContractGenerationHandler) SweepIntegrity(w http.ResponseWriter, r *http.Request) {
+	var req models.IntegritySweepRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeDecodeError(w, err, models.ErrCodeInvalidJSON, "Invalid request body")
+		return
+	}
+	if req.TenantID == "" {
+		req.TenantID = middleware.GetTenantID(r.Context())
+	}
+	if req.AfterID < 0 || req.MaxRecords < 0 {
+		writeError(w, http.StatusBadRequest, models.ErrCodeValidationErr, MsgInvalidSweepRange)
+		return
+	}
+
+	sweep, err := h.svc.SweepIntegrity(r.Context(), req, middleware.GetUser(r.Context()))
+	if err != nil {
+		log.Printf("failed to sweep integrity of tenant %s: %v", req.TenantID, err)
+		writeServerError(w, err, MsgInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, models.SuccessResponse(sweep))
+}
+
+// contentHashPattern matches the SHA-256 hex digest stored for generated contracts
+var contentHashPattern = regexp.MustCompile(`^[0-9a-fA-F]{64}$`)
 
-	writeJSON(w, http.StatusOK, models.SuccessResponse(map[string]bool{"valid": isValid}))
+// verificationMessages are the human-readable outcomes shown on the verification page
+var verificationMessages = map[models.VerificationStatus]string{
+	models.VerificationValid:    "This document is authentic and has not been modified.",
+	models.VerificationTampered: "This document does not match the issued original and may have been altered.",
+	models.VerificationNotFound: "No document matching this verification code was found.",
+}
+
+// VerifyByHash handles GET /api/v1/verify/{hash}
+// Public endpoint reached from the QR code on printed contracts. It reports only
+// whether the document is valid, tampered or unknown - never its contents.
+func (h *ContractGenerationHandler) VerifyByHash(w http.ResponseWriter, r *http.Request) {
+	hash := r.PathValue("hash")
+	if !contentHashPattern.MatchString(hash) {
+		writeError(w, http.StatusBadRequest, models.ErrCodeValidationErr, MsgInvalidContentHash)
+		return
+	}
+
+	status, err := h.svc.VerifyByHash(r.Context(), hash)
+	if err != nil {
+		log.Printf("failed to verify content hash: %v", err)
+		writeServerError(w, err, MsgInternalServerError)
+		return
+	}
+
+	if strings.Contains(r.Header.Get("Accept"), "text/html") {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `<!DOCTYPE html>
+<html>
+<head><meta charset="UTF-8"><title>Document verification</title></head>
+<body style="font-family: Arial, sans-serif; margin: 40px;">
+    <h1>Document verification</h1>
+    <p><strong>%s</strong></p>
+    <p>%s</p>
+</body>
+</html>`, html.EscapeString(strings.ToUpper(strings.ReplaceAll(string(status), "_", " "))), html.EscapeString(verificationMessages[status]))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, models.SuccessResponse(models.VerificationResponse{Status: status}))
 }
 
 // GetStats handles GET /api/v1/contracts/generation/stats
@@ -276,13 +452,89 @@ func (h *ContractGenerationHandler) GetStats(w http.ResponseWriter, r *http.Requ
 	stats, err := h.svc.GetGenerationStats(r.Context(), tenantID)
 	if err != nil {
 		log.Printf("failed to get generation stats: %v", err)
-		writeError(w, http.StatusInternalServerError, ErrCodeInternalError, MsgInternalServerError)
+		writeServerError(w, err, MsgInternalServerError)
 		return
 	}
 
 	writeJSON(w, http.StatusOK, models.SuccessResponse(stats))
 }
 
+// GetContractStats handles GET /api/v1/contracts/{id}/generation-stats
+// Returns generation statistics for one contract
+func (h *ContractGenerationHandler) GetContractStats(w http.ResponseWriter, r *http.Request) {
+	contractID, err := parseIDFromPath(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidID, MsgInvalidContractID)
+		return
+	}
+
+	stats, err := h.svc.GetContractGenerationStats(r.Context(), middleware.GetTenantID(r.Context()), contractID)
+	if err != nil {
+		if errors.Is(err, service.ErrContractNotFound) {
+			writeError(w, http.StatusNotFound, models.ErrCodeNotFound, MsgContractNotFound)
+			return
+		}
+		log.Printf("failed to get contract generation stats: %v", err)
+		writeServerError(w, err, MsgInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, models.SuccessResponse(stats))
+}
+
+// validGenerationActions contains the action values ListActions filters on
+var validGenerationActions = map[models.ContractGenerationAction]bool{
+	models.GenerationActionGenerate: true,
+	models.GenerationActionView:     true,
+	models.GenerationActionDownload: true,
+	models.GenerationActionPrint:    true,
+	models.GenerationActionCompare:  true,
+}
+
+// ListActions handles GET /api/v1/contracts/{id}/actions
+// Lists the contract's action log, newest first. Supports action and
+// performed_by filters.
+func (h *ContractGenerationHandler) ListActions(w http.ResponseWriter, r *http.Request) {
+	contractID, err := parseIDFromPath(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidID, MsgInvalidContractID)
+		return
+	}
+
+	q := r.URL.Query()
+	var filter models.ContractActionFilter
+	if action := strings.ToUpper(strings.TrimSpace(q.Get("action"))); action != "" {
+		if !validGenerationActions[models.ContractGenerationAction(action)] {
+			writeError(w, http.StatusBadRequest, models.ErrCodeValidationErr, fmt.Sprintf("invalid action %q", action))
+			return
+		}
+		filter.Action = models.ContractGenerationAction(action)
+	}
+	if performedBy := strings.TrimSpace(q.Get("performed_by")); performedBy != "" {
+		if len(performedBy) > maxRequestedByLen {
+			writeError(w, http.StatusBadRequest, models.ErrCodeValidationErr,
+				fmt.Sprintf("performed_by must be at most %d characters", maxRequestedByLen))
+			return
+		}
+		filter.PerformedBy = performedBy
+	}
+
+	params := parsePagination(r)
+	entries, total, err := h.svc.ListContractActions(r.Context(), middleware.GetTenantID(r.Context()), contractID, filter, params.Page, params.PageSize)
+	if err != nil {
+		if errors.Is(err, service.ErrContractNotFound) {
+			writeError(w, http.StatusNotFound, models.ErrCodeNotFound, MsgContractNotFound)
+			return
+		}
+		log.Printf("failed to list contract actions: %v", err)
+		writeServerError(w, err, MsgInternalServerError)
+		return
+	}
+
+	result := models.NewPaginatedResponse(entries, params.Page, params.PageSize, total)
+	writeJSON(w, http.StatusOK, models.SuccessResponse(result))
+}
+
 // ListTemplates handles GET /api/v1/contracts/templates
 // Lists available contract templates for the tenant
 func (h *ContractGenerationHandler) ListTemplates(w http.ResponseWriter, r *http.Request) {
@@ -291,7 +543,25 @@ func (h *ContractGenerationHandler) ListTemplates(w http.ResponseWriter, r *http
 	templates, err := h.svc.ListTemplates(r.Context(), tenantID)
 	if err != nil {
 		log.Printf("failed to list templates: %v", err)
-		writeError(w, http.StatusInternalServerError, ErrCodeInternalError, MsgInternalServerError)
+		writeServerError(w, err, MsgInternalServerError)
+		return
+	}
+
+	responses := make([]models.ContractTemplateResponse, len(templates))
+	for i, t := range templates {
+		responses[i] = t.ToResponse()
+	}
+
+	writeJSON(w, http.StatusOK, models.SuccessResponse(responses))
+}
+
+// ListAllTemplates handles GET /api/v1/templates
+// Lists the tenant's templates, retired ones included, without their bodies
+func (h *ContractGenerationHandler) ListAllTemplates(w http.ResponseWriter, r *http.Request) {
+	templates, err := h.svc.ListAllTemplates(r.Context(), middleware.GetTenantID(r.Context()))
+	if err != nil {
+		log.Printf("failed to list templates: %v", err)
+		writeServerError(w, err, MsgInternalServerError)
 		return
 	}
 
@@ -302,3 +572,302 @@ func (h *ContractGenerationHandler) ListTemplates(w http.ResponseWriter, r *http
 
 	writeJSON(w, http.StatusOK, models.SuccessResponse(responses))
 }
+
+// GetTemplate handles GET /api/v1/templates/{code}
+// Returns a template with its body
+func (h *ContractGenerationHandler) GetTemplate(w http.ResponseWriter, r *http.Request) {
+	t, err := h.svc.GetTemplate(r.Context(), middleware.GetTenantID(r.Context()), r.PathValue("code"))
+	if err != nil {
+		h.writeTemplateError(w, err, "failed to get template")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, models.SuccessResponse(t.ToResponse()))
+}
+
+// CreateTemplate handles POST /api/v1/templates
+func (h *ContractGenerationHandler) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodySize)
+
+	var req models.CreateTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeDecodeError(w, err, models.ErrCodeInvalidRequest, MsgInvalidRequestBody)
+		return
+	}
+	if problems := req.Validate(); len(problems) > 0 {
+		writeValidationError(w, problems...)
+		return
+	}
+
+	t, err := h.svc.CreateTemplate(r.Context(), middleware.GetTenantID(r.Context()), &req, middleware.GetUser(r.Context()))
+	if err != nil {
+		h.writeTemplateError(w, err, "failed to create template")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, models.SuccessResponse(t.ToResponse()))
+}
+
+// UpdateTemplate handles PUT /api/v1/templates/{code}
+// Setting active to false retires the template; generation no longer uses it
+func (h *ContractGenerationHandler) UpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodySize)
+
+	var req models.UpdateTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeDecodeError(w, err, models.ErrCodeInvalidRequest, MsgInvalidRequestBody)
+		return
+	}
+	if problems := req.Validate(); len(problems) > 0 {
+		writeValidationError(w, problems...)
+		return
+	}
+
+	t, err := h.svc.UpdateTemplate(r.Context(), middleware.GetTenantID(r.Context()), r.PathValue("code"), &req, middleware.GetUser(r.Context()))
+	if err != nil {
+		h.writeTemplateError(w, err, "failed to update template")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, models.SuccessResponse(t.ToResponse()))
+}
+
+// DeleteTemplate handles DELETE /api/v1/templates/{code}
+func (h *ContractGenerationHandler) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	if err := h.svc.DeleteTemplate(r.Context(), middleware.GetTenantID(r.Context()), r.PathValue("code")); err != nil {
+		h.writeTemplateError(w, err, "failed to delete template")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, models.SuccessResponse(nil))
+}
+
+// writeTemplateError maps template service errors to responses; a rejected
+// body is reported as a body_html validation error with its line
+func (h *ContractGenerationHandler) writeTemplateError(w http.ResponseWriter, err error, logMsg string) {
+	var bodyErr *doctemplate.Error
+	switch {
+	case errors.As(err, &bodyErr):
+		writeValidationError(w, models.FieldError{Field: "body_html", Rule: "template", Message: bodyErr.Error(), Line: bodyErr.Line})
+	case errors.Is(err, service.ErrTemplateNotFound):
+		writeError(w, http.StatusNotFound, models.ErrCodeNotFound, MsgTemplateNotFound)
+	case errors.Is(err, service.ErrDuplicateTemplate):
+		writeError(w, http.StatusConflict, models.ErrCodeConflict, MsgDuplicateTemplate)
+	case errors.Is(err, service.ErrTemplateInUse), errors.Is(err, service.ErrDefaultTemplate):
+		writeError(w, http.StatusConflict, models.ErrCodeConflict, err.Error())
+	default:
+		log.Printf("%s: %v", logMsg, err)
+		writeServerError(w, err, MsgInternalServerError)
+	}
+}
+
+// PreviewTemplate handles POST /api/v1/templates/{code}/preview
+// Renders the template with {"contract_id": n}'s data, or with sample data
+// when the body is empty or omits it. Nothing is generated or logged.
+func (h *ContractGenerationHandler) PreviewTemplate(w http.ResponseWriter, r *http.Request) {
+	var req models.TemplatePreviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeDecodeError(w, err, models.ErrCodeInvalidJSON, MsgInvalidRequestBody)
+		return
+	}
+	if req.ContractID < 0 {
+		writeValidationError(w, models.FieldError{Field: "contract_id", Rule: "gt", Message: "contract_id must be a positive ID"})
+		return
+	}
+
+	preview, err := h.svc.PreviewTemplate(r.Context(), middleware.GetTenantID(r.Context()), r.PathValue("code"), req.ContractID)
+	if err != nil {
+		var renderErr *doctemplate.Error
+		switch {
+		case errors.As(err, &renderErr):
+			field := renderErr.Field
+			if field == "" {
+				field = "body_html"
+			}
+			writeErrorDetails(w, http.StatusUnprocessableEntity, models.ErrCodeTemplateError, MsgTemplateRenderFailed,
+				[]models.FieldError{{Field: field, Rule: "template", Message: renderErr.Error(), Line: renderErr.Line}})
+		case errors.Is(err, service.ErrContractNotFound):
+			writeError(w, http.StatusNotFound, models.ErrCodeNotFound, MsgContractNotFound)
+		default:
+			h.writeTemplateError(w, err, "failed to preview template")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, models.SuccessResponse(preview))
+}
+
+// DiffGenerated handles GET /api/v1/contracts/{id}/generated/diff?from={gen_id}&to={gen_id}
+// Compares two generated versions of the contract; format=html returns a
+// side-by-side page instead of JSON
+func (h *ContractGenerationHandler) DiffGenerated(w http.ResponseWriter, r *http.Request) {
+	contractID, err := parseIDFromPath(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidID, MsgInvalidContractID)
+		return
+	}
+	q := r.URL.Query()
+	fromID, errFrom := strconv.ParseInt(q.Get("from"), 10, 64)
+	toID, errTo := strconv.ParseInt(q.Get("to"), 10, 64)
+	if errFrom != nil || errTo != nil || fromID <= 0 || toID <= 0 {
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidID, MsgInvalidDiffRange)
+		return
+	}
+	format := q.Get("format")
+	if format != "" && format != "json" && format != "html" {
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidRequest, MsgInvalidDiffFormat)
+		return
+	}
+
+	diff, err := h.svc.DiffGenerated(r.Context(), middleware.GetTenantID(r.Context()), contractID, fromID, toID,
+		middleware.GetUser(r.Context()), getClientIP(r), getSessionID(r))
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			writeError(w, http.StatusNotFound, models.ErrCodeNotFound, MsgGeneratedNotFound)
+			return
+		}
+		log.Printf("failed to diff generated contracts: %v", err)
+		writeServerError(w, err, MsgInternalServerError)
+		return
+	}
+
+	if format == "html" {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		writeDiffHTML(w, diff)
+		return
+	}
+	writeJSON(w, http.StatusOK, models.SuccessResponse(diff))
+}
+
+// renderFormats maps the format query values of RenderGenerated to output formats
+var renderFormats = map[string]models.PrintFormat{
+	"":     models.PrintFormatHTML,
+	"html": models.PrintFormatHTML,
+	"pdf":  models.PrintFormatPDF,
+}
+
+// RenderGenerated handles GET /api/v1/contracts/{id}/generated/{gen_id}/render?format=html|pdf
+// Renders a generated version from its snapshot. HTML is shown inline and
+// PDF sent as a download; content failing its hash check gets a 409.
+func (h *ContractGenerationHandler) RenderGenerated(w http.ResponseWriter, r *http.Request) {
+	contractID, err := parseIDFromPath(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidID, MsgInvalidContractID)
+		return
+	}
+	generatedID, err := parseIDFromPath(r, "gen_id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidID, MsgInvalidGeneratedID)
+		return
+	}
+	format, ok := renderFormats[strings.ToLower(r.URL.Query().Get("format"))]
+	if !ok {
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidRequest, MsgInvalidRenderFormat)
+		return
+	}
+
+	started := false
+	err = h.svc.RenderGenerated(r.Context(), middleware.GetTenantID(r.Context()), contractID, generatedID, format,
+		middleware.GetUser(r.Context()), getClientIP(r), getSessionID(r),
+		func(doc *models.RenderedContract) io.Writer {
+			started = true
+			w.Header().Set("Content-Type", doc.ContentType)
+			if format != models.PrintFormatHTML {
+				w.Header().Set("Content-Disposition", attachmentDisposition(
+					fmt.Sprintf("contract_%d_v%d.%s", doc.ContractID, doc.GenerationNumber, strings.ToLower(string(format)))))
+			}
+			w.WriteHeader(http.StatusOK)
+			return w
+		})
+	if started {
+		// The status is sent; a failure can only cut the document short
+		if err != nil {
+			log.Printf("failed to stream rendered contract (generated_id=%d): %v", generatedID, err)
+			panic(http.ErrAbortHandler)
+		}
+		return
+	}
+
+	var renderErr *doctemplate.Error
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		writeError(w, http.StatusNotFound, models.ErrCodeNotFound, MsgGeneratedNotFound)
+	case errors.Is(err, service.ErrUnauthorized):
+		writeError(w, http.StatusForbidden, models.ErrCodeUnauthorized, "Access denied to this generated contract")
+	case errors.Is(err, service.ErrContentTampered):
+		writeError(w, http.StatusConflict, models.ErrCodeConflict, MsgContentTampered)
+	case errors.Is(err, service.ErrFormatNotSupported):
+		writeError(w, http.StatusNotImplemented, models.ErrCodeNotImplemented, MsgFormatNotSupported)
+	case errors.As(err, &renderErr):
+		writeErrorDetails(w, http.StatusUnprocessableEntity, models.ErrCodeTemplateError, MsgTemplateRenderFailed,
+			[]models.FieldError{{Field: "body_html", Rule: "template", Message: renderErr.Error(), Line: renderErr.Line}})
+	default:
+		log.Printf("failed to render generated contract: %v", err)
+		writeServerError(w, err, MsgInternalServerError)
+	}
+}
+
+// writeDiffHTML renders a diff as a page with the older version on the left
+// and the newer on the right
+func writeDiffHTML(w io.Writer, diff *models.GenerationDiff) {
+	cell := func(v any) string {
+		if v == nil {
+			return "&mdash;"
+		}
+		if s, ok := v.(string); ok {
+			return html.EscapeString(s)
+		}
+		b, _ := json.Marshal(v)
+		return html.EscapeString(string(b))
+	}
+	row := func(label string, before, after any) {
+		fmt.Fprintf(w, "<tr><th>%s</th><td class=\"old\">%s</td><td class=\"new\">%s</td></tr>\n",
+			html.EscapeString(label), cell(before), cell(after))
+	}
+	itemLabel := func(item models.ItemChange) string {
+		if item.ServiceName != "" {
+			return item.ServiceName + " (" + item.Key + ")"
+		}
+		return item.Key
+	}
+
+	fmt.Fprintf(w, `<!DOCTYPE html>
+<html>
+<head><meta charset="UTF-8"><title>Contract %d: generation %d vs %d</title>
+<style>
+    body { font-family: Arial, sans-serif; margin: 40px; }
+    table { width: 100%%; border-collapse: collapse; }
+    th, td { border: 1px solid #ddd; padding: 6px 10px; text-align: left; vertical-align: top; }
+    thead th { background-color: #f5f5f5; }
+    td.old { background-color: #fdecea; }
+    td.new { background-color: #e8f5e9; }
+</style>
+</head>
+<body>
+<h1>Contract %d</h1>
+<table>
+<thead><tr><th>Field</th><th>Generation %d (%s)</th><th>Generation %d (%s)</th></tr></thead>
+<tbody>
+`, diff.ContractID, diff.From.GenerationNumber, diff.To.GenerationNumber, diff.ContractID,
+		diff.From.GenerationNumber, diff.From.GeneratedAt.Format("2006-01-02 15:04"),
+		diff.To.GenerationNumber, diff.To.GeneratedAt.Format("2006-01-02 15:04"))
+	for _, c := range diff.Changes {
+		row(c.Path, c.Old, c.New)
+	}
+	for _, item := range diff.ChangedItems {
+		for _, c := range item.Changes {
+			row(itemLabel(item)+": "+c.Path, c.Old, c.New)
+		}
+	}
+	for _, item := range diff.AddedItems {
+		row(itemLabel(item), nil, "added")
+	}
+	for _, item := range diff.RemovedItems {
+		row(itemLabel(item), "removed", nil)
+	}
+	if len(diff.Changes)+len(diff.ChangedItems)+len(diff.AddedItems)+len(diff.RemovedItems) == 0 {
+		fmt.Fprint(w, "<tr><td colspan=\"3\">No differences.</td></tr>\n")
+	}
+	fmt.Fprint(w, "</tbody>\n</table>\n</body>\n</html>\n")
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/zlovtnik/gprint/internal/models"
+	"github.com/zlovtnik/gprint/internal/repository"
+	"github.com/zlovtnik/gprint/internal/service"
+	"github.com/zlovtnik/gprint/pkg/requestctx"
+)
+
+// newMockGenerationHandler builds a ContractGenerationHandler over a mocked database
+func newMockGenerationHandler(t *testing.T) (*ContractGenerationHandler, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	repo := repository.NewContractGenerationRepository(repository.NewDB(db, repository.DBConfig{}, slog.New(slog.NewTextHandler(io.Discard, nil))))
+	return NewContractGenerationHandler(service.NewContractGenerationService(repo, nil, nil, nil, nil)), mock
+}
+
+// verifyResult matches the result out bind of the integrity check and sets
+// it to the given verify_integrity result
+type verifyResult int
+
+func (v verifyResult) Match(value driver.Value) bool {
+	out, ok := value.(sql.Out)
+	if !ok {
+		return false
+	}
+	dest, ok := out.Dest.(*int)
+	if ok {
+		*dest = int(v)
+	}
+	return ok
+}
+
+// expectIntegrityCheck expects version 9 of contract 5 to be checked and
+// found intact
+func expectIntegrityCheck(mock sqlmock.Sqlmock) {
+	mock.ExpectExec("verify_integrity").WithArgs(int64(9), "t1", verifyResult(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("FROM generated_contracts").WithArgs("t1", int64(5), int64(9)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "generation_number", "content_hash", "generated_at"}).
+			AddRow(int64(9), 2, "9f86d081884c7d65", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)))
+}
+
+// verifyRequest builds a request for the verify route of version 9 of
+// contract 5, logging to logs
+func verifyRequest(method string, logs io.Writer) *http.Request {
+	req := httptest.NewRequest(method, "/api/v1/contracts/5/generated/9/verify", nil)
+	req.SetPathValue("id", "5")
+	req.SetPathValue("gen_id", "9")
+	ctx := requestctx.WithTenantID(req.Context(), "t1")
+	ctx = requestctx.WithUser(ctx, "alice")
+	ctx = requestctx.WithLogger(ctx, slog.New(slog.NewTextHandler(logs, nil)))
+	return req.WithContext(ctx)
+}
+
+func checkIntegrityResponse(t *testing.T, rec *httptest.ResponseRecorder) {
+	t.Helper()
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	var resp struct {
+		Data models.IntegrityCheck `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Data.Valid || resp.Data.ContentHash != "9f86d081884c7d65" || resp.Data.GeneratedID != 9 {
+		t.Errorf("check = %+v, want version 9 valid with its hash", resp.Data)
+	}
+}
+
+func TestCheckIntegrityRecordsNothing(t *testing.T) {
+	h, mock := newMockGenerationHandler(t)
+	expectIntegrityCheck(mock)
+
+	// Logging the action would be an unexpected statement, which fails
+	// and is reported as a warning
+	var logs bytes.Buffer
+	rec := httptest.NewRecorder()
+	h.CheckIntegrity(rec, verifyRequest(http.MethodGet, &logs))
+
+	checkIntegrityResponse(t, rec)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+	if logs.Len() > 0 {
+		t.Errorf("GET tried to record the check: %s", logs.String())
+	}
+}
+
+func TestVerifyIntegrityRecordsCheck(t *testing.T) {
+	h, mock := newMockGenerationHandler(t)
+	expectIntegrityCheck(mock)
+	mock.ExpectExec("log_action").
+		WithArgs("t1", int64(5), int64(9), "VERIFY", "alice", sqlmock.AnyArg(), sqlmock.AnyArg(), "SUCCESS", nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	var logs bytes.Buffer
+	rec := httptest.NewRecorder()
+	h.VerifyIntegrity(rec, verifyRequest(http.MethodPost, &logs))
+
+	checkIntegrityResponse(t, rec)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+	if logs.Len() > 0 {
+		t.Errorf("unexpected warnings: %s", logs.String())
+	}
+}
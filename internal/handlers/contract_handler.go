@@ -3,9 +3,11 @@ package handlers
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
 	"net"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/zlovtnik/gprint/internal/middleware"
@@ -16,16 +18,6 @@ import (
 // maxRequestBodySize limits the size of request bodies (1MB)
 const maxRequestBodySize = 1 << 20 // 1MB
 
-// ValidContractStatuses contains all valid contract status values
-var ValidContractStatuses = map[models.ContractStatus]bool{
-	models.ContractStatusDraft:     true,
-	models.ContractStatusPending:   true,
-	models.ContractStatusActive:    true,
-	models.ContractStatusSuspended: true,
-	models.ContractStatusCancelled: true,
-	models.ContractStatusCompleted: true,
-}
-
 // ContractHandler handles contract HTTP requests
 type ContractHandler struct {
 	svc *service.ContractService
@@ -37,15 +29,36 @@ func NewContractHandler(svc *service.ContractService) *ContractHandler {
 }
 
 // List handles GET /api/v1/contracts
+// Passing cursor switches from page/page_size to keyset pagination; fields
+// narrows each contract to the listed fields.
 func (h *ContractHandler) List(w http.ResponseWriter, r *http.Request) {
 	tenantID := middleware.GetTenantID(r.Context())
-	params := parsePagination(r)
 	search := parseSearchParams(r)
+	if len(search.Tags) > models.MaxContractTags {
+		writeError(w, http.StatusBadRequest, models.ErrCodeValidationErr, MsgTooManyTagFilters)
+		return
+	}
+
+	fields, err := models.ContractResponseFields.Parse(r.URL.Query().Get("fields"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, models.ErrCodeValidationErr, err.Error())
+		return
+	}
+	keyset, ok, err := parseKeyset(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, models.ErrCodeValidationErr, err.Error())
+		return
+	}
+	if ok {
+		h.listKeyset(w, r, keyset, search, fields)
+		return
+	}
+	params := parsePagination(r)
 
 	contracts, total, err := h.svc.List(r.Context(), tenantID, params, search)
 	if err != nil {
 		log.Printf("failed to list contracts: %v", err)
-		writeError(w, http.StatusInternalServerError, ErrCodeInternalError, MsgInternalServerError)
+		writeServerError(w, err, MsgInternalServerError)
 		return
 	}
 
@@ -54,8 +67,54 @@ func (h *ContractHandler) List(w http.ResponseWriter, r *http.Request) {
 		responses[i] = c.ToResponse()
 	}
 
-	result := models.NewPaginatedResponse(responses, params.Page, params.PageSize, total)
-	writeJSON(w, http.StatusOK, models.SuccessResponse(result))
+	writePage(w, responses, fields, models.ContractResponseFields, params, total)
+}
+
+// listKeyset writes a keyset page of contracts, newest first. The order is
+// fixed, so sort_by and sort_dir are rejected.
+func (h *ContractHandler) listKeyset(w http.ResponseWriter, r *http.Request, params models.KeysetParams, search models.SearchParams, fields []string) {
+	if r.URL.Query().Has("sort_by") || r.URL.Query().Has("sort_dir") {
+		writeError(w, http.StatusBadRequest, models.ErrCodeValidationErr, MsgCursorWithSort)
+		return
+	}
+
+	contracts, next, err := h.svc.ListKeyset(r.Context(), middleware.GetTenantID(r.Context()), params, search)
+	if err != nil {
+		log.Printf("failed to list contracts: %v", err)
+		writeServerError(w, err, MsgInternalServerError)
+		return
+	}
+
+	responses := make([]models.ContractResponse, len(contracts))
+	for i, c := range contracts {
+		responses[i] = c.ToResponse()
+	}
+
+	writeCursorPage(w, responses, fields, models.ContractResponseFields, params.PageSize, next)
+}
+
+// ListTags handles GET /api/v1/tags, listing the tags in use, optionally
+// those starting with ?q=, for autocomplete
+func (h *ContractHandler) ListTags(w http.ResponseWriter, r *http.Request) {
+	limit := service.DefaultTagLimit
+	if l := r.URL.Query().Get("limit"); l != "" {
+		parsed, err := strconv.Atoi(l)
+		if err != nil || parsed < 1 || parsed > service.MaxTagLimit {
+			writeError(w, http.StatusBadRequest, models.ErrCodeValidationErr,
+				fmt.Sprintf("limit must be between 1 and %d", service.MaxTagLimit))
+			return
+		}
+		limit = parsed
+	}
+
+	tags, err := h.svc.ListTags(r.Context(), middleware.GetTenantID(r.Context()), r.URL.Query().Get("q"), limit)
+	if err != nil {
+		log.Printf("failed to list tags: %v", err)
+		writeServerError(w, err, MsgInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, models.SuccessResponse(tags))
 }
 
 // Get handles GET /api/v1/contracts/{id}
@@ -63,18 +122,18 @@ func (h *ContractHandler) Get(w http.ResponseWriter, r *http.Request) {
 	tenantID := middleware.GetTenantID(r.Context())
 	id, err := parseIDFromPath(r, "id")
 	if err != nil {
-		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, MsgInvalidContractID)
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidID, MsgInvalidContractID)
 		return
 	}
 
 	contract, err := h.svc.GetByID(r.Context(), tenantID, id)
 	if err != nil {
 		log.Printf("failed to get contract: %v", err)
-		writeError(w, http.StatusInternalServerError, ErrCodeInternalError, MsgInternalServerError)
+		writeServerError(w, err, MsgInternalServerError)
 		return
 	}
 	if contract == nil {
-		writeError(w, http.StatusNotFound, ErrCodeNotFound, MsgContractNotFound)
+		writeError(w, http.StatusNotFound, models.ErrCodeNotFound, MsgContractNotFound)
 		return
 	}
 
@@ -91,19 +150,19 @@ func (h *ContractHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 	var req models.CreateContractRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, MsgInvalidRequestBody)
+		writeDecodeError(w, err, models.ErrCodeInvalidRequest, MsgInvalidRequestBody)
 		return
 	}
 
-	if req.ContractNumber == "" || req.CustomerID == 0 {
-		writeError(w, http.StatusBadRequest, ErrCodeValidationErr, "contract_number and customer_id are required")
+	if problems := req.Validate(); len(problems) > 0 {
+		writeValidationError(w, problems...)
 		return
 	}
 
 	contract, err := h.svc.Create(r.Context(), tenantID, &req, user)
 	if err != nil {
 		log.Printf("failed to create contract: %v", err)
-		writeError(w, http.StatusInternalServerError, ErrCodeInternalError, MsgInternalServerError)
+		writeServerError(w, err, MsgInternalServerError)
 		return
 	}
 
@@ -116,7 +175,7 @@ func (h *ContractHandler) Update(w http.ResponseWriter, r *http.Request) {
 	user := middleware.GetUser(r.Context())
 	id, err := parseIDFromPath(r, "id")
 	if err != nil {
-		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, MsgInvalidContractID)
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidID, MsgInvalidContractID)
 		return
 	}
 
@@ -125,22 +184,26 @@ func (h *ContractHandler) Update(w http.ResponseWriter, r *http.Request) {
 
 	var req models.UpdateContractRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, MsgInvalidRequestBody)
+		writeDecodeError(w, err, models.ErrCodeInvalidRequest, MsgInvalidRequestBody)
+		return
+	}
+	if problems := req.Validate(); len(problems) > 0 {
+		writeValidationError(w, problems...)
 		return
 	}
 
 	contract, err := h.svc.Update(r.Context(), tenantID, id, &req, user)
 	if err != nil {
 		if errors.Is(err, service.ErrContractNotFound) {
-			writeError(w, http.StatusNotFound, ErrCodeNotFound, MsgContractNotFound)
+			writeError(w, http.StatusNotFound, models.ErrCodeNotFound, MsgContractNotFound)
 			return
 		}
 		if errors.Is(err, service.ErrContractCannotUpdate) {
-			writeError(w, http.StatusConflict, "CONFLICT", "contract cannot be updated in current status")
+			writeError(w, http.StatusConflict, models.ErrCodeConflict, "contract cannot be updated in current status")
 			return
 		}
 		log.Printf("failed to update contract: %v", err)
-		writeError(w, http.StatusInternalServerError, ErrCodeInternalError, MsgInternalServerError)
+		writeServerError(w, err, MsgInternalServerError)
 		return
 	}
 
@@ -153,7 +216,7 @@ func (h *ContractHandler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
 	user := middleware.GetUser(r.Context())
 	id, err := parseIDFromPath(r, "id")
 	if err != nil {
-		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, MsgInvalidContractID)
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidID, MsgInvalidContractID)
 		return
 	}
 
@@ -162,28 +225,27 @@ func (h *ContractHandler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
 
 	var req models.UpdateContractStatusRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, MsgInvalidRequestBody)
+		writeDecodeError(w, err, models.ErrCodeInvalidRequest, MsgInvalidRequestBody)
 		return
 	}
 
-	// Validate that status is non-empty and matches allowed contract statuses
-	if req.Status == "" || !ValidContractStatuses[req.Status] {
-		writeError(w, http.StatusBadRequest, "INVALID_STATUS", "invalid or missing status")
+	if problems := req.Validate(); len(problems) > 0 {
+		writeValidationError(w, problems...)
 		return
 	}
 
 	ipAddress := getClientIP(r)
 	if err := h.svc.UpdateStatus(r.Context(), tenantID, id, req.Status, user, ipAddress); err != nil {
 		if errors.Is(err, service.ErrInvalidStatusTransition) {
-			writeError(w, http.StatusConflict, "INVALID_TRANSITION", "invalid status transition")
+			writeError(w, http.StatusConflict, models.ErrCodeInvalidTransition, "invalid status transition")
 			return
 		}
 		if errors.Is(err, service.ErrContractNotFound) {
-			writeError(w, http.StatusNotFound, ErrCodeNotFound, MsgContractNotFound)
+			writeError(w, http.StatusNotFound, models.ErrCodeNotFound, MsgContractNotFound)
 			return
 		}
 		log.Printf("failed to update contract status: %v", err)
-		writeError(w, http.StatusInternalServerError, ErrCodeInternalError, MsgInternalServerError)
+		writeServerError(w, err, MsgInternalServerError)
 		return
 	}
 
@@ -195,7 +257,7 @@ func (h *ContractHandler) Sign(w http.ResponseWriter, r *http.Request) {
 	tenantID := middleware.GetTenantID(r.Context())
 	id, err := parseIDFromPath(r, "id")
 	if err != nil {
-		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, MsgInvalidContractID)
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidID, MsgInvalidContractID)
 		return
 	}
 
@@ -204,7 +266,7 @@ func (h *ContractHandler) Sign(w http.ResponseWriter, r *http.Request) {
 
 	var req models.SignContractRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, MsgInvalidRequestBody)
+		writeDecodeError(w, err, models.ErrCodeInvalidRequest, MsgInvalidRequestBody)
 		return
 	}
 
@@ -215,15 +277,15 @@ func (h *ContractHandler) Sign(w http.ResponseWriter, r *http.Request) {
 	ipAddress := getClientIP(r)
 	if err := h.svc.Sign(r.Context(), tenantID, id, req.SignedBy, ipAddress); err != nil {
 		if errors.Is(err, service.ErrCannotSign) {
-			writeError(w, http.StatusConflict, "INVALID_STATUS", "contract cannot be signed in current status")
+			writeError(w, http.StatusConflict, models.ErrCodeInvalidStatus, "contract cannot be signed in current status")
 			return
 		}
 		if errors.Is(err, service.ErrContractNotFound) {
-			writeError(w, http.StatusNotFound, ErrCodeNotFound, MsgContractNotFound)
+			writeError(w, http.StatusNotFound, models.ErrCodeNotFound, MsgContractNotFound)
 			return
 		}
 		log.Printf("failed to sign contract: %v", err)
-		writeError(w, http.StatusInternalServerError, ErrCodeInternalError, MsgInternalServerError)
+		writeServerError(w, err, MsgInternalServerError)
 		return
 	}
 
@@ -235,7 +297,7 @@ func (h *ContractHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
 	tenantID := middleware.GetTenantID(r.Context())
 	id, err := parseIDFromPath(r, "id")
 	if err != nil {
-		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, MsgInvalidContractID)
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidID, MsgInvalidContractID)
 		return
 	}
 
@@ -243,7 +305,7 @@ func (h *ContractHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
 	history, total, err := h.svc.GetHistory(r.Context(), tenantID, id, params)
 	if err != nil {
 		log.Printf("failed to get contract history: %v", err)
-		writeError(w, http.StatusInternalServerError, ErrCodeInternalError, MsgInternalServerError)
+		writeServerError(w, err, MsgInternalServerError)
 		return
 	}
 
@@ -256,13 +318,66 @@ func (h *ContractHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, models.SuccessResponse(result))
 }
 
+// BillingSchedule handles GET /api/v1/contracts/{id}/billing-schedule
+func (h *ContractHandler) BillingSchedule(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	id, err := parseIDFromPath(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidID, MsgInvalidContractID)
+		return
+	}
+
+	schedule, err := h.svc.BillingSchedule(r.Context(), tenantID, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrContractNotFound):
+			writeError(w, http.StatusNotFound, models.ErrCodeNotFound, MsgContractNotFound)
+		case errors.Is(err, service.ErrCannotBill):
+			writeError(w, http.StatusConflict, models.ErrCodeConflict, err.Error())
+		default:
+			log.Printf("failed to compute billing schedule: %v", err)
+			writeServerError(w, err, MsgInternalServerError)
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, models.SuccessResponse(schedule))
+}
+
+// ListItems handles GET /api/v1/contracts/{id}/items
+func (h *ContractHandler) ListItems(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	id, err := parseIDFromPath(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidID, MsgInvalidContractID)
+		return
+	}
+
+	items, err := h.svc.ListItems(r.Context(), tenantID, id)
+	if err != nil {
+		if errors.Is(err, service.ErrContractNotFound) {
+			writeError(w, http.StatusNotFound, models.ErrCodeNotFound, MsgContractNotFound)
+			return
+		}
+		log.Printf("failed to list contract items: %v", err)
+		writeServerError(w, err, MsgInternalServerError)
+		return
+	}
+
+	responses := make([]models.ContractItemResponse, len(items))
+	for i, item := range items {
+		responses[i] = item.ToResponse()
+	}
+	writeJSON(w, http.StatusOK, models.SuccessResponse(responses))
+}
+
 // AddItem handles POST /api/v1/contracts/{id}/items
 func (h *ContractHandler) AddItem(w http.ResponseWriter, r *http.Request) {
 	tenantID := middleware.GetTenantID(r.Context())
 	user := middleware.GetUser(r.Context())
 	contractID, err := parseIDFromPath(r, "id")
 	if err != nil {
-		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, MsgInvalidContractID)
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidID, MsgInvalidContractID)
 		return
 	}
 
@@ -271,27 +386,27 @@ func (h *ContractHandler) AddItem(w http.ResponseWriter, r *http.Request) {
 
 	var req models.CreateContractItemRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, MsgInvalidRequestBody)
+		writeDecodeError(w, err, models.ErrCodeInvalidRequest, MsgInvalidRequestBody)
 		return
 	}
 
-	if req.ServiceID == 0 {
-		writeError(w, http.StatusBadRequest, ErrCodeValidationErr, "service_id is required")
+	if problems := req.Validate(); len(problems) > 0 {
+		writeValidationError(w, problems...)
 		return
 	}
 
 	item, err := h.svc.AddItem(r.Context(), tenantID, contractID, &req, user)
 	if err != nil {
 		if errors.Is(err, service.ErrCannotAddItem) {
-			writeError(w, http.StatusConflict, "INVALID_STATUS", "cannot add items to contract in current status")
+			writeError(w, http.StatusConflict, models.ErrCodeInvalidStatus, "cannot add items to contract in current status")
 			return
 		}
 		if errors.Is(err, service.ErrContractNotFound) {
-			writeError(w, http.StatusNotFound, ErrCodeNotFound, MsgContractNotFound)
+			writeError(w, http.StatusNotFound, models.ErrCodeNotFound, MsgContractNotFound)
 			return
 		}
 		log.Printf("failed to add item to contract: %v", err)
-		writeError(w, http.StatusInternalServerError, ErrCodeInternalError, MsgInternalServerError)
+		writeServerError(w, err, MsgInternalServerError)
 		return
 	}
 
@@ -304,26 +419,26 @@ func (h *ContractHandler) DeleteItem(w http.ResponseWriter, r *http.Request) {
 	user := middleware.GetUser(r.Context())
 	contractID, err := parseIDFromPath(r, "id")
 	if err != nil {
-		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, MsgInvalidContractID)
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidID, MsgInvalidContractID)
 		return
 	}
 	itemID, err := parseIDFromPath(r, "itemId")
 	if err != nil {
-		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, MsgInvalidContractID)
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidID, MsgInvalidContractID)
 		return
 	}
 
 	if err := h.svc.DeleteItem(r.Context(), tenantID, contractID, itemID, user); err != nil {
 		if errors.Is(err, service.ErrCannotDeleteItem) {
-			writeError(w, http.StatusConflict, "INVALID_STATUS", "cannot delete items from contract in current status")
+			writeError(w, http.StatusConflict, models.ErrCodeInvalidStatus, "cannot delete items from contract in current status")
 			return
 		}
 		if errors.Is(err, service.ErrContractNotFound) {
-			writeError(w, http.StatusNotFound, ErrCodeNotFound, MsgContractNotFound)
+			writeError(w, http.StatusNotFound, models.ErrCodeNotFound, MsgContractNotFound)
 			return
 		}
 		log.Printf("failed to delete item from contract: %v", err)
-		writeError(w, http.StatusInternalServerError, ErrCodeInternalError, MsgInternalServerError)
+		writeServerError(w, err, MsgInternalServerError)
 		return
 	}
 
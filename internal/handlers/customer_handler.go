@@ -3,9 +3,10 @@ package handlers
 import (
 	"encoding/json"
 	"errors"
+	"io"
 	"log"
 	"net/http"
-	"strings"
+	"strconv"
 
 	"github.com/zlovtnik/gprint/internal/middleware"
 	"github.com/zlovtnik/gprint/internal/models"
@@ -27,15 +28,22 @@ func NewCustomerHandler(svc *service.CustomerService) *CustomerHandler {
 }
 
 // List handles GET /api/v1/customers
+// fields narrows each customer to the listed fields.
 func (h *CustomerHandler) List(w http.ResponseWriter, r *http.Request) {
 	tenantID := middleware.GetTenantID(r.Context())
 	params := parsePagination(r)
 	search := parseSearchParams(r)
 
+	fields, err := models.CustomerResponseFields.Parse(r.URL.Query().Get("fields"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, models.ErrCodeValidationErr, err.Error())
+		return
+	}
+
 	customers, total, err := h.svc.List(r.Context(), tenantID, params, search)
 	if err != nil {
 		log.Printf("failed to list customers: %v", err)
-		writeError(w, http.StatusInternalServerError, ErrCodeInternalError, MsgInternalServerError)
+		writeServerError(w, err, MsgInternalServerError)
 		return
 	}
 
@@ -44,8 +52,7 @@ func (h *CustomerHandler) List(w http.ResponseWriter, r *http.Request) {
 		responses[i] = c.ToResponse()
 	}
 
-	result := models.NewPaginatedResponse(responses, params.Page, params.PageSize, total)
-	writeJSON(w, http.StatusOK, models.SuccessResponse(result))
+	writePage(w, responses, fields, models.CustomerResponseFields, params, total)
 }
 
 // Get handles GET /api/v1/customers/{id}
@@ -53,18 +60,18 @@ func (h *CustomerHandler) Get(w http.ResponseWriter, r *http.Request) {
 	tenantID := middleware.GetTenantID(r.Context())
 	id, err := parseIDFromPath(r, "id")
 	if err != nil {
-		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, MsgInvalidCustomerID)
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidID, MsgInvalidCustomerID)
 		return
 	}
 
 	customer, err := h.svc.GetByID(r.Context(), tenantID, id)
 	if err != nil {
 		if errors.Is(err, service.ErrCustomerNotFound) {
-			writeError(w, http.StatusNotFound, ErrCodeNotFound, MsgCustomerNotFound)
+			writeError(w, http.StatusNotFound, models.ErrCodeNotFound, MsgCustomerNotFound)
 			return
 		}
 		log.Printf("failed to retrieve customer (id=%d): %v", id, err)
-		writeError(w, http.StatusInternalServerError, ErrCodeInternalError, MsgFailedToRetrieveCustomer)
+		writeServerError(w, err, MsgFailedToRetrieveCustomer)
 		return
 	}
 
@@ -78,23 +85,23 @@ func (h *CustomerHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 	var req models.CreateCustomerRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, MsgInvalidRequestBody)
+		writeDecodeError(w, err, models.ErrCodeInvalidRequest, MsgInvalidRequestBody)
 		return
 	}
 
-	if strings.TrimSpace(req.Name) == "" || strings.TrimSpace(req.CustomerCode) == "" {
-		writeError(w, http.StatusBadRequest, ErrCodeValidationErr, "name and customer_code are required and cannot be whitespace-only")
+	if problems := req.Validate(); len(problems) > 0 {
+		writeValidationError(w, problems...)
 		return
 	}
 
 	customer, err := h.svc.Create(r.Context(), tenantID, &req, user)
 	if err != nil {
 		if errors.Is(err, service.ErrDuplicateCustomer) {
-			writeError(w, http.StatusConflict, "CONFLICT", "customer with this code already exists")
+			writeError(w, http.StatusConflict, models.ErrCodeConflict, "customer with this code already exists")
 			return
 		}
 		log.Printf("failed to create customer: %v", err)
-		writeError(w, http.StatusInternalServerError, ErrCodeInternalError, MsgInternalServerError)
+		writeServerError(w, err, MsgInternalServerError)
 		return
 	}
 
@@ -107,24 +114,28 @@ func (h *CustomerHandler) Update(w http.ResponseWriter, r *http.Request) {
 	user := middleware.GetUser(r.Context())
 	id, err := parseIDFromPath(r, "id")
 	if err != nil {
-		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, MsgInvalidCustomerID)
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidID, MsgInvalidCustomerID)
 		return
 	}
 
 	var req models.UpdateCustomerRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, MsgInvalidRequestBody)
+		writeDecodeError(w, err, models.ErrCodeInvalidRequest, MsgInvalidRequestBody)
+		return
+	}
+	if problems := req.Validate(); len(problems) > 0 {
+		writeValidationError(w, problems...)
 		return
 	}
 
 	customer, err := h.svc.Update(r.Context(), tenantID, id, &req, user)
 	if err != nil {
+		if errors.Is(err, service.ErrCustomerNotFound) {
+			writeError(w, http.StatusNotFound, models.ErrCodeNotFound, MsgCustomerNotFound)
+			return
+		}
 		log.Printf("failed to update customer: %v", err)
-		writeError(w, http.StatusInternalServerError, ErrCodeInternalError, MsgInternalServerError)
-		return
-	}
-	if customer == nil {
-		writeError(w, http.StatusNotFound, ErrCodeNotFound, MsgCustomerNotFound)
+		writeServerError(w, err, MsgInternalServerError)
 		return
 	}
 
@@ -137,19 +148,77 @@ func (h *CustomerHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	user := middleware.GetUser(r.Context())
 	id, err := parseIDFromPath(r, "id")
 	if err != nil {
-		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, MsgInvalidCustomerID)
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidID, MsgInvalidCustomerID)
 		return
 	}
 
 	if err := h.svc.Delete(r.Context(), tenantID, id, user); err != nil {
 		if errors.Is(err, service.ErrCustomerNotFound) {
-			writeError(w, http.StatusNotFound, ErrCodeNotFound, MsgCustomerNotFound)
+			writeError(w, http.StatusNotFound, models.ErrCodeNotFound, MsgCustomerNotFound)
 			return
 		}
 		log.Printf("failed to delete customer: %v", err)
-		writeError(w, http.StatusInternalServerError, ErrCodeInternalError, MsgInternalServerError)
+		writeServerError(w, err, MsgInternalServerError)
 		return
 	}
 
 	writeJSON(w, http.StatusOK, models.SuccessResponse(nil))
 }
+
+// Import handles POST /api/v1/customers/import. The CSV arrives as the
+// "file" part of a multipart/form-data upload and is read as it streams in;
+// dry_run=true validates it without creating anything.
+func (h *CustomerHandler) Import(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	user := middleware.GetUser(r.Context())
+
+	dryRun := false
+	if v := r.URL.Query().Get("dry_run"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, models.ErrCodeInvalidRequest, "dry_run must be true or false")
+			return
+		}
+		dryRun = b
+	}
+
+	mr, err := r.MultipartReader()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidRequest, MsgMultipartRequired)
+		return
+	}
+	var file io.Reader
+	for file == nil {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			writeValidationError(w, requiredField("file"))
+			return
+		}
+		if err != nil {
+			writeDecodeError(w, err, models.ErrCodeInvalidRequest, MsgMultipartRequired)
+			return
+		}
+		if part.FormName() == "file" {
+			file = part
+		}
+	}
+
+	report, err := h.svc.Import(r.Context(), tenantID, file, dryRun, user)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.Is(err, service.ErrImportTooLarge):
+			writeError(w, http.StatusRequestEntityTooLarge, models.ErrCodeTooLarge, err.Error())
+		case errors.As(err, &tooLarge):
+			writeError(w, http.StatusRequestEntityTooLarge, models.ErrCodeTooLarge, MsgPayloadTooLarge)
+		case errors.Is(err, service.ErrInvalidImportFile):
+			writeError(w, http.StatusBadRequest, models.ErrCodeInvalidRequest, err.Error())
+		default:
+			log.Printf("failed to import customers: %v", err)
+			writeServerError(w, err, MsgInternalServerError)
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, models.SuccessResponse(report))
+}
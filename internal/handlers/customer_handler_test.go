@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/zlovtnik/gprint/internal/models"
+	"github.com/zlovtnik/gprint/internal/repository"
+	"github.com/zlovtnik/gprint/internal/service"
+	"github.com/zlovtnik/gprint/pkg/requestctx"
+)
+
+// newMockCustomerHandler builds a CustomerHandler over a mocked database
+func newMockCustomerHandler(t *testing.T) (*CustomerHandler, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	repo, err := repository.NewCustomerRepository(repository.NewDB(db, repository.DBConfig{}, slog.New(slog.NewTextHandler(io.Discard, nil))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewCustomerHandler(service.NewCustomerService(repo, service.CustomerImportConfig{})), mock
+}
+
+// crudOut matches an out bind of a sp_generic_* block and fills it as a
+// successful call affecting rows rows does
+type crudOut int64
+
+func (n crudOut) Match(v driver.Value) bool {
+	out, ok := v.(sql.Out)
+	if !ok {
+		return false
+	}
+	switch dest := out.Dest.(type) {
+	case *int64:
+		*dest = int64(n)
+	case *int:
+		*dest = 1
+	}
+	return true
+}
+
+// crudArgs matches n binds followed by the three out binds of a
+// sp_generic_* block affecting rows rows
+func crudArgs(n int, rows int64) []driver.Value {
+	args := make([]driver.Value, 0, n+3)
+	for range n {
+		args = append(args, sqlmock.AnyArg())
+	}
+	return append(args, crudOut(rows), crudOut(rows), crudOut(rows))
+}
+
+// customerRequest builds a request for customer 7 of tenant t1
+func customerRequest(method, body string) *http.Request {
+	req := httptest.NewRequest(method, "/api/v1/customers/7", strings.NewReader(body))
+	req.SetPathValue("id", "7")
+	ctx := requestctx.WithTenantID(req.Context(), "t1")
+	return req.WithContext(requestctx.WithUser(ctx, "alice"))
+}
+
+func TestCustomerHandlerUpdateErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		expect func(e *sqlmock.ExpectedExec)
+		status int
+		code   string
+	}{
+		{
+			"no row updated is 404",
+			// Four binds for NAME, then table, tenant, id and actor
+			func(e *sqlmock.ExpectedExec) {
+				e.WithArgs(crudArgs(8, 0)...).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			http.StatusNotFound, models.ErrCodeNotFound,
+		},
+		{
+			"driver error is 500",
+			func(e *sqlmock.ExpectedExec) {
+				e.WillReturnError(errors.New("ORA-03113: end-of-file on communication channel"))
+			},
+			http.StatusInternalServerError, models.ErrCodeInternalError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, mock := newMockCustomerHandler(t)
+			tt.expect(mock.ExpectExec(`sp_generic_update`))
+
+			rec := httptest.NewRecorder()
+			h.Update(rec, customerRequest(http.MethodPut, `{"name":"Alice Ltd"}`))
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+			if resp := decodeError(t, rec); resp.Code != tt.code {
+				t.Errorf("error code = %s, want %s", resp.Code, tt.code)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestCustomerHandlerDeleteNotFound(t *testing.T) {
+	tests := []struct {
+		name   string
+		expect func(mock sqlmock.Sqlmock)
+	}{
+		{
+			"no such customer",
+			func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM customers WHERE tenant_id = :1 AND id = :2`).WithArgs("t1", int64(7)).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			},
+		},
+		{
+			"deleted concurrently",
+			func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM customers WHERE tenant_id = :1 AND id = :2`).WithArgs("t1", int64(7)).
+					WillReturnRows(sqlmock.NewRows(customerColumns).AddRow(customerRow()...))
+				mock.ExpectExec(`sp_generic_delete`).WithArgs(crudArgs(5, 0)...).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, mock := newMockCustomerHandler(t)
+			tt.expect(mock)
+
+			rec := httptest.NewRecorder()
+			h.Delete(rec, customerRequest(http.MethodDelete, ""))
+
+			if rec.Code != http.StatusNotFound {
+				t.Fatalf("status = %d, want 404: %s", rec.Code, rec.Body)
+			}
+			if resp := decodeError(t, rec); resp.Code != models.ErrCodeNotFound {
+				t.Errorf("error code = %s, want %s", resp.Code, models.ErrCodeNotFound)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// customerColumns are the columns GetByID scans, in order
+var customerColumns = []string{
+	"id", "tenant_id", "customer_code", "customer_type", "name", "trade_name",
+	"tax_id", "state_reg", "municipal_reg", "email", "phone", "mobile",
+	"address_street", "address_number", "address_comp", "address_district",
+	"address_city", "address_state", "address_zip", "address_country",
+	"active", "notes", "created_at", "updated_at", "created_by", "updated_by",
+}
+
+// customerRow is customer 7 of tenant t1 with only the required columns set
+func customerRow() []driver.Value {
+	row := make([]driver.Value, len(customerColumns))
+	copy(row, []driver.Value{int64(7), "t1", "C7", "COMPANY", "Alice Ltd"})
+	row[20] = 1
+	return row
+}
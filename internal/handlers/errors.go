@@ -1,40 +1,93 @@
 package handlers
 
-// Error codes
-const (
-	ErrCodeInternalError  = "INTERNAL_ERROR"
-	ErrCodeInvalidID      = "INVALID_ID"
-	ErrCodeNotFound       = "NOT_FOUND"
-	ErrCodeUnauthorized   = "UNAUTHORIZED"
-	ErrCodeInvalidRequest = "INVALID_REQUEST"
-	ErrCodeInvalidJSON    = "INVALID_JSON"
-	ErrCodeValidationErr  = "VALIDATION_ERROR"
-	ErrCodeNotReady       = "NOT_READY"
-	ErrCodeFileNotFound   = "FILE_NOT_FOUND"
-)
+// Error codes are defined in models (models.ErrCode*) so middleware shares them
 
 // Error messages used in HTTP handlers
 const (
 	// Common error messages
 	MsgInternalServerError = "internal server error"
+	MsgInvalidMetricsToken = "missing or invalid metrics token"
+	MsgQueryTimeout        = "the database took too long to respond; please retry"
+	MsgPayloadTooLarge     = "request body too large"
 	MsgInvalidContractID   = "invalid contract id"
 	MsgContractNotFound    = "contract not found"
 	MsgInvalidRequestBody  = "invalid request body"
+	MsgValidationFailed    = "request validation failed"
+	MsgCursorWithPage      = "page and cursor cannot be combined"
+	MsgCursorWithSort      = "sort_by and sort_dir cannot be combined with cursor"
+	MsgInvalidCursor       = "invalid cursor; pass back next_cursor unchanged"
+	MsgTooManyTagFilters   = "at most 20 tag filters may be given"
 
 	// Contract generation messages
-	MsgInvalidGeneratedID  = "invalid generated contract id"
-	MsgGeneratedNotFound   = "generated contract not found"
-	MsgNoGeneratedContract = "no generated contract found"
+	MsgInvalidGeneratedID   = "invalid generated contract id"
+	MsgGeneratedNotFound    = "generated contract not found"
+	MsgNoGeneratedContract  = "no generated contract found"
+	MsgInvalidContentHash   = "invalid verification code"
+	MsgTemplateNotFound     = "template not found"
+	MsgDuplicateTemplate    = "template with this code already exists"
+	MsgTemplateRenderFailed = "template could not be rendered"
+	MsgInvalidDiffRange     = "from and to must be generated contract ids"
+	MsgInvalidDiffFormat    = "format must be json or html"
+	MsgInvalidRenderFormat  = "format must be html or pdf"
+	MsgContentTampered      = "generated contract content does not match its hash and will not be rendered"
+	MsgFormatNotSupported   = "this server cannot render the requested format"
+	MsgStaleGeneration      = "contract changed after its latest generation; print again with regenerate set to generate it first"
+	MsgGeneratedForbidden   = "access denied to this generated contract"
+	MsgInvalidSweepRange    = "after_id and max_records must not be negative"
 
 	// Customer specific messages
 	MsgInvalidCustomerID        = "invalid customer ID"
 	MsgFailedToRetrieveCustomer = "failed to retrieve customer"
 	MsgCustomerNotFound         = "customer not found"
+	MsgMultipartRequired        = "expected a multipart/form-data upload with a file part"
+
+	// Service specific messages
+	MsgServiceNotFound = "service not found"
 
 	// Print job specific messages
 	MsgInvalidPrintJobID   = "invalid print job ID"
 	MsgFailedToRetrieveJob = "failed to retrieve print job"
 	MsgPrintJobNotFound    = "print job not found"
 	MsgJobNotCompleted     = "job not completed"
+	MsgJobNotRetryable     = "only failed print jobs can be retried"
+	MsgJobNotCancellable   = "print job cannot be cancelled in current status"
+	MsgJobNotQueued        = "priority can only be changed while the print job is queued"
+	MsgInvalidPriority     = "priority must be one of LOW, NORMAL, HIGH"
+	MsgWatermarkRequired   = "only print administrators may disable the draft watermark"
 	MsgFileNotFound        = "file not found"
+	MsgOutputPurged        = "print output was removed by the retention policy; create a new print job to re-print"
+
+	// Webhook specific messages
+	MsgInvalidWebhookID     = "invalid webhook ID"
+	MsgWebhookNotFound      = "webhook not found"
+	MsgInvalidWebhookURL    = "url must be an absolute http or https URL"
+	MsgInvalidWebhookEvents = "event_types must list one or more of print_job.completed, print_job.failed, contract.signed"
+
+	// Contract attachment specific messages
+	MsgInvalidAttachmentID = "invalid attachment ID"
+	MsgAttachmentNotFound  = "attachment not found"
+	MsgAttachmentMissing   = "attachment file is missing from storage"
+	MsgOverrideRequired    = "deleting an attachment of a signed contract requires the contracts:override role"
+
+	// Invoice specific messages
+	MsgInvalidInvoiceID = "invalid invoice ID"
+	MsgInvoiceNotFound  = "invoice not found"
+
+	// Notification preference messages
+	MsgNotificationPrefNotFound = "no notification preference set"
+	MsgInvalidEmail             = "email must be a valid email address"
+
+	// Notification messages
+	MsgInvalidNotificationID = "invalid notification ID"
+	MsgNotificationNotFound  = "notification not found"
+
+	// Background job messages
+	MsgJobNotFound = "job not found"
+	MsgJobRunning  = "job is already running"
+
+	// Feature flag messages
+	MsgFeatureNotFound = "unknown feature flag"
+
+	// Search messages
+	MsgSearchQueryTooShort = "q must be at least 2 characters"
 )
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/zlovtnik/gprint/internal/middleware"
+	"github.com/zlovtnik/gprint/internal/models"
+	"github.com/zlovtnik/gprint/internal/service"
+)
+
+// FeatureHandler handles feature flag HTTP requests: the caller's tenant's
+// flags, and the admin routes that set any tenant's
+type FeatureHandler struct {
+	svc *service.FlagService
+}
+
+// NewFeatureHandler creates a new FeatureHandler
+// Panics if svc is nil to fail fast on misconfiguration
+func NewFeatureHandler(svc *service.FlagService) *FeatureHandler {
+	if svc == nil {
+		panic("NewFeatureHandler: svc (FlagService) must not be nil")
+	}
+	return &FeatureHandler{svc: svc}
+}
+
+// List handles GET /api/v1/features
+func (h *FeatureHandler) List(w http.ResponseWriter, r *http.Request) {
+	h.list(w, r, middleware.GetTenantID(r.Context()))
+}
+
+// TenantList handles GET /api/v1/admin/tenants/{id}/features
+func (h *FeatureHandler) TenantList(w http.ResponseWriter, r *http.Request) {
+	h.list(w, r, r.PathValue("id"))
+}
+
+func (h *FeatureHandler) list(w http.ResponseWriter, r *http.Request, tenantID string) {
+	flags, err := h.svc.List(r.Context(), tenantID)
+	if err != nil {
+		log.Printf("failed to list feature flags of tenant %s: %v", tenantID, err)
+		writeServerError(w, err, MsgInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, models.SuccessResponse(flags))
+}
+
+// Set handles PUT /api/v1/admin/tenants/{id}/features/{name}
+func (h *FeatureHandler) Set(w http.ResponseWriter, r *http.Request) {
+	tenantID := r.PathValue("id")
+	name := r.PathValue("name")
+	user := middleware.GetUser(r.Context())
+
+	var req models.SetFeatureFlagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeDecodeError(w, err, models.ErrCodeInvalidRequest, MsgInvalidRequestBody)
+		return
+	}
+	if problems := req.Validate(); len(problems) > 0 {
+		writeValidationError(w, problems...)
+		return
+	}
+
+	if err := h.svc.Set(r.Context(), tenantID, name, *req.Enabled, user); err != nil {
+		h.writeFlagError(w, err, "set", tenantID, name)
+		return
+	}
+	h.list(w, r, tenantID)
+}
+
+// Reset handles DELETE /api/v1/admin/tenants/{id}/features/{name}, returning
+// the flag to the server default
+func (h *FeatureHandler) Reset(w http.ResponseWriter, r *http.Request) {
+	tenantID := r.PathValue("id")
+	name := r.PathValue("name")
+
+	if err := h.svc.Reset(r.Context(), tenantID, name); err != nil {
+		h.writeFlagError(w, err, "reset", tenantID, name)
+		return
+	}
+	h.list(w, r, tenantID)
+}
+
+func (h *FeatureHandler) writeFlagError(w http.ResponseWriter, err error, op, tenantID, name string) {
+	if errors.Is(err, service.ErrFeatureNotFound) {
+		writeError(w, http.StatusNotFound, models.ErrCodeNotFound, MsgFeatureNotFound)
+		return
+	}
+	log.Printf("failed to %s feature flag %s of tenant %s: %v", op, name, tenantID, err)
+	writeServerError(w, err, MsgInternalServerError)
+}
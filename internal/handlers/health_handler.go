@@ -3,43 +3,162 @@ package handlers
 import (
 	"context"
 	"database/sql"
+	"log"
 	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/zlovtnik/gprint/internal/version"
 )
 
+// readinessTimeout bounds each readiness check so a hung dependency cannot block the probe
+const readinessTimeout = 5 * time.Second
+
+// Health statuses reported by the probe endpoints
+const (
+	healthStatusOK           = "ok"
+	healthStatusFail         = "fail"
+	healthStatusShuttingDown = "shutting down"
+)
+
+// Pinger reports whether a dependency is reachable; the Keycloak client implements it
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// HealthCheckResult is the outcome of one readiness check
+type HealthCheckResult struct {
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// HealthResponse is the body of the liveness and readiness endpoints
+type HealthResponse struct {
+	Status string                       `json:"status"`
+	Checks map[string]HealthCheckResult `json:"checks,omitempty"`
+}
+
 // HealthHandler handles health check HTTP requests
 type HealthHandler struct {
-	db *sql.DB
+	db           *sql.DB
+	keycloak     Pinger
+	outputPath   string
+	extra        map[string]func(context.Context) error
+	shuttingDown atomic.Bool
 }
 
-// NewHealthHandler creates a new HealthHandler
-func NewHealthHandler(db *sql.DB) *HealthHandler {
-	return &HealthHandler{db: db}
+// NewHealthHandler creates a new HealthHandler. A nil keycloak or empty
+// outputPath skips that readiness check (e.g. outputs stored in S3).
+func NewHealthHandler(db *sql.DB, keycloak Pinger, outputPath string) *HealthHandler {
+	return &HealthHandler{db: db, keycloak: keycloak, outputPath: outputPath}
 }
 
-// Health handles GET /health
+// AddCheck adds a readiness check reported under name. It must be called
+// before the handler serves requests.
+func (h *HealthHandler) AddCheck(name string, check func(context.Context) error) {
+	if h.extra == nil {
+		h.extra = make(map[string]func(context.Context) error)
+	}
+	h.extra[name] = check
+}
+
+// SetShuttingDown makes liveness and readiness fail so load balancers stop
+// sending traffic before the listener closes
+func (h *HealthHandler) SetShuttingDown() {
+	h.shuttingDown.Store(true)
+}
+
+// Health handles GET /healthz (and the legacy GET /health). It only reports
+// that the process is up; dependencies are the readiness probe's concern.
 func (h *HealthHandler) Health(w http.ResponseWriter, r *http.Request) {
-	writeJSON(w, http.StatusOK, map[string]string{
-		"status": "ok",
-	})
+	if h.shuttingDown.Load() {
+		writeJSON(w, http.StatusServiceUnavailable, HealthResponse{Status: healthStatusShuttingDown})
+		return
+	}
+	writeJSON(w, http.StatusOK, HealthResponse{Status: healthStatusOK})
 }
 
-// Ready handles GET /ready
+// Ready handles GET /readyz (and the legacy GET /ready). It checks the
+// database, Keycloak, the print output path and any added checks concurrently.
 func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
-	// Create a context with a short timeout to prevent blocking indefinitely
-	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	if h.shuttingDown.Load() {
+		writeJSON(w, http.StatusServiceUnavailable, HealthResponse{Status: healthStatusShuttingDown})
+		return
+	}
+
+	checks := map[string]func(context.Context) error{
+		"database": h.db.PingContext,
+	}
+	if h.keycloak != nil {
+		checks["keycloak"] = h.keycloak.Ping
+	}
+	if h.outputPath != "" {
+		checks["output_path"] = func(context.Context) error { return checkWritable(h.outputPath) }
+	}
+	for name, check := range h.extra {
+		checks[name] = check
+	}
+
+	resp := HealthResponse{Status: healthStatusOK, Checks: make(map[string]HealthCheckResult, len(checks))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := runHealthCheck(r.Context(), name, check)
+			mu.Lock()
+			resp.Checks[name] = result
+			if result.Status != healthStatusOK {
+				resp.Status = healthStatusFail
+			}
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	status := http.StatusOK
+	if resp.Status != healthStatusOK {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, resp)
+}
+
+// Version handles GET /version
+func (h *HealthHandler) Version(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, version.Get())
+}
+
+// runHealthCheck runs one check with a timeout. The endpoint is public, so the
+// underlying error is logged rather than returned.
+func runHealthCheck(ctx context.Context, name string, check func(context.Context) error) HealthCheckResult {
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
 	defer cancel()
 
-	// Check database connection with context
-	if err := h.db.PingContext(ctx); err != nil {
-		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
-			"status": "not ready",
-			"error":  "database connection failed",
-		})
-		return
+	start := time.Now()
+	err := check(ctx)
+	result := HealthCheckResult{
+		Status:    healthStatusOK,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		log.Printf("readiness check %s failed: %v", name, err)
+		result.Status = healthStatusFail
+		result.Error = name + " check failed"
 	}
+	return result
+}
 
-	writeJSON(w, http.StatusOK, map[string]string{
-		"status": "ready",
-	})
+// checkWritable verifies a file can be created in dir
+func checkWritable(dir string) error {
+	f, err := os.CreateTemp(dir, ".gprint-ready-*")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+	f.Close()
+	return os.Remove(name)
 }
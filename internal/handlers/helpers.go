@@ -2,12 +2,17 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
+	"mime"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 
+	"github.com/zlovtnik/gprint/internal/middleware"
 	"github.com/zlovtnik/gprint/internal/models"
+	"github.com/zlovtnik/gprint/internal/service"
 )
 
 // parseIDFromPath extracts an int64 ID from the request path.
@@ -17,6 +22,26 @@ func parseIDFromPath(r *http.Request, name string) (int64, error) {
 	return strconv.ParseInt(idStr, 10, 64)
 }
 
+// attachmentDisposition returns a Content-Disposition header downloading
+// the response as fileName, with both filename and filename* (RFC 5987)
+func attachmentDisposition(fileName string) string {
+	// Sanitize filename for Content-Disposition header
+	safeName := strings.Map(func(r rune) rune {
+		if r == '\r' || r == '\n' || r < 32 {
+			return -1
+		}
+		if r == '"' {
+			return '\''
+		}
+		return r
+	}, fileName)
+	disposition := mime.FormatMediaType("attachment", map[string]string{
+		"filename": safeName,
+	})
+	// Add filename* for UTF-8 encoding support
+	return disposition + "; filename*=UTF-8''" + url.PathEscape(safeName)
+}
+
 // parsePagination extracts pagination parameters from query string
 func parsePagination(r *http.Request) models.PaginationParams {
 	page := 1
@@ -39,6 +64,49 @@ func parsePagination(r *http.Request) models.PaginationParams {
 	}
 }
 
+// parseKeyset reports whether the request asked for keyset pagination by
+// passing cursor (empty for the first page) and returns its parameters.
+// Errors are messages for a 400 response.
+func parseKeyset(r *http.Request) (models.KeysetParams, bool, error) {
+	q := r.URL.Query()
+	if !q.Has("cursor") {
+		return models.KeysetParams{}, false, nil
+	}
+	if q.Has("page") {
+		return models.KeysetParams{}, true, errors.New(MsgCursorWithPage)
+	}
+
+	params := models.KeysetParams{PageSize: parsePagination(r).PageSize}
+	if token := q.Get("cursor"); token != "" {
+		cursor, err := models.ParseListCursor(token)
+		if err != nil {
+			return models.KeysetParams{}, true, errors.New(MsgInvalidCursor)
+		}
+		params.After = &cursor
+	}
+	return params, true, nil
+}
+
+// writePage writes a page of list responses, narrowed to fields when the
+// request selected any
+func writePage[T any](w http.ResponseWriter, items []T, fields []string, all models.ResponseFields[T], params models.PaginationParams, total int) {
+	if fields != nil {
+		writeJSON(w, http.StatusOK, models.SuccessResponse(models.NewPaginatedResponse(all.Project(items, fields), params.Page, params.PageSize, total)))
+		return
+	}
+	writeJSON(w, http.StatusOK, models.SuccessResponse(models.NewPaginatedResponse(items, params.Page, params.PageSize, total)))
+}
+
+// writeCursorPage writes a keyset page of list responses, narrowed to fields
+// when the request selected any
+func writeCursorPage[T any](w http.ResponseWriter, items []T, fields []string, all models.ResponseFields[T], pageSize int, next *models.ListCursor) {
+	if fields != nil {
+		writeJSON(w, http.StatusOK, models.SuccessResponse(models.NewCursorResponse(all.Project(items, fields), pageSize, next)))
+		return
+	}
+	writeJSON(w, http.StatusOK, models.SuccessResponse(models.NewCursorResponse(items, pageSize, next)))
+}
+
 // parseSearchParams extracts search/filter parameters from query string
 func parseSearchParams(r *http.Request) models.SearchParams {
 	params := models.SearchParams{
@@ -60,6 +128,14 @@ func parseSearchParams(r *http.Request) models.SearchParams {
 		params.Active = &b
 	}
 
+	if id, err := strconv.ParseInt(r.URL.Query().Get("customer_id"), 10, 64); err == nil && id > 0 {
+		params.CustomerID = id
+	}
+
+	if tags := r.URL.Query()["tag"]; len(tags) > 0 {
+		params.Tags = models.NormalizeTags(tags)
+	}
+
 	return params
 }
 
@@ -73,7 +149,54 @@ func writeJSON(w http.ResponseWriter, status int, data any) {
 	}
 }
 
-// writeError writes an error response in the standard format
+// writeError writes an error response in the standard format. The request ID
+// is read back from the response header set by RequestIDMiddleware.
 func writeError(w http.ResponseWriter, status int, code, message string) {
-	writeJSON(w, status, models.ErrorResponse(code, message, nil))
+	writeErrorDetails(w, status, code, message, nil)
+}
+
+func writeErrorDetails(w http.ResponseWriter, status int, code, message string, details []models.FieldError) {
+	resp := models.ErrorResponse(code, message, details)
+	resp.Error.RequestID = w.Header().Get(middleware.RequestIDHeader)
+	writeJSON(w, status, resp)
+}
+
+// writeErrorData writes an error response that also carries data, for
+// errors the client can act on
+func writeErrorData(w http.ResponseWriter, status int, code, message string, data any) {
+	resp := models.ErrorResponse(code, message, nil)
+	resp.Error.RequestID = w.Header().Get(middleware.RequestIDHeader)
+	resp.Data = data
+	writeJSON(w, status, resp)
+}
+
+// writeValidationError writes a 400 listing every rejected field
+func writeValidationError(w http.ResponseWriter, details ...models.FieldError) {
+	writeErrorDetails(w, http.StatusBadRequest, models.ErrCodeValidationErr, MsgValidationFailed, details)
+}
+
+// requiredField reports a missing required field
+func requiredField(field string) models.FieldError {
+	return models.FieldError{Field: field, Rule: "required", Message: field + " is required"}
+}
+
+// writeDecodeError reports a request body that could not be read or decoded:
+// 413 when it exceeded the body size limit, otherwise 400 with code and message
+func writeDecodeError(w http.ResponseWriter, err error, code, message string) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writeError(w, http.StatusRequestEntityTooLarge, models.ErrCodeTooLarge, MsgPayloadTooLarge)
+		return
+	}
+	writeError(w, http.StatusBadRequest, code, message)
+}
+
+// writeServerError writes a 500 for an unexpected error, or a 504 when it was
+// caused by a database statement exceeding its timeout
+func writeServerError(w http.ResponseWriter, err error, message string) {
+	if errors.Is(err, service.ErrQueryTimeout) {
+		writeError(w, http.StatusGatewayTimeout, models.ErrCodeTimeout, MsgQueryTimeout)
+		return
+	}
+	writeError(w, http.StatusInternalServerError, models.ErrCodeInternalError, message)
 }
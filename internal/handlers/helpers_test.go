@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/zlovtnik/gprint/internal/models"
+)
+
+// decodeError returns the error of a recorded error envelope
+func decodeError(t *testing.T, rec *httptest.ResponseRecorder) *models.APIError {
+	t.Helper()
+	var resp models.APIResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("body is not JSON: %v\n%s", err, rec.Body)
+	}
+	if resp.Success || resp.Error == nil {
+		t.Fatalf("body = %s, want an error envelope", rec.Body)
+	}
+	return resp.Error
+}
+
+// decodeData decodes the data of a recorded success envelope into v
+func decodeData(t *testing.T, rec *httptest.ResponseRecorder, v any) {
+	t.Helper()
+	resp := struct {
+		Success bool `json:"success"`
+		Data    any  `json:"data"`
+	}{Data: v}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("body is not JSON: %v\n%s", err, rec.Body)
+	}
+	if !resp.Success {
+		t.Fatalf("body = %s, want a success envelope", rec.Body)
+	}
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/zlovtnik/gprint/internal/middleware"
+	"github.com/zlovtnik/gprint/internal/models"
+	"github.com/zlovtnik/gprint/internal/service"
+)
+
+// InvoiceHandler handles invoice HTTP requests
+type InvoiceHandler struct {
+	svc *service.InvoiceService
+}
+
+// NewInvoiceHandler creates a new InvoiceHandler
+// Panics if svc is nil to fail fast on misconfiguration
+func NewInvoiceHandler(svc *service.InvoiceService) *InvoiceHandler {
+	if svc == nil {
+		panic("NewInvoiceHandler: svc (InvoiceService) must not be nil")
+	}
+	return &InvoiceHandler{svc: svc}
+}
+
+// ListByContract handles GET /api/v1/contracts/{id}/invoices
+func (h *InvoiceHandler) ListByContract(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	contractID, err := parseIDFromPath(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidID, MsgInvalidContractID)
+		return
+	}
+
+	params := parsePagination(r)
+	invoices, total, err := h.svc.ListByContract(r.Context(), tenantID, contractID, params)
+	if err != nil {
+		if errors.Is(err, service.ErrContractNotFound) {
+			writeError(w, http.StatusNotFound, models.ErrCodeNotFound, MsgContractNotFound)
+			return
+		}
+		log.Printf("failed to list invoices: %v", err)
+		writeServerError(w, err, MsgInternalServerError)
+		return
+	}
+	if invoices == nil {
+		invoices = []models.Invoice{}
+	}
+
+	writeJSON(w, http.StatusOK, models.SuccessResponse(models.NewPaginatedResponse(invoices, params.Page, params.PageSize, total)))
+}
+
+// UpdateStatus handles PATCH /api/v1/invoices/{id}/status
+func (h *InvoiceHandler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	user := middleware.GetUser(r.Context())
+	id, err := parseIDFromPath(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidID, MsgInvalidInvoiceID)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodySize)
+
+	var req models.UpdateInvoiceStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeDecodeError(w, err, models.ErrCodeInvalidRequest, MsgInvalidRequestBody)
+		return
+	}
+	if problems := req.Validate(); len(problems) > 0 {
+		writeValidationError(w, problems...)
+		return
+	}
+
+	invoice, err := h.svc.UpdateStatus(r.Context(), tenantID, id, req.Status, user)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvoiceNotFound):
+			writeError(w, http.StatusNotFound, models.ErrCodeNotFound, MsgInvoiceNotFound)
+		case errors.Is(err, service.ErrInvalidInvoiceTransition):
+			writeError(w, http.StatusConflict, models.ErrCodeInvalidTransition, err.Error())
+		default:
+			log.Printf("failed to update invoice status: %v", err)
+			writeServerError(w, err, MsgInternalServerError)
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, models.SuccessResponse(invoice))
+}
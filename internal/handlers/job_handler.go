@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/zlovtnik/gprint/internal/models"
+	"github.com/zlovtnik/gprint/internal/scheduler"
+)
+
+// JobHandler handles the admin routes over this instance's background jobs
+type JobHandler struct {
+	sched *scheduler.Scheduler
+}
+
+// NewJobHandler creates a new JobHandler
+// Panics if sched is nil to fail fast on misconfiguration
+func NewJobHandler(sched *scheduler.Scheduler) *JobHandler {
+	if sched == nil {
+		panic("NewJobHandler: sched (Scheduler) must not be nil")
+	}
+	return &JobHandler{sched: sched}
+}
+
+// List handles GET /api/v1/admin/jobs
+// Reports each job's schedule and its last run on the instance serving the request
+func (h *JobHandler) List(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, models.SuccessResponse(h.sched.Status()))
+}
+
+// RunNow handles POST /api/v1/admin/jobs/{name}/run-now
+// Starts a run on the instance serving the request and answers without waiting for it
+func (h *JobHandler) RunNow(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if err := h.sched.RunNow(name); err != nil {
+		switch {
+		case errors.Is(err, scheduler.ErrJobNotFound):
+			writeError(w, http.StatusNotFound, models.ErrCodeNotFound, MsgJobNotFound)
+		case errors.Is(err, scheduler.ErrJobRunning):
+			writeError(w, http.StatusConflict, models.ErrCodeConflict, MsgJobRunning)
+		default:
+			log.Printf("failed to trigger job %s: %v", name, err)
+			writeServerError(w, err, MsgInternalServerError)
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, models.SuccessResponse(map[string]string{"job": name}))
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/zlovtnik/gprint/internal/models"
+)
+
+// MetricsHandler serves Prometheus metrics, optionally requiring an internal bearer token
+type MetricsHandler struct {
+	registry http.Handler
+	token    string
+}
+
+// NewMetricsHandler creates a new MetricsHandler. An empty token disables the
+// check, which is only appropriate on a listener not exposed publicly.
+// Panics if registry is nil to fail fast on misconfiguration
+func NewMetricsHandler(registry http.Handler, token string) *MetricsHandler {
+	if registry == nil {
+		panic("NewMetricsHandler: registry must not be nil")
+	}
+	return &MetricsHandler{registry: registry, token: token}
+}
+
+// Metrics handles GET /metrics
+func (h *MetricsHandler) Metrics(w http.ResponseWriter, r *http.Request) {
+	if h.token != "" {
+		presented, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(presented), []byte(h.token)) != 1 {
+			writeError(w, http.StatusUnauthorized, models.ErrCodeUnauthorized, MsgInvalidMetricsToken)
+			return
+		}
+	}
+	h.registry.ServeHTTP(w, r)
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/mail"
+	"strconv"
+	"strings"
+
+	"github.com/zlovtnik/gprint/internal/middleware"
+	"github.com/zlovtnik/gprint/internal/models"
+	"github.com/zlovtnik/gprint/internal/service"
+)
+
+// NotificationHandler handles the caller's notification preference and
+// in-app notification HTTP requests
+type NotificationHandler struct {
+	svc *service.NotificationService
+}
+
+// NewNotificationHandler creates a new NotificationHandler
+// Panics if svc is nil to fail fast on misconfiguration
+func NewNotificationHandler(svc *service.NotificationService) *NotificationHandler {
+	if svc == nil {
+		panic("NewNotificationHandler: svc (NotificationService) must not be nil")
+	}
+	return &NotificationHandler{svc: svc}
+}
+
+// Get handles GET /api/v1/notification-preferences
+func (h *NotificationHandler) Get(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	user := middleware.GetUser(r.Context())
+
+	pref, err := h.svc.GetPreference(r.Context(), tenantID, user)
+	if err != nil {
+		log.Printf("failed to get notification preference: %v", err)
+		writeServerError(w, err, MsgInternalServerError)
+		return
+	}
+	if pref == nil {
+		writeError(w, http.StatusNotFound, models.ErrCodeNotFound, MsgNotificationPrefNotFound)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, models.SuccessResponse(pref))
+}
+
+// Update handles PUT /api/v1/notification-preferences
+func (h *NotificationHandler) Update(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	user := middleware.GetUser(r.Context())
+
+	var req models.UpdateNotificationPreferenceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeDecodeError(w, err, models.ErrCodeInvalidRequest, MsgInvalidRequestBody)
+		return
+	}
+
+	req.Email = strings.TrimSpace(req.Email)
+	if addr, err := mail.ParseAddress(req.Email); err != nil || addr.Address != req.Email {
+		writeValidationError(w, models.FieldError{Field: "email", Rule: "email", Message: MsgInvalidEmail})
+		return
+	}
+
+	pref, err := h.svc.UpdatePreference(r.Context(), tenantID, user, &req)
+	if err != nil {
+		log.Printf("failed to update notification preference: %v", err)
+		writeServerError(w, err, MsgInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, models.SuccessResponse(pref))
+}
+
+// Delete handles DELETE /api/v1/notification-preferences
+func (h *NotificationHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	user := middleware.GetUser(r.Context())
+
+	if err := h.svc.DeletePreference(r.Context(), tenantID, user); err != nil {
+		log.Printf("failed to delete notification preference: %v", err)
+		writeServerError(w, err, MsgInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, models.SuccessResponse(nil))
+}
+
+// List handles GET /api/v1/notifications
+// Lists the caller's notifications, unread first; unread=true leaves out read ones
+func (h *NotificationHandler) List(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	user := middleware.GetUser(r.Context())
+
+	unreadOnly := false
+	if v := r.URL.Query().Get("unread"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, models.ErrCodeInvalidRequest, "unread must be true or false")
+			return
+		}
+		unreadOnly = b
+	}
+
+	params := parsePagination(r)
+	notifications, total, err := h.svc.List(r.Context(), tenantID, user, unreadOnly, params.Page, params.PageSize)
+	if err != nil {
+		log.Printf("failed to list notifications: %v", err)
+		writeServerError(w, err, MsgInternalServerError)
+		return
+	}
+
+	result := models.NewPaginatedResponse(notifications, params.Page, params.PageSize, total)
+	writeJSON(w, http.StatusOK, models.SuccessResponse(result))
+}
+
+// UnreadCount handles GET /api/v1/notifications/unread-count
+func (h *NotificationHandler) UnreadCount(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	user := middleware.GetUser(r.Context())
+
+	count, err := h.svc.UnreadCount(r.Context(), tenantID, user)
+	if err != nil {
+		log.Printf("failed to count unread notifications: %v", err)
+		writeServerError(w, err, MsgInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, models.SuccessResponse(models.NotificationCount{Unread: count}))
+}
+
+// MarkRead handles POST /api/v1/notifications/{id}/read
+func (h *NotificationHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	user := middleware.GetUser(r.Context())
+
+	id, err := parseIDFromPath(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidID, MsgInvalidNotificationID)
+		return
+	}
+
+	notification, err := h.svc.MarkRead(r.Context(), tenantID, user, id)
+	if err != nil {
+		if errors.Is(err, service.ErrNotificationNotFound) {
+			writeError(w, http.StatusNotFound, models.ErrCodeNotFound, MsgNotificationNotFound)
+			return
+		}
+		log.Printf("failed to mark notification read: %v", err)
+		writeServerError(w, err, MsgInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, models.SuccessResponse(notification))
+}
+
+// MarkAllRead handles POST /api/v1/notifications/read-all
+func (h *NotificationHandler) MarkAllRead(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	user := middleware.GetUser(r.Context())
+
+	marked, err := h.svc.MarkAllRead(r.Context(), tenantID, user)
+	if err != nil {
+		log.Printf("failed to mark notifications read: %v", err)
+		writeServerError(w, err, MsgInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, models.SuccessResponse(models.MarkAllNotificationsReadResponse{Marked: marked}))
+}
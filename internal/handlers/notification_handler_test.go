@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/zlovtnik/gprint/internal/models"
+	"github.com/zlovtnik/gprint/internal/repository"
+	"github.com/zlovtnik/gprint/internal/service"
+	"github.com/zlovtnik/gprint/pkg/requestctx"
+)
+
+// newMockNotificationHandler builds a NotificationHandler over a mocked database
+func newMockNotificationHandler(t *testing.T) (*NotificationHandler, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	repo, err := repository.NewNotificationRepository(repository.NewDB(db, repository.DBConfig{}, slog.New(slog.NewTextHandler(io.Discard, nil))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewNotificationHandler(service.NewNotificationService(repo)), mock
+}
+
+// notificationRequest builds a request of user alice of tenant t1
+func notificationRequest(method, target string) *http.Request {
+	req := httptest.NewRequest(method, target, nil)
+	ctx := requestctx.WithTenantID(req.Context(), "t1")
+	return req.WithContext(requestctx.WithUser(ctx, "alice"))
+}
+
+func TestNotificationUnreadCount(t *testing.T) {
+	h, mock := newMockNotificationHandler(t)
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM NOTIFICATIONS WHERE tenant_id = :1 AND user_id = :2 AND read_at IS NULL`).
+		WithArgs("t1", "alice").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+	rec := httptest.NewRecorder()
+	h.UnreadCount(rec, notificationRequest(http.MethodGet, "/api/v1/notifications/unread-count"))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	var count models.NotificationCount
+	decodeData(t, rec, &count)
+	if count.Unread != 3 {
+		t.Errorf("unread = %d, want 3", count.Unread)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNotificationMarkRead(t *testing.T) {
+	h, mock := newMockNotificationHandler(t)
+	readAt := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	mock.ExpectExec(`UPDATE NOTIFICATIONS\s+SET read_at = COALESCE\(read_at, CURRENT_TIMESTAMP\)\s+WHERE tenant_id = :1 AND user_id = :2 AND id = :3`).
+		WithArgs("t1", "alice", int64(42)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`FROM NOTIFICATIONS\s+WHERE tenant_id = :1 AND user_id = :2 AND id = :3`).
+		WithArgs("t1", "alice", int64(42)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id", "user_id", "type", "title", "body", "entity_type", "entity_id", "created_at", "read_at"}).
+			AddRow(int64(42), "t1", "alice", "print_job.completed", "Print job #9 is ready", nil, "print_job", int64(9), readAt, readAt))
+
+	req := notificationRequest(http.MethodPost, "/api/v1/notifications/42/read")
+	req.SetPathValue("id", "42")
+	rec := httptest.NewRecorder()
+	h.MarkRead(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	var n models.Notification
+	decodeData(t, rec, &n)
+	if n.ID != 42 || n.ReadAt == nil || !n.ReadAt.Equal(readAt) {
+		t.Errorf("notification = %+v, want 42 read", n)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNotificationMarkReadOfAnotherUser(t *testing.T) {
+	// Another user's notification, or one of another tenant, is not updated
+	// by the tenant and user scoped UPDATE
+	h, mock := newMockNotificationHandler(t)
+	mock.ExpectExec(`UPDATE NOTIFICATIONS`).
+		WithArgs("t1", "alice", int64(43)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	req := notificationRequest(http.MethodPost, "/api/v1/notifications/43/read")
+	req.SetPathValue("id", "43")
+	rec := httptest.NewRecorder()
+	h.MarkRead(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404: %s", rec.Code, rec.Body)
+	}
+	if resp := decodeError(t, rec); resp.Code != models.ErrCodeNotFound || resp.Message != MsgNotificationNotFound {
+		t.Errorf("error = %+v", resp)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNotificationMarkReadInvalidID(t *testing.T) {
+	h, mock := newMockNotificationHandler(t)
+	req := notificationRequest(http.MethodPost, "/api/v1/notifications/abc/read")
+	req.SetPathValue("id", "abc")
+	rec := httptest.NewRecorder()
+	h.MarkRead(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400: %s", rec.Code, rec.Body)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNotificationMarkAllRead(t *testing.T) {
+	h, mock := newMockNotificationHandler(t)
+	mock.ExpectExec(`UPDATE NOTIFICATIONS\s+SET read_at = CURRENT_TIMESTAMP\s+WHERE tenant_id = :1 AND user_id = :2 AND read_at IS NULL`).
+		WithArgs("t1", "alice").
+		WillReturnResult(sqlmock.NewResult(0, 5))
+
+	rec := httptest.NewRecorder()
+	h.MarkAllRead(rec, notificationRequest(http.MethodPost, "/api/v1/notifications/read-all"))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	var resp models.MarkAllNotificationsReadResponse
+	decodeData(t, rec, &resp)
+	if resp.Marked != 5 {
+		t.Errorf("marked = %d, want 5", resp.Marked)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -4,20 +4,40 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"log"
-	"mime"
 	"net/http"
-	"net/url"
-	"os"
-	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/zlovtnik/gprint/internal/middleware"
 	"github.com/zlovtnik/gprint/internal/models"
 	"github.com/zlovtnik/gprint/internal/service"
 )
 
+// ValidPrintPriorities contains all valid print job priority values
+var ValidPrintPriorities = map[models.PrintPriority]bool{
+	models.PrintPriorityLow:    true,
+	models.PrintPriorityNormal: true,
+	models.PrintPriorityHigh:   true,
+}
+
+// ValidPrintJobStatuses contains all valid print job status values
+var ValidPrintJobStatuses = map[models.PrintJobStatus]bool{
+	models.PrintJobStatusQueued:     true,
+	models.PrintJobStatusProcessing: true,
+	models.PrintJobStatusCompleted:  true,
+	models.PrintJobStatusFailed:     true,
+	models.PrintJobStatusDead:       true,
+	models.PrintJobStatusCancelled:  true,
+	models.PrintJobStatusPurged:     true,
+}
+
+// maxRequestedByLen matches the requested_by column width
+const maxRequestedByLen = 100
+
 // PrintHandler handles print job HTTP requests
 type PrintHandler struct {
 	svc *service.PrintService
@@ -34,18 +54,21 @@ func (h *PrintHandler) CreateJob(w http.ResponseWriter, r *http.Request) {
 	user := middleware.GetUser(r.Context())
 	contractID, err := parseIDFromPath(r, "id")
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "INVALID_ID", "invalid contract ID")
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidID, "invalid contract ID")
 		return
 	}
 
 	var req struct {
-		Format models.PrintFormat `json:"format"`
+		Format     models.PrintFormat   `json:"format"`
+		Priority   models.PrintPriority `json:"priority"`
+		Watermark  *bool                `json:"watermark"`
+		Regenerate bool                 `json:"regenerate"`
 	}
 
 	// Read the entire body
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "failed to read request body")
+		writeDecodeError(w, err, models.ErrCodeInvalidRequest, "failed to read request body")
 		return
 	}
 
@@ -57,7 +80,7 @@ func (h *PrintHandler) CreateJob(w http.ResponseWriter, r *http.Request) {
 	} else {
 		// Try to unmarshal
 		if err := json.Unmarshal(body, &req); err != nil {
-			writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid JSON in request body")
+			writeError(w, http.StatusBadRequest, models.ErrCodeInvalidRequest, "invalid JSON in request body")
 			return
 		}
 	}
@@ -65,15 +88,36 @@ func (h *PrintHandler) CreateJob(w http.ResponseWriter, r *http.Request) {
 	if req.Format == "" {
 		req.Format = models.PrintFormatPDF
 	}
+	if req.Priority == "" {
+		req.Priority = models.PrintPriorityNormal
+	} else if !ValidPrintPriorities[req.Priority] {
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidPriority, MsgInvalidPriority)
+		return
+	}
 
-	job, err := h.svc.CreateJob(r.Context(), tenantID, contractID, req.Format, user)
+	job, err := h.svc.CreateJob(r.Context(), tenantID, &models.CreatePrintJobRequest{
+		ContractID: contractID,
+		Format:     req.Format,
+		Priority:   req.Priority,
+		Watermark:  req.Watermark,
+		Regenerate: req.Regenerate,
+	}, user)
 	if err != nil {
 		if errors.Is(err, service.ErrContractNotFound) {
-			writeError(w, http.StatusNotFound, ErrCodeNotFound, MsgContractNotFound)
+			writeError(w, http.StatusNotFound, models.ErrCodeNotFound, MsgContractNotFound)
+			return
+		}
+		var stale *service.StaleGenerationError
+		if errors.As(err, &stale) {
+			writeErrorData(w, http.StatusConflict, models.ErrCodeStaleGeneration, MsgStaleGeneration, stale.StaleGeneration)
+			return
+		}
+		if errors.Is(err, service.ErrWatermarkRequired) {
+			writeError(w, http.StatusForbidden, models.ErrCodeForbidden, MsgWatermarkRequired)
 			return
 		}
 		log.Printf("failed to create print job: %v", err)
-		writeError(w, http.StatusInternalServerError, ErrCodeInternalError, MsgInternalServerError)
+		writeServerError(w, err, MsgInternalServerError)
 		return
 	}
 
@@ -81,16 +125,66 @@ func (h *PrintHandler) CreateJob(w http.ResponseWriter, r *http.Request) {
 }
 
 // List handles GET /api/v1/print-jobs
+// Supports status, contract_id, requested_by, queued_from and queued_to filters.
 func (h *PrintHandler) List(w http.ResponseWriter, r *http.Request) {
+	filter, err := parsePrintJobFilter(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, models.ErrCodeValidationErr, err.Error())
+		return
+	}
+	h.writeJobList(w, r, filter)
+}
+
+// GetJobsByContract handles GET /api/v1/contracts/{id}/print-jobs
+// Accepts the same filters as List; contract_id is taken from the path.
+func (h *PrintHandler) GetJobsByContract(w http.ResponseWriter, r *http.Request) {
+	contractID, err := parseIDFromPath(r, "id")
+	if err != nil || contractID <= 0 {
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidID, MsgInvalidContractID)
+		return
+	}
+
+	filter, err := parsePrintJobFilter(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, models.ErrCodeValidationErr, err.Error())
+		return
+	}
+	filter.ContractID = contractID
+	h.writeJobList(w, r, filter)
+}
+
+// writeJobList writes a page of print jobs matching filter, by page/page_size
+// or, when cursor is passed, by keyset
+func (h *PrintHandler) writeJobList(w http.ResponseWriter, r *http.Request, filter models.PrintJobFilter) {
 	tenantID := middleware.GetTenantID(r.Context())
 
+	keyset, ok, err := parseKeyset(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, models.ErrCodeValidationErr, err.Error())
+		return
+	}
+	if ok {
+		jobs, next, err := h.svc.ListKeyset(r.Context(), tenantID, filter, keyset)
+		if err != nil {
+			log.Printf("failed to list print jobs: %v", err)
+			writeServerError(w, err, MsgInternalServerError)
+			return
+		}
+		responses := make([]models.PrintJobResponse, len(jobs))
+		for i, j := range jobs {
+			responses[i] = j.ToResponse()
+		}
+		writeJSON(w, http.StatusOK, models.SuccessResponse(models.NewCursorResponse(responses, keyset.PageSize, next)))
+		return
+	}
+
 	// Parse pagination parameters
 	params := parsePagination(r)
 
-	jobs, total, err := h.svc.List(r.Context(), tenantID, params.Page, params.PageSize)
+	jobs, total, err := h.svc.List(r.Context(), tenantID, filter, params.Page, params.PageSize)
 	if err != nil {
 		log.Printf("failed to list print jobs: %v", err)
-		writeError(w, http.StatusInternalServerError, ErrCodeInternalError, MsgInternalServerError)
+		writeServerError(w, err, MsgInternalServerError)
 		return
 	}
 
@@ -103,51 +197,293 @@ func (h *PrintHandler) List(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, models.SuccessResponse(result))
 }
 
+// parsePrintJobFilter extracts and validates print job list filters from the query string.
+// Dates accept RFC 3339 timestamps or plain YYYY-MM-DD days; a plain queued_to day is inclusive.
+func parsePrintJobFilter(r *http.Request) (models.PrintJobFilter, error) {
+	q := r.URL.Query()
+	var filter models.PrintJobFilter
+
+	if status := strings.ToUpper(strings.TrimSpace(q.Get("status"))); status != "" {
+		if !ValidPrintJobStatuses[models.PrintJobStatus(status)] {
+			return filter, fmt.Errorf("invalid status %q", status)
+		}
+		filter.Status = models.PrintJobStatus(status)
+	}
+
+	if contractID := strings.TrimSpace(q.Get("contract_id")); contractID != "" {
+		id, err := strconv.ParseInt(contractID, 10, 64)
+		if err != nil || id <= 0 {
+			return filter, errors.New("contract_id must be a positive integer")
+		}
+		filter.ContractID = id
+	}
+
+	if requestedBy := strings.TrimSpace(q.Get("requested_by")); requestedBy != "" {
+		if len(requestedBy) > maxRequestedByLen {
+			return filter, fmt.Errorf("requested_by must be at most %d characters", maxRequestedByLen)
+		}
+		filter.RequestedBy = requestedBy
+	}
+
+	if from := strings.TrimSpace(q.Get("queued_from")); from != "" {
+		t, _, err := parseFilterTime(from)
+		if err != nil {
+			return filter, errors.New("queued_from must be RFC 3339 or YYYY-MM-DD")
+		}
+		filter.QueuedFrom = &t
+	}
+
+	if to := strings.TrimSpace(q.Get("queued_to")); to != "" {
+		t, dateOnly, err := parseFilterTime(to)
+		if err != nil {
+			return filter, errors.New("queued_to must be RFC 3339 or YYYY-MM-DD")
+		}
+		if dateOnly {
+			t = t.AddDate(0, 0, 1)
+		}
+		filter.QueuedTo = &t
+	}
+
+	if filter.QueuedFrom != nil && filter.QueuedTo != nil && !filter.QueuedFrom.Before(*filter.QueuedTo) {
+		return filter, errors.New("queued_from must be before queued_to")
+	}
+
+	return filter, nil
+}
+
+// parseFilterTime parses an RFC 3339 timestamp or a YYYY-MM-DD date,
+// reporting whether the value was a plain date
+func parseFilterTime(value string) (time.Time, bool, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, false, nil
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return t, true, nil
+}
+
 // GetJob handles GET /api/v1/print-jobs/{id}
 func (h *PrintHandler) GetJob(w http.ResponseWriter, r *http.Request) {
 	tenantID := middleware.GetTenantID(r.Context())
 	id, err := parseIDFromPath(r, "id")
 	if err != nil {
-		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, MsgInvalidPrintJobID)
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidID, MsgInvalidPrintJobID)
 		return
 	}
 
 	job, err := h.svc.GetJob(r.Context(), tenantID, id)
 	if err != nil {
 		log.Printf("failed to retrieve print job (id=%d, tenant=%s): %v", id, tenantID, err)
-		writeError(w, http.StatusInternalServerError, ErrCodeInternalError, MsgFailedToRetrieveJob)
+		writeServerError(w, err, MsgFailedToRetrieveJob)
 		return
 	}
 	if job == nil {
-		writeError(w, http.StatusNotFound, ErrCodeNotFound, MsgPrintJobNotFound)
+		writeError(w, http.StatusNotFound, models.ErrCodeNotFound, MsgPrintJobNotFound)
 		return
 	}
 
 	writeJSON(w, http.StatusOK, models.SuccessResponse(job.ToResponse()))
 }
 
-// GetJobsByContract handles GET /api/v1/contracts/{id}/print-jobs
-func (h *PrintHandler) GetJobsByContract(w http.ResponseWriter, r *http.Request) {
+const (
+	// jobEventPollInterval is how often the events stream re-reads the job
+	jobEventPollInterval = time.Second
+	// jobEventKeepAlive is how often a comment is sent so idle proxies keep the stream open
+	jobEventKeepAlive = 15 * time.Second
+)
+
+// Events handles GET /api/v1/print-jobs/{id}/events
+// Streams the job as Server-Sent Events: a "status" event whenever its status or
+// progress changes, ending once the job reaches a terminal state.
+func (h *PrintHandler) Events(w http.ResponseWriter, r *http.Request) {
 	tenantID := middleware.GetTenantID(r.Context())
-	contractID, err := parseIDFromPath(r, "id")
+	id, err := parseIDFromPath(r, "id")
 	if err != nil {
-		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, MsgInvalidContractID)
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidID, MsgInvalidPrintJobID)
 		return
 	}
 
-	jobs, err := h.svc.GetJobsByContract(r.Context(), tenantID, contractID)
+	job, err := h.svc.GetJob(r.Context(), tenantID, id)
 	if err != nil {
-		log.Printf("failed to get print jobs for contract: %v", err)
-		writeError(w, http.StatusInternalServerError, ErrCodeInternalError, MsgInternalServerError)
+		log.Printf("failed to retrieve print job (id=%d, tenant=%s): %v", id, tenantID, err)
+		writeServerError(w, err, MsgFailedToRetrieveJob)
+		return
+	}
+	if job == nil {
+		writeError(w, http.StatusNotFound, models.ErrCodeNotFound, MsgPrintJobNotFound)
 		return
 	}
 
-	responses := make([]models.PrintJobResponse, len(jobs))
-	for i, j := range jobs {
-		responses[i] = j.ToResponse()
+	// The stream outlives the server write timeout
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		log.Printf("failed to clear write deadline for print job events: %v", err)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	send := func(job *models.ContractPrintJob) bool {
+		data, err := json.Marshal(job.ToResponse())
+		if err != nil {
+			log.Printf("failed to encode print job event (id=%d): %v", id, err)
+			return false
+		}
+		if _, err := fmt.Fprintf(w, "event: status\ndata: %s\n\n", data); err != nil {
+			return false
+		}
+		return rc.Flush() == nil
+	}
+
+	if !send(job) || job.IsTerminal() {
+		return
+	}
+	lastStatus, lastProgress := job.Status, job.ProgressPct
+
+	poll := time.NewTicker(jobEventPollInterval)
+	defer poll.Stop()
+	keepAlive := time.NewTicker(jobEventKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil || rc.Flush() != nil {
+				return
+			}
+		case <-poll.C:
+			job, err := h.svc.GetJob(r.Context(), tenantID, id)
+			if err != nil {
+				if r.Context().Err() == nil {
+					log.Printf("failed to poll print job events (id=%d): %v", id, err)
+				}
+				return
+			}
+			if job == nil {
+				return
+			}
+			if job.Status == lastStatus && job.ProgressPct == lastProgress {
+				continue
+			}
+			if !send(job) || job.IsTerminal() {
+				return
+			}
+			lastStatus, lastProgress = job.Status, job.ProgressPct
+		}
+	}
+}
+
+// UpdatePriority handles PATCH /api/v1/print-jobs/{id}/priority
+func (h *PrintHandler) UpdatePriority(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	id, err := parseIDFromPath(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidID, MsgInvalidPrintJobID)
+		return
+	}
+
+	// Limit request body size to prevent excessive payloads
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodySize)
+
+	var req models.UpdatePrintJobPriorityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeDecodeError(w, err, models.ErrCodeInvalidRequest, MsgInvalidRequestBody)
+		return
+	}
+	if !ValidPrintPriorities[req.Priority] {
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidPriority, MsgInvalidPriority)
+		return
+	}
+
+	job, err := h.svc.UpdatePriority(r.Context(), tenantID, id, req.Priority)
+	if err != nil {
+		if errors.Is(err, service.ErrPrintJobNotFound) {
+			writeError(w, http.StatusNotFound, models.ErrCodeNotFound, MsgPrintJobNotFound)
+			return
+		}
+		if errors.Is(err, service.ErrPrintJobNotQueued) {
+			writeError(w, http.StatusConflict, models.ErrCodeInvalidStatus, MsgJobNotQueued)
+			return
+		}
+		log.Printf("failed to update print job priority (id=%d, tenant=%s): %v", id, tenantID, err)
+		writeServerError(w, err, MsgInternalServerError)
+		return
+	}
+	if job == nil {
+		writeError(w, http.StatusNotFound, models.ErrCodeNotFound, MsgPrintJobNotFound)
+		return
 	}
 
-	writeJSON(w, http.StatusOK, models.SuccessResponse(responses))
+	writeJSON(w, http.StatusOK, models.SuccessResponse(job.ToResponse()))
+}
+
+// RetryJob handles POST /api/v1/print-jobs/{id}/retry
+func (h *PrintHandler) RetryJob(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	id, err := parseIDFromPath(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidID, MsgInvalidPrintJobID)
+		return
+	}
+
+	job, err := h.svc.RetryJob(r.Context(), tenantID, id)
+	if err != nil {
+		if errors.Is(err, service.ErrPrintJobNotFound) {
+			writeError(w, http.StatusNotFound, models.ErrCodeNotFound, MsgPrintJobNotFound)
+			return
+		}
+		if errors.Is(err, service.ErrPrintJobNotRetryable) {
+			writeError(w, http.StatusConflict, models.ErrCodeInvalidStatus, MsgJobNotRetryable)
+			return
+		}
+		log.Printf("failed to retry print job (id=%d, tenant=%s): %v", id, tenantID, err)
+		writeServerError(w, err, MsgInternalServerError)
+		return
+	}
+	if job == nil {
+		writeError(w, http.StatusNotFound, models.ErrCodeNotFound, MsgPrintJobNotFound)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, models.SuccessResponse(job.ToResponse()))
+}
+
+// CancelJob handles POST /api/v1/print-jobs/{id}/cancel
+func (h *PrintHandler) CancelJob(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	id, err := parseIDFromPath(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidID, MsgInvalidPrintJobID)
+		return
+	}
+
+	job, err := h.svc.CancelJob(r.Context(), tenantID, id)
+	if err != nil {
+		if errors.Is(err, service.ErrPrintJobNotFound) {
+			writeError(w, http.StatusNotFound, models.ErrCodeNotFound, MsgPrintJobNotFound)
+			return
+		}
+		if errors.Is(err, service.ErrPrintJobNotCancellable) {
+			writeError(w, http.StatusConflict, models.ErrCodeInvalidStatus, MsgJobNotCancellable)
+			return
+		}
+		log.Printf("failed to cancel print job (id=%d, tenant=%s): %v", id, tenantID, err)
+		writeServerError(w, err, MsgInternalServerError)
+		return
+	}
+	if job == nil {
+		writeError(w, http.StatusNotFound, models.ErrCodeNotFound, MsgPrintJobNotFound)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, models.SuccessResponse(job.ToResponse()))
 }
 
 // Download handles GET /api/v1/print-jobs/{id}/download
@@ -155,67 +491,52 @@ func (h *PrintHandler) Download(w http.ResponseWriter, r *http.Request) {
 	tenantID := middleware.GetTenantID(r.Context())
 	id, err := parseIDFromPath(r, "id")
 	if err != nil {
-		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, MsgInvalidPrintJobID)
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidID, MsgInvalidPrintJobID)
 		return
 	}
 
-	filePath, err := h.svc.DownloadJob(r.Context(), tenantID, id)
+	download, err := h.svc.DownloadJob(r.Context(), service.DownloadRequest{
+		TenantID:  tenantID,
+		JobID:     id,
+		UserID:    middleware.GetUser(r.Context()),
+		IPAddress: getClientIP(r),
+		SessionID: getSessionID(r),
+	})
 	if err != nil {
 		if errors.Is(err, service.ErrPrintJobNotFound) {
-			writeError(w, http.StatusNotFound, ErrCodeNotFound, MsgPrintJobNotFound)
+			writeError(w, http.StatusNotFound, models.ErrCodeNotFound, MsgPrintJobNotFound)
+			return
+		}
+		if errors.Is(err, service.ErrOutputPurged) {
+			writeError(w, http.StatusGone, models.ErrCodeOutputPurged, MsgOutputPurged)
 			return
 		}
 		if errors.Is(err, service.ErrJobNotCompleted) {
-			writeError(w, http.StatusConflict, ErrCodeNotReady, MsgJobNotCompleted)
+			writeError(w, http.StatusConflict, models.ErrCodeNotReady, MsgJobNotCompleted)
 			return
 		}
 		if errors.Is(err, service.ErrOutputFileNotFound) {
-			writeError(w, http.StatusNotFound, ErrCodeFileNotFound, MsgFileNotFound)
+			writeError(w, http.StatusNotFound, models.ErrCodeFileNotFound, MsgFileNotFound)
 			return
 		}
 		log.Printf("failed to download print job: %v", err)
-		writeError(w, http.StatusInternalServerError, ErrCodeInternalError, MsgInternalServerError)
+		writeServerError(w, err, MsgInternalServerError)
 		return
 	}
 
-	// Check if file exists
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		writeError(w, http.StatusNotFound, ErrCodeFileNotFound, MsgFileNotFound)
+	if download.RedirectURL != "" {
+		http.Redirect(w, r, download.RedirectURL, http.StatusFound)
 		return
 	}
+	defer download.Body.Close()
 
-	// Determine content type
-	ext := strings.ToLower(filepath.Ext(filePath))
-	contentType := "application/octet-stream"
-	switch ext {
-	case ".pdf":
-		contentType = "application/pdf"
-	case ".html":
-		contentType = "text/html"
-	case ".docx":
-		contentType = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	w.Header().Set("Content-Type", download.ContentType)
+	if download.Size >= 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(download.Size, 10))
+	}
+	w.Header().Set("Content-Disposition", attachmentDisposition(download.FileName))
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, download.Body); err != nil {
+		log.Printf("failed to stream print job output (id=%d): %v", id, err)
 	}
-
-	// Sanitize filename for Content-Disposition header
-	safeName := filepath.Base(filePath)
-	// Remove control characters and replace quotes
-	safeName = strings.Map(func(r rune) rune {
-		if r == '\r' || r == '\n' || r < 32 {
-			return -1
-		}
-		if r == '"' {
-			return '\''
-		}
-		return r
-	}, safeName)
-	// Build Content-Disposition with both filename and filename* (RFC5987)
-	disposition := mime.FormatMediaType("attachment", map[string]string{
-		"filename": safeName,
-	})
-	// Add filename* for UTF-8 encoding support
-	disposition += "; filename*=UTF-8''" + url.PathEscape(safeName)
-
-	w.Header().Set("Content-Type", contentType)
-	w.Header().Set("Content-Disposition", disposition)
-	http.ServeFile(w, r, filePath)
 }
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/zlovtnik/gprint/internal/middleware"
+	"github.com/zlovtnik/gprint/internal/models"
+	"github.com/zlovtnik/gprint/internal/service"
+)
+
+// SearchHandler handles the cross-entity search used by the TUI search box
+type SearchHandler struct {
+	svc *service.SearchService
+}
+
+// NewSearchHandler creates a new SearchHandler
+// Panics if svc is nil to fail fast on misconfiguration
+func NewSearchHandler(svc *service.SearchService) *SearchHandler {
+	if svc == nil {
+		panic("NewSearchHandler: svc (SearchService) must not be nil")
+	}
+	return &SearchHandler{svc: svc}
+}
+
+// Search handles GET /api/v1/search?q=...&limit=...
+// limit caps the hits returned per type.
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+
+	limit := service.DefaultSearchLimit
+	if l := r.URL.Query().Get("limit"); l != "" {
+		parsed, err := strconv.Atoi(l)
+		if err != nil || parsed < 1 || parsed > service.MaxSearchLimit {
+			writeError(w, http.StatusBadRequest, models.ErrCodeValidationErr,
+				fmt.Sprintf("limit must be between 1 and %d", service.MaxSearchLimit))
+			return
+		}
+		limit = parsed
+	}
+
+	resp, err := h.svc.Search(r.Context(), tenantID, r.URL.Query().Get("q"), limit)
+	if err != nil {
+		if errors.Is(err, service.ErrSearchQueryTooShort) {
+			writeError(w, http.StatusBadRequest, models.ErrCodeValidationErr, MsgSearchQueryTooShort)
+			return
+		}
+		log.Printf("failed to search: %v", err)
+		writeServerError(w, err, MsgInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, models.SuccessResponse(resp))
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/zlovtnik/gprint/internal/models"
+	"github.com/zlovtnik/gprint/internal/repository"
+	"github.com/zlovtnik/gprint/internal/service"
+	"github.com/zlovtnik/gprint/pkg/requestctx"
+)
+
+// newMockSearchHandler builds a SearchHandler over a mocked database. The
+// three lookups run concurrently, so expectations match in any order.
+func newMockSearchHandler(t *testing.T) (*SearchHandler, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	mock.MatchExpectationsInOrder(false)
+	t.Cleanup(func() { db.Close() })
+	rdb := repository.NewDB(db, repository.DBConfig{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	customers, err := repository.NewCustomerRepository(rdb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := service.NewSearchService(customers, repository.NewContractRepository(rdb), repository.NewServiceRepository(rdb))
+	return NewSearchHandler(svc), mock
+}
+
+// searchRequest builds a search request of tenant t1 with the given parameters
+func searchRequest(params url.Values) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/search?"+params.Encode(), nil)
+	return req.WithContext(requestctx.WithTenantID(req.Context(), "t1"))
+}
+
+// summaryRows returns search rows labelled labels out of total matches
+func summaryRows(total int, labels ...string) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{"id", "label", "secondary", "total"})
+	for i, label := range labels {
+		rows.AddRow(int64(i+1), label, nil, total)
+	}
+	return rows
+}
+
+// expectSearch expects each type to be searched for pattern within tenant t1,
+// limit hits at a time
+func expectSearch(mock sqlmock.Sqlmock, pattern string, limit int) (customers, contracts, services *sqlmock.ExpectedQuery) {
+	customers = mock.ExpectQuery(`FROM customers\s+WHERE tenant_id = :1 AND active = 1`).
+		WithArgs("t1", pattern, pattern, pattern, limit)
+	contracts = mock.ExpectQuery(`(?s)FROM contracts c\s+JOIN customers cu ON cu.id = c.customer_id AND cu.tenant_id = c.tenant_id\s+WHERE c.tenant_id = :1.*UPPER\(c.contract_number\) LIKE.*OR UPPER\(cu.name\) LIKE`).
+		WithArgs("t1", pattern, pattern, limit)
+	services = mock.ExpectQuery(`FROM services\s+WHERE tenant_id = :1 AND active = 1`).
+		WithArgs("t1", pattern, pattern, limit)
+	return customers, contracts, services
+}
+
+func TestSearchRejectsShortQueries(t *testing.T) {
+	for _, q := range []string{"", "a", "  a  ", "é"} {
+		h, mock := newMockSearchHandler(t)
+		rec := httptest.NewRecorder()
+		h.Search(rec, searchRequest(url.Values{"q": {q}}))
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("q=%q: status = %d, want 400", q, rec.Code)
+			continue
+		}
+		if resp := decodeError(t, rec); resp.Code != models.ErrCodeValidationErr || resp.Message != MsgSearchQueryTooShort {
+			t.Errorf("q=%q: error = %+v", q, resp)
+		}
+		// Nothing is looked up
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestSearchScopesToTenantAndLimitsEachType(t *testing.T) {
+	h, mock := newMockSearchHandler(t)
+	customers, contracts, services := expectSearch(mock, "%ac\\_%", 2)
+	customers.WillReturnRows(summaryRows(7, "Acme_1", "Acme_2"))
+	// Matched on the customer name
+	contracts.WillReturnRows(summaryRows(1, "CTR-0001"))
+	services.WillReturnRows(summaryRows(0))
+
+	rec := httptest.NewRecorder()
+	h.Search(rec, searchRequest(url.Values{"q": {" ac_ "}, "limit": {"2"}}))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+	var resp struct {
+		Data models.SearchResponse `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	got := resp.Data
+	if got.Query != "ac_" {
+		t.Errorf("query = %q, want it trimmed", got.Query)
+	}
+	if got.Customers.Count != 7 || len(got.Customers.Results) != 2 || got.Customers.Results[0].Type != models.SearchTypeCustomer {
+		t.Errorf("customers = %+v, want 2 of 7", got.Customers)
+	}
+	if got.Contracts.Count != 1 || len(got.Contracts.Results) != 1 || got.Contracts.Results[0].Label != "CTR-0001" {
+		t.Errorf("contracts = %+v, want CTR-0001", got.Contracts)
+	}
+	if got.Services.Count != 0 || got.Services.Results == nil {
+		t.Errorf("services = %+v, want an empty list", got.Services)
+	}
+}
+
+func TestSearchLimits(t *testing.T) {
+	// Without limit each type returns the default number of hits
+	h, mock := newMockSearchHandler(t)
+	customers, contracts, services := expectSearch(mock, "%acme%", service.DefaultSearchLimit)
+	for _, q := range []*sqlmock.ExpectedQuery{customers, contracts, services} {
+		q.WillReturnRows(summaryRows(0))
+	}
+	rec := httptest.NewRecorder()
+	h.Search(rec, searchRequest(url.Values{"q": {"acme"}}))
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+
+	for _, limit := range []string{"0", "21", "-1", "five"} {
+		h, mock := newMockSearchHandler(t)
+		rec := httptest.NewRecorder()
+		h.Search(rec, searchRequest(url.Values{"q": {"acme"}, "limit": {limit}}))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("limit=%s: status = %d, want 400", limit, rec.Code)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestSearchFailsWhenALookupFails(t *testing.T) {
+	h, mock := newMockSearchHandler(t)
+	customers, contracts, services := expectSearch(mock, "%acme%", service.DefaultSearchLimit)
+	customers.WillReturnRows(summaryRows(0))
+	contracts.WillReturnError(errors.New("ORA-03113: end-of-file on communication channel"))
+	services.WillReturnRows(summaryRows(0))
+
+	rec := httptest.NewRecorder()
+	h.Search(rec, searchRequest(url.Values{"q": {"acme"}}))
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500: %s", rec.Code, rec.Body)
+	}
+	if resp := decodeError(t, rec); resp.Code != models.ErrCodeInternalError {
+		t.Errorf("error code = %s, want %s", resp.Code, models.ErrCodeInternalError)
+	}
+}
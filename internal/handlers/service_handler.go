@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 
@@ -29,7 +30,7 @@ func (h *ServiceHandler) List(w http.ResponseWriter, r *http.Request) {
 	services, total, err := h.svc.List(r.Context(), tenantID, params, search)
 	if err != nil {
 		log.Printf("failed to list services (tenant=%s): %v", tenantID, err)
-		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to list services")
+		writeServerError(w, err, "failed to list services")
 		return
 	}
 
@@ -47,18 +48,22 @@ func (h *ServiceHandler) Get(w http.ResponseWriter, r *http.Request) {
 	tenantID := middleware.GetTenantID(r.Context())
 	id, err := parseIDFromPath(r, "id")
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "INVALID_ID", "invalid service ID")
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidID, "invalid service ID")
 		return
 	}
 
 	svc, err := h.svc.GetByID(r.Context(), tenantID, id)
 	if err != nil {
+		if errors.Is(err, service.ErrServiceNotFound) {
+			writeError(w, http.StatusNotFound, models.ErrCodeNotFound, MsgServiceNotFound)
+			return
+		}
 		log.Printf("failed to get service (id=%d, tenant=%s): %v", id, tenantID, err)
-		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to get service")
+		writeServerError(w, err, "failed to get service")
 		return
 	}
 	if svc == nil {
-		writeError(w, http.StatusNotFound, "NOT_FOUND", "service not found")
+		writeError(w, http.StatusNotFound, models.ErrCodeNotFound, MsgServiceNotFound)
 		return
 	}
 
@@ -72,19 +77,19 @@ func (h *ServiceHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 	var req models.CreateServiceRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid request body")
+		writeDecodeError(w, err, models.ErrCodeInvalidRequest, "invalid request body")
 		return
 	}
 
-	if req.Name == "" || req.ServiceCode == "" {
-		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "name and service_code are required")
+	if problems := req.Validate(); len(problems) > 0 {
+		writeValidationError(w, problems...)
 		return
 	}
 
 	svc, err := h.svc.Create(r.Context(), tenantID, &req, user)
 	if err != nil {
 		log.Printf("failed to create service (tenant=%s): %v", tenantID, err)
-		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to create service")
+		writeServerError(w, err, "failed to create service")
 		return
 	}
 
@@ -97,24 +102,32 @@ func (h *ServiceHandler) Update(w http.ResponseWriter, r *http.Request) {
 	user := middleware.GetUser(r.Context())
 	id, err := parseIDFromPath(r, "id")
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "INVALID_ID", "invalid service ID")
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidID, "invalid service ID")
 		return
 	}
 
 	var req models.UpdateServiceRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid request body")
+		writeDecodeError(w, err, models.ErrCodeInvalidRequest, "invalid request body")
+		return
+	}
+	if problems := req.Validate(); len(problems) > 0 {
+		writeValidationError(w, problems...)
 		return
 	}
 
 	svc, err := h.svc.Update(r.Context(), tenantID, id, &req, user)
 	if err != nil {
+		if errors.Is(err, service.ErrServiceNotFound) {
+			writeError(w, http.StatusNotFound, models.ErrCodeNotFound, MsgServiceNotFound)
+			return
+		}
 		log.Printf("failed to update service (id=%d, tenant=%s): %v", id, tenantID, err)
-		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to update service")
+		writeServerError(w, err, "failed to update service")
 		return
 	}
 	if svc == nil {
-		writeError(w, http.StatusNotFound, "NOT_FOUND", "service not found")
+		writeError(w, http.StatusNotFound, models.ErrCodeNotFound, MsgServiceNotFound)
 		return
 	}
 
@@ -127,13 +140,17 @@ func (h *ServiceHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	user := middleware.GetUser(r.Context())
 	id, err := parseIDFromPath(r, "id")
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "INVALID_ID", "invalid service ID")
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidID, "invalid service ID")
 		return
 	}
 
 	if err := h.svc.Delete(r.Context(), tenantID, id, user); err != nil {
+		if errors.Is(err, service.ErrServiceNotFound) {
+			writeError(w, http.StatusNotFound, models.ErrCodeNotFound, MsgServiceNotFound)
+			return
+		}
 		log.Printf("failed to delete service (id=%d, tenant=%s): %v", id, tenantID, err)
-		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to delete service")
+		writeServerError(w, err, "failed to delete service")
 		return
 	}
 
@@ -147,7 +164,7 @@ func (h *ServiceHandler) GetCategories(w http.ResponseWriter, r *http.Request) {
 	categories, err := h.svc.GetCategories(r.Context(), tenantID)
 	if err != nil {
 		log.Printf("failed to get service categories (tenant=%s): %v", tenantID, err)
-		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to get categories")
+		writeServerError(w, err, "failed to get categories")
 		return
 	}
 
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/zlovtnik/gprint/internal/models"
+	"github.com/zlovtnik/gprint/internal/repository"
+	"github.com/zlovtnik/gprint/internal/service"
+	"github.com/zlovtnik/gprint/pkg/requestctx"
+)
+
+// newMockServiceHandler builds a ServiceHandler over a mocked database
+func newMockServiceHandler(t *testing.T) (*ServiceHandler, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	repo := repository.NewServiceRepository(repository.NewDB(db, repository.DBConfig{}, slog.New(slog.NewTextHandler(io.Discard, nil))))
+	return NewServiceHandler(service.NewServiceService(repo, nil)), mock
+}
+
+func TestServiceHandlerGetErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		expect func(q *sqlmock.ExpectedQuery)
+		status int
+		code   string
+	}{
+		{
+			"missing row is 404",
+			func(q *sqlmock.ExpectedQuery) { q.WillReturnRows(sqlmock.NewRows([]string{"id"})) },
+			http.StatusNotFound, models.ErrCodeNotFound,
+		},
+		{
+			"driver error is 500",
+			func(q *sqlmock.ExpectedQuery) {
+				q.WillReturnError(errors.New("ORA-03113: end-of-file on communication channel"))
+			},
+			http.StatusInternalServerError, models.ErrCodeInternalError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, mock := newMockServiceHandler(t)
+			tt.expect(mock.ExpectQuery(`FROM services WHERE tenant_id = :1 AND id = :2`).WithArgs("t1", int64(7)))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/services/7", nil)
+			req.SetPathValue("id", "7")
+			req = req.WithContext(requestctx.WithTenantID(req.Context(), "t1"))
+			rec := httptest.NewRecorder()
+			h.Get(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+			if resp := decodeError(t, rec); resp.Code != tt.code {
+				t.Errorf("error code = %s, want %s", resp.Code, tt.code)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/zlovtnik/gprint/internal/middleware"
+	"github.com/zlovtnik/gprint/internal/models"
+	"github.com/zlovtnik/gprint/internal/service"
+)
+
+// SettingsHandler handles the caller's tenant settings HTTP requests
+type SettingsHandler struct {
+	svc *service.TenantSettingsService
+}
+
+// NewSettingsHandler creates a new SettingsHandler
+// Panics if svc is nil to fail fast on misconfiguration
+func NewSettingsHandler(svc *service.TenantSettingsService) *SettingsHandler {
+	if svc == nil {
+		panic("NewSettingsHandler: svc (TenantSettingsService) must not be nil")
+	}
+	return &SettingsHandler{svc: svc}
+}
+
+// Get handles GET /api/v1/settings
+func (h *SettingsHandler) Get(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+
+	settings, err := h.svc.Get(r.Context(), tenantID)
+	if err != nil {
+		log.Printf("failed to get tenant settings: %v", err)
+		writeServerError(w, err, MsgInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, models.SuccessResponse(settings))
+}
+
+// Update handles PUT /api/v1/settings. Fields left out of the body fall
+// back to the server defaults.
+func (h *SettingsHandler) Update(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	user := middleware.GetUser(r.Context())
+
+	var req models.TenantSettingsOverrides
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeDecodeError(w, err, models.ErrCodeInvalidRequest, MsgInvalidRequestBody)
+		return
+	}
+	if problems := req.Validate(); len(problems) > 0 {
+		writeValidationError(w, problems...)
+		return
+	}
+
+	settings, err := h.svc.Update(r.Context(), tenantID, &req, user)
+	if err != nil {
+		log.Printf("failed to update tenant settings: %v", err)
+		writeServerError(w, err, MsgInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, models.SuccessResponse(settings))
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/zlovtnik/gprint/internal/middleware"
+	"github.com/zlovtnik/gprint/internal/models"
+	"github.com/zlovtnik/gprint/internal/service"
+)
+
+// TenantHandler handles tenant administration HTTP requests. Its routes act
+// on any tenant, not the caller's, so they are guarded by an admin role.
+type TenantHandler struct {
+	svc *service.TenantService
+}
+
+// NewTenantHandler creates a new TenantHandler
+// Panics if svc is nil to fail fast on misconfiguration
+func NewTenantHandler(svc *service.TenantService) *TenantHandler {
+	if svc == nil {
+		panic("NewTenantHandler: svc (TenantService) must not be nil")
+	}
+	return &TenantHandler{svc: svc}
+}
+
+// Provision handles POST /api/v1/admin/tenants. It responds 201 when any
+// step created something and 200 when the tenant was already provisioned.
+func (h *TenantHandler) Provision(w http.ResponseWriter, r *http.Request) {
+	user := middleware.GetUser(r.Context())
+
+	var req models.ProvisionTenantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeDecodeError(w, err, models.ErrCodeInvalidRequest, MsgInvalidRequestBody)
+		return
+	}
+	req.TenantID = strings.TrimSpace(req.TenantID)
+	req.DisplayName = strings.TrimSpace(req.DisplayName)
+	if problems := req.Validate(); len(problems) > 0 {
+		writeValidationError(w, problems...)
+		return
+	}
+
+	result, err := h.svc.Provision(r.Context(), &req, user)
+	if err != nil {
+		log.Printf("failed to provision tenant %s: %v", req.TenantID, err)
+		writeServerError(w, err, MsgInternalServerError)
+		return
+	}
+
+	status := http.StatusOK
+	for _, step := range result.Steps {
+		if step.Status == models.ProvisionCreated {
+			status = http.StatusCreated
+			break
+		}
+	}
+	writeJSON(w, status, models.SuccessResponse(result))
+}
+
+// Status handles GET /api/v1/admin/tenants/{id}/status
+func (h *TenantHandler) Status(w http.ResponseWriter, r *http.Request) {
+	tenantID := r.PathValue("id")
+
+	status, err := h.svc.Status(r.Context(), tenantID)
+	if err != nil {
+		log.Printf("failed to get tenant %s status: %v", tenantID, err)
+		writeServerError(w, err, MsgInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, models.SuccessResponse(status))
+}
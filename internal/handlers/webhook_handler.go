@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/zlovtnik/gprint/internal/middleware"
+	"github.com/zlovtnik/gprint/internal/models"
+	"github.com/zlovtnik/gprint/internal/service"
+)
+
+// webhookDeliveryLogLimit is the number of recent deliveries returned per webhook
+const webhookDeliveryLogLimit = 50
+
+// WebhookHandler handles webhook registration HTTP requests
+type WebhookHandler struct {
+	svc *service.WebhookService
+}
+
+// NewWebhookHandler creates a new WebhookHandler
+// Panics if svc is nil to fail fast on misconfiguration
+func NewWebhookHandler(svc *service.WebhookService) *WebhookHandler {
+	if svc == nil {
+		panic("NewWebhookHandler: svc (WebhookService) must not be nil")
+	}
+	return &WebhookHandler{svc: svc}
+}
+
+// List handles GET /api/v1/webhooks
+func (h *WebhookHandler) List(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+
+	webhooks, err := h.svc.List(r.Context(), tenantID)
+	if err != nil {
+		log.Printf("failed to list webhooks: %v", err)
+		writeServerError(w, err, MsgInternalServerError)
+		return
+	}
+
+	responses := make([]models.WebhookResponse, len(webhooks))
+	for i := range webhooks {
+		responses[i] = webhooks[i].ToResponse()
+	}
+	writeJSON(w, http.StatusOK, models.SuccessResponse(responses))
+}
+
+// Create handles POST /api/v1/webhooks. The response is the only place the
+// signing secret is returned.
+func (h *WebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	user := middleware.GetUser(r.Context())
+
+	var req models.CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeDecodeError(w, err, models.ErrCodeInvalidRequest, MsgInvalidRequestBody)
+		return
+	}
+
+	req.URL = strings.TrimSpace(req.URL)
+	if u, err := url.Parse(req.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		writeValidationError(w, models.FieldError{Field: "url", Rule: "url", Message: MsgInvalidWebhookURL})
+		return
+	}
+	if len(req.EventTypes) == 0 {
+		writeValidationError(w, models.FieldError{Field: "event_types", Rule: "oneof", Message: MsgInvalidWebhookEvents})
+		return
+	}
+	for _, event := range req.EventTypes {
+		if !event.IsValid() {
+			writeValidationError(w, models.FieldError{Field: "event_types", Rule: "oneof", Message: MsgInvalidWebhookEvents})
+			return
+		}
+	}
+
+	webhook, err := h.svc.Create(r.Context(), tenantID, &req, user)
+	if err != nil {
+		log.Printf("failed to create webhook: %v", err)
+		writeServerError(w, err, MsgInternalServerError)
+		return
+	}
+
+	resp := webhook.ToResponse()
+	resp.Secret = webhook.Secret
+	writeJSON(w, http.StatusCreated, models.SuccessResponse(resp))
+}
+
+// Delete handles DELETE /api/v1/webhooks/{id}
+func (h *WebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	id, err := parseIDFromPath(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidID, MsgInvalidWebhookID)
+		return
+	}
+
+	if err := h.svc.Delete(r.Context(), tenantID, id); err != nil {
+		if errors.Is(err, service.ErrWebhookNotFound) {
+			writeError(w, http.StatusNotFound, models.ErrCodeNotFound, MsgWebhookNotFound)
+			return
+		}
+		log.Printf("failed to delete webhook: %v", err)
+		writeServerError(w, err, MsgInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, models.SuccessResponse(nil))
+}
+
+// ListDeliveries handles GET /api/v1/webhooks/{id}/deliveries
+func (h *WebhookHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r.Context())
+	id, err := parseIDFromPath(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, models.ErrCodeInvalidID, MsgInvalidWebhookID)
+		return
+	}
+
+	deliveries, err := h.svc.ListDeliveries(r.Context(), tenantID, id, webhookDeliveryLogLimit)
+	if err != nil {
+		if errors.Is(err, service.ErrWebhookNotFound) {
+			writeError(w, http.StatusNotFound, models.ErrCodeNotFound, MsgWebhookNotFound)
+			return
+		}
+		log.Printf("failed to list webhook deliveries: %v", err)
+		writeServerError(w, err, MsgInternalServerError)
+		return
+	}
+	if deliveries == nil {
+		deliveries = []models.WebhookDelivery{}
+	}
+
+	writeJSON(w, http.StatusOK, models.SuccessResponse(deliveries))
+}
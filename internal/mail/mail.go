@@ -0,0 +1,237 @@
+// Package mail sends notification emails over SMTP.
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TLS modes for SMTPConfig.TLSMode
+const (
+	TLSNone     = "none"     // plain connection; only suitable for local relays
+	TLSStartTLS = "starttls" // upgrade a plain connection, usually port 587
+	TLSImplicit = "tls"      // TLS from the first byte, usually port 465
+)
+
+// base64LineLength is the maximum encoded line length allowed by RFC 2045
+const base64LineLength = 76
+
+// Attachment is a file sent with a message
+type Attachment struct {
+	FileName    string
+	ContentType string
+	Data        []byte
+}
+
+// Message is a plain-text email with optional attachments
+type Message struct {
+	To          []string
+	Subject     string
+	Body        string
+	Attachments []Attachment
+}
+
+// Sender delivers messages
+type Sender interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// SMTPConfig configures an SMTPSender
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string // empty disables authentication
+	Password string
+	From     string
+	TLSMode  string // TLSNone, TLSStartTLS or TLSImplicit; defaults to TLSStartTLS
+	Timeout  time.Duration
+}
+
+// SMTPSender sends messages through an SMTP relay
+type SMTPSender struct {
+	cfg SMTPConfig
+}
+
+// NewSMTPSender validates cfg and creates an SMTPSender
+func NewSMTPSender(cfg SMTPConfig) (*SMTPSender, error) {
+	if cfg.Host == "" {
+		return nil, errors.New("smtp: host is required")
+	}
+	if cfg.From == "" {
+		return nil, errors.New("smtp: from address is required")
+	}
+	if cfg.Port <= 0 {
+		cfg.Port = 587
+	}
+	switch cfg.TLSMode {
+	case "":
+		cfg.TLSMode = TLSStartTLS
+	case TLSNone, TLSStartTLS, TLSImplicit:
+	default:
+		return nil, fmt.Errorf("smtp: unknown TLS mode %q", cfg.TLSMode)
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+	return &SMTPSender{cfg: cfg}, nil
+}
+
+// Send implements Sender
+func (s *SMTPSender) Send(ctx context.Context, msg *Message) error {
+	if len(msg.To) == 0 {
+		return errors.New("smtp: message has no recipients")
+	}
+	body, err := s.build(msg)
+	if err != nil {
+		return err
+	}
+
+	client, err := s.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if s.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)); err != nil {
+			return fmt.Errorf("smtp: authentication failed: %w", err)
+		}
+	}
+	if err := client.Mail(s.cfg.From); err != nil {
+		return fmt.Errorf("smtp: MAIL FROM rejected: %w", err)
+	}
+	for _, to := range msg.To {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("smtp: recipient %s rejected: %w", to, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp: DATA rejected: %w", err)
+	}
+	if _, err := w.Write(body); err != nil {
+		w.Close()
+		return fmt.Errorf("smtp: failed to write message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("smtp: message rejected: %w", err)
+	}
+	return client.Quit()
+}
+
+// dial connects to the relay and negotiates TLS according to the configured mode
+func (s *SMTPSender) dial(ctx context.Context) (*smtp.Client, error) {
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+	dialer := &net.Dialer{Timeout: s.cfg.Timeout}
+	tlsConfig := &tls.Config{ServerName: s.cfg.Host, MinVersion: tls.VersionTLS12}
+
+	var conn net.Conn
+	var err error
+	if s.cfg.TLSMode == TLSImplicit {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("smtp: failed to connect to %s: %w", addr, err)
+	}
+
+	// net/smtp has no context support; bound the whole exchange with a deadline instead
+	deadline := time.Now().Add(s.cfg.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("smtp: failed to set deadline: %w", err)
+	}
+
+	client, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("smtp: handshake failed: %w", err)
+	}
+	if s.cfg.TLSMode == TLSStartTLS {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("smtp: STARTTLS failed: %w", err)
+		}
+	}
+	return client, nil
+}
+
+// build renders msg as an RFC 5322 message, using multipart/mixed when it has attachments
+func (s *SMTPSender) build(msg *Message) ([]byte, error) {
+	var buf bytes.Buffer
+	header := func(k, v string) { fmt.Fprintf(&buf, "%s: %s\r\n", k, v) }
+
+	header("From", s.cfg.From)
+	header("To", strings.Join(msg.To, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+
+	if len(msg.Attachments) == 0 {
+		header("Content-Type", "text/plain; charset=utf-8")
+		header("Content-Transfer-Encoding", "base64")
+		buf.WriteString("\r\n")
+		writeBase64(&buf, []byte(msg.Body))
+		return buf.Bytes(), nil
+	}
+
+	mw := multipart.NewWriter(&buf)
+	header("Content-Type", mime.FormatMediaType("multipart/mixed", map[string]string{"boundary": mw.Boundary()}))
+	buf.WriteString("\r\n")
+
+	part, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/plain; charset=utf-8"},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("smtp: failed to build message: %w", err)
+	}
+	writeBase64(part, []byte(msg.Body))
+
+	for _, a := range msg.Attachments {
+		contentType := a.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		part, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {contentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": a.FileName})},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("smtp: failed to build message: %w", err)
+		}
+		writeBase64(part, a.Data)
+	}
+	if err := mw.Close(); err != nil {
+		return nil, fmt.Errorf("smtp: failed to build message: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// writeBase64 writes data base64-encoded in CRLF-terminated lines
+func writeBase64(w io.Writer, data []byte) {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > base64LineLength {
+		w.Write([]byte(encoded[:base64LineLength] + "\r\n"))
+		encoded = encoded[base64LineLength:]
+	}
+	w.Write([]byte(encoded + "\r\n"))
+}
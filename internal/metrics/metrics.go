@@ -0,0 +1,182 @@
+// Package metrics defines the application's Prometheus metrics. Everything is
+// registered on Registry, which the /metrics endpoint serves through Handler.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/zlovtnik/gprint/internal/models"
+)
+
+// Registry holds all gprint metrics, plus the Go runtime and process collectors
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Handler serves Registry for Prometheus to scrape
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+var (
+	// HTTPRequests counts requests by route pattern (never the raw URL), method and status
+	HTTPRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "gprint_http_requests_total",
+		Help: "HTTP requests handled, by route pattern, method and status code.",
+	}, []string{"route", "method", "status"})
+	// HTTPDuration observes request latency by route pattern and method
+	HTTPDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "gprint_http_request_duration_seconds",
+		Help:    "HTTP request latency in seconds, by route pattern and method.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route", "method"})
+	// HTTPPanics counts handler panics recovered by the recovery middleware
+	HTTPPanics = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "gprint_http_panics_total",
+		Help: "Panics recovered while handling HTTP requests, by route pattern.",
+	}, []string{"route"})
+	// HTTPRateLimited counts requests rejected by the rate limiter
+	HTTPRateLimited = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "gprint_http_rate_limited_total",
+		Help: "Requests rejected with 429 by the rate limiter, by route group.",
+	}, []string{"group"})
+
+	// DBConnections reports database pool connections by state
+	DBConnections = factory.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "gprint_db_connections",
+		Help: "Database pool connections by state (open, in_use, idle, max_open).",
+	}, []string{"state"})
+	// DBWaits reports the cumulative number of waits for a free connection
+	DBWaits = factory.NewGauge(prometheus.GaugeOpts{
+		Name: "gprint_db_wait_count",
+		Help: "Total number of times a query waited for a free database connection.",
+	})
+	// DBWaitSeconds reports the cumulative time spent waiting for a free connection
+	DBWaitSeconds = factory.NewGauge(prometheus.GaugeOpts{
+		Name: "gprint_db_wait_duration_seconds",
+		Help: "Total time spent waiting for a free database connection, in seconds.",
+	})
+	// DBSlowQueries counts statements that exceeded the slow query threshold
+	DBSlowQueries = factory.NewCounter(prometheus.CounterOpts{
+		Name: "gprint_db_slow_queries_total",
+		Help: "Database statements that took longer than the slow query threshold.",
+	})
+	// DBQueryTimeouts counts statements cancelled by the statement timeout
+	DBQueryTimeouts = factory.NewCounter(prometheus.CounterOpts{
+		Name: "gprint_db_query_timeouts_total",
+		Help: "Database statements cancelled because they exceeded the statement timeout.",
+	})
+
+	// PrintJobs reports print jobs by status across all tenants
+	PrintJobs = factory.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "gprint_print_jobs",
+		Help: "Print jobs by status across all tenants, refreshed periodically.",
+	}, []string{"status"})
+
+	// GenerationCalls counts contract generation service calls by operation and result
+	GenerationCalls = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "gprint_contract_generation_calls_total",
+		Help: "Contract generation calls, by operation and result (success or error).",
+	}, []string{"operation", "result"})
+	// GenerationCleanupLastRun reports when this instance last ran the expired generation cleanup
+	GenerationCleanupLastRun = factory.NewGauge(prometheus.GaugeOpts{
+		Name: "gprint_generation_cleanup_last_run_timestamp_seconds",
+		Help: "Unix time this instance last completed the expired generated contract cleanup.",
+	})
+	// GenerationCleanupLastDeleted reports the generated contracts removed by that run
+	GenerationCleanupLastDeleted = factory.NewGauge(prometheus.GaugeOpts{
+		Name: "gprint_generation_cleanup_last_deleted",
+		Help: "Expired generated contracts deleted by the last cleanup run on this instance.",
+	})
+	// GenerationCleanupDeleted counts generated contracts removed by the cleanup
+	GenerationCleanupDeleted = factory.NewCounter(prometheus.CounterOpts{
+		Name: "gprint_generation_cleanup_deleted_total",
+		Help: "Expired generated contracts deleted by the cleanup on this instance.",
+	})
+
+	// JobRuns counts background job runs by job and result
+	JobRuns = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "gprint_job_runs_total",
+		Help: "Background job runs on this instance, by job and result (success or error).",
+	}, []string{"job", "result"})
+	// JobDuration observes background job run time by job
+	JobDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "gprint_job_duration_seconds",
+		Help:    "Background job run time in seconds, by job.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"job"})
+	// JobLastSuccess reports when each background job last succeeded
+	JobLastSuccess = factory.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "gprint_job_last_success_timestamp_seconds",
+		Help: "Unix time each background job last completed without error on this instance.",
+	}, []string{"job"})
+)
+
+// printJobStatuses are always exported, as zero when no job has the status
+var printJobStatuses = []models.PrintJobStatus{
+	models.PrintJobStatusQueued,
+	models.PrintJobStatusProcessing,
+	models.PrintJobStatusCompleted,
+	models.PrintJobStatusFailed,
+	models.PrintJobStatusDead,
+	models.PrintJobStatusCancelled,
+	models.PrintJobStatusPurged,
+}
+
+// ObserveDBStats updates the database pool gauges from a sql.DB.Stats sample
+func ObserveDBStats(stats sql.DBStats) {
+	DBConnections.WithLabelValues("open").Set(float64(stats.OpenConnections))
+	DBConnections.WithLabelValues("in_use").Set(float64(stats.InUse))
+	DBConnections.WithLabelValues("idle").Set(float64(stats.Idle))
+	DBConnections.WithLabelValues("max_open").Set(float64(stats.MaxOpenConnections))
+	DBWaits.Set(float64(stats.WaitCount))
+	DBWaitSeconds.Set(stats.WaitDuration.Seconds())
+}
+
+// SetPrintJobCounts updates the print job gauges from per-status counts
+func SetPrintJobCounts(counts map[models.PrintJobStatus]int64) {
+	for _, status := range printJobStatuses {
+		PrintJobs.WithLabelValues(string(status)).Set(float64(counts[status]))
+	}
+}
+
+// RecordGeneration counts one contract generation call
+func RecordGeneration(operation string, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	GenerationCalls.WithLabelValues(operation, result).Inc()
+}
+
+// RecordGenerationCleanup records a completed expired generation cleanup run
+func RecordGenerationCleanup(at time.Time, deleted int) {
+	GenerationCleanupLastRun.Set(float64(at.Unix()))
+	GenerationCleanupLastDeleted.Set(float64(deleted))
+	GenerationCleanupDeleted.Add(float64(deleted))
+}
+
+// RecordJobRun records one background job run that finished at finished
+func RecordJobRun(job string, took time.Duration, finished time.Time, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	} else {
+		JobLastSuccess.WithLabelValues(job).Set(float64(finished.Unix()))
+	}
+	JobRuns.WithLabelValues(job, result).Inc()
+	JobDuration.WithLabelValues(job).Observe(took.Seconds())
+}
@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/zlovtnik/gprint/internal/models"
+)
+
+func TestRecordJobRun(t *testing.T) {
+	finished := time.Unix(1700000000, 0)
+	RecordJobRun("purge", 30*time.Millisecond, finished, nil)
+	RecordJobRun("purge", 2*time.Second, finished.Add(time.Minute), errors.New("boom"))
+
+	want := `
+# HELP gprint_job_runs_total Background job runs on this instance, by job and result (success or error).
+# TYPE gprint_job_runs_total counter
+gprint_job_runs_total{job="purge",result="error"} 1
+gprint_job_runs_total{job="purge",result="success"} 1
+# HELP gprint_job_duration_seconds Background job run time in seconds, by job.
+# TYPE gprint_job_duration_seconds histogram
+gprint_job_duration_seconds_bucket{job="purge",le="0.005"} 0
+gprint_job_duration_seconds_bucket{job="purge",le="0.01"} 0
+gprint_job_duration_seconds_bucket{job="purge",le="0.025"} 0
+gprint_job_duration_seconds_bucket{job="purge",le="0.05"} 1
+gprint_job_duration_seconds_bucket{job="purge",le="0.1"} 1
+gprint_job_duration_seconds_bucket{job="purge",le="0.25"} 1
+gprint_job_duration_seconds_bucket{job="purge",le="0.5"} 1
+gprint_job_duration_seconds_bucket{job="purge",le="1"} 1
+gprint_job_duration_seconds_bucket{job="purge",le="2.5"} 2
+gprint_job_duration_seconds_bucket{job="purge",le="5"} 2
+gprint_job_duration_seconds_bucket{job="purge",le="10"} 2
+gprint_job_duration_seconds_bucket{job="purge",le="+Inf"} 2
+gprint_job_duration_seconds_sum{job="purge"} 2.03
+gprint_job_duration_seconds_count{job="purge"} 2
+# HELP gprint_job_last_success_timestamp_seconds Unix time each background job last completed without error on this instance.
+# TYPE gprint_job_last_success_timestamp_seconds gauge
+gprint_job_last_success_timestamp_seconds{job="purge"} 1.7e+09
+`
+	err := testutil.GatherAndCompare(Registry, strings.NewReader(want),
+		"gprint_job_runs_total", "gprint_job_duration_seconds", "gprint_job_last_success_timestamp_seconds")
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSetPrintJobCountsExportsEveryStatus(t *testing.T) {
+	SetPrintJobCounts(map[models.PrintJobStatus]int64{models.PrintJobStatusQueued: 3})
+
+	if n := testutil.CollectAndCount(PrintJobs); n != len(printJobStatuses) {
+		t.Errorf("exported %d print job series, want %d", n, len(printJobStatuses))
+	}
+	if v := testutil.ToFloat64(PrintJobs.WithLabelValues(string(models.PrintJobStatusQueued))); v != 3 {
+		t.Errorf("queued = %v, want 3", v)
+	}
+	if v := testutil.ToFloat64(PrintJobs.WithLabelValues(string(models.PrintJobStatusDead))); v != 0 {
+		t.Errorf("dead = %v, want 0", v)
+	}
+}
+
+func TestHandlerServesTextFormat(t *testing.T) {
+	HTTPRequests.WithLabelValues("GET /api/v1/contracts/{id}", "GET", "200").Inc()
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q, want the text exposition format", ct)
+	}
+	body, _ := io.ReadAll(rec.Body)
+	for _, want := range []string{
+		"# TYPE gprint_http_requests_total counter",
+		`gprint_http_requests_total{method="GET",route="GET /api/v1/contracts/{id}",status="200"} 1`,
+		"# TYPE go_goroutines gauge",
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("exposition missing %q", want)
+		}
+	}
+}
@@ -2,19 +2,19 @@ package middleware
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
 
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/zlovtnik/gprint/internal/models"
 	"github.com/zlovtnik/gprint/pkg/auth"
+	"github.com/zlovtnik/gprint/pkg/requestctx"
 )
 
-type contextKey string
-
 const (
-	contextKeyTenantID contextKey = "tenant_id"
-	contextKeyUser     contextKey = "user"
-	contextKeyClaims   contextKey = "claims"
-
 	// HTTP header constants
 	headerContentType = "Content-Type"
 	contentTypeJSON   = "application/json"
@@ -26,58 +26,87 @@ type UserClaims = auth.Claims
 
 // AuthMiddleware validates JWT tokens.
 // This is a thin HTTP wrapper that delegates token validation to pkg/auth.
-func AuthMiddleware(jwtSecret string) func(http.Handler) http.Handler {
+func AuthMiddleware(verifier *auth.Verifier) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			authHeader := r.Header.Get("Authorization")
 			if authHeader == "" {
-				w.Header().Set(headerContentType, contentTypeJSON)
-				w.WriteHeader(http.StatusUnauthorized)
-				_, _ = w.Write([]byte(`{"error":"missing authorization header"}`))
+				writeAPIError(w, r, http.StatusUnauthorized, models.ErrCodeUnauthorized, "missing authorization header")
 				return
 			}
 
 			parts := strings.Split(authHeader, " ")
 			if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
-				w.Header().Set(headerContentType, contentTypeJSON)
-				w.WriteHeader(http.StatusUnauthorized)
-				_, _ = w.Write([]byte(`{"error":"invalid authorization header format"}`))
+				writeAPIError(w, r, http.StatusUnauthorized, models.ErrCodeUnauthorized, "invalid authorization header format")
 				return
 			}
 
 			tokenString := parts[1]
-			claims, err := auth.ValidateToken(tokenString, jwtSecret)
+			claims, err := verifier.Verify(r.Context(), tokenString)
+			if errors.Is(err, auth.ErrClientLookup) {
+				requestctx.Logger(r.Context()).Error("failed to look up service client", "error", err)
+				writeAPIError(w, r, http.StatusInternalServerError, models.ErrCodeInternalError, "internal server error")
+				return
+			}
 			if err != nil {
-				w.Header().Set(headerContentType, contentTypeJSON)
-				w.WriteHeader(http.StatusUnauthorized)
-				_, _ = w.Write([]byte(`{"error":"invalid token"}`))
+				code, msg := tokenError(err)
+				writeAPIError(w, r, http.StatusUnauthorized, code, msg)
 				return
 			}
 
 			// Add claims to context
-			ctx := context.WithValue(r.Context(), contextKeyTenantID, claims.TenantID)
-			ctx = context.WithValue(ctx, contextKeyUser, claims.User)
-			ctx = context.WithValue(ctx, contextKeyClaims, claims)
+			ctx := requestctx.WithTenantID(r.Context(), claims.TenantID)
+			ctx = requestctx.WithUser(ctx, claims.User)
+			ctx = requestctx.WithClaims(ctx, claims)
+			ctx = requestctx.WithRoles(ctx, claims.Roles(verifier.Config().Audience))
+			ctx = requestctx.WithLogger(ctx, requestctx.Logger(ctx).With("tenant_id", claims.TenantID))
+			if info := getRequestInfo(ctx); info != nil {
+				info.tenantID = claims.TenantID
+			}
 
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
 
+// tokenError maps a verification failure to an error code and message.
+// Signature and format problems stay a generic "invalid token".
+func tokenError(err error) (code, msg string) {
+	switch {
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return models.ErrCodeTokenExpired, "token has expired"
+	case errors.Is(err, jwt.ErrTokenNotValidYet), errors.Is(err, jwt.ErrTokenUsedBeforeIssued):
+		return models.ErrCodeUnauthorized, "token is not valid yet"
+	case errors.Is(err, jwt.ErrTokenInvalidIssuer):
+		return models.ErrCodeInvalidIssuer, "token was issued by another realm"
+	case errors.Is(err, auth.ErrInvalidAudience):
+		return models.ErrCodeInvalidAudience, "token was issued for another client"
+	case errors.Is(err, auth.ErrMissingTenant):
+		return models.ErrCodeMissingTenant, "token has no tenant"
+	default:
+		return models.ErrCodeUnauthorized, "invalid token"
+	}
+}
+
+// writeAPIError writes an error in the models.APIResponse envelope used by
+// the handlers, tagged with the request ID
+func writeAPIError(w http.ResponseWriter, r *http.Request, status int, code, message string) {
+	resp := models.ErrorResponse(code, message, nil)
+	resp.Error.RequestID = GetRequestID(r.Context())
+	body, _ := json.Marshal(resp)
+	w.Header().Set(headerContentType, contentTypeJSON)
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
+
 // GetTenantID retrieves the tenant ID from context
 func GetTenantID(ctx context.Context) string {
-	if v := ctx.Value(contextKeyTenantID); v != nil {
-		return v.(string)
-	}
-	return ""
+	return requestctx.TenantID(ctx)
 }
 
 // GetUser retrieves the user from context
 func GetUser(ctx context.Context) string {
-	if v := ctx.Value(contextKeyUser); v != nil {
-		return v.(string)
-	}
-	return ""
+	return requestctx.User(ctx)
 }
 
 // GetUserID is an alias for GetUser for API consistency
@@ -85,10 +114,12 @@ func GetUserID(ctx context.Context) string {
 	return GetUser(ctx)
 }
 
+// GetRoles retrieves the user's realm and client roles from context
+func GetRoles(ctx context.Context) []string {
+	return requestctx.Roles(ctx)
+}
+
 // GetUserClaims retrieves the full claims from context
 func GetUserClaims(ctx context.Context) *UserClaims {
-	if v := ctx.Value(contextKeyClaims); v != nil {
-		return v.(*UserClaims)
-	}
-	return nil
+	return requestctx.Claims(ctx)
 }
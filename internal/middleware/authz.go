@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/zlovtnik/gprint/internal/models"
+	"github.com/zlovtnik/gprint/pkg/requestctx"
+)
+
+// RoleConfig holds role enforcement settings
+type RoleConfig struct {
+	// Enforce rejects requests missing a required role. When false the
+	// request is let through and the missing role only logged, so existing
+	// single-role deployments can assign roles before turning it on.
+	Enforce bool
+}
+
+// RequireRole rejects requests whose token lacks role with 403. It must run
+// inside the auth middleware, which puts the roles in the context.
+func RequireRole(cfg RoleConfig, role string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if HasRole(cfg, r, role) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			writeAPIError(w, r, http.StatusForbidden, models.ErrCodeForbidden, "missing required role "+role)
+		})
+	}
+}
+
+// HasRole reports whether the request's token has role, for handlers whose
+// permission depends on the resource. With enforcement off it reports true,
+// logging the missing role as RequireRole does.
+func HasRole(cfg RoleConfig, r *http.Request, role string) bool {
+	if slices.Contains(GetRoles(r.Context()), role) {
+		return true
+	}
+	if !cfg.Enforce {
+		requestctx.Logger(r.Context()).Warn("request is missing a role; allowed because role enforcement is off",
+			"role", role,
+			"user", GetUser(r.Context()),
+			"method", r.Method,
+			"path", r.URL.Path,
+		)
+		return true
+	}
+	return false
+}
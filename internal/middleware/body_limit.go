@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"mime"
+	"net/http"
+
+	"github.com/zlovtnik/gprint/internal/models"
+)
+
+// BodyLimitConfig holds request body size limits
+type BodyLimitConfig struct {
+	MaxBytes       int64 // Limit for ordinary requests, such as JSON bodies
+	MaxUploadBytes int64 // Limit for multipart/form-data uploads
+}
+
+// BodyLimitMiddleware caps request bodies with http.MaxBytesReader. Requests
+// that declare a larger Content-Length are rejected with 413 up front; bodies
+// that only turn out too large while being read fail with an
+// *http.MaxBytesError, which handlers report as 413.
+func BodyLimitMiddleware(cfg BodyLimitConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			limit := cfg.MaxBytes
+			if isMultipart(r) {
+				limit = cfg.MaxUploadBytes
+			}
+			if r.ContentLength > limit {
+				writeAPIError(w, r, http.StatusRequestEntityTooLarge, models.ErrCodeTooLarge, "request body too large")
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// isMultipart reports whether the request carries a multipart/form-data upload
+func isMultipart(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get(headerContentType))
+	return err == nil && mediaType == "multipart/form-data"
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/zlovtnik/gprint/internal/models"
+	"github.com/zlovtnik/gprint/pkg/requestctx"
+)
+
+// FeatureChecker reports whether a feature is enabled for a tenant
+type FeatureChecker interface {
+	IsEnabled(ctx context.Context, tenantID, feature string) (bool, error)
+}
+
+// RequireFeature answers 404 to tenants that do not have feature enabled,
+// so its routes look absent to them. It must run inside the auth
+// middleware, which puts the tenant in the context.
+func RequireFeature(flags FeatureChecker, feature string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			enabled, err := flags.IsEnabled(r.Context(), GetTenantID(r.Context()), feature)
+			if err != nil {
+				requestctx.Logger(r.Context()).Error("failed to check feature flag", "feature", feature, "error", err)
+				writeAPIError(w, r, http.StatusInternalServerError, models.ErrCodeInternalError, "internal server error")
+				return
+			}
+			if !enabled {
+				writeAPIError(w, r, http.StatusNotFound, models.ErrCodeNotFound, "not found")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
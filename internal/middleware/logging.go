@@ -1,16 +1,20 @@
 package middleware
 
 import (
+	"context"
 	"log/slog"
 	"net/http"
 	"time"
+
+	"github.com/zlovtnik/gprint/pkg/requestctx"
 )
 
-// responseWriter wraps http.ResponseWriter to capture status code
+// responseWriter wraps http.ResponseWriter to capture status code and bytes written
 type responseWriter struct {
 	http.ResponseWriter
 	statusCode    int
 	headerWritten bool
+	bytes         int64
 }
 
 func newResponseWriter(w http.ResponseWriter) *responseWriter {
@@ -27,19 +31,63 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
-// LoggingMiddleware logs HTTP requests
+func (rw *responseWriter) Write(b []byte) (int, error) {
+	rw.headerWritten = true
+	n, err := rw.ResponseWriter.Write(b)
+	rw.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap exposes the underlying writer so http.ResponseController can flush streams
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// requestInfoKey is the context key of the per-request requestInfo
+type requestInfoKey struct{}
+
+// requestInfo collects details that only inner handlers learn, such as the
+// matched route and the authenticated tenant, for outer middleware to report.
+// Inner middleware replaces the request with copies, so the details are
+// written through a pointer shared via the context.
+type requestInfo struct {
+	route    string
+	tenantID string
+}
+
+// withRequestInfo adds an empty requestInfo to the request context, reusing
+// one added by an outer middleware
+func withRequestInfo(r *http.Request) (*http.Request, *requestInfo) {
+	if info := getRequestInfo(r.Context()); info != nil {
+		return r, info
+	}
+	info := &requestInfo{}
+	return r.WithContext(context.WithValue(r.Context(), requestInfoKey{}, info)), info
+}
+
+func getRequestInfo(ctx context.Context) *requestInfo {
+	info, _ := ctx.Value(requestInfoKey{}).(*requestInfo)
+	return info
+}
+
+// LoggingMiddleware logs one line per HTTP request through the request-scoped
+// logger, falling back to logger outside RequestIDMiddleware
 func LoggingMiddleware(logger *slog.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
+			r, info := withRequestInfo(r)
 			wrapped := newResponseWriter(w)
 
 			next.ServeHTTP(wrapped, r)
 
-			logger.Info("HTTP request",
+			requestctx.LoggerOr(r.Context(), logger).Info("HTTP request",
 				"method", r.Method,
+				"route", routeLabel(info.route),
 				"path", r.URL.Path,
 				"status", wrapped.statusCode,
+				"bytes", wrapped.bytes,
+				"tenant_id", info.tenantID,
 				"duration", time.Since(start),
 				"remote_addr", r.RemoteAddr,
 			)
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zlovtnik/gprint/internal/metrics"
+)
+
+// unmatchedRoute labels requests that did not match any registered pattern
+const unmatchedRoute = "unmatched"
+
+// knownMethods bounds the method label; anything else is reported as OTHER
+var knownMethods = map[string]bool{
+	http.MethodGet: true, http.MethodHead: true, http.MethodPost: true, http.MethodPut: true,
+	http.MethodPatch: true, http.MethodDelete: true, http.MethodOptions: true,
+}
+
+// CaptureRoute wraps the ServeMux so outer middleware can see which pattern
+// matched. The mux records the pattern on the request it is given, which
+// outer middleware never sees because auth replaces the request with a copy.
+func CaptureRoute(mux http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Deferred so the route is known to the recovery middleware after a panic
+		defer func() {
+			if info := getRequestInfo(r.Context()); info != nil {
+				info.route = r.Pattern
+			}
+		}()
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// routeLabel turns a mux pattern such as "GET /api/v1/customers/{id}" into
+// its path part, keeping label cardinality bounded by the route table
+func routeLabel(pattern string) string {
+	if pattern == "" {
+		return unmatchedRoute
+	}
+	if _, path, ok := strings.Cut(pattern, " "); ok {
+		return path
+	}
+	return pattern
+}
+
+// MetricsMiddleware records request counts and latencies labelled by the
+// matched route pattern. The mux must be wrapped with CaptureRoute.
+func MetricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		r, info := withRequestInfo(r)
+		wrapped := newResponseWriter(w)
+
+		next.ServeHTTP(wrapped, r)
+
+		method := r.Method
+		if !knownMethods[method] {
+			method = "OTHER"
+		}
+		label := routeLabel(info.route)
+		metrics.HTTPRequests.WithLabelValues(label, method, strconv.Itoa(wrapped.statusCode)).Inc()
+		metrics.HTTPDuration.WithLabelValues(label, method).Observe(time.Since(start).Seconds())
+	})
+}
@@ -4,26 +4,61 @@ import (
 	"log/slog"
 	"net/http"
 	"runtime/debug"
+
+	"github.com/zlovtnik/gprint/internal/metrics"
+	"github.com/zlovtnik/gprint/internal/models"
+	"github.com/zlovtnik/gprint/pkg/requestctx"
 )
 
-// RecoveryMiddleware recovers from panics and logs the error
+// RecoveryMiddleware recovers from panics in later middleware and handlers.
+// It logs the stack through the request logger, counts the panic and replies
+// with a 500 in the standard error envelope. http.ErrAbortHandler is re-raised
+// so net/http aborts the response quietly, as the convention requires.
 func RecoveryMiddleware(logger *slog.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r, info := withRequestInfo(r)
+			wrapped := newResponseWriter(w)
 			defer func() {
-				if err := recover(); err != nil {
-					logger.Error("panic recovered",
-						"error", err,
-						"stack", string(debug.Stack()),
-						"path", r.URL.Path,
-						"method", r.Method,
-					)
-					w.Header().Set("Content-Type", "application/json")
-					w.WriteHeader(http.StatusInternalServerError)
-					_, _ = w.Write([]byte(`{"error":"internal server error"}`))
+				err := recover()
+				if err == nil {
+					return
+				}
+				if err == http.ErrAbortHandler {
+					panic(err)
+				}
+
+				metrics.HTTPPanics.WithLabelValues(routeLabel(info.route)).Inc()
+				r := withResponseRequestID(r, w, logger)
+				requestctx.LoggerOr(r.Context(), logger).Error("panic recovered",
+					"error", err,
+					"stack", string(debug.Stack()),
+					"method", r.Method,
+					"path", r.URL.Path,
+				)
+
+				// A partly written response cannot be turned into an error;
+				// abort it so the client does not mistake it for a success
+				if wrapped.headerWritten {
+					panic(http.ErrAbortHandler)
 				}
+				writeAPIError(w, r, http.StatusInternalServerError, models.ErrCodeInternalError, "internal server error")
 			}()
-			next.ServeHTTP(w, r)
+			next.ServeHTTP(wrapped, r)
 		})
 	}
 }
+
+// withResponseRequestID returns r carrying the request ID and request logger
+// of the response. Recovery is the outermost middleware, so the ID that
+// RequestIDMiddleware put in the context is gone by the time a panic reaches
+// it; the ID it set on the response header is not.
+func withResponseRequestID(r *http.Request, w http.ResponseWriter, logger *slog.Logger) *http.Request {
+	id := w.Header().Get(RequestIDHeader)
+	if id == "" || GetRequestID(r.Context()) != "" {
+		return r
+	}
+	ctx := requestctx.WithTraceID(r.Context(), id)
+	ctx = requestctx.WithLogger(ctx, logger.With("request_id", id))
+	return r.WithContext(ctx)
+}
@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/zlovtnik/gprint/internal/metrics"
+	"github.com/zlovtnik/gprint/internal/models"
+)
+
+// recoveryChain wraps h the way the server does, with recovery outside
+// request IDs, logging JSON to the returned buffer
+func recoveryChain(h http.HandlerFunc) (http.Handler, *bytes.Buffer) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, nil))
+	return RecoveryMiddleware(logger)(RequestIDMiddleware(logger)(h)), &logs
+}
+
+func TestRecoveryMiddlewareReturnsErrorEnvelope(t *testing.T) {
+	handler, logs := recoveryChain(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+	panics := metrics.HTTPPanics.WithLabelValues(unmatchedRoute)
+	before := testutil.ToFloat64(panics)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/contracts", nil)
+	req.Header.Set(RequestIDHeader, "req-123")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", rec.Code)
+	}
+	if ct := rec.Header().Get(headerContentType); ct != contentTypeJSON {
+		t.Errorf("Content-Type = %q, want %q", ct, contentTypeJSON)
+	}
+	if id := rec.Header().Get(RequestIDHeader); id != "req-123" {
+		t.Errorf("%s = %q, want req-123", RequestIDHeader, id)
+	}
+
+	var resp models.APIResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("body is not JSON: %v\n%s", err, rec.Body)
+	}
+	if resp.Success || resp.Error == nil {
+		t.Fatalf("body = %s, want an error envelope", rec.Body)
+	}
+	if resp.Error.Code != models.ErrCodeInternalError || resp.Error.RequestID != "req-123" {
+		t.Errorf("error = %+v, want code %s and request ID req-123", resp.Error, models.ErrCodeInternalError)
+	}
+	if strings.Contains(rec.Body.String(), "boom") {
+		t.Error("the panic value leaked into the response")
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal(logs.Bytes(), &entry); err != nil {
+		t.Fatalf("log is not one JSON line: %v\n%s", err, logs)
+	}
+	if entry["msg"] != "panic recovered" || entry["level"] != "ERROR" {
+		t.Errorf("log entry = %v, want an ERROR \"panic recovered\"", entry)
+	}
+	if entry["request_id"] != "req-123" || entry["error"] != "boom" || entry["path"] != "/api/v1/contracts" {
+		t.Errorf("log entry = %v, want request_id, error and path", entry)
+	}
+	if stack, _ := entry["stack"].(string); !strings.Contains(stack, "recovery_test.go") {
+		t.Error("log entry does not carry the panicking stack")
+	}
+
+	if got := testutil.ToFloat64(panics) - before; got != 1 {
+		t.Errorf("panic counter rose by %v, want 1", got)
+	}
+}
+
+func TestRecoveryMiddlewareAbortsStartedResponse(t *testing.T) {
+	handler, logs := recoveryChain(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"partial":`))
+		panic("boom")
+	})
+
+	defer func() {
+		if err := recover(); err != http.ErrAbortHandler {
+			t.Fatalf("recovered %v, want http.ErrAbortHandler", err)
+		}
+		if !strings.Contains(logs.String(), "panic recovered") {
+			t.Error("the panic was not logged before aborting")
+		}
+	}()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	t.Fatal("ServeHTTP returned normally")
+}
+
+func TestRecoveryMiddlewareReraisesAbortHandler(t *testing.T) {
+	handler, logs := recoveryChain(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	})
+
+	defer func() {
+		if err := recover(); err != http.ErrAbortHandler {
+			t.Fatalf("recovered %v, want http.ErrAbortHandler", err)
+		}
+		if logs.Len() != 0 {
+			t.Errorf("an aborted handler should not be logged, got %s", logs)
+		}
+	}()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	t.Fatal("ServeHTTP returned normally")
+}
+
+func TestRecoveryMiddlewareOutsideRequestID(t *testing.T) {
+	// A panic in a middleware outside RequestIDMiddleware still becomes a
+	// 500, without a request ID to report
+	var logs bytes.Buffer
+	handler := RecoveryMiddleware(slog.New(slog.NewJSONHandler(&logs, nil)))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	var resp models.APIResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, body %s; want a 500 error envelope", rec.Code, rec.Body)
+	}
+	if resp.Error == nil || resp.Error.RequestID != "" {
+		t.Errorf("error = %+v, want no request ID", resp.Error)
+	}
+	if !strings.Contains(logs.String(), "panic recovered") || strings.Contains(logs.String(), "request_id") {
+		t.Errorf("log = %s, want the panic without a request ID", logs.String())
+	}
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// APIClient is a machine integration that authenticates with the
+// client_credentials grant and acts for a single tenant
+type APIClient struct {
+	ClientID    string    `json:"client_id"`
+	TenantID    string    `json:"tenant_id"`
+	Scopes      []string  `json:"scopes"` // Roles granted to the client's requests
+	Active      bool      `json:"active"`
+	Description string    `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
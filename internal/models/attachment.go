@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// ContractAttachment is a file uploaded against a contract, such as a
+// signed scan
+type ContractAttachment struct {
+	ID             int64     `json:"id"`
+	TenantID       string    `json:"tenant_id"`
+	ContractID     int64     `json:"contract_id"`
+	FileName       string    `json:"file_name"`
+	ContentType    string    `json:"content_type"`
+	SizeBytes      int64     `json:"size_bytes"`
+	Checksum       string    `json:"checksum_sha256"` // Lowercase hex SHA-256 of the content
+	StorageBackend string    `json:"-"`
+	StorageKey     string    `json:"-"`
+	UploadedBy     string    `json:"uploaded_by"`
+	CreatedAt      time.Time `json:"created_at"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// BillingPeriod is one invoice of a contract's billing schedule. Start and
+// End are inclusive dates.
+type BillingPeriod struct {
+	Number  int             `json:"number"`
+	Start   time.Time       `json:"period_start"`
+	End     time.Time       `json:"period_end"`
+	DueDate time.Time       `json:"due_date"`
+	Amount  decimal.Decimal `json:"amount"`
+}
+
+// BillingSchedule is the GET /api/v1/contracts/{id}/billing-schedule
+// response: when the contract's total will be invoiced and for how much
+type BillingSchedule struct {
+	ContractID   int64           `json:"contract_id"`
+	BillingCycle BillingCycle    `json:"billing_cycle"`
+	PaymentTerms string          `json:"payment_terms,omitempty"`
+	NetDays      *int            `json:"net_days"` // nil when PaymentTerms is not NET<days>; due dates are then the period starts
+	Total        decimal.Decimal `json:"total"`
+	Periods      []BillingPeriod `json:"periods"`
+}
@@ -1,5 +1,13 @@
 package models
 
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
 // PaginationParams holds pagination parameters
 type PaginationParams struct {
 	Page     int `json:"page"`
@@ -60,11 +68,77 @@ func NewPaginatedResponse[T any](data []T, page, pageSize, totalCount int) Pagin
 	}
 }
 
-// APIError represents an API error response
+// ErrInvalidCursor is returned for a cursor that was not produced by Encode
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ListCursor marks the last row of a keyset page by its sort time
+// (created_at, or queued_at for print jobs) and ID
+type ListCursor struct {
+	At time.Time
+	ID int64
+}
+
+// Encode returns the opaque token handed to clients as next_cursor
+func (c ListCursor) Encode() string {
+	raw := c.At.Format(time.RFC3339Nano) + "|" + strconv.FormatInt(c.ID, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseListCursor decodes a token produced by Encode
+func ParseListCursor(token string) (ListCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return ListCursor{}, ErrInvalidCursor
+	}
+	at, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return ListCursor{}, ErrInvalidCursor
+	}
+	var c ListCursor
+	if c.At, err = time.Parse(time.RFC3339Nano, at); err != nil {
+		return ListCursor{}, ErrInvalidCursor
+	}
+	if c.ID, err = strconv.ParseInt(id, 10, 64); err != nil || c.ID <= 0 {
+		return ListCursor{}, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// KeysetParams selects a keyset page of PageSize rows, newest first,
+// following After; a nil After starts at the newest row
+type KeysetParams struct {
+	After    *ListCursor
+	PageSize int
+}
+
+// CursorResponse wraps a keyset page. NextCursor is set when more rows follow
+// and is passed back as the cursor parameter to fetch them.
+type CursorResponse[T any] struct {
+	Data       []T    `json:"data"`
+	PageSize   int    `json:"page_size"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// NewCursorResponse creates a new keyset page response
+func NewCursorResponse[T any](data []T, pageSize int, next *ListCursor) CursorResponse[T] {
+	if data == nil {
+		data = make([]T, 0)
+	}
+	resp := CursorResponse[T]{Data: data, PageSize: pageSize}
+	if next != nil {
+		resp.NextCursor = next.Encode()
+	}
+	return resp
+}
+
+// APIError is the error payload of every failed request. Code is one of the
+// ErrCode constants; Details lists field-level problems for validation errors.
 type APIError struct {
-	Code    string `json:"code"`
-	Message string `json:"message"`
-	Details any    `json:"details,omitempty"`
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Details []FieldError `json:"details,omitempty"`
+	// RequestID correlates the error with server logs
+	RequestID string `json:"request_id,omitempty"`
 }
 
 // APIResponse represents a standard API response
@@ -83,7 +157,7 @@ func SuccessResponse(data any) APIResponse {
 }
 
 // ErrorResponse creates an error response
-func ErrorResponse(code, message string, details any) APIResponse {
+func ErrorResponse(code, message string, details []FieldError) APIResponse {
 	return APIResponse{
 		Success: false,
 		Error: &APIError{
@@ -96,9 +170,11 @@ func ErrorResponse(code, message string, details any) APIResponse {
 
 // SearchParams holds search parameters
 type SearchParams struct {
-	Query   string `json:"query"`
-	Field   string `json:"field"`
-	SortBy  string `json:"sort_by"`
-	SortDir string `json:"sort_dir"`
-	Active  *bool  `json:"active,omitempty"`
+	Query      string   `json:"query"`
+	Field      string   `json:"field"`
+	SortBy     string   `json:"sort_by"`
+	SortDir    string   `json:"sort_dir"`
+	Active     *bool    `json:"active,omitempty"`
+	Tags       []string `json:"tags,omitempty"`        // Contracts only; matches have every tag
+	CustomerID int64    `json:"customer_id,omitempty"` // Contracts only
 }
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/shopspring/decimal"
@@ -49,7 +50,12 @@ type Contract struct {
 	EndDate         *time.Time      `json:"end_date,omitempty"`
 	DurationMonths  int             `json:"duration_months,omitempty"`
 	AutoRenew       bool            `json:"auto_renew"`
-	TotalValue      decimal.Decimal `json:"total_value"`
+	Subtotal        decimal.Decimal `json:"subtotal"` // Sum of the item line totals
+	DiscountPct     decimal.Decimal `json:"discount_pct"`
+	DiscountAmount  decimal.Decimal `json:"discount_amount"`
+	TaxPct          decimal.Decimal `json:"tax_pct"`
+	TaxAmount       decimal.Decimal `json:"tax_amount"`
+	TotalValue      decimal.Decimal `json:"total_value"` // Subtotal - DiscountAmount + TaxAmount
 	PaymentTerms    string          `json:"payment_terms,omitempty"`
 	BillingCycle    BillingCycle    `json:"billing_cycle"`
 	Status          ContractStatus  `json:"status"`
@@ -60,6 +66,7 @@ type Contract struct {
 	Notes           string          `json:"notes,omitempty"`
 	TermsConditions string          `json:"terms_conditions,omitempty"`
 	Items           []ContractItem  `json:"items,omitempty"`
+	Tags            []string        `json:"tags,omitempty"`
 	CreatedAt       time.Time       `json:"created_at"`
 	UpdatedAt       time.Time       `json:"updated_at"`
 	CreatedBy       string          `json:"created_by,omitempty"`
@@ -100,18 +107,21 @@ type ContractItem struct {
 
 // CreateContractRequest represents the request to create a contract
 type CreateContractRequest struct {
-	ContractNumber  string                      `json:"contract_number" validate:"required,max=50"`
+	ContractNumber  string                      `json:"contract_number,omitempty" validate:"max=50"` // Generated from the tenant's pattern when empty
 	ContractType    ContractType                `json:"contract_type" validate:"required,oneof=SERVICE RECURRING PROJECT"`
 	CustomerID      int64                       `json:"customer_id" validate:"required,gt=0"`
 	StartDate       time.Time                   `json:"start_date" validate:"required"`
 	EndDate         *time.Time                  `json:"end_date,omitempty"`
 	DurationMonths  int                         `json:"duration_months,omitempty" validate:"omitempty,gte=0"`
 	AutoRenew       bool                        `json:"auto_renew"`
+	DiscountPct     decimal.Decimal             `json:"discount_pct,omitempty"` // Applied to the subtotal
+	TaxPct          decimal.Decimal             `json:"tax_pct,omitempty"`      // Applied to the discounted subtotal
 	PaymentTerms    string                      `json:"payment_terms,omitempty"`
 	BillingCycle    BillingCycle                `json:"billing_cycle,omitempty" validate:"omitempty,oneof=MONTHLY QUARTERLY YEARLY ONCE"`
 	Notes           string                      `json:"notes,omitempty"`
 	TermsConditions string                      `json:"terms_conditions,omitempty"`
 	Items           []CreateContractItemRequest `json:"items,omitempty" validate:"dive"`
+	Tags            []string                    `json:"tags,omitempty"` // Normalized by NormalizeTags
 }
 
 // CreateContractItemRequest represents the request to create a contract item
@@ -129,15 +139,18 @@ type CreateContractItemRequest struct {
 
 // UpdateContractRequest represents the request to update a contract
 type UpdateContractRequest struct {
-	ContractType    *ContractType `json:"contract_type,omitempty"`
-	StartDate       *time.Time    `json:"start_date,omitempty"`
-	EndDate         *time.Time    `json:"end_date,omitempty"`
-	DurationMonths  *int          `json:"duration_months,omitempty"`
-	AutoRenew       *bool         `json:"auto_renew,omitempty"`
-	PaymentTerms    *string       `json:"payment_terms,omitempty"` // nil=no change, &""=clear
-	BillingCycle    *BillingCycle `json:"billing_cycle,omitempty"`
-	Notes           *string       `json:"notes,omitempty"`            // nil=no change, &""=clear
-	TermsConditions *string       `json:"terms_conditions,omitempty"` // nil=no change, &""=clear
+	ContractType    *ContractType    `json:"contract_type,omitempty"`
+	StartDate       *time.Time       `json:"start_date,omitempty"`
+	EndDate         *time.Time       `json:"end_date,omitempty"`
+	DurationMonths  *int             `json:"duration_months,omitempty"`
+	AutoRenew       *bool            `json:"auto_renew,omitempty"`
+	DiscountPct     *decimal.Decimal `json:"discount_pct,omitempty"`
+	TaxPct          *decimal.Decimal `json:"tax_pct,omitempty"`
+	PaymentTerms    *string          `json:"payment_terms,omitempty"` // nil=no change, &""=clear
+	BillingCycle    *BillingCycle    `json:"billing_cycle,omitempty"`
+	Notes           *string          `json:"notes,omitempty"`            // nil=no change, &""=clear
+	TermsConditions *string          `json:"terms_conditions,omitempty"` // nil=no change, &""=clear
+	Tags            *[]string        `json:"tags,omitempty"`             // nil=no change, &[]=clear; replaces all tags
 }
 
 // UpdateContractStatusRequest represents the request to update contract status
@@ -161,15 +174,45 @@ type ContractResponse struct {
 	EndDate        *time.Time             `json:"end_date,omitempty"`
 	DurationMonths int                    `json:"duration_months,omitempty"`
 	AutoRenew      bool                   `json:"auto_renew"`
+	Subtotal       decimal.Decimal        `json:"subtotal"`
+	DiscountPct    decimal.Decimal        `json:"discount_pct"`
+	DiscountAmount decimal.Decimal        `json:"discount_amount"`
+	TaxPct         decimal.Decimal        `json:"tax_pct"`
+	TaxAmount      decimal.Decimal        `json:"tax_amount"`
 	TotalValue     decimal.Decimal        `json:"total_value"`
 	BillingCycle   BillingCycle           `json:"billing_cycle"`
 	Status         ContractStatus         `json:"status"`
 	SignedAt       *time.Time             `json:"signed_at,omitempty"`
 	Items          []ContractItemResponse `json:"items,omitempty"`
+	Tags           []string               `json:"tags"`
 	CreatedAt      time.Time              `json:"created_at"`
 	UpdatedAt      time.Time              `json:"updated_at"`
 }
 
+// ContractResponseFields are the fields ?fields= can narrow contract lists to
+var ContractResponseFields = ResponseFields[ContractResponse]{
+	"id":              func(c ContractResponse) any { return c.ID },
+	"contract_number": func(c ContractResponse) any { return c.ContractNumber },
+	"contract_type":   func(c ContractResponse) any { return c.ContractType },
+	"customer_id":     func(c ContractResponse) any { return c.CustomerID },
+	"start_date":      func(c ContractResponse) any { return c.StartDate },
+	"end_date":        func(c ContractResponse) any { return c.EndDate },
+	"duration_months": func(c ContractResponse) any { return c.DurationMonths },
+	"auto_renew":      func(c ContractResponse) any { return c.AutoRenew },
+	"subtotal":        func(c ContractResponse) any { return c.Subtotal },
+	"discount_pct":    func(c ContractResponse) any { return c.DiscountPct },
+	"discount_amount": func(c ContractResponse) any { return c.DiscountAmount },
+	"tax_pct":         func(c ContractResponse) any { return c.TaxPct },
+	"tax_amount":      func(c ContractResponse) any { return c.TaxAmount },
+	"total_value":     func(c ContractResponse) any { return c.TotalValue },
+	"billing_cycle":   func(c ContractResponse) any { return c.BillingCycle },
+	"status":          func(c ContractResponse) any { return c.Status },
+	"signed_at":       func(c ContractResponse) any { return c.SignedAt },
+	"tags":            func(c ContractResponse) any { return c.Tags },
+	"created_at":      func(c ContractResponse) any { return c.CreatedAt },
+	"updated_at":      func(c ContractResponse) any { return c.UpdatedAt },
+}
+
 // ContractItemResponse represents the API response for a contract item
 type ContractItemResponse struct {
 	ID          int64              `json:"id"`
@@ -198,14 +241,24 @@ func (c *Contract) ToResponse() ContractResponse {
 		EndDate:        c.EndDate,
 		DurationMonths: c.DurationMonths,
 		AutoRenew:      c.AutoRenew,
+		Subtotal:       c.Subtotal,
+		DiscountPct:    c.DiscountPct,
+		DiscountAmount: c.DiscountAmount,
+		TaxPct:         c.TaxPct,
+		TaxAmount:      c.TaxAmount,
 		TotalValue:     c.TotalValue,
 		BillingCycle:   c.BillingCycle,
 		Status:         c.Status,
 		SignedAt:       c.SignedAt,
+		Tags:           c.Tags,
 		CreatedAt:      c.CreatedAt,
 		UpdatedAt:      c.UpdatedAt,
 	}
 
+	if resp.Tags == nil {
+		resp.Tags = []string{}
+	}
+
 	if c.Customer != nil {
 		custResp := c.Customer.ToResponse()
 		resp.Customer = &custResp
@@ -238,3 +291,96 @@ func (ci *ContractItem) ToResponse() ContractItemResponse {
 
 	return resp
 }
+
+// contractTypes, billingCycles and contractStatuses mirror the CHECK
+// constraints and defaults of the contracts table
+var (
+	contractTypes    = []string{string(ContractTypeService), string(ContractTypeRecurring), string(ContractTypeProject)}
+	billingCycles    = []string{string(BillingCycleMonthly), string(BillingCycleQuarterly), string(BillingCycleYearly), string(BillingCycleOnce)}
+	contractStatuses = []string{string(ContractStatusDraft), string(ContractStatusPending), string(ContractStatusActive),
+		string(ContractStatusSuspended), string(ContractStatusCancelled), string(ContractStatusCompleted)}
+)
+
+// Validate checks the request and its items against the contracts tables
+func (r *CreateContractRequest) Validate() []FieldError {
+	var v Validator
+	v.MaxLen("contract_number", r.ContractNumber, 50)
+	v.Required("contract_type", string(r.ContractType))
+	v.OneOf("contract_type", string(r.ContractType), contractTypes...)
+	v.PositiveID("customer_id", r.CustomerID)
+	v.Check(!r.StartDate.IsZero(), "start_date", "required", "start_date is required")
+	if !r.StartDate.IsZero() {
+		v.After("end_date", r.EndDate, &r.StartDate, "start_date")
+	}
+	v.Check(r.DurationMonths >= 0 && r.DurationMonths <= 9999, "duration_months", "range",
+		"duration_months must be between 0 and 9999")
+	v.Percent("discount_pct", r.DiscountPct)
+	v.Percent("tax_pct", r.TaxPct)
+	v.MaxLen("payment_terms", r.PaymentTerms, 100)
+	v.OneOf("billing_cycle", string(r.BillingCycle), billingCycles...)
+	for i := range r.Items {
+		r.Items[i].validate(&v, fmt.Sprintf("items[%d].", i))
+	}
+	validateTags(&v, "tags", r.Tags)
+	return v.Problems()
+}
+
+// Validate checks the request against the contract_items table
+func (r *CreateContractItemRequest) Validate() []FieldError {
+	var v Validator
+	r.validate(&v, "")
+	return v.Problems()
+}
+
+// validate records item problems with field names under prefix
+func (r *CreateContractItemRequest) validate(v *Validator, prefix string) {
+	v.PositiveID(prefix+"service_id", r.ServiceID)
+	v.Positive(prefix+"quantity", r.Quantity)
+	v.NonNegative(prefix+"unit_price", r.UnitPrice)
+	v.Percent(prefix+"discount_pct", r.DiscountPct)
+	v.After(prefix+"end_date", r.EndDate, r.StartDate, prefix+"start_date")
+}
+
+// Validate checks the fields being changed against the contracts table.
+// The date order is only checked when both dates are in the request.
+func (r *UpdateContractRequest) Validate() []FieldError {
+	var v Validator
+	if r.ContractType != nil {
+		v.Required("contract_type", string(*r.ContractType))
+		v.OneOf("contract_type", string(*r.ContractType), contractTypes...)
+	}
+	v.After("end_date", r.EndDate, r.StartDate, "start_date")
+	if r.DurationMonths != nil {
+		v.Check(*r.DurationMonths >= 0 && *r.DurationMonths <= 9999, "duration_months", "range",
+			"duration_months must be between 0 and 9999")
+	}
+	if r.DiscountPct != nil {
+		v.Percent("discount_pct", *r.DiscountPct)
+	}
+	if r.TaxPct != nil {
+		v.Percent("tax_pct", *r.TaxPct)
+	}
+	v.MaxLen("payment_terms", deref(r.PaymentTerms), 100)
+	if r.BillingCycle != nil {
+		v.Required("billing_cycle", string(*r.BillingCycle))
+		v.OneOf("billing_cycle", string(*r.BillingCycle), billingCycles...)
+	}
+	if r.Tags != nil {
+		validateTags(&v, "tags", *r.Tags)
+	}
+	return v.Problems()
+}
+
+// TagsOnly reports whether the request changes the tags and nothing else.
+// Tags are labels rather than terms, so they may change in any status.
+func (r *UpdateContractRequest) TagsOnly() bool {
+	return r.Tags != nil && *r == UpdateContractRequest{Tags: r.Tags}
+}
+
+// Validate checks the requested status
+func (r *UpdateContractStatusRequest) Validate() []FieldError {
+	var v Validator
+	v.Required("status", string(r.Status))
+	v.OneOf("status", string(r.Status), contractStatuses...)
+	return v.Problems()
+}
@@ -23,8 +23,25 @@ const (
 	GenerationActionView     ContractGenerationAction = "VIEW"
 	GenerationActionDownload ContractGenerationAction = "DOWNLOAD"
 	GenerationActionPrint    ContractGenerationAction = "PRINT"
+	GenerationActionCompare  ContractGenerationAction = "COMPARE"
+	GenerationActionVerify   ContractGenerationAction = "VERIFY"
 )
 
+// VerificationStatus is the outcome of a public document verification
+type VerificationStatus string
+
+const (
+	VerificationValid    VerificationStatus = "valid"
+	VerificationTampered VerificationStatus = "tampered"
+	VerificationNotFound VerificationStatus = "not_found"
+)
+
+// VerificationResponse is returned by the public verification endpoint.
+// It deliberately carries no contract content.
+type VerificationResponse struct {
+	Status VerificationStatus `json:"status"`
+}
+
 // GeneratedContract represents a generated contract document
 type GeneratedContract struct {
 	ID                    int64                    `json:"id"`
@@ -53,6 +70,7 @@ type ContractTemplate struct {
 	IsDefault    bool      `json:"is_default"`
 	Active       bool      `json:"active"`
 	Version      int       `json:"version"`
+	BodyHTML     string    `json:"body_html,omitempty"` // Only loaded for a single template
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 }
@@ -87,11 +105,16 @@ type GenerateContractResponse struct {
 	ErrorMessage string          `json:"error_message,omitempty"`
 }
 
+// GeneratedContentMeta describes a generated version without its content
+type GeneratedContentMeta struct {
+	GeneratedID int64     `json:"generated_id"`
+	ContentHash string    `json:"content_hash"`
+	GeneratedAt time.Time `json:"generated_at"`
+}
+
 // GetGeneratedContentResponse represents the response when fetching generated content
 type GetGeneratedContentResponse struct {
-	GeneratedID  int64           `json:"generated_id"`
-	ContentHash  string          `json:"content_hash"`
-	GeneratedAt  time.Time       `json:"generated_at"`
+	GeneratedContentMeta
 	ContractJSON json.RawMessage `json:"contract_data"` // Clean JSON structure for PDF rendering
 }
 
@@ -118,6 +141,129 @@ type GenerationStats struct {
 	UniqueContracts int64 `json:"unique_contracts"`
 }
 
+// ContractGenerationStats summarizes the generations of one contract and
+// the actions logged on them
+type ContractGenerationStats struct {
+	ContractID       int64      `json:"contract_id"`
+	GenerationCount  int64      `json:"generation_count"`
+	FirstGeneratedAt *time.Time `json:"first_generated_at"` // Nil when never generated
+	LastGeneratedAt  *time.Time `json:"last_generated_at"`
+	DistinctUsers    int64      `json:"distinct_users"` // Users who generated it
+	ViewCount        int64      `json:"view_count"`
+	DownloadCount    int64      `json:"download_count"`
+	PrintCount       int64      `json:"print_count"`
+}
+
+// ContractActionFilter narrows a contract's action log. Zero values mean "no filter".
+type ContractActionFilter struct {
+	Action      ContractGenerationAction
+	PerformedBy string
+}
+
+// ContractActionLogEntry is an action logged on a contract's generated
+// versions. IP address and session hashes are not exposed.
+type ContractActionLogEntry struct {
+	ID          int64                    `json:"id"`
+	ContractID  int64                    `json:"contract_id"`
+	GeneratedID *int64                   `json:"generated_id,omitempty"` // Nil once the version is deleted
+	Action      ContractGenerationAction `json:"action"`
+	Status      string                   `json:"status"`
+	PerformedBy string                   `json:"performed_by"`
+	PerformedAt time.Time                `json:"performed_at"`
+	ErrorCode   string                   `json:"error_code,omitempty"`
+}
+
+// GenerationRef identifies one generated version of a contract
+type GenerationRef struct {
+	GeneratedID      int64     `json:"generated_id"`
+	GenerationNumber int       `json:"generation_number"`
+	ContentHash      string    `json:"content_hash"`
+	GeneratedAt      time.Time `json:"generated_at"`
+}
+
+// IntegrityCheck is the outcome of checking a generated version against
+// its content hash
+type IntegrityCheck struct {
+	GeneratedID int64     `json:"generated_id"`
+	ContractID  int64     `json:"contract_id"`
+	Valid       bool      `json:"valid"`
+	ContentHash string    `json:"content_hash"`
+	CheckedAt   time.Time `json:"checked_at"`
+}
+
+// IntegritySweepRequest starts an integrity sweep of a tenant's generated
+// contracts. A sweep stopping at MaxRecords resumes with AfterID.
+type IntegritySweepRequest struct {
+	TenantID   string `json:"tenant_id,omitempty"`   // Defaults to the caller's tenant
+	AfterID    int64  `json:"after_id,omitempty"`    // Only generated IDs above this are checked
+	MaxRecords int    `json:"max_records,omitempty"` // Defaults to 5000, at most 50000
+}
+
+// SweptGeneration is a generated version found by an integrity sweep
+type SweptGeneration struct {
+	ContractID int64 `json:"contract_id"`
+	GenerationRef
+}
+
+// IntegritySweep summarizes an integrity sweep of a tenant's generated contracts
+type IntegritySweep struct {
+	TenantID    string            `json:"tenant_id"`
+	Checked     int               `json:"checked"`
+	Valid       int               `json:"valid"`
+	Tampered    []SweptGeneration `json:"tampered"`
+	Missing     int               `json:"missing"`                 // Deleted while the sweep ran
+	NextAfterID int64             `json:"next_after_id,omitempty"` // Set when records remain; pass as after_id
+	StartedAt   time.Time         `json:"started_at"`
+	FinishedAt  time.Time         `json:"finished_at"`
+}
+
+// StaleGeneration describes a contract edited after its latest generation
+type StaleGeneration struct {
+	ContractID        int64     `json:"contract_id"`
+	ContractUpdatedAt time.Time `json:"contract_updated_at"`
+	GeneratedID       int64     `json:"generated_id"`
+	GeneratedAt       time.Time `json:"generated_at"`
+}
+
+// RenderedContract describes a generated version being rendered as a
+// document; the document itself is streamed
+type RenderedContract struct {
+	GenerationRef
+	ContractID  int64
+	Format      PrintFormat
+	ContentType string
+}
+
+// FieldChange is a value that differs between two generated versions.
+// Path is dotted from the document root, e.g. contract.total_value; Old or
+// New is null when the field is missing from that version.
+type FieldChange struct {
+	Path string `json:"path"`
+	Old  any    `json:"old"`
+	New  any    `json:"new"`
+}
+
+// ItemChange is a contract item added, removed or changed between two
+// generated versions. Items are matched by service: Key is the service ID,
+// suffixed with #2, #3... when the service appears more than once.
+type ItemChange struct {
+	Key         string         `json:"key"`
+	ServiceName string         `json:"service_name,omitempty"`
+	Item        map[string]any `json:"item,omitempty"`    // The whole item, when added or removed
+	Changes     []FieldChange  `json:"changes,omitempty"` // Paths relative to the item, when changed
+}
+
+// GenerationDiff is what changed between two generated versions of a contract
+type GenerationDiff struct {
+	ContractID   int64         `json:"contract_id"`
+	From         GenerationRef `json:"from"`
+	To           GenerationRef `json:"to"`
+	Changes      []FieldChange `json:"changes"`
+	AddedItems   []ItemChange  `json:"added_items"`
+	RemovedItems []ItemChange  `json:"removed_items"`
+	ChangedItems []ItemChange  `json:"changed_items"`
+}
+
 // ToListItem converts a GeneratedContract to GeneratedContractListItem (excludes content)
 func (g *GeneratedContract) ToListItem() GeneratedContractListItem {
 	return GeneratedContractListItem{
@@ -143,6 +289,7 @@ type ContractTemplateResponse struct {
 	IsDefault    bool      `json:"is_default"`
 	Active       bool      `json:"active"`
 	Version      int       `json:"version"`
+	BodyHTML     string    `json:"body_html,omitempty"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 }
@@ -157,6 +304,7 @@ func (t *ContractTemplate) ToResponse() ContractTemplateResponse {
 		IsDefault:    t.IsDefault,
 		Active:       t.Active,
 		Version:      t.Version,
+		BodyHTML:     t.BodyHTML,
 		CreatedAt:    t.CreatedAt,
 		UpdatedAt:    t.UpdatedAt,
 	}
@@ -0,0 +1,50 @@
+package models
+
+import "github.com/shopspring/decimal"
+
+// RoundingMode selects how contract amounts are rounded to cents
+type RoundingMode string
+
+const (
+	RoundingHalfEven RoundingMode = "HALF_EVEN" // Banker's rounding: 0.125 becomes 0.12
+	RoundingHalfUp   RoundingMode = "HALF_UP"   // 0.125 becomes 0.13
+)
+
+// roundingModes mirrors the CHECK constraint on tenant_settings.rounding_mode
+var roundingModes = []string{string(RoundingHalfEven), string(RoundingHalfUp)}
+
+// Round rounds d to cents. Unknown modes round half to even.
+func (m RoundingMode) Round(d decimal.Decimal) decimal.Decimal {
+	if m == RoundingHalfUp {
+		return d.Round(2)
+	}
+	return d.RoundBank(2)
+}
+
+// ContractTotals are the stored amounts of a contract, each rounded to cents
+type ContractTotals struct {
+	Subtotal       decimal.Decimal // Sum of the item line totals
+	DiscountAmount decimal.Decimal // Contract discount on the subtotal
+	TaxAmount      decimal.Decimal // Tax on the discounted subtotal
+	Total          decimal.Decimal // Subtotal - DiscountAmount + TaxAmount
+}
+
+// ComputeContractTotals applies the contract discount and then the tax to
+// the sum of the item line totals. Each line total and each amount is
+// rounded with mode before it is used, so the printed figures add up.
+func ComputeContractTotals(items []ContractItem, discountPct, taxPct decimal.Decimal, mode RoundingMode) ContractTotals {
+	hundred := decimal.NewFromInt(100)
+	subtotal := decimal.Zero
+	for _, item := range items {
+		line := item.Quantity.Mul(item.UnitPrice).Mul(hundred.Sub(item.DiscountPct)).Div(hundred)
+		subtotal = subtotal.Add(mode.Round(line))
+	}
+	discount := mode.Round(subtotal.Mul(discountPct).Div(hundred))
+	tax := mode.Round(subtotal.Sub(discount).Mul(taxPct).Div(hundred))
+	return ContractTotals{
+		Subtotal:       subtotal,
+		DiscountAmount: discount,
+		TaxAmount:      tax,
+		Total:          subtotal.Sub(discount).Add(tax),
+	}
+}
@@ -0,0 +1,125 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func dec(s string) decimal.Decimal { return decimal.RequireFromString(s) }
+
+func TestRoundingModeRound(t *testing.T) {
+	tests := []struct {
+		in       string
+		halfEven string
+		halfUp   string
+	}{
+		{"0.125", "0.12", "0.13"},
+		{"0.135", "0.14", "0.14"},
+		{"0.124", "0.12", "0.12"},
+		{"0.1251", "0.13", "0.13"},
+		{"2.5", "2.5", "2.5"},
+		{"-0.125", "-0.12", "-0.13"},
+		{"1000.005", "1000", "1000.01"},
+	}
+	for _, tt := range tests {
+		if got := RoundingHalfEven.Round(dec(tt.in)); !got.Equal(dec(tt.halfEven)) {
+			t.Errorf("HALF_EVEN(%s) = %s, want %s", tt.in, got, tt.halfEven)
+		}
+		if got := RoundingHalfUp.Round(dec(tt.in)); !got.Equal(dec(tt.halfUp)) {
+			t.Errorf("HALF_UP(%s) = %s, want %s", tt.in, got, tt.halfUp)
+		}
+	}
+	// Unset and unknown modes round like HALF_EVEN
+	for _, mode := range []RoundingMode{"", "CEILING"} {
+		if got := mode.Round(dec("0.125")); !got.Equal(dec("0.12")) {
+			t.Errorf("%q.Round(0.125) = %s, want 0.12", mode, got)
+		}
+	}
+}
+
+func TestComputeContractTotals(t *testing.T) {
+	item := func(qty, price, discount string) ContractItem {
+		return ContractItem{Quantity: dec(qty), UnitPrice: dec(price), DiscountPct: dec(discount)}
+	}
+	tests := []struct {
+		name        string
+		items       []ContractItem
+		discountPct string
+		taxPct      string
+		mode        RoundingMode
+		want        [4]string // subtotal, discount, tax, total
+	}{
+		{
+			"no discount or tax",
+			[]ContractItem{item("2", "150.00", "0"), item("1", "99.90", "0")},
+			"0", "0", RoundingHalfEven,
+			[4]string{"399.90", "0", "0", "399.90"},
+		},
+		{
+			"discount then tax on the discounted subtotal",
+			[]ContractItem{item("1", "1000.00", "0")},
+			"10", "5", RoundingHalfEven,
+			[4]string{"1000.00", "100.00", "45.00", "945.00"},
+		},
+		{
+			// Each 0.125 line rounds to 0.12; summing first would give 0.25
+			"line totals are rounded before summing",
+			[]ContractItem{item("1", "0.125", "0"), item("1", "0.125", "0")},
+			"0", "0", RoundingHalfEven,
+			[4]string{"0.24", "0", "0", "0.24"},
+		},
+		{
+			"discount rounded to cents",
+			[]ContractItem{item("1", "10.25", "0")},
+			"5", "0", RoundingHalfEven,
+			// 5% of 10.25 is 0.5125
+			[4]string{"10.25", "0.51", "0", "9.74"},
+		},
+		{
+			"tie rounds to even",
+			[]ContractItem{item("1", "0.25", "0")},
+			"50", "50", RoundingHalfEven,
+			// discount 0.125 -> 0.12; tax on 0.13 is 0.065 -> 0.06
+			[4]string{"0.25", "0.12", "0.06", "0.19"},
+		},
+		{
+			"tie rounds up",
+			[]ContractItem{item("1", "0.25", "0")},
+			"50", "50", RoundingHalfUp,
+			// discount 0.125 -> 0.13; tax on 0.12 is 0.06
+			[4]string{"0.25", "0.13", "0.06", "0.18"},
+		},
+		{
+			"item discounts",
+			[]ContractItem{item("3", "33.33", "15"), item("0.5", "19.99", "0")},
+			"0", "8.25", RoundingHalfUp,
+			// 84.9915 -> 84.99 and 9.995 -> 10.00; 8.25% of 94.99 is 7.836675
+			[4]string{"94.99", "0", "7.84", "102.83"},
+		},
+		{
+			"full discount",
+			[]ContractItem{item("1", "500", "0")},
+			"100", "20", RoundingHalfEven,
+			[4]string{"500", "500", "0", "0"},
+		},
+		{"no items", nil, "10", "10", RoundingHalfEven, [4]string{"0", "0", "0", "0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ComputeContractTotals(tt.items, dec(tt.discountPct), dec(tt.taxPct), tt.mode)
+			for i, pair := range []struct {
+				name string
+				got  decimal.Decimal
+			}{{"subtotal", got.Subtotal}, {"discount", got.DiscountAmount}, {"tax", got.TaxAmount}, {"total", got.Total}} {
+				if !pair.got.Equal(dec(tt.want[i])) {
+					t.Errorf("%s = %s, want %s", pair.name, pair.got, tt.want[i])
+				}
+			}
+			if !got.Total.Equal(got.Subtotal.Sub(got.DiscountAmount).Add(got.TaxAmount)) {
+				t.Errorf("total %s is not subtotal - discount + tax", got.Total)
+			}
+		})
+	}
+}
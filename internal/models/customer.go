@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // CustomerType represents the type of customer
 type CustomerType string
@@ -107,6 +110,23 @@ type CustomerResponse struct {
 	UpdatedAt    time.Time    `json:"updated_at"`
 }
 
+// CustomerResponseFields are the fields ?fields= can narrow customer lists to
+var CustomerResponseFields = ResponseFields[CustomerResponse]{
+	"id":            func(c CustomerResponse) any { return c.ID },
+	"customer_code": func(c CustomerResponse) any { return c.CustomerCode },
+	"customer_type": func(c CustomerResponse) any { return c.CustomerType },
+	"name":          func(c CustomerResponse) any { return c.Name },
+	"trade_name":    func(c CustomerResponse) any { return c.TradeName },
+	"tax_id":        func(c CustomerResponse) any { return c.TaxID },
+	"email":         func(c CustomerResponse) any { return c.Email },
+	"phone":         func(c CustomerResponse) any { return c.Phone },
+	"mobile":        func(c CustomerResponse) any { return c.Mobile },
+	"address":       func(c CustomerResponse) any { return c.Address },
+	"active":        func(c CustomerResponse) any { return c.Active },
+	"created_at":    func(c CustomerResponse) any { return c.CreatedAt },
+	"updated_at":    func(c CustomerResponse) any { return c.UpdatedAt },
+}
+
 // ToResponse converts a Customer to CustomerResponse
 func (c *Customer) ToResponse() CustomerResponse {
 	if c == nil {
@@ -131,3 +151,54 @@ func (c *Customer) ToResponse() CustomerResponse {
 	}
 	return resp
 }
+
+// Validate checks the request against the customers table
+func (r *CreateCustomerRequest) Validate() []FieldError {
+	var v Validator
+	v.Required("customer_code", r.CustomerCode)
+	v.MaxLen("customer_code", r.CustomerCode, 50)
+	v.Required("name", r.Name)
+	v.MaxLen("name", r.Name, 255)
+	v.OneOf("customer_type", string(r.CustomerType), string(CustomerTypeIndividual), string(CustomerTypeCompany))
+	validateCustomerDetails(&v, r.TradeName, r.TaxID, r.StateReg, r.MunicipalReg, r.Email, r.Phone, r.Mobile, r.Address)
+	return v.Problems()
+}
+
+// Validate checks the fields being changed against the customers table
+func (r *UpdateCustomerRequest) Validate() []FieldError {
+	var v Validator
+	if r.Name != nil {
+		v.Required("name", *r.Name)
+		v.MaxLen("name", *r.Name, 255)
+	}
+	if r.CustomerType != nil {
+		v.Check(*r.CustomerType != "", "customer_type", "required", "customer_type must not be empty")
+		v.OneOf("customer_type", string(*r.CustomerType), string(CustomerTypeIndividual), string(CustomerTypeCompany))
+	}
+	validateCustomerDetails(&v, r.TradeName, r.TaxID, r.StateReg, r.MunicipalReg, r.Email, r.Phone, r.Mobile, r.Address)
+	return v.Problems()
+}
+
+// validateCustomerDetails checks the optional customer columns shared by create and update
+func validateCustomerDetails(v *Validator, tradeName, taxID, stateReg, municipalReg, email, phone, mobile *string, addr *AddressInput) {
+	v.MaxLen("trade_name", deref(tradeName), 255)
+	v.MaxLen("tax_id", deref(taxID), 20)
+	v.MaxLen("state_reg", deref(stateReg), 30)
+	v.MaxLen("municipal_reg", deref(municipalReg), 30)
+	v.MaxLen("email", deref(email), 255)
+	if e := deref(email); e != "" {
+		v.Check(strings.Contains(e, "@"), "email", "email", "email must be a valid email address")
+	}
+	v.MaxLen("phone", deref(phone), 20)
+	v.MaxLen("mobile", deref(mobile), 20)
+	if addr != nil {
+		v.MaxLen("address.street", deref(addr.Street), 255)
+		v.MaxLen("address.number", deref(addr.Number), 20)
+		v.MaxLen("address.comp", deref(addr.Comp), 100)
+		v.MaxLen("address.district", deref(addr.District), 100)
+		v.MaxLen("address.city", deref(addr.City), 100)
+		v.MaxLen("address.state", deref(addr.State), 2)
+		v.MaxLen("address.zip", deref(addr.Zip), 10)
+		v.MaxLen("address.country", deref(addr.Country), 50)
+	}
+}
@@ -0,0 +1,25 @@
+package models
+
+// CustomerImportColumns are the header columns of a customer import CSV.
+// customer_code, type and name are required; the others may be omitted.
+var CustomerImportColumns = []string{"customer_code", "type", "name", "trade_name", "tax_id", "email", "phone"}
+
+// CustomerImportRequiredColumns must appear in a customer import header
+var CustomerImportRequiredColumns = []string{"customer_code", "type", "name"}
+
+// CustomerImportRowError reports why a row of a customer import was rejected
+type CustomerImportRowError struct {
+	Row     int    `json:"row"` // Line in the file; the header is line 1
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// CustomerImportReport summarizes a customer import
+type CustomerImportReport struct {
+	DryRun  bool                     `json:"dry_run"`
+	Rows    int                      `json:"rows"`    // Data rows read, excluding the header
+	Created int                      `json:"created"` // In a dry run, the rows that would be created
+	Skipped int                      `json:"skipped"` // Duplicate customer codes, in the file or already stored
+	Failed  int                      `json:"failed"`  // Rows listed in Errors
+	Errors  []CustomerImportRowError `json:"errors"`
+}
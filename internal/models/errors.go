@@ -0,0 +1,41 @@
+package models
+
+// Error codes returned in APIError.Code. Clients branch on these, so they
+// must not change once published.
+const (
+	ErrCodeInternalError     = "INTERNAL_ERROR"     // 500; details are only logged
+	ErrCodeTimeout           = "TIMEOUT"            // 504; a database statement timed out
+	ErrCodeInvalidID         = "INVALID_ID"         // 400; malformed path ID
+	ErrCodeInvalidRequest    = "INVALID_REQUEST"    // 400; malformed body or query
+	ErrCodeInvalidJSON       = "INVALID_JSON"       // 400; body is not valid JSON
+	ErrCodeValidationErr     = "VALIDATION_ERROR"   // 400; Details lists the offending fields
+	ErrCodeInvalidPriority   = "INVALID_PRIORITY"   // 400; unknown print priority
+	ErrCodeUnauthorized      = "UNAUTHORIZED"       // 401; missing or invalid credentials
+	ErrCodeTokenExpired      = "TOKEN_EXPIRED"      // 401; refresh the access token and retry
+	ErrCodeInvalidIssuer     = "INVALID_ISSUER"     // 401; token from another realm
+	ErrCodeInvalidAudience   = "INVALID_AUDIENCE"   // 401; token minted for another client
+	ErrCodeMissingTenant     = "MISSING_TENANT"     // 401; token has no tenant_id claim
+	ErrCodeForbidden         = "FORBIDDEN"          // 403; authenticated but not allowed
+	ErrCodeNotFound          = "NOT_FOUND"          // 404
+	ErrCodeFileNotFound      = "FILE_NOT_FOUND"     // 404; print output missing from storage
+	ErrCodeConflict          = "CONFLICT"           // 409; duplicate or conflicting state
+	ErrCodeInvalidStatus     = "INVALID_STATUS"     // 409; not allowed in the current status
+	ErrCodeInvalidTransition = "INVALID_TRANSITION" // 409; status change not allowed
+	ErrCodeNotReady          = "NOT_READY"          // 409; print output not ready yet
+	ErrCodeStaleGeneration   = "STALE_GENERATION"   // 409; contract changed after its latest generation, data has both times
+	ErrCodeOutputPurged      = "OUTPUT_PURGED"      // 410; print output removed by retention
+	ErrCodeTooLarge          = "PAYLOAD_TOO_LARGE"  // 413; request body over the size limit
+	ErrCodeUnsupportedType   = "UNSUPPORTED_TYPE"   // 415; upload content type not allowed
+	ErrCodeTemplateError     = "TEMPLATE_ERROR"     // 422; template could not be rendered, Details give the line
+	ErrCodeRateLimited       = "RATE_LIMITED"       // 429; see the Retry-After header
+	ErrCodeLoginLocked       = "LOGIN_LOCKED"       // 429; too many failed logins, see Retry-After
+	ErrCodeNotImplemented    = "NOT_IMPLEMENTED"    // 501; no engine for the requested output format
+)
+
+// FieldError describes why one request field was rejected
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule,omitempty"`
+	Message string `json:"message"`
+	Line    int    `json:"line,omitempty"` // Line of a multi-line value the problem is on
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// Feature flags gate modules that are rolled out tenant by tenant
+const (
+	FeatureCLM      = "clm"
+	FeatureWebhooks = "webhooks"
+)
+
+// Features lists every known feature flag
+var Features = []string{FeatureCLM, FeatureWebhooks}
+
+// IsFeature reports whether name is a known feature flag
+func IsFeature(name string) bool {
+	return slices.Contains(Features, name)
+}
+
+// Feature flag sources
+const (
+	FeatureSourceTenant  = "tenant"  // Set for the tenant
+	FeatureSourceDefault = "default" // Server default
+)
+
+// FeatureFlag is a feature flag's effective state for a tenant
+type FeatureFlag struct {
+	Name      string     `json:"name"`
+	Enabled   bool       `json:"enabled"`
+	Source    string     `json:"source"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	UpdatedBy string     `json:"updated_by,omitempty"`
+}
+
+// SetFeatureFlagRequest represents the request to set a tenant's feature flag
+type SetFeatureFlagRequest struct {
+	Enabled *bool `json:"enabled"`
+}
+
+// Validate reports every invalid field of the request
+func (r *SetFeatureFlagRequest) Validate() []FieldError {
+	var v Validator
+	v.Check(r.Enabled != nil, "enabled", "required", "enabled is required")
+	return v.Problems()
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ResponseFields maps each field a list response can be narrowed to with
+// ?fields= to a getter for its value
+type ResponseFields[T any] map[string]func(T) any
+
+// Names returns the selectable field names, sorted
+func (f ResponseFields[T]) Names() []string {
+	names := make([]string, 0, len(f))
+	for name := range f {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Parse validates a comma-separated fields parameter, returning the names
+// in the order given without repeats. An empty parameter selects nothing,
+// meaning the full response.
+func (f ResponseFields[T]) Parse(param string) ([]string, error) {
+	if strings.TrimSpace(param) == "" {
+		return nil, nil
+	}
+	var fields []string
+	for _, name := range strings.Split(param, ",") {
+		name = strings.TrimSpace(name)
+		if _, ok := f[name]; !ok {
+			return nil, fmt.Errorf("unknown field %q; valid fields are %s", name, strings.Join(f.Names(), ", "))
+		}
+		if !slices.Contains(fields, name) {
+			fields = append(fields, name)
+		}
+	}
+	return fields, nil
+}
+
+// Project narrows items to fields, which must have been returned by Parse
+func (f ResponseFields[T]) Project(items []T, fields []string) []Projection {
+	projected := make([]Projection, len(items))
+	for i, item := range items {
+		p := Projection{names: fields, values: make([]any, len(fields))}
+		for j, name := range fields {
+			p.values[j] = f[name](item)
+		}
+		projected[i] = p
+	}
+	return projected
+}
+
+// Projection is a response narrowed to some of its fields. It marshals as
+// an object holding those fields in the order they were requested.
+type Projection struct {
+	names  []string
+	values []any
+}
+
+// MarshalJSON implements json.Marshaler
+func (p Projection) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, name := range p.names {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(p.values[i])
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", name, err)
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
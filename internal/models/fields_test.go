@@ -0,0 +1,177 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func sampleCustomer(id int64) CustomerResponse {
+	at := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	return CustomerResponse{
+		ID:           id,
+		CustomerCode: fmt.Sprintf("CUST-%04d", id),
+		CustomerType: CustomerTypeCompany,
+		Name:         "Acme Printing Supplies",
+		TradeName:    "Acme",
+		TaxID:        "12.345.678/0001-90",
+		Email:        "billing@acme.example.com",
+		Phone:        "+55 11 5555-0100",
+		Mobile:       "+55 11 95555-0100",
+		Address: Address{
+			Street: "Avenida Paulista", Number: "1000", Comp: "Sala 12", District: "Bela Vista",
+			City: "São Paulo", State: "SP", Zip: "01310-100", Country: "BR",
+		},
+		Active:    true,
+		CreatedAt: at,
+		UpdatedAt: at.Add(time.Hour),
+	}
+}
+
+func sampleContract(id int64) ContractResponse {
+	start := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(1, 0, 0)
+	return ContractResponse{
+		ID:             id,
+		ContractNumber: fmt.Sprintf("CTR-%04d", id),
+		ContractType:   ContractTypeService,
+		CustomerID:     7,
+		StartDate:      start,
+		EndDate:        &end,
+		DurationMonths: 12,
+		AutoRenew:      true,
+		Subtotal:       decimal.RequireFromString("1000.00"),
+		DiscountPct:    decimal.RequireFromString("10"),
+		DiscountAmount: decimal.RequireFromString("100.00"),
+		TaxPct:         decimal.RequireFromString("5"),
+		TaxAmount:      decimal.RequireFromString("45.00"),
+		TotalValue:     decimal.RequireFromString("945.00"),
+		BillingCycle:   BillingCycleMonthly,
+		Status:         ContractStatusActive,
+		SignedAt:       &start,
+		Tags:           []string{"priority"},
+		CreatedAt:      start,
+		UpdatedAt:      start,
+	}
+}
+
+func TestResponseFieldsParse(t *testing.T) {
+	tests := []struct {
+		param   string
+		want    []string
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"  ", nil, false},
+		{"name,id", []string{"name", "id"}, false},
+		{" id , name ,id", []string{"id", "name"}, false},
+		{"id,password", nil, true},
+		{"id,", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := CustomerResponseFields.Parse(tt.param)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Parse(%q) error = %v, want error %v", tt.param, err, tt.wantErr)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("Parse(%q) = %q, want %q", tt.param, got, tt.want)
+		}
+	}
+}
+
+// checkFieldsMatchJSON checks that every selectable field projects to the
+// same value the full response marshals under that name, and that every
+// top-level field of the full response, except expanded relations, can be
+// selected
+func checkFieldsMatchJSON[T any](t *testing.T, fields ResponseFields[T], item T, relations ...string) {
+	t.Helper()
+	full := marshalObject(t, item)
+	for name := range full {
+		if _, ok := fields[name]; !ok && !slices.Contains(relations, name) {
+			t.Errorf("%s cannot be selected with ?fields=", name)
+		}
+	}
+
+	names := fields.Names()
+	projected := marshalObject(t, fields.Project([]T{item}, names)[0])
+	for _, name := range names {
+		want, ok := full[name]
+		if !ok {
+			// Omitted when empty in the full response; still selectable
+			continue
+		}
+		if string(projected[name]) != string(want) {
+			t.Errorf("%s = %s, want %s as in the full response", name, projected[name], want)
+		}
+	}
+}
+
+func marshalObject(t *testing.T, v any) map[string]json.RawMessage {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		t.Fatal(err)
+	}
+	return object
+}
+
+func TestCustomerResponseFieldsMatchJSON(t *testing.T) {
+	checkFieldsMatchJSON(t, CustomerResponseFields, sampleCustomer(1))
+}
+
+func TestContractResponseFieldsMatchJSON(t *testing.T) {
+	checkFieldsMatchJSON(t, ContractResponseFields, sampleContract(1), "customer", "items")
+}
+
+func TestProjectionKeepsRequestedFields(t *testing.T) {
+	fields, err := CustomerResponseFields.Parse("name,id,email")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(CustomerResponseFields.Project([]CustomerResponse{sampleCustomer(42)}, fields))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Requested fields only, in the order requested
+	if want := `[{"name":"Acme Printing Supplies","id":42,"email":"billing@acme.example.com"}]`; string(data) != want {
+		t.Errorf("projection = %s, want %s", data, want)
+	}
+}
+
+func TestProjectionReducesPayload(t *testing.T) {
+	customers := make([]CustomerResponse, 50)
+	for i := range customers {
+		customers[i] = sampleCustomer(int64(i + 1))
+	}
+	fields, err := CustomerResponseFields.Parse("id,name")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	full, err := json.Marshal(SuccessResponse(NewPaginatedResponse(customers, 1, 50, 500)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sparse, err := json.Marshal(SuccessResponse(NewPaginatedResponse(CustomerResponseFields.Project(customers, fields), 1, 50, 500)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Logf("page of 50 customers: %d bytes in full, %d bytes with fields=id,name", len(full), len(sparse))
+	if len(sparse)*4 > len(full) {
+		t.Errorf("fields=id,name kept %d of %d bytes, want under a quarter", len(sparse), len(full))
+	}
+	if strings.Count(string(sparse), `"name":`) != 50 || strings.Contains(string(sparse), "address") {
+		t.Error("the sparse page does not hold exactly the requested fields of every customer")
+	}
+}
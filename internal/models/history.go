@@ -15,6 +15,8 @@ const (
 	HistoryActionSign         HistoryAction = "SIGN"
 	HistoryActionPrint        HistoryAction = "PRINT"
 	HistoryActionDelete       HistoryAction = "DELETE"
+	HistoryActionAttach       HistoryAction = "ATTACH" // A file was attached; new_value is its name
+	HistoryActionDetach       HistoryAction = "DETACH" // An attachment was deleted; old_value is its name
 )
 
 // DataRetentionConfig defines retention policy for history personal data
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// InvoiceStatus represents the status of an invoice
+type InvoiceStatus string
+
+const (
+	InvoiceStatusDraft  InvoiceStatus = "DRAFT" // Created by the invoice job, not yet sent
+	InvoiceStatusIssued InvoiceStatus = "ISSUED"
+	InvoiceStatusPaid   InvoiceStatus = "PAID"
+)
+
+// Invoice is the bill for one billing period of a contract
+type Invoice struct {
+	ID            int64           `json:"id"`
+	TenantID      string          `json:"tenant_id"`
+	InvoiceNumber string          `json:"invoice_number"`
+	ContractID    int64           `json:"contract_id"`
+	PeriodNumber  int             `json:"period_number"`
+	PeriodStart   time.Time       `json:"period_start"`
+	PeriodEnd     time.Time       `json:"period_end"`
+	DueDate       time.Time       `json:"due_date"`
+	Amount        decimal.Decimal `json:"amount"`
+	Status        InvoiceStatus   `json:"status"`
+	IssuedAt      *time.Time      `json:"issued_at,omitempty"`
+	IssuedBy      string          `json:"issued_by,omitempty"`
+	PaidAt        *time.Time      `json:"paid_at,omitempty"`
+	PaidBy        string          `json:"paid_by,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+}
+
+// InvoiceNumber numbers a contract's invoice by its billing period
+func InvoiceNumber(contractNumber string, period int) string {
+	return fmt.Sprintf("%s-%03d", contractNumber, period)
+}
+
+// NextInvoiceStatus is the status each status may move to: a draft is
+// issued, and an issued invoice is paid
+var NextInvoiceStatus = map[InvoiceStatus]InvoiceStatus{
+	InvoiceStatusDraft:  InvoiceStatusIssued,
+	InvoiceStatusIssued: InvoiceStatusPaid,
+}
+
+// UpdateInvoiceStatusRequest represents the request to issue or pay an invoice
+type UpdateInvoiceStatusRequest struct {
+	Status InvoiceStatus `json:"status"`
+}
+
+// Validate checks the requested status
+func (r *UpdateInvoiceStatusRequest) Validate() []FieldError {
+	var v Validator
+	v.Required("status", string(r.Status))
+	v.OneOf("status", string(r.Status), string(InvoiceStatusIssued), string(InvoiceStatusPaid))
+	return v.Problems()
+}
@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+// NotificationPreference is a user's opt-in to print job notification emails
+type NotificationPreference struct {
+	TenantID          string    `json:"-"`
+	UserID            string    `json:"user_id"`
+	Email             string    `json:"email"`
+	PrintJobCompleted bool      `json:"print_job_completed"`
+	PrintJobFailed    bool      `json:"print_job_failed"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// Wants reports whether the preference opts in to emails for event
+func (p *NotificationPreference) Wants(event EventType) bool {
+	switch event {
+	case EventPrintJobCompleted:
+		return p.PrintJobCompleted
+	case EventPrintJobFailed:
+		return p.PrintJobFailed
+	default:
+		return false
+	}
+}
+
+// UpdateNotificationPreferenceRequest represents the request to set the caller's preference
+type UpdateNotificationPreferenceRequest struct {
+	Email             string `json:"email"`
+	PrintJobCompleted bool   `json:"print_job_completed"`
+	PrintJobFailed    bool   `json:"print_job_failed"`
+}
+
+// Notification entity types name what a notification links to
+const (
+	NotificationEntityPrintJob = "print_job"
+	NotificationEntityContract = "contract"
+)
+
+// Notification is a message in a user's in-app notification list
+type Notification struct {
+	ID         int64      `json:"id"`
+	TenantID   string     `json:"-"`
+	UserID     string     `json:"user_id"`
+	Type       EventType  `json:"type"`
+	Title      string     `json:"title"`
+	Body       string     `json:"body,omitempty"`
+	EntityType string     `json:"entity_type,omitempty"`
+	EntityID   *int64     `json:"entity_id,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ReadAt     *time.Time `json:"read_at,omitempty"`
+}
+
+// NotificationCount is the number of a user's unread notifications
+type NotificationCount struct {
+	Unread int `json:"unread"`
+}
+
+// MarkAllNotificationsReadResponse reports how many notifications were marked read
+type MarkAllNotificationsReadResponse struct {
+	Marked int `json:"marked"`
+}
@@ -10,6 +10,9 @@ const (
 	PrintJobStatusProcessing PrintJobStatus = "PROCESSING"
 	PrintJobStatusCompleted  PrintJobStatus = "COMPLETED"
 	PrintJobStatusFailed     PrintJobStatus = "FAILED"
+	PrintJobStatusDead       PrintJobStatus = "DEAD" // retries exhausted, terminal
+	PrintJobStatusCancelled  PrintJobStatus = "CANCELLED"
+	PrintJobStatusPurged     PrintJobStatus = "PURGED" // output removed by retention cleanup
 )
 
 // PrintFormat represents the output format
@@ -21,56 +24,114 @@ const (
 	PrintFormatHTML PrintFormat = "HTML"
 )
 
+// PrintPriority represents the queue priority of a print job
+type PrintPriority string
+
+const (
+	PrintPriorityLow    PrintPriority = "LOW"
+	PrintPriorityNormal PrintPriority = "NORMAL"
+	PrintPriorityHigh   PrintPriority = "HIGH"
+)
+
 // ContractPrintJob represents a contract printing job
 type ContractPrintJob struct {
+	ID             int64          `json:"id"`
+	TenantID       string         `json:"tenant_id"`
+	ContractID     int64          `json:"contract_id"`
+	Status         PrintJobStatus `json:"status"`
+	Format         PrintFormat    `json:"format"`
+	Priority       PrintPriority  `json:"priority"`
+	Watermark      bool           `json:"watermark"`
+	OutputPath     string         `json:"output_path,omitempty"`
+	StorageBackend string         `json:"storage_backend,omitempty"` // empty for jobs that predate storage backends
+	ProgressPct    int            `json:"progress_pct"`
+	FileSize       int64          `json:"file_size,omitempty"`
+	PageCount      int            `json:"page_count,omitempty"`
+	QueuedAt       time.Time      `json:"queued_at"`
+	StartedAt      *time.Time     `json:"started_at,omitempty"`
+	CompletedAt    *time.Time     `json:"completed_at,omitempty"`
+	RetryCount     int            `json:"retry_count"`
+	NextRetryAt    *time.Time     `json:"next_retry_at,omitempty"`
+	ErrorMessage   string         `json:"error_message,omitempty"`
+	RequestedBy    string         `json:"requested_by"`
+}
+
+// CreatePrintJobRequest represents the request to create a print job
+type CreatePrintJobRequest struct {
+	ContractID int64         `json:"contract_id"`
+	Format     PrintFormat   `json:"format"`
+	Priority   PrintPriority `json:"priority,omitempty"`
+	Watermark  *bool         `json:"watermark,omitempty"` // nil means true; false requires admin permission
+	// Regenerate generates the contract again when it changed after its latest generation
+	Regenerate bool `json:"regenerate,omitempty"`
+}
+
+// PrintJobFilter narrows print job listings. Zero values mean "no filter".
+// QueuedFrom is inclusive and QueuedTo is exclusive.
+type PrintJobFilter struct {
+	Status      PrintJobStatus
+	ContractID  int64
+	RequestedBy string
+	QueuedFrom  *time.Time
+	QueuedTo    *time.Time
+}
+
+// UpdatePrintJobPriorityRequest represents the request to change a queued job's priority
+type UpdatePrintJobPriorityRequest struct {
+	Priority PrintPriority `json:"priority"`
+}
+
+// PrintJobResponse represents the API response for a print job
+type PrintJobResponse struct {
 	ID           int64          `json:"id"`
-	TenantID     string         `json:"tenant_id"`
 	ContractID   int64          `json:"contract_id"`
 	Status       PrintJobStatus `json:"status"`
 	Format       PrintFormat    `json:"format"`
-	OutputPath   string         `json:"output_path,omitempty"`
+	Priority     PrintPriority  `json:"priority"`
+	Watermark    bool           `json:"watermark"`
+	ProgressPct  int            `json:"progress_pct"`
 	FileSize     int64          `json:"file_size,omitempty"`
 	PageCount    int            `json:"page_count,omitempty"`
 	QueuedAt     time.Time      `json:"queued_at"`
 	StartedAt    *time.Time     `json:"started_at,omitempty"`
 	CompletedAt  *time.Time     `json:"completed_at,omitempty"`
 	RetryCount   int            `json:"retry_count"`
+	NextRetryAt  *time.Time     `json:"next_retry_at,omitempty"`
 	ErrorMessage string         `json:"error_message,omitempty"`
 	RequestedBy  string         `json:"requested_by"`
 }
 
-// CreatePrintJobRequest represents the request to create a print job
-type CreatePrintJobRequest struct {
-	ContractID int64       `json:"contract_id"`
-	Format     PrintFormat `json:"format"`
-}
-
-// PrintJobResponse represents the API response for a print job
-type PrintJobResponse struct {
-	ID          int64          `json:"id"`
-	ContractID  int64          `json:"contract_id"`
-	Status      PrintJobStatus `json:"status"`
-	Format      PrintFormat    `json:"format"`
-	FileSize    int64          `json:"file_size,omitempty"`
-	PageCount   int            `json:"page_count,omitempty"`
-	QueuedAt    time.Time      `json:"queued_at"`
-	StartedAt   *time.Time     `json:"started_at,omitempty"`
-	CompletedAt *time.Time     `json:"completed_at,omitempty"`
-	RequestedBy string         `json:"requested_by"`
+// IsTerminal reports whether the job will not change again without user action.
+// FAILED jobs with a scheduled automatic retry are not terminal.
+func (j *ContractPrintJob) IsTerminal() bool {
+	switch j.Status {
+	case PrintJobStatusCompleted, PrintJobStatusDead, PrintJobStatusCancelled, PrintJobStatusPurged:
+		return true
+	case PrintJobStatusFailed:
+		return j.NextRetryAt == nil
+	default:
+		return false
+	}
 }
 
 // ToResponse converts a ContractPrintJob to PrintJobResponse
 func (j *ContractPrintJob) ToResponse() PrintJobResponse {
 	return PrintJobResponse{
-		ID:          j.ID,
-		ContractID:  j.ContractID,
-		Status:      j.Status,
-		Format:      j.Format,
-		FileSize:    j.FileSize,
-		PageCount:   j.PageCount,
-		QueuedAt:    j.QueuedAt,
-		StartedAt:   j.StartedAt,
-		CompletedAt: j.CompletedAt,
-		RequestedBy: j.RequestedBy,
+		ID:           j.ID,
+		ContractID:   j.ContractID,
+		Status:       j.Status,
+		Format:       j.Format,
+		Priority:     j.Priority,
+		Watermark:    j.Watermark,
+		ProgressPct:  j.ProgressPct,
+		FileSize:     j.FileSize,
+		PageCount:    j.PageCount,
+		QueuedAt:     j.QueuedAt,
+		StartedAt:    j.StartedAt,
+		CompletedAt:  j.CompletedAt,
+		RetryCount:   j.RetryCount,
+		NextRetryAt:  j.NextRetryAt,
+		ErrorMessage: j.ErrorMessage,
+		RequestedBy:  j.RequestedBy,
 	}
 }
@@ -0,0 +1,34 @@
+package models
+
+// Search result types
+const (
+	SearchTypeCustomer = "customer"
+	SearchTypeContract = "contract"
+	SearchTypeService  = "service"
+)
+
+// MinSearchQueryLength is the shortest query GET /api/v1/search accepts
+const MinSearchQueryLength = 2
+
+// SearchSummary is one search hit: enough to show it and open it
+type SearchSummary struct {
+	ID        int64  `json:"id"`
+	Type      string `json:"type"`
+	Label     string `json:"label"`
+	Secondary string `json:"secondary,omitempty"`
+}
+
+// SearchGroup holds the first hits of one type. Count is the number of
+// matches, which may exceed len(Results).
+type SearchGroup struct {
+	Count   int             `json:"count"`
+	Results []SearchSummary `json:"results"`
+}
+
+// SearchResponse groups search hits by type
+type SearchResponse struct {
+	Query     string      `json:"query"`
+	Customers SearchGroup `json:"customers"`
+	Contracts SearchGroup `json:"contracts"`
+	Services  SearchGroup `json:"services"`
+}
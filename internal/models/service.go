@@ -108,3 +108,46 @@ func (s *Service) ToResponse() ServiceResponse {
 		UpdatedAt:   s.UpdatedAt,
 	}
 }
+
+// Validate checks the request against the services table
+func (r *CreateServiceRequest) Validate() []FieldError {
+	var v Validator
+	v.Required("service_code", r.ServiceCode)
+	v.MaxLen("service_code", r.ServiceCode, 50)
+	v.Required("name", r.Name)
+	v.MaxLen("name", r.Name, 255)
+	v.Check(r.UnitPrice >= 0, "unit_price", "gte", "unit_price must not be negative")
+	validateServiceDetails(&v, r.Category, r.Subcategory, r.Currency, r.PriceUnit, r.ServiceCodeFiscal,
+		r.ISSRate, r.IRRFRate, r.PISRate, r.COFINSRate, r.CSLLRate)
+	return v.Problems()
+}
+
+// Validate checks the fields being changed against the services table
+func (r *UpdateServiceRequest) Validate() []FieldError {
+	var v Validator
+	v.MaxLen("name", r.Name, 255)
+	if r.UnitPrice != nil {
+		v.Check(*r.UnitPrice >= 0, "unit_price", "gte", "unit_price must not be negative")
+	}
+	validateServiceDetails(&v, r.Category, r.Subcategory, r.Currency, r.PriceUnit, r.ServiceCodeFiscal,
+		r.ISSRate, r.IRRFRate, r.PISRate, r.COFINSRate, r.CSLLRate)
+	return v.Problems()
+}
+
+// validateServiceDetails checks the optional service columns shared by create and update
+func validateServiceDetails(v *Validator, category, subcategory, currency string, unit PriceUnit, fiscal string, iss, irrf, pis, cofins, csll *float64) {
+	v.MaxLen("category", category, 100)
+	v.MaxLen("subcategory", subcategory, 100)
+	v.MaxLen("currency", currency, 3)
+	v.OneOf("price_unit", string(unit), string(PriceUnitHour), string(PriceUnitDay), string(PriceUnitMonth),
+		string(PriceUnitProject), string(PriceUnitUnit))
+	v.MaxLen("service_code_fiscal", fiscal, 20)
+	for _, rate := range []struct {
+		field string
+		value *float64
+	}{{"iss_rate", iss}, {"irrf_rate", irrf}, {"pis_rate", pis}, {"cofins_rate", cofins}, {"csll_rate", csll}} {
+		if rate.value != nil {
+			v.Check(*rate.value >= 0 && *rate.value <= 100, rate.field, "range", "%s must be between 0 and 100", rate.field)
+		}
+	}
+}
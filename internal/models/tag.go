@@ -0,0 +1,51 @@
+package models
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Limits on contract tags, mirrored by the contract_tags table
+const (
+	MaxContractTags = 20 // Tags per contract
+	MaxTagLength    = 40
+)
+
+// tagPattern is the form of a normalized tag: lowercase ASCII letters,
+// digits and . _ : - separators, starting with a letter or digit
+var tagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._:-]*$`)
+
+// TagUsage is a tag in use by a tenant and the number of contracts it is on
+type TagUsage struct {
+	Tag       string `json:"tag"`
+	Contracts int    `json:"contracts"`
+}
+
+// NormalizeTags trims and lowercases tags, dropping empty ones and
+// duplicates, and returns them sorted
+func NormalizeTags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+			normalized = append(normalized, tag)
+		}
+	}
+	slices.Sort(normalized)
+	return slices.Compact(normalized)
+}
+
+// validateTags records problems with tags, as they will be stored after
+// NormalizeTags
+func validateTags(v *Validator, field string, tags []string) {
+	tags = NormalizeTags(tags)
+	v.Check(len(tags) <= MaxContractTags, field, "max", "%s must have at most %d tags", field, MaxContractTags)
+	for _, tag := range tags {
+		if len(tag) > MaxTagLength {
+			v.Check(false, field, "max", "tag %q must be at most %d characters", tag, MaxTagLength)
+			continue
+		}
+		v.Check(tagPattern.MatchString(tag), field, "format",
+			"tag %q may only contain letters, digits and . _ : -", tag)
+	}
+}
@@ -0,0 +1,90 @@
+package models
+
+import "regexp"
+
+// DefaultTemplateLanguage is the language of templates created without one,
+// matching the contract_templates column default
+const DefaultTemplateLanguage = "pt-BR"
+
+var (
+	// templateCodePattern is the form of a template code: letters, digits, _ and -
+	templateCodePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+	// languagePattern is a language tag such as pt or pt-BR
+	languagePattern = regexp.MustCompile(`^[a-z]{2}(-[A-Z]{2})?$`)
+)
+
+// CreateTemplateRequest represents the request to create a contract template
+type CreateTemplateRequest struct {
+	TemplateCode string `json:"template_code"`
+	TemplateName string `json:"template_name"`
+	Language     string `json:"language,omitempty"` // DefaultTemplateLanguage when empty
+	BodyHTML     string `json:"body_html"`
+	IsDefault    bool   `json:"is_default"`
+}
+
+// Validate checks the request against the contract_templates table. The
+// body's merge fields are checked by the service.
+func (r *CreateTemplateRequest) Validate() []FieldError {
+	var v Validator
+	v.Required("template_code", r.TemplateCode)
+	v.MaxLen("template_code", r.TemplateCode, 50)
+	if r.TemplateCode != "" {
+		v.Check(templateCodePattern.MatchString(r.TemplateCode), "template_code", "format",
+			"template_code may only contain letters, digits, _ and -")
+	}
+	v.Required("template_name", r.TemplateName)
+	v.MaxLen("template_name", r.TemplateName, 255)
+	validateLanguage(&v, r.Language)
+	v.Required("body_html", r.BodyHTML)
+	return v.Problems()
+}
+
+// UpdateTemplateRequest represents the request to update a contract template.
+// Nil fields are left unchanged; a changed body bumps the version.
+type UpdateTemplateRequest struct {
+	TemplateName *string `json:"template_name,omitempty"`
+	Language     *string `json:"language,omitempty"`
+	BodyHTML     *string `json:"body_html,omitempty"`
+	IsDefault    *bool   `json:"is_default,omitempty"`
+	Active       *bool   `json:"active,omitempty"` // false retires the template without deleting it
+}
+
+// Validate checks the fields being set against the contract_templates table
+func (r *UpdateTemplateRequest) Validate() []FieldError {
+	var v Validator
+	if r.TemplateName != nil {
+		v.Required("template_name", *r.TemplateName)
+		v.MaxLen("template_name", *r.TemplateName, 255)
+	}
+	if r.Language != nil {
+		v.Required("language", *r.Language)
+		validateLanguage(&v, *r.Language)
+	}
+	if r.BodyHTML != nil {
+		v.Required("body_html", *r.BodyHTML)
+	}
+	return v.Problems()
+}
+
+// TemplatePreviewRequest selects the data a template preview is rendered
+// with: a contract, or the built-in sample data when ContractID is unset
+type TemplatePreviewRequest struct {
+	ContractID int64 `json:"contract_id,omitempty"`
+}
+
+// TemplatePreview is a template rendered for display
+type TemplatePreview struct {
+	TemplateCode string `json:"template_code"`
+	Version      int    `json:"version"`
+	ContractID   int64  `json:"contract_id,omitempty"` // Unset when rendered with sample data
+	Sample       bool   `json:"sample"`
+	HTML         string `json:"html"`
+}
+
+// validateLanguage checks a non-empty language tag
+func validateLanguage(v *Validator, language string) {
+	if language != "" {
+		v.Check(languagePattern.MatchString(language), "language", "format",
+			"language must be a language tag such as pt-BR")
+	}
+}
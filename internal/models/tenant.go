@@ -0,0 +1,175 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Tenant is an organization whose data is isolated by tenant_id
+type Tenant struct {
+	TenantID    string    `json:"tenant_id"`
+	DisplayName string    `json:"display_name"`
+	CreatedAt   time.Time `json:"created_at"`
+	CreatedBy   string    `json:"created_by,omitempty"`
+}
+
+// ContractNumberSeq is the contract number pattern placeholder for the
+// tenant's running number. Patterns may also use {YYYY}, {YY} and {MM}.
+const ContractNumberSeq = "{SEQ}"
+
+// ContractNumberParts fills in the date placeholders of a contract number
+// pattern for t and returns the text before and after {SEQ}
+func ContractNumberParts(pattern string, t time.Time) (prefix, suffix string) {
+	expanded := strings.NewReplacer(
+		"{YYYY}", t.Format("2006"),
+		"{YY}", t.Format("06"),
+		"{MM}", t.Format("01"),
+	).Replace(pattern)
+	prefix, suffix, _ = strings.Cut(expanded, ContractNumberSeq)
+	return prefix, suffix
+}
+
+// TenantSettings are a tenant's effective settings: its overrides with the
+// server defaults filled in
+type TenantSettings struct {
+	TenantID              string       `json:"tenant_id"`
+	Currency              string       `json:"currency"`
+	Locale                string       `json:"locale"`
+	ContractNumberPattern string       `json:"contract_number_pattern"`
+	WatermarkText         string       `json:"watermark_text"`
+	PrintRetentionDays    int          `json:"print_retention_days"` // 0 keeps outputs forever
+	RoundingMode          RoundingMode `json:"rounding_mode"`        // Rounding of contract amounts
+	UpdatedAt             *time.Time   `json:"updated_at,omitempty"`
+	UpdatedBy             string       `json:"updated_by,omitempty"`
+}
+
+// TenantSettingsOverrides are the settings a tenant has set; nil values
+// fall back to the server defaults. It is also the PUT /api/v1/settings
+// body, which replaces all of a tenant's overrides.
+type TenantSettingsOverrides struct {
+	Currency              *string       `json:"currency"`
+	Locale                *string       `json:"locale"`
+	ContractNumberPattern *string       `json:"contract_number_pattern"`
+	WatermarkText         *string       `json:"watermark_text"`
+	PrintRetentionDays    *int          `json:"print_retention_days"`
+	RoundingMode          *RoundingMode `json:"rounding_mode"`
+	UpdatedAt             *time.Time    `json:"-"`
+	UpdatedBy             string        `json:"-"`
+}
+
+var (
+	currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+	localePattern   = regexp.MustCompile(`^[a-z]{2,3}(-[A-Z]{2})?$`)
+)
+
+// Validate reports every invalid field of the overrides
+func (o *TenantSettingsOverrides) Validate() []FieldError {
+	var v Validator
+	if o.Currency != nil {
+		v.Check(currencyPattern.MatchString(*o.Currency), "currency", "iso4217", "currency must be a three-letter ISO 4217 code")
+	}
+	if o.Locale != nil {
+		v.Check(localePattern.MatchString(*o.Locale), "locale", "locale", "locale must look like pt-BR or en")
+	}
+	if o.ContractNumberPattern != nil {
+		v.Required("contract_number_pattern", *o.ContractNumberPattern)
+		// Leaves room in the 50 character contract number for the sequence
+		v.MaxLen("contract_number_pattern", *o.ContractNumberPattern, 40)
+		v.Check(strings.Count(*o.ContractNumberPattern, ContractNumberSeq) == 1, "contract_number_pattern", "pattern",
+			"contract_number_pattern must contain %s exactly once", ContractNumberSeq)
+	}
+	if o.WatermarkText != nil {
+		v.Required("watermark_text", *o.WatermarkText)
+		v.MaxLen("watermark_text", *o.WatermarkText, 200)
+	}
+	if o.PrintRetentionDays != nil {
+		v.Check(*o.PrintRetentionDays >= 0 && *o.PrintRetentionDays <= 99999, "print_retention_days", "range",
+			"print_retention_days must be between 0 and 99999")
+	}
+	if o.RoundingMode != nil {
+		v.Required("rounding_mode", string(*o.RoundingMode))
+		v.OneOf("rounding_mode", string(*o.RoundingMode), roundingModes...)
+	}
+	return v.Problems()
+}
+
+// Apply returns defaults with the overrides applied
+func (o *TenantSettingsOverrides) Apply(defaults TenantSettings) TenantSettings {
+	s := defaults
+	if o == nil {
+		return s
+	}
+	if o.Currency != nil {
+		s.Currency = *o.Currency
+	}
+	if o.Locale != nil {
+		s.Locale = *o.Locale
+	}
+	if o.ContractNumberPattern != nil {
+		s.ContractNumberPattern = *o.ContractNumberPattern
+	}
+	if o.WatermarkText != nil {
+		s.WatermarkText = *o.WatermarkText
+	}
+	if o.PrintRetentionDays != nil {
+		s.PrintRetentionDays = *o.PrintRetentionDays
+	}
+	if o.RoundingMode != nil {
+		s.RoundingMode = *o.RoundingMode
+	}
+	s.UpdatedAt = o.UpdatedAt
+	s.UpdatedBy = o.UpdatedBy
+	return s
+}
+
+// ProvisionTenantRequest represents the request to provision a tenant
+type ProvisionTenantRequest struct {
+	TenantID    string `json:"tenant_id"`
+	DisplayName string `json:"display_name"`
+}
+
+// Validate reports every invalid field of the request
+func (r *ProvisionTenantRequest) Validate() []FieldError {
+	var v Validator
+	v.Required("tenant_id", r.TenantID)
+	v.MaxLen("tenant_id", r.TenantID, 100)
+	v.Required("display_name", r.DisplayName)
+	v.MaxLen("display_name", r.DisplayName, 200)
+	return v.Problems()
+}
+
+// Provisioning steps
+const (
+	ProvisionStepTenant   = "tenant"
+	ProvisionStepTemplate = "default_template"
+	ProvisionStepSettings = "settings"
+)
+
+// Provisioning step outcomes
+const (
+	ProvisionCreated = "created"
+	ProvisionSkipped = "skipped" // already present
+)
+
+// ProvisionStep is the outcome of one provisioning step
+type ProvisionStep struct {
+	Step   string `json:"step"`
+	Status string `json:"status"`
+}
+
+// ProvisionResult summarizes a provisioning run
+type ProvisionResult struct {
+	TenantID string          `json:"tenant_id"`
+	Steps    []ProvisionStep `json:"steps"`
+}
+
+// TenantStatus reports which provisioning steps a tenant has completed
+type TenantStatus struct {
+	TenantID        string `json:"tenant_id"`
+	DisplayName     string `json:"display_name,omitempty"`
+	Tenant          bool   `json:"tenant"`
+	DefaultTemplate bool   `json:"default_template"`
+	Settings        bool   `json:"settings"`
+	Complete        bool   `json:"complete"`
+}
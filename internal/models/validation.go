@@ -0,0 +1,89 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Validator collects field problems for a request's Validate method. Request
+// types validate what the database would otherwise reject (required columns,
+// VARCHAR2 sizes, CHECK constraints) so clients get a 400 listing every
+// problem instead of an Oracle error.
+type Validator struct {
+	problems []FieldError
+}
+
+// Problems returns the collected problems, nil when the request is valid
+func (v *Validator) Problems() []FieldError {
+	return v.problems
+}
+
+// Check records a problem when ok is false
+func (v *Validator) Check(ok bool, field, rule, format string, args ...any) {
+	if !ok {
+		v.problems = append(v.problems, FieldError{Field: field, Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+}
+
+// Required checks that a string is not blank
+func (v *Validator) Required(field, value string) {
+	v.Check(strings.TrimSpace(value) != "", field, "required", "%s is required", field)
+}
+
+// MaxLen checks a string against a VARCHAR2 column size, which Oracle counts in bytes
+func (v *Validator) MaxLen(field, value string, max int) {
+	v.Check(len(value) <= max, field, "max", "%s must be at most %d bytes", field, max)
+}
+
+// OneOf checks that a non-empty value is one of the allowed values
+func (v *Validator) OneOf(field, value string, allowed ...string) {
+	if value == "" {
+		return
+	}
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	v.Check(false, field, "oneof", "%s must be one of %s", field, strings.Join(allowed, ", "))
+}
+
+// PositiveID checks that a referenced ID is set
+func (v *Validator) PositiveID(field string, id int64) {
+	v.Check(id > 0, field, "gt", "%s must be a positive ID", field)
+}
+
+// Positive checks that a decimal is greater than zero
+func (v *Validator) Positive(field string, d decimal.Decimal) {
+	v.Check(d.IsPositive(), field, "gt", "%s must be greater than 0", field)
+}
+
+// NonNegative checks that a decimal is zero or more
+func (v *Validator) NonNegative(field string, d decimal.Decimal) {
+	v.Check(!d.IsNegative(), field, "gte", "%s must not be negative", field)
+}
+
+// Percent checks that a decimal lies between 0 and 100
+func (v *Validator) Percent(field string, d decimal.Decimal) {
+	v.Check(!d.IsNegative() && d.LessThanOrEqual(decimal.NewFromInt(100)), field, "range",
+		"%s must be between 0 and 100", field)
+}
+
+// After checks that end, when both are set, is after start
+func (v *Validator) After(endField string, end, start *time.Time, startField string) {
+	if end == nil || start == nil {
+		return
+	}
+	v.Check(end.After(*start), endField, "after", "%s must be after %s", endField, startField)
+}
+
+// deref returns the value of an optional string, or "" when it is nil
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
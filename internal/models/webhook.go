@@ -0,0 +1,121 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// EventType identifies an event that webhooks can subscribe to
+type EventType string
+
+const (
+	EventPrintJobCompleted EventType = "print_job.completed"
+	EventPrintJobFailed    EventType = "print_job.failed" // retries exhausted or not scheduled
+	EventContractSigned    EventType = "contract.signed"
+)
+
+// IsValid reports whether e is a known event type
+func (e EventType) IsValid() bool {
+	switch e {
+	case EventPrintJobCompleted, EventPrintJobFailed, EventContractSigned:
+		return true
+	default:
+		return false
+	}
+}
+
+// WebhookDeliveryStatus represents the state of a single webhook delivery
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "PENDING" // waiting for its first or next attempt
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "DELIVERED"
+	WebhookDeliveryDead      WebhookDeliveryStatus = "DEAD" // attempts exhausted, terminal
+)
+
+// Webhook represents a tenant's subscription to events
+type Webhook struct {
+	ID         int64       `json:"id"`
+	TenantID   string      `json:"tenant_id"`
+	URL        string      `json:"url"`
+	Secret     string      `json:"-"`
+	EventTypes []EventType `json:"event_types"`
+	Active     bool        `json:"active"`
+	CreatedAt  time.Time   `json:"created_at"`
+	CreatedBy  string      `json:"created_by,omitempty"`
+}
+
+// Subscribes reports whether the webhook wants events of type e
+func (w *Webhook) Subscribes(e EventType) bool {
+	for _, t := range w.EventTypes {
+		if t == e {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateWebhookRequest represents the request to register a webhook.
+// A secret is generated when none is supplied.
+type CreateWebhookRequest struct {
+	URL        string      `json:"url"`
+	Secret     string      `json:"secret,omitempty"`
+	EventTypes []EventType `json:"event_types"`
+}
+
+// WebhookResponse represents the API response for a webhook. The secret is
+// only included in the response to the create request.
+type WebhookResponse struct {
+	ID         int64       `json:"id"`
+	URL        string      `json:"url"`
+	Secret     string      `json:"secret,omitempty"`
+	EventTypes []EventType `json:"event_types"`
+	Active     bool        `json:"active"`
+	CreatedAt  time.Time   `json:"created_at"`
+	CreatedBy  string      `json:"created_by,omitempty"`
+}
+
+// ToResponse converts a Webhook to WebhookResponse without its secret
+func (w *Webhook) ToResponse() WebhookResponse {
+	return WebhookResponse{
+		ID:         w.ID,
+		URL:        w.URL,
+		EventTypes: w.EventTypes,
+		Active:     w.Active,
+		CreatedAt:  w.CreatedAt,
+		CreatedBy:  w.CreatedBy,
+	}
+}
+
+// WebhookDelivery represents one attempt series to deliver an event to a webhook
+type WebhookDelivery struct {
+	ID             int64                 `json:"id"`
+	TenantID       string                `json:"tenant_id"`
+	WebhookID      int64                 `json:"webhook_id"`
+	EventType      EventType             `json:"event_type"`
+	Payload        string                `json:"-"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	Attempts       int                   `json:"attempts"`
+	NextAttemptAt  *time.Time            `json:"next_attempt_at,omitempty"`
+	LastStatusCode int                   `json:"last_status_code,omitempty"`
+	LastError      string                `json:"last_error,omitempty"`
+	CreatedAt      time.Time             `json:"created_at"`
+	DeliveredAt    *time.Time            `json:"delivered_at,omitempty"`
+}
+
+// WebhookEvent is the JSON body POSTed to webhook receivers
+type WebhookEvent struct {
+	ID         string          `json:"id"` // unique per event; the same across retries so receivers can deduplicate
+	Event      EventType       `json:"event"`
+	TenantID   string          `json:"tenant_id"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// ContractSignedEvent is the data sent with contract.signed events
+type ContractSignedEvent struct {
+	ContractID     int64      `json:"contract_id"`
+	ContractNumber string     `json:"contract_number"`
+	SignedBy       string     `json:"signed_by"`
+	SignedAt       *time.Time `json:"signed_at,omitempty"`
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/zlovtnik/gprint/internal/models"
+)
+
+// TableAPIClients is the table name for service account clients
+const TableAPIClients = "API_CLIENTS"
+
+// APIClientRepository handles API client data access
+type APIClientRepository struct {
+	db *DB
+}
+
+// NewAPIClientRepository creates a new APIClientRepository
+func NewAPIClientRepository(db *DB) (*APIClientRepository, error) {
+	if db == nil {
+		return nil, fmt.Errorf("NewAPIClientRepository: db is nil")
+	}
+	return &APIClientRepository{db: db}, nil
+}
+
+// GetByClientID retrieves a client by its Keycloak client ID, returning nil when it is not registered
+func (r *APIClientRepository) GetByClientID(ctx context.Context, clientID string) (*models.APIClient, error) {
+	query := `SELECT client_id, tenant_id, scopes, active, description, created_at
+		FROM ` + TableAPIClients + `
+		WHERE client_id = :1`
+
+	var client models.APIClient
+	var scopes, description sql.NullString
+	var active int
+	err := r.db.QueryRowContext(ctx, query, clientID).Scan(
+		&client.ClientID, &client.TenantID, &scopes, &active, &description, &client.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API client: %w", err)
+	}
+
+	client.Scopes = splitScopes(scopes.String)
+	client.Active = IntToBool(active)
+	client.Description = description.String
+	return &client, nil
+}
+
+// splitScopes parses the comma-separated scopes column
+func splitScopes(s string) []string {
+	var scopes []string
+	for _, scope := range strings.Split(s, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/zlovtnik/gprint/internal/models"
+)
+
+// TableContractAttachments is the contract attachments table name
+const TableContractAttachments = "CONTRACT_ATTACHMENTS"
+
+// attachmentSelectColumns is the column list read by scanAttachment, in scan order
+const attachmentSelectColumns = `id, tenant_id, contract_id, file_name, content_type, size_bytes,
+			checksum_sha256, storage_backend, storage_key, uploaded_by, created_at`
+
+// ContractAttachmentRepository handles contract attachment metadata
+type ContractAttachmentRepository struct {
+	db *DB
+}
+
+// NewContractAttachmentRepository creates a new ContractAttachmentRepository
+func NewContractAttachmentRepository(db *DB) *ContractAttachmentRepository {
+	if db == nil {
+		panic("ContractAttachmentRepository: db is nil")
+	}
+	return &ContractAttachmentRepository{db: db}
+}
+
+// Create records an attachment whose file has been stored, returning it
+// with its ID
+func (r *ContractAttachmentRepository) Create(ctx context.Context, a *models.ContractAttachment) (*models.ContractAttachment, error) {
+	var id int64
+	query := `INSERT INTO ` + TableContractAttachments + ` (tenant_id, contract_id, file_name, content_type,
+			size_bytes, checksum_sha256, storage_backend, storage_key, uploaded_by)
+		VALUES (:1, :2, :3, :4, :5, :6, :7, :8, :9)
+		RETURNING id INTO :10`
+	_, err := r.db.ExecContext(ctx, query,
+		a.TenantID, a.ContractID, a.FileName, a.ContentType,
+		a.SizeBytes, a.Checksum, a.StorageBackend, a.StorageKey, a.UploadedBy,
+		sql.Out{Dest: &id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create contract attachment: %w", err)
+	}
+	return r.GetByID(ctx, a.TenantID, a.ContractID, id)
+}
+
+// GetByID retrieves an attachment of a contract by ID
+func (r *ContractAttachmentRepository) GetByID(ctx context.Context, tenantID string, contractID, id int64) (*models.ContractAttachment, error) {
+	query := `SELECT ` + attachmentSelectColumns + ` FROM ` + TableContractAttachments + `
+		WHERE tenant_id = :1 AND contract_id = :2 AND id = :3`
+	a, err := scanAttachment(r.db.QueryRowContext(ctx, query, tenantID, contractID, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contract attachment: %w", err)
+	}
+	return &a, nil
+}
+
+// ListByContract retrieves a page of a contract's attachments, newest first
+func (r *ContractAttachmentRepository) ListByContract(ctx context.Context, tenantID string, contractID int64, params models.PaginationParams) ([]models.ContractAttachment, int, error) {
+	qb := NewQueryBuilder(2)
+	qb.AddCondition("contract_id = :%d", contractID)
+	return listPage(ctx, r.db, tenantID, pageQuery{
+		noun:    "contract attachments",
+		table:   TableContractAttachments,
+		columns: attachmentSelectColumns,
+		filter:  qb,
+		orderBy: "created_at DESC, id DESC",
+		offset:  params.Offset(),
+		limit:   params.Limit(),
+	}, scanAttachment)
+}
+
+// Delete removes an attachment's metadata
+func (r *ContractAttachmentRepository) Delete(ctx context.Context, tenantID string, contractID, id int64) error {
+	result, err := r.db.ExecContext(ctx,
+		`DELETE FROM `+TableContractAttachments+` WHERE tenant_id = :1 AND contract_id = :2 AND id = :3`,
+		tenantID, contractID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete contract attachment: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf(errFmtRowsAffected, err)
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func scanAttachment(scanner rowScanner) (models.ContractAttachment, error) {
+	var a models.ContractAttachment
+	err := scanner.Scan(
+		&a.ID, &a.TenantID, &a.ContractID, &a.FileName, &a.ContentType, &a.SizeBytes,
+		&a.Checksum, &a.StorageBackend, &a.StorageKey, &a.UploadedBy, &a.CreatedAt,
+	)
+	return a, err
+}
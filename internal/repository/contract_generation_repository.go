@@ -5,21 +5,36 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"io"
+	"strings"
 
+	"github.com/godror/godror"
 	"github.com/zlovtnik/gprint/internal/models"
 )
 
+// Table names for contract generation
+const (
+	TableContractTemplates     = "CONTRACT_TEMPLATES"
+	TableGeneratedContracts    = "GENERATED_CONTRACTS"
+	TableContractGenerationLog = "CONTRACT_GENERATION_LOG"
+)
+
+func init() {
+	RegisterTable(TableContractTemplates, TableOptions{SoftDelete: true})
+	RegisterTable(TableGeneratedContracts, TableOptions{})
+}
+
 // ErrUnauthorized is returned when a tenant tries to access another tenant's data
 var ErrUnauthorized = errors.New("unauthorized: tenant does not own this resource")
 
 // ContractGenerationRepository handles contract generation data access
 // All sensitive operations are delegated to PL/SQL package for security
 type ContractGenerationRepository struct {
-	db *sql.DB
+	db *DB
 }
 
 // NewContractGenerationRepository creates a new ContractGenerationRepository
-func NewContractGenerationRepository(db *sql.DB) *ContractGenerationRepository {
+func NewContractGenerationRepository(db *DB) *ContractGenerationRepository {
 	return &ContractGenerationRepository{db: db}
 }
 
@@ -34,13 +49,66 @@ type GenerateContractParams struct {
 	SessionID    string
 }
 
+// ErrContentTooLarge is returned when generated content is over
+// MaxBufferedContent and must be streamed instead
+var ErrContentTooLarge = errors.New("generated content is too large to buffer")
+
+// MaxBufferedContent bounds the generated JSON the buffering accessors read
+// into memory
+const MaxBufferedContent = 32 << 20
+
+// lobReader returns the reader of a CLOB out bind, empty when it was NULL
+func lobReader(lob *godror.Lob) io.Reader {
+	if lob.Reader == nil {
+		return strings.NewReader("")
+	}
+	return lob
+}
+
+// readContent buffers generated content of up to MaxBufferedContent bytes
+func readContent(content io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(content, MaxBufferedContent+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read generated content: %w", err)
+	}
+	if len(data) > MaxBufferedContent {
+		return nil, ErrContentTooLarge
+	}
+	return data, nil
+}
+
 // GenerateContract calls the PL/SQL package to generate a contract document
 // All sensitive data processing happens in the database layer
-// Returns both the result metadata and the generated JSON for immediate use
+// Returns both the result metadata and the generated JSON for immediate use,
+// buffered up to MaxBufferedContent; GenerateContractStream streams it.
 func (r *ContractGenerationRepository) GenerateContract(
 	ctx context.Context,
 	params GenerateContractParams,
 ) (*models.GenerateContractResponse, error) {
+	var resp *models.GenerateContractResponse
+	err := r.GenerateContractStream(ctx, params, func(result *models.GenerateContractResponse, content io.Reader) error {
+		if result.Success {
+			data, err := readContent(content)
+			if err != nil {
+				return err
+			}
+			result.ContractJSON = data
+		}
+		resp = result
+		return nil
+	})
+	return resp, err
+}
+
+// GenerateContractStream generates a contract like GenerateContract, then
+// calls fn with the result, whose ContractJSON is left empty, and the
+// generated JSON read from its CLOB in chunks. content is only readable
+// until fn returns; fn's error is returned as is.
+func (r *ContractGenerationRepository) GenerateContractStream(
+	ctx context.Context,
+	params GenerateContractParams,
+	fn func(result *models.GenerateContractResponse, content io.Reader) error,
+) error {
 	// Use PL/SQL anonymous block to call the package procedure
 	// The procedure returns both result object fields and the JSON content
 	query := `
@@ -73,10 +141,20 @@ func (r *ContractGenerationRepository) GenerateContract(
 		contentHash  sql.NullString
 		errorCode    sql.NullString
 		errorMessage sql.NullString
-		contractJSON sql.NullString
+		contractJSON = godror.Lob{IsClob: true}
+		fnErr        error
 	)
 
-	_, err := r.db.ExecContext(ctx, query,
+	err := r.db.ExecLOBs(ctx, query, func() error {
+		fnErr = fn(&models.GenerateContractResponse{
+			Success:      success == 1,
+			GeneratedID:  generatedID.Int64,
+			ContentHash:  contentHash.String,
+			ErrorCode:    errorCode.String,
+			ErrorMessage: errorMessage.String,
+		}, lobReader(&contractJSON))
+		return nil
+	},
 		params.TenantID,
 		params.ContractID,
 		params.UserID,
@@ -92,27 +170,43 @@ func (r *ContractGenerationRepository) GenerateContract(
 		sql.Out{Dest: &contractJSON},
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to call generate_contract: %w", err)
+		return fmt.Errorf("failed to call generate_contract: %w", err)
 	}
-
-	return &models.GenerateContractResponse{
-		Success:      success == 1,
-		GeneratedID:  generatedID.Int64,
-		ContentHash:  contentHash.String,
-		ContractJSON: []byte(contractJSON.String),
-		ErrorCode:    errorCode.String,
-		ErrorMessage: errorMessage.String,
-	}, nil
+	return fnErr
 }
 
 // GetGeneratedContent retrieves generated contract content with tenant validation
-// Content is only returned after proper tenant verification in PL/SQL
+// Content is only returned after proper tenant verification in PL/SQL. The
+// JSON is buffered up to MaxBufferedContent; StreamGeneratedContent streams it.
 func (r *ContractGenerationRepository) GetGeneratedContent(
 	ctx context.Context,
 	tenantID string,
 	generatedID int64,
 	userID string,
 ) (*models.GetGeneratedContentResponse, error) {
+	var resp *models.GetGeneratedContentResponse
+	err := r.StreamGeneratedContent(ctx, tenantID, generatedID, userID, func(meta *models.GeneratedContentMeta, content io.Reader) error {
+		data, err := readContent(content)
+		if err != nil {
+			return err
+		}
+		resp = &models.GetGeneratedContentResponse{GeneratedContentMeta: *meta, ContractJSON: data}
+		return nil
+	})
+	return resp, err
+}
+
+// StreamGeneratedContent retrieves a generated version like
+// GetGeneratedContent, then calls fn with its metadata and the JSON read
+// from its CLOB in chunks. content is only readable until fn returns; fn's
+// error is returned as is.
+func (r *ContractGenerationRepository) StreamGeneratedContent(
+	ctx context.Context,
+	tenantID string,
+	generatedID int64,
+	userID string,
+	fn func(meta *models.GeneratedContentMeta, content io.Reader) error,
+) error {
 	query := `
 		DECLARE
 			v_json_data    CLOB;
@@ -139,14 +233,31 @@ func (r *ContractGenerationRepository) GetGeneratedContent(
 		END;`
 
 	var (
-		jsonData    sql.NullString
+		jsonData    = godror.Lob{IsClob: true}
 		contentHash sql.NullString
 		generatedAt sql.NullTime
 		success     int
 		errorCode   sql.NullString
+		fnErr       error
 	)
 
-	_, err := r.db.ExecContext(ctx, query,
+	err := r.db.ExecLOBs(ctx, query, func() error {
+		if success != 1 {
+			fnErr = fmt.Errorf("access denied: %s", errorCode.String)
+			return nil
+		}
+		// Validate that generatedAt is valid before using it
+		if !generatedAt.Valid {
+			fnErr = fmt.Errorf("generated_at is NULL for generatedID %d", generatedID)
+			return nil
+		}
+		fnErr = fn(&models.GeneratedContentMeta{
+			GeneratedID: generatedID,
+			ContentHash: contentHash.String,
+			GeneratedAt: generatedAt.Time,
+		}, lobReader(&jsonData))
+		return nil
+	},
 		tenantID,
 		generatedID,
 		userID,
@@ -157,33 +268,58 @@ func (r *ContractGenerationRepository) GetGeneratedContent(
 		sql.Out{Dest: &errorCode},
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get generated content: %w", err)
-	}
-
-	if success != 1 {
-		return nil, fmt.Errorf("access denied: %s", errorCode.String)
-	}
-
-	// Validate that generatedAt is valid before using it
-	if !generatedAt.Valid {
-		return nil, fmt.Errorf("generated_at is NULL for generatedID %d", generatedID)
+		return fmt.Errorf("failed to get generated content: %w", err)
 	}
-
-	return &models.GetGeneratedContentResponse{
-		GeneratedID:  generatedID,
-		ContentHash:  contentHash.String,
-		GeneratedAt:  generatedAt.Time,
-		ContractJSON: []byte(jsonData.String),
-	}, nil
+	return fnErr
 }
 
-// GetLatestGenerated retrieves the most recent generated version for a contract
+// GetLatestGenerated retrieves the most recent generated version for a
+// contract, with its JSON buffered up to MaxBufferedContent
 func (r *ContractGenerationRepository) GetLatestGenerated(
 	ctx context.Context,
 	tenantID string,
 	contractID int64,
 	userID string,
 ) (*models.GetGeneratedContentResponse, error) {
+	var resp *models.GetGeneratedContentResponse
+	err := r.StreamLatestGenerated(ctx, tenantID, contractID, userID, func(meta *models.GeneratedContentMeta, content io.Reader) error {
+		data, err := readContent(content)
+		if err != nil {
+			return err
+		}
+		resp = &models.GetGeneratedContentResponse{GeneratedContentMeta: *meta, ContractJSON: data}
+		return nil
+	})
+	return resp, err
+}
+
+// GetLatestGeneratedMeta retrieves the most recent generated version for a
+// contract without reading its content
+func (r *ContractGenerationRepository) GetLatestGeneratedMeta(
+	ctx context.Context,
+	tenantID string,
+	contractID int64,
+	userID string,
+) (*models.GeneratedContentMeta, error) {
+	var latest *models.GeneratedContentMeta
+	err := r.StreamLatestGenerated(ctx, tenantID, contractID, userID, func(meta *models.GeneratedContentMeta, _ io.Reader) error {
+		latest = meta
+		return nil
+	})
+	return latest, err
+}
+
+// StreamLatestGenerated retrieves the most recent generated version like
+// GetLatestGenerated, then calls fn with its metadata and the JSON read
+// from its CLOB in chunks. content is only readable until fn returns; fn's
+// error is returned as is.
+func (r *ContractGenerationRepository) StreamLatestGenerated(
+	ctx context.Context,
+	tenantID string,
+	contractID int64,
+	userID string,
+	fn func(meta *models.GeneratedContentMeta, content io.Reader) error,
+) error {
 	query := `
 		DECLARE
 			v_json_data    CLOB;
@@ -213,15 +349,33 @@ func (r *ContractGenerationRepository) GetLatestGenerated(
 		END;`
 
 	var (
-		jsonData    sql.NullString
+		jsonData    = godror.Lob{IsClob: true}
 		contentHash sql.NullString
 		generatedID sql.NullInt64
 		generatedAt sql.NullTime
 		success     int
 		errorCode   sql.NullString
+		fnErr       error
 	)
 
-	_, err := r.db.ExecContext(ctx, query,
+	err := r.db.ExecLOBs(ctx, query, func() error {
+		switch {
+		case success != 1:
+			fnErr = ErrNotFound
+		// Validate that generatedID and generatedAt are valid before using them
+		case !generatedID.Valid:
+			fnErr = fmt.Errorf("generated contract ID is NULL despite success: generatedID.Valid=false")
+		case !generatedAt.Valid:
+			fnErr = fmt.Errorf("generated_at is NULL for generatedID %d", generatedID.Int64)
+		default:
+			fnErr = fn(&models.GeneratedContentMeta{
+				GeneratedID: generatedID.Int64,
+				ContentHash: contentHash.String,
+				GeneratedAt: generatedAt.Time,
+			}, lobReader(&jsonData))
+		}
+		return nil
+	},
 		tenantID,
 		contractID,
 		userID,
@@ -233,29 +387,9 @@ func (r *ContractGenerationRepository) GetLatestGenerated(
 		sql.Out{Dest: &errorCode},
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get latest generated: %w", err)
-	}
-
-	if success != 1 {
-		return nil, ErrNotFound
+		return fmt.Errorf("failed to get latest generated: %w", err)
 	}
-
-	// Validate that generatedID is valid before using it
-	if !generatedID.Valid {
-		return nil, fmt.Errorf("generated contract ID is NULL despite success: generatedID.Valid=false")
-	}
-
-	// Validate that generatedAt is valid before using it
-	if !generatedAt.Valid {
-		return nil, fmt.Errorf("generated_at is NULL for generatedID %d", generatedID.Int64)
-	}
-
-	return &models.GetGeneratedContentResponse{
-		GeneratedID:  generatedID.Int64,
-		ContentHash:  contentHash.String,
-		GeneratedAt:  generatedAt.Time,
-		ContractJSON: []byte(jsonData.String),
-	}, nil
+	return fnErr
 }
 
 // LogActionParams holds parameters for logging a contract action
@@ -423,6 +557,139 @@ func (r *ContractGenerationRepository) GetGenerationStats(
 	return &stats, nil
 }
 
+// GetContractGenerationStats retrieves generation statistics for one
+// contract, with the VIEW, DOWNLOAD and PRINT actions logged on it. Returns
+// ErrNotFound when the contract does not belong to the tenant.
+func (r *ContractGenerationRepository) GetContractGenerationStats(
+	ctx context.Context,
+	tenantID string,
+	contractID int64,
+) (*models.ContractGenerationStats, error) {
+	query := `
+		SELECT g.generations, g.first_at, g.last_at, g.users, l.views, l.downloads, l.prints
+		FROM contracts c
+		CROSS JOIN (
+			SELECT COUNT(*) generations, MIN(generated_at) first_at, MAX(generated_at) last_at,
+			       COUNT(DISTINCT generated_by) users
+			FROM ` + TableGeneratedContracts + `
+			WHERE tenant_id = :1 AND contract_id = :2
+		) g
+		CROSS JOIN (
+			SELECT COUNT(CASE WHEN action = 'VIEW' THEN 1 END) views,
+			       COUNT(CASE WHEN action = 'DOWNLOAD' THEN 1 END) downloads,
+			       COUNT(CASE WHEN action = 'PRINT' THEN 1 END) prints
+			FROM ` + TableContractGenerationLog + `
+			WHERE tenant_id = :3 AND contract_id = :4
+		) l
+		WHERE c.tenant_id = :5 AND c.id = :6`
+
+	stats := models.ContractGenerationStats{ContractID: contractID}
+	var firstAt, lastAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, query,
+		tenantID, contractID, tenantID, contractID, tenantID, contractID,
+	).Scan(
+		&stats.GenerationCount,
+		&firstAt,
+		&lastAt,
+		&stats.DistinctUsers,
+		&stats.ViewCount,
+		&stats.DownloadCount,
+		&stats.PrintCount,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contract generation stats: %w", err)
+	}
+	if firstAt.Valid {
+		stats.FirstGeneratedAt = &firstAt.Time
+	}
+	if lastAt.Valid {
+		stats.LastGeneratedAt = &lastAt.Time
+	}
+	return &stats, nil
+}
+
+// contractActionSelectColumns is the select list read by scanContractAction
+const contractActionSelectColumns = `id, contract_id, generated_id, action, action_status,
+	performed_by, performed_at, error_code`
+
+// scanContractAction scans a row selecting contractActionSelectColumns
+func scanContractAction(scanner rowScanner) (models.ContractActionLogEntry, error) {
+	var entry models.ContractActionLogEntry
+	var generatedID sql.NullInt64
+	var action string
+	var errorCode sql.NullString
+	err := scanner.Scan(
+		&entry.ID,
+		&entry.ContractID,
+		&generatedID,
+		&action,
+		&entry.Status,
+		&entry.PerformedBy,
+		&entry.PerformedAt,
+		&errorCode,
+	)
+	if err != nil {
+		return entry, err
+	}
+	if generatedID.Valid {
+		entry.GeneratedID = &generatedID.Int64
+	}
+	entry.Action = models.ContractGenerationAction(action)
+	entry.ErrorCode = errorCode.String
+	return entry, nil
+}
+
+// ListContractActions lists the actions logged on a contract matching
+// filter, newest first, with the number matching. Returns ErrNotFound when
+// the contract does not belong to the tenant.
+func (r *ContractGenerationRepository) ListContractActions(
+	ctx context.Context,
+	tenantID string,
+	contractID int64,
+	filter models.ContractActionFilter,
+	offset int,
+	limit int,
+) ([]models.ContractActionLogEntry, int, error) {
+	var exists int
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM contracts WHERE tenant_id = :1 AND id = :2`, tenantID, contractID,
+	).Scan(&exists)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to check contract: %w", err)
+	}
+	if exists == 0 {
+		return nil, 0, ErrNotFound
+	}
+
+	qb := NewQueryBuilder(2)
+	qb.AddCondition("contract_id = :%d", contractID)
+	if filter.Action != "" {
+		qb.AddCondition("action = :%d", string(filter.Action))
+	}
+	if filter.PerformedBy != "" {
+		qb.AddCondition("performed_by = :%d", filter.PerformedBy)
+	}
+	entries, total, err := listPage(ctx, r.db, tenantID, pageQuery{
+		noun:    "contract actions",
+		table:   TableContractGenerationLog,
+		columns: contractActionSelectColumns,
+		filter:  qb,
+		orderBy: "performed_at DESC, id DESC",
+		offset:  offset,
+		limit:   limit,
+	}, scanContractAction)
+	if err != nil {
+		return nil, 0, err
+	}
+	if entries == nil {
+		entries = []models.ContractActionLogEntry{}
+	}
+	return entries, total, nil
+}
+
 // VerifyContentIntegrity verifies the integrity of a generated contract.
 // Enforces tenant isolation by checking COUNT(*) WHERE id=:1 AND tenant_id=:2 first.
 //
@@ -480,52 +747,86 @@ func (r *ContractGenerationRepository) VerifyContentIntegrity(
 	}
 }
 
-// ListTemplates lists all active templates for a tenant
-func (r *ContractGenerationRepository) ListTemplates(
+// ListGenerationsAfter lists up to limit of a tenant's generated versions
+// with IDs above afterID, in ID order, for walking them in batches
+func (r *ContractGenerationRepository) ListGenerationsAfter(
 	ctx context.Context,
 	tenantID string,
-) ([]models.ContractTemplate, error) {
-	query := `
-		SELECT id, tenant_id, template_code, template_name, language,
-		       is_default, active, version, created_at, updated_at
-		FROM contract_templates
-		WHERE tenant_id = :1 AND active = 1
-		ORDER BY is_default DESC, template_name`
-
-	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	afterID int64,
+	limit int,
+) ([]models.SweptGeneration, error) {
+	query := `SELECT id, contract_id, generation_number, content_hash, generated_at
+		FROM generated_contracts
+		WHERE tenant_id = :1 AND id > :2
+		ORDER BY id
+		FETCH FIRST :3 ROWS ONLY`
+	rows, err := r.db.QueryContext(ctx, query, tenantID, afterID, limit)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list templates: %w", err)
+		return nil, fmt.Errorf("failed to list generated contracts: %w", err)
 	}
 	defer rows.Close()
 
-	var templates []models.ContractTemplate
+	var gens []models.SweptGeneration
 	for rows.Next() {
-		var t models.ContractTemplate
-		var isDefault, active int
-		var createdAt, updatedAt sql.NullTime
-		err := rows.Scan(
-			&t.ID, &t.TenantID, &t.TemplateCode, &t.TemplateName, &t.Language,
-			&isDefault, &active, &t.Version, &createdAt, &updatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan template: %w", err)
+		var g models.SweptGeneration
+		if err := rows.Scan(&g.GeneratedID, &g.ContractID, &g.GenerationNumber, &g.ContentHash, &g.GeneratedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan generated contract: %w", err)
 		}
-		t.IsDefault = isDefault == 1
-		t.Active = active == 1
-		if createdAt.Valid {
-			t.CreatedAt = createdAt.Time
-		}
-		if updatedAt.Valid {
-			t.UpdatedAt = updatedAt.Time
-		}
-		templates = append(templates, t)
+		gens = append(gens, g)
 	}
-
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("rows iteration error: %w", err)
 	}
+	return gens, nil
+}
+
+// GeneratedRef identifies the tenant-owned generation record behind a content hash
+type GeneratedRef struct {
+	TenantID    string
+	GeneratedID int64
+}
+
+// FindByContentHash locates the most recent generation with the given content hash.
+// It crosses tenants on purpose: the hash is what a printed QR code carries, and
+// callers only receive the verification outcome, never the content.
+func (r *ContractGenerationRepository) FindByContentHash(ctx context.Context, contentHash string) (*GeneratedRef, error) {
+	query := `SELECT tenant_id, id FROM generated_contracts
+		WHERE content_hash = :1
+		ORDER BY id DESC
+		FETCH FIRST 1 ROWS ONLY`
+
+	var ref GeneratedRef
+	err := r.db.QueryRowContext(ctx, query, contentHash).Scan(&ref.TenantID, &ref.GeneratedID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to find generated contract by hash: %w", err)
+	}
+	return &ref, nil
+}
+
+// ListTemplates lists all active templates for a tenant
+func (r *ContractGenerationRepository) ListTemplates(
+	ctx context.Context,
+	tenantID string,
+) ([]models.ContractTemplate, error) {
+	query := `SELECT ` + templateSelectColumns + ` FROM contract_templates
+		WHERE tenant_id = :1 AND active = 1
+		ORDER BY is_default DESC, template_name`
+	return r.queryTemplates(ctx, query, tenantID)
+}
 
-	return templates, nil
+// HasDefaultTemplate reports whether the tenant has a default template
+func (r *ContractGenerationRepository) HasDefaultTemplate(ctx context.Context, tenantID string) (bool, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM contract_templates WHERE tenant_id = :1 AND is_default = 1`, tenantID,
+	).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to check default template: %w", err)
+	}
+	return count > 0, nil
 }
 
 // InitTenantTemplate initializes the default template for a tenant
@@ -547,29 +848,91 @@ func (r *ContractGenerationRepository) InitTenantTemplate(
 	return nil
 }
 
-// CleanupExpiredGenerations removes expired generated contracts
+// CleanupExpiredGenerations deletes a tenant's generated contracts whose
+// expiry has passed, or every tenant's when tenantID is empty, and returns
+// the number deleted
 func (r *ContractGenerationRepository) CleanupExpiredGenerations(
 	ctx context.Context,
 	tenantID string,
 ) (int, error) {
-	query := `
-		DECLARE
-			v_deleted NUMBER;
-		BEGIN
-			pkg_contract_generation.cleanup_expired_generations(:1, v_deleted);
-			:2 := v_deleted;
-		END;`
+	query := `DELETE FROM generated_contracts
+		WHERE expires_at < CURRENT_TIMESTAMP AND (:1 IS NULL OR tenant_id = :2)`
 
-	var deleted int
 	var nullableTenantID sql.NullString
 	if tenantID != "" {
 		nullableTenantID = sql.NullString{String: tenantID, Valid: true}
 	}
 
-	_, err := r.db.ExecContext(ctx, query, nullableTenantID, sql.Out{Dest: &deleted})
+	result, err := r.db.ExecContext(ctx, query, nullableTenantID, nullableTenantID)
 	if err != nil {
 		return 0, fmt.Errorf("failed to cleanup expired generations: %w", err)
 	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf(errFmtRowsAffected, err)
+	}
+
+	return int(deleted), nil
+}
+
+// ListExpiredGenerationTenants lists the tenants that have generated
+// contracts past their expiry
+func (r *ContractGenerationRepository) ListExpiredGenerationTenants(ctx context.Context) ([]string, error) {
+	query := `SELECT DISTINCT tenant_id FROM generated_contracts
+		WHERE expires_at < CURRENT_TIMESTAMP
+		ORDER BY tenant_id`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenants with expired generations: %w", err)
+	}
+	defer rows.Close()
+
+	var tenants []string
+	for rows.Next() {
+		var tenantID string
+		if err := rows.Scan(&tenantID); err != nil {
+			return nil, fmt.Errorf("failed to scan tenant: %w", err)
+		}
+		tenants = append(tenants, tenantID)
+	}
+	return tenants, rows.Err()
+}
 
-	return deleted, nil
+// GetGenerationRefs retrieves the generated versions among ids that belong
+// to the contract, keyed by ID. IDs of other contracts or tenants are
+// left out.
+func (r *ContractGenerationRepository) GetGenerationRefs(
+	ctx context.Context,
+	tenantID string,
+	contractID int64,
+	ids ...int64,
+) (map[int64]models.GenerationRef, error) {
+	refs := make(map[int64]models.GenerationRef, len(ids))
+	if len(ids) == 0 {
+		return refs, nil
+	}
+	in := NewInClauseBuilder(3)
+	for _, id := range ids {
+		in.Add(id)
+	}
+	query := `SELECT id, generation_number, content_hash, generated_at
+		FROM generated_contracts
+		WHERE tenant_id = :1 AND contract_id = :2 AND id IN (` + in.Placeholders() + `)`
+	rows, err := r.db.QueryContext(ctx, query, append([]any{tenantID, contractID}, in.Args()...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get generated contracts: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var ref models.GenerationRef
+		if err := rows.Scan(&ref.GeneratedID, &ref.GenerationNumber, &ref.ContentHash, &ref.GeneratedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan generated contract: %w", err)
+		}
+		refs[ref.GeneratedID] = ref
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return refs, nil
 }
@@ -5,13 +5,14 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
-	"log"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/shopspring/decimal"
 	"github.com/zlovtnik/gprint/internal/models"
+	"github.com/zlovtnik/gprint/pkg/requestctx"
 )
 
 // ErrNotFound is returned when a requested resource does not exist
@@ -23,23 +24,21 @@ const (
 	TableContractItems = "CONTRACT_ITEMS"
 )
 
-const (
-	dateLayoutYMD       = "2006-01-02"
-	errUpdateTotalValue = "failed to update total: %s"
-)
-
-type contextKey string
+func init() {
+	RegisterTable(TableContracts, TableOptions{})
+	RegisterTable(TableContractItems, TableOptions{})
+}
 
-const traceIDKey contextKey = "trace_id"
+const dateLayoutYMD = "2006-01-02"
 
 // ContractRepository handles contract data access
 type ContractRepository struct {
-	db      *sql.DB
+	db      *DB
 	generic *GenericRepository
 }
 
 // NewContractRepository creates a new ContractRepository
-func NewContractRepository(db *sql.DB) *ContractRepository {
+func NewContractRepository(db *DB) *ContractRepository {
 	if db == nil {
 		panic("ContractRepository: db is nil")
 	}
@@ -50,29 +49,27 @@ func NewContractRepository(db *sql.DB) *ContractRepository {
 }
 
 // decimalToFloat64 converts a decimal to float64 and logs a warning if precision is lost.
+// The warning goes to the request-scoped logger, so it carries the trace and tenant IDs.
 func decimalToFloat64(ctx context.Context, fieldName string, d decimal.Decimal) float64 {
 	f, exact := d.Float64()
 	if !exact {
-		// Extract trace ID from context if available (assuming it's stored as a string value)
-		traceID := "unknown"
-		if traceVal := ctx.Value(traceIDKey); traceVal != nil {
-			if tid, ok := traceVal.(string); ok {
-				traceID = tid
-			}
-		}
-
-		log.Printf("WARNING: precision loss converting fieldName=%s value=%s traceID=%s", fieldName, d.String(), traceID)
+		requestctx.Logger(ctx).Warn("precision loss converting decimal",
+			"field", fieldName,
+			"value", d.String(),
+		)
 	}
 	return f
 }
 
-// Create creates a new contract with items using dynamic CRUD
-func (r *ContractRepository) Create(ctx context.Context, tenantID string, req *models.CreateContractRequest, createdBy string) (*models.Contract, error) {
+// Create creates a new contract with items using dynamic CRUD, rounding
+// its totals with rounding
+func (r *ContractRepository) Create(ctx context.Context, tenantID string, req *models.CreateContractRequest, createdBy string, rounding models.RoundingMode) (*models.Contract, error) {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, fmt.Errorf(errFmtBeginTx, err)
 	}
 	defer func() { _ = tx.Rollback() }()
+	generic := r.generic.WithTx(tx)
 
 	billingCycleStr := string(req.BillingCycle)
 	if billingCycleStr == "" {
@@ -89,7 +86,8 @@ func (r *ContractRepository) Create(ctx context.Context, tenantID string, req *m
 		{Name: "AUTO_RENEW", Value: boolToInt(req.AutoRenew), Type: "NUMBER"},
 		{Name: "BILLING_CYCLE", Value: billingCycleStr},
 		{Name: "STATUS", Value: "DRAFT"},
-		{Name: "TOTAL_VALUE", Value: 0, Type: "NUMBER"},
+		{Name: "DISCOUNT_PCT", Value: decimalToFloat64(ctx, "DiscountPct", req.DiscountPct), Type: "NUMBER"},
+		{Name: "TAX_PCT", Value: decimalToFloat64(ctx, "TaxPct", req.TaxPct), Type: "NUMBER"},
 	}
 
 	if req.EndDate != nil {
@@ -106,7 +104,7 @@ func (r *ContractRepository) Create(ctx context.Context, tenantID string, req *m
 	}
 
 	// Insert contract using generic CRUD
-	result, err := r.generic.Insert(ctx, TableContracts, tenantID, columns, createdBy)
+	result, err := generic.Insert(ctx, TableContracts, tenantID, columns, createdBy)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create contract: %w", err)
 	}
@@ -121,18 +119,18 @@ func (r *ContractRepository) Create(ctx context.Context, tenantID string, req *m
 
 	// Insert contract items
 	for _, item := range req.Items {
-		if err := r.insertContractItem(ctx, tenantID, contractID, item, createdBy); err != nil {
+		if err := r.insertContractItem(ctx, generic, tenantID, contractID, item, createdBy); err != nil {
 			return nil, err
 		}
 	}
 
-	// Update total using aggregate
-	aggResult, err := r.generic.UpdateAggregate(ctx, TableContracts, contractID, tenantID, TableContractItems)
-	if err != nil {
-		return nil, fmt.Errorf(errFmtUpdateTotalVal, err)
+	if err := recalculateTotals(ctx, tx, tenantID, contractID, rounding); err != nil {
+		return nil, err
 	}
-	if !aggResult.Success {
-		return nil, fmt.Errorf(errUpdateTotalValue, aggResult.ErrorMessage)
+	if len(req.Tags) > 0 {
+		if err := replaceTags(ctx, tx, tenantID, contractID, req.Tags); err != nil {
+			return nil, err
+		}
 	}
 
 	if err := tx.Commit(); err != nil {
@@ -142,8 +140,9 @@ func (r *ContractRepository) Create(ctx context.Context, tenantID string, req *m
 	return r.GetByID(ctx, tenantID, contractID)
 }
 
-// insertContractItem inserts a single contract item using dynamic CRUD.
-func (r *ContractRepository) insertContractItem(ctx context.Context, tenantID string, contractID int64, item models.CreateContractItemRequest, createdBy string) error {
+// insertContractItem inserts a single contract item using dynamic CRUD within
+// the transaction generic is bound to.
+func (r *ContractRepository) insertContractItem(ctx context.Context, generic *GenericRepository, tenantID string, contractID int64, item models.CreateContractItemRequest, createdBy string) error {
 	columns := []ColumnValue{
 		{Name: "CONTRACT_ID", Value: contractID, Type: "NUMBER"},
 		{Name: "SERVICE_ID", Value: item.ServiceID, Type: "NUMBER"},
@@ -169,7 +168,7 @@ func (r *ContractRepository) insertContractItem(ctx context.Context, tenantID st
 		columns = append(columns, ColumnValue{Name: "NOTES", Value: item.Notes})
 	}
 
-	result, err := r.generic.Insert(ctx, TableContractItems, tenantID, columns, createdBy)
+	result, err := generic.Insert(ctx, TableContractItems, tenantID, columns, createdBy)
 	if err != nil {
 		return fmt.Errorf("failed to create contract item: %w", err)
 	}
@@ -190,30 +189,8 @@ func (r *ContractRepository) GetByID(ctx context.Context, tenantID string, id in
 
 // getByIDDirect retrieves a contract by ID with items using direct SQL
 func (r *ContractRepository) getByIDDirect(ctx context.Context, tenantID string, id int64) (*models.Contract, error) {
-	query := `
-		SELECT c.id, c.tenant_id, c.contract_number, c.contract_type, c.customer_id,
-			c.start_date, c.end_date, c.duration_months, c.auto_renew,
-			c.total_value, c.payment_terms, c.billing_cycle, c.status,
-			c.signed_at, c.signed_by, c.document_path, c.document_hash,
-			c.notes, c.terms_conditions, c.created_at, c.updated_at, c.created_by, c.updated_by
-		FROM contracts c
-		WHERE c.tenant_id = :1 AND c.id = :2`
-
-	var contract models.Contract
-	var endDate, signedAt sql.NullTime
-	var durationMonths sql.NullInt64
-	var signedBy, documentPath, documentHash, paymentTerms sql.NullString
-	var notes, termsConditions, createdBy, updatedBy sql.NullString
-	var totalValueFloat float64
-	var createdAt, updatedAt sql.NullTime
-
-	err := r.db.QueryRowContext(ctx, query, tenantID, id).Scan(
-		&contract.ID, &contract.TenantID, &contract.ContractNumber, &contract.ContractType, &contract.CustomerID,
-		&contract.StartDate, &endDate, &durationMonths, &contract.AutoRenew,
-		&totalValueFloat, &paymentTerms, &contract.BillingCycle, &contract.Status,
-		&signedAt, &signedBy, &documentPath, &documentHash,
-		&notes, &termsConditions, &createdAt, &updatedAt, &createdBy, &updatedBy,
-	)
+	query := `SELECT ` + contractSelectColumns + ` FROM contracts WHERE tenant_id = :1 AND id = :2`
+	contract, err := scanContract(r.db.QueryRowContext(ctx, query, tenantID, id))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrNotFound
@@ -221,29 +198,6 @@ func (r *ContractRepository) getByIDDirect(ctx context.Context, tenantID string,
 		return nil, fmt.Errorf("failed to get contract: %w", err)
 	}
 
-	contract.TotalValue = decimal.NewFromFloat(totalValueFloat)
-	if endDate.Valid {
-		contract.EndDate = &endDate.Time
-	}
-	if signedAt.Valid {
-		contract.SignedAt = &signedAt.Time
-	}
-	contract.DurationMonths = int(durationMonths.Int64)
-	contract.SignedBy = signedBy.String
-	contract.DocumentPath = documentPath.String
-	contract.DocumentHash = documentHash.String
-	contract.PaymentTerms = paymentTerms.String
-	contract.Notes = notes.String
-	contract.TermsConditions = termsConditions.String
-	contract.CreatedBy = createdBy.String
-	contract.UpdatedBy = updatedBy.String
-	if createdAt.Valid {
-		contract.CreatedAt = createdAt.Time
-	}
-	if updatedAt.Valid {
-		contract.UpdatedAt = updatedAt.Time
-	}
-
 	// Get items
 	items, err := r.GetItems(ctx, tenantID, id)
 	if err != nil {
@@ -251,6 +205,10 @@ func (r *ContractRepository) getByIDDirect(ctx context.Context, tenantID string,
 	}
 	contract.Items = items
 
+	if contract.Tags, err = r.GetTags(ctx, tenantID, id); err != nil {
+		return nil, err
+	}
+
 	return &contract, nil
 }
 
@@ -378,6 +336,14 @@ func getDeterministicFallbackSortColumn(allowed map[string]bool) string {
 	return "id"
 }
 
+// contractSelectColumns are the contract columns read by contractScanDest
+const contractSelectColumns = `id, tenant_id, contract_number, contract_type, customer_id,
+			start_date, end_date, duration_months, auto_renew,
+			subtotal, discount_pct, discount_amount, tax_pct, tax_amount,
+			total_value, payment_terms, billing_cycle, status,
+			signed_at, signed_by, document_path, document_hash,
+			notes, terms_conditions, created_at, updated_at, created_by, updated_by`
+
 // contractScanDest holds scan destinations for contract queries.
 type contractScanDest struct {
 	contract                             models.Contract
@@ -387,6 +353,7 @@ type contractScanDest struct {
 	paymentTerms, notes, termsConditions sql.NullString
 	createdBy, updatedBy                 sql.NullString
 	createdAt, updatedAt                 sql.NullTime
+	subtotal, discountAmount, taxAmount  decimal.NullDecimal
 	totalValueFloat                      float64
 }
 
@@ -395,6 +362,7 @@ func (d *contractScanDest) scanArgs() []any {
 	return []any{
 		&d.contract.ID, &d.contract.TenantID, &d.contract.ContractNumber, &d.contract.ContractType, &d.contract.CustomerID,
 		&d.contract.StartDate, &d.endDate, &d.durationMonths, &d.contract.AutoRenew,
+		&d.subtotal, &d.contract.DiscountPct, &d.discountAmount, &d.contract.TaxPct, &d.taxAmount,
 		&d.totalValueFloat, &d.paymentTerms, &d.contract.BillingCycle, &d.contract.Status,
 		&d.signedAt, &d.signedBy, &d.documentPath, &d.documentHash,
 		&d.notes, &d.termsConditions, &d.createdAt, &d.updatedAt, &d.createdBy, &d.updatedBy,
@@ -403,6 +371,9 @@ func (d *contractScanDest) scanArgs() []any {
 
 // toContract converts scanned nullable fields to a Contract.
 func (d *contractScanDest) toContract() models.Contract {
+	d.contract.Subtotal = d.subtotal.Decimal
+	d.contract.DiscountAmount = d.discountAmount.Decimal
+	d.contract.TaxAmount = d.taxAmount.Decimal
 	d.contract.TotalValue = decimal.NewFromFloat(d.totalValueFloat)
 	d.contract.EndDate = TimeFromNull(d.endDate)
 	d.contract.SignedAt = TimeFromNull(d.signedAt)
@@ -420,75 +391,169 @@ func (d *contractScanDest) toContract() models.Contract {
 	return d.contract
 }
 
-// List retrieves contracts with pagination
-func (r *ContractRepository) List(ctx context.Context, tenantID string, params models.PaginationParams, search models.SearchParams) ([]models.Contract, int, error) {
-	// Count query
-	countQuery := `SELECT COUNT(*) FROM contracts WHERE tenant_id = :1`
-	args := []any{tenantID}
-	argIndex := 2
+// LastContractSequence returns the highest running number among the
+// tenant's contract numbers made of prefix, digits and suffix, or 0 if none
+func (r *ContractRepository) LastContractSequence(ctx context.Context, tenantID, prefix, suffix string) (int, error) {
+	escape := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT contract_number FROM contracts WHERE tenant_id = :1 AND contract_number LIKE :2 ESCAPE '\'`,
+		tenantID, escape.Replace(prefix)+"%"+escape.Replace(suffix),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query contract numbers: %w", err)
+	}
+	defer rows.Close()
 
-	if search.Query != "" {
-		countQuery += fmt.Sprintf(" AND UPPER(contract_number) LIKE UPPER(:%d)", argIndex)
-		args = append(args, "%"+search.Query+"%")
+	last := 0
+	for rows.Next() {
+		var number string
+		if err := rows.Scan(&number); err != nil {
+			return 0, fmt.Errorf("failed to scan contract number: %w", err)
+		}
+		digits := strings.TrimSuffix(strings.TrimPrefix(number, prefix), suffix)
+		if len(digits) != len(number)-len(prefix)-len(suffix) {
+			continue
+		}
+		// Atoi accepts signs, which are not part of a running number
+		if seq, err := strconv.Atoi(digits); err == nil && digits[0] != '+' && digits[0] != '-' && seq > last {
+			last = seq
+		}
 	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to iterate contract numbers: %w", err)
+	}
+	return last, nil
+}
 
-	var total int
-	err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total)
+// List retrieves contracts with pagination, counting the matches in the
+// same round trip
+func (r *ContractRepository) List(ctx context.Context, tenantID string, params models.PaginationParams, search models.SearchParams) ([]models.Contract, int, error) {
+	sortBy, sortDir := getSortClause(search.SortBy, search.SortDir, contractListAllowedSorts, "created_at")
+	contracts, total, err := listPage(ctx, r.db, tenantID, pageQuery{
+		noun:    "contracts",
+		table:   "contracts",
+		columns: contractSelectColumns,
+		filter:  contractFilterConditions(search),
+		orderBy: sortBy + " " + sortDir,
+		offset:  params.Offset(),
+		limit:   params.Limit(),
+	}, scanContract)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to count contracts: %w", err)
+		return nil, 0, err
 	}
+	if err := r.attachTags(ctx, tenantID, contracts); err != nil {
+		return nil, 0, err
+	}
+	return contracts, total, nil
+}
 
-	// Main query - stored procedure sp_list_contracts available for ref cursor usage
-	query := `
-		SELECT id, tenant_id, contract_number, contract_type, customer_id,
-			start_date, end_date, duration_months, auto_renew,
-			total_value, payment_terms, billing_cycle, status,
-			signed_at, signed_by, document_path, document_hash,
-			notes, terms_conditions, created_at, updated_at, created_by, updated_by
-		FROM contracts
-		WHERE tenant_id = :1`
+// ListKeyset retrieves a page of contracts, newest first, following
+// params.After. Unlike List it does not count the matching contracts, so
+// deep pages cost the same as the first. The returned cursor is nil on the
+// last page.
+func (r *ContractRepository) ListKeyset(ctx context.Context, tenantID string, params models.KeysetParams, search models.SearchParams) ([]models.Contract, *models.ListCursor, error) {
+	qb := contractFilterConditions(search)
+	if params.After != nil {
+		qb.AddKeyset("created_at", "id", *params.After)
+	}
+	next := qb.NextIndex()
+	query := fmt.Sprintf(`SELECT `+contractSelectColumns+` FROM contracts WHERE tenant_id = :1%s
+		ORDER BY created_at DESC, id DESC
+		FETCH FIRST :%d ROWS ONLY`, qb.WhereClause(), next)
+
+	args := append([]any{tenantID}, qb.Args()...)
+	contracts, err := r.queryContracts(ctx, query, append(args, params.PageSize+1)...)
+	if err != nil {
+		return nil, nil, err
+	}
+	contracts, cursor := keysetPage(contracts, params.PageSize, func(c models.Contract) models.ListCursor {
+		return models.ListCursor{At: c.CreatedAt, ID: c.ID}
+	})
+	if err := r.attachTags(ctx, tenantID, contracts); err != nil {
+		return nil, nil, err
+	}
+	return contracts, cursor, nil
+}
 
-	queryArgs := []any{tenantID}
-	queryArgIndex := 2
+// ListActiveAfter retrieves up to limit ACTIVE contracts of every tenant
+// with an ID above afterID, in ID order, without their items. Background
+// jobs page through all active contracts with it.
+func (r *ContractRepository) ListActiveAfter(ctx context.Context, afterID int64, limit int) ([]models.Contract, error) {
+	query := `SELECT ` + contractSelectColumns + ` FROM contracts
+		WHERE status = :1 AND id > :2
+		ORDER BY id
+		FETCH FIRST :3 ROWS ONLY`
+	return r.queryContracts(ctx, query, string(models.ContractStatusActive), afterID, limit)
+}
 
+// contractFilterConditions builds the list conditions for search,
+// numbering binds from :2 after the tenant
+func contractFilterConditions(search models.SearchParams) *QueryBuilder {
+	qb := NewQueryBuilder(2)
 	if search.Query != "" {
-		query += fmt.Sprintf(" AND UPPER(contract_number) LIKE UPPER(:%d)", queryArgIndex)
-		queryArgs = append(queryArgs, "%"+search.Query+"%")
-		queryArgIndex++
+		qb.AddCondition("UPPER(contract_number) LIKE UPPER(:%d)", "%"+search.Query+"%")
 	}
+	if search.CustomerID > 0 {
+		qb.AddCondition("customer_id = :%d", search.CustomerID)
+	}
+	for _, tag := range search.Tags {
+		qb.AddCondition(tagFilterCondition, tag)
+	}
+	return qb
+}
 
-	// Sorting
-	sortBy, sortDir := getSortClause(search.SortBy, search.SortDir, contractListAllowedSorts, "created_at")
-	query += fmt.Sprintf(" ORDER BY %s %s", sortBy, sortDir)
-
-	// Pagination
-	query += fmt.Sprintf(" OFFSET :%d ROWS FETCH NEXT :%d ROWS ONLY", queryArgIndex, queryArgIndex+1)
-	queryArgs = append(queryArgs, params.Offset(), params.Limit())
-
-	rows, err := r.db.QueryContext(ctx, query, queryArgs...)
+// queryContracts runs a query selecting contractSelectColumns
+func (r *ContractRepository) queryContracts(ctx context.Context, query string, args ...any) ([]models.Contract, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to list contracts: %w", err)
+		return nil, fmt.Errorf("failed to list contracts: %w", err)
 	}
 	defer rows.Close()
 
 	var contracts []models.Contract
 	for rows.Next() {
-		var dest contractScanDest
-		if err := rows.Scan(dest.scanArgs()...); err != nil {
-			return nil, 0, fmt.Errorf("failed to scan contract: %w", err)
+		contract, err := scanContract(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan contract: %w", err)
 		}
-		contracts = append(contracts, dest.toContract())
+		contracts = append(contracts, contract)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, 0, fmt.Errorf("failed to iterate contracts: %w", err)
+		return nil, fmt.Errorf("failed to iterate contracts: %w", err)
 	}
+	return contracts, nil
+}
 
-	return contracts, total, nil
+// scanContract scans a row of contractSelectColumns
+func scanContract(scanner rowScanner) (models.Contract, error) {
+	var dest contractScanDest
+	if err := scanner.Scan(dest.scanArgs()...); err != nil {
+		return models.Contract{}, err
+	}
+	return dest.toContract(), nil
 }
 
-// Update updates a contract using dynamic CRUD
-func (r *ContractRepository) Update(ctx context.Context, tenantID string, id int64, req *models.UpdateContractRequest, updatedBy string) (*models.Contract, error) {
+// SearchSummary finds contracts whose number or customer name contains
+// query, returning up to limit of them, newest first, with their status and
+// the number of matches
+func (r *ContractRepository) SearchSummary(ctx context.Context, tenantID, query string, limit int) ([]models.SearchSummary, int, error) {
+	return searchSummaries(ctx, r.db, models.SearchTypeContract, `
+		SELECT c.id, c.contract_number, c.status, COUNT(*) OVER ()
+		FROM contracts c
+		JOIN customers cu ON cu.id = c.customer_id AND cu.tenant_id = c.tenant_id
+		WHERE c.tenant_id = :1
+			AND (UPPER(c.contract_number) LIKE UPPER(:2) ESCAPE '\'
+				OR UPPER(cu.name) LIKE UPPER(:3) ESCAPE '\')
+		ORDER BY c.created_at DESC, c.id DESC
+		FETCH FIRST :4 ROWS ONLY`,
+		tenantID, LikeContains(query), LikeContains(query), limit)
+}
+
+// Update changes the fields set in req using dynamic CRUD. A new discount
+// or tax percentage recalculates the totals, rounded with rounding, and new
+// tags replace the old ones, in the same transaction.
+func (r *ContractRepository) Update(ctx context.Context, tenantID string, id int64, req *models.UpdateContractRequest, updatedBy string, rounding models.RoundingMode) (*models.Contract, error) {
 	var columns []ColumnValue
 
 	if req.ContractType != nil {
@@ -506,6 +571,12 @@ func (r *ContractRepository) Update(ctx context.Context, tenantID string, id int
 	if req.AutoRenew != nil {
 		columns = append(columns, ColumnValue{Name: "AUTO_RENEW", Value: boolToInt(*req.AutoRenew), Type: "NUMBER"})
 	}
+	if req.DiscountPct != nil {
+		columns = append(columns, ColumnValue{Name: "DISCOUNT_PCT", Value: decimalToFloat64(ctx, "DiscountPct", *req.DiscountPct), Type: "NUMBER"})
+	}
+	if req.TaxPct != nil {
+		columns = append(columns, ColumnValue{Name: "TAX_PCT", Value: decimalToFloat64(ctx, "TaxPct", *req.TaxPct), Type: "NUMBER"})
+	}
 	// PaymentTerms: nil=no change, &""=clear, &"value"=set
 	if req.PaymentTerms != nil {
 		columns = append(columns, ColumnValue{Name: "PAYMENT_TERMS", Value: *req.PaymentTerms})
@@ -523,10 +594,21 @@ func (r *ContractRepository) Update(ctx context.Context, tenantID string, id int
 	}
 
 	if len(columns) == 0 {
+		if req.Tags != nil {
+			if err := r.ReplaceTags(ctx, tenantID, id, *req.Tags, updatedBy); err != nil {
+				return nil, err
+			}
+		}
 		return r.GetByID(ctx, tenantID, id)
 	}
 
-	result, err := r.generic.Update(ctx, TableContracts, tenantID, id, columns, updatedBy)
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf(errFmtBeginTx, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	result, err := r.generic.WithTx(tx).Update(ctx, TableContracts, tenantID, id, columns, updatedBy)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update contract: %w", err)
 	}
@@ -540,9 +622,75 @@ func (r *ContractRepository) Update(ctx context.Context, tenantID string, id int
 		return nil, ErrNotFound
 	}
 
+	if req.DiscountPct != nil || req.TaxPct != nil {
+		if err := recalculateTotals(ctx, tx, tenantID, id, rounding); err != nil {
+			return nil, err
+		}
+	}
+	if req.Tags != nil {
+		if err := replaceTags(ctx, tx, tenantID, id, *req.Tags); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf(errFmtCommitTx, err)
+	}
+
 	return r.GetByID(ctx, tenantID, id)
 }
 
+// recalculateTotals computes the contract's subtotal, discount, tax and
+// total from its items and percentages and stores them. The contract row
+// is locked first, so concurrent item changes are totalled one at a time.
+func recalculateTotals(ctx context.Context, db Execer, tenantID string, contractID int64, rounding models.RoundingMode) error {
+	var discountPct, taxPct decimal.Decimal
+	err := db.QueryRowContext(ctx,
+		`SELECT discount_pct, tax_pct FROM contracts WHERE tenant_id = :1 AND id = :2 FOR UPDATE`,
+		tenantID, contractID,
+	).Scan(&discountPct, &taxPct)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf(errFmtUpdateTotalVal, err)
+	}
+
+	rows, err := db.QueryContext(ctx,
+		`SELECT quantity, unit_price, NVL(discount_pct, 0) FROM contract_items WHERE tenant_id = :1 AND contract_id = :2`,
+		tenantID, contractID,
+	)
+	if err != nil {
+		return fmt.Errorf(errFmtUpdateTotalVal, err)
+	}
+	defer rows.Close()
+	var items []models.ContractItem
+	for rows.Next() {
+		var item models.ContractItem
+		if err := rows.Scan(&item.Quantity, &item.UnitPrice, &item.DiscountPct); err != nil {
+			return fmt.Errorf(errFmtUpdateTotalVal, err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf(errFmtUpdateTotalVal, err)
+	}
+
+	// The amounts are whole cents, which float64 carries to NUMBER(15,2) intact
+	totals := models.ComputeContractTotals(items, discountPct, taxPct, rounding)
+	_, err = db.ExecContext(ctx,
+		`UPDATE contracts SET subtotal = :1, discount_amount = :2, tax_amount = :3, total_value = :4
+		WHERE tenant_id = :5 AND id = :6`,
+		totals.Subtotal.InexactFloat64(), totals.DiscountAmount.InexactFloat64(),
+		totals.TaxAmount.InexactFloat64(), totals.Total.InexactFloat64(),
+		tenantID, contractID,
+	)
+	if err != nil {
+		return fmt.Errorf(errFmtUpdateTotalVal, err)
+	}
+	return nil
+}
+
 // UpdateStatus updates the contract status
 func (r *ContractRepository) UpdateStatus(ctx context.Context, tenantID string, id int64, status models.ContractStatus, updatedBy string) error {
 	query := `UPDATE contracts SET status = :1, updated_at = CURRENT_TIMESTAMP, updated_by = :2 WHERE tenant_id = :3 AND id = :4`
@@ -580,14 +728,16 @@ func (r *ContractRepository) Sign(ctx context.Context, tenantID string, id int64
 	return nil
 }
 
-// AddItem adds an item to a contract using dynamic CRUD
+// AddItem adds an item to a contract using dynamic CRUD and recalculates
+// the contract totals, rounded with rounding
 // Note: createdBy is extracted from the caller context; pass empty string if unknown.
-func (r *ContractRepository) AddItem(ctx context.Context, tenantID string, contractID int64, req *models.CreateContractItemRequest, createdBy string) (*models.ContractItem, error) {
+func (r *ContractRepository) AddItem(ctx context.Context, tenantID string, contractID int64, req *models.CreateContractItemRequest, createdBy string, rounding models.RoundingMode) (*models.ContractItem, error) {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, fmt.Errorf(errFmtBeginTx, err)
 	}
 	defer func() { _ = tx.Rollback() }()
+	generic := r.generic.WithTx(tx)
 
 	columns := []ColumnValue{
 		{Name: "CONTRACT_ID", Value: contractID, Type: "NUMBER"},
@@ -614,7 +764,7 @@ func (r *ContractRepository) AddItem(ctx context.Context, tenantID string, contr
 		columns = append(columns, ColumnValue{Name: "NOTES", Value: req.Notes})
 	}
 
-	result, err := r.generic.Insert(ctx, TableContractItems, tenantID, columns, createdBy)
+	result, err := generic.Insert(ctx, TableContractItems, tenantID, columns, createdBy)
 	if err != nil {
 		return nil, fmt.Errorf("failed to insert item: %w", err)
 	}
@@ -627,13 +777,8 @@ func (r *ContractRepository) AddItem(ctx context.Context, tenantID string, contr
 
 	itemID := *result.GeneratedID
 
-	// Update total using aggregate
-	aggResult, err := r.generic.UpdateAggregate(ctx, TableContracts, contractID, tenantID, TableContractItems)
-	if err != nil {
-		return nil, fmt.Errorf(errFmtUpdateTotalVal, err)
-	}
-	if !aggResult.Success {
-		return nil, fmt.Errorf(errUpdateTotalValue, aggResult.ErrorMessage)
+	if err := recalculateTotals(ctx, tx, tenantID, contractID, rounding); err != nil {
+		return nil, err
 	}
 
 	if err := tx.Commit(); err != nil {
@@ -668,16 +813,18 @@ func (r *ContractRepository) GetItemByID(ctx context.Context, tenantID string, c
 	return &item, nil
 }
 
-// DeleteItem removes an item from a contract using dynamic CRUD
-func (r *ContractRepository) DeleteItem(ctx context.Context, tenantID string, contractID, itemID int64, deletedBy string) error {
+// DeleteItem removes an item from a contract using dynamic CRUD and
+// recalculates the contract totals, rounded with rounding
+func (r *ContractRepository) DeleteItem(ctx context.Context, tenantID string, contractID, itemID int64, deletedBy string, rounding models.RoundingMode) error {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf(errFmtBeginTx, err)
 	}
 	defer func() { _ = tx.Rollback() }()
+	generic := r.generic.WithTx(tx)
 
-	// Delete item (hard delete since contract items don't have ACTIVE column)
-	result, err := r.generic.Delete(ctx, TableContractItems, tenantID, itemID, false, deletedBy)
+	// Hard delete, since contract items don't have an ACTIVE column
+	result, err := generic.Delete(ctx, TableContractItems, tenantID, itemID, deletedBy)
 	if err != nil {
 		return fmt.Errorf("failed to delete item: %w", err)
 	}
@@ -691,13 +838,8 @@ func (r *ContractRepository) DeleteItem(ctx context.Context, tenantID string, co
 		return ErrNotFound
 	}
 
-	// Update total using aggregate
-	aggResult, err := r.generic.UpdateAggregate(ctx, TableContracts, contractID, tenantID, TableContractItems)
-	if err != nil {
-		return fmt.Errorf(errFmtUpdateTotalVal, err)
-	}
-	if !aggResult.Success {
-		return fmt.Errorf(errUpdateTotalValue, aggResult.ErrorMessage)
+	if err := recalculateTotals(ctx, tx, tenantID, contractID, rounding); err != nil {
+		return err
 	}
 
 	if err := tx.Commit(); err != nil {
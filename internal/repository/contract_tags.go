@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zlovtnik/gprint/internal/models"
+)
+
+// tagFilterCondition matches contracts carrying a tag; one is added per
+// ?tag= so a contract must have every tag
+const tagFilterCondition = `EXISTS (SELECT 1 FROM contract_tags t
+	WHERE t.tenant_id = contracts.tenant_id AND t.contract_id = contracts.id AND t.tag = :%d)`
+
+// GetTags retrieves a contract's tags in alphabetical order
+func (r *ContractRepository) GetTags(ctx context.Context, tenantID string, contractID int64) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT tag FROM contract_tags WHERE tenant_id = :1 AND contract_id = :2 ORDER BY tag`,
+		tenantID, contractID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contract tags: %w", err)
+	}
+	defer rows.Close()
+
+	var tags []string
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, fmt.Errorf("failed to scan contract tag: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate contract tags: %w", err)
+	}
+	return tags, nil
+}
+
+// ReplaceTags sets a contract's tags to tags, after NormalizeTags, removing
+// any others
+func (r *ContractRepository) ReplaceTags(ctx context.Context, tenantID string, contractID int64, tags []string, updatedBy string) error {
+	return RunInTx(ctx, r.db, func(repos *TxRepositories) error {
+		result, err := repos.Tx.ExecContext(ctx,
+			`UPDATE contracts SET updated_at = CURRENT_TIMESTAMP, updated_by = :1 WHERE tenant_id = :2 AND id = :3`,
+			updatedBy, tenantID, contractID)
+		if err != nil {
+			return fmt.Errorf("failed to update contract: %w", err)
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf(errFmtRowsAffected, err)
+		}
+		if n == 0 {
+			return ErrNotFound
+		}
+		return replaceTags(ctx, repos.Tx, tenantID, contractID, tags)
+	})
+}
+
+// replaceTags sets a contract's tags through db, for callers already in a
+// transaction
+func replaceTags(ctx context.Context, db Execer, tenantID string, contractID int64, tags []string) error {
+	if _, err := db.ExecContext(ctx,
+		`DELETE FROM contract_tags WHERE tenant_id = :1 AND contract_id = :2`,
+		tenantID, contractID); err != nil {
+		return fmt.Errorf("failed to clear contract tags: %w", err)
+	}
+	for _, tag := range models.NormalizeTags(tags) {
+		if _, err := db.ExecContext(ctx,
+			`INSERT INTO contract_tags (tenant_id, contract_id, tag) VALUES (:1, :2, :3)`,
+			tenantID, contractID, tag); err != nil {
+			return fmt.Errorf("failed to add contract tag: %w", err)
+		}
+	}
+	return nil
+}
+
+// attachTags loads the tags of a page of contracts in one query
+func (r *ContractRepository) attachTags(ctx context.Context, tenantID string, contracts []models.Contract) error {
+	if len(contracts) == 0 {
+		return nil
+	}
+	in := NewInClauseBuilder(2)
+	index := make(map[int64]int, len(contracts))
+	for i := range contracts {
+		in.Add(contracts[i].ID)
+		index[contracts[i].ID] = i
+	}
+
+	query := `SELECT contract_id, tag FROM contract_tags
+		WHERE tenant_id = :1 AND contract_id IN (` + in.Placeholders() + `)
+		ORDER BY contract_id, tag`
+	rows, err := r.db.QueryContext(ctx, query, append([]any{tenantID}, in.Args()...)...)
+	if err != nil {
+		return fmt.Errorf("failed to get contract tags: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var contractID int64
+		var tag string
+		if err := rows.Scan(&contractID, &tag); err != nil {
+			return fmt.Errorf("failed to scan contract tag: %w", err)
+		}
+		if i, ok := index[contractID]; ok {
+			contracts[i].Tags = append(contracts[i].Tags, tag)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate contract tags: %w", err)
+	}
+	return nil
+}
+
+// ListTags retrieves the tenant's tags starting with prefix, alphabetically,
+// with the number of contracts carrying each, up to limit of them
+func (r *ContractRepository) ListTags(ctx context.Context, tenantID, prefix string, limit int) ([]models.TagUsage, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT tag, COUNT(*) FROM contract_tags
+		WHERE tenant_id = :1 AND tag LIKE :2 ESCAPE '\'
+		GROUP BY tag
+		ORDER BY tag
+		FETCH FIRST :3 ROWS ONLY`,
+		tenantID, LikePrefix(prefix), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := []models.TagUsage{}
+	for rows.Next() {
+		var usage models.TagUsage
+		if err := rows.Scan(&usage.Tag, &usage.Contracts); err != nil {
+			return nil, fmt.Errorf("failed to scan tag: %w", err)
+		}
+		tags = append(tags, usage)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate tags: %w", err)
+	}
+	return tags, nil
+}
@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/zlovtnik/gprint/internal/models"
+)
+
+// ErrTemplateInUse is returned when deleting a template generated contracts refer to
+var ErrTemplateInUse = errors.New("template is referenced by generated contracts")
+
+// templateSelectColumns is the column list read by scanTemplate, in scan order
+const templateSelectColumns = `id, tenant_id, template_code, template_name, language,
+		       is_default, active, version, created_at, updated_at`
+
+// ListAllTemplates lists a tenant's templates, retired ones included, without their bodies
+func (r *ContractGenerationRepository) ListAllTemplates(ctx context.Context, tenantID string) ([]models.ContractTemplate, error) {
+	query := `SELECT ` + templateSelectColumns + ` FROM contract_templates
+		WHERE tenant_id = :1
+		ORDER BY is_default DESC, active DESC, template_name`
+	return r.queryTemplates(ctx, query, tenantID)
+}
+
+// GetTemplate retrieves a template with its body by code
+func (r *ContractGenerationRepository) GetTemplate(ctx context.Context, tenantID, code string) (*models.ContractTemplate, error) {
+	query := `SELECT ` + templateSelectColumns + `, body_html FROM contract_templates
+		WHERE tenant_id = :1 AND template_code = :2`
+	return r.getTemplate(ctx, query, tenantID, code)
+}
+
+// GetGenerationTemplate retrieves, with its body, the template a generated
+// contract was built from. ErrNotFound means the generation had no template
+// or the template has since been deleted.
+func (r *ContractGenerationRepository) GetGenerationTemplate(ctx context.Context, tenantID string, generatedID int64) (*models.ContractTemplate, error) {
+	query := `SELECT ` + templateSelectColumns + `, body_html FROM contract_templates
+		WHERE tenant_id = :1
+		  AND id = (SELECT template_id FROM generated_contracts WHERE tenant_id = :2 AND id = :3)`
+	return r.getTemplate(ctx, query, tenantID, tenantID, generatedID)
+}
+
+// getTemplate runs a query selecting templateSelectColumns and body_html
+// for at most one template
+func (r *ContractGenerationRepository) getTemplate(ctx context.Context, query string, args ...any) (*models.ContractTemplate, error) {
+	var body sql.NullString
+	t, err := scanTemplate(r.db.QueryRowContext(ctx, query, args...), &body)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get template: %w", err)
+	}
+	t.BodyHTML = body.String
+	return &t, nil
+}
+
+// CreateTemplate inserts an active template at version 1. A default
+// template takes over from the tenant's previous default in the same
+// transaction.
+func (r *ContractGenerationRepository) CreateTemplate(ctx context.Context, t *models.ContractTemplate, createdBy string) error {
+	return RunInTx(ctx, r.db, func(repos *TxRepositories) error {
+		if t.IsDefault {
+			if err := clearDefaultTemplate(ctx, repos.Tx, t.TenantID, t.TemplateCode); err != nil {
+				return err
+			}
+		}
+		_, err := repos.Tx.ExecContext(ctx, `INSERT INTO contract_templates
+				(tenant_id, template_code, template_name, language, body_html, is_default, active, version, created_by, updated_by)
+			VALUES (:1, :2, :3, :4, :5, :6, 1, 1, :7, :8)`,
+			t.TenantID, t.TemplateCode, t.TemplateName, t.Language, t.BodyHTML, boolToInt(t.IsDefault), createdBy, createdBy)
+		if err != nil {
+			return fmt.Errorf("failed to create template: %w", err)
+		}
+		return nil
+	})
+}
+
+// UpdateTemplate writes a template's name, language, body and flags,
+// bumping its version when bumpVersion is set. A default template takes
+// over from the tenant's previous default in the same transaction.
+func (r *ContractGenerationRepository) UpdateTemplate(ctx context.Context, t *models.ContractTemplate, bumpVersion bool, updatedBy string) error {
+	return RunInTx(ctx, r.db, func(repos *TxRepositories) error {
+		if t.IsDefault {
+			if err := clearDefaultTemplate(ctx, repos.Tx, t.TenantID, t.TemplateCode); err != nil {
+				return err
+			}
+		}
+		result, err := repos.Tx.ExecContext(ctx, `UPDATE contract_templates
+			SET template_name = :1, language = :2, body_html = :3, is_default = :4, active = :5,
+			    version = version + :6, updated_at = CURRENT_TIMESTAMP, updated_by = :7
+			WHERE tenant_id = :8 AND template_code = :9`,
+			t.TemplateName, t.Language, t.BodyHTML, boolToInt(t.IsDefault), boolToInt(t.Active),
+			boolToInt(bumpVersion), updatedBy, t.TenantID, t.TemplateCode)
+		if err != nil {
+			return fmt.Errorf("failed to update template: %w", err)
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf(errFmtRowsAffected, err)
+		}
+		if n == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
+
+// DeleteTemplate removes a template no generated contract refers to.
+// Returns ErrTemplateInUse when one does; such templates are retired by
+// deactivating them instead.
+func (r *ContractGenerationRepository) DeleteTemplate(ctx context.Context, tenantID, code string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM contract_templates t
+		WHERE t.tenant_id = :1 AND t.template_code = :2
+		  AND NOT EXISTS (SELECT 1 FROM generated_contracts g WHERE g.template_id = t.id)`,
+		tenantID, code)
+	if err != nil {
+		return fmt.Errorf("failed to delete template: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf(errFmtRowsAffected, err)
+	}
+	if n > 0 {
+		return nil
+	}
+	if _, err := r.GetTemplate(ctx, tenantID, code); err != nil {
+		return err
+	}
+	return ErrTemplateInUse
+}
+
+// clearDefaultTemplate unsets the tenant's default template unless it is
+// the one with code, so uk_template_default_tenant allows a new default
+func clearDefaultTemplate(ctx context.Context, db Execer, tenantID, code string) error {
+	_, err := db.ExecContext(ctx, `UPDATE contract_templates
+		SET is_default = 0, updated_at = CURRENT_TIMESTAMP
+		WHERE tenant_id = :1 AND is_default = 1 AND template_code <> :2`,
+		tenantID, code)
+	if err != nil {
+		return fmt.Errorf("failed to clear default template: %w", err)
+	}
+	return nil
+}
+
+// queryTemplates runs a query selecting templateSelectColumns
+func (r *ContractGenerationRepository) queryTemplates(ctx context.Context, query string, args ...any) ([]models.ContractTemplate, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list templates: %w", err)
+	}
+	defer rows.Close()
+
+	var templates []models.ContractTemplate
+	for rows.Next() {
+		t, err := scanTemplate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan template: %w", err)
+		}
+		templates = append(templates, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return templates, nil
+}
+
+// scanTemplate scans templateSelectColumns followed by any extra columns
+func scanTemplate(scanner rowScanner, extra ...any) (models.ContractTemplate, error) {
+	var t models.ContractTemplate
+	var isDefault, active int
+	var createdAt, updatedAt sql.NullTime
+	dest := []any{
+		&t.ID, &t.TenantID, &t.TemplateCode, &t.TemplateName, &t.Language,
+		&isDefault, &active, &t.Version, &createdAt, &updatedAt,
+	}
+	if err := scanner.Scan(append(dest, extra...)...); err != nil {
+		return t, err
+	}
+	t.IsDefault = isDefault == 1
+	t.Active = active == 1
+	if createdAt.Valid {
+		t.CreatedAt = createdAt.Time
+	}
+	if updatedAt.Valid {
+		t.UpdatedAt = updatedAt.Time
+	}
+	return t, nil
+}
@@ -12,9 +12,13 @@ import (
 // TableCustomers is the table name for customers.
 const TableCustomers = "CUSTOMERS"
 
+func init() {
+	RegisterTable(TableCustomers, TableOptions{SoftDelete: true})
+}
+
 // CustomerRepository handles customer data access
 type CustomerRepository struct {
-	db      *sql.DB
+	db      *DB
 	generic *GenericRepository
 }
 
@@ -48,7 +52,7 @@ func appendAddressColumns(columns []ColumnValue, address *models.AddressInput) [
 }
 
 // NewCustomerRepository creates a new CustomerRepository
-func NewCustomerRepository(db *sql.DB) (*CustomerRepository, error) {
+func NewCustomerRepository(db *DB) (*CustomerRepository, error) {
 	if db == nil {
 		return nil, fmt.Errorf("NewCustomerRepository: db is nil")
 	}
@@ -58,58 +62,36 @@ func NewCustomerRepository(db *sql.DB) (*CustomerRepository, error) {
 	}, nil
 }
 
-// searchCondition holds a SQL condition fragment and its argument
-type searchCondition struct {
-	clause string
-	arg    any
+// customerListAllowedSorts defines valid sort columns for customer listing
+var customerListAllowedSorts = map[string]bool{
+	"name":          true,
+	"customer_code": true,
+	"created_at":    true,
+	"updated_at":    true,
 }
 
-// buildSearchConditions builds WHERE clause conditions from search params
-func buildSearchConditions(search models.SearchParams, startIndex int) ([]searchCondition, int) {
-	var conditions []searchCondition
-	argIndex := startIndex
+// customerSelectColumns are the customer columns read by scanCustomer
+const customerSelectColumns = `id, tenant_id, customer_code, customer_type, name, trade_name,
+			tax_id, state_reg, municipal_reg, email, phone, mobile,
+			address_street, address_number, address_comp, address_district,
+			address_city, address_state, address_zip, address_country,
+			active, notes, created_at, updated_at, created_by, updated_by`
 
+// customerFilterConditions builds the list conditions for search,
+// numbering binds from :2 after the tenant
+func customerFilterConditions(search models.SearchParams) *QueryBuilder {
+	qb := NewQueryBuilder(2)
 	if search.Query != "" {
-		conditions = append(conditions, searchCondition{
-			clause: fmt.Sprintf(" AND UPPER(name) LIKE UPPER(:%d)", argIndex),
-			arg:    "%" + search.Query + "%",
-		})
-		argIndex++
+		qb.AddCondition("UPPER(name) LIKE UPPER(:%d)", "%"+search.Query+"%")
 	}
-
 	if search.Active != nil {
-		activeVal := 0
-		if *search.Active {
-			activeVal = 1
-		}
-		conditions = append(conditions, searchCondition{
-			clause: fmt.Sprintf(" AND active = :%d", argIndex),
-			arg:    activeVal,
-		})
-		argIndex++
-	}
-
-	return conditions, argIndex
-}
-
-// applySorting returns the ORDER BY clause for customer queries
-func applySorting(search models.SearchParams) string {
-	sortBy := "created_at"
-	allowedSorts := map[string]bool{"name": true, "customer_code": true, "created_at": true, "updated_at": true}
-	if search.SortBy != "" && allowedSorts[search.SortBy] {
-		sortBy = search.SortBy
-	}
-
-	sortDir := "DESC"
-	if strings.ToUpper(search.SortDir) == "ASC" {
-		sortDir = "ASC"
+		qb.AddCondition("active = :%d", boolToInt(*search.Active))
 	}
-
-	return fmt.Sprintf(" ORDER BY %s %s", sortBy, sortDir)
+	return qb
 }
 
 // scanCustomer scans a row into a Customer struct
-func scanCustomer(scanner interface{ Scan(...any) error }) (*models.Customer, error) {
+func scanCustomer(scanner rowScanner) (*models.Customer, error) {
 	var c models.Customer
 	var tradeName, taxID, stateReg, municipalReg, email, phone, mobile sql.NullString
 	var street, number, comp, district, city, state, zip, country sql.NullString
@@ -157,8 +139,8 @@ func scanCustomer(scanner interface{ Scan(...any) error }) (*models.Customer, er
 	return &c, nil
 }
 
-// Create creates a new customer using dynamic CRUD
-func (r *CustomerRepository) Create(ctx context.Context, tenantID string, req *models.CreateCustomerRequest, createdBy string) (*models.Customer, error) {
+// customerColumns returns the columns of a new customer
+func customerColumns(req *models.CreateCustomerRequest) []ColumnValue {
 	columns := []ColumnValue{
 		{Name: "CUSTOMER_CODE", Value: req.CustomerCode},
 		{Name: "CUSTOMER_TYPE", Value: string(req.CustomerType)},
@@ -175,8 +157,12 @@ func (r *CustomerRepository) Create(ctx context.Context, tenantID string, req *m
 	columns = appendOptionalStringColumn(columns, "MOBILE", req.Mobile)
 	columns = appendAddressColumns(columns, req.Address)
 	columns = appendOptionalStringColumn(columns, "NOTES", req.Notes)
+	return columns
+}
 
-	result, err := r.generic.Insert(ctx, TableCustomers, tenantID, columns, createdBy)
+// Create creates a new customer using dynamic CRUD
+func (r *CustomerRepository) Create(ctx context.Context, tenantID string, req *models.CreateCustomerRequest, createdBy string) (*models.Customer, error) {
+	result, err := r.generic.Insert(ctx, TableCustomers, tenantID, customerColumns(req), createdBy)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create customer: %w", err)
 	}
@@ -190,108 +176,112 @@ func (r *CustomerRepository) Create(ctx context.Context, tenantID string, req *m
 	return r.GetByID(ctx, tenantID, *result.GeneratedID)
 }
 
-// GetByID retrieves a customer by ID
-// Stored procedure sp_get_customer available for ref cursor usage
-func (r *CustomerRepository) GetByID(ctx context.Context, tenantID string, id int64) (*models.Customer, error) {
-	query := `
-		SELECT id, tenant_id, customer_code, customer_type, name, trade_name,
-			tax_id, state_reg, municipal_reg, email, phone, mobile,
-			address_street, address_number, address_comp, address_district,
-			address_city, address_state, address_zip, address_country,
-			active, notes, created_at, updated_at, created_by, updated_by
-		FROM customers
-		WHERE tenant_id = :1 AND id = :2`
-
-	customer, err := scanCustomer(r.db.QueryRowContext(ctx, query, tenantID, id))
-	if err == sql.ErrNoRows {
-		return nil, nil
-	}
-	if err != nil {
-		return nil, fmt.Errorf("failed to get customer: %w", err)
-	}
-
-	return customer, nil
+// CreateBatch creates customers in one transaction; if any insert fails,
+// none of them are created
+func (r *CustomerRepository) CreateBatch(ctx context.Context, tenantID string, reqs []*models.CreateCustomerRequest, createdBy string) error {
+	return RunInTx(ctx, r.db, func(repos *TxRepositories) error {
+		for _, req := range reqs {
+			result, err := repos.Generic.Insert(ctx, TableCustomers, tenantID, customerColumns(req), createdBy)
+			if err != nil {
+				return fmt.Errorf("failed to create customer %q: %w", req.CustomerCode, err)
+			}
+			if !result.Success {
+				return fmt.Errorf("failed to create customer %q: %s", req.CustomerCode, result.ErrorMessage)
+			}
+		}
+		return nil
+	})
 }
 
-// List retrieves customers with pagination
-func (r *CustomerRepository) List(ctx context.Context, tenantID string, params models.PaginationParams, search models.SearchParams) ([]models.Customer, int, error) {
-	conditions, lastIndex := buildSearchConditions(search, 2)
+// ExistingCodes returns which of codes already belong to one of the
+// tenant's customers, active or not. At most 1000 codes may be passed.
+func (r *CustomerRepository) ExistingCodes(ctx context.Context, tenantID string, codes []string) (map[string]bool, error) {
+	existing := make(map[string]bool)
+	if len(codes) == 0 {
+		return existing, nil
+	}
 
-	// Count query
-	total, err := r.countCustomers(ctx, tenantID, conditions)
-	if err != nil {
-		return nil, 0, err
+	placeholders := make([]string, len(codes))
+	args := make([]any, 0, len(codes)+1)
+	args = append(args, tenantID)
+	for i, code := range codes {
+		placeholders[i] = fmt.Sprintf(":%d", i+2)
+		args = append(args, code)
 	}
+	query := `SELECT customer_code FROM customers WHERE tenant_id = :1 AND customer_code IN (` +
+		strings.Join(placeholders, ", ") + `)`
 
-	// Main query
-	customers, err := r.fetchCustomers(ctx, tenantID, conditions, search, params, lastIndex)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, 0, err
+		return nil, fmt.Errorf("failed to look up customer codes: %w", err)
 	}
+	defer rows.Close()
 
-	return customers, total, nil
-}
-
-// countCustomers executes the count query with search conditions
-func (r *CustomerRepository) countCustomers(ctx context.Context, tenantID string, conditions []searchCondition) (int, error) {
-	countQuery := `SELECT COUNT(*) FROM customers WHERE tenant_id = :1`
-	args := []any{tenantID}
-
-	for _, cond := range conditions {
-		countQuery += cond.clause
-		args = append(args, cond.arg)
+	for rows.Next() {
+		var code string
+		if err := rows.Scan(&code); err != nil {
+			return nil, fmt.Errorf("failed to scan customer code: %w", err)
+		}
+		existing[code] = true
 	}
-
-	var total int
-	err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total)
-	if err != nil {
-		return 0, fmt.Errorf("failed to count customers: %w", err)
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate customer codes: %w", err)
 	}
-	return total, nil
+	return existing, nil
 }
 
-// fetchCustomers executes the main query with search, sorting, and pagination
-// Stored procedure sp_list_customers available for ref cursor usage
-func (r *CustomerRepository) fetchCustomers(ctx context.Context, tenantID string, conditions []searchCondition, search models.SearchParams, params models.PaginationParams, argIndex int) ([]models.Customer, error) {
-	query := `
-		SELECT id, tenant_id, customer_code, customer_type, name, trade_name,
-			tax_id, state_reg, municipal_reg, email, phone, mobile,
-			address_street, address_number, address_comp, address_district,
-			address_city, address_state, address_zip, address_country,
-			active, notes, created_at, updated_at, created_by, updated_by
-		FROM customers
-		WHERE tenant_id = :1`
+// GetByID retrieves a customer by ID
+// Stored procedure sp_get_customer available for ref cursor usage
+func (r *CustomerRepository) GetByID(ctx context.Context, tenantID string, id int64) (*models.Customer, error) {
+	query := `SELECT ` + customerSelectColumns + ` FROM customers WHERE tenant_id = :1 AND id = :2`
 
-	queryArgs := []any{tenantID}
-	for _, cond := range conditions {
-		query += cond.clause
-		queryArgs = append(queryArgs, cond.arg)
+	customer, err := scanCustomer(r.db.QueryRowContext(ctx, query, tenantID, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
 	}
-
-	query += applySorting(search)
-	query += fmt.Sprintf(" OFFSET :%d ROWS FETCH NEXT :%d ROWS ONLY", argIndex, argIndex+1)
-	queryArgs = append(queryArgs, params.Offset(), params.Limit())
-
-	rows, err := r.db.QueryContext(ctx, query, queryArgs...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list customers: %w", err)
+		return nil, fmt.Errorf("failed to get customer: %w", err)
 	}
-	defer rows.Close()
 
-	var customers []models.Customer
-	for rows.Next() {
-		customer, err := scanCustomer(rows)
+	return customer, nil
+}
+
+// List retrieves customers with pagination, counting the matches in the
+// same round trip
+// Stored procedure sp_list_customers available for ref cursor usage
+func (r *CustomerRepository) List(ctx context.Context, tenantID string, params models.PaginationParams, search models.SearchParams) ([]models.Customer, int, error) {
+	sortBy, sortDir := getSortClause(search.SortBy, search.SortDir, customerListAllowedSorts, "created_at")
+	return listPage(ctx, r.db, tenantID, pageQuery{
+		noun:    "customers",
+		table:   "customers",
+		columns: customerSelectColumns,
+		filter:  customerFilterConditions(search),
+		orderBy: sortBy + " " + sortDir,
+		offset:  params.Offset(),
+		limit:   params.Limit(),
+	}, func(row rowScanner) (models.Customer, error) {
+		customer, err := scanCustomer(row)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan customer: %w", err)
+			return models.Customer{}, err
 		}
-		customers = append(customers, *customer)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("failed to iterate customers: %w", err)
-	}
+		return *customer, nil
+	})
+}
 
-	return customers, nil
+// SearchSummary finds active customers whose name, trade name or code
+// contains query, returning up to limit of them by name and the number of
+// matches
+func (r *CustomerRepository) SearchSummary(ctx context.Context, tenantID, query string, limit int) ([]models.SearchSummary, int, error) {
+	return searchSummaries(ctx, r.db, models.SearchTypeCustomer, `
+		SELECT id, name, customer_code, COUNT(*) OVER ()
+		FROM customers
+		WHERE tenant_id = :1 AND active = 1
+			AND (UPPER(name) LIKE UPPER(:2) ESCAPE '\'
+				OR UPPER(trade_name) LIKE UPPER(:3) ESCAPE '\'
+				OR UPPER(customer_code) LIKE UPPER(:4) ESCAPE '\')
+		ORDER BY name, id
+		FETCH FIRST :5 ROWS ONLY`,
+		tenantID, LikeContains(query), LikeContains(query), LikeContains(query), limit)
 }
 
 // Update updates a customer using dynamic CRUD
@@ -321,7 +311,7 @@ func (r *CustomerRepository) Update(ctx context.Context, tenantID string, id int
 		return nil, fmt.Errorf("failed to update customer: %s", result.ErrorMessage)
 	}
 	if result.RowsAffected == 0 {
-		return nil, fmt.Errorf("customer not found: tenant=%s id=%d", tenantID, id)
+		return nil, sql.ErrNoRows
 	}
 
 	return r.GetByID(ctx, tenantID, id)
@@ -329,7 +319,7 @@ func (r *CustomerRepository) Update(ctx context.Context, tenantID string, id int
 
 // Delete soft-deletes a customer using dynamic CRUD
 func (r *CustomerRepository) Delete(ctx context.Context, tenantID string, id int64, deletedBy string) error {
-	result, err := r.generic.Delete(ctx, TableCustomers, tenantID, id, true, deletedBy)
+	result, err := r.generic.Delete(ctx, TableCustomers, tenantID, id, deletedBy)
 	if err != nil {
 		return fmt.Errorf("failed to delete customer: %w", err)
 	}
@@ -337,7 +327,7 @@ func (r *CustomerRepository) Delete(ctx context.Context, tenantID string, id int
 		return fmt.Errorf("failed to delete customer: %s", result.ErrorMessage)
 	}
 	if result.RowsAffected == 0 {
-		return fmt.Errorf("customer not found: tenant=%s id=%d", tenantID, id)
+		return sql.ErrNoRows
 	}
 	return nil
 }
@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/godror/godror"
+	"github.com/zlovtnik/gprint/internal/metrics"
+	"github.com/zlovtnik/gprint/pkg/requestctx"
+)
+
+// ErrQueryTimeout is returned when a statement exceeds the configured statement timeout
+var ErrQueryTimeout = errors.New("database query timed out")
+
+// maxLoggedSQLLength truncates SQL text in slow query logs
+const maxLoggedSQLLength = 500
+
+// DBConfig holds statement limits applied by DB
+type DBConfig struct {
+	QueryTimeout       time.Duration // Per-statement timeout; zero disables it
+	SlowQueryThreshold time.Duration // Statements slower than this are logged; zero disables logging
+}
+
+// DB wraps *sql.DB for repositories, bounding every statement with the
+// statement timeout and logging slow statements. The embedded *sql.DB remains
+// available for calls that are not instrumented, such as Stats.
+type DB struct {
+	*sql.DB
+	cfg           DBConfig
+	slowThreshold atomic.Int64 // cfg.SlowQueryThreshold, adjustable at runtime
+	logger        *slog.Logger
+}
+
+// NewDB wraps db with the given statement limits
+func NewDB(db *sql.DB, cfg DBConfig, logger *slog.Logger) *DB {
+	if db == nil {
+		return nil
+	}
+	wrapped := &DB{DB: db, cfg: cfg, logger: logger}
+	wrapped.SetSlowQueryThreshold(cfg.SlowQueryThreshold)
+	return wrapped
+}
+
+// SetSlowQueryThreshold changes the slow query threshold; zero disables slow query logging
+func (db *DB) SetSlowQueryThreshold(d time.Duration) {
+	db.slowThreshold.Store(int64(d))
+}
+
+// ExecContext executes a statement within the statement timeout
+func (db *DB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	qctx := db.withTimeout(ctx)
+	start := time.Now()
+	result, err := db.DB.ExecContext(qctx, query, args...)
+	return result, db.observe(ctx, qctx, query, start, err)
+}
+
+// QueryContext executes a query within the statement timeout. The timeout
+// also covers reading the rows.
+func (db *DB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	qctx := db.withTimeout(ctx)
+	start := time.Now()
+	rows, err := db.DB.QueryContext(qctx, query, args...)
+	return rows, db.observe(ctx, qctx, query, start, err)
+}
+
+// QueryRowContext executes a single-row query within the statement timeout.
+// Errors, including timeouts, are reported by Scan.
+func (db *DB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	qctx := db.withTimeout(ctx)
+	start := time.Now()
+	row := db.DB.QueryRowContext(qctx, query, args...)
+	_ = db.observe(ctx, qctx, query, start, row.Err())
+	return row
+}
+
+// QueryCursor executes a PL/SQL block that opens a REF CURSOR into the
+// sql.Out bind pointing at rset and returns the cursor as rows. The block
+// runs on a dedicated connection, since the cursor only exists in that
+// session; the connection goes back to the pool when the rows are closed.
+func (db *DB) QueryCursor(ctx context.Context, query string, rset *driver.Rows, args ...any) (*sql.Rows, error) {
+	qctx := db.withTimeout(ctx)
+	start := time.Now()
+	conn, err := db.DB.Conn(qctx)
+	if err != nil {
+		return nil, db.observe(ctx, qctx, query, start, err)
+	}
+	if _, err := conn.ExecContext(qctx, query, args...); err != nil {
+		_ = conn.Close()
+		return nil, db.observe(ctx, qctx, query, start, err)
+	}
+	if *rset == nil {
+		_ = conn.Close()
+		return nil, db.observe(ctx, qctx, query, start, errors.New("no cursor returned"))
+	}
+	rows, err := godror.WrapRows(qctx, conn, *rset)
+	if err != nil {
+		_ = (*rset).Close()
+		_ = conn.Close()
+		return nil, db.observe(ctx, qctx, query, start, err)
+	}
+	// Conn.Close blocks until the rows are closed before releasing the connection
+	go conn.Close()
+	return rows, db.observe(ctx, qctx, query, start, nil)
+}
+
+// ExecLOBs executes a PL/SQL block whose CLOB and BLOB out binds are
+// *godror.Lob, then calls read to consume them. The block runs on a
+// dedicated connection, since the LOB locators only exist in that session;
+// the readers are invalid once ExecLOBs returns.
+func (db *DB) ExecLOBs(ctx context.Context, query string, read func() error, args ...any) error {
+	qctx := db.withTimeout(ctx)
+	start := time.Now()
+	conn, err := db.DB.Conn(qctx)
+	if err != nil {
+		return db.observe(ctx, qctx, query, start, err)
+	}
+	defer conn.Close()
+
+	_, err = conn.ExecContext(qctx, query, append([]any{godror.LobAsReader()}, args...)...)
+	if err := db.observe(ctx, qctx, query, start, err); err != nil {
+		return err
+	}
+	return read()
+}
+
+// withTimeout bounds ctx by the statement timeout. The context is not
+// cancelled when the call returns, because rows, *sql.Row and REF CURSOR out
+// binds are read afterwards; it is released when its deadline passes.
+func (db *DB) withTimeout(ctx context.Context) context.Context {
+	if db.cfg.QueryTimeout <= 0 {
+		return ctx
+	}
+	qctx, cancel := context.WithTimeout(ctx, db.cfg.QueryTimeout)
+	_ = cancel // released by the deadline, see above
+	return qctx
+}
+
+// observe logs and counts slow and timed-out statements. A timeout is
+// reported as ErrQueryTimeout; other errors are returned unchanged.
+func (db *DB) observe(ctx, qctx context.Context, query string, start time.Time, err error) error {
+	elapsed := time.Since(start)
+	logger := requestctx.LoggerOr(ctx, db.logger)
+
+	if err != nil && qctx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+		metrics.DBQueryTimeouts.Inc()
+		logger.Warn("database query timed out",
+			"sql", truncateSQL(query),
+			"duration", elapsed,
+			"timeout", db.cfg.QueryTimeout,
+		)
+		return fmt.Errorf("%w: %w", ErrQueryTimeout, err)
+	}
+
+	if threshold := time.Duration(db.slowThreshold.Load()); threshold > 0 && elapsed > threshold {
+		metrics.DBSlowQueries.Inc()
+		logger.Warn("slow database query",
+			"sql", truncateSQL(query),
+			"duration", elapsed,
+			"threshold", threshold,
+		)
+	}
+	return err
+}
+
+// truncateSQL collapses whitespace and shortens SQL text for logging
+func truncateSQL(query string) string {
+	query = strings.Join(strings.Fields(query), " ")
+	if len(query) > maxLoggedSQLLength {
+		return query[:maxLoggedSQLLength] + "..."
+	}
+	return query
+}
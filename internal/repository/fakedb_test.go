@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+)
+
+// fakeDB is an in-memory database/sql driver standing in for Oracle. PL/SQL
+// blocks are recorded; REF CURSOR out binds receive fakeRows, which the
+// cursor-wrapping query godror issues hands back, and NUMBER out binds
+// receive count or 1 (success).
+type fakeDB struct {
+	mu        sync.Mutex
+	columns   []string
+	rows      [][]driver.Value
+	noCursor  bool  // leave REF CURSOR out binds unset
+	count     int64 // value of *int64 out binds
+	execErr   error // returned by every ExecContext
+	execs     []string
+	cursors   []*fakeRows
+	commits   int
+	rollbacks int
+}
+
+// open returns a *DB over f
+func (f *fakeDB) open(t *testing.T) *DB {
+	t.Helper()
+	sqlDB := sql.OpenDB(fakeConnector{f})
+	t.Cleanup(func() { sqlDB.Close() })
+	return NewDB(sqlDB, DBConfig{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+type fakeConnector struct{ db *fakeDB }
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) { return &fakeConn{db: c.db}, nil }
+func (c fakeConnector) Driver() driver.Driver                        { return fakeDriver{} }
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("fakeDriver: use the connector")
+}
+
+type fakeConn struct{ db *fakeDB }
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("fakeConn: Prepare") }
+func (c *fakeConn) Close() error                        { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)           { return &fakeTx{db: c.db}, nil }
+
+func (c *fakeConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	return &fakeTx{db: c.db}, nil
+}
+
+// CheckNamedValue passes out binds and cursors through to the driver
+func (c *fakeConn) CheckNamedValue(nv *driver.NamedValue) error {
+	switch nv.Value.(type) {
+	case sql.Out, driver.Rows:
+		return nil
+	}
+	return driver.ErrSkip
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	f := c.db
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.execs = append(f.execs, query)
+	if f.execErr != nil {
+		return nil, f.execErr
+	}
+	for _, arg := range args {
+		out, ok := arg.Value.(sql.Out)
+		if !ok {
+			continue
+		}
+		switch dest := out.Dest.(type) {
+		case *driver.Rows:
+			if !f.noCursor {
+				rows := &fakeRows{columns: f.columns, rows: f.rows}
+				f.cursors = append(f.cursors, rows)
+				*dest = rows
+			}
+		case *int64:
+			*dest = f.count
+		case *int:
+			*dest = 1
+		}
+	}
+	return driver.RowsAffected(1), nil
+}
+
+// QueryContext only serves godror.WrapRows, which queries with the cursor as
+// its single argument
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if len(args) == 1 {
+		if rows, ok := args[0].Value.(driver.Rows); ok {
+			return rows, nil
+		}
+	}
+	return nil, errors.New("fakeConn: only cursors can be queried")
+}
+
+type fakeTx struct{ db *fakeDB }
+
+func (tx *fakeTx) Commit() error {
+	tx.db.mu.Lock()
+	defer tx.db.mu.Unlock()
+	tx.db.commits++
+	return nil
+}
+
+func (tx *fakeTx) Rollback() error {
+	tx.db.mu.Lock()
+	defer tx.db.mu.Unlock()
+	tx.db.rollbacks++
+	return nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+	next    int
+	closed  bool
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+
+func (r *fakeRows) Close() error {
+	r.closed = true
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+	return nil
+}
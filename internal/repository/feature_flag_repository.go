@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/zlovtnik/gprint/internal/models"
+)
+
+// TableFeatureFlags is the per-tenant feature flags table
+const TableFeatureFlags = "FEATURE_FLAGS"
+
+// FeatureFlagRepository handles feature flag data access
+type FeatureFlagRepository struct {
+	db *DB
+}
+
+// NewFeatureFlagRepository creates a new FeatureFlagRepository
+func NewFeatureFlagRepository(db *DB) (*FeatureFlagRepository, error) {
+	if db == nil {
+		return nil, fmt.Errorf("NewFeatureFlagRepository: db is nil")
+	}
+	return &FeatureFlagRepository{db: db}, nil
+}
+
+// List retrieves the flags set for a tenant, keyed by name
+func (r *FeatureFlagRepository) List(ctx context.Context, tenantID string) (map[string]models.FeatureFlag, error) {
+	query := `SELECT flag_name, enabled, updated_at, updated_by
+		FROM ` + TableFeatureFlags + `
+		WHERE tenant_id = :1`
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feature flags: %w", err)
+	}
+	defer rows.Close()
+
+	flags := make(map[string]models.FeatureFlag)
+	for rows.Next() {
+		f := models.FeatureFlag{Source: models.FeatureSourceTenant}
+		var enabled int
+		var updatedAt sql.NullTime
+		var updatedBy sql.NullString
+		if err := rows.Scan(&f.Name, &enabled, &updatedAt, &updatedBy); err != nil {
+			return nil, fmt.Errorf("failed to scan feature flag: %w", err)
+		}
+		f.Enabled = IntToBool(enabled)
+		f.UpdatedAt = TimeFromNull(updatedAt)
+		f.UpdatedBy = updatedBy.String
+		flags[f.Name] = f
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate feature flags: %w", err)
+	}
+	return flags, nil
+}
+
+// Set enables or disables a flag for a tenant
+func (r *FeatureFlagRepository) Set(ctx context.Context, tenantID, name string, enabled bool, updatedBy string) error {
+	query := `MERGE INTO ` + TableFeatureFlags + ` t
+		USING (SELECT :1 AS tenant_id, :2 AS flag_name FROM dual) s
+		ON (t.tenant_id = s.tenant_id AND t.flag_name = s.flag_name)
+		WHEN MATCHED THEN UPDATE SET
+			enabled = :3, updated_at = CURRENT_TIMESTAMP, updated_by = :4
+		WHEN NOT MATCHED THEN INSERT (tenant_id, flag_name, enabled, updated_by)
+			VALUES (s.tenant_id, s.flag_name, :5, :6)`
+	value := BoolToInt(enabled)
+	if _, err := r.db.ExecContext(ctx, query, tenantID, name, value, updatedBy, value, updatedBy); err != nil {
+		return fmt.Errorf("failed to set feature flag: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a tenant's flag so the server default applies again
+func (r *FeatureFlagRepository) Delete(ctx context.Context, tenantID, name string) error {
+	_, err := r.db.ExecContext(ctx,
+		`DELETE FROM `+TableFeatureFlags+` WHERE tenant_id = :1 AND flag_name = :2`, tenantID, name,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete feature flag: %w", err)
+	}
+	return nil
+}
@@ -4,9 +4,18 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"fmt"
+	"reflect"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/godror/godror"
 )
 
 // identifierPattern validates SQL identifiers to prevent SQL injection.
@@ -27,17 +36,33 @@ func validateIdentifier(name string) error {
 	return nil
 }
 
-// allowedTables contains the whitelist of tables that can be accessed via GenericRepository.
-// This prevents SQL injection by ensuring only known, safe table names are accepted.
-var allowedTables = map[string]bool{
-	"CONTRACTS":           true,
-	"CONTRACT_ITEMS":      true,
-	"CUSTOMERS":           true,
-	"SERVICES":            true,
-	"CONTRACT_HISTORY":    true,
-	"CONTRACT_PRINT_JOBS": true,
-	"CONTRACT_TEMPLATES":  true,
-	"GENERATED_CONTRACTS": true,
+// TableOptions describes how GenericRepository treats a registered table.
+// The tenant key column is not declared here: pkg_crud reads it from the
+// table's crud_allowed_tables row.
+type TableOptions struct {
+	SoftDelete bool // Delete sets ACTIVE = 0 instead of removing the row; the table needs an ACTIVE column
+}
+
+// tables is the allowlist of tables GenericRepository may access, filled by
+// RegisterTable. Only registered, known table names are accepted, which
+// keeps caller input out of the generated PL/SQL.
+var (
+	tablesMu sync.RWMutex
+	tables   = map[string]TableOptions{}
+)
+
+// RegisterTable adds name to the tables GenericRepository may access,
+// replacing any earlier registration. Repositories call it from init. It
+// panics on an invalid name, so mistakes surface at startup. The table must
+// also be registered with pkg_crud.register_table.
+func RegisterTable(name string, opts TableOptions) {
+	if err := validateIdentifier(name); err != nil {
+		panic(fmt.Sprintf("RegisterTable: %v", err))
+	}
+
+	tablesMu.Lock()
+	defer tablesMu.Unlock()
+	tables[strings.ToUpper(name)] = opts
 }
 
 const (
@@ -45,12 +70,22 @@ const (
 	queryErrFmt         = "query %s: %w"
 )
 
-// validateTableName checks if a table name is in the allowed list.
-func validateTableName(name string) error {
-	if !allowedTables[strings.ToUpper(name)] {
-		return fmt.Errorf("table %q is not in the allowed list for generic operations", name)
+// tableOptions returns the registration of a table, or an error if it is
+// not registered
+func tableOptions(name string) (TableOptions, error) {
+	tablesMu.RLock()
+	defer tablesMu.RUnlock()
+	opts, ok := tables[strings.ToUpper(name)]
+	if !ok {
+		return TableOptions{}, fmt.Errorf("table %q is not in the allowed list for generic operations", name)
 	}
-	return nil
+	return opts, nil
+}
+
+// validateTableName checks if a table name is registered.
+func validateTableName(name string) error {
+	_, err := tableOptions(name)
+	return err
 }
 
 // ColumnValue represents a column name-value pair for dynamic CRUD operations.
@@ -61,13 +96,26 @@ type ColumnValue struct {
 }
 
 // FilterCondition represents a WHERE clause filter used by `Query` and `Count`.
+// For IN, Value is a slice of up to maxInValues values; an empty slice
+// matches no rows. For DATE and TIMESTAMP filters, Value is a time.Time or a
+// string in the dateFilterLayout or timestampFilterLayout format.
 type FilterCondition struct {
 	Column   string
 	Operator string // =, <>, <, >, <=, >=, LIKE, IN, IS NULL, IS NOT NULL
 	Value    any
-	Type     string
+	Type     string // STRING, NUMBER, DATE, TIMESTAMP; inferred from Value when empty
 }
 
+// maxInValues caps the values of an IN filter, matching Oracle's IN list limit
+const maxInValues = 1000
+
+// Layouts of DATE and TIMESTAMP filter values, matching the formats
+// t_filter_condition converts them with
+const (
+	dateFilterLayout      = "2006-01-02T15:04:05"
+	timestampFilterLayout = "2006-01-02T15:04:05.000000000"
+)
+
 // SortSpec represents an ORDER BY specification used by `Query`.
 type SortSpec struct {
 	Column    string
@@ -84,43 +132,85 @@ type CRUDResult struct {
 	ErrorMessage string
 }
 
+// UpsertResult is the result of an Upsert. Inserted tells whether the row
+// was created or an existing one updated.
+type UpsertResult struct {
+	CRUDResult
+	Inserted bool
+}
+
 // GenericRepository provides dynamic CRUD operations using pkg_crud.
 type GenericRepository struct {
-	db *sql.DB
+	db Execer
 }
 
 // NewGenericRepository creates a new GenericRepository.
-func NewGenericRepository(db *sql.DB) *GenericRepository {
+func NewGenericRepository(db *DB) *GenericRepository {
 	if db == nil {
 		panic("GenericRepository: db is nil")
 	}
+	return newGenericRepository(db)
+}
+
+func newGenericRepository(db Execer) *GenericRepository {
 	return &GenericRepository{db: db}
 }
 
-// buildColumnValuesSQL creates the t_column_values constructor SQL.
-// Returns an error if any column name is not a valid SQL identifier.
-func buildColumnValuesSQL(cols []ColumnValue) (string, error) {
+// WithTx returns a copy of the repository whose operations run in tx
+func (r *GenericRepository) WithTx(tx *sql.Tx) *GenericRepository {
+	if tx == nil {
+		panic("GenericRepository: tx is nil")
+	}
+	return newGenericRepository(tx)
+}
+
+// maxVarchar2Bytes is the size of the VARCHAR2 attributes of the pkg_crud
+// types; longer values travel as CLOBs
+const maxVarchar2Bytes = 4000
+
+// plsqlBinds collects the bind values of a generated PL/SQL block. Each
+// value gets the next positional placeholder, so values must be added in
+// the order their placeholders appear in the block.
+type plsqlBinds struct {
+	args []any
+}
+
+// add records v and returns its placeholder
+func (b *plsqlBinds) add(v any) string {
+	b.args = append(b.args, v)
+	return ":" + strconv.Itoa(len(b.args))
+}
+
+// buildColumnValuesSQL creates the t_column_values constructor SQL, binding
+// every name, value and type. Returns an error if any column name is not a
+// valid SQL identifier.
+func buildColumnValuesSQL(cols []ColumnValue, binds *plsqlBinds) (string, error) {
 	if len(cols) == 0 {
 		return "NULL", nil
 	}
 
-	var parts []string
+	parts := make([]string, 0, len(cols))
 	for _, col := range cols {
 		// Validate column name to prevent SQL injection
 		if err := validateIdentifier(col.Name); err != nil {
 			return "", fmt.Errorf("invalid column name: %w", err)
 		}
 
-		valueStr := formatValue(col.Value)
 		colType := col.Type
 		if colType == "" {
 			colType = inferType(col.Value)
 		}
+		value := bindValue(col.Value)
+		var clob any
+		if s, ok := value.(string); ok && len(s) > maxVarchar2Bytes {
+			value, clob = nil, godror.Lob{Reader: strings.NewReader(s), IsClob: true}
+		}
 		parts = append(parts, fmt.Sprintf(
-			"t_column_value('%s', %s, '%s')",
-			escapeSQLString(col.Name),
-			valueStr,
-			colType,
+			"t_column_value(%s, %s, %s, %s)",
+			binds.add(col.Name),
+			binds.add(value),
+			binds.add(colType),
+			binds.add(clob),
 		))
 	}
 
@@ -144,9 +234,9 @@ func buildColumnsCSV(cols []string) (string, error) {
 	return strings.Join(parts, ","), nil
 }
 
-// buildFilterConditionsSQL creates the t_filter_conditions constructor SQL.
-// Returns "NULL" when no filters are provided.
-func buildFilterConditionsSQL(filters []FilterCondition) (string, error) {
+// buildFilterConditionsSQL creates the t_filter_conditions constructor SQL
+// with bound values. Returns "NULL" when no filters are provided.
+func buildFilterConditionsSQL(filters []FilterCondition, binds *plsqlBinds) (string, error) {
 	if len(filters) == 0 {
 		return "NULL", nil
 	}
@@ -167,29 +257,112 @@ func buildFilterConditionsSQL(filters []FilterCondition) (string, error) {
 
 		valueType := f.Type
 		if valueType == "" {
-			valueType = inferType(f.Value)
+			valueType = inferFilterType(f.Value)
 		}
 
-		valueExpr := "NULL"
-		if op != "IS NULL" && op != "IS NOT NULL" {
-			valueExpr = formatValue(f.Value)
+		var value any
+		var inValues []any
+		switch op {
+		case "IS NULL", "IS NOT NULL":
+		case "IN":
+			list, err := filterValues(f.Column, f.Value, valueType)
+			if err != nil {
+				return "", err
+			}
+			inValues = list
+		default:
+			v, err := filterValue(f.Column, f.Value, valueType)
+			if err != nil {
+				return "", err
+			}
+			value = v
 		}
 
-		parts = append(parts, fmt.Sprintf(
-			"t_filter_condition('%s', '%s', %s, '%s')",
-			escapeSQLString(f.Column),
-			op,
-			valueExpr,
-			escapeSQLString(valueType),
-		))
+		part := fmt.Sprintf(
+			"t_filter_condition(%s, %s, %s, %s, ",
+			binds.add(f.Column),
+			binds.add(op),
+			binds.add(value),
+			binds.add(valueType),
+		)
+		if op == "IN" {
+			placeholders := make([]string, len(inValues))
+			for i, v := range inValues {
+				placeholders[i] = binds.add(v)
+			}
+			part += "t_filter_values(" + strings.Join(placeholders, ", ") + "))"
+		} else {
+			part += "NULL)"
+		}
+		parts = append(parts, part)
 	}
 
 	return "t_filter_conditions(" + strings.Join(parts, ", ") + ")", nil
 }
 
+// filterValues returns the bind values of an IN filter, whose Value must be
+// a slice (or array) of at most maxInValues elements
+func filterValues(column string, v any, valueType string) ([]any, error) {
+	rv := reflect.ValueOf(v)
+	if v == nil || (rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array) || rv.Type().Elem().Kind() == reflect.Uint8 {
+		return nil, fmt.Errorf("IN filter on %s needs a slice value", column)
+	}
+	if rv.Len() > maxInValues {
+		return nil, fmt.Errorf("IN filter on %s has %d values (max %d)", column, rv.Len(), maxInValues)
+	}
+	values := make([]any, rv.Len())
+	for i := range values {
+		value, err := filterValue(column, rv.Index(i).Interface(), valueType)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// filterValue returns the bind value of a single filter value. time.Time
+// values are formatted for DATE and TIMESTAMP filters, so the conversion
+// does not depend on the session's NLS settings.
+func filterValue(column string, v any, valueType string) (any, error) {
+	var value any
+	if t, ok := v.(time.Time); ok {
+		switch strings.ToUpper(valueType) {
+		case "DATE":
+			value = t.Format(dateFilterLayout)
+		case "TIMESTAMP":
+			value = t.Format(timestampFilterLayout)
+		default:
+			return nil, fmt.Errorf("filter on %s: time value needs type DATE or TIMESTAMP, got %q", column, valueType)
+		}
+	} else {
+		value = bindValue(v)
+	}
+	if s, ok := value.(string); ok && len(s) > maxVarchar2Bytes {
+		return nil, fmt.Errorf("filter value for %s exceeds %d bytes", column, maxVarchar2Bytes)
+	}
+	return value, nil
+}
+
+// inferFilterType determines the value type of a filter value; for IN
+// filters it is inferred from the first element.
+func inferFilterType(v any) string {
+	rv := reflect.ValueOf(v)
+	if v != nil && (rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array) && rv.Type().Elem().Kind() != reflect.Uint8 {
+		if rv.Len() == 0 {
+			return "STRING"
+		}
+		v = rv.Index(0).Interface()
+	}
+	if _, ok := v.(time.Time); ok {
+		return "TIMESTAMP"
+	}
+	return inferType(v)
+}
+
 // buildSortSpecsSQL creates the t_sort_specs constructor SQL.
 // Returns "NULL" when no sorts are provided.
-func buildSortSpecsSQL(sorts []SortSpec) (string, error) {
+func buildSortSpecsSQL(sorts []SortSpec, binds *plsqlBinds) (string, error) {
 	if len(sorts) == 0 {
 		return "NULL", nil
 	}
@@ -208,47 +381,33 @@ func buildSortSpecsSQL(sorts []SortSpec) (string, error) {
 		}
 
 		parts = append(parts, fmt.Sprintf(
-			"t_sort_spec('%s', '%s')",
-			escapeSQLString(s.Column),
-			dir,
+			"t_sort_spec(%s, %s)",
+			binds.add(s.Column),
+			binds.add(dir),
 		))
 	}
 
 	return "t_sort_specs(" + strings.Join(parts, ", ") + ")", nil
 }
 
-// formatValue converts a Go value to SQL literal.
-func formatValue(v any) string {
-	if v == nil {
-		return "NULL"
-	}
+// bindValue converts a Go value to the text pkg_crud expects, or nil for NULL.
+// pkg_crud converts the text to the column's type.
+func bindValue(v any) any {
 	switch val := v.(type) {
+	case nil:
+		return nil
 	case string:
-		return "'" + escapeSQLString(val) + "'"
-	case int, int32, int64, float32, float64:
-		return fmt.Sprintf("%v", val)
+		return val
 	case bool:
 		if val {
-			return "'1'"
+			return "1"
 		}
-		return "'0'"
+		return "0"
 	default:
-		return "'" + escapeSQLString(fmt.Sprintf("%v", val)) + "'"
+		return fmt.Sprintf("%v", val)
 	}
 }
 
-// escapeSQLString escapes problematic characters in SQL strings.
-// Handles single quotes, null bytes, and backslashes for Oracle compatibility.
-func escapeSQLString(s string) string {
-	// Remove null bytes which can cause issues
-	s = strings.ReplaceAll(s, "\x00", "")
-	// Escape backslashes (Oracle treats them specially in some contexts)
-	s = strings.ReplaceAll(s, "\\", "\\\\")
-	// Escape single quotes by doubling them
-	s = strings.ReplaceAll(s, "'", "''")
-	return s
-}
-
 // inferType determines the value type for Oracle.
 func inferType(v any) string {
 	if v == nil {
@@ -277,11 +436,16 @@ func (r *GenericRepository) Insert(
 		return nil, fmt.Errorf("insert: %w", err)
 	}
 
-	colsSQL, err := buildColumnValuesSQL(columns)
+	binds := &plsqlBinds{}
+	colsSQL, err := buildColumnValuesSQL(columns, binds)
 	if err != nil {
 		return nil, fmt.Errorf("insert %s: %w", tableName, err)
 	}
 
+	var id sql.NullInt64
+	var success int
+	var errorMsg sql.NullString
+
 	query := fmt.Sprintf(`
 		DECLARE
 			v_cols t_column_values := %s;
@@ -289,25 +453,21 @@ func (r *GenericRepository) Insert(
 			v_success NUMBER;
 			v_error VARCHAR2(4000);
 		BEGIN
-			sp_generic_insert(:1, :2, v_cols, :3, v_id, v_success, v_error);
-			:4 := v_id;
-			:5 := v_success;
-			:6 := v_error;
+			sp_generic_insert(%s, %s, v_cols, %s, v_id, v_success, v_error);
+			%s := v_id;
+			%s := v_success;
+			%s := v_error;
 		END;
-	`, colsSQL)
-
-	var id sql.NullInt64
-	var success int
-	var errorMsg sql.NullString
-
-	_, err = r.db.ExecContext(ctx, query,
-		tableName,
-		tenantID,
-		sql.NullString{String: createdBy, Valid: createdBy != ""},
-		sql.Out{Dest: &id},
-		sql.Out{Dest: &success},
-		sql.Out{Dest: &errorMsg},
+	`, colsSQL,
+		binds.add(tableName),
+		binds.add(tenantID),
+		binds.add(sql.NullString{String: createdBy, Valid: createdBy != ""}),
+		binds.add(sql.Out{Dest: &id}),
+		binds.add(sql.Out{Dest: &success}),
+		binds.add(sql.Out{Dest: &errorMsg}),
 	)
+
+	_, err = r.db.ExecContext(ctx, query, binds.args...)
 	if err != nil {
 		return nil, fmt.Errorf("insert %s: %w", tableName, err)
 	}
@@ -353,11 +513,16 @@ func (r *GenericRepository) Update(
 		return nil, fmt.Errorf("update: %w", err)
 	}
 
-	colsSQL, err := buildColumnValuesSQL(columns)
+	binds := &plsqlBinds{}
+	colsSQL, err := buildColumnValuesSQL(columns, binds)
 	if err != nil {
 		return nil, fmt.Errorf("update %s: %w", tableName, err)
 	}
 
+	var rows int64
+	var success int
+	var errorMsg sql.NullString
+
 	query := fmt.Sprintf(`
 		DECLARE
 			v_cols t_column_values := %s;
@@ -365,26 +530,22 @@ func (r *GenericRepository) Update(
 			v_success NUMBER;
 			v_error VARCHAR2(4000);
 		BEGIN
-			sp_generic_update(:1, :2, :3, v_cols, :4, v_rows, v_success, v_error);
-			:5 := v_rows;
-			:6 := v_success;
-			:7 := v_error;
+			sp_generic_update(%s, %s, %s, v_cols, %s, v_rows, v_success, v_error);
+			%s := v_rows;
+			%s := v_success;
+			%s := v_error;
 		END;
-	`, colsSQL)
-
-	var rows int64
-	var success int
-	var errorMsg sql.NullString
-
-	_, err = r.db.ExecContext(ctx, query,
-		tableName,
-		tenantID,
-		id,
-		sql.NullString{String: updatedBy, Valid: updatedBy != ""},
-		sql.Out{Dest: &rows},
-		sql.Out{Dest: &success},
-		sql.Out{Dest: &errorMsg},
+	`, colsSQL,
+		binds.add(tableName),
+		binds.add(tenantID),
+		binds.add(id),
+		binds.add(sql.NullString{String: updatedBy, Valid: updatedBy != ""}),
+		binds.add(sql.Out{Dest: &rows}),
+		binds.add(sql.Out{Dest: &success}),
+		binds.add(sql.Out{Dest: &errorMsg}),
 	)
+
+	_, err = r.db.ExecContext(ctx, query, binds.args...)
 	if err != nil {
 		return nil, fmt.Errorf("update %s: %w", tableName, err)
 	}
@@ -413,24 +574,136 @@ func (r *GenericRepository) Update(
 	return result, nil
 }
 
-// Delete performs a generic DELETE operation (soft delete by default).
-// If the table supports DELETED_BY, the value is passed to sp_generic_delete.
+// Upsert inserts a row or, when one with the same keyColumns values exists,
+// updates it, atomically via sp_generic_upsert. Every key column must be one
+// of columns with a non-NULL value that fits in a VARCHAR2; the table needs a
+// unique key on them for concurrent upserts to resolve to a single row.
+func (r *GenericRepository) Upsert(
+	ctx context.Context,
+	tableName string,
+	tenantID string,
+	keyColumns []string,
+	columns []ColumnValue,
+	actor string,
+) (*UpsertResult, error) {
+	if err := validateTableName(tableName); err != nil {
+		return nil, fmt.Errorf("upsert: %w", err)
+	}
+	keys, err := upsertKeys(keyColumns, columns)
+	if err != nil {
+		return nil, fmt.Errorf("upsert %s: %w", tableName, err)
+	}
+
+	binds := &plsqlBinds{}
+	keysSQL, err := buildColumnValuesSQL(keys, binds)
+	if err != nil {
+		return nil, fmt.Errorf("upsert %s: %w", tableName, err)
+	}
+	colsSQL, err := buildColumnValuesSQL(columns, binds)
+	if err != nil {
+		return nil, fmt.Errorf("upsert %s: %w", tableName, err)
+	}
+
+	var id sql.NullInt64
+	var inserted int
+	var success int
+	var errorMsg sql.NullString
+
+	query := fmt.Sprintf(`
+		DECLARE
+			v_keys t_column_values := %s;
+			v_cols t_column_values := %s;
+			v_id NUMBER;
+			v_inserted NUMBER;
+			v_success NUMBER;
+			v_error VARCHAR2(4000);
+		BEGIN
+			sp_generic_upsert(%s, %s, v_keys, v_cols, %s, v_id, v_inserted, v_success, v_error);
+			%s := v_id;
+			%s := v_inserted;
+			%s := v_success;
+			%s := v_error;
+		END;
+	`, keysSQL, colsSQL,
+		binds.add(tableName),
+		binds.add(tenantID),
+		binds.add(sql.NullString{String: actor, Valid: actor != ""}),
+		binds.add(sql.Out{Dest: &id}),
+		binds.add(sql.Out{Dest: &inserted}),
+		binds.add(sql.Out{Dest: &success}),
+		binds.add(sql.Out{Dest: &errorMsg}),
+	)
+
+	_, err = r.db.ExecContext(ctx, query, binds.args...)
+	if err != nil {
+		return nil, fmt.Errorf("upsert %s: %w", tableName, err)
+	}
+
+	if success != 1 {
+		errorStr := storedProcFailedMsg
+		if errorMsg.Valid && errorMsg.String != "" {
+			errorStr = errorMsg.String
+		}
+		return nil, fmt.Errorf("upsert %s: %s", tableName, errorStr)
+	}
+
+	result := &UpsertResult{
+		CRUDResult: CRUDResult{Success: true, RowsAffected: 1},
+		Inserted:   inserted == 1,
+	}
+	if id.Valid {
+		result.GeneratedID = &id.Int64
+	}
+	return result, nil
+}
+
+// upsertKeys returns the columns named by keyColumns, which must each appear
+// in columns with a non-NULL value short enough to compare as VARCHAR2
+func upsertKeys(keyColumns []string, columns []ColumnValue) ([]ColumnValue, error) {
+	if len(keyColumns) == 0 {
+		return nil, fmt.Errorf("no key columns")
+	}
+	keys := make([]ColumnValue, 0, len(keyColumns))
+	for _, name := range keyColumns {
+		if err := validateIdentifier(name); err != nil {
+			return nil, fmt.Errorf("invalid key column: %w", err)
+		}
+		idx := slices.IndexFunc(columns, func(c ColumnValue) bool { return strings.EqualFold(c.Name, name) })
+		if idx < 0 {
+			return nil, fmt.Errorf("key column %q is not among the columns", name)
+		}
+		col := columns[idx]
+		value := bindValue(col.Value)
+		if value == nil {
+			return nil, fmt.Errorf("key column %q is NULL", name)
+		}
+		if len(value.(string)) > maxVarchar2Bytes {
+			return nil, fmt.Errorf("key column %q value exceeds %d bytes", name, maxVarchar2Bytes)
+		}
+		keys = append(keys, col)
+	}
+	return keys, nil
+}
+
+// Delete performs a generic DELETE operation. Tables registered with
+// SoftDelete are deactivated instead of removed. If the table supports
+// DELETED_BY, the value is passed to sp_generic_delete.
 func (r *GenericRepository) Delete(
 	ctx context.Context,
 	tableName string,
 	tenantID string,
 	id int64,
-	softDelete bool,
 	deletedBy string,
 ) (*CRUDResult, error) {
 	// Validate table name against allowlist to prevent SQL injection
-	if err := validateTableName(tableName); err != nil {
+	opts, err := tableOptions(tableName)
+	if err != nil {
 		return nil, fmt.Errorf("delete: %w", err)
 	}
 
-	soft := 1
-	if !softDelete {
-		soft = 0
+	soft := 0
+	if opts.SoftDelete {
+		soft = 1
 	}
 
 	query := `
@@ -450,7 +723,7 @@ func (r *GenericRepository) Delete(
 	var success int
 	var errorMsg sql.NullString
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err = r.db.ExecContext(ctx, query,
 		tableName,
 		tenantID,
 		id,
@@ -542,6 +815,9 @@ type QueryOptions struct {
 }
 
 // Query retrieves rows from a table using pkg_crud.do_query with optional filters and sorting.
+// The REF CURSOR it returns is read on the connection that opened it. do_query
+// returns no cursor when the table does not allow selects or the query fails;
+// the reason is in crud_error_log.
 // Note: The caller is responsible for closing the returned rows.
 func (r *GenericRepository) Query(
 	ctx context.Context,
@@ -561,39 +837,60 @@ func (r *GenericRepository) Query(
 		return nil, fmt.Errorf(queryErrFmt, tableName, err)
 	}
 
-	filtersSQL, err := buildFilterConditionsSQL(opts.Filters)
+	var cursor driver.Rows
+	binds := &plsqlBinds{}
+	result := binds.add(sql.Out{Dest: &cursor})
+	table := binds.add(tableName)
+	tenant := binds.add(tenantID)
+	columns := binds.add(sql.NullString{String: colsCSV, Valid: colsCSV != ""})
+
+	filtersSQL, err := buildFilterConditionsSQL(opts.Filters, binds)
 	if err != nil {
 		return nil, fmt.Errorf(queryErrFmt, tableName, err)
 	}
 
-	sortSQL, err := buildSortSpecsSQL(opts.Sort)
+	sortSQL, err := buildSortSpecsSQL(opts.Sort, binds)
 	if err != nil {
 		return nil, fmt.Errorf(queryErrFmt, tableName, err)
 	}
 
 	query := fmt.Sprintf(`
 		BEGIN
-			:1 := pkg_crud.do_query(:2, :3, :4, %s, %s, :5, :6);
+			%s := pkg_crud.do_query(%s, %s, %s, %s, %s, %s, %s);
 		END;
-	`, filtersSQL, sortSQL)
+	`, result, table, tenant, columns, filtersSQL, sortSQL, binds.add(opts.Offset), binds.add(opts.Limit))
 
-	var cursor *sql.Rows
-	_, err = r.db.ExecContext(ctx, query,
-		sql.Out{Dest: &cursor},
-		tableName,
-		tenantID,
-		sql.NullString{String: colsCSV, Valid: colsCSV != ""},
-		opts.Offset,
-		opts.Limit,
-	)
+	rows, err := r.queryCursor(ctx, query, &cursor, binds.args...)
 	if err != nil {
 		return nil, fmt.Errorf(queryErrFmt, tableName, err)
 	}
 
-	return cursor, nil
+	return rows, nil
+}
+
+// queryCursor runs a block that opens the REF CURSOR bound to rset. Outside a
+// transaction DB.QueryCursor pins a connection; a transaction already has one.
+func (r *GenericRepository) queryCursor(ctx context.Context, query string, rset *driver.Rows, args ...any) (*sql.Rows, error) {
+	if db, ok := r.db.(*DB); ok {
+		return db.QueryCursor(ctx, query, rset, args...)
+	}
+	if _, err := r.db.ExecContext(ctx, query, args...); err != nil {
+		return nil, err
+	}
+	if *rset == nil {
+		return nil, errors.New("no cursor returned")
+	}
+	rows, err := godror.WrapRows(ctx, r.db, *rset)
+	if err != nil {
+		_ = (*rset).Close()
+		return nil, err
+	}
+	return rows, nil
 }
 
 // Count returns the number of rows matching the provided filters using pkg_crud.do_count.
+// Like Query, it applies the table's crud_allowed_tables config; do_count
+// returns -1 when it fails, which is reported as an error.
 func (r *GenericRepository) Count(
 	ctx context.Context,
 	tableName string,
@@ -604,26 +901,44 @@ func (r *GenericRepository) Count(
 		return 0, fmt.Errorf("count: %w", err)
 	}
 
-	filtersSQL, err := buildFilterConditionsSQL(filters)
+	var count int64
+	binds := &plsqlBinds{}
+	result := binds.add(sql.Out{Dest: &count})
+	table := binds.add(tableName)
+	tenant := binds.add(tenantID)
+
+	filtersSQL, err := buildFilterConditionsSQL(filters, binds)
 	if err != nil {
 		return 0, fmt.Errorf("count %s: %w", tableName, err)
 	}
 
 	query := fmt.Sprintf(`
 		BEGIN
-			:1 := pkg_crud.do_count(:2, :3, %s);
+			%s := pkg_crud.do_count(%s, %s, %s);
 		END;
-	`, filtersSQL)
+	`, result, table, tenant, filtersSQL)
 
-	var count int64
-	_, err = r.db.ExecContext(ctx, query,
-		sql.Out{Dest: &count},
-		tableName,
-		tenantID,
-	)
+	_, err = r.db.ExecContext(ctx, query, binds.args...)
 	if err != nil {
 		return 0, fmt.Errorf("count %s: %w", tableName, err)
 	}
+	if count < 0 {
+		return 0, fmt.Errorf("count %s: do_count failed (see crud_error_log)", tableName)
+	}
 
 	return count, nil
 }
+
+// Exists reports whether any row matches filters, using Count.
+func (r *GenericRepository) Exists(
+	ctx context.Context,
+	tableName string,
+	tenantID string,
+	filters []FilterCondition,
+) (bool, error) {
+	count, err := r.Count(ctx, tableName, tenantID, filters)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,108 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"testing"
+	"time"
+)
+
+// openIntegrationDB connects to the Oracle database named by
+// GPRINT_TEST_ORACLE_DSN, a godror connection string for a schema with the
+// migrations applied. Run with: go test -tags integration ./internal/repository
+func openIntegrationDB(t *testing.T) *DB {
+	t.Helper()
+	dsn := os.Getenv("GPRINT_TEST_ORACLE_DSN")
+	if dsn == "" {
+		t.Skip("GPRINT_TEST_ORACLE_DSN is not set")
+	}
+	sqlDB, err := sql.Open("godror", dsn)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := sqlDB.Ping(); err != nil {
+		t.Fatalf("ping: %v", err)
+	}
+	return NewDB(sqlDB, DBConfig{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestIntegrationQueryAndCount(t *testing.T) {
+	db := openIntegrationDB(t)
+	repo := NewGenericRepository(db)
+	ctx := context.Background()
+
+	// A tenant of its own keeps the test's rows apart from everything else
+	tenant := fmt.Sprintf("it-%d", time.Now().UnixNano())
+	t.Cleanup(func() {
+		_, _ = db.ExecContext(context.Background(), "DELETE FROM customers WHERE tenant_id = :1", tenant)
+	})
+
+	names := []string{"Acme", "O'Brien & Sons", "Zeta 🖨"}
+	for i, name := range names {
+		_, err := repo.Insert(ctx, TableCustomers, tenant, []ColumnValue{
+			{Name: "CUSTOMER_CODE", Value: fmt.Sprintf("IT-%d", i)},
+			{Name: "NAME", Value: name},
+		}, "integration-test")
+		if err != nil {
+			t.Fatalf("Insert %q: %v", name, err)
+		}
+	}
+
+	filters := []FilterCondition{{Column: "CUSTOMER_CODE", Operator: "IN", Value: []string{"IT-0", "IT-1"}}}
+	rows, err := repo.Query(ctx, TableCustomers, tenant, QueryOptions{
+		Columns: []string{"NAME"},
+		Filters: filters,
+		Sort:    []SortSpec{{Column: "NAME", Direction: "DESC"}},
+	})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	defer rows.Close()
+	var got []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			t.Fatalf("Scan: %v", err)
+		}
+		got = append(got, name)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("rows: %v", err)
+	}
+	if want := []string{"O'Brien & Sons", "Acme"}; !slices.Equal(got, want) {
+		t.Errorf("Query = %q, want %q", got, want)
+	}
+
+	count, err := repo.Count(ctx, TableCustomers, tenant, filters)
+	if err != nil || count != 2 {
+		t.Errorf("Count = %d, %v; want 2", count, err)
+	}
+}
+
+func TestIntegrationQueryInTransaction(t *testing.T) {
+	db := openIntegrationDB(t)
+	ctx := context.Background()
+
+	err := RunInTx(ctx, db, func(repos *TxRepositories) error {
+		rows, err := repos.Generic.Query(ctx, TableCustomers, "it-none", QueryOptions{Columns: []string{"ID"}})
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			t.Error("a tenant without customers returned rows")
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		t.Fatalf("Query in a transaction: %v", err)
+	}
+}
@@ -0,0 +1,315 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/godror/godror"
+)
+
+const testTable = "GENERIC_TEST"
+
+// placeholderPattern matches the positional binds of a generated block
+var placeholderPattern = regexp.MustCompile(`:[0-9]+`)
+
+func init() {
+	RegisterTable(testTable, TableOptions{})
+}
+
+// recordingExecer is an Execer that records the statements it is given and
+// reports success through the stored procedure's output binds
+type recordingExecer struct {
+	query string
+	args  []any
+}
+
+func (e *recordingExecer) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	e.query, e.args = query, args
+	for _, arg := range args {
+		if out, ok := arg.(sql.Out); ok {
+			if success, ok := out.Dest.(*int); ok {
+				*success = 1
+			}
+		}
+	}
+	return driverResult(1), nil
+}
+
+func (e *recordingExecer) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	panic("recordingExecer: QueryContext is not supported")
+}
+
+func (e *recordingExecer) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	panic("recordingExecer: QueryRowContext is not supported")
+}
+
+type driverResult int64
+
+func (r driverResult) LastInsertId() (int64, error) { return 0, nil }
+func (r driverResult) RowsAffected() (int64, error) { return int64(r), nil }
+
+func TestInsertBindsValuesVerbatim(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		clob  bool
+	}{
+		{"quotes", `O'Brien said "'); DROP TABLE contracts; --"`, false},
+		{"backslashes", `C:\print\out\`, false},
+		{"newlines", "line one\nline two\r\n\ttabbed", false},
+		{"NUL byte", "before\x00after", false},
+		{"emoji", "printed 🖨️ ✅ — ok", false},
+		{"4000 bytes", strings.Repeat("a", maxVarchar2Bytes), false},
+		{"4001 bytes", strings.Repeat("a", maxVarchar2Bytes+1), true},
+		// 1500 characters, but 6000 bytes of UTF-8
+		{"1500 emoji", strings.Repeat("🖨", 1500), true},
+		{"long text with quotes and newlines", strings.Repeat("it's\n", 1000), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &recordingExecer{}
+			repo := newGenericRepository(db)
+			if _, err := repo.Insert(context.Background(), testTable, "tenant-1", []ColumnValue{{Name: "NOTES", Value: tt.value}}, "alice"); err != nil {
+				t.Fatalf("Insert: %v", err)
+			}
+
+			// The value is bound, never part of the statement
+			if strings.Contains(db.query, tt.value) {
+				t.Error("the value was inlined into the PL/SQL block")
+			}
+			if n := len(placeholderPattern.FindAllString(db.query, -1)); n != len(db.args) {
+				t.Errorf("the block has %d placeholders for %d binds", n, len(db.args))
+			}
+
+			// t_column_value(name, col_value, value_type, col_clob)
+			if db.args[0] != "NOTES" || db.args[2] != "STRING" {
+				t.Errorf("column binds = %v, %v; want NOTES, STRING", db.args[0], db.args[2])
+			}
+			value, clob := db.args[1], db.args[3]
+			if !tt.clob {
+				if value != tt.value || clob != nil {
+					t.Errorf("col_value = %q, col_clob = %v; want the value as VARCHAR2", value, clob)
+				}
+				return
+			}
+			if value != nil {
+				t.Errorf("col_value = %q, want NULL for a CLOB value", value)
+			}
+			lob, ok := clob.(godror.Lob)
+			if !ok || !lob.IsClob {
+				t.Fatalf("col_clob = %T, want a CLOB", clob)
+			}
+			got, err := io.ReadAll(lob)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.value {
+				t.Errorf("CLOB holds %d bytes, want the %d bytes given", len(got), len(tt.value))
+			}
+		})
+	}
+}
+
+func TestDeleteFollowsRegistration(t *testing.T) {
+	RegisterTable("GENERIC_SOFT_TEST", TableOptions{SoftDelete: true})
+	tests := []struct {
+		table string
+		soft  int
+	}{
+		{testTable, 0},
+		{"generic_soft_test", 1},
+	}
+	for _, tt := range tests {
+		db := &recordingExecer{}
+		if _, err := newGenericRepository(db).Delete(context.Background(), tt.table, "tenant-1", 42, "alice"); err != nil {
+			t.Fatalf("Delete %s: %v", tt.table, err)
+		}
+		// sp_generic_delete(table, tenant, id, soft, deleted_by, ...)
+		if db.args[3] != tt.soft {
+			t.Errorf("Delete %s passed soft delete %v, want %d", tt.table, db.args[3], tt.soft)
+		}
+	}
+
+	db := &recordingExecer{}
+	if _, err := newGenericRepository(db).Delete(context.Background(), "UNREGISTERED", "tenant-1", 42, "alice"); err == nil || db.query != "" {
+		t.Errorf("Delete of an unregistered table = %v, ran %q", err, db.query)
+	}
+}
+
+func TestFilterValueLimit(t *testing.T) {
+	filters := []FilterCondition{{Column: "NOTES", Operator: "=", Value: strings.Repeat("🖨", 1001)}}
+	if _, err := buildFilterConditionsSQL(filters, &plsqlBinds{}); err == nil {
+		t.Error("a filter value over 4000 bytes was accepted")
+	}
+}
+
+// fakeCustomers is the result set the fake do_query cursor returns
+func fakeCustomers() *fakeDB {
+	return &fakeDB{
+		columns: []string{"ID", "NAME"},
+		rows: [][]driver.Value{
+			{int64(1), "Acme"},
+			{int64(2), "O'Brien & Sons"},
+		},
+	}
+}
+
+// readNames iterates rows and returns the NAME column
+func readNames(t *testing.T, rows *sql.Rows) []string {
+	t.Helper()
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		var id int64
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			t.Fatalf("Scan: %v", err)
+		}
+		names = append(names, name)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("rows: %v", err)
+	}
+	return names
+}
+
+func TestQueryIteratesCursor(t *testing.T) {
+	fake := fakeCustomers()
+	repo := NewGenericRepository(fake.open(t))
+
+	rows, err := repo.Query(context.Background(), testTable, "tenant-1", QueryOptions{
+		Columns: []string{"ID", "NAME"},
+		Filters: []FilterCondition{{Column: "NAME", Operator: "LIKE", Value: "%o%"}},
+		Sort:    []SortSpec{{Column: "NAME", Direction: "asc"}},
+		Limit:   10,
+	})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if got := readNames(t, rows); !slices.Equal(got, []string{"Acme", "O'Brien & Sons"}) {
+		t.Errorf("rows = %v, want both customers", got)
+	}
+	if !fake.cursors[0].closed {
+		t.Error("the cursor was not closed with the rows")
+	}
+	if !strings.Contains(fake.execs[0], "pkg_crud.do_query(") {
+		t.Errorf("executed %q, want a do_query block", fake.execs[0])
+	}
+}
+
+func TestQueryInTransactionIteratesCursor(t *testing.T) {
+	fake := fakeCustomers()
+	db := fake.open(t)
+	tx, err := db.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	rows, err := NewGenericRepository(db).WithTx(tx).Query(context.Background(), testTable, "tenant-1", QueryOptions{})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if got := readNames(t, rows); len(got) != 2 {
+		t.Errorf("rows = %v, want 2", got)
+	}
+}
+
+func TestQueryWithoutCursor(t *testing.T) {
+	fake := fakeCustomers()
+	fake.noCursor = true
+	if _, err := NewGenericRepository(fake.open(t)).Query(context.Background(), testTable, "tenant-1", QueryOptions{}); err == nil || !strings.Contains(err.Error(), "no cursor") {
+		t.Errorf("Query error = %v, want no cursor returned", err)
+	}
+}
+
+func TestCount(t *testing.T) {
+	fake := &fakeDB{count: 42}
+	repo := NewGenericRepository(fake.open(t))
+	filters := []FilterCondition{{Column: "STATUS", Operator: "IN", Value: []string{"ACTIVE", "DRAFT"}}}
+
+	count, err := repo.Count(context.Background(), testTable, "tenant-1", filters)
+	if err != nil || count != 42 {
+		t.Fatalf("Count = %d, %v; want 42", count, err)
+	}
+	if !strings.Contains(fake.execs[0], "pkg_crud.do_count(") {
+		t.Errorf("executed %q, want a do_count block", fake.execs[0])
+	}
+
+	// do_count reports failures as -1
+	fake.count = -1
+	if _, err := repo.Count(context.Background(), testTable, "tenant-1", filters); err == nil {
+		t.Error("Count accepted -1 from do_count")
+	}
+}
+
+func TestFilterConditionsBindLists(t *testing.T) {
+	at := time.Date(2024, 2, 29, 13, 45, 0, 123000000, time.UTC)
+	tests := []struct {
+		name   string
+		filter FilterCondition
+		sql    string
+		args   []any
+	}{
+		{
+			"IN list",
+			FilterCondition{Column: "STATUS", Operator: "in", Value: []string{"ACTIVE", "DRAFT"}},
+			"t_filter_conditions(t_filter_condition(:1, :2, :3, :4, t_filter_values(:5, :6)))",
+			[]any{"STATUS", "IN", nil, "STRING", "ACTIVE", "DRAFT"},
+		},
+		{
+			"empty IN list",
+			FilterCondition{Column: "ID", Operator: "IN", Value: []int64{}},
+			"t_filter_conditions(t_filter_condition(:1, :2, :3, :4, t_filter_values()))",
+			[]any{"ID", "IN", nil, "STRING"},
+		},
+		{
+			"timestamp",
+			FilterCondition{Column: "CREATED_AT", Operator: ">=", Value: at},
+			"t_filter_conditions(t_filter_condition(:1, :2, :3, :4, NULL))",
+			[]any{"CREATED_AT", ">=", "2024-02-29T13:45:00.123000000", "TIMESTAMP"},
+		},
+		{
+			"date",
+			FilterCondition{Column: "START_DATE", Operator: "<", Value: at, Type: "DATE"},
+			"t_filter_conditions(t_filter_condition(:1, :2, :3, :4, NULL))",
+			[]any{"START_DATE", "<", "2024-02-29T13:45:00", "DATE"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			binds := &plsqlBinds{}
+			got, err := buildFilterConditionsSQL([]FilterCondition{tt.filter}, binds)
+			if err != nil {
+				t.Fatalf("buildFilterConditionsSQL: %v", err)
+			}
+			if got != tt.sql {
+				t.Errorf("SQL = %s, want %s", got, tt.sql)
+			}
+			if !slices.Equal(binds.args, tt.args) {
+				t.Errorf("binds = %q, want %q", binds.args, tt.args)
+			}
+		})
+	}
+}
+
+func TestFilterConditionsRejectBadLists(t *testing.T) {
+	for name, f := range map[string]FilterCondition{
+		"IN with a scalar":       {Column: "STATUS", Operator: "IN", Value: "ACTIVE"},
+		"IN with too many":       {Column: "ID", Operator: "IN", Value: make([]int, maxInValues+1)},
+		"time without date type": {Column: "NAME", Operator: "=", Value: time.Now(), Type: "STRING"},
+	} {
+		if _, err := buildFilterConditionsSQL([]FilterCondition{f}, &plsqlBinds{}); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+}
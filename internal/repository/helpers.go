@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/zlovtnik/gprint/internal/models"
 )
 
 // ═══════════════════════════════════════════════════════════════════════════
@@ -23,6 +24,15 @@ func NullableString(s string) sql.NullString {
 	return sql.NullString{String: s, Valid: true}
 }
 
+// NullStringFromPtr returns a sql.NullString for a *string value.
+// A nil pointer results in a NULL database value; an empty string is kept.
+func NullStringFromPtr(s *string) sql.NullString {
+	if s == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: *s, Valid: true}
+}
+
 // NullableUUID returns a sql.NullString for a UUID value formatted as string.
 func NullableUUID[T ~[16]byte](id *T) sql.NullString {
 	if id == nil {
@@ -60,6 +70,15 @@ func StringFromNull(ns sql.NullString) string {
 	return ""
 }
 
+// StringPtrFromNull extracts the *string value from a sql.NullString.
+// Returns nil if null.
+func StringPtrFromNull(ns sql.NullString) *string {
+	if ns.Valid {
+		return &ns.String
+	}
+	return nil
+}
+
 // TimeFromNull extracts the *time.Time value from a sql.NullTime.
 // Returns nil if null.
 func TimeFromNull(nt sql.NullTime) *time.Time {
@@ -218,6 +237,11 @@ func (b *QueryBuilder) AddCondition(conditionFmt string, value interface{}) {
 	b.nextIdx++
 }
 
+// AddRawCondition adds a condition without a placeholder, e.g. "col IS NULL".
+func (b *QueryBuilder) AddRawCondition(condition string) {
+	b.conditions = append(b.conditions, condition)
+}
+
 // AddConditionMultiple adds a condition with multiple same-value placeholders.
 // Useful for LIKE queries that need the same value in multiple positions.
 func (b *QueryBuilder) AddConditionMultiple(conditionFmt string, value interface{}, count int) {
@@ -250,6 +274,47 @@ func (b *QueryBuilder) NextIndex() int {
 	return b.nextIdx
 }
 
+// likeEscaper escapes LIKE wildcards for patterns used with ESCAPE '\'
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// LikeContains returns a LIKE pattern matching values containing s
+// literally; the condition must declare ESCAPE '\'.
+func LikeContains(s string) string {
+	return "%" + likeEscaper.Replace(s) + "%"
+}
+
+// LikePrefix returns a LIKE pattern matching values starting with s
+// literally; the condition must declare ESCAPE '\'.
+func LikePrefix(s string) string {
+	return likeEscaper.Replace(s) + "%"
+}
+
+// ═══════════════════════════════════════════════════════════════════════════
+// KEYSET PAGINATION - Newest-first pages that follow a (time, id) cursor
+// ═══════════════════════════════════════════════════════════════════════════
+
+// AddKeyset restricts a listing ordered by timeColumn DESC, idColumn DESC to
+// the rows after cursor. Oracle has no row value comparison, so
+// (time, id) < (:a, :b) is spelled out.
+func (b *QueryBuilder) AddKeyset(timeColumn, idColumn string, cursor models.ListCursor) {
+	i := b.nextIdx
+	b.conditions = append(b.conditions, fmt.Sprintf("(%s < :%d OR (%s = :%d AND %s < :%d))",
+		timeColumn, i, timeColumn, i+1, idColumn, i+2))
+	b.args = append(b.args, cursor.At, cursor.At, cursor.ID)
+	b.nextIdx += 3
+}
+
+// keysetPage trims rows fetched with a limit of pageSize+1 to pageSize and
+// returns the cursor of the last row kept when more rows follow
+func keysetPage[T any](rows []T, pageSize int, cursor func(T) models.ListCursor) ([]T, *models.ListCursor) {
+	if len(rows) <= pageSize {
+		return rows, nil
+	}
+	rows = rows[:pageSize]
+	next := cursor(rows[len(rows)-1])
+	return rows, &next
+}
+
 // ═══════════════════════════════════════════════════════════════════════════
 // CHUNK PROCESSOR - Process slices in chunks to avoid DB limits
 // ═══════════════════════════════════════════════════════════════════════════
@@ -8,13 +8,20 @@ import (
 	"github.com/zlovtnik/gprint/internal/models"
 )
 
+// TableContractHistory is the table name for contract history.
+const TableContractHistory = "CONTRACT_HISTORY"
+
+func init() {
+	RegisterTable(TableContractHistory, TableOptions{})
+}
+
 // HistoryRepository handles contract history data access
 type HistoryRepository struct {
-	db *sql.DB
+	db *DB
 }
 
 // NewHistoryRepository creates a new HistoryRepository
-func NewHistoryRepository(db *sql.DB) *HistoryRepository {
+func NewHistoryRepository(db *DB) *HistoryRepository {
 	return &HistoryRepository{db: db}
 }
 
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/zlovtnik/gprint/internal/models"
+)
+
+// TableInvoices is the invoices table name
+const TableInvoices = "INVOICES"
+
+// invoiceSelectColumns is the column list read by scanInvoice, in scan order
+const invoiceSelectColumns = `id, tenant_id, invoice_number, contract_id, period_number,
+			period_start, period_end, due_date, amount, status,
+			issued_at, issued_by, paid_at, paid_by, created_at, updated_at`
+
+// InvoiceRepository handles invoice data access
+type InvoiceRepository struct {
+	db *DB
+}
+
+// NewInvoiceRepository creates a new InvoiceRepository
+func NewInvoiceRepository(db *DB) *InvoiceRepository {
+	if db == nil {
+		panic("InvoiceRepository: db is nil")
+	}
+	return &InvoiceRepository{db: db}
+}
+
+// CreateDraft inserts a DRAFT invoice unless the contract already has one
+// for the period, reporting whether it was created. A concurrent insert of
+// the same period counts as already created.
+func (r *InvoiceRepository) CreateDraft(ctx context.Context, inv *models.Invoice) (bool, error) {
+	query := `MERGE INTO ` + TableInvoices + ` t
+		USING (SELECT :1 AS tenant_id, :2 AS contract_id, :3 AS period_start FROM dual) s
+		ON (t.tenant_id = s.tenant_id AND t.contract_id = s.contract_id AND t.period_start = s.period_start)
+		WHEN NOT MATCHED THEN INSERT
+			(tenant_id, contract_id, period_start, invoice_number, period_number, period_end, due_date, amount, status)
+			VALUES (s.tenant_id, s.contract_id, s.period_start, :4, :5, :6, :7, :8, :9)`
+	result, err := r.db.ExecContext(ctx, query,
+		inv.TenantID, inv.ContractID, inv.PeriodStart,
+		inv.InvoiceNumber, inv.PeriodNumber, inv.PeriodEnd, inv.DueDate,
+		inv.Amount.InexactFloat64(), string(models.InvoiceStatusDraft),
+	)
+	if err != nil {
+		if strings.Contains(err.Error(), "ORA-00001") {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to create invoice: %w", err)
+	}
+	return rowsInserted(result)
+}
+
+// GetByID retrieves an invoice by ID
+func (r *InvoiceRepository) GetByID(ctx context.Context, tenantID string, id int64) (*models.Invoice, error) {
+	query := `SELECT ` + invoiceSelectColumns + ` FROM ` + TableInvoices + ` WHERE tenant_id = :1 AND id = :2`
+	inv, err := scanInvoice(r.db.QueryRowContext(ctx, query, tenantID, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invoice: %w", err)
+	}
+	return &inv, nil
+}
+
+// ListByContract retrieves a page of a contract's invoices in billing order
+func (r *InvoiceRepository) ListByContract(ctx context.Context, tenantID string, contractID int64, params models.PaginationParams) ([]models.Invoice, int, error) {
+	qb := NewQueryBuilder(2)
+	qb.AddCondition("contract_id = :%d", contractID)
+	return listPage(ctx, r.db, tenantID, pageQuery{
+		noun:    "invoices",
+		table:   TableInvoices,
+		columns: invoiceSelectColumns,
+		filter:  qb,
+		orderBy: "period_start ASC",
+		offset:  params.Offset(),
+		limit:   params.Limit(),
+	}, scanInvoice)
+}
+
+// UpdateStatus moves an invoice from one status to the next, stamping who
+// issued or paid it. It reports false when the invoice is no longer in from.
+func (r *InvoiceRepository) UpdateStatus(ctx context.Context, tenantID string, id int64, from, to models.InvoiceStatus, updatedBy string) (bool, error) {
+	stamp := "issued_at = CURRENT_TIMESTAMP, issued_by = :2"
+	if to == models.InvoiceStatusPaid {
+		stamp = "paid_at = CURRENT_TIMESTAMP, paid_by = :2"
+	}
+	query := `UPDATE ` + TableInvoices + ` SET status = :1, ` + stamp + `, updated_at = CURRENT_TIMESTAMP
+		WHERE tenant_id = :3 AND id = :4 AND status = :5`
+	result, err := r.db.ExecContext(ctx, query, string(to), updatedBy, tenantID, id, string(from))
+	if err != nil {
+		return false, fmt.Errorf("failed to update invoice status: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf(errFmtRowsAffected, err)
+	}
+	return n > 0, nil
+}
+
+func scanInvoice(scanner rowScanner) (models.Invoice, error) {
+	var inv models.Invoice
+	var issuedAt, paidAt sql.NullTime
+	var issuedBy, paidBy sql.NullString
+	err := scanner.Scan(
+		&inv.ID, &inv.TenantID, &inv.InvoiceNumber, &inv.ContractID, &inv.PeriodNumber,
+		&inv.PeriodStart, &inv.PeriodEnd, &inv.DueDate, &inv.Amount, &inv.Status,
+		&issuedAt, &issuedBy, &paidAt, &paidBy, &inv.CreatedAt, &inv.UpdatedAt,
+	)
+	if err != nil {
+		return models.Invoice{}, err
+	}
+	inv.IssuedAt = TimeFromNull(issuedAt)
+	inv.IssuedBy = issuedBy.String
+	inv.PaidAt = TimeFromNull(paidAt)
+	inv.PaidBy = paidBy.String
+	return inv, nil
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// TableJobLocks is the table scheduled jobs are claimed in
+const TableJobLocks = "JOB_LOCKS"
+
+// JobLockRepository claims runs of scheduled jobs so that only one instance
+// performs each run
+type JobLockRepository struct {
+	db *DB
+}
+
+// NewJobLockRepository creates a new JobLockRepository
+func NewJobLockRepository(db *DB) *JobLockRepository {
+	return &JobLockRepository{db: db}
+}
+
+// Claim claims the run of job due at due for instanceID, holding it for
+// lease. It fails (false) when a run due at or after due was already
+// claimed, or another instance still holds an unexpired lease.
+func (r *JobLockRepository) Claim(ctx context.Context, job, instanceID string, due time.Time, lease time.Duration) (bool, error) {
+	query := `UPDATE ` + TableJobLocks + `
+		SET locked_by = :1, locked_until = CURRENT_TIMESTAMP + NUMTODSINTERVAL(:2, 'SECOND'),
+			last_due_at = :3, last_run_at = CURRENT_TIMESTAMP
+		WHERE job_name = :4
+		  AND (last_due_at IS NULL OR last_due_at < :5)
+		  AND (locked_until IS NULL OR locked_until < CURRENT_TIMESTAMP)`
+	result, err := r.db.ExecContext(ctx, query, instanceID, lease.Seconds(), due, job, due)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim job %s: %w", job, err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf(errFmtRowsAffected, err)
+	}
+	return rows == 1, nil
+}
+
+// Release gives up instanceID's lease on job once its run is over
+func (r *JobLockRepository) Release(ctx context.Context, job, instanceID string) error {
+	query := `UPDATE ` + TableJobLocks + `
+		SET locked_by = NULL, locked_until = NULL
+		WHERE job_name = :1 AND locked_by = :2`
+	if _, err := r.db.ExecContext(ctx, query, job, instanceID); err != nil {
+		return fmt.Errorf("failed to release job %s: %w", job, err)
+	}
+	return nil
+}
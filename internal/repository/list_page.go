@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/zlovtnik/gprint/internal/models"
+)
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// pageQuery describes one OFFSET/FETCH page of a tenant's rows
+type pageQuery struct {
+	noun    string        // Plural used in errors, e.g. "contracts"
+	table   string        // Table to select from
+	columns string        // Select list read by the scan function
+	filter  *QueryBuilder // Conditions after tenant_id = :1, so numbered from :2
+	orderBy string        // Validated "column DIRECTION"
+	offset  int
+	limit   int
+}
+
+// totalScanner reads the trailing COUNT(*) OVER () column of a page row
+// into total, passing the other columns to the wrapped destinations
+type totalScanner struct {
+	rows  rowScanner
+	total *int
+}
+
+func (s totalScanner) Scan(dest ...any) error {
+	return s.rows.Scan(append(dest, s.total)...)
+}
+
+// listPage fetches a page and the number of rows matching the filter in
+// one round trip, using COUNT(*) OVER () as an extra column. An empty page
+// past the first falls back to a COUNT query, since the window has no row
+// to report the total on.
+func listPage[T any](ctx context.Context, db *DB, tenantID string, q pageQuery, scan func(rowScanner) (T, error)) ([]T, int, error) {
+	where := `WHERE tenant_id = :1` + q.filter.WhereClause()
+	args := append([]any{tenantID}, q.filter.Args()...)
+	next := q.filter.NextIndex()
+
+	query := fmt.Sprintf(`SELECT %s, COUNT(*) OVER () FROM %s %s ORDER BY %s OFFSET :%d ROWS FETCH NEXT :%d ROWS ONLY`,
+		q.columns, q.table, where, q.orderBy, next, next+1)
+	rows, err := db.QueryContext(ctx, query, append(args, q.offset, q.limit)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list %s: %w", q.noun, err)
+	}
+	defer rows.Close()
+
+	var items []T
+	var total int
+	scanner := totalScanner{rows: rows, total: &total}
+	for rows.Next() {
+		item, err := scan(scanner)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan %s: %w", q.noun, err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate %s: %w", q.noun, err)
+	}
+
+	if len(items) == 0 && q.offset > 0 {
+		countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM %s %s`, q.table, where)
+		if err := db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+			return nil, 0, fmt.Errorf("failed to count %s: %w", q.noun, err)
+		}
+	}
+	return items, total, nil
+}
+
+// searchSummaries runs a query selecting id, label, secondary text and
+// COUNT(*) OVER (), returning the hits as summaries of type and the number
+// of matches
+func searchSummaries(ctx context.Context, db *DB, summaryType, query string, args ...any) ([]models.SearchSummary, int, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search %ss: %w", summaryType, err)
+	}
+	defer rows.Close()
+
+	summaries := []models.SearchSummary{}
+	var total int
+	for rows.Next() {
+		s := models.SearchSummary{Type: summaryType}
+		var secondary sql.NullString
+		if err := rows.Scan(&s.ID, &s.Label, &secondary, &total); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan %s search result: %w", summaryType, err)
+		}
+		s.Secondary = secondary.String
+		summaries = append(summaries, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate %s search results: %w", summaryType, err)
+	}
+	return summaries, total, nil
+}
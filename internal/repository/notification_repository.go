@@ -0,0 +1,233 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/zlovtnik/gprint/internal/models"
+)
+
+// Table names for notification data
+const (
+	TableNotificationPreferences = "NOTIFICATION_PREFERENCES"
+	TableNotifications           = "NOTIFICATIONS"
+)
+
+// notificationSelectColumns is the column list read by scanNotification, in scan order
+const notificationSelectColumns = `id, tenant_id, user_id, type, title, body, entity_type, entity_id, created_at, read_at`
+
+// Widths of notifications.title and notifications.body
+const (
+	maxNotificationTitleLength = 200
+	maxNotificationBodyLength  = 2000
+)
+
+// NotificationRepository handles notification preference and in-app
+// notification data access
+type NotificationRepository struct {
+	db *DB
+}
+
+// NewNotificationRepository creates a new NotificationRepository
+func NewNotificationRepository(db *DB) (*NotificationRepository, error) {
+	if db == nil {
+		return nil, fmt.Errorf("NewNotificationRepository: db is nil")
+	}
+	return &NotificationRepository{db: db}, nil
+}
+
+// GetPreference retrieves a user's preference, returning nil when the user has not opted in
+func (r *NotificationRepository) GetPreference(ctx context.Context, tenantID, userID string) (*models.NotificationPreference, error) {
+	query := `SELECT tenant_id, user_id, email, print_job_completed, print_job_failed, updated_at
+		FROM ` + TableNotificationPreferences + `
+		WHERE tenant_id = :1 AND user_id = :2`
+
+	var pref models.NotificationPreference
+	var completed, failed int
+	err := r.db.QueryRowContext(ctx, query, tenantID, userID).Scan(
+		&pref.TenantID, &pref.UserID, &pref.Email, &completed, &failed, &pref.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification preference: %w", err)
+	}
+
+	pref.PrintJobCompleted = IntToBool(completed)
+	pref.PrintJobFailed = IntToBool(failed)
+	return &pref, nil
+}
+
+// UpsertPreference creates or replaces a user's preference
+func (r *NotificationRepository) UpsertPreference(ctx context.Context, tenantID, userID string, req *models.UpdateNotificationPreferenceRequest) (*models.NotificationPreference, error) {
+	query := `MERGE INTO ` + TableNotificationPreferences + ` p
+		USING (SELECT :1 AS tenant_id, :2 AS user_id FROM dual) s
+		ON (p.tenant_id = s.tenant_id AND p.user_id = s.user_id)
+		WHEN MATCHED THEN UPDATE SET
+			email = :3, print_job_completed = :4, print_job_failed = :5, updated_at = CURRENT_TIMESTAMP
+		WHEN NOT MATCHED THEN INSERT (tenant_id, user_id, email, print_job_completed, print_job_failed)
+			VALUES (s.tenant_id, s.user_id, :6, :7, :8)`
+	completed, failed := BoolToInt(req.PrintJobCompleted), BoolToInt(req.PrintJobFailed)
+	if _, err := r.db.ExecContext(ctx, query,
+		tenantID, userID, req.Email, completed, failed, req.Email, completed, failed,
+	); err != nil {
+		return nil, fmt.Errorf("failed to save notification preference: %w", err)
+	}
+	return r.GetPreference(ctx, tenantID, userID)
+}
+
+// DeletePreference opts a user out of notification emails
+func (r *NotificationRepository) DeletePreference(ctx context.Context, tenantID, userID string) error {
+	_, err := r.db.ExecContext(ctx,
+		`DELETE FROM `+TableNotificationPreferences+` WHERE tenant_id = :1 AND user_id = :2`, tenantID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete notification preference: %w", err)
+	}
+	return nil
+}
+
+// CreateNotification adds a notification to a user's list, cutting the title
+// and body to their column widths
+func (r *NotificationRepository) CreateNotification(ctx context.Context, n *models.Notification) (*models.Notification, error) {
+	if n == nil {
+		return nil, fmt.Errorf("notification cannot be nil")
+	}
+	title, body := n.Title, n.Body
+	if len(title) > maxNotificationTitleLength {
+		title = title[:maxNotificationTitleLength]
+	}
+	if len(body) > maxNotificationBodyLength {
+		body = body[:maxNotificationBodyLength]
+	}
+
+	var id int64
+	query := `INSERT INTO ` + TableNotifications + ` (tenant_id, user_id, type, title, body, entity_type, entity_id)
+		VALUES (:1, :2, :3, :4, :5, :6, :7)
+		RETURNING id INTO :8`
+	_, err := r.db.ExecContext(ctx, query,
+		n.TenantID, n.UserID, string(n.Type), title, NullableString(body), NullableString(n.EntityType), NullableInt64(n.EntityID),
+		sql.Out{Dest: &id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create notification: %w", err)
+	}
+	return r.GetNotification(ctx, n.TenantID, n.UserID, id)
+}
+
+// GetNotification retrieves one of a user's notifications, returning
+// ErrNotFound when the user has no notification with that ID
+func (r *NotificationRepository) GetNotification(ctx context.Context, tenantID, userID string, id int64) (*models.Notification, error) {
+	query := `SELECT ` + notificationSelectColumns + `
+		FROM ` + TableNotifications + `
+		WHERE tenant_id = :1 AND user_id = :2 AND id = :3`
+
+	n, err := scanNotification(r.db.QueryRowContext(ctx, query, tenantID, userID, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification: %w", err)
+	}
+	return &n, nil
+}
+
+// ListNotifications retrieves a page of a user's notifications, unread first
+// and newest first within each group, and the number matching
+func (r *NotificationRepository) ListNotifications(ctx context.Context, tenantID, userID string, unreadOnly bool, offset, limit int) ([]models.Notification, int, error) {
+	qb := NewQueryBuilder(2)
+	qb.AddCondition("user_id = :%d", userID)
+	if unreadOnly {
+		qb.AddRawCondition("read_at IS NULL")
+	}
+	items, total, err := listPage(ctx, r.db, tenantID, pageQuery{
+		noun:    "notifications",
+		table:   TableNotifications,
+		columns: notificationSelectColumns,
+		filter:  qb,
+		orderBy: "CASE WHEN read_at IS NULL THEN 0 ELSE 1 END, created_at DESC, id DESC",
+		offset:  offset,
+		limit:   limit,
+	}, scanNotification)
+	if err != nil {
+		return nil, 0, err
+	}
+	if items == nil {
+		items = []models.Notification{}
+	}
+	return items, total, nil
+}
+
+// CountUnread returns the number of a user's unread notifications
+func (r *NotificationRepository) CountUnread(ctx context.Context, tenantID, userID string) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM `+TableNotifications+` WHERE tenant_id = :1 AND user_id = :2 AND read_at IS NULL`,
+		tenantID, userID,
+	).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+	return count, nil
+}
+
+// MarkRead marks one of a user's notifications read, keeping the first read
+// time when it already was. It returns ErrNotFound when the user has no
+// notification with that ID.
+func (r *NotificationRepository) MarkRead(ctx context.Context, tenantID, userID string, id int64) error {
+	query := `UPDATE ` + TableNotifications + `
+		SET read_at = COALESCE(read_at, CURRENT_TIMESTAMP)
+		WHERE tenant_id = :1 AND user_id = :2 AND id = :3`
+	result, err := r.db.ExecContext(ctx, query, tenantID, userID, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark notification read: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf(errFmtRowsAffected, err)
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// MarkAllRead marks all of a user's unread notifications read and returns
+// how many there were
+func (r *NotificationRepository) MarkAllRead(ctx context.Context, tenantID, userID string) (int, error) {
+	query := `UPDATE ` + TableNotifications + `
+		SET read_at = CURRENT_TIMESTAMP
+		WHERE tenant_id = :1 AND user_id = :2 AND read_at IS NULL`
+	result, err := r.db.ExecContext(ctx, query, tenantID, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark notifications read: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf(errFmtRowsAffected, err)
+	}
+	return int(rows), nil
+}
+
+// scanNotification reads a row selected with notificationSelectColumns
+func scanNotification(row rowScanner) (models.Notification, error) {
+	var n models.Notification
+	var eventType string
+	var body, entityType sql.NullString
+	var entityID sql.NullInt64
+	var readAt sql.NullTime
+	if err := row.Scan(&n.ID, &n.TenantID, &n.UserID, &eventType, &n.Title, &body,
+		&entityType, &entityID, &n.CreatedAt, &readAt); err != nil {
+		return n, err
+	}
+	n.Type = models.EventType(eventType)
+	n.Body = body.String
+	n.EntityType = entityType.String
+	if entityID.Valid {
+		n.EntityID = &entityID.Int64
+	}
+	if readAt.Valid {
+		n.ReadAt = &readAt.Time
+	}
+	return n, nil
+}
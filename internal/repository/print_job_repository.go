@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/zlovtnik/gprint/internal/models"
 )
@@ -11,9 +12,22 @@ import (
 // TablePrintJobs is the table name for print job operations
 const TablePrintJobs = "CONTRACT_PRINT_JOBS"
 
+func init() {
+	RegisterTable(TablePrintJobs, TableOptions{})
+}
+
+// printJobSelectColumns is the column list read by scanPrintJob, in scan order
+const printJobSelectColumns = `id, tenant_id, contract_id, status, format, priority, watermark,
+			output_path, storage_backend, progress_pct, file_size, page_count,
+			queued_at, started_at, completed_at,
+			retry_count, next_retry_at, error_message, requested_by`
+
+// printJobPriorityOrder sorts HIGH before NORMAL before LOW
+const printJobPriorityOrder = `CASE priority WHEN 'HIGH' THEN 0 WHEN 'NORMAL' THEN 1 ELSE 2 END`
+
 // PrintJobRepository handles print job data access
 type PrintJobRepository struct {
-	db      *sql.DB
+	db      *DB
 	generic *GenericRepository
 }
 
@@ -29,7 +43,7 @@ type UpdateStatusParams struct {
 }
 
 // NewPrintJobRepository creates a new PrintJobRepository
-func NewPrintJobRepository(db *sql.DB) *PrintJobRepository {
+func NewPrintJobRepository(db *DB) *PrintJobRepository {
 	return &PrintJobRepository{
 		db:      db,
 		generic: NewGenericRepository(db),
@@ -45,10 +59,17 @@ func (r *PrintJobRepository) Create(ctx context.Context, tenantID string, req *m
 	if format == "" {
 		format = models.PrintFormatPDF
 	}
+	priority := req.Priority
+	if priority == "" {
+		priority = models.PrintPriorityNormal
+	}
+	watermark := req.Watermark == nil || *req.Watermark
 
 	columns := []ColumnValue{
 		{Name: "CONTRACT_ID", Value: req.ContractID, Type: "NUMBER"},
 		{Name: "FORMAT", Value: string(format), Type: "STRING"},
+		{Name: "PRIORITY", Value: string(priority), Type: "STRING"},
+		{Name: "WATERMARK", Value: BoolToInt(watermark), Type: "NUMBER"},
 		{Name: "REQUESTED_BY", Value: requestedBy, Type: "STRING"},
 		{Name: "STATUS", Value: string(models.PrintJobStatusQueued), Type: "STRING"},
 	}
@@ -73,10 +94,7 @@ func (r *PrintJobRepository) Create(ctx context.Context, tenantID string, req *m
 // FUTURE: Migrate to sp_get_print_job if/when ref cursor handling is needed.
 func (r *PrintJobRepository) GetByID(ctx context.Context, tenantID string, id int64) (*models.ContractPrintJob, error) {
 	query := `
-		SELECT id, tenant_id, contract_id, status, format,
-			output_path, file_size, page_count,
-			queued_at, started_at, completed_at,
-			retry_count, error_message, requested_by
+		SELECT ` + printJobSelectColumns + `
 		FROM ` + TablePrintJobs + `
 		WHERE tenant_id = :1 AND id = :2`
 
@@ -96,10 +114,7 @@ func (r *PrintJobRepository) GetByID(ctx context.Context, tenantID string, id in
 // Stored procedure sp_get_print_jobs_by_contract available for ref cursor usage
 func (r *PrintJobRepository) GetByContractID(ctx context.Context, tenantID string, contractID int64) ([]models.ContractPrintJob, error) {
 	query := `
-		SELECT id, tenant_id, contract_id, status, format,
-			output_path, file_size, page_count,
-			queued_at, started_at, completed_at,
-			retry_count, error_message, requested_by
+		SELECT ` + printJobSelectColumns + `
 		FROM ` + TablePrintJobs + `
 		WHERE tenant_id = :1 AND contract_id = :2
 		ORDER BY queued_at DESC`
@@ -126,32 +141,77 @@ func (r *PrintJobRepository) GetByContractID(ctx context.Context, tenantID strin
 	return jobs, nil
 }
 
-// FindAll retrieves all print jobs for a tenant with pagination
-func (r *PrintJobRepository) FindAll(ctx context.Context, tenantID string, offset, limit int) ([]models.ContractPrintJob, int64, error) {
-	// Get total count
-	countQuery := `SELECT COUNT(*) FROM ` + TablePrintJobs + ` WHERE tenant_id = :1`
-	var total int64
-	err := r.db.QueryRowContext(ctx, countQuery, tenantID).Scan(&total)
+// FindAll retrieves print jobs for a tenant matching filter, with pagination.
+// The returned total counts all rows matching the filter and comes back in
+// the same round trip as the page.
+// Stored procedure sp_list_print_jobs available for ref cursor usage
+func (r *PrintJobRepository) FindAll(ctx context.Context, tenantID string, filter models.PrintJobFilter, offset, limit int) ([]models.ContractPrintJob, int64, error) {
+	jobs, total, err := listPage(ctx, r.db, tenantID, pageQuery{
+		noun:    "print jobs",
+		table:   TablePrintJobs,
+		columns: printJobSelectColumns,
+		filter:  printJobFilterConditions(filter),
+		orderBy: "queued_at DESC",
+		offset:  offset,
+		limit:   limit,
+	}, scanPrintJob)
+	return jobs, int64(total), err
+}
+
+// FindKeyset retrieves a page of print jobs matching filter, newest first,
+// following params.After. Nothing is counted; the returned cursor is nil on
+// the last page.
+func (r *PrintJobRepository) FindKeyset(ctx context.Context, tenantID string, filter models.PrintJobFilter, params models.KeysetParams) ([]models.ContractPrintJob, *models.ListCursor, error) {
+	qb := printJobFilterConditions(filter)
+	if params.After != nil {
+		qb.AddKeyset("queued_at", "id", *params.After)
+	}
+	query := fmt.Sprintf(`
+		SELECT `+printJobSelectColumns+`
+		FROM `+TablePrintJobs+`
+		WHERE tenant_id = :1%s
+		ORDER BY queued_at DESC, id DESC
+		FETCH FIRST :%d ROWS ONLY
+	`, qb.WhereClause(), qb.NextIndex())
+
+	args := append([]any{tenantID}, qb.Args()...)
+	jobs, err := r.queryPrintJobs(ctx, query, append(args, params.PageSize+1)...)
 	if err != nil {
-		return nil, 0, fmt.Errorf("error counting print jobs: %w", err)
+		return nil, nil, err
 	}
+	jobs, cursor := keysetPage(jobs, params.PageSize, func(j models.ContractPrintJob) models.ListCursor {
+		return models.ListCursor{At: j.QueuedAt, ID: j.ID}
+	})
+	return jobs, cursor, nil
+}
 
-	// Get paginated results
-	// Stored procedure sp_list_print_jobs available for ref cursor usage
-	query := `
-		SELECT id, tenant_id, contract_id, status, format,
-			output_path, file_size, page_count,
-			queued_at, started_at, completed_at,
-			retry_count, error_message, requested_by
-		FROM ` + TablePrintJobs + `
-		WHERE tenant_id = :1
-		ORDER BY queued_at DESC
-		OFFSET :2 ROWS FETCH NEXT :3 ROWS ONLY
-	`
+// printJobFilterConditions builds the conditions for filter, numbering
+// binds from :2 after the tenant
+func printJobFilterConditions(filter models.PrintJobFilter) *QueryBuilder {
+	qb := NewQueryBuilder(2)
+	if filter.Status != "" {
+		qb.AddCondition("status = :%d", string(filter.Status))
+	}
+	if filter.ContractID > 0 {
+		qb.AddCondition("contract_id = :%d", filter.ContractID)
+	}
+	if filter.RequestedBy != "" {
+		qb.AddCondition("requested_by = :%d", filter.RequestedBy)
+	}
+	if filter.QueuedFrom != nil {
+		qb.AddCondition("queued_at >= :%d", *filter.QueuedFrom)
+	}
+	if filter.QueuedTo != nil {
+		qb.AddCondition("queued_at < :%d", *filter.QueuedTo)
+	}
+	return qb
+}
 
-	rows, err := r.db.QueryContext(ctx, query, tenantID, offset, limit)
+// queryPrintJobs runs a query selecting printJobSelectColumns
+func (r *PrintJobRepository) queryPrintJobs(ctx context.Context, query string, args ...any) ([]models.ContractPrintJob, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, 0, fmt.Errorf("error querying print jobs: %w", err)
+		return nil, fmt.Errorf("error querying print jobs: %w", err)
 	}
 	defer rows.Close()
 
@@ -159,16 +219,15 @@ func (r *PrintJobRepository) FindAll(ctx context.Context, tenantID string, offse
 	for rows.Next() {
 		job, err := scanPrintJob(rows)
 		if err != nil {
-			return nil, 0, fmt.Errorf("error scanning print job: %w", err)
+			return nil, fmt.Errorf("error scanning print job: %w", err)
 		}
 		jobs = append(jobs, job)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, 0, fmt.Errorf("error iterating print jobs: %w", err)
+		return nil, fmt.Errorf("error iterating print jobs: %w", err)
 	}
-
-	return jobs, total, nil
+	return jobs, nil
 }
 
 // UpdateStatus updates the print job status using dynamic CRUD
@@ -193,7 +252,8 @@ func (r *PrintJobRepository) UpdateStatus(ctx context.Context, tenantID string,
 	if params.Status == models.PrintJobStatusProcessing {
 		columns = append(columns, ColumnValue{Name: "STARTED_AT", Value: "SYSDATE", Type: "DATE"})
 	}
-	if params.Status == models.PrintJobStatusCompleted || params.Status == models.PrintJobStatusFailed {
+	if params.Status == models.PrintJobStatusCompleted || params.Status == models.PrintJobStatusFailed ||
+		params.Status == models.PrintJobStatusDead || params.Status == models.PrintJobStatusCancelled {
 		columns = append(columns, ColumnValue{Name: "COMPLETED_AT", Value: "SYSDATE", Type: "DATE"})
 	}
 
@@ -210,20 +270,19 @@ func (r *PrintJobRepository) UpdateStatus(ctx context.Context, tenantID string,
 	return nil
 }
 
-// GetPendingJobs retrieves pending print jobs
+// GetPendingJobs retrieves pending print jobs, including PROCESSING jobs whose lease expired
 // Stored procedure sp_get_pending_print_jobs available for ref cursor usage
 func (r *PrintJobRepository) GetPendingJobs(ctx context.Context, limit int) ([]models.ContractPrintJob, error) {
 	query := `
-		SELECT id, tenant_id, contract_id, status, format,
-			output_path, file_size, page_count,
-			queued_at, started_at, completed_at,
-			retry_count, error_message, requested_by
+		SELECT ` + printJobSelectColumns + `
 		FROM ` + TablePrintJobs + `
-		WHERE status = :1
-		ORDER BY queued_at ASC
-		FETCH FIRST :2 ROWS ONLY`
+		WHERE (status = :1 AND (next_retry_at IS NULL OR next_retry_at <= CURRENT_TIMESTAMP))
+		   OR (status = :2 AND locked_until < CURRENT_TIMESTAMP)
+		ORDER BY ` + printJobPriorityOrder + `, queued_at ASC
+		FETCH FIRST :3 ROWS ONLY`
 
-	rows, err := r.db.QueryContext(ctx, query, string(models.PrintJobStatusQueued), limit)
+	rows, err := r.db.QueryContext(ctx, query,
+		string(models.PrintJobStatusQueued), string(models.PrintJobStatusProcessing), limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get pending jobs: %w", err)
 	}
@@ -245,26 +304,377 @@ func (r *PrintJobRepository) GetPendingJobs(ctx context.Context, limit int) ([]m
 	return jobs, nil
 }
 
-type printJobScanner interface {
-	Scan(dest ...any) error
+// Claim atomically leases a job to instanceID and moves it to PROCESSING.
+// A job can be claimed while QUEUED, or while PROCESSING under an expired
+// lease (the previous owner stopped renewing it). It returns false when the
+// job was claimed by someone else first.
+func (r *PrintJobRepository) Claim(ctx context.Context, tenantID string, id int64, instanceID string, lease time.Duration) (bool, error) {
+	query := `UPDATE ` + TablePrintJobs + `
+		SET status = :1, started_at = CURRENT_TIMESTAMP, progress_pct = 0,
+			locked_by = :2, locked_until = CURRENT_TIMESTAMP + NUMTODSINTERVAL(:3, 'SECOND')
+		WHERE tenant_id = :4 AND id = :5
+		  AND (status = :6 OR (status = :7 AND locked_until < CURRENT_TIMESTAMP))`
+	result, err := r.db.ExecContext(ctx, query,
+		string(models.PrintJobStatusProcessing), instanceID, lease.Seconds(), tenantID, id,
+		string(models.PrintJobStatusQueued), string(models.PrintJobStatusProcessing))
+	if err != nil {
+		return false, fmt.Errorf("failed to claim print job: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf(errFmtRowsAffected, err)
+	}
+	return rowsAffected == 1, nil
+}
+
+// RenewLease extends the lease held by instanceID on a PROCESSING job.
+// It returns false when the lease was lost, either because another instance
+// took over or because the job left PROCESSING (e.g. it was cancelled).
+func (r *PrintJobRepository) RenewLease(ctx context.Context, tenantID string, id int64, instanceID string, lease time.Duration) (bool, error) {
+	query := `UPDATE ` + TablePrintJobs + `
+		SET locked_until = CURRENT_TIMESTAMP + NUMTODSINTERVAL(:1, 'SECOND')
+		WHERE tenant_id = :2 AND id = :3 AND locked_by = :4 AND status = :5`
+	result, err := r.db.ExecContext(ctx, query,
+		lease.Seconds(), tenantID, id, instanceID, string(models.PrintJobStatusProcessing))
+	if err != nil {
+		return false, fmt.Errorf("failed to renew print job lease: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf(errFmtRowsAffected, err)
+	}
+	return rowsAffected == 1, nil
+}
+
+// Release hands a job this instance still leases back to the queue without
+// counting an attempt, for a render stopped by shutdown. It returns false when
+// instanceID no longer holds the lease.
+func (r *PrintJobRepository) Release(ctx context.Context, tenantID string, id int64, instanceID string) (bool, error) {
+	query := `UPDATE ` + TablePrintJobs + `
+		SET status = :1, started_at = NULL, progress_pct = 0,
+			locked_by = NULL, locked_until = NULL
+		WHERE tenant_id = :2 AND id = :3 AND locked_by = :4 AND status = :5`
+	result, err := r.db.ExecContext(ctx, query,
+		string(models.PrintJobStatusQueued), tenantID, id, instanceID, string(models.PrintJobStatusProcessing))
+	if err != nil {
+		return false, fmt.Errorf("failed to release print job: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf(errFmtRowsAffected, err)
+	}
+	return rowsAffected == 1, nil
+}
+
+// RequeueExpiredLeases moves PROCESSING jobs whose lease expired, across all
+// tenants, back to QUEUED. Their owner stopped renewing them, typically
+// because it crashed. It returns the number of jobs requeued.
+func (r *PrintJobRepository) RequeueExpiredLeases(ctx context.Context) (int64, error) {
+	query := `UPDATE ` + TablePrintJobs + `
+		SET status = :1, started_at = NULL, progress_pct = 0,
+			locked_by = NULL, locked_until = NULL
+		WHERE status = :2 AND locked_until < CURRENT_TIMESTAMP`
+	result, err := r.db.ExecContext(ctx, query,
+		string(models.PrintJobStatusQueued), string(models.PrintJobStatusProcessing))
+	if err != nil {
+		return 0, fmt.Errorf("failed to requeue print jobs with expired leases: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf(errFmtRowsAffected, err)
+	}
+	return rowsAffected, nil
+}
+
+// UpdateProgress records rendering progress on a job this instance still leases.
+// A lost lease is not an error here; the renewal loop detects and handles it.
+func (r *PrintJobRepository) UpdateProgress(ctx context.Context, tenantID string, id int64, instanceID string, pct int) error {
+	query := `UPDATE ` + TablePrintJobs + `
+		SET progress_pct = :1
+		WHERE tenant_id = :2 AND id = :3 AND locked_by = :4 AND status = :5`
+	_, err := r.db.ExecContext(ctx, query,
+		pct, tenantID, id, instanceID, string(models.PrintJobStatusProcessing))
+	if err != nil {
+		return fmt.Errorf("failed to update print job progress: %w", err)
+	}
+	return nil
+}
+
+// CompleteParams contains the output details recorded when a job completes
+type CompleteParams struct {
+	OutputPath     string // Object key within StorageBackend
+	StorageBackend string
+	FileSize       int64
+	PageCount      int
+}
+
+// Complete marks a leased job COMPLETED and releases the lease. It returns
+// false when instanceID no longer holds the lease, in which case nothing is
+// written and the caller should discard its output.
+func (r *PrintJobRepository) Complete(ctx context.Context, tenantID string, id int64, instanceID string, params CompleteParams) (bool, error) {
+	query := `UPDATE ` + TablePrintJobs + `
+		SET status = :1, output_path = :2, storage_backend = :3, file_size = :4, page_count = :5,
+			completed_at = CURRENT_TIMESTAMP, error_message = NULL, progress_pct = 100,
+			locked_by = NULL, locked_until = NULL
+		WHERE tenant_id = :6 AND id = :7 AND locked_by = :8 AND status = :9`
+	result, err := r.db.ExecContext(ctx, query,
+		string(models.PrintJobStatusCompleted), params.OutputPath, params.StorageBackend, params.FileSize, params.PageCount,
+		tenantID, id, instanceID, string(models.PrintJobStatusProcessing))
+	if err != nil {
+		return false, fmt.Errorf("failed to complete print job: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf(errFmtRowsAffected, err)
+	}
+	return rowsAffected == 1, nil
+}
+
+// FailParams contains what is recorded when an attempt at a job fails
+type FailParams struct {
+	ErrorMsg string
+	// NextRetryAt makes the job eligible for automatic requeue once it has
+	// passed; nil leaves the job waiting for a manual retry
+	NextRetryAt *time.Time
+	// Dead moves the job to the terminal DEAD status instead of FAILED
+	Dead bool
+}
+
+// MarkFailed records a failed attempt on a job instanceID leases and releases
+// the lease. Like Complete it returns false when instanceID no longer holds
+// the lease, in which case nothing is written: the job belongs to whoever
+// took it over.
+func (r *PrintJobRepository) MarkFailed(ctx context.Context, tenantID string, id int64, instanceID string, params FailParams) (bool, error) {
+	status := models.PrintJobStatusFailed
+	if params.Dead {
+		status = models.PrintJobStatusDead
+	}
+	var retryAt sql.NullTime
+	if params.NextRetryAt != nil {
+		retryAt = sql.NullTime{Time: *params.NextRetryAt, Valid: true}
+	}
+
+	query := `UPDATE ` + TablePrintJobs + `
+		SET status = :1, error_message = :2, next_retry_at = :3, completed_at = CURRENT_TIMESTAMP,
+			locked_by = NULL, locked_until = NULL
+		WHERE tenant_id = :4 AND id = :5 AND locked_by = :6 AND status = :7`
+	result, err := r.db.ExecContext(ctx, query,
+		string(status), params.ErrorMsg, retryAt,
+		tenantID, id, instanceID, string(models.PrintJobStatusProcessing))
+	if err != nil {
+		return false, fmt.Errorf("failed to mark print job failed: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf(errFmtRowsAffected, err)
+	}
+	return rowsAffected == 1, nil
+}
+
+// Requeue moves a FAILED job back to QUEUED and increments its retry count.
+// Returns ErrNotFound if the job does not exist or is no longer FAILED.
+func (r *PrintJobRepository) Requeue(ctx context.Context, tenantID string, id int64) error {
+	query := `UPDATE ` + TablePrintJobs + `
+		SET status = :1, retry_count = NVL(retry_count, 0) + 1,
+			next_retry_at = NULL, started_at = NULL, completed_at = NULL, progress_pct = 0
+		WHERE tenant_id = :2 AND id = :3 AND status = :4`
+	result, err := r.db.ExecContext(ctx, query,
+		string(models.PrintJobStatusQueued), tenantID, id, string(models.PrintJobStatusFailed))
+	if err != nil {
+		return fmt.Errorf("failed to requeue print job: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf(errFmtRowsAffected, err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: failed print job tenant %s id %d", ErrNotFound, tenantID, id)
+	}
+	return nil
+}
+
+// UpdatePriority changes the priority of a job that is still QUEUED.
+// Returns ErrNotFound if the job does not exist or is no longer queued.
+func (r *PrintJobRepository) UpdatePriority(ctx context.Context, tenantID string, id int64, priority models.PrintPriority) error {
+	query := `UPDATE ` + TablePrintJobs + `
+		SET priority = :1
+		WHERE tenant_id = :2 AND id = :3 AND status = :4`
+	result, err := r.db.ExecContext(ctx, query, string(priority), tenantID, id, string(models.PrintJobStatusQueued))
+	if err != nil {
+		return fmt.Errorf("failed to update print job priority: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf(errFmtRowsAffected, err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: queued print job tenant %s id %d", ErrNotFound, tenantID, id)
+	}
+	return nil
+}
+
+// Cancel moves a job to CANCELLED, provided it is still in fromStatus.
+// Returns ErrNotFound if the job does not exist or its status has changed.
+func (r *PrintJobRepository) Cancel(ctx context.Context, tenantID string, id int64, fromStatus models.PrintJobStatus) error {
+	query := `UPDATE ` + TablePrintJobs + `
+		SET status = :1, next_retry_at = NULL, completed_at = CURRENT_TIMESTAMP,
+			locked_by = NULL, locked_until = NULL
+		WHERE tenant_id = :2 AND id = :3 AND status = :4`
+	result, err := r.db.ExecContext(ctx, query,
+		string(models.PrintJobStatusCancelled), tenantID, id, string(fromStatus))
+	if err != nil {
+		return fmt.Errorf("failed to cancel print job: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf(errFmtRowsAffected, err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: %s print job tenant %s id %d", ErrNotFound, fromStatus, tenantID, id)
+	}
+	return nil
+}
+
+// GetRetryableJobs retrieves FAILED jobs whose backoff has elapsed and that
+// still have retries left under maxRetries
+func (r *PrintJobRepository) GetRetryableJobs(ctx context.Context, maxRetries, limit int) ([]models.ContractPrintJob, error) {
+	query := `
+		SELECT ` + printJobSelectColumns + `
+		FROM ` + TablePrintJobs + `
+		WHERE status = :1
+		  AND next_retry_at <= CURRENT_TIMESTAMP
+		  AND NVL(retry_count, 0) < :2
+		ORDER BY next_retry_at ASC
+		FETCH FIRST :3 ROWS ONLY`
+
+	rows, err := r.db.QueryContext(ctx, query, string(models.PrintJobStatusFailed), maxRetries, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get retryable jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []models.ContractPrintJob
+	for rows.Next() {
+		job, err := scanPrintJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan print job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating retryable jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// GetPurgeableJobs retrieves COMPLETED jobs finished before cutoff, across all
+// tenants, ordered by ID. afterID allows paging through the backlog.
+func (r *PrintJobRepository) GetPurgeableJobs(ctx context.Context, cutoff time.Time, afterID int64, limit int) ([]models.ContractPrintJob, error) {
+	query := `
+		SELECT ` + printJobSelectColumns + `
+		FROM ` + TablePrintJobs + `
+		WHERE status = :1 AND completed_at < :2 AND id > :3
+		ORDER BY id ASC
+		FETCH FIRST :4 ROWS ONLY`
+
+	rows, err := r.db.QueryContext(ctx, query, string(models.PrintJobStatusCompleted), cutoff, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get purgeable jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []models.ContractPrintJob
+	for rows.Next() {
+		job, err := scanPrintJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan print job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating purgeable jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// MarkPurged flags a COMPLETED job whose output file was deleted
+func (r *PrintJobRepository) MarkPurged(ctx context.Context, tenantID string, id int64) error {
+	query := `UPDATE ` + TablePrintJobs + `
+		SET status = :1, file_size = NULL
+		WHERE tenant_id = :2 AND id = :3 AND status = :4`
+	result, err := r.db.ExecContext(ctx, query,
+		string(models.PrintJobStatusPurged), tenantID, id, string(models.PrintJobStatusCompleted))
+	if err != nil {
+		return fmt.Errorf("failed to mark print job purged: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf(errFmtRowsAffected, err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: completed print job tenant %s id %d", ErrNotFound, tenantID, id)
+	}
+	return nil
 }
 
-func scanPrintJob(scanner printJobScanner) (models.ContractPrintJob, error) {
+// CountByStatus counts print jobs of all tenants grouped by status
+func (r *PrintJobRepository) CountByStatus(ctx context.Context) (map[models.PrintJobStatus]int64, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT status, COUNT(*) FROM `+TablePrintJobs+` GROUP BY status`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count print jobs: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[models.PrintJobStatus]int64)
+	for rows.Next() {
+		var status models.PrintJobStatus
+		var n int64
+		if err := rows.Scan(&status, &n); err != nil {
+			return nil, fmt.Errorf("failed to scan print job count: %w", err)
+		}
+		counts[status] = n
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating print job counts: %w", err)
+	}
+
+	return counts, nil
+}
+
+func scanPrintJob(scanner rowScanner) (models.ContractPrintJob, error) {
 	var job models.ContractPrintJob
-	var outputPath, errorMessage sql.NullString
+	var watermark int
+	var outputPath, storageBackend, errorMessage sql.NullString
 	var fileSize, pageCount sql.NullInt64
-	var startedAt, completedAt sql.NullTime
+	var startedAt, completedAt, nextRetryAt sql.NullTime
 
 	if err := scanner.Scan(
-		&job.ID, &job.TenantID, &job.ContractID, &job.Status, &job.Format,
-		&outputPath, &fileSize, &pageCount,
+		&job.ID, &job.TenantID, &job.ContractID, &job.Status, &job.Format, &job.Priority, &watermark,
+		&outputPath, &storageBackend, &job.ProgressPct, &fileSize, &pageCount,
 		&job.QueuedAt, &startedAt, &completedAt,
-		&job.RetryCount, &errorMessage, &job.RequestedBy,
+		&job.RetryCount, &nextRetryAt, &errorMessage, &job.RequestedBy,
 	); err != nil {
 		return models.ContractPrintJob{}, err
 	}
 
+	job.Watermark = IntToBool(watermark)
 	job.OutputPath = outputPath.String
+	job.StorageBackend = storageBackend.String
 	job.FileSize = fileSize.Int64
 	job.PageCount = int(pageCount.Int64)
 	job.ErrorMessage = errorMessage.String
@@ -274,6 +684,9 @@ func scanPrintJob(scanner printJobScanner) (models.ContractPrintJob, error) {
 	if completedAt.Valid {
 		job.CompletedAt = &completedAt.Time
 	}
+	if nextRetryAt.Valid {
+		job.NextRetryAt = &nextRetryAt.Time
+	}
 
 	return job, nil
 }
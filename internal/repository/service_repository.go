@@ -4,7 +4,6 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"strings"
 
 	"github.com/zlovtnik/gprint/internal/models"
 )
@@ -12,16 +11,20 @@ import (
 // TableServices is the table name for services.
 const TableServices = "SERVICES"
 
+func init() {
+	RegisterTable(TableServices, TableOptions{SoftDelete: true})
+}
+
 // ServiceRepository handles service data access.
 // Uses direct SQL reads (GetByID, List, GetCategories) via db for performance/control,
 // and delegates writes (Create, Update, Delete) to generic via the GenericRepository.
 type ServiceRepository struct {
-	db      *sql.DB
+	db      *DB
 	generic *GenericRepository
 }
 
 // NewServiceRepository creates a new ServiceRepository
-func NewServiceRepository(db *sql.DB) *ServiceRepository {
+func NewServiceRepository(db *DB) *ServiceRepository {
 	if db == nil {
 		panic("ServiceRepository: db is nil")
 	}
@@ -119,30 +122,39 @@ func (r *ServiceRepository) Create(ctx context.Context, tenantID string, req *mo
 // Stored procedure sp_get_service is available but not used here.
 // FUTURE: Migrate to sp_get_service if/when ref cursor handling is needed.
 func (r *ServiceRepository) GetByID(ctx context.Context, tenantID string, id int64) (*models.Service, error) {
-	query := `
-		SELECT id, tenant_id, service_code, name, description, category, subcategory,
+	query := `SELECT ` + serviceSelectColumns + ` FROM services WHERE tenant_id = :1 AND id = :2`
+
+	s, err := scanService(r.db.QueryRowContext(ctx, query, tenantID, id))
+	if err == sql.ErrNoRows {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get service: %w", err)
+	}
+	return &s, nil
+}
+
+// serviceSelectColumns are the service columns read by scanService
+const serviceSelectColumns = `id, tenant_id, service_code, name, description, category, subcategory,
 			unit_price, currency, price_unit, service_code_fiscal,
 			iss_rate, irrf_rate, pis_rate, cofins_rate, csll_rate,
-			active, notes, created_at, updated_at, created_by, updated_by
-		FROM services
-		WHERE tenant_id = :1 AND id = :2`
+			active, notes, created_at, updated_at, created_by, updated_by`
 
+// scanService scans a row of serviceSelectColumns
+func scanService(scanner rowScanner) (models.Service, error) {
 	var s models.Service
 	var description, category, subcategory, serviceCodeFiscal sql.NullString
 	var notes, createdBy, updatedBy sql.NullString
 	var createdAt, updatedAt sql.NullTime
 
-	err := r.db.QueryRowContext(ctx, query, tenantID, id).Scan(
+	err := scanner.Scan(
 		&s.ID, &s.TenantID, &s.ServiceCode, &s.Name, &description, &category, &subcategory,
 		&s.UnitPrice, &s.Currency, &s.PriceUnit, &serviceCodeFiscal,
 		&s.ISSRate, &s.IRRFRate, &s.PISRate, &s.COFINSRate, &s.CSLLRate,
 		&s.Active, &notes, &createdAt, &updatedAt, &createdBy, &updatedBy,
 	)
-	if err == sql.ErrNoRows {
-		return nil, sql.ErrNoRows
-	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to get service: %w", err)
+		return models.Service{}, err
 	}
 
 	s.Description = description.String
@@ -158,8 +170,7 @@ func (r *ServiceRepository) GetByID(ctx context.Context, tenantID string, id int
 	if updatedAt.Valid {
 		s.UpdatedAt = updatedAt.Time
 	}
-
-	return &s, nil
+	return s, nil
 }
 
 // serviceListAllowedSorts defines valid sort columns for service listing
@@ -171,121 +182,46 @@ var serviceListAllowedSorts = map[string]bool{
 	"created_at":   true,
 }
 
-// getServiceSortClause returns validated sort column and direction
-func getServiceSortClause(sortBy, sortDir string) (string, string) {
-	col := "created_at"
-	if sortBy != "" && serviceListAllowedSorts[sortBy] {
-		col = sortBy
-	}
-	dir := "DESC"
-	if strings.ToUpper(sortDir) == "ASC" {
-		dir = "ASC"
-	}
-	return col, dir
-}
-
-// List retrieves services with pagination
-// Stored procedure sp_list_services available for ref cursor usage
+// List retrieves services with pagination, counting the matches in the
+// same round trip
 func (r *ServiceRepository) List(ctx context.Context, tenantID string, params models.PaginationParams, search models.SearchParams) ([]models.Service, int, error) {
-	// Count query
-	countQuery := `SELECT COUNT(*) FROM services WHERE tenant_id = :1`
-	args := []any{tenantID}
-	argIndex := 2
+	sortBy, sortDir := getSortClause(search.SortBy, search.SortDir, serviceListAllowedSorts, "created_at")
+	return listPage(ctx, r.db, tenantID, pageQuery{
+		noun:    "services",
+		table:   "services",
+		columns: serviceSelectColumns,
+		filter:  serviceFilterConditions(search),
+		orderBy: sortBy + " " + sortDir,
+		offset:  params.Offset(),
+		limit:   params.Limit(),
+	}, scanService)
+}
 
+// serviceFilterConditions builds the list conditions for search,
+// numbering binds from :2 after the tenant
+func serviceFilterConditions(search models.SearchParams) *QueryBuilder {
+	qb := NewQueryBuilder(2)
 	if search.Query != "" {
-		countQuery += fmt.Sprintf(" AND UPPER(name) LIKE UPPER(:%d)", argIndex)
-		args = append(args, "%"+search.Query+"%")
-		argIndex++
+		qb.AddCondition("UPPER(name) LIKE UPPER(:%d)", "%"+search.Query+"%")
 	}
-
 	if search.Active != nil {
-		countQuery += fmt.Sprintf(" AND active = :%d", argIndex)
-		args = append(args, boolToInt(*search.Active))
-	}
-
-	var total int
-	err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total)
-	if err != nil {
-		return nil, 0, fmt.Errorf("failed to count services: %w", err)
+		qb.AddCondition("active = :%d", boolToInt(*search.Active))
 	}
+	return qb
+}
 
-	// Main query
-	query := `
-		SELECT id, tenant_id, service_code, name, description, category, subcategory,
-			unit_price, currency, price_unit, service_code_fiscal,
-			iss_rate, irrf_rate, pis_rate, cofins_rate, csll_rate,
-			active, notes, created_at, updated_at, created_by, updated_by
+// SearchSummary finds active services whose name or code contains query,
+// returning up to limit of them by name and the number of matches
+func (r *ServiceRepository) SearchSummary(ctx context.Context, tenantID, query string, limit int) ([]models.SearchSummary, int, error) {
+	return searchSummaries(ctx, r.db, models.SearchTypeService, `
+		SELECT id, name, service_code, COUNT(*) OVER ()
 		FROM services
-		WHERE tenant_id = :1`
-
-	queryArgs := []any{tenantID}
-	queryArgIndex := 2
-
-	if search.Query != "" {
-		query += fmt.Sprintf(" AND UPPER(name) LIKE UPPER(:%d)", queryArgIndex)
-		queryArgs = append(queryArgs, "%"+search.Query+"%")
-		queryArgIndex++
-	}
-
-	if search.Active != nil {
-		query += fmt.Sprintf(" AND active = :%d", queryArgIndex)
-		queryArgs = append(queryArgs, boolToInt(*search.Active))
-		queryArgIndex++
-	}
-
-	// Sorting
-	sortBy, sortDir := getServiceSortClause(search.SortBy, search.SortDir)
-	query += fmt.Sprintf(" ORDER BY %s %s", sortBy, sortDir)
-
-	// Pagination
-	query += fmt.Sprintf(" OFFSET :%d ROWS FETCH NEXT :%d ROWS ONLY", queryArgIndex, queryArgIndex+1)
-	queryArgs = append(queryArgs, params.Offset(), params.Limit())
-
-	rows, err := r.db.QueryContext(ctx, query, queryArgs...)
-	if err != nil {
-		return nil, 0, fmt.Errorf("failed to list services: %w", err)
-	}
-	defer rows.Close()
-
-	var services []models.Service
-	for rows.Next() {
-		var s models.Service
-		var description, category, subcategory, serviceCodeFiscal sql.NullString
-		var notes, createdBy, updatedBy sql.NullString
-		var createdAt, updatedAt sql.NullTime
-
-		err := rows.Scan(
-			&s.ID, &s.TenantID, &s.ServiceCode, &s.Name, &description, &category, &subcategory,
-			&s.UnitPrice, &s.Currency, &s.PriceUnit, &serviceCodeFiscal,
-			&s.ISSRate, &s.IRRFRate, &s.PISRate, &s.COFINSRate, &s.CSLLRate,
-			&s.Active, &notes, &createdAt, &updatedAt, &createdBy, &updatedBy,
-		)
-		if err != nil {
-			return nil, 0, fmt.Errorf("failed to scan service: %w", err)
-		}
-
-		s.Description = description.String
-		s.Category = category.String
-		s.Subcategory = subcategory.String
-		s.ServiceCodeFiscal = serviceCodeFiscal.String
-		s.Notes = notes.String
-		s.CreatedBy = createdBy.String
-		s.UpdatedBy = updatedBy.String
-		if createdAt.Valid {
-			s.CreatedAt = createdAt.Time
-		}
-		if updatedAt.Valid {
-			s.UpdatedAt = updatedAt.Time
-		}
-
-		services = append(services, s)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, 0, fmt.Errorf("failed to iterate services: %w", err)
-	}
-
-	return services, total, nil
+		WHERE tenant_id = :1 AND active = 1
+			AND (UPPER(name) LIKE UPPER(:2) ESCAPE '\'
+				OR UPPER(service_code) LIKE UPPER(:3) ESCAPE '\')
+		ORDER BY name, id
+		FETCH FIRST :4 ROWS ONLY`,
+		tenantID, LikeContains(query), LikeContains(query), limit)
 }
 
 // Update updates a service using dynamic CRUD
@@ -341,7 +277,7 @@ func (r *ServiceRepository) Update(ctx context.Context, tenantID string, id int6
 
 // Delete soft-deletes a service using dynamic CRUD.
 func (r *ServiceRepository) Delete(ctx context.Context, tenantID string, id int64, deletedBy string) error {
-	result, err := r.generic.Delete(ctx, TableServices, tenantID, id, true, deletedBy)
+	result, err := r.generic.Delete(ctx, TableServices, tenantID, id, deletedBy)
 	if err != nil {
 		return fmt.Errorf("failed to delete service: %w", err)
 	}
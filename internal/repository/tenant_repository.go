@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/zlovtnik/gprint/internal/models"
+)
+
+// Table names for tenants
+const (
+	TableTenants        = "TENANTS"
+	TableTenantSettings = "TENANT_SETTINGS"
+)
+
+// TenantRepository handles tenant and tenant settings data access
+type TenantRepository struct {
+	db *DB
+}
+
+// NewTenantRepository creates a new TenantRepository
+func NewTenantRepository(db *DB) (*TenantRepository, error) {
+	if db == nil {
+		return nil, fmt.Errorf("NewTenantRepository: db is nil")
+	}
+	return &TenantRepository{db: db}, nil
+}
+
+// GetTenant retrieves a tenant, returning nil when it does not exist
+func (r *TenantRepository) GetTenant(ctx context.Context, tenantID string) (*models.Tenant, error) {
+	query := `SELECT tenant_id, display_name, created_at, created_by
+		FROM ` + TableTenants + `
+		WHERE tenant_id = :1`
+
+	var tenant models.Tenant
+	var createdBy sql.NullString
+	err := r.db.QueryRowContext(ctx, query, tenantID).Scan(
+		&tenant.TenantID, &tenant.DisplayName, &tenant.CreatedAt, &createdBy,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+	tenant.CreatedBy = createdBy.String
+	return &tenant, nil
+}
+
+// CreateTenant inserts a tenant unless it already exists, reporting whether it was created
+func (r *TenantRepository) CreateTenant(ctx context.Context, tenantID, displayName, createdBy string) (bool, error) {
+	query := `MERGE INTO ` + TableTenants + ` t
+		USING (SELECT :1 AS tenant_id FROM dual) s
+		ON (t.tenant_id = s.tenant_id)
+		WHEN NOT MATCHED THEN INSERT (tenant_id, display_name, created_by)
+			VALUES (s.tenant_id, :2, :3)`
+	result, err := r.db.ExecContext(ctx, query, tenantID, displayName, createdBy)
+	if err != nil {
+		return false, fmt.Errorf("failed to create tenant: %w", err)
+	}
+	return rowsInserted(result)
+}
+
+// HasSettings reports whether the tenant has a settings row
+func (r *TenantRepository) HasSettings(ctx context.Context, tenantID string) (bool, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM `+TableTenantSettings+` WHERE tenant_id = :1`, tenantID,
+	).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to check tenant settings: %w", err)
+	}
+	return count > 0, nil
+}
+
+// GetSettings retrieves a tenant's setting overrides, returning nil when it has none
+func (r *TenantRepository) GetSettings(ctx context.Context, tenantID string) (*models.TenantSettingsOverrides, error) {
+	query := `SELECT currency, locale, contract_number_pattern, watermark_text, print_retention_days,
+			rounding_mode, updated_at, updated_by
+		FROM ` + TableTenantSettings + `
+		WHERE tenant_id = :1`
+
+	var currency, locale, pattern, watermark, rounding, updatedBy sql.NullString
+	var retention sql.NullInt64
+	var updatedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, query, tenantID).Scan(
+		&currency, &locale, &pattern, &watermark, &retention, &rounding, &updatedAt, &updatedBy,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant settings: %w", err)
+	}
+
+	o := &models.TenantSettingsOverrides{
+		Currency:              StringPtrFromNull(currency),
+		Locale:                StringPtrFromNull(locale),
+		ContractNumberPattern: StringPtrFromNull(pattern),
+		WatermarkText:         StringPtrFromNull(watermark),
+		UpdatedBy:             updatedBy.String,
+	}
+	if retention.Valid {
+		days := int(retention.Int64)
+		o.PrintRetentionDays = &days
+	}
+	if rounding.Valid {
+		mode := models.RoundingMode(rounding.String)
+		o.RoundingMode = &mode
+	}
+	o.UpdatedAt = TimeFromNull(updatedAt)
+	return o, nil
+}
+
+// UpsertSettings replaces a tenant's setting overrides
+func (r *TenantRepository) UpsertSettings(ctx context.Context, tenantID string, o *models.TenantSettingsOverrides, updatedBy string) error {
+	query := `MERGE INTO ` + TableTenantSettings + ` t
+		USING (SELECT :1 AS tenant_id FROM dual) s
+		ON (t.tenant_id = s.tenant_id)
+		WHEN MATCHED THEN UPDATE SET
+			currency = :2, locale = :3, contract_number_pattern = :4, watermark_text = :5,
+			print_retention_days = :6, rounding_mode = :7, updated_at = CURRENT_TIMESTAMP, updated_by = :8
+		WHEN NOT MATCHED THEN INSERT
+			(tenant_id, currency, locale, contract_number_pattern, watermark_text, print_retention_days, rounding_mode, updated_by)
+			VALUES (s.tenant_id, :9, :10, :11, :12, :13, :14, :15)`
+	values := settingsValues(o)
+	args := append([]any{tenantID}, values...)
+	args = append(args, updatedBy)
+	args = append(args, values...)
+	args = append(args, updatedBy)
+	if _, err := r.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to save tenant settings: %w", err)
+	}
+	return nil
+}
+
+// CreateSettings inserts overrides unless the tenant already has settings, reporting whether they were created
+func (r *TenantRepository) CreateSettings(ctx context.Context, tenantID string, o *models.TenantSettingsOverrides, updatedBy string) (bool, error) {
+	query := `MERGE INTO ` + TableTenantSettings + ` t
+		USING (SELECT :1 AS tenant_id FROM dual) s
+		ON (t.tenant_id = s.tenant_id)
+		WHEN NOT MATCHED THEN INSERT
+			(tenant_id, currency, locale, contract_number_pattern, watermark_text, print_retention_days, rounding_mode, updated_by)
+			VALUES (s.tenant_id, :2, :3, :4, :5, :6, :7, :8)`
+	args := append([]any{tenantID}, settingsValues(o)...)
+	result, err := r.db.ExecContext(ctx, query, append(args, updatedBy)...)
+	if err != nil {
+		return false, fmt.Errorf("failed to create tenant settings: %w", err)
+	}
+	return rowsInserted(result)
+}
+
+// ListRetentionOverrides returns the print retention days of every tenant that overrides it
+func (r *TenantRepository) ListRetentionOverrides(ctx context.Context) (map[string]int, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT tenant_id, print_retention_days FROM `+TableTenantSettings+` WHERE print_retention_days IS NOT NULL`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list retention overrides: %w", err)
+	}
+	defer rows.Close()
+
+	overrides := make(map[string]int)
+	for rows.Next() {
+		var tenantID string
+		var days int
+		if err := rows.Scan(&tenantID, &days); err != nil {
+			return nil, fmt.Errorf("failed to scan retention override: %w", err)
+		}
+		overrides[tenantID] = days
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating retention overrides: %w", err)
+	}
+	return overrides, nil
+}
+
+// settingsValues returns the override columns in table order, NULL for unset values
+func settingsValues(o *models.TenantSettingsOverrides) []any {
+	var retention *int64
+	if o.PrintRetentionDays != nil {
+		days := int64(*o.PrintRetentionDays)
+		retention = &days
+	}
+	var rounding *string
+	if o.RoundingMode != nil {
+		mode := string(*o.RoundingMode)
+		rounding = &mode
+	}
+	return []any{
+		NullStringFromPtr(o.Currency),
+		NullStringFromPtr(o.Locale),
+		NullStringFromPtr(o.ContractNumberPattern),
+		NullStringFromPtr(o.WatermarkText),
+		NullableInt64(retention),
+		NullStringFromPtr(rounding),
+	}
+}
+
+// rowsInserted reports whether an insert-if-missing MERGE inserted its row
+func rowsInserted(result sql.Result) (bool, error) {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf(errFmtRowsAffected, err)
+	}
+	return n > 0, nil
+}
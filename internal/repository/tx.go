@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// Execer runs statements. It is satisfied by *DB and by *sql.Tx, so
+// repositories built on it can run inside a transaction.
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// TxRepositories holds the repositories bound to one transaction
+type TxRepositories struct {
+	Tx      *sql.Tx
+	Generic *GenericRepository
+}
+
+// RunInTx runs fn in a transaction. The transaction is committed when fn
+// returns nil and rolled back when it returns an error or panics; a panic is
+// re-raised after the rollback.
+func RunInTx(ctx context.Context, db *DB, fn func(repos *TxRepositories) error) error {
+	if db == nil {
+		return errors.New("RunInTx: db is nil")
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf(errFmtBeginTx, err)
+	}
+	// Rolls back on error and panic; a no-op after Commit
+	defer func() { _ = tx.Rollback() }()
+
+	if err := fn(&TxRepositories{Tx: tx, Generic: newGenericRepository(tx)}); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf(errFmtCommitTx, err)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// insertInTx runs one generic insert inside RunInTx, then returns fnErr
+func insertInTx(db *DB, fnErr error) error {
+	return RunInTx(context.Background(), db, func(repos *TxRepositories) error {
+		if _, err := repos.Generic.Insert(context.Background(), testTable, "tenant-1", []ColumnValue{{Name: "NOTES", Value: "x"}}, ""); err != nil {
+			return err
+		}
+		return fnErr
+	})
+}
+
+func TestRunInTxCommits(t *testing.T) {
+	fake := &fakeDB{}
+	if err := insertInTx(fake.open(t), nil); err != nil {
+		t.Fatalf("RunInTx: %v", err)
+	}
+	if fake.commits != 1 || fake.rollbacks != 0 {
+		t.Errorf("commits = %d, rollbacks = %d; want 1 commit", fake.commits, fake.rollbacks)
+	}
+	if len(fake.execs) != 1 || !strings.Contains(fake.execs[0], "sp_generic_insert") {
+		t.Errorf("executed %q, want the insert inside the transaction", fake.execs)
+	}
+}
+
+func TestRunInTxRollsBackOnError(t *testing.T) {
+	fake := &fakeDB{}
+	errFn := errors.New("second step failed")
+	if err := insertInTx(fake.open(t), errFn); !errors.Is(err, errFn) {
+		t.Fatalf("RunInTx error = %v, want %v", err, errFn)
+	}
+	if fake.commits != 0 || fake.rollbacks != 1 {
+		t.Errorf("commits = %d, rollbacks = %d; want 1 rollback", fake.commits, fake.rollbacks)
+	}
+}
+
+func TestRunInTxRollsBackFailedStatement(t *testing.T) {
+	fake := &fakeDB{execErr: errors.New("ORA-00001: unique constraint violated")}
+	if err := insertInTx(fake.open(t), nil); err == nil || !strings.Contains(err.Error(), "ORA-00001") {
+		t.Fatalf("RunInTx error = %v, want the statement's error", err)
+	}
+	if fake.commits != 0 || fake.rollbacks != 1 {
+		t.Errorf("commits = %d, rollbacks = %d; want 1 rollback", fake.commits, fake.rollbacks)
+	}
+}
+
+func TestRunInTxRollsBackOnPanic(t *testing.T) {
+	fake := &fakeDB{}
+	db := fake.open(t)
+
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Fatalf("recovered %v, want the panic re-raised", r)
+		}
+		if fake.commits != 0 || fake.rollbacks != 1 {
+			t.Errorf("commits = %d, rollbacks = %d; want 1 rollback", fake.commits, fake.rollbacks)
+		}
+	}()
+	_ = RunInTx(context.Background(), db, func(repos *TxRepositories) error {
+		panic("boom")
+	})
+	t.Fatal("RunInTx returned normally")
+}
+
+func TestRunInTxNilDB(t *testing.T) {
+	if err := RunInTx(context.Background(), nil, func(*TxRepositories) error { return nil }); err == nil {
+		t.Error("RunInTx accepted a nil db")
+	}
+}
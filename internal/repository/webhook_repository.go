@@ -0,0 +1,315 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zlovtnik/gprint/internal/models"
+)
+
+// Table names for webhook operations
+const (
+	TableWebhooks          = "WEBHOOKS"
+	TableWebhookDeliveries = "WEBHOOK_DELIVERIES"
+)
+
+// webhookSelectColumns is the column list read by scanWebhook, in scan order
+const webhookSelectColumns = `id, tenant_id, url, secret, event_types, active, created_at, created_by`
+
+// webhookDeliverySelectColumns is the column list read by scanWebhookDelivery, in scan order
+const webhookDeliverySelectColumns = `id, tenant_id, webhook_id, event_type, payload, status, attempts,
+			next_attempt_at, last_status_code, last_error, created_at, delivered_at`
+
+// maxWebhookErrorLength matches the width of webhook_deliveries.last_error
+const maxWebhookErrorLength = 1000
+
+// WebhookRepository handles webhook and webhook delivery data access
+type WebhookRepository struct {
+	db *DB
+}
+
+// NewWebhookRepository creates a new WebhookRepository
+func NewWebhookRepository(db *DB) (*WebhookRepository, error) {
+	if db == nil {
+		return nil, fmt.Errorf("NewWebhookRepository: db is nil")
+	}
+	return &WebhookRepository{db: db}, nil
+}
+
+// Create registers a webhook for a tenant
+func (r *WebhookRepository) Create(ctx context.Context, tenantID string, req *models.CreateWebhookRequest, createdBy string) (*models.Webhook, error) {
+	if req == nil {
+		return nil, fmt.Errorf("create webhook request cannot be nil")
+	}
+
+	var id int64
+	query := `INSERT INTO ` + TableWebhooks + ` (tenant_id, url, secret, event_types, created_by)
+		VALUES (:1, :2, :3, :4, :5)
+		RETURNING id INTO :6`
+	_, err := r.db.ExecContext(ctx, query,
+		tenantID, req.URL, req.Secret, joinEventTypes(req.EventTypes), createdBy,
+		sql.Out{Dest: &id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	return r.GetByID(ctx, tenantID, id)
+}
+
+// GetByID retrieves a webhook by ID, returning nil when it does not exist
+func (r *WebhookRepository) GetByID(ctx context.Context, tenantID string, id int64) (*models.Webhook, error) {
+	query := `SELECT ` + webhookSelectColumns + `
+		FROM ` + TableWebhooks + `
+		WHERE tenant_id = :1 AND id = :2`
+
+	webhook, err := scanWebhook(r.db.QueryRowContext(ctx, query, tenantID, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+	return &webhook, nil
+}
+
+// List retrieves all webhooks registered by a tenant
+func (r *WebhookRepository) List(ctx context.Context, tenantID string) ([]models.Webhook, error) {
+	query := `SELECT ` + webhookSelectColumns + `
+		FROM ` + TableWebhooks + `
+		WHERE tenant_id = :1
+		ORDER BY id`
+	return r.queryWebhooks(ctx, query, tenantID)
+}
+
+// FindActive retrieves a tenant's active webhooks. Event filtering happens in
+// the caller since event types are stored as a list.
+func (r *WebhookRepository) FindActive(ctx context.Context, tenantID string) ([]models.Webhook, error) {
+	query := `SELECT ` + webhookSelectColumns + `
+		FROM ` + TableWebhooks + `
+		WHERE tenant_id = :1 AND active = 1
+		ORDER BY id`
+	return r.queryWebhooks(ctx, query, tenantID)
+}
+
+// Delete removes a webhook together with its delivery log.
+// Returns ErrNotFound if the webhook does not exist.
+func (r *WebhookRepository) Delete(ctx context.Context, tenantID string, id int64) error {
+	result, err := r.db.ExecContext(ctx,
+		`DELETE FROM `+TableWebhooks+` WHERE tenant_id = :1 AND id = :2`, tenantID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf(errFmtRowsAffected, err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: webhook tenant %s id %d", ErrNotFound, tenantID, id)
+	}
+	return nil
+}
+
+// CreateDelivery records a PENDING delivery that is due immediately
+func (r *WebhookRepository) CreateDelivery(ctx context.Context, tenantID string, webhookID int64, event models.EventType, payload string) (int64, error) {
+	var id int64
+	query := `INSERT INTO ` + TableWebhookDeliveries + ` (tenant_id, webhook_id, event_type, payload, status, next_attempt_at)
+		VALUES (:1, :2, :3, :4, :5, CURRENT_TIMESTAMP)
+		RETURNING id INTO :6`
+	_, err := r.db.ExecContext(ctx, query,
+		tenantID, webhookID, string(event), payload, string(models.WebhookDeliveryPending),
+		sql.Out{Dest: &id})
+	if err != nil {
+		return 0, fmt.Errorf("failed to create webhook delivery: %w", err)
+	}
+	return id, nil
+}
+
+// GetDueDeliveries retrieves PENDING deliveries whose next attempt is due, across all tenants
+func (r *WebhookRepository) GetDueDeliveries(ctx context.Context, limit int) ([]models.WebhookDelivery, error) {
+	query := `SELECT ` + webhookDeliverySelectColumns + `
+		FROM ` + TableWebhookDeliveries + `
+		WHERE status = :1 AND next_attempt_at <= CURRENT_TIMESTAMP
+		ORDER BY next_attempt_at ASC
+		FETCH FIRST :2 ROWS ONLY`
+
+	return r.queryDeliveries(ctx, query, string(models.WebhookDeliveryPending), limit)
+}
+
+// ListDeliveries retrieves the most recent deliveries of a webhook, newest first
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, tenantID string, webhookID int64, limit int) ([]models.WebhookDelivery, error) {
+	query := `SELECT ` + webhookDeliverySelectColumns + `
+		FROM ` + TableWebhookDeliveries + `
+		WHERE tenant_id = :1 AND webhook_id = :2
+		ORDER BY id DESC
+		FETCH FIRST :3 ROWS ONLY`
+	return r.queryDeliveries(ctx, query, tenantID, webhookID, limit)
+}
+
+// ClaimDelivery reserves a due PENDING delivery for one attempt by pushing its
+// next attempt to now + hold, so other instances skip it while it is in flight.
+// It returns false when the delivery is not due or was claimed by someone else.
+func (r *WebhookRepository) ClaimDelivery(ctx context.Context, id int64, hold time.Duration) (bool, error) {
+	query := `UPDATE ` + TableWebhookDeliveries + `
+		SET next_attempt_at = CURRENT_TIMESTAMP + NUMTODSINTERVAL(:1, 'SECOND')
+		WHERE id = :2 AND status = :3 AND next_attempt_at <= CURRENT_TIMESTAMP`
+	result, err := r.db.ExecContext(ctx, query, hold.Seconds(), id, string(models.WebhookDeliveryPending))
+	if err != nil {
+		return false, fmt.Errorf("failed to claim webhook delivery: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf(errFmtRowsAffected, err)
+	}
+	return rowsAffected == 1, nil
+}
+
+// MarkDelivered records a successful attempt
+func (r *WebhookRepository) MarkDelivered(ctx context.Context, id int64, statusCode int) error {
+	query := `UPDATE ` + TableWebhookDeliveries + `
+		SET status = :1, attempts = attempts + 1, last_status_code = :2, last_error = NULL,
+			next_attempt_at = NULL, delivered_at = CURRENT_TIMESTAMP
+		WHERE id = :3`
+	if _, err := r.db.ExecContext(ctx, query, string(models.WebhookDeliveryDelivered), statusCode, id); err != nil {
+		return fmt.Errorf("failed to mark webhook delivery delivered: %w", err)
+	}
+	return nil
+}
+
+// MarkAttemptFailed records a failed attempt. When nextAttemptAt is nil the
+// delivery is out of attempts and becomes DEAD.
+func (r *WebhookRepository) MarkAttemptFailed(ctx context.Context, id int64, statusCode int, errMsg string, nextAttemptAt *time.Time) error {
+	status := models.WebhookDeliveryPending
+	if nextAttemptAt == nil {
+		status = models.WebhookDeliveryDead
+	}
+	var code sql.NullInt64
+	if statusCode > 0 {
+		code = sql.NullInt64{Int64: int64(statusCode), Valid: true}
+	}
+	if len(errMsg) > maxWebhookErrorLength {
+		errMsg = errMsg[:maxWebhookErrorLength]
+	}
+
+	query := `UPDATE ` + TableWebhookDeliveries + `
+		SET status = :1, attempts = attempts + 1, last_status_code = :2, last_error = :3, next_attempt_at = :4
+		WHERE id = :5`
+	if _, err := r.db.ExecContext(ctx, query, string(status), code, errMsg, NullableTime(nextAttemptAt), id); err != nil {
+		return fmt.Errorf("failed to record webhook delivery failure: %w", err)
+	}
+	return nil
+}
+
+func (r *WebhookRepository) queryWebhooks(ctx context.Context, query string, args ...any) ([]models.Webhook, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	var webhooks []models.Webhook
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook: %w", err)
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating webhooks: %w", err)
+	}
+
+	return webhooks, nil
+}
+
+func (r *WebhookRepository) queryDeliveries(ctx context.Context, query string, args ...any) ([]models.WebhookDelivery, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []models.WebhookDelivery
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating webhook deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// joinEventTypes stores an event list in the comma-separated event_types column
+func joinEventTypes(events []models.EventType) string {
+	parts := make([]string, len(events))
+	for i, e := range events {
+		parts[i] = string(e)
+	}
+	return strings.Join(parts, ",")
+}
+
+// splitEventTypes parses the comma-separated event_types column
+func splitEventTypes(s string) []models.EventType {
+	var events []models.EventType
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			events = append(events, models.EventType(part))
+		}
+	}
+	return events
+}
+
+type webhookScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanWebhook(scanner webhookScanner) (models.Webhook, error) {
+	var webhook models.Webhook
+	var eventTypes string
+	var active int
+	var createdBy sql.NullString
+
+	if err := scanner.Scan(
+		&webhook.ID, &webhook.TenantID, &webhook.URL, &webhook.Secret, &eventTypes, &active,
+		&webhook.CreatedAt, &createdBy,
+	); err != nil {
+		return models.Webhook{}, err
+	}
+
+	webhook.EventTypes = splitEventTypes(eventTypes)
+	webhook.Active = IntToBool(active)
+	webhook.CreatedBy = createdBy.String
+	return webhook, nil
+}
+
+func scanWebhookDelivery(scanner webhookScanner) (models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	var lastStatusCode sql.NullInt64
+	var lastError sql.NullString
+	var nextAttemptAt, deliveredAt sql.NullTime
+
+	if err := scanner.Scan(
+		&delivery.ID, &delivery.TenantID, &delivery.WebhookID, &delivery.EventType, &delivery.Payload,
+		&delivery.Status, &delivery.Attempts,
+		&nextAttemptAt, &lastStatusCode, &lastError, &delivery.CreatedAt, &deliveredAt,
+	); err != nil {
+		return models.WebhookDelivery{}, err
+	}
+
+	delivery.NextAttemptAt = TimeFromNull(nextAttemptAt)
+	delivery.LastStatusCode = int(lastStatusCode.Int64)
+	delivery.LastError = lastError.String
+	delivery.DeliveredAt = TimeFromNull(deliveredAt)
+	return delivery, nil
+}
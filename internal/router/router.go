@@ -4,9 +4,13 @@ import (
 	"errors"
 	"log/slog"
 	"net/http"
+	"strings"
 
 	"github.com/zlovtnik/gprint/internal/handlers"
 	"github.com/zlovtnik/gprint/internal/middleware"
+	"github.com/zlovtnik/gprint/internal/models"
+	"github.com/zlovtnik/gprint/pkg/auth"
+	"github.com/zlovtnik/gprint/pkg/ratelimit"
 )
 
 // Handlers groups all HTTP handlers for cleaner dependency injection
@@ -18,23 +22,67 @@ type Handlers struct {
 	Print              *handlers.PrintHandler
 	Health             *handlers.HealthHandler
 	Auth               *handlers.AuthHandler
+	Webhook            *handlers.WebhookHandler
+	Notification       *handlers.NotificationHandler
+	Tenant             *handlers.TenantHandler
+	Settings           *handlers.SettingsHandler
+	Feature            *handlers.FeatureHandler
+	Search             *handlers.SearchHandler
+	Invoice            *handlers.InvoiceHandler
+	Attachment         *handlers.ContractAttachmentHandler
+	Jobs               *handlers.JobHandler
+	Metrics            *handlers.MetricsHandler // optional; nil keeps /metrics off the API listener
 }
 
+// Options tunes the middleware stack
+type Options struct {
+	BodyLimit    middleware.BodyLimitConfig
+	GzipMinBytes int // Smallest JSON response that is gzip compressed
+	// Limiter enables rate limiting with RateLimit; nil disables it
+	Limiter   ratelimit.Limiter
+	RateLimit middleware.RateLimitConfig
+	Roles     middleware.RoleConfig
+	Security  middleware.SecurityHeadersConfig
+	// Features gates routes of features rolled out per tenant
+	Features middleware.FeatureChecker
+}
+
+// Roles required by routes that change data or expose reports. Reads are open
+// to every authenticated user of the tenant.
+const (
+	roleCustomersWrite = "customers:write"
+	roleServicesWrite  = "services:write"
+	roleContractsWrite = "contracts:write"
+	rolePrintManage    = "print:manage"
+	roleReportsRead    = "reports:read"
+	roleWebhooksManage = "webhooks:manage"
+	roleTenantsAdmin   = "tenants:admin"
+	roleSettingsWrite  = "settings:write"
+	roleInvoicesWrite  = "invoices:write"
+	roleTemplatesWrite = "templates:write"
+)
+
 // Router holds all route handlers
 type Router struct {
-	mux       *http.ServeMux
-	jwtSecret string
-	logger    *slog.Logger
-	handlers  Handlers
+	mux      *http.ServeMux
+	verifier *auth.Verifier
+	logger   *slog.Logger
+	handlers Handlers
+	opts     Options
 }
 
 // NewRouter creates a new Router with validated handlers.
 // Returns an error if any required handler is nil.
 func NewRouter(
-	jwtSecret string,
+	verifier *auth.Verifier,
 	logger *slog.Logger,
 	h Handlers,
+	opts Options,
 ) (*Router, error) {
+	if verifier == nil {
+		return nil, errors.New("token verifier is required")
+	}
+
 	// Validate all required handlers are set
 	if h.Customer == nil {
 		return nil, errors.New("customer handler is required")
@@ -57,20 +105,59 @@ func NewRouter(
 	if h.Auth == nil {
 		return nil, errors.New("auth handler is required")
 	}
+	if h.Webhook == nil {
+		return nil, errors.New("webhook handler is required")
+	}
+	if h.Notification == nil {
+		return nil, errors.New("notification handler is required")
+	}
+	if h.Tenant == nil {
+		return nil, errors.New("tenant handler is required")
+	}
+	if h.Settings == nil {
+		return nil, errors.New("settings handler is required")
+	}
+	if h.Feature == nil {
+		return nil, errors.New("feature handler is required")
+	}
+	if h.Search == nil {
+		return nil, errors.New("search handler is required")
+	}
+	if h.Invoice == nil {
+		return nil, errors.New("invoice handler is required")
+	}
+	if h.Attachment == nil {
+		return nil, errors.New("attachment handler is required")
+	}
+	if h.Jobs == nil {
+		return nil, errors.New("job handler is required")
+	}
+	if opts.Features == nil {
+		return nil, errors.New("feature checker is required")
+	}
 
 	return &Router{
-		mux:       http.NewServeMux(),
-		jwtSecret: jwtSecret,
-		logger:    logger,
-		handlers:  h,
+		mux:      http.NewServeMux(),
+		verifier: verifier,
+		logger:   logger,
+		handlers: h,
+		opts:     opts,
 	}, nil
 }
 
 // Setup configures all routes
 func (r *Router) Setup() http.Handler {
-	// Health endpoints (no auth required)
+	// Health endpoints (no auth required); /health and /ready are kept for existing probes
+	r.mux.HandleFunc("GET /healthz", r.handlers.Health.Health)
+	r.mux.HandleFunc("GET /readyz", r.handlers.Health.Ready)
 	r.mux.HandleFunc("GET /health", r.handlers.Health.Health)
 	r.mux.HandleFunc("GET /ready", r.handlers.Health.Ready)
+	r.mux.HandleFunc("GET /version", r.handlers.Health.Version)
+
+	// Metrics endpoint (protected by its own token, not JWT)
+	if r.handlers.Metrics != nil {
+		r.mux.HandleFunc("GET /metrics", r.handlers.Metrics.Metrics)
+	}
 
 	// Auth endpoints:
 	// - POST /api/v1/auth/login: public (no auth required)
@@ -85,28 +172,39 @@ func (r *Router) Setup() http.Handler {
 	// Customer endpoints
 	r.mux.HandleFunc("GET /api/v1/customers", r.handlers.Customer.List)
 	r.mux.HandleFunc("GET /api/v1/customers/{id}", r.handlers.Customer.Get)
-	r.mux.HandleFunc("POST /api/v1/customers", r.handlers.Customer.Create)
-	r.mux.HandleFunc("PUT /api/v1/customers/{id}", r.handlers.Customer.Update)
-	r.mux.HandleFunc("DELETE /api/v1/customers/{id}", r.handlers.Customer.Delete)
+	r.mux.Handle("POST /api/v1/customers", r.requireRole(roleCustomersWrite, r.handlers.Customer.Create))
+	r.mux.Handle("POST /api/v1/customers/import", r.requireRole(roleCustomersWrite, r.handlers.Customer.Import))
+	r.mux.Handle("PUT /api/v1/customers/{id}", r.requireRole(roleCustomersWrite, r.handlers.Customer.Update))
+	r.mux.Handle("DELETE /api/v1/customers/{id}", r.requireRole(roleCustomersWrite, r.handlers.Customer.Delete))
 
 	// Service endpoints
 	r.mux.HandleFunc("GET /api/v1/services", r.handlers.Service.List)
 	r.mux.HandleFunc("GET /api/v1/services/categories", r.handlers.Service.GetCategories)
 	r.mux.HandleFunc("GET /api/v1/services/{id}", r.handlers.Service.Get)
-	r.mux.HandleFunc("POST /api/v1/services", r.handlers.Service.Create)
-	r.mux.HandleFunc("PUT /api/v1/services/{id}", r.handlers.Service.Update)
-	r.mux.HandleFunc("DELETE /api/v1/services/{id}", r.handlers.Service.Delete)
+	r.mux.Handle("POST /api/v1/services", r.requireRole(roleServicesWrite, r.handlers.Service.Create))
+	r.mux.Handle("PUT /api/v1/services/{id}", r.requireRole(roleServicesWrite, r.handlers.Service.Update))
+	r.mux.Handle("DELETE /api/v1/services/{id}", r.requireRole(roleServicesWrite, r.handlers.Service.Delete))
 
 	// Contract endpoints
 	r.mux.HandleFunc("GET /api/v1/contracts", r.handlers.Contract.List)
 	r.mux.HandleFunc("GET /api/v1/contracts/{id}", r.handlers.Contract.Get)
-	r.mux.HandleFunc("POST /api/v1/contracts", r.handlers.Contract.Create)
-	r.mux.HandleFunc("PUT /api/v1/contracts/{id}", r.handlers.Contract.Update)
-	r.mux.HandleFunc("PATCH /api/v1/contracts/{id}/status", r.handlers.Contract.UpdateStatus)
-	r.mux.HandleFunc("POST /api/v1/contracts/{id}/sign", r.handlers.Contract.Sign)
+	r.mux.Handle("POST /api/v1/contracts", r.requireRole(roleContractsWrite, r.handlers.Contract.Create))
+	r.mux.Handle("PUT /api/v1/contracts/{id}", r.requireRole(roleContractsWrite, r.handlers.Contract.Update))
+	r.mux.Handle("PATCH /api/v1/contracts/{id}/status", r.requireRole(roleContractsWrite, r.handlers.Contract.UpdateStatus))
+	r.mux.Handle("POST /api/v1/contracts/{id}/sign", r.requireRole(roleContractsWrite, r.handlers.Contract.Sign))
 	r.mux.HandleFunc("GET /api/v1/contracts/{id}/history", r.handlers.Contract.GetHistory)
-	r.mux.HandleFunc("POST /api/v1/contracts/{id}/items", r.handlers.Contract.AddItem)
-	r.mux.HandleFunc("DELETE /api/v1/contracts/{id}/items/{itemId}", r.handlers.Contract.DeleteItem)
+	r.mux.HandleFunc("GET /api/v1/contracts/{id}/billing-schedule", r.handlers.Contract.BillingSchedule)
+	r.mux.HandleFunc("GET /api/v1/contracts/{id}/invoices", r.handlers.Invoice.ListByContract)
+	r.mux.Handle("PATCH /api/v1/invoices/{id}/status", r.requireRole(roleInvoicesWrite, r.handlers.Invoice.UpdateStatus))
+	r.mux.HandleFunc("GET /api/v1/contracts/{id}/items", r.handlers.Contract.ListItems)
+	r.mux.Handle("POST /api/v1/contracts/{id}/items", r.requireRole(roleContractsWrite, r.handlers.Contract.AddItem))
+	r.mux.Handle("DELETE /api/v1/contracts/{id}/items/{itemId}", r.requireRole(roleContractsWrite, r.handlers.Contract.DeleteItem))
+
+	// Contract attachments; deleting one from a signed contract also needs contracts:override
+	r.mux.HandleFunc("GET /api/v1/contracts/{id}/attachments", r.handlers.Attachment.List)
+	r.mux.Handle("POST /api/v1/contracts/{id}/attachments", r.requireRole(roleContractsWrite, r.handlers.Attachment.Upload))
+	r.mux.HandleFunc("GET /api/v1/contracts/{id}/attachments/{attachmentId}", r.handlers.Attachment.Download)
+	r.mux.Handle("DELETE /api/v1/contracts/{id}/attachments/{attachmentId}", r.requireRole(roleContractsWrite, r.handlers.Attachment.Delete))
 
 	// Print job endpoints
 	r.mux.HandleFunc("POST /api/v1/contracts/{id}/print", r.handlers.Print.CreateJob)
@@ -114,20 +212,90 @@ func (r *Router) Setup() http.Handler {
 	r.mux.HandleFunc("GET /api/v1/contracts/{id}/print-jobs", r.handlers.Print.GetJobsByContract)
 	r.mux.HandleFunc("GET /api/v1/print-jobs/{id}", r.handlers.Print.GetJob)
 	r.mux.HandleFunc("GET /api/v1/print-jobs/{id}/download", r.handlers.Print.Download)
+	r.mux.HandleFunc("GET /api/v1/print-jobs/{id}/events", r.handlers.Print.Events)
+	r.mux.Handle("POST /api/v1/print-jobs/{id}/retry", r.requireRole(rolePrintManage, r.handlers.Print.RetryJob))
+	r.mux.Handle("POST /api/v1/print-jobs/{id}/cancel", r.requireRole(rolePrintManage, r.handlers.Print.CancelJob))
+	r.mux.Handle("PATCH /api/v1/print-jobs/{id}/priority", r.requireRole(rolePrintManage, r.handlers.Print.UpdatePriority))
 
 	// Contract generation endpoints (all processing happens in PL/SQL for security)
-	r.mux.HandleFunc("POST /api/v1/contracts/{id}/generate", r.handlers.ContractGeneration.Generate)
+	r.mux.Handle("POST /api/v1/contracts/{id}/generate", r.requireRole(roleContractsWrite, r.handlers.ContractGeneration.Generate))
 	r.mux.HandleFunc("GET /api/v1/contracts/{id}/generated", r.handlers.ContractGeneration.ListGenerated)
 	r.mux.HandleFunc("GET /api/v1/contracts/{id}/generated/latest", r.handlers.ContractGeneration.GetLatest)
+	r.mux.HandleFunc("GET /api/v1/contracts/{id}/generated/diff", r.handlers.ContractGeneration.DiffGenerated)
 	r.mux.HandleFunc("GET /api/v1/contracts/{id}/generated/{gen_id}", r.handlers.ContractGeneration.GetContent)
 	r.mux.HandleFunc("POST /api/v1/contracts/{id}/generated/{gen_id}/log/download", r.handlers.ContractGeneration.LogDownload)
 	r.mux.HandleFunc("POST /api/v1/contracts/{id}/generated/{gen_id}/log/print", r.handlers.ContractGeneration.LogPrint)
-	r.mux.HandleFunc("GET /api/v1/contracts/{id}/generated/{gen_id}/verify", r.handlers.ContractGeneration.VerifyIntegrity)
-	r.mux.HandleFunc("GET /api/v1/contracts/generation/stats", r.handlers.ContractGeneration.GetStats)
+	r.mux.HandleFunc("GET /api/v1/contracts/{id}/generated/{gen_id}/verify", r.handlers.ContractGeneration.CheckIntegrity)
+	r.mux.HandleFunc("POST /api/v1/contracts/{id}/generated/{gen_id}/verify", r.handlers.ContractGeneration.VerifyIntegrity)
+	r.mux.HandleFunc("GET /api/v1/contracts/{id}/generated/{gen_id}/render", r.handlers.ContractGeneration.RenderGenerated)
+	r.mux.Handle("GET /api/v1/contracts/generation/stats", r.requireRole(roleReportsRead, r.handlers.ContractGeneration.GetStats))
+	r.mux.Handle("GET /api/v1/contracts/{id}/generation-stats", r.requireRole(roleReportsRead, r.handlers.ContractGeneration.GetContractStats))
+	r.mux.Handle("GET /api/v1/contracts/{id}/actions", r.requireRole(roleReportsRead, r.handlers.ContractGeneration.ListActions))
 	r.mux.HandleFunc("GET /api/v1/contracts/templates", r.handlers.ContractGeneration.ListTemplates)
 
+	// Template management
+	r.mux.HandleFunc("GET /api/v1/templates", r.handlers.ContractGeneration.ListAllTemplates)
+	r.mux.Handle("POST /api/v1/templates", r.requireRole(roleTemplatesWrite, r.handlers.ContractGeneration.CreateTemplate))
+	r.mux.HandleFunc("GET /api/v1/templates/{code}", r.handlers.ContractGeneration.GetTemplate)
+	r.mux.Handle("PUT /api/v1/templates/{code}", r.requireRole(roleTemplatesWrite, r.handlers.ContractGeneration.UpdateTemplate))
+	r.mux.Handle("DELETE /api/v1/templates/{code}", r.requireRole(roleTemplatesWrite, r.handlers.ContractGeneration.DeleteTemplate))
+	r.mux.HandleFunc("POST /api/v1/templates/{code}/preview", r.handlers.ContractGeneration.PreviewTemplate)
+
+	// Webhook endpoints
+	r.mux.Handle("GET /api/v1/webhooks", r.requireFeature(models.FeatureWebhooks, r.requireRole(roleWebhooksManage, r.handlers.Webhook.List)))
+	r.mux.Handle("POST /api/v1/webhooks", r.requireFeature(models.FeatureWebhooks, r.requireRole(roleWebhooksManage, r.handlers.Webhook.Create)))
+	r.mux.Handle("DELETE /api/v1/webhooks/{id}", r.requireFeature(models.FeatureWebhooks, r.requireRole(roleWebhooksManage, r.handlers.Webhook.Delete)))
+	r.mux.Handle("GET /api/v1/webhooks/{id}/deliveries", r.requireFeature(models.FeatureWebhooks, r.requireRole(roleWebhooksManage, r.handlers.Webhook.ListDeliveries)))
+
+	// Notification preference endpoints (apply to the calling user)
+	r.mux.HandleFunc("GET /api/v1/notification-preferences", r.handlers.Notification.Get)
+	r.mux.HandleFunc("PUT /api/v1/notification-preferences", r.handlers.Notification.Update)
+	r.mux.HandleFunc("DELETE /api/v1/notification-preferences", r.handlers.Notification.Delete)
+
+	// In-app notification endpoints (apply to the calling user)
+	r.mux.HandleFunc("GET /api/v1/notifications", r.handlers.Notification.List)
+	r.mux.HandleFunc("GET /api/v1/notifications/unread-count", r.handlers.Notification.UnreadCount)
+	r.mux.HandleFunc("POST /api/v1/notifications/read-all", r.handlers.Notification.MarkAllRead)
+	r.mux.HandleFunc("POST /api/v1/notifications/{id}/read", r.handlers.Notification.MarkRead)
+
+	// Feature flags of the calling tenant, so clients can hide disabled features
+	r.mux.HandleFunc("GET /api/v1/features", r.handlers.Feature.List)
+
+	// Contract tags in use, for autocomplete
+	r.mux.HandleFunc("GET /api/v1/tags", r.handlers.Contract.ListTags)
+
+	// Search across customers, contracts and services
+	r.mux.HandleFunc("GET /api/v1/search", r.handlers.Search.Search)
+
+	// Settings endpoints (apply to the calling tenant)
+	r.mux.HandleFunc("GET /api/v1/settings", r.handlers.Settings.Get)
+	r.mux.Handle("PUT /api/v1/settings", r.requireRole(roleSettingsWrite, r.handlers.Settings.Update))
+
+	// Tenant administration (acts on any tenant)
+	r.mux.Handle("POST /api/v1/admin/tenants", r.requireRole(roleTenantsAdmin, r.handlers.Tenant.Provision))
+	r.mux.Handle("GET /api/v1/admin/tenants/{id}/status", r.requireRole(roleTenantsAdmin, r.handlers.Tenant.Status))
+	r.mux.Handle("GET /api/v1/admin/tenants/{id}/features", r.requireRole(roleTenantsAdmin, r.handlers.Feature.TenantList))
+	r.mux.Handle("PUT /api/v1/admin/tenants/{id}/features/{name}", r.requireRole(roleTenantsAdmin, r.handlers.Feature.Set))
+	r.mux.Handle("DELETE /api/v1/admin/tenants/{id}/features/{name}", r.requireRole(roleTenantsAdmin, r.handlers.Feature.Reset))
+	r.mux.Handle("POST /api/v1/admin/verify-integrity", r.requireRole(roleTenantsAdmin, r.handlers.ContractGeneration.SweepIntegrity))
+
+	// Background jobs of the instance serving the request
+	r.mux.Handle("GET /api/v1/admin/jobs", r.requireRole(roleTenantsAdmin, r.handlers.Jobs.List))
+	r.mux.Handle("POST /api/v1/admin/jobs/{name}/run-now", r.requireRole(roleTenantsAdmin, r.handlers.Jobs.RunNow))
+
+	// Public document verification (linked from printed QR codes)
+	r.mux.HandleFunc("GET /api/v1/verify/{hash}", r.handlers.ContractGeneration.VerifyByHash)
+
 	// Apply middleware stack
-	var handler http.Handler = r.mux
+	// CaptureRoute sits directly on the mux so metrics can label by route pattern
+	var handler http.Handler = middleware.CaptureRoute(r.mux)
+
+	// Rate limiting - inside auth so requests are limited per tenant
+	if r.opts.Limiter != nil {
+		rl := r.opts.RateLimit
+		rl.Exempt = func(req *http.Request) bool { return probePaths[req.URL.Path] }
+		handler = middleware.RateLimitMiddleware(r.opts.Limiter, rl, r.logger)(handler)
+	}
 
 	// Auth middleware (skip for health endpoints and OPTIONS)
 	handler = r.authMiddleware(handler)
@@ -135,32 +303,91 @@ func (r *Router) Setup() http.Handler {
 	// CORS - applied after auth so it can set headers for preflight before auth rejects
 	handler = middleware.CORSMiddleware(middleware.DefaultCORSConfig())(handler)
 
+	// Body size limits, before any handler reads the body
+	handler = middleware.BodyLimitMiddleware(r.opts.BodyLimit)(handler)
+
+	// Compression - outside the handlers, inside logging so logged sizes are wire sizes
+	handler = middleware.GzipMiddleware(r.opts.GzipMinBytes)(handler)
+
 	// Logging
 	handler = middleware.LoggingMiddleware(r.logger)(handler)
 
-	// Recovery
+	// Metrics
+	handler = middleware.MetricsMiddleware(handler)
+
+	// Security headers - set before anything can write a response
+	handler = middleware.SecurityHeadersMiddleware(r.opts.Security)(handler)
+
+	// Request ID - outside everything that logs, and set on the response so
+	// Recovery can still log a panic with it
+	handler = middleware.RequestIDMiddleware(r.logger)(handler)
+
+	// Recovery - outermost, so a panic in any middleware becomes a 500
 	handler = middleware.RecoveryMiddleware(r.logger)(handler)
 
 	return handler
 }
 
+// requireRole wraps a route handler so it needs role
+func (r *Router) requireRole(role string, h http.HandlerFunc) http.Handler {
+	return middleware.RequireRole(r.opts.Roles, role)(h)
+}
+
+// requireFeature hides h from tenants that do not have feature enabled.
+// Routes of the CLM module belong behind models.FeatureCLM.
+func (r *Router) requireFeature(feature string, h http.Handler) http.Handler {
+	return middleware.RequireFeature(r.opts.Features, feature)(h)
+}
+
+// probePaths are polled by infrastructure and never rate limited
+var probePaths = map[string]bool{
+	"/health":  true,
+	"/healthz": true,
+	"/ready":   true,
+	"/readyz":  true,
+	"/metrics": true,
+	"/version": true,
+}
+
 // unauthenticatedPaths is an explicit allowlist of paths that bypass auth middleware
 var unauthenticatedPaths = map[string]bool{
 	"/health":              true,
+	"/healthz":             true,
+	"/metrics":             true, // checks the metrics token itself
 	"/ready":               true,
+	"/readyz":              true,
+	"/version":             true,
 	"/api/v1/auth/login":   true,
 	"/api/v1/auth/refresh": true,
 	"/api/v1/auth/logout":  true,
 	// Note: /api/v1/auth/me is NOT in this list - it requires authentication
 }
 
+// unauthenticatedPrefixes lists path prefixes that bypass auth middleware
+var unauthenticatedPrefixes = []string{
+	"/api/v1/verify/",
+}
+
+// isUnauthenticatedPath reports whether a path is exempt from authentication
+func isUnauthenticatedPath(path string) bool {
+	if unauthenticatedPaths[path] {
+		return true
+	}
+	for _, prefix := range unauthenticatedPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 // authMiddleware wraps the auth middleware but skips unauthenticated paths and OPTIONS requests
 func (r *Router) authMiddleware(next http.Handler) http.Handler {
-	authHandler := middleware.AuthMiddleware(r.jwtSecret)(next)
+	authHandler := middleware.AuthMiddleware(r.verifier)(next)
 
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		// Skip auth for explicitly allowed unauthenticated paths
-		if unauthenticatedPaths[req.URL.Path] {
+		if isUnauthenticatedPath(req.URL.Path) {
 			next.ServeHTTP(w, req)
 			return
 		}
@@ -0,0 +1,346 @@
+// Package scheduler runs the server's background jobs. Each registered job
+// runs in its own loop, never overlapping itself; panics are recovered and
+// every run's outcome is kept for the admin jobs endpoint and metrics.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/zlovtnik/gprint/internal/metrics"
+)
+
+// Errors returned by the scheduler
+var (
+	// ErrJobNotFound indicates no job is registered under the name
+	ErrJobNotFound = errors.New("job not found")
+	// ErrJobRunning indicates the job is already running
+	ErrJobRunning = errors.New("job is already running")
+	// ErrNotStarted indicates a run was requested before Start
+	ErrNotStarted = errors.New("scheduler not started")
+)
+
+// Func is the work of a job. due is the time the run was scheduled for,
+// before jitter, or the request time for a run triggered by RunNow.
+type Func func(ctx context.Context, due time.Time) error
+
+// Option tunes a registered job
+type Option func(*job)
+
+// Immediately runs the job as soon as the scheduler starts rather than
+// after its first interval
+func Immediately() Option {
+	return func(j *job) { j.immediate = true }
+}
+
+// Status is a job's schedule and the outcome of its runs on this instance
+type Status struct {
+	Name           string     `json:"name"`
+	Schedule       string     `json:"schedule"`
+	Running        bool       `json:"running"`
+	Runs           int64      `json:"runs"`
+	Failures       int64      `json:"failures"`
+	LastStartedAt  *time.Time `json:"last_started_at,omitempty"`
+	LastFinishedAt *time.Time `json:"last_finished_at,omitempty"`
+	LastDurationMS int64      `json:"last_duration_ms"`
+	LastError      string     `json:"last_error,omitempty"`
+	NextRunAt      *time.Time `json:"next_run_at,omitempty"`
+}
+
+// job is a registered job and its run state
+type job struct {
+	name      string
+	fn        Func
+	jitter    time.Duration
+	immediate bool
+	dailyAt   *time.Time // run once a day at this hour and minute instead of every interval
+
+	interval chan time.Duration // new intervals from SetInterval
+	trigger  chan time.Time     // runs requested by RunNow
+	running  atomic.Bool
+
+	mu      sync.Mutex
+	status  Status
+	every   time.Duration
+	nextDue time.Time
+}
+
+// Scheduler owns the background jobs. Jobs are registered before Start.
+type Scheduler struct {
+	logger *slog.Logger
+
+	mu      sync.Mutex
+	jobs    map[string]*job
+	started bool
+}
+
+// New creates a new Scheduler
+func New(logger *slog.Logger) *Scheduler {
+	return &Scheduler{logger: logger, jobs: make(map[string]*job)}
+}
+
+// Register adds a job run every interval, each run delayed by a random
+// amount up to jitter so instances do not hit the database together
+func (s *Scheduler) Register(name string, interval, jitter time.Duration, fn Func, opts ...Option) error {
+	if interval <= 0 {
+		return fmt.Errorf("job %s: interval must be positive", name)
+	}
+	j := &job{name: name, fn: fn, jitter: jitter, every: interval}
+	for _, opt := range opts {
+		opt(j)
+	}
+	return s.add(j)
+}
+
+// RegisterDaily adds a job run once a day at at's hour and minute, in local
+// time. Every instance gets the same due time, so jobs claimed through
+// job_locks run once per day across instances.
+func (s *Scheduler) RegisterDaily(name string, at time.Time, fn Func, opts ...Option) error {
+	j := &job{name: name, fn: fn, dailyAt: &at}
+	for _, opt := range opts {
+		opt(j)
+	}
+	return s.add(j)
+}
+
+func (s *Scheduler) add(j *job) error {
+	if j.fn == nil {
+		return fmt.Errorf("job %s: func is nil", j.name)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return fmt.Errorf("job %s: scheduler already started", j.name)
+	}
+	if _, ok := s.jobs[j.name]; ok {
+		return fmt.Errorf("job %s: already registered", j.name)
+	}
+	j.interval = make(chan time.Duration, 1)
+	j.trigger = make(chan time.Time, 1)
+	j.status.Name = j.name
+	s.jobs[j.name] = j
+	return nil
+}
+
+// Start runs every registered job in its own goroutine, tracked by wg,
+// until ctx is cancelled. A run in progress is given the same ctx.
+func (s *Scheduler) Start(ctx context.Context, wg *sync.WaitGroup) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return
+	}
+	s.started = true
+	for _, j := range s.jobs {
+		wg.Add(1)
+		go func(j *job) {
+			defer wg.Done()
+			s.loop(ctx, j)
+		}(j)
+	}
+}
+
+// SetInterval changes how often an interval job runs, from its next run on
+func (s *Scheduler) SetInterval(name string, interval time.Duration) error {
+	j, err := s.job(name)
+	if err != nil {
+		return err
+	}
+	if j.dailyAt != nil || interval <= 0 {
+		return fmt.Errorf("job %s: interval cannot be set", name)
+	}
+	// Replace any interval the loop has not picked up yet
+	select {
+	case <-j.interval:
+	default:
+	}
+	j.interval <- interval
+	return nil
+}
+
+// RunNow asks for an immediate run of the job. It fails with ErrJobRunning
+// when a run is in progress; a run already asked for is not repeated.
+func (s *Scheduler) RunNow(name string) error {
+	j, err := s.job(name)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	started := s.started
+	s.mu.Unlock()
+	if !started {
+		return ErrNotStarted
+	}
+	if j.running.Load() {
+		return ErrJobRunning
+	}
+	select {
+	case j.trigger <- time.Now():
+	default:
+	}
+	return nil
+}
+
+// Status returns the state of every job, by name
+func (s *Scheduler) Status() []Status {
+	s.mu.Lock()
+	jobs := make([]*job, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		jobs = append(jobs, j)
+	}
+	s.mu.Unlock()
+
+	statuses := make([]Status, 0, len(jobs))
+	for _, j := range jobs {
+		statuses = append(statuses, j.snapshot())
+	}
+	sort.Slice(statuses, func(a, b int) bool { return statuses[a].Name < statuses[b].Name })
+	return statuses
+}
+
+func (s *Scheduler) job(name string) (*job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[name]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	return j, nil
+}
+
+// loop runs j on its schedule until ctx is cancelled
+func (s *Scheduler) loop(ctx context.Context, j *job) {
+	if j.immediate {
+		s.run(ctx, j, time.Now())
+	}
+	for {
+		due := j.next(time.Now())
+		wait := time.Until(due)
+		if j.jitter > 0 {
+			wait += rand.N(j.jitter)
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case interval := <-j.interval:
+			timer.Stop()
+			j.setEvery(interval)
+			continue
+		case requested := <-j.trigger:
+			timer.Stop()
+			due = requested
+		case <-timer.C:
+		}
+		s.run(ctx, j, due)
+	}
+}
+
+// run performs one run of j, recovering a panic and recording the outcome
+func (s *Scheduler) run(ctx context.Context, j *job, due time.Time) {
+	if !j.running.CompareAndSwap(false, true) {
+		return
+	}
+	defer j.running.Store(false)
+
+	started := time.Now()
+	j.begin(started)
+	err := func() (err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				s.logger.Error("scheduled job panicked",
+					"job", j.name,
+					"panic", p,
+					"stack", string(debug.Stack()),
+				)
+				err = fmt.Errorf("panic: %v", p)
+			}
+		}()
+		return j.fn(ctx, due)
+	}()
+	finished := time.Now()
+
+	// A run cut short by shutdown is not a failure
+	if err != nil && ctx.Err() != nil {
+		err = nil
+	}
+	if err != nil {
+		s.logger.Error("scheduled job failed", "job", j.name, "error", err)
+	}
+	j.finish(started, finished, err)
+	metrics.RecordJobRun(j.name, finished.Sub(started), finished, err)
+}
+
+// next returns when j is next due after now
+func (j *job) next(now time.Time) time.Time {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.dailyAt != nil {
+		j.nextDue = nextDailyRun(now, *j.dailyAt)
+	} else {
+		j.nextDue = now.Add(j.every)
+	}
+	return j.nextDue
+}
+
+func (j *job) setEvery(interval time.Duration) {
+	j.mu.Lock()
+	j.every = interval
+	j.mu.Unlock()
+}
+
+func (j *job) begin(at time.Time) {
+	j.mu.Lock()
+	j.status.LastStartedAt = &at
+	j.mu.Unlock()
+}
+
+func (j *job) finish(started, finished time.Time, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.status.Runs++
+	j.status.LastFinishedAt = &finished
+	j.status.LastDurationMS = finished.Sub(started).Milliseconds()
+	j.status.LastError = ""
+	if err != nil {
+		j.status.Failures++
+		j.status.LastError = err.Error()
+	}
+}
+
+// snapshot copies j's status for reporting
+func (j *job) snapshot() Status {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	st := j.status
+	st.Running = j.running.Load()
+	if j.dailyAt != nil {
+		st.Schedule = "daily at " + j.dailyAt.Format("15:04")
+	} else {
+		st.Schedule = "every " + j.every.String()
+	}
+	if !j.nextDue.IsZero() {
+		next := j.nextDue
+		st.NextRunAt = &next
+	}
+	return st
+}
+
+// nextDailyRun returns the first time after now at at's hour and minute,
+// in now's location
+func nextDailyRun(now, at time.Time) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), at.Hour(), at.Minute(), 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
@@ -0,0 +1,283 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestScheduler() *Scheduler {
+	return New(slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+// start starts s and stops it when the test ends, failing the test when the
+// job loops do not return
+func start(t *testing.T, s *Scheduler) context.CancelFunc {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	s.Start(ctx, &wg)
+	t.Cleanup(func() {
+		cancel()
+		waitGroup(t, &wg)
+	})
+	return cancel
+}
+
+func waitGroup(t *testing.T, wg *sync.WaitGroup) {
+	t.Helper()
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("job loops did not stop")
+	}
+}
+
+// waitFor polls cond until it holds
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func status(s *Scheduler, name string) Status {
+	for _, st := range s.Status() {
+		if st.Name == name {
+			return st
+		}
+	}
+	return Status{}
+}
+
+func TestJobNeverOverlaps(t *testing.T) {
+	s := newTestScheduler()
+	var active, maxActive, runs atomic.Int32
+	err := s.Register("slow", time.Millisecond, 0, func(ctx context.Context, due time.Time) error {
+		n := active.Add(1)
+		defer active.Add(-1)
+		for {
+			m := maxActive.Load()
+			if n <= m || maxActive.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		runs.Add(1)
+		return nil
+	}, Immediately())
+	if err != nil {
+		t.Fatal(err)
+	}
+	start(t, s)
+
+	// Due every millisecond, each run taking ten, and asked for on top
+	for runs.Load() < 5 {
+		if err := s.RunNow("slow"); err != nil && !errors.Is(err, ErrJobRunning) {
+			t.Fatalf("RunNow: %v", err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if m := maxActive.Load(); m != 1 {
+		t.Errorf("%d runs of the job were in progress at once", m)
+	}
+}
+
+func TestRunNow(t *testing.T) {
+	s := newTestScheduler()
+	release := make(chan struct{})
+	dues := make(chan time.Time, 10)
+	err := s.Register("hourly", time.Hour, 0, func(ctx context.Context, due time.Time) error {
+		dues <- due
+		<-release
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.RunNow("hourly"); !errors.Is(err, ErrNotStarted) {
+		t.Errorf("RunNow before Start = %v, want %v", err, ErrNotStarted)
+	}
+	if err := s.RunNow("missing"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("RunNow of an unknown job = %v, want %v", err, ErrJobNotFound)
+	}
+	start(t, s)
+
+	requested := time.Now()
+	if err := s.RunNow("hourly"); err != nil {
+		t.Fatalf("RunNow: %v", err)
+	}
+	select {
+	case due := <-dues:
+		if due.Before(requested) || time.Since(due) > time.Minute {
+			t.Errorf("run due at %v, want the request time %v", due, requested)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("RunNow did not start a run")
+	}
+
+	waitFor(t, "the run to be reported", func() bool { return status(s, "hourly").Running })
+	if err := s.RunNow("hourly"); !errors.Is(err, ErrJobRunning) {
+		t.Errorf("RunNow during a run = %v, want %v", err, ErrJobRunning)
+	}
+	close(release)
+	waitFor(t, "the run to finish", func() bool { return status(s, "hourly").Runs == 1 })
+
+	st := status(s, "hourly")
+	if st.Running || st.Failures != 0 || st.LastStartedAt == nil || st.LastFinishedAt == nil || st.Schedule != "every 1h0m0s" {
+		t.Errorf("status = %+v, want one finished run", st)
+	}
+	if st.NextRunAt == nil || time.Until(*st.NextRunAt) < 59*time.Minute {
+		t.Errorf("next run at %v, want an hour out", st.NextRunAt)
+	}
+}
+
+func TestPanicIsRecordedAndJobKeepsRunning(t *testing.T) {
+	s := newTestScheduler()
+	var calls atomic.Int32
+	err := s.Register("flaky", time.Hour, 0, func(ctx context.Context, due time.Time) error {
+		if calls.Add(1) == 1 {
+			panic("boom")
+		}
+		return errors.New("still failing")
+	}, Immediately())
+	if err != nil {
+		t.Fatal(err)
+	}
+	start(t, s)
+
+	waitFor(t, "the panicking run", func() bool { return status(s, "flaky").Runs == 1 })
+	if st := status(s, "flaky"); st.Failures != 1 || st.LastError != "panic: boom" {
+		t.Errorf("after the panic, status = %+v", st)
+	}
+
+	// The loop survived the panic
+	waitFor(t, "the job to be idle", func() bool { return !status(s, "flaky").Running })
+	if err := s.RunNow("flaky"); err != nil {
+		t.Fatalf("RunNow: %v", err)
+	}
+	waitFor(t, "the second run", func() bool { return status(s, "flaky").Runs == 2 })
+	if st := status(s, "flaky"); st.Failures != 2 || st.LastError != "still failing" {
+		t.Errorf("after the second run, status = %+v", st)
+	}
+}
+
+func TestShutdownStopsRunsInProgress(t *testing.T) {
+	s := newTestScheduler()
+	running := make(chan struct{})
+	err := s.Register("long", time.Hour, 0, func(ctx context.Context, due time.Time) error {
+		close(running)
+		<-ctx.Done()
+		return ctx.Err()
+	}, Immediately())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Register("idle", time.Hour, 0, func(ctx context.Context, due time.Time) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	s.Start(ctx, &wg)
+	<-running
+	cancel()
+	// Both the job in a run and the one waiting for its interval return
+	waitGroup(t, &wg)
+
+	// A run cut short by shutdown is not a failure
+	if st := status(s, "long"); st.Runs != 1 || st.Failures != 0 || st.Running {
+		t.Errorf("status = %+v, want one run without failure", st)
+	}
+	if err := s.Register("late", time.Hour, 0, func(ctx context.Context, due time.Time) error { return nil }); err == nil {
+		t.Error("registered a job after Start")
+	}
+}
+
+func TestSetInterval(t *testing.T) {
+	s := newTestScheduler()
+	var runs atomic.Int32
+	err := s.Register("tick", time.Hour, 0, func(ctx context.Context, due time.Time) error {
+		runs.Add(1)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RegisterDaily("nightly", time.Date(0, 1, 1, 3, 0, 0, 0, time.Local), func(ctx context.Context, due time.Time) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	start(t, s)
+
+	if err := s.SetInterval("tick", time.Millisecond); err != nil {
+		t.Fatalf("SetInterval: %v", err)
+	}
+	waitFor(t, "runs at the new interval", func() bool { return runs.Load() >= 3 })
+	if st := status(s, "tick"); st.Schedule != "every 1ms" {
+		t.Errorf("schedule = %q, want every 1ms", st.Schedule)
+	}
+
+	if err := s.SetInterval("nightly", time.Minute); err == nil {
+		t.Error("set the interval of a daily job")
+	}
+	if err := s.SetInterval("tick", 0); err == nil {
+		t.Error("set a zero interval")
+	}
+	if err := s.SetInterval("missing", time.Minute); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("SetInterval of an unknown job = %v, want %v", err, ErrJobNotFound)
+	}
+	if st := status(s, "nightly"); st.Schedule != "daily at 03:00" {
+		t.Errorf("schedule = %q, want daily at 03:00", st.Schedule)
+	}
+}
+
+func TestRegisterErrors(t *testing.T) {
+	s := newTestScheduler()
+	noop := func(ctx context.Context, due time.Time) error { return nil }
+	if err := s.Register("a", 0, 0, noop); err == nil {
+		t.Error("registered a zero interval")
+	}
+	if err := s.Register("a", time.Minute, 0, nil); err == nil {
+		t.Error("registered a nil func")
+	}
+	if err := s.Register("a", time.Minute, 0, noop); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Register("a", time.Minute, 0, noop); err == nil {
+		t.Error("registered a name twice")
+	}
+}
+
+func TestNextDailyRun(t *testing.T) {
+	at := time.Date(0, 1, 1, 2, 30, 0, 0, time.UTC)
+	tests := []struct {
+		now  time.Time
+		want time.Time
+	}{
+		{time.Date(2024, 3, 10, 1, 0, 0, 0, time.UTC), time.Date(2024, 3, 10, 2, 30, 0, 0, time.UTC)},
+		{time.Date(2024, 3, 10, 2, 30, 0, 0, time.UTC), time.Date(2024, 3, 11, 2, 30, 0, 0, time.UTC)},
+		{time.Date(2024, 3, 10, 23, 59, 0, 0, time.UTC), time.Date(2024, 3, 11, 2, 30, 0, 0, time.UTC)},
+		{time.Date(2024, 2, 28, 12, 0, 0, 0, time.UTC), time.Date(2024, 2, 29, 2, 30, 0, 0, time.UTC)},
+		{time.Date(2024, 12, 31, 3, 0, 0, 0, time.UTC), time.Date(2025, 1, 1, 2, 30, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		if got := nextDailyRun(tt.now, at); !got.Equal(tt.want) {
+			t.Errorf("nextDailyRun(%v) = %v, want %v", tt.now, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/zlovtnik/gprint/internal/repository"
+	"github.com/zlovtnik/gprint/pkg/auth"
+)
+
+// apiClientCacheTTL bounds how long a registration change takes to apply
+const apiClientCacheTTL = time.Minute
+
+// APIClientService resolves service account tokens to their registered
+// client. Lookups run on every such request, so results, including unknown
+// clients, are cached briefly.
+type APIClientService struct {
+	repo *repository.APIClientRepository
+
+	mu    sync.Mutex
+	cache map[string]cachedAPIClient
+}
+
+type cachedAPIClient struct {
+	client  *auth.ServiceClient // nil for unknown or disabled clients
+	expires time.Time
+}
+
+// NewAPIClientService creates a new APIClientService
+func NewAPIClientService(repo *repository.APIClientRepository) *APIClientService {
+	return &APIClientService{repo: repo, cache: make(map[string]cachedAPIClient)}
+}
+
+// ServiceClient implements auth.ServiceClientStore
+func (s *APIClientService) ServiceClient(ctx context.Context, clientID string) (*auth.ServiceClient, error) {
+	now := time.Now()
+	s.mu.Lock()
+	entry, ok := s.cache[clientID]
+	s.mu.Unlock()
+	if ok && now.Before(entry.expires) {
+		return entry.client, nil
+	}
+
+	c, err := s.repo.GetByClientID(ctx, clientID)
+	if err != nil {
+		return nil, err
+	}
+	var client *auth.ServiceClient
+	if c != nil && c.Active {
+		client = &auth.ServiceClient{ClientID: c.ClientID, TenantID: c.TenantID, Scopes: c.Scopes}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Drop expired entries so tokens naming many clients cannot grow the cache
+	for id, e := range s.cache {
+		if !now.Before(e.expires) {
+			delete(s.cache, id)
+		}
+	}
+	s.cache[clientID] = cachedAPIClient{client: client, expires: now.Add(apiClientCacheTTL)}
+	return client, nil
+}
@@ -0,0 +1,374 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/zlovtnik/gprint/internal/models"
+	"github.com/zlovtnik/gprint/internal/repository"
+	"github.com/zlovtnik/gprint/internal/storage"
+	"github.com/zlovtnik/gprint/pkg/requestctx"
+)
+
+// maxAttachmentNameBytes matches contract_attachments.file_name
+const maxAttachmentNameBytes = 255
+
+// ContractAttachmentConfig configures attachment uploads
+type ContractAttachmentConfig struct {
+	MaxBytes     int64
+	AllowedTypes []string // Media types accepted, e.g. application/pdf
+	Storage      storage.Storage
+}
+
+// ContractAttachmentService stores files against contracts
+type ContractAttachmentService struct {
+	attachmentRepo *repository.ContractAttachmentRepository
+	contractRepo   *repository.ContractRepository
+	historyRepo    *repository.HistoryRepository
+	cfg            ContractAttachmentConfig
+}
+
+// NewContractAttachmentService creates a new ContractAttachmentService
+func NewContractAttachmentService(attachmentRepo *repository.ContractAttachmentRepository, contractRepo *repository.ContractRepository, historyRepo *repository.HistoryRepository, cfg ContractAttachmentConfig) *ContractAttachmentService {
+	if cfg.Storage == nil {
+		panic("ContractAttachmentService: storage is nil")
+	}
+	return &ContractAttachmentService{
+		attachmentRepo: attachmentRepo,
+		contractRepo:   contractRepo,
+		historyRepo:    historyRepo,
+		cfg:            cfg,
+	}
+}
+
+// UploadAttachmentRequest is a file being attached to a contract
+type UploadAttachmentRequest struct {
+	TenantID    string
+	ContractID  int64
+	FileName    string
+	ContentType string // As declared by the client
+	Body        io.Reader
+	UploadedBy  string
+}
+
+// AttachmentDownload is an open attachment. Reading Body to the end fails
+// with ErrAttachmentChecksumMismatch when the content does not match the
+// checksum recorded at upload; the caller must close it.
+type AttachmentDownload struct {
+	Attachment *models.ContractAttachment
+	Body       io.ReadCloser
+}
+
+// Upload stores the file and records it on the contract. The file is
+// spooled to a temporary file first, as the storage backend needs its size
+// up front, and hashed on the way.
+func (s *ContractAttachmentService) Upload(ctx context.Context, req UploadAttachmentRequest) (*models.ContractAttachment, error) {
+	if _, err := s.contract(ctx, req.TenantID, req.ContractID); err != nil {
+		return nil, err
+	}
+	contentType, err := s.allowedType(req.ContentType)
+	if err != nil {
+		return nil, err
+	}
+	fileName := cleanAttachmentName(req.FileName)
+	if fileName == "" {
+		return nil, fmt.Errorf("%w: file name is required", ErrInvalidAttachment)
+	}
+
+	spool, err := os.CreateTemp("", "attachment-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create upload file: %w", err)
+	}
+	defer os.Remove(spool.Name())
+	defer spool.Close()
+
+	sum := sha256.New()
+	size, err := io.Copy(io.MultiWriter(spool, sum), io.LimitReader(req.Body, s.cfg.MaxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upload: %w", err)
+	}
+	if size > s.cfg.MaxBytes {
+		return nil, fmt.Errorf("%w: over %d bytes", ErrAttachmentTooLarge, s.cfg.MaxBytes)
+	}
+	if size == 0 {
+		return nil, fmt.Errorf("%w: file is empty", ErrInvalidAttachment)
+	}
+	if err := checkAttachmentContent(spool, contentType); err != nil {
+		return nil, err
+	}
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to rewind upload file: %w", err)
+	}
+
+	key, err := attachmentKey(req.TenantID, req.ContractID, fileName)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.cfg.Storage.Put(ctx, key, spool, size, contentType); err != nil {
+		return nil, fmt.Errorf("failed to store attachment: %w", err)
+	}
+
+	attachment, err := s.attachmentRepo.Create(ctx, &models.ContractAttachment{
+		TenantID:       req.TenantID,
+		ContractID:     req.ContractID,
+		FileName:       fileName,
+		ContentType:    contentType,
+		SizeBytes:      size,
+		Checksum:       hex.EncodeToString(sum.Sum(nil)),
+		StorageBackend: s.cfg.Storage.Name(),
+		StorageKey:     key,
+		UploadedBy:     req.UploadedBy,
+	})
+	if err != nil {
+		s.removeFile(ctx, key)
+		return nil, err
+	}
+
+	s.recordHistory(ctx, req.TenantID, &models.CreateHistoryRequest{
+		ContractID:   req.ContractID,
+		Action:       models.HistoryActionAttach,
+		FieldChanged: "attachment",
+		NewValue:     fileName,
+		PerformedBy:  req.UploadedBy,
+	})
+	return attachment, nil
+}
+
+// List retrieves a page of a contract's attachments, newest first
+func (s *ContractAttachmentService) List(ctx context.Context, tenantID string, contractID int64, params models.PaginationParams) ([]models.ContractAttachment, int, error) {
+	if _, err := s.contract(ctx, tenantID, contractID); err != nil {
+		return nil, 0, err
+	}
+	return s.attachmentRepo.ListByContract(ctx, tenantID, contractID, params)
+}
+
+// Download opens an attachment for streaming
+func (s *ContractAttachmentService) Download(ctx context.Context, tenantID string, contractID, id int64) (*AttachmentDownload, error) {
+	attachment, err := s.attachment(ctx, tenantID, contractID, id)
+	if err != nil {
+		return nil, err
+	}
+	if attachment.StorageBackend != s.cfg.Storage.Name() {
+		return nil, fmt.Errorf("attachment %d is stored in %s, which is not configured", id, attachment.StorageBackend)
+	}
+
+	obj, err := s.cfg.Storage.Get(ctx, attachment.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) || errors.Is(err, storage.ErrInvalidKey) {
+		return nil, ErrAttachmentFileMissing
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open attachment: %w", err)
+	}
+	return &AttachmentDownload{
+		Attachment: attachment,
+		Body:       &checksumReader{body: obj.Body, sum: sha256.New(), want: attachment.Checksum},
+	}, nil
+}
+
+// Delete removes an attachment and its file. Attachments of a signed
+// contract are part of the signed record, so only callers with override set
+// may delete them.
+func (s *ContractAttachmentService) Delete(ctx context.Context, tenantID string, contractID, id int64, deletedBy string, override bool) error {
+	contract, err := s.contract(ctx, tenantID, contractID)
+	if err != nil {
+		return err
+	}
+	attachment, err := s.attachment(ctx, tenantID, contractID, id)
+	if err != nil {
+		return err
+	}
+	signed := contract.SignedAt != nil
+	if signed && !override {
+		return ErrAttachmentOverrideRequired
+	}
+
+	if err := s.attachmentRepo.Delete(ctx, tenantID, contractID, id); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrAttachmentNotFound
+		}
+		return err
+	}
+	if attachment.StorageBackend == s.cfg.Storage.Name() {
+		s.removeFile(ctx, attachment.StorageKey)
+	}
+
+	field := "attachment"
+	if signed {
+		field = "attachment_signed_override"
+	}
+	s.recordHistory(ctx, tenantID, &models.CreateHistoryRequest{
+		ContractID:   contractID,
+		Action:       models.HistoryActionDetach,
+		FieldChanged: field,
+		OldValue:     attachment.FileName,
+		PerformedBy:  deletedBy,
+	})
+	return nil
+}
+
+// contract retrieves the contract, mapping a missing one to ErrContractNotFound
+func (s *ContractAttachmentService) contract(ctx context.Context, tenantID string, id int64) (*models.Contract, error) {
+	contract, err := s.contractRepo.GetByID(ctx, tenantID, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrContractNotFound
+	}
+	return contract, err
+}
+
+// attachment retrieves the attachment, mapping a missing one to ErrAttachmentNotFound
+func (s *ContractAttachmentService) attachment(ctx context.Context, tenantID string, contractID, id int64) (*models.ContractAttachment, error) {
+	attachment, err := s.attachmentRepo.GetByID(ctx, tenantID, contractID, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrAttachmentNotFound
+	}
+	return attachment, err
+}
+
+// allowedType returns the declared media type without parameters when it
+// is in the allowlist
+func (s *ContractAttachmentService) allowedType(declared string) (string, error) {
+	mediaType, _, err := mime.ParseMediaType(declared)
+	if err != nil || !slices.Contains(s.cfg.AllowedTypes, mediaType) {
+		return "", fmt.Errorf("%w: %q; allowed: %s", ErrAttachmentTypeNotAllowed, declared, strings.Join(s.cfg.AllowedTypes, ", "))
+	}
+	return mediaType, nil
+}
+
+// sniffLen is the number of leading bytes http.DetectContentType considers
+const sniffLen = 512
+
+// checkAttachmentContent sniffs the start of the spooled upload and rejects
+// content that is not of the declared media type, so an allowlisted
+// Content-Type cannot smuggle in another kind of file
+func checkAttachmentContent(spool io.ReadSeeker, declared string) error {
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to rewind upload file: %w", err)
+	}
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(spool, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("failed to read upload file: %w", err)
+	}
+	sniffed := sniffAttachmentType(head[:n])
+	if !contentMatchesType(sniffed, declared) {
+		return fmt.Errorf("%w: content is %s, not the declared %s", ErrAttachmentTypeNotAllowed, sniffed, declared)
+	}
+	return nil
+}
+
+// sniffAttachmentType returns the media type of content starting with
+// head. TIFF, which http.DetectContentType does not know, is matched on its
+// byte order mark.
+func sniffAttachmentType(head []byte) string {
+	if bytes.HasPrefix(head, []byte("II*\x00")) || bytes.HasPrefix(head, []byte("MM\x00*")) {
+		return "image/tiff"
+	}
+	mediaType, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	return mediaType
+}
+
+// contentMatchesType reports whether sniffed content may be stored as the
+// declared type. Sniffing only tells text from binary for text formats and
+// sees a zip archive for Office Open XML, so those match any text type and
+// any zip based type respectively.
+func contentMatchesType(sniffed, declared string) bool {
+	switch {
+	case sniffed == declared:
+		return true
+	case sniffed == "text/plain":
+		return strings.HasPrefix(declared, "text/")
+	case sniffed == "application/zip":
+		return strings.HasSuffix(declared, "+zip") || strings.HasPrefix(declared, "application/vnd.openxmlformats-officedocument.")
+	}
+	return false
+}
+
+// removeFile deletes a stored file, logging failures; the metadata is
+// already gone or was never written, so the file is only an orphan
+func (s *ContractAttachmentService) removeFile(ctx context.Context, key string) {
+	if err := s.cfg.Storage.Delete(context.WithoutCancel(ctx), key); err != nil {
+		requestctx.Logger(ctx).Warn("failed to delete attachment file", "key", key, "backend", s.cfg.Storage.Name(), "error", err)
+	}
+}
+
+// recordHistory records an attachment change, logging failures
+func (s *ContractAttachmentService) recordHistory(ctx context.Context, tenantID string, req *models.CreateHistoryRequest) {
+	if _, err := s.historyRepo.Create(ctx, tenantID, req); err != nil {
+		requestctx.Logger(ctx).Warn("failed to record attachment history",
+			"contract_id", req.ContractID, "action", req.Action, "performed_by", req.PerformedBy, "error", err)
+	}
+}
+
+// cleanAttachmentName keeps the last element of a client file name, without
+// control characters, truncated to the column size
+func cleanAttachmentName(name string) string {
+	name = path.Base(strings.ReplaceAll(name, `\`, "/"))
+	name = strings.TrimSpace(strings.Map(func(r rune) rune {
+		if r < 32 || r == 127 {
+			return -1
+		}
+		return r
+	}, name))
+	if name == "." || name == "/" {
+		return ""
+	}
+	for len(name) > maxAttachmentNameBytes {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	return name
+}
+
+// attachmentExt is a file extension kept on storage keys
+var attachmentExt = regexp.MustCompile(`^\.[a-z0-9]{1,10}$`)
+
+// attachmentKey returns a new storage key for a contract's attachment. The
+// key is random so names chosen by clients never reach the storage layout.
+func attachmentKey(tenantID string, contractID int64, fileName string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate attachment key: %w", err)
+	}
+	ext := strings.ToLower(path.Ext(fileName))
+	if !attachmentExt.MatchString(ext) {
+		ext = ""
+	}
+	return path.Join("attachments", sanitizeFilename(tenantID), strconv.FormatInt(contractID, 10), hex.EncodeToString(b)+ext), nil
+}
+
+// checksumReader hashes what is read through it and fails at the end of
+// the content when the hash differs from want
+type checksumReader struct {
+	body io.ReadCloser
+	sum  hash.Hash
+	want string
+}
+
+func (c *checksumReader) Read(p []byte) (int, error) {
+	n, err := c.body.Read(p)
+	c.sum.Write(p[:n])
+	if errors.Is(err, io.EOF) && hex.EncodeToString(c.sum.Sum(nil)) != c.want {
+		return n, ErrAttachmentChecksumMismatch
+	}
+	return n, err
+}
+
+func (c *checksumReader) Close() error {
+	return c.body.Close()
+}
@@ -0,0 +1,250 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/zlovtnik/gprint/internal/repository"
+)
+
+// Leading bytes of files of each kind
+var (
+	pdfFile  = []byte("%PDF-1.7\n1 0 obj\n<< /Type /Catalog >>\nendobj\n")
+	pngFile  = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01")
+	tiffFile = []byte("II*\x00\x08\x00\x00\x00\x01\x00")
+	zipFile  = []byte("PK\x03\x04\x14\x00\x06\x00\x08\x00")
+	htmlFile = []byte("<!DOCTYPE html><html><script>alert(1)</script></html>")
+	csvFile  = []byte("code,name\nC1,Alice\n")
+)
+
+func TestCleanAttachmentName(t *testing.T) {
+	long := strings.Repeat("a", 250) + "ção.pdf"
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"contract.pdf", "contract.pdf"},
+		{"  signed scan.pdf ", "signed scan.pdf"},
+		{"../../etc/passwd", "passwd"},
+		{`C:\Users\alice\Desktop\contract.pdf`, "contract.pdf"},
+		{"/var/tmp/", "tmp"},
+		{"evil\r\nX-Header: 1.pdf", "evilX-Header: 1.pdf"},
+		{"tab\tand\x00nul\x7f.pdf", "tabandnul.pdf"},
+		{"", ""},
+		{".", ""},
+		{"/", ""},
+		{"\n\t", ""},
+		{long, strings.Repeat("a", 250) + "ção"},
+	}
+	for _, tt := range tests {
+		got := cleanAttachmentName(tt.name)
+		if got != tt.want {
+			t.Errorf("cleanAttachmentName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+		if len(got) > maxAttachmentNameBytes {
+			t.Errorf("cleanAttachmentName(%q) is %d bytes, over the column size", tt.name, len(got))
+		}
+	}
+}
+
+func TestAttachmentKey(t *testing.T) {
+	tests := []struct {
+		fileName string
+		ext      string
+	}{
+		{"contract.pdf", ".pdf"},
+		{"SCAN.TIFF", ".tiff"},
+		{"no extension", ""},
+		{"archive.tar.gz", ".gz"},
+		{"odd.p df", ""},
+		{"x.verylongextension", ""},
+	}
+	for _, tt := range tests {
+		// The tenant is cleaned and the name only lends its extension
+		key, err := attachmentKey("tenant/../1", 42, tt.fileName)
+		if err != nil {
+			t.Fatalf("attachmentKey: %v", err)
+		}
+		layout := regexp.MustCompile(`^attachments/[^/.]+/42/[0-9a-f]{32}` + regexp.QuoteMeta(tt.ext) + `$`)
+		if !layout.MatchString(key) {
+			t.Errorf("attachmentKey(%q) = %q, want attachments/<tenant>/42/<random>%s", tt.fileName, key, tt.ext)
+		}
+	}
+	a, _ := attachmentKey("t1", 42, "contract.pdf")
+	b, _ := attachmentKey("t1", 42, "contract.pdf")
+	if a == b {
+		t.Errorf("two uploads of the same name share the key %q", a)
+	}
+}
+
+func TestCheckAttachmentContent(t *testing.T) {
+	tests := []struct {
+		content  []byte
+		declared string
+		ok       bool
+	}{
+		{pdfFile, "application/pdf", true},
+		{pngFile, "image/png", true},
+		{tiffFile, "image/tiff", true},
+		{[]byte("MM\x00*\x00\x00\x00\x08"), "image/tiff", true},
+		{[]byte("\xff\xd8\xff\xe0\x00\x10JFIF\x00"), "image/jpeg", true},
+		{csvFile, "text/csv", true},
+		{zipFile, "application/vnd.openxmlformats-officedocument.wordprocessingml.document", true},
+		{pngFile, "application/pdf", false},
+		{htmlFile, "application/pdf", false},
+		{htmlFile, "image/png", false},
+		{htmlFile, "text/csv", false},
+		{pdfFile, "image/tiff", false},
+		{csvFile, "application/pdf", false},
+		{zipFile, "application/pdf", false},
+		{[]byte{0x01, 0x02, 0x03}, "image/jpeg", false},
+	}
+	for _, tt := range tests {
+		err := checkAttachmentContent(bytes.NewReader(tt.content), tt.declared)
+		if tt.ok && err != nil {
+			t.Errorf("%q declared %s: %v", tt.content[:8], tt.declared, err)
+		}
+		if !tt.ok && !errors.Is(err, ErrAttachmentTypeNotAllowed) {
+			t.Errorf("%q declared %s: error = %v, want %v", tt.content[:3], tt.declared, err, ErrAttachmentTypeNotAllowed)
+		}
+	}
+}
+
+func TestChecksumReader(t *testing.T) {
+	content := bytes.Repeat([]byte("signed contract\n"), 1000)
+	sum := sha256.Sum256(content)
+	want := hex.EncodeToString(sum[:])
+
+	body := &checksumReader{body: io.NopCloser(bytes.NewReader(content)), sum: sha256.New(), want: want}
+	got, err := io.ReadAll(body)
+	if err != nil || !bytes.Equal(got, content) {
+		t.Errorf("reading intact content: %d bytes, %v", len(got), err)
+	}
+
+	tampered := bytes.Clone(content)
+	tampered[len(tampered)-2] = '!'
+	body = &checksumReader{body: io.NopCloser(bytes.NewReader(tampered)), sum: sha256.New(), want: want}
+	if _, err := io.ReadAll(body); !errors.Is(err, ErrAttachmentChecksumMismatch) {
+		t.Errorf("reading tampered content: error = %v, want %v", err, ErrAttachmentChecksumMismatch)
+	}
+
+	body = &checksumReader{body: io.NopCloser(bytes.NewReader(content[:len(content)-1])), sum: sha256.New(), want: want}
+	if _, err := io.ReadAll(body); !errors.Is(err, ErrAttachmentChecksumMismatch) {
+		t.Errorf("reading truncated content: error = %v, want %v", err, ErrAttachmentChecksumMismatch)
+	}
+}
+
+// newAttachmentService builds a ContractAttachmentService over a mocked
+// database and in-memory storage, allowing PDFs and PNGs
+func newAttachmentService(t *testing.T) (*ContractAttachmentService, sqlmock.Sqlmock, *fakeStorage) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	rdb := repository.NewDB(db, repository.DBConfig{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	store := &fakeStorage{}
+	svc := NewContractAttachmentService(repository.NewContractAttachmentRepository(rdb), repository.NewContractRepository(rdb),
+		repository.NewHistoryRepository(rdb), ContractAttachmentConfig{
+			MaxBytes:     1 << 20,
+			AllowedTypes: []string{"application/pdf", "image/png"},
+			Storage:      store,
+		})
+	return svc, mock, store
+}
+
+// expectContract expects contract 1 of tenant-1 to be looked up
+func expectContract(mock sqlmock.Sqlmock) {
+	start := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("SELECT .* FROM contracts WHERE tenant_id").WithArgs("tenant-1", int64(1)).
+		WillReturnRows(sqlmock.NewRows(contractColumns).AddRow(
+			int64(1), "tenant-1", "CTR-0001", "SERVICE", int64(7),
+			start, nil, int64(12), false,
+			"1200", "0", "0", "0", "0",
+			1200.0, nil, "MONTHLY", "ACTIVE",
+			nil, nil, nil, nil,
+			nil, nil, start, start, nil, nil,
+		))
+	mock.ExpectQuery("FROM contract_items").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery("FROM contract_tags").WillReturnRows(sqlmock.NewRows([]string{"tag"}))
+}
+
+func TestUploadRecordsCleanNameAndChecksum(t *testing.T) {
+	svc, mock, store := newAttachmentService(t)
+	sum := sha256.Sum256(pdfFile)
+
+	expectContract(mock)
+	// The insert fails, so the stored file must be removed again
+	mock.ExpectExec("INSERT INTO CONTRACT_ATTACHMENTS").
+		WithArgs("tenant-1", int64(1), "contract.pdf", "application/pdf", int64(len(pdfFile)),
+			hex.EncodeToString(sum[:]), "fake", sqlmock.AnyArg(), "alice", sqlmock.AnyArg()).
+		WillReturnError(errors.New("ORA-02291: integrity constraint violated"))
+
+	_, err := svc.Upload(context.Background(), UploadAttachmentRequest{
+		TenantID:    "tenant-1",
+		ContractID:  1,
+		FileName:    `..\..\contract.pdf`,
+		ContentType: "application/pdf; name=contract.pdf",
+		Body:        bytes.NewReader(pdfFile),
+		UploadedBy:  "alice",
+	})
+	if err == nil || !strings.Contains(err.Error(), "ORA-02291") {
+		t.Errorf("Upload error = %v, want the insert's error", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+	if n := store.count(); n != 0 {
+		t.Errorf("%d files left in storage after the failed insert", n)
+	}
+}
+
+func TestUploadRejectsContentOfAnotherType(t *testing.T) {
+	tests := []struct {
+		name     string
+		declared string
+		content  []byte
+		want     error
+	}{
+		{"html declared as pdf", "application/pdf", htmlFile, ErrAttachmentTypeNotAllowed},
+		{"png declared as pdf", "application/pdf", pngFile, ErrAttachmentTypeNotAllowed},
+		{"type not allowed", "image/tiff", tiffFile, ErrAttachmentTypeNotAllowed},
+		{"empty file", "application/pdf", nil, ErrInvalidAttachment},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, mock, store := newAttachmentService(t)
+			expectContract(mock)
+
+			_, err := svc.Upload(context.Background(), UploadAttachmentRequest{
+				TenantID:    "tenant-1",
+				ContractID:  1,
+				FileName:    "contract.pdf",
+				ContentType: tt.declared,
+				Body:        bytes.NewReader(tt.content),
+				UploadedBy:  "alice",
+			})
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Upload error = %v, want %v", err, tt.want)
+			}
+			// Nothing is stored or recorded
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+			if n := store.count(); n != 0 {
+				t.Errorf("%d files stored", n)
+			}
+		})
+	}
+}
@@ -3,7 +3,12 @@ package service
 import (
 	"context"
 	"errors"
+	"io"
+	"strings"
+	"time"
 
+	"github.com/zlovtnik/gprint/internal/doctemplate"
+	"github.com/zlovtnik/gprint/internal/metrics"
 	"github.com/zlovtnik/gprint/internal/models"
 	"github.com/zlovtnik/gprint/internal/repository"
 )
@@ -14,16 +19,27 @@ var ErrUnauthorized = errors.New("unauthorized")
 // ContractGenerationService handles contract generation business logic
 // Delegates all sensitive data processing to the repository/database layer
 type ContractGenerationService struct {
-	repo *repository.ContractGenerationRepository
+	repo         *repository.ContractGenerationRepository
+	contractRepo *repository.ContractRepository
+	customerRepo *repository.CustomerRepository
+	settings     *TenantSettingsService
+	renderer     Renderer
 }
 
-// NewContractGenerationService creates a new ContractGenerationService
-func NewContractGenerationService(repo *repository.ContractGenerationRepository) *ContractGenerationService {
-	return &ContractGenerationService{repo: repo}
+// NewContractGenerationService creates a new ContractGenerationService.
+// renderer converts rendered generations to their output format; nil uses
+// HTMLRenderer.
+func NewContractGenerationService(repo *repository.ContractGenerationRepository, contractRepo *repository.ContractRepository, customerRepo *repository.CustomerRepository, settings *TenantSettingsService, renderer Renderer) *ContractGenerationService {
+	if renderer == nil {
+		renderer = HTMLRenderer{}
+	}
+	return &ContractGenerationService{repo: repo, contractRepo: contractRepo, customerRepo: customerRepo, settings: settings, renderer: renderer}
 }
 
 // GenerateContract generates a printable contract document
-// All sensitive data processing happens in the database layer
+// All sensitive data processing happens in the database layer. Without a
+// template code, the tenant's template in its locale is used, falling back
+// to its default template.
 func (s *ContractGenerationService) GenerateContract(
 	ctx context.Context,
 	tenantID string,
@@ -33,6 +49,47 @@ func (s *ContractGenerationService) GenerateContract(
 	ipAddress string,
 	sessionID string,
 ) (*models.GenerateContractResponse, error) {
+	params, err := s.generateParams(ctx, tenantID, contractID, userID, req, ipAddress, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.repo.GenerateContract(ctx, params)
+	metrics.RecordGeneration("generate", err)
+	return resp, err
+}
+
+// GenerateContractStream generates a contract like GenerateContract and
+// passes the result and its JSON to fn, streamed from the database; see
+// repository.ContractGenerationRepository.GenerateContractStream.
+func (s *ContractGenerationService) GenerateContractStream(
+	ctx context.Context,
+	tenantID string,
+	contractID int64,
+	userID string,
+	req *models.GenerateContractRequest,
+	ipAddress string,
+	sessionID string,
+	fn func(result *models.GenerateContractResponse, content io.Reader) error,
+) error {
+	params, err := s.generateParams(ctx, tenantID, contractID, userID, req, ipAddress, sessionID)
+	if err != nil {
+		return err
+	}
+	err = s.repo.GenerateContractStream(ctx, params, fn)
+	metrics.RecordGeneration("generate", err)
+	return err
+}
+
+// generateParams resolves the template and reason of a generation request
+func (s *ContractGenerationService) generateParams(
+	ctx context.Context,
+	tenantID string,
+	contractID int64,
+	userID string,
+	req *models.GenerateContractRequest,
+	ipAddress string,
+	sessionID string,
+) (repository.GenerateContractParams, error) {
 	templateCode := ""
 	reason := string(models.GenerationReasonInitial)
 
@@ -44,8 +101,15 @@ func (s *ContractGenerationService) GenerateContract(
 			reason = string(req.Reason)
 		}
 	}
+	if templateCode == "" {
+		code, err := s.localeTemplate(ctx, tenantID)
+		if err != nil {
+			return repository.GenerateContractParams{}, err
+		}
+		templateCode = code
+	}
 
-	return s.repo.GenerateContract(ctx, repository.GenerateContractParams{
+	return repository.GenerateContractParams{
 		TenantID:     tenantID,
 		ContractID:   contractID,
 		UserID:       userID,
@@ -53,7 +117,27 @@ func (s *ContractGenerationService) GenerateContract(
 		Reason:       reason,
 		IPAddress:    ipAddress,
 		SessionID:    sessionID,
-	})
+	}, nil
+}
+
+// localeTemplate returns the code of the tenant's active template in its
+// locale, preferring the default, or "" when it has none
+func (s *ContractGenerationService) localeTemplate(ctx context.Context, tenantID string) (string, error) {
+	settings, err := s.settings.Get(ctx, tenantID)
+	if err != nil {
+		return "", err
+	}
+	templates, err := s.repo.ListTemplates(ctx, tenantID)
+	if err != nil {
+		return "", err
+	}
+	// Templates are listed with the default first
+	for _, t := range templates {
+		if strings.EqualFold(t.Language, settings.Locale) {
+			return t.TemplateCode, nil
+		}
+	}
+	return "", nil
 }
 
 // GetGeneratedContent retrieves the JSON content of a generated contract
@@ -66,6 +150,30 @@ func (s *ContractGenerationService) GetGeneratedContent(
 	return s.repo.GetGeneratedContent(ctx, tenantID, generatedID, userID)
 }
 
+// StreamGeneratedContent passes a generated contract's metadata and JSON
+// to fn, streamed from the database
+func (s *ContractGenerationService) StreamGeneratedContent(
+	ctx context.Context,
+	tenantID string,
+	generatedID int64,
+	userID string,
+	fn func(meta *models.GeneratedContentMeta, content io.Reader) error,
+) error {
+	return s.repo.StreamGeneratedContent(ctx, tenantID, generatedID, userID, fn)
+}
+
+// StreamLatestGenerated passes the most recent generated version of a
+// contract and its JSON to fn, streamed from the database
+func (s *ContractGenerationService) StreamLatestGenerated(
+	ctx context.Context,
+	tenantID string,
+	contractID int64,
+	userID string,
+	fn func(meta *models.GeneratedContentMeta, content io.Reader) error,
+) error {
+	return s.repo.StreamLatestGenerated(ctx, tenantID, contractID, userID, fn)
+}
+
 // GetLatestGenerated retrieves the most recent generated version for a contract
 func (s *ContractGenerationService) GetLatestGenerated(
 	ctx context.Context,
@@ -145,6 +253,43 @@ func (s *ContractGenerationService) GetGenerationStats(
 	return s.repo.GetGenerationStats(ctx, tenantID)
 }
 
+// GetContractGenerationStats retrieves generation statistics for one contract
+func (s *ContractGenerationService) GetContractGenerationStats(
+	ctx context.Context,
+	tenantID string,
+	contractID int64,
+) (*models.ContractGenerationStats, error) {
+	stats, err := s.repo.GetContractGenerationStats(ctx, tenantID, contractID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrContractNotFound
+	}
+	return stats, err
+}
+
+// ListContractActions lists the actions logged on a contract's generated versions
+func (s *ContractGenerationService) ListContractActions(
+	ctx context.Context,
+	tenantID string,
+	contractID int64,
+	filter models.ContractActionFilter,
+	page int,
+	pageSize int,
+) ([]models.ContractActionLogEntry, int, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	offset := (page - 1) * pageSize
+
+	entries, total, err := s.repo.ListContractActions(ctx, tenantID, contractID, filter, offset, pageSize)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, 0, ErrContractNotFound
+	}
+	return entries, total, err
+}
+
 // VerifyContentIntegrity verifies that a generated contract hasn't been tampered with
 // Enforces tenant isolation by checking authorization first
 // Returns:
@@ -158,6 +303,7 @@ func (s *ContractGenerationService) VerifyContentIntegrity(
 	generatedID int64,
 ) (bool, error) {
 	isValid, err := s.repo.VerifyContentIntegrity(ctx, tenantID, generatedID)
+	metrics.RecordGeneration("verify", err)
 	if err != nil {
 		if errors.Is(err, repository.ErrUnauthorized) {
 			return false, ErrUnauthorized
@@ -170,6 +316,34 @@ func (s *ContractGenerationService) VerifyContentIntegrity(
 	return isValid, nil
 }
 
+// VerifyByHash checks the generation behind a printed verification code.
+// Unknown hashes and records removed mid-check both report not_found.
+func (s *ContractGenerationService) VerifyByHash(
+	ctx context.Context,
+	contentHash string,
+) (models.VerificationStatus, error) {
+	ref, err := s.repo.FindByContentHash(ctx, strings.ToLower(contentHash))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return models.VerificationNotFound, nil
+		}
+		return "", err
+	}
+
+	isValid, err := s.repo.VerifyContentIntegrity(ctx, ref.TenantID, ref.GeneratedID)
+	metrics.RecordGeneration("verify", err)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) || errors.Is(err, repository.ErrUnauthorized) {
+			return models.VerificationNotFound, nil
+		}
+		return "", err
+	}
+	if !isValid {
+		return models.VerificationTampered, nil
+	}
+	return models.VerificationValid, nil
+}
+
 // ListTemplates lists all active templates for a tenant
 func (s *ContractGenerationService) ListTemplates(
 	ctx context.Context,
@@ -195,3 +369,154 @@ func (s *ContractGenerationService) CleanupExpiredGenerations(
 ) (int, error) {
 	return s.repo.CleanupExpiredGenerations(ctx, tenantID)
 }
+
+// ListAllTemplates lists a tenant's templates, retired ones included
+func (s *ContractGenerationService) ListAllTemplates(ctx context.Context, tenantID string) ([]models.ContractTemplate, error) {
+	return s.repo.ListAllTemplates(ctx, tenantID)
+}
+
+// GetTemplate retrieves a template with its body
+func (s *ContractGenerationService) GetTemplate(ctx context.Context, tenantID, code string) (*models.ContractTemplate, error) {
+	t, err := s.repo.GetTemplate(ctx, tenantID, code)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrTemplateNotFound
+	}
+	return t, err
+}
+
+// CreateTemplate creates an active template. The body is checked with
+// doctemplate.Parse, whose *doctemplate.Error is returned when it fails.
+func (s *ContractGenerationService) CreateTemplate(ctx context.Context, tenantID string, req *models.CreateTemplateRequest, createdBy string) (*models.ContractTemplate, error) {
+	if _, err := doctemplate.Parse(req.BodyHTML); err != nil {
+		return nil, err
+	}
+	language := req.Language
+	if language == "" {
+		language = models.DefaultTemplateLanguage
+	}
+	err := s.repo.CreateTemplate(ctx, &models.ContractTemplate{
+		TenantID:     tenantID,
+		TemplateCode: req.TemplateCode,
+		TemplateName: req.TemplateName,
+		Language:     language,
+		BodyHTML:     req.BodyHTML,
+		IsDefault:    req.IsDefault,
+	}, createdBy)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrDuplicateTemplate
+		}
+		return nil, err
+	}
+	return s.GetTemplate(ctx, tenantID, req.TemplateCode)
+}
+
+// UpdateTemplate applies the fields set in req. The version is bumped when
+// the body changes. The default template cannot be unset or deactivated
+// directly: making another template the default unsets it.
+func (s *ContractGenerationService) UpdateTemplate(ctx context.Context, tenantID, code string, req *models.UpdateTemplateRequest, updatedBy string) (*models.ContractTemplate, error) {
+	t, err := s.GetTemplate(ctx, tenantID, code)
+	if err != nil {
+		return nil, err
+	}
+	wasDefault := t.IsDefault
+
+	if req.TemplateName != nil {
+		t.TemplateName = *req.TemplateName
+	}
+	if req.Language != nil {
+		t.Language = *req.Language
+	}
+	bumpVersion := false
+	if req.BodyHTML != nil && *req.BodyHTML != t.BodyHTML {
+		if _, err := doctemplate.Parse(*req.BodyHTML); err != nil {
+			return nil, err
+		}
+		t.BodyHTML = *req.BodyHTML
+		bumpVersion = true
+	}
+	if req.IsDefault != nil {
+		t.IsDefault = *req.IsDefault
+	}
+	if req.Active != nil {
+		t.Active = *req.Active
+	}
+	if (wasDefault && !t.IsDefault) || (t.IsDefault && !t.Active) {
+		return nil, ErrDefaultTemplate
+	}
+
+	if err := s.repo.UpdateTemplate(ctx, t, bumpVersion, updatedBy); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrTemplateNotFound
+		}
+		return nil, err
+	}
+	return s.GetTemplate(ctx, tenantID, code)
+}
+
+// DeleteTemplate deletes a template no generated contract refers to. The
+// default template cannot be deleted, and templates in use are retired by
+// deactivating them so the generation history keeps its reference.
+func (s *ContractGenerationService) DeleteTemplate(ctx context.Context, tenantID, code string) error {
+	t, err := s.GetTemplate(ctx, tenantID, code)
+	if err != nil {
+		return err
+	}
+	if t.IsDefault {
+		return ErrDefaultTemplate
+	}
+	err = s.repo.DeleteTemplate(ctx, tenantID, code)
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return ErrTemplateNotFound
+	case errors.Is(err, repository.ErrTemplateInUse):
+		return ErrTemplateInUse
+	}
+	return err
+}
+
+// PreviewTemplate renders a template with a contract's data, or with
+// doctemplate.Sample when contractID is 0. Nothing is written: no
+// generation record or log entry is created. A body that cannot be
+// rendered returns a *doctemplate.Error.
+func (s *ContractGenerationService) PreviewTemplate(ctx context.Context, tenantID, code string, contractID int64) (*models.TemplatePreview, error) {
+	t, err := s.GetTemplate(ctx, tenantID, code)
+	if err != nil {
+		return nil, err
+	}
+	if t.BodyHTML == "" {
+		return nil, &doctemplate.Error{Message: "template has no body"}
+	}
+	tmpl, err := doctemplate.Parse(t.BodyHTML)
+	if err != nil {
+		return nil, err
+	}
+
+	data := doctemplate.Sample()
+	if contractID > 0 {
+		contract, err := s.contractRepo.GetByID(ctx, tenantID, contractID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrContractNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		customer, err := s.customerRepo.GetByID(ctx, tenantID, contract.CustomerID)
+		if err != nil {
+			return nil, err
+		}
+		data = doctemplate.FromContract(contract, customer, time.Now())
+	}
+
+	html, err := tmpl.Preview(data)
+	if err != nil {
+		return nil, err
+	}
+	return &models.TemplatePreview{
+		TemplateCode: t.TemplateCode,
+		Version:      t.Version,
+		ContractID:   contractID,
+		Sample:       contractID == 0,
+		HTML:         html,
+	}, nil
+}
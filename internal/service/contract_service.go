@@ -2,30 +2,68 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/shopspring/decimal"
+	"github.com/zlovtnik/gprint/internal/billing"
 	"github.com/zlovtnik/gprint/internal/models"
 	"github.com/zlovtnik/gprint/internal/repository"
+	"github.com/zlovtnik/gprint/pkg/requestctx"
+)
+
+// contractNumberAttempts bounds the retries when a generated contract
+// number is taken by a concurrent create
+const contractNumberAttempts = 3
+
+// Tag list limits
+const (
+	DefaultTagLimit = 50
+	MaxTagLimit     = 200
 )
 
 // ContractService handles contract business logic
 type ContractService struct {
 	contractRepo *repository.ContractRepository
 	historyRepo  *repository.HistoryRepository
+	notifier     EventNotifier
+	settings     *TenantSettingsService
 }
 
-// NewContractService creates a new ContractService
-func NewContractService(contractRepo *repository.ContractRepository, historyRepo *repository.HistoryRepository) *ContractService {
+// NewContractService creates a new ContractService.
+// notifier may be nil to disable contract event notifications.
+func NewContractService(contractRepo *repository.ContractRepository, historyRepo *repository.HistoryRepository, notifier EventNotifier, settings *TenantSettingsService) *ContractService {
 	return &ContractService{
 		contractRepo: contractRepo,
 		historyRepo:  historyRepo,
+		notifier:     notifier,
+		settings:     settings,
 	}
 }
 
-// Create creates a new contract
+// Create creates a new contract. A request without a contract number gets
+// the next one from the tenant's contract number pattern.
 func (s *ContractService) Create(ctx context.Context, tenantID string, req *models.CreateContractRequest, createdBy string) (*models.Contract, error) {
-	contract, err := s.contractRepo.Create(ctx, tenantID, req, createdBy)
+	generate := req.ContractNumber == ""
+	rounding, err := s.roundingMode(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	var contract *models.Contract
+	for attempt := 1; ; attempt++ {
+		if generate {
+			if req.ContractNumber, err = s.nextContractNumber(ctx, tenantID); err != nil {
+				return nil, err
+			}
+		}
+		contract, err = s.contractRepo.Create(ctx, tenantID, req, createdBy, rounding)
+		if err == nil || !generate || !isUniqueViolation(err) || attempt == contractNumberAttempts {
+			break
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -37,12 +75,42 @@ func (s *ContractService) Create(ctx context.Context, tenantID string, req *mode
 		PerformedBy: createdBy,
 	}); err != nil {
 		// Log but don't fail the operation
-		log.Printf("failed to record contract creation history (tenant=%s, contractID=%d, performedBy=%s): %v", tenantID, contract.ID, createdBy, err)
+		requestctx.Logger(ctx).Warn("failed to record contract creation history", "contract_id", contract.ID, "performed_by", createdBy, "error", err)
 	}
 
 	return contract, nil
 }
 
+// nextContractNumber formats the number after the tenant's highest one for
+// the current period
+func (s *ContractService) nextContractNumber(ctx context.Context, tenantID string) (string, error) {
+	settings, err := s.settings.Get(ctx, tenantID)
+	if err != nil {
+		return "", err
+	}
+	prefix, suffix := models.ContractNumberParts(settings.ContractNumberPattern, time.Now())
+	last, err := s.contractRepo.LastContractSequence(ctx, tenantID, prefix, suffix)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s%05d%s", prefix, last+1, suffix), nil
+}
+
+// roundingMode returns how the tenant's contract amounts are rounded
+func (s *ContractService) roundingMode(ctx context.Context, tenantID string) (models.RoundingMode, error) {
+	settings, err := s.settings.Get(ctx, tenantID)
+	if err != nil {
+		return "", err
+	}
+	return settings.RoundingMode, nil
+}
+
+// isUniqueViolation reports whether err is an Oracle unique constraint
+// violation (ORA-00001)
+func isUniqueViolation(err error) bool {
+	return strings.Contains(err.Error(), "ORA-00001") || strings.Contains(err.Error(), "unique constraint")
+}
+
 // GetByID retrieves a contract by ID
 func (s *ContractService) GetByID(ctx context.Context, tenantID string, id int64) (*models.Contract, error) {
 	return s.contractRepo.GetByID(ctx, tenantID, id)
@@ -53,6 +121,21 @@ func (s *ContractService) List(ctx context.Context, tenantID string, params mode
 	return s.contractRepo.List(ctx, tenantID, params, search)
 }
 
+// ListKeyset retrieves a page of contracts, newest first, following
+// params.After, and the cursor of the next page if there is one
+func (s *ContractService) ListKeyset(ctx context.Context, tenantID string, params models.KeysetParams, search models.SearchParams) ([]models.Contract, *models.ListCursor, error) {
+	return s.contractRepo.ListKeyset(ctx, tenantID, params, search)
+}
+
+// ListTags retrieves the tenant's tags starting with prefix, with the number
+// of contracts carrying each, for autocomplete
+func (s *ContractService) ListTags(ctx context.Context, tenantID, prefix string, limit int) ([]models.TagUsage, error) {
+	if limit < 1 {
+		limit = DefaultTagLimit
+	}
+	return s.contractRepo.ListTags(ctx, tenantID, strings.ToLower(strings.TrimSpace(prefix)), min(limit, MaxTagLimit))
+}
+
 // Update updates a contract
 func (s *ContractService) Update(ctx context.Context, tenantID string, id int64, req *models.UpdateContractRequest, updatedBy string) (*models.Contract, error) {
 	existing, err := s.contractRepo.GetByID(ctx, tenantID, id)
@@ -63,12 +146,16 @@ func (s *ContractService) Update(ctx context.Context, tenantID string, id int64,
 		return nil, ErrContractNotFound
 	}
 
-	// Only allow updates on DRAFT or PENDING contracts
-	if existing.Status != models.ContractStatusDraft && existing.Status != models.ContractStatusPending {
+	// Only allow updates on DRAFT or PENDING contracts, except to tags
+	if existing.Status != models.ContractStatusDraft && existing.Status != models.ContractStatusPending && !req.TagsOnly() {
 		return nil, fmt.Errorf("%w: cannot update contract in %s status", ErrContractCannotUpdate, existing.Status)
 	}
 
-	contract, err := s.contractRepo.Update(ctx, tenantID, id, req, updatedBy)
+	rounding, err := s.roundingMode(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	contract, err := s.contractRepo.Update(ctx, tenantID, id, req, updatedBy, rounding)
 	if err != nil {
 		return nil, err
 	}
@@ -79,12 +166,93 @@ func (s *ContractService) Update(ctx context.Context, tenantID string, id int64,
 		Action:      models.HistoryActionUpdate,
 		PerformedBy: updatedBy,
 	}); err != nil {
-		log.Printf("failed to record contract update history (tenant=%s, contractID=%d, performedBy=%s): %v", tenantID, id, updatedBy, err)
+		requestctx.Logger(ctx).Warn("failed to record contract update history", "contract_id", id, "performed_by", updatedBy, "error", err)
+	}
+	s.recordPercentChange(ctx, tenantID, id, "discount_pct", existing.DiscountPct, req.DiscountPct, updatedBy)
+	s.recordPercentChange(ctx, tenantID, id, "tax_pct", existing.TaxPct, req.TaxPct, updatedBy)
+	if req.Tags != nil {
+		s.recordTagChange(ctx, tenantID, id, existing.Tags, contract.Tags, updatedBy)
 	}
 
 	return contract, nil
 }
 
+// recordTagChange records a history entry when an update changed the tags
+func (s *ContractService) recordTagChange(ctx context.Context, tenantID string, id int64, old, updated []string, updatedBy string) {
+	if slices.Equal(old, updated) {
+		return
+	}
+	if _, err := s.historyRepo.Create(ctx, tenantID, &models.CreateHistoryRequest{
+		ContractID:   id,
+		Action:       models.HistoryActionUpdate,
+		FieldChanged: "tags",
+		OldValue:     strings.Join(old, ","),
+		NewValue:     strings.Join(updated, ","),
+		PerformedBy:  updatedBy,
+	}); err != nil {
+		requestctx.Logger(ctx).Warn("failed to record contract tag history", "contract_id", id, "performed_by", updatedBy, "error", err)
+	}
+}
+
+// recordPercentChange records a history entry when an update changed a
+// percentage the contract totals are computed from
+func (s *ContractService) recordPercentChange(ctx context.Context, tenantID string, id int64, field string, old decimal.Decimal, updated *decimal.Decimal, updatedBy string) {
+	if updated == nil || updated.Equal(old) {
+		return
+	}
+	if _, err := s.historyRepo.Create(ctx, tenantID, &models.CreateHistoryRequest{
+		ContractID:   id,
+		Action:       models.HistoryActionUpdate,
+		FieldChanged: field,
+		OldValue:     old.String(),
+		NewValue:     updated.String(),
+		PerformedBy:  updatedBy,
+	}); err != nil {
+		requestctx.Logger(ctx).Warn("failed to record contract percentage history", "contract_id", id, "field", field, "performed_by", updatedBy, "error", err)
+	}
+}
+
+// BillingSchedule previews when the contract's total will be invoiced and
+// for how much. Due dates follow the contract's payment terms when they are
+// NET<days>, and are the period starts otherwise.
+func (s *ContractService) BillingSchedule(ctx context.Context, tenantID string, id int64) (*models.BillingSchedule, error) {
+	contract, err := s.contractRepo.GetByID(ctx, tenantID, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrContractNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	rounding, err := s.roundingMode(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule := &models.BillingSchedule{
+		ContractID:   contract.ID,
+		BillingCycle: contract.BillingCycle,
+		PaymentTerms: contract.PaymentTerms,
+		Total:        contract.TotalValue,
+	}
+	netDays, ok := billing.ParsePaymentTerms(contract.PaymentTerms)
+	if ok {
+		schedule.NetDays = &netDays
+	}
+	schedule.Periods, err = billing.Schedule(billing.Terms{
+		Start:          contract.StartDate,
+		DurationMonths: contract.DurationMonths,
+		End:            contract.EndDate,
+		Cycle:          contract.BillingCycle,
+		Total:          contract.TotalValue,
+		NetDays:        netDays,
+		Rounding:       rounding,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCannotBill, err)
+	}
+	return schedule, nil
+}
+
 // UpdateStatus updates the contract status
 func (s *ContractService) UpdateStatus(ctx context.Context, tenantID string, id int64, newStatus models.ContractStatus, updatedBy, ipAddress string) error {
 	existing, err := s.contractRepo.GetByID(ctx, tenantID, id)
@@ -114,7 +282,7 @@ func (s *ContractService) UpdateStatus(ctx context.Context, tenantID string, id
 		PerformedBy:  updatedBy,
 		IPAddress:    ipAddress,
 	}); err != nil {
-		log.Printf("failed to record contract status change history (tenant=%s, contractID=%d, action=STATUS_CHANGE, performedBy=%s): %v", tenantID, id, updatedBy, err)
+		requestctx.Logger(ctx).Warn("failed to record contract status change history", "contract_id", id, "performed_by", updatedBy, "error", err)
 	}
 
 	return nil
@@ -146,7 +314,17 @@ func (s *ContractService) Sign(ctx context.Context, tenantID string, id int64, s
 		PerformedBy: signedBy,
 		IPAddress:   ipAddress,
 	}); err != nil {
-		log.Printf("failed to record contract sign history (tenant=%s, contractID=%d, action=SIGN, performedBy=%s): %v", tenantID, id, signedBy, err)
+		requestctx.Logger(ctx).Warn("failed to record contract sign history", "contract_id", id, "performed_by", signedBy, "error", err)
+	}
+
+	if s.notifier != nil {
+		signedAt := time.Now()
+		s.notifier.Notify(ctx, tenantID, models.EventContractSigned, models.ContractSignedEvent{
+			ContractID:     id,
+			ContractNumber: existing.ContractNumber,
+			SignedBy:       signedBy,
+			SignedAt:       &signedAt,
+		})
 	}
 
 	return nil
@@ -157,6 +335,18 @@ func (s *ContractService) GetHistory(ctx context.Context, tenantID string, contr
 	return s.historyRepo.GetByContractID(ctx, tenantID, contractID, params)
 }
 
+// ListItems retrieves the items of a contract
+func (s *ContractService) ListItems(ctx context.Context, tenantID string, contractID int64) ([]models.ContractItem, error) {
+	contract, err := s.contractRepo.GetByID(ctx, tenantID, contractID)
+	if err != nil {
+		return nil, err
+	}
+	if contract == nil {
+		return nil, ErrContractNotFound
+	}
+	return contract.Items, nil
+}
+
 // AddItem adds an item to a contract
 func (s *ContractService) AddItem(ctx context.Context, tenantID string, contractID int64, req *models.CreateContractItemRequest, createdBy string) (*models.ContractItem, error) {
 	existing, err := s.contractRepo.GetByID(ctx, tenantID, contractID)
@@ -172,7 +362,11 @@ func (s *ContractService) AddItem(ctx context.Context, tenantID string, contract
 		return nil, fmt.Errorf("%w: can only add items to contracts in DRAFT status", ErrCannotAddItem)
 	}
 
-	item, err := s.contractRepo.AddItem(ctx, tenantID, contractID, req, createdBy)
+	rounding, err := s.roundingMode(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	item, err := s.contractRepo.AddItem(ctx, tenantID, contractID, req, createdBy, rounding)
 	if err != nil {
 		return nil, err
 	}
@@ -185,7 +379,7 @@ func (s *ContractService) AddItem(ctx context.Context, tenantID string, contract
 		NewValue:     fmt.Sprintf("Added item with service_id=%d", req.ServiceID),
 		PerformedBy:  createdBy,
 	}); err != nil {
-		log.Printf("failed to record contract add item history (tenant=%s, contractID=%d, itemServiceID=%d, performedBy=%s): %v", tenantID, contractID, req.ServiceID, createdBy, err)
+		requestctx.Logger(ctx).Warn("failed to record contract add item history", "contract_id", contractID, "service_id", req.ServiceID, "performed_by", createdBy, "error", err)
 	}
 
 	return item, nil
@@ -206,7 +400,11 @@ func (s *ContractService) DeleteItem(ctx context.Context, tenantID string, contr
 		return fmt.Errorf("%w: can only delete items from contracts in DRAFT status", ErrCannotDeleteItem)
 	}
 
-	if err := s.contractRepo.DeleteItem(ctx, tenantID, contractID, itemID, deletedBy); err != nil {
+	rounding, err := s.roundingMode(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	if err := s.contractRepo.DeleteItem(ctx, tenantID, contractID, itemID, deletedBy, rounding); err != nil {
 		return err
 	}
 
@@ -218,7 +416,7 @@ func (s *ContractService) DeleteItem(ctx context.Context, tenantID string, contr
 		NewValue:     fmt.Sprintf("Removed item_id=%d", itemID),
 		PerformedBy:  deletedBy,
 	}); err != nil {
-		log.Printf("failed to record contract delete item history (tenant=%s, contractID=%d, itemID=%d, performedBy=%s): %v", tenantID, contractID, itemID, deletedBy, err)
+		requestctx.Logger(ctx).Warn("failed to record contract delete item history", "contract_id", contractID, "item_id", itemID, "performed_by", deletedBy, "error", err)
 	}
 
 	return nil
@@ -0,0 +1,255 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/zlovtnik/gprint/internal/models"
+	"github.com/zlovtnik/gprint/pkg/requestctx"
+)
+
+var (
+	// ErrImportTooLarge indicates the import file exceeds the size or row limit
+	ErrImportTooLarge = errors.New("import file is too large")
+
+	// ErrInvalidImportFile indicates the import file is not a usable CSV
+	ErrInvalidImportFile = errors.New("invalid import file")
+)
+
+// CustomerImportConfig limits customer imports
+type CustomerImportConfig struct {
+	MaxBytes  int64 // Largest accepted file
+	MaxRows   int   // Most data rows accepted
+	BatchSize int   // Customers inserted per transaction; at most 1000
+}
+
+// importRow is a validated row waiting to be created
+type importRow struct {
+	line int
+	req  *models.CreateCustomerRequest
+}
+
+// Import creates customers from a CSV file with a models.CustomerImportColumns
+// header. Every row is validated like a create request before anything is
+// written, so a file over the limits or with a broken header creates
+// nothing. Rows whose customer_code exists, or repeats an earlier row, are
+// skipped. Valid rows are inserted in transactions of BatchSize; a batch
+// that fails is rolled back and its rows reported as failed. With dryRun
+// nothing is written and Created counts the rows that would be.
+func (s *CustomerService) Import(ctx context.Context, tenantID string, file io.Reader, dryRun bool, createdBy string) (*models.CustomerImportReport, error) {
+	report := &models.CustomerImportReport{DryRun: dryRun, Errors: []models.CustomerImportRowError{}}
+
+	rows, err := s.readImport(file, report)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err = s.skipExistingCodes(ctx, tenantID, rows, report)
+	if err != nil {
+		return nil, err
+	}
+
+	if dryRun {
+		report.Created = len(rows)
+	} else {
+		for batch := range slices.Chunk(rows, s.importCfg.BatchSize) {
+			reqs := make([]*models.CreateCustomerRequest, len(batch))
+			for i, row := range batch {
+				reqs[i] = row.req
+			}
+			if err := s.repo.CreateBatch(ctx, tenantID, reqs, createdBy); err != nil {
+				if ctx.Err() != nil {
+					return nil, err
+				}
+				requestctx.Logger(ctx).Error("customer import batch failed",
+					"first_line", batch[0].line, "last_line", batch[len(batch)-1].line, "error", err)
+				for _, row := range batch {
+					report.Errors = append(report.Errors, models.CustomerImportRowError{
+						Row:     row.line,
+						Message: "not imported: its batch could not be saved and was rolled back",
+					})
+				}
+				report.Failed += len(batch)
+				continue
+			}
+			report.Created += len(batch)
+		}
+	}
+
+	slices.SortStableFunc(report.Errors, func(a, b models.CustomerImportRowError) int { return a.Row - b.Row })
+	return report, nil
+}
+
+// readImport parses and validates the file, recording rejected rows and
+// in-file duplicates in report, and returns the rows to create
+func (s *CustomerService) readImport(file io.Reader, report *models.CustomerImportReport) ([]importRow, error) {
+	r := csv.NewReader(&cappedReader{r: file, max: s.importCfg.MaxBytes})
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: the file is empty", ErrInvalidImportFile)
+	}
+	if err != nil {
+		return nil, importReadError(err)
+	}
+	columns, err := importColumns(header)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []importRow
+	seen := make(map[string]bool)
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil && !errors.Is(err, csv.ErrFieldCount) {
+			return nil, importReadError(err)
+		}
+		line, _ := r.FieldPos(0)
+
+		report.Rows++
+		if report.Rows > s.importCfg.MaxRows {
+			return nil, fmt.Errorf("%w: more than %d rows", ErrImportTooLarge, s.importCfg.MaxRows)
+		}
+		if err != nil {
+			report.Failed++
+			report.Errors = append(report.Errors, models.CustomerImportRowError{
+				Row:     line,
+				Message: fmt.Sprintf("expected %d fields, got %d", len(header), len(record)),
+			})
+			continue
+		}
+
+		req := importRequest(columns, record)
+		if problems := req.Validate(); len(problems) > 0 {
+			report.Failed++
+			for _, p := range problems {
+				// The CSV column is "type", not the JSON field name
+				field, message := p.Field, p.Message
+				if field == "customer_type" {
+					field, message = "type", strings.Replace(message, "customer_type", "type", 1)
+				}
+				report.Errors = append(report.Errors, models.CustomerImportRowError{Row: line, Field: field, Message: message})
+			}
+			continue
+		}
+		if seen[req.CustomerCode] {
+			report.Skipped++
+			continue
+		}
+		seen[req.CustomerCode] = true
+		rows = append(rows, importRow{line: line, req: req})
+	}
+	return rows, nil
+}
+
+// skipExistingCodes drops the rows whose customer_code is already stored
+func (s *CustomerService) skipExistingCodes(ctx context.Context, tenantID string, rows []importRow, report *models.CustomerImportReport) ([]importRow, error) {
+	kept := rows[:0]
+	for batch := range slices.Chunk(rows, s.importCfg.BatchSize) {
+		codes := make([]string, len(batch))
+		for i, row := range batch {
+			codes[i] = row.req.CustomerCode
+		}
+		existing, err := s.repo.ExistingCodes(ctx, tenantID, codes)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range batch {
+			if existing[row.req.CustomerCode] {
+				report.Skipped++
+				continue
+			}
+			kept = append(kept, row)
+		}
+	}
+	return kept, nil
+}
+
+// importColumns maps the header to column positions, rejecting unknown,
+// repeated and missing required columns
+func importColumns(header []string) (map[string]int, error) {
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff") // Spreadsheet byte order mark
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !slices.Contains(models.CustomerImportColumns, name) {
+			return nil, fmt.Errorf("%w: unknown column %q; expected %s",
+				ErrInvalidImportFile, name, strings.Join(models.CustomerImportColumns, ","))
+		}
+		if _, dup := columns[name]; dup {
+			return nil, fmt.Errorf("%w: column %q appears twice", ErrInvalidImportFile, name)
+		}
+		columns[name] = i
+	}
+	for _, name := range models.CustomerImportRequiredColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%w: missing required column %q", ErrInvalidImportFile, name)
+		}
+	}
+	return columns, nil
+}
+
+// importRequest builds the create request for a record; empty optional
+// fields are left unset
+func importRequest(columns map[string]int, record []string) *models.CreateCustomerRequest {
+	field := func(name string) string {
+		if i, ok := columns[name]; ok {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	optional := func(name string) *string {
+		if v := field(name); v != "" {
+			return &v
+		}
+		return nil
+	}
+	return &models.CreateCustomerRequest{
+		CustomerCode: field("customer_code"),
+		CustomerType: models.CustomerType(strings.ToUpper(field("type"))),
+		Name:         field("name"),
+		TradeName:    optional("trade_name"),
+		TaxID:        optional("tax_id"),
+		Email:        optional("email"),
+		Phone:        optional("phone"),
+	}
+}
+
+// importReadError classifies an error reading the file
+func importReadError(err error) error {
+	if errors.Is(err, ErrImportTooLarge) {
+		return err
+	}
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return fmt.Errorf("%w: %v", ErrInvalidImportFile, parseErr)
+	}
+	return fmt.Errorf("failed to read import file: %w", err)
+}
+
+// cappedReader fails with ErrImportTooLarge once more than max bytes are read
+type cappedReader struct {
+	r    io.Reader
+	max  int64
+	read int64
+}
+
+func (c *cappedReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.read += int64(n)
+	if c.read > c.max {
+		return n, fmt.Errorf("%w: over %d bytes", ErrImportTooLarge, c.max)
+	}
+	return n, err
+}
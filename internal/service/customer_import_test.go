@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/zlovtnik/gprint/internal/models"
+	"github.com/zlovtnik/gprint/internal/repository"
+)
+
+// newImportService builds a CustomerService over a mocked database
+func newImportService(t *testing.T, cfg CustomerImportConfig) (*CustomerService, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	repo, err := repository.NewCustomerRepository(repository.NewDB(db, repository.DBConfig{}, slog.New(slog.NewTextHandler(io.Discard, nil))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewCustomerService(repo, cfg), mock
+}
+
+// expectExistingCodes expects one customer code lookup returning existing
+func expectExistingCodes(mock sqlmock.Sqlmock, existing ...string) {
+	rows := sqlmock.NewRows([]string{"customer_code"})
+	for _, code := range existing {
+		rows.AddRow(code)
+	}
+	mock.ExpectQuery("SELECT customer_code FROM customers").WillReturnRows(rows)
+}
+
+// procOut matches an out bind of a sp_generic_* block and fills it the way
+// a successful call does
+type procOut struct{}
+
+func (procOut) Match(v driver.Value) bool {
+	out, ok := v.(sql.Out)
+	if !ok {
+		return false
+	}
+	switch dest := out.Dest.(type) {
+	case *int:
+		*dest = 1
+	case *sql.NullInt64:
+		*dest = sql.NullInt64{Int64: 1, Valid: true}
+	}
+	return true
+}
+
+// customerInsertArgs matches the binds of inserting a customer with only
+// the required columns: four binds for each of CUSTOMER_CODE, CUSTOMER_TYPE,
+// NAME and ACTIVE, then table, tenant, actor and three out binds
+func customerInsertArgs() []driver.Value {
+	args := make([]driver.Value, 0, 22)
+	for range 19 {
+		args = append(args, sqlmock.AnyArg())
+	}
+	return append(args, procOut{}, procOut{}, procOut{})
+}
+
+func TestCustomerImportDryRun(t *testing.T) {
+	svc, mock := newImportService(t, CustomerImportConfig{MaxBytes: 1 << 20, MaxRows: 100, BatchSize: 2})
+	file := "\ufeffcustomer_code, type, name, email\n" +
+		"C1,individual,Alice,\n" + // line 2
+		"C2,PERSON,Bob,\n" + // line 3: unknown type
+		"C1,COMPANY,Alice again,\n" + // line 4: repeats C1
+		"C3,company,Carol Ltd,carol@example.com\n" + // line 5
+		"C9,COMPANY,Already stored,\n" + // line 6
+		"C4,COMPANY\n" // line 7: too few fields
+
+	// Valid rows are looked up BatchSize codes at a time
+	expectExistingCodes(mock)
+	expectExistingCodes(mock, "C9")
+
+	report, err := svc.Import(context.Background(), "tenant-1", strings.NewReader(file), true, "alice")
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+
+	want := models.CustomerImportReport{DryRun: true, Rows: 6, Created: 2, Skipped: 2, Failed: 2}
+	if report.DryRun != want.DryRun || report.Rows != want.Rows || report.Created != want.Created ||
+		report.Skipped != want.Skipped || report.Failed != want.Failed {
+		t.Errorf("report = %+v, want %+v", *report, want)
+	}
+	if len(report.Errors) != 2 {
+		t.Fatalf("errors = %+v, want rows 3 and 7", report.Errors)
+	}
+	if e := report.Errors[0]; e.Row != 3 || e.Field != "type" || !strings.Contains(e.Message, "type") || strings.Contains(e.Message, "customer_type") {
+		t.Errorf("first error = %+v, want row 3 on the type column", e)
+	}
+	if e := report.Errors[1]; e.Row != 7 || !strings.Contains(e.Message, "expected 4 fields, got 2") {
+		t.Errorf("second error = %+v, want row 7 with a field count", e)
+	}
+}
+
+func TestCustomerImportBatches(t *testing.T) {
+	svc, mock := newImportService(t, CustomerImportConfig{MaxBytes: 1 << 20, MaxRows: 100, BatchSize: 2})
+	file := "customer_code,type,name\nC1,INDIVIDUAL,Alice\nC2,INDIVIDUAL,Bob\nC3,COMPANY,Carol Ltd\n"
+
+	expectExistingCodes(mock)
+	expectExistingCodes(mock)
+	// The first batch commits
+	mock.ExpectBegin()
+	mock.ExpectExec("sp_generic_insert").WithArgs(customerInsertArgs()...).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("sp_generic_insert").WithArgs(customerInsertArgs()...).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	// The second fails and is rolled back
+	mock.ExpectBegin()
+	mock.ExpectExec("sp_generic_insert").WillReturnError(errors.New("ORA-00001: unique constraint violated"))
+	mock.ExpectRollback()
+
+	report, err := svc.Import(context.Background(), "tenant-1", strings.NewReader(file), false, "alice")
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+	if report.Rows != 3 || report.Created != 2 || report.Failed != 1 || report.Skipped != 0 {
+		t.Errorf("report = %+v, want 2 created and 1 failed", *report)
+	}
+	if len(report.Errors) != 1 || report.Errors[0].Row != 4 || !strings.Contains(report.Errors[0].Message, "rolled back") {
+		t.Errorf("errors = %+v, want row 4 rolled back", report.Errors)
+	}
+}
+
+func TestCustomerImportRejectsFile(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		want error
+	}{
+		{"empty file", "", ErrInvalidImportFile},
+		{"unknown column", "customer_code,type,name,fax\n", ErrInvalidImportFile},
+		{"repeated column", "customer_code,type,name,name\n", ErrInvalidImportFile},
+		{"missing required column", "customer_code,name\nC1,Alice\n", ErrInvalidImportFile},
+		{"broken quoting", "customer_code,type,name\nC1,COMPANY,\"Acme\n", ErrInvalidImportFile},
+		{"too many rows", "customer_code,type,name\nC1,COMPANY,A\nC2,COMPANY,B\nC3,COMPANY,C\n", ErrImportTooLarge},
+		{"too many bytes", "customer_code,type,name\nC1,COMPANY," + strings.Repeat("a", 200) + "\n", ErrImportTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Nothing is looked up or written for a rejected file
+			svc, mock := newImportService(t, CustomerImportConfig{MaxBytes: 200, MaxRows: 2, BatchSize: 10})
+			_, err := svc.Import(context.Background(), "tenant-1", strings.NewReader(tt.file), false, "alice")
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Import error = %v, want %v", err, tt.want)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -10,12 +10,13 @@ import (
 
 // CustomerService handles customer business logic
 type CustomerService struct {
-	repo *repository.CustomerRepository
+	repo      *repository.CustomerRepository
+	importCfg CustomerImportConfig
 }
 
 // NewCustomerService creates a new CustomerService
-func NewCustomerService(repo *repository.CustomerRepository) *CustomerService {
-	return &CustomerService{repo: repo}
+func NewCustomerService(repo *repository.CustomerRepository, importCfg CustomerImportConfig) *CustomerService {
+	return &CustomerService{repo: repo, importCfg: importCfg}
 }
 
 // Create creates a new customer
@@ -50,7 +51,14 @@ func (s *CustomerService) List(ctx context.Context, tenantID string, params mode
 
 // Update updates a customer
 func (s *CustomerService) Update(ctx context.Context, tenantID string, id int64, req *models.UpdateCustomerRequest, updatedBy string) (*models.Customer, error) {
-	return s.repo.Update(ctx, tenantID, id, req, updatedBy)
+	customer, err := s.repo.Update(ctx, tenantID, id, req, updatedBy)
+	if err != nil {
+		return nil, notFoundAs(err, ErrCustomerNotFound)
+	}
+	if customer == nil {
+		return nil, ErrCustomerNotFound
+	}
+	return customer, nil
 }
 
 // Delete soft-deletes a customer
@@ -63,5 +71,5 @@ func (s *CustomerService) Delete(ctx context.Context, tenantID string, id int64,
 	if customer == nil {
 		return ErrCustomerNotFound
 	}
-	return s.repo.Delete(ctx, tenantID, id, deletedBy)
+	return notFoundAs(s.repo.Delete(ctx, tenantID, id, deletedBy), ErrCustomerNotFound)
 }
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/zlovtnik/gprint/internal/mail"
+	"github.com/zlovtnik/gprint/internal/models"
+	"github.com/zlovtnik/gprint/internal/repository"
+)
+
+// PrintOutputOpener opens the output of a completed print job; PrintService implements it
+type PrintOutputOpener interface {
+	OpenOutput(ctx context.Context, tenantID string, jobID int64) (*PrintJobDownload, error)
+}
+
+// EmailNotifierConfig holds tunables for print job notification emails
+type EmailNotifierConfig struct {
+	// AttachmentMaxBytes is the largest output attached to the email; larger
+	// output is linked instead. Zero always links.
+	AttachmentMaxBytes int64
+	// LinkBaseURL is the public API address used to build download links
+	LinkBaseURL  string
+	MaxAttempts  int           // Send attempts per email; defaults to 3
+	RetryBackoff time.Duration // Delay after the first failed attempt, doubling; defaults to 30s
+}
+
+// printJobEmailData is the template input for print job emails
+type printJobEmailData struct {
+	Job      models.PrintJobResponse
+	Attached bool
+	Link     string
+}
+
+var (
+	printJobCompletedSubject = template.Must(template.New("completed_subject").Parse(
+		`Print job #{{.Job.ID}} is ready`))
+	printJobCompletedBody = template.Must(template.New("completed_body").Parse(
+		`Your print job #{{.Job.ID}} for contract {{.Job.ContractID}} has finished.
+
+Format: {{.Job.Format}}
+{{- if .Job.PageCount}}
+Pages:  {{.Job.PageCount}}
+{{- end}}
+
+{{if .Attached}}The document is attached to this email.
+{{- else if .Link}}Download the document here: {{.Link}}
+{{- else}}The document is available from the print jobs page.
+{{- end}}
+`))
+	printJobFailedSubject = template.Must(template.New("failed_subject").Parse(
+		`Print job #{{.Job.ID}} failed`))
+	printJobFailedBody = template.Must(template.New("failed_body").Parse(
+		`Your print job #{{.Job.ID}} for contract {{.Job.ContractID}} could not be completed
+{{- if .Job.RetryCount}} after {{.Job.RetryCount}} retries{{end}}.
+{{if .Job.ErrorMessage}}
+Error: {{.Job.ErrorMessage}}
+{{end}}
+You can retry the job from the print jobs page.
+`))
+)
+
+// EmailNotifier emails the requester of a print job when it completes or
+// fails, provided they opted in through their notification preferences
+type EmailNotifier struct {
+	repo    *repository.NotificationRepository
+	sender  mail.Sender
+	outputs PrintOutputOpener
+	cfg     EmailNotifierConfig
+	logger  *slog.Logger
+}
+
+// NewEmailNotifier creates a new EmailNotifier. Until SetOutputs is called,
+// completed jobs are always linked rather than attached.
+func NewEmailNotifier(repo *repository.NotificationRepository, sender mail.Sender, cfg EmailNotifierConfig, logger *slog.Logger) *EmailNotifier {
+	if cfg.MaxAttempts < 1 {
+		cfg.MaxAttempts = 3
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = 30 * time.Second
+	}
+	cfg.LinkBaseURL = strings.TrimRight(cfg.LinkBaseURL, "/")
+	return &EmailNotifier{repo: repo, sender: sender, cfg: cfg, logger: logger}
+}
+
+// SetOutputs sets where job output is read from for attachments. It must be
+// called before the notifier receives events; PrintService itself needs the
+// notifier at construction, hence the separate step.
+func (n *EmailNotifier) SetOutputs(outputs PrintOutputOpener) {
+	n.outputs = outputs
+}
+
+// Notify implements EventNotifier. Only print job events are emailed; the
+// email is sent in the background.
+func (n *EmailNotifier) Notify(ctx context.Context, tenantID string, event models.EventType, data any) {
+	if event != models.EventPrintJobCompleted && event != models.EventPrintJobFailed {
+		return
+	}
+	job, ok := data.(models.PrintJobResponse)
+	if !ok {
+		return
+	}
+	go n.notifyJob(context.WithoutCancel(ctx), tenantID, event, job)
+}
+
+// notifyJob looks up the requester's preference and sends the email, retrying
+// a bounded number of times. Failures are logged only.
+func (n *EmailNotifier) notifyJob(ctx context.Context, tenantID string, event models.EventType, job models.PrintJobResponse) {
+	pref, err := n.repo.GetPreference(ctx, tenantID, job.RequestedBy)
+	if err != nil {
+		n.logger.Error("failed to load notification preference",
+			"tenant_id", tenantID,
+			"user_id", job.RequestedBy,
+			"error", err,
+		)
+		return
+	}
+	if pref == nil || !pref.Wants(event) {
+		return
+	}
+
+	msg, err := n.buildMessage(ctx, tenantID, event, job)
+	if err != nil {
+		n.logger.Error("failed to build notification email",
+			"job_id", job.ID,
+			"tenant_id", tenantID,
+			"event", event,
+			"error", err,
+		)
+		return
+	}
+	msg.To = []string{pref.Email}
+
+	delay := n.cfg.RetryBackoff
+	for attempt := 1; ; attempt++ {
+		err := n.sender.Send(ctx, msg)
+		if err == nil {
+			return
+		}
+		final := attempt >= n.cfg.MaxAttempts
+		n.logger.Warn("failed to send notification email",
+			"job_id", job.ID,
+			"tenant_id", tenantID,
+			"event", event,
+			"attempt", attempt,
+			"final", final,
+			"error", err,
+		)
+		if final {
+			return
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// buildMessage renders the email for a job event, attaching the output when
+// it is small enough and linking to it otherwise
+func (n *EmailNotifier) buildMessage(ctx context.Context, tenantID string, event models.EventType, job models.PrintJobResponse) (*mail.Message, error) {
+	data := printJobEmailData{Job: job}
+	subjectTmpl, bodyTmpl := printJobFailedSubject, printJobFailedBody
+	msg := &mail.Message{}
+
+	if event == models.EventPrintJobCompleted {
+		subjectTmpl, bodyTmpl = printJobCompletedSubject, printJobCompletedBody
+		if attachment := n.attachment(ctx, tenantID, job); attachment != nil {
+			msg.Attachments = []mail.Attachment{*attachment}
+			data.Attached = true
+		} else if n.cfg.LinkBaseURL != "" {
+			data.Link = fmt.Sprintf("%s/api/v1/print-jobs/%d/download", n.cfg.LinkBaseURL, job.ID)
+		}
+	}
+
+	var subject, body bytes.Buffer
+	if err := subjectTmpl.Execute(&subject, data); err != nil {
+		return nil, err
+	}
+	if err := bodyTmpl.Execute(&body, data); err != nil {
+		return nil, err
+	}
+	msg.Subject = subject.String()
+	msg.Body = body.String()
+	return msg, nil
+}
+
+// attachment reads the job output when it fits under the attachment limit.
+// It returns nil when the output should be linked instead.
+func (n *EmailNotifier) attachment(ctx context.Context, tenantID string, job models.PrintJobResponse) *mail.Attachment {
+	limit := n.cfg.AttachmentMaxBytes
+	if n.outputs == nil || limit <= 0 || job.FileSize <= 0 || job.FileSize > limit {
+		return nil
+	}
+
+	download, err := n.outputs.OpenOutput(ctx, tenantID, job.ID)
+	if err != nil {
+		n.logger.Warn("failed to open print output for email, linking instead",
+			"job_id", job.ID,
+			"tenant_id", tenantID,
+			"error", err,
+		)
+		return nil
+	}
+	defer download.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(download.Body, limit+1))
+	if err != nil || int64(len(data)) > limit {
+		n.logger.Warn("failed to read print output for email, linking instead",
+			"job_id", job.ID,
+			"tenant_id", tenantID,
+			"error", err,
+		)
+		return nil
+	}
+	return &mail.Attachment{
+		FileName:    download.FileName,
+		ContentType: download.ContentType,
+		Data:        data,
+	}
+}
@@ -1,9 +1,19 @@
 package service
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zlovtnik/gprint/internal/models"
+	"github.com/zlovtnik/gprint/internal/repository"
+)
 
 // Sentinel errors for service operations
 var (
+	// ErrQueryTimeout indicates a database statement exceeded the statement timeout
+	ErrQueryTimeout = repository.ErrQueryTimeout
+
 	// ErrNotFound indicates the requested resource was not found
 	ErrNotFound = errors.New("resource not found")
 
@@ -37,16 +47,96 @@ var (
 	// ErrCannotDeleteItem indicates items cannot be deleted from the contract in its current status
 	ErrCannotDeleteItem = errors.New("cannot delete items from contract in current status")
 
+	// ErrCannotBill indicates the contract's terms do not give a billing schedule
+	ErrCannotBill = errors.New("contract cannot be billed")
+
+	// ErrInvoiceNotFound indicates the invoice was not found
+	ErrInvoiceNotFound = errors.New("invoice not found")
+
+	// ErrInvalidInvoiceTransition indicates an invoice status change other than DRAFT to ISSUED or ISSUED to PAID
+	ErrInvalidInvoiceTransition = errors.New("invalid invoice status transition")
+
+	// ErrAttachmentNotFound indicates the attachment does not exist on the contract
+	ErrAttachmentNotFound = errors.New("attachment not found")
+
+	// ErrAttachmentTooLarge indicates an upload over the configured size limit
+	ErrAttachmentTooLarge = errors.New("attachment is too large")
+
+	// ErrAttachmentTypeNotAllowed indicates an upload whose content type is not allowed
+	ErrAttachmentTypeNotAllowed = errors.New("attachment content type is not allowed")
+
+	// ErrInvalidAttachment indicates an empty upload or one without a usable file name
+	ErrInvalidAttachment = errors.New("invalid attachment")
+
+	// ErrAttachmentOverrideRequired indicates a delete from a signed contract without the override role
+	ErrAttachmentOverrideRequired = errors.New("deleting an attachment of a signed contract requires the override role")
+
+	// ErrAttachmentChecksumMismatch indicates stored content that no longer matches its checksum
+	ErrAttachmentChecksumMismatch = errors.New("attachment content does not match its checksum")
+
+	// ErrAttachmentFileMissing indicates an attachment whose file is gone from storage
+	ErrAttachmentFileMissing = errors.New("attachment file is missing from storage")
+
+	// ErrTemplateNotFound indicates the contract template was not found
+	ErrTemplateNotFound = errors.New("template not found")
+
+	// ErrDuplicateTemplate indicates a template with the same code already exists
+	ErrDuplicateTemplate = errors.New("template with this code already exists")
+
+	// ErrTemplateInUse indicates a delete of a template generated contracts refer to
+	ErrTemplateInUse = errors.New("template is referenced by generated contracts; deactivate it instead")
+
+	// ErrDefaultTemplate indicates a change that would leave the tenant without an active default template
+	ErrDefaultTemplate = errors.New("the default template must stay active; mark another template as default first")
+
+	// ErrContentTampered indicates a generated contract no longer matches its content hash
+	ErrContentTampered = errors.New("generated contract content does not match its hash")
+
 	// ErrJobNotCompleted indicates the print job is not yet completed
 	ErrJobNotCompleted = errors.New("print job is not completed")
 
+	// ErrOutputPurged indicates the output file was removed by the retention policy
+	ErrOutputPurged = errors.New("print output purged by retention policy")
+
 	// ErrOutputFileNotFound indicates the output file is missing
 	ErrOutputFileNotFound = errors.New("output file not found")
 
+	// ErrPrintJobNotRetryable indicates the print job is not in a status that allows a retry
+	ErrPrintJobNotRetryable = errors.New("print job can only be retried when failed")
+
+	// ErrPrintJobNotCancellable indicates the print job already reached a final status
+	ErrPrintJobNotCancellable = errors.New("print job cannot be cancelled in current status")
+
+	// ErrPrintJobNotQueued indicates the operation requires the print job to still be queued
+	ErrPrintJobNotQueued = errors.New("print job is no longer queued")
+
+	// ErrWatermarkRequired indicates the user may not disable the draft watermark
+	ErrWatermarkRequired = errors.New("only print administrators may disable the draft watermark")
+
+	// ErrWebhookNotFound indicates the webhook was not found
+	ErrWebhookNotFound = errors.New("webhook not found")
+
+	// ErrNotificationNotFound indicates the notification does not exist in the caller's list
+	ErrNotificationNotFound = errors.New("notification not found")
+
+	// ErrFeatureNotFound indicates the feature flag is not a known one
+	ErrFeatureNotFound = errors.New("feature flag not found")
+
 	// ErrFormatNotSupported indicates the requested format is not supported
 	ErrFormatNotSupported = errors.New("format not supported")
 )
 
+// StaleGenerationError is returned when printing a contract that changed
+// after its latest generation without asking for a regeneration
+type StaleGenerationError struct {
+	models.StaleGeneration
+}
+
+func (e *StaleGenerationError) Error() string {
+	return fmt.Sprintf("contract %d was updated at %s, after its latest generation at %s",
+		e.ContractID, e.ContractUpdatedAt.Format(time.RFC3339), e.GeneratedAt.Format(time.RFC3339))
+}
+
 // ContractError wraps a contract-related error with additional context
 type ContractError struct {
 	Op      string // Operation that failed
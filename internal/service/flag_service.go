@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/zlovtnik/gprint/internal/models"
+	"github.com/zlovtnik/gprint/internal/repository"
+)
+
+// flagCacheTTL bounds how long a flag change made on another instance
+// takes to apply here; changes on this instance apply at once
+const flagCacheTTL = 30 * time.Second
+
+// FlagService answers whether a feature is enabled for a tenant. Flags are
+// checked on every gated request, so each tenant's flags are cached briefly
+// and the entry is dropped when one of them is changed.
+type FlagService struct {
+	repo     *repository.FeatureFlagRepository
+	defaults map[string]bool
+
+	mu    sync.Mutex
+	cache map[string]cachedFlags
+}
+
+type cachedFlags struct {
+	flags   map[string]models.FeatureFlag
+	expires time.Time
+}
+
+// NewFlagService creates a new FlagService. The features named in
+// enabledByDefault are on for tenants that have not set them.
+func NewFlagService(repo *repository.FeatureFlagRepository, enabledByDefault []string) *FlagService {
+	defaults := make(map[string]bool, len(enabledByDefault))
+	for _, name := range enabledByDefault {
+		defaults[name] = true
+	}
+	return &FlagService{repo: repo, defaults: defaults, cache: make(map[string]cachedFlags)}
+}
+
+// IsEnabled reports whether flag is enabled for the tenant. Unknown flags
+// are disabled.
+func (s *FlagService) IsEnabled(ctx context.Context, tenantID, flag string) (bool, error) {
+	if !models.IsFeature(flag) {
+		return false, nil
+	}
+	flags, err := s.flags(ctx, tenantID)
+	if err != nil {
+		return false, err
+	}
+	if f, ok := flags[flag]; ok {
+		return f.Enabled, nil
+	}
+	return s.defaults[flag], nil
+}
+
+// List returns the effective state of every known flag for the tenant
+func (s *FlagService) List(ctx context.Context, tenantID string) ([]models.FeatureFlag, error) {
+	flags, err := s.flags(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]models.FeatureFlag, 0, len(models.Features))
+	for _, name := range models.Features {
+		f, ok := flags[name]
+		if !ok {
+			f = models.FeatureFlag{Name: name, Enabled: s.defaults[name], Source: models.FeatureSourceDefault}
+		}
+		result = append(result, f)
+	}
+	return result, nil
+}
+
+// Set enables or disables a known flag for the tenant
+func (s *FlagService) Set(ctx context.Context, tenantID, flag string, enabled bool, updatedBy string) error {
+	if !models.IsFeature(flag) {
+		return ErrFeatureNotFound
+	}
+	err := s.repo.Set(ctx, tenantID, flag, enabled, updatedBy)
+	s.invalidate(tenantID)
+	return err
+}
+
+// Reset removes the tenant's setting for a known flag so the server default applies
+func (s *FlagService) Reset(ctx context.Context, tenantID, flag string) error {
+	if !models.IsFeature(flag) {
+		return ErrFeatureNotFound
+	}
+	err := s.repo.Delete(ctx, tenantID, flag)
+	s.invalidate(tenantID)
+	return err
+}
+
+func (s *FlagService) invalidate(tenantID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.cache, tenantID)
+}
+
+func (s *FlagService) flags(ctx context.Context, tenantID string) (map[string]models.FeatureFlag, error) {
+	now := time.Now()
+	s.mu.Lock()
+	entry, ok := s.cache[tenantID]
+	s.mu.Unlock()
+	if ok && now.Before(entry.expires) {
+		return entry.flags, nil
+	}
+
+	flags, err := s.repo.List(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cache[tenantID] = cachedFlags{flags: flags, expires: now.Add(flagCacheTTL)}
+	return flags, nil
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/zlovtnik/gprint/internal/metrics"
+	"github.com/zlovtnik/gprint/internal/repository"
+	"github.com/zlovtnik/gprint/pkg/requestctx"
+)
+
+// generationCleanupJob is the job_locks row the cleanup is claimed through
+const generationCleanupJob = "generation_cleanup"
+
+// GenerationCleanupConfig configures GenerationCleanupService
+type GenerationCleanupConfig struct {
+	Tenants    []string      // Tenants cleaned up; empty means every tenant with expired generations
+	InstanceID string        // Lock owner ID; generated from host and PID when empty
+	LockTTL    time.Duration // How long a claimed run keeps other instances out
+}
+
+// GenerationCleanupService removes expired generated contracts on a
+// schedule, once per run across all instances
+type GenerationCleanupService struct {
+	repo       *repository.ContractGenerationRepository
+	locks      *repository.JobLockRepository
+	tenants    []string
+	instanceID string
+	lockTTL    time.Duration
+}
+
+// NewGenerationCleanupService creates a new GenerationCleanupService
+func NewGenerationCleanupService(repo *repository.ContractGenerationRepository, locks *repository.JobLockRepository, cfg GenerationCleanupConfig) *GenerationCleanupService {
+	instanceID := cfg.InstanceID
+	if instanceID == "" {
+		instanceID = defaultInstanceID()
+	}
+	return &GenerationCleanupService{
+		repo:       repo,
+		locks:      locks,
+		tenants:    cfg.Tenants,
+		instanceID: instanceID,
+		lockTTL:    cfg.LockTTL,
+	}
+}
+
+// RunDue performs the cleanup run due at due, unless another instance
+// claimed it first, and returns the generated contracts deleted per tenant.
+// ran is false when the run was left to another instance. A tenant whose
+// cleanup fails is logged and skipped.
+func (s *GenerationCleanupService) RunDue(ctx context.Context, due time.Time) (deleted map[string]int, ran bool, err error) {
+	claimed, err := s.locks.Claim(ctx, generationCleanupJob, s.instanceID, due, s.lockTTL)
+	if err != nil || !claimed {
+		return nil, false, err
+	}
+	defer func() {
+		if err := s.locks.Release(ctx, generationCleanupJob, s.instanceID); err != nil {
+			requestctx.Logger(ctx).Warn("failed to release generation cleanup lock", "error", err)
+		}
+	}()
+
+	tenants := s.tenants
+	if len(tenants) == 0 {
+		if tenants, err = s.repo.ListExpiredGenerationTenants(ctx); err != nil {
+			return nil, true, err
+		}
+	}
+
+	deleted = make(map[string]int, len(tenants))
+	total := 0
+	for _, tenantID := range tenants {
+		if ctx.Err() != nil {
+			return deleted, true, ctx.Err()
+		}
+		n, err := s.repo.CleanupExpiredGenerations(ctx, tenantID)
+		if err != nil {
+			requestctx.Logger(ctx).Warn("failed to clean up expired generations",
+				"tenant_id", tenantID, "error", err)
+			continue
+		}
+		deleted[tenantID] = n
+		total += n
+	}
+	metrics.RecordGenerationCleanup(time.Now(), total)
+	return deleted, true, nil
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/zlovtnik/gprint/internal/models"
+	"github.com/zlovtnik/gprint/internal/repository"
+	"github.com/zlovtnik/gprint/pkg/requestctx"
+)
+
+// diffIgnoredPaths differ between every two generations without the
+// document changing, so they are left out of diffs
+var diffIgnoredPaths = map[string]bool{
+	"meta.generated_at":             true,
+	"meta.generated_date_formatted": true,
+}
+
+// DiffGenerated compares two generated versions of a contract: the fields
+// whose values differ, and the items added, removed or changed, matched by
+// service. Both versions must belong to the contract. Each version read is
+// logged with the COMPARE action.
+func (s *ContractGenerationService) DiffGenerated(
+	ctx context.Context,
+	tenantID string,
+	contractID, fromID, toID int64,
+	userID, ipAddress, sessionID string,
+) (*models.GenerationDiff, error) {
+	refs, err := s.repo.GetGenerationRefs(ctx, tenantID, contractID, fromID, toID)
+	if err != nil {
+		return nil, err
+	}
+	from, okFrom := refs[fromID]
+	to, okTo := refs[toID]
+	if !okFrom || !okTo {
+		return nil, ErrNotFound
+	}
+
+	fromDoc, err := s.generatedDocument(ctx, tenantID, fromID, userID)
+	if err != nil {
+		return nil, err
+	}
+	toDoc, err := s.generatedDocument(ctx, tenantID, toID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &models.GenerationDiff{
+		ContractID:   contractID,
+		From:         from,
+		To:           to,
+		Changes:      []models.FieldChange{},
+		AddedItems:   []models.ItemChange{},
+		RemovedItems: []models.ItemChange{},
+		ChangedItems: []models.ItemChange{},
+	}
+	fromItems, _ := fromDoc["items"].([]any)
+	toItems, _ := toDoc["items"].([]any)
+	delete(fromDoc, "items")
+	delete(toDoc, "items")
+	diffValues(&diff.Changes, "", fromDoc, toDoc)
+	diffItems(diff, fromItems, toItems)
+
+	for _, id := range []int64{fromID, toID} {
+		err := s.repo.LogContractAction(ctx, repository.LogActionParams{
+			TenantID:    tenantID,
+			ContractID:  contractID,
+			GeneratedID: id,
+			Action:      string(models.GenerationActionCompare),
+			UserID:      userID,
+			IPAddress:   ipAddress,
+			SessionID:   sessionID,
+			Status:      "SUCCESS",
+		})
+		if err != nil {
+			requestctx.Logger(ctx).Warn("failed to log compare action",
+				"contract_id", contractID, "generated_id", id, "error", err)
+		}
+	}
+	return diff, nil
+}
+
+// generatedDocument reads a generated version's JSON as a generic document
+func (s *ContractGenerationService) generatedDocument(ctx context.Context, tenantID string, generatedID int64, userID string) (map[string]any, error) {
+	content, err := s.repo.GetGeneratedContent(ctx, tenantID, generatedID, userID)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(content.ContractJSON))
+	dec.UseNumber()
+	var doc map[string]any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to decode generated contract %d: %w", generatedID, err)
+	}
+	return doc, nil
+}
+
+// diffValues appends the differences between before and after under path,
+// descending into objects; any other values, arrays included, are compared
+// whole
+func diffValues(changes *[]models.FieldChange, path string, before, after any) {
+	if diffIgnoredPaths[path] {
+		return
+	}
+	oldObj, oldIsObj := before.(map[string]any)
+	newObj, newIsObj := after.(map[string]any)
+	if !oldIsObj || !newIsObj {
+		if !reflect.DeepEqual(before, after) {
+			*changes = append(*changes, models.FieldChange{Path: path, Old: before, New: after})
+		}
+		return
+	}
+
+	keys := make([]string, 0, len(oldObj)+len(newObj))
+	for k := range oldObj {
+		keys = append(keys, k)
+	}
+	for k := range newObj {
+		if _, ok := oldObj[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		child := k
+		if path != "" {
+			child = path + "." + k
+		}
+		diffValues(changes, child, oldObj[k], newObj[k])
+	}
+}
+
+// diffItems matches the items of two versions by service and records those
+// added, removed or changed, in the order of the newer version
+func diffItems(diff *models.GenerationDiff, fromItems, toItems []any) {
+	fromKeys, fromByKey := keyItems(fromItems)
+	toKeys, toByKey := keyItems(toItems)
+
+	for _, key := range toKeys {
+		item := toByKey[key]
+		old, ok := fromByKey[key]
+		if !ok {
+			diff.AddedItems = append(diff.AddedItems, models.ItemChange{Key: key, ServiceName: serviceName(item), Item: item})
+			continue
+		}
+		var changes []models.FieldChange
+		diffValues(&changes, "", old, item)
+		if len(changes) > 0 {
+			diff.ChangedItems = append(diff.ChangedItems, models.ItemChange{Key: key, ServiceName: serviceName(item), Changes: changes})
+		}
+	}
+	for _, key := range fromKeys {
+		if _, ok := toByKey[key]; !ok {
+			item := fromByKey[key]
+			diff.RemovedItems = append(diff.RemovedItems, models.ItemChange{Key: key, ServiceName: serviceName(item), Item: item})
+		}
+	}
+}
+
+// keyItems keys items by service ID, falling back to the service code, and
+// numbers repeats of a service #2, #3...
+func keyItems(items []any) ([]string, map[string]map[string]any) {
+	keys := make([]string, 0, len(items))
+	byKey := make(map[string]map[string]any, len(items))
+	seen := make(map[string]int, len(items))
+	for _, raw := range items {
+		item, ok := raw.(map[string]any)
+		if !ok {
+			continue
+		}
+		base := fmt.Sprint(item["service_id"])
+		if item["service_id"] == nil {
+			base = fmt.Sprint(item["service_code"])
+		}
+		seen[base]++
+		key := base
+		if n := seen[base]; n > 1 {
+			key = fmt.Sprintf("%s#%d", base, n)
+		}
+		keys = append(keys, key)
+		byKey[key] = item
+	}
+	return keys, byKey
+}
+
+// serviceName returns an item's service name, or "" when it has none
+func serviceName(item map[string]any) string {
+	name, _ := item["service_name"].(string)
+	return name
+}
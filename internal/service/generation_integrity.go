@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/zlovtnik/gprint/internal/models"
+	"github.com/zlovtnik/gprint/internal/repository"
+	"github.com/zlovtnik/gprint/pkg/requestctx"
+)
+
+const (
+	// integritySweepBatch is how many generated versions a sweep reads at a time
+	integritySweepBatch = 500
+	// defaultSweepRecords and maxSweepRecords bound the versions one sweep checks
+	defaultSweepRecords = 5000
+	maxSweepRecords     = 50000
+)
+
+// CheckGenerated checks a generated version of a contract against its
+// content hash without recording the check. A version of another tenant
+// returns ErrUnauthorized; one of another contract, ErrNotFound.
+func (s *ContractGenerationService) CheckGenerated(
+	ctx context.Context,
+	tenantID string,
+	contractID, generatedID int64,
+) (*models.IntegrityCheck, error) {
+	valid, err := s.VerifyContentIntegrity(ctx, tenantID, generatedID)
+	if err != nil {
+		return nil, err
+	}
+	refs, err := s.repo.GetGenerationRefs(ctx, tenantID, contractID, generatedID)
+	if err != nil {
+		return nil, err
+	}
+	ref, ok := refs[generatedID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &models.IntegrityCheck{
+		GeneratedID: generatedID,
+		ContractID:  contractID,
+		Valid:       valid,
+		ContentHash: ref.ContentHash,
+		CheckedAt:   time.Now().UTC(),
+	}, nil
+}
+
+// VerifyGenerated checks a generated version like CheckGenerated and logs
+// the check as VERIFY, FAILED with TAMPERED when the content no longer
+// matches
+func (s *ContractGenerationService) VerifyGenerated(
+	ctx context.Context,
+	tenantID string,
+	contractID, generatedID int64,
+	userID, ipAddress, sessionID string,
+) (*models.IntegrityCheck, error) {
+	check, err := s.CheckGenerated(ctx, tenantID, contractID, generatedID)
+	if err != nil {
+		return nil, err
+	}
+
+	logParams := repository.LogActionParams{
+		TenantID:    tenantID,
+		ContractID:  contractID,
+		GeneratedID: generatedID,
+		Action:      string(models.GenerationActionVerify),
+		UserID:      userID,
+		IPAddress:   ipAddress,
+		SessionID:   sessionID,
+		Status:      "SUCCESS",
+	}
+	if !check.Valid {
+		logParams.Status = "FAILED"
+		logParams.ErrorCode = "TAMPERED"
+	}
+	if err := s.repo.LogContractAction(ctx, logParams); err != nil {
+		requestctx.Logger(ctx).Warn("failed to log verification", "generated_id", generatedID, "error", err)
+	}
+	return check, nil
+}
+
+// SweepIntegrity checks a tenant's generated versions against their content
+// hashes in ID order, in batches, and returns the ones that fail. Each
+// tampered version is logged as a FAILED VERIFY by userID. A sweep stopping
+// at req.MaxRecords sets NextAfterID to resume from.
+func (s *ContractGenerationService) SweepIntegrity(
+	ctx context.Context,
+	req models.IntegritySweepRequest,
+	userID string,
+) (*models.IntegritySweep, error) {
+	limit := req.MaxRecords
+	if limit <= 0 {
+		limit = defaultSweepRecords
+	}
+	limit = min(limit, maxSweepRecords)
+
+	sweep := &models.IntegritySweep{
+		TenantID:  req.TenantID,
+		Tampered:  []models.SweptGeneration{},
+		StartedAt: time.Now().UTC(),
+	}
+	afterID := req.AfterID
+	for sweep.Checked < limit {
+		gens, err := s.repo.ListGenerationsAfter(ctx, req.TenantID, afterID, min(integritySweepBatch, limit-sweep.Checked))
+		if err != nil {
+			return nil, err
+		}
+		for _, g := range gens {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			afterID = g.GeneratedID
+			sweep.Checked++
+
+			valid, err := s.VerifyContentIntegrity(ctx, req.TenantID, g.GeneratedID)
+			switch {
+			case errors.Is(err, ErrNotFound), errors.Is(err, ErrUnauthorized):
+				sweep.Missing++
+			case err != nil:
+				return nil, err
+			case valid:
+				sweep.Valid++
+			default:
+				sweep.Tampered = append(sweep.Tampered, g)
+				s.logSweepTampered(ctx, req.TenantID, g, userID)
+			}
+		}
+		if len(gens) < integritySweepBatch {
+			break
+		}
+	}
+
+	if sweep.Checked == limit {
+		more, err := s.repo.ListGenerationsAfter(ctx, req.TenantID, afterID, 1)
+		if err != nil {
+			return nil, err
+		}
+		if len(more) > 0 {
+			sweep.NextAfterID = afterID
+		}
+	}
+	sweep.FinishedAt = time.Now().UTC()
+	return sweep, nil
+}
+
+// logSweepTampered records a tampered version found by a sweep. A failure
+// is logged rather than ending the sweep.
+func (s *ContractGenerationService) logSweepTampered(ctx context.Context, tenantID string, g models.SweptGeneration, userID string) {
+	err := s.repo.LogContractAction(ctx, repository.LogActionParams{
+		TenantID:    tenantID,
+		ContractID:  g.ContractID,
+		GeneratedID: g.GeneratedID,
+		Action:      string(models.GenerationActionVerify),
+		UserID:      userID,
+		Status:      "FAILED",
+		ErrorCode:   "TAMPERED",
+	})
+	if err != nil {
+		requestctx.Logger(ctx).Warn("failed to log tampered generation", "generated_id", g.GeneratedID, "error", err)
+	}
+}
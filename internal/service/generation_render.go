@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/zlovtnik/gprint/internal/doctemplate"
+	"github.com/zlovtnik/gprint/internal/models"
+	"github.com/zlovtnik/gprint/internal/repository"
+	"github.com/zlovtnik/gprint/pkg/requestctx"
+)
+
+// RenderGenerated renders a generated version of a contract as a document.
+// The merge data comes from the version's JSON snapshot, never from the
+// contract as it is now, and the body from the template it was generated
+// with, or doctemplate.DefaultBody when that template has none. The
+// snapshot's hash is verified first: tampered content is refused with
+// ErrContentTampered. HTML renders are logged as VIEW, other formats as
+// DOWNLOAD. A body that cannot be rendered returns a *doctemplate.Error.
+//
+// The document is streamed to the writer open returns, which is called
+// once, before the first byte, with the document's details. An error
+// returned after open was called happened partway through the document.
+func (s *ContractGenerationService) RenderGenerated(
+	ctx context.Context,
+	tenantID string,
+	contractID, generatedID int64,
+	format models.PrintFormat,
+	userID, ipAddress, sessionID string,
+	open func(*models.RenderedContract) io.Writer,
+) error {
+	refs, err := s.repo.GetGenerationRefs(ctx, tenantID, contractID, generatedID)
+	if err != nil {
+		return err
+	}
+	ref, ok := refs[generatedID]
+	if !ok {
+		return ErrNotFound
+	}
+
+	action := models.GenerationActionView
+	if format != models.PrintFormatHTML {
+		action = models.GenerationActionDownload
+	}
+	logParams := repository.LogActionParams{
+		TenantID:    tenantID,
+		ContractID:  contractID,
+		GeneratedID: generatedID,
+		Action:      string(action),
+		UserID:      userID,
+		IPAddress:   ipAddress,
+		SessionID:   sessionID,
+		Status:      "SUCCESS",
+	}
+
+	valid, err := s.VerifyContentIntegrity(ctx, tenantID, generatedID)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		logParams.Status = "FAILED"
+		logParams.ErrorCode = "TAMPERED"
+		s.logRenderAction(ctx, logParams)
+		return ErrContentTampered
+	}
+
+	content, err := s.repo.GetGeneratedContent(ctx, tenantID, generatedID, userID)
+	if err != nil {
+		return err
+	}
+	data, err := doctemplate.FromGenerated(content.ContractJSON)
+	if err != nil {
+		return err
+	}
+
+	body := doctemplate.DefaultBody
+	t, err := s.repo.GetGenerationTemplate(ctx, tenantID, generatedID)
+	switch {
+	case err == nil && t.BodyHTML != "":
+		body = t.BodyHTML
+	case err != nil && !errors.Is(err, repository.ErrNotFound):
+		return err
+	}
+	tmpl, err := doctemplate.Parse(body)
+	if err != nil {
+		return err
+	}
+	html, err := tmpl.Preview(data)
+	if err != nil {
+		return err
+	}
+
+	out := &openingWriter{open: func() io.Writer {
+		return open(&models.RenderedContract{
+			GenerationRef: ref,
+			ContractID:    contractID,
+			Format:        format,
+			ContentType:   printFormatContentTypes[format],
+		})
+	}}
+	if err := s.renderer.Render(ctx, out, html, format); err != nil {
+		return err
+	}
+	// An empty document still gets its response
+	out.writer()
+
+	s.logRenderAction(ctx, logParams)
+	return nil
+}
+
+// logRenderAction records a render in the generation log. Failing to log
+// does not fail the render.
+func (s *ContractGenerationService) logRenderAction(ctx context.Context, params repository.LogActionParams) {
+	if err := s.repo.LogContractAction(ctx, params); err != nil {
+		requestctx.Logger(ctx).Warn("failed to log render action",
+			"contract_id", params.ContractID, "generated_id", params.GeneratedID, "error", err)
+	}
+}
+
+// openingWriter calls open on the first write, or when writer is called,
+// and writes through the writer it returns
+type openingWriter struct {
+	open func() io.Writer
+	w    io.Writer
+}
+
+func (o *openingWriter) writer() io.Writer {
+	if o.w == nil {
+		o.w = o.open()
+	}
+	return o.w
+}
+
+func (o *openingWriter) Write(p []byte) (int, error) {
+	return o.writer().Write(p)
+}
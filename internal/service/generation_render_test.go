@@ -0,0 +1,56 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/zlovtnik/gprint/internal/models"
+)
+
+func TestOpeningWriterOpensOnce(t *testing.T) {
+	var buf bytes.Buffer
+	opened := 0
+	w := &openingWriter{open: func() io.Writer { opened++; return &buf }}
+	if opened != 0 {
+		t.Fatal("opened before the first write")
+	}
+	io.WriteString(w, "<p>one</p>")
+	io.WriteString(w, "<p>two</p>")
+	w.writer()
+	if opened != 1 || buf.String() != "<p>one</p><p>two</p>" {
+		t.Errorf("opened %d times, wrote %q; want once with both writes", opened, buf.String())
+	}
+
+	// Nothing written still opens when asked, so an empty document gets its response
+	opened = 0
+	(&openingWriter{open: func() io.Writer { opened++; return io.Discard }}).writer()
+	if opened != 1 {
+		t.Errorf("opened %d times, want 1", opened)
+	}
+}
+
+func TestHTMLRendererStreams(t *testing.T) {
+	html := "<html><body>" + string(bytes.Repeat([]byte("<p>clause</p>"), 10000)) + "</body></html>"
+	var buf bytes.Buffer
+	if err := (HTMLRenderer{}).Render(context.Background(), &buf, html, models.PrintFormatHTML); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if buf.String() != html {
+		t.Errorf("wrote %d bytes, want the %d bytes of the document", buf.Len(), len(html))
+	}
+
+	// Unsupported formats fail before anything is written, so the caller can still answer with an error
+	for _, format := range []models.PrintFormat{models.PrintFormatPDF, models.PrintFormatDOCX} {
+		opened := false
+		w := &openingWriter{open: func() io.Writer { opened = true; return io.Discard }}
+		if err := (HTMLRenderer{}).Render(context.Background(), w, html, format); !errors.Is(err, ErrFormatNotSupported) {
+			t.Errorf("%s: error = %v, want %v", format, err, ErrFormatNotSupported)
+		}
+		if opened {
+			t.Errorf("%s: the response was opened before failing", format)
+		}
+	}
+}
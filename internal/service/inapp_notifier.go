@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/zlovtnik/gprint/internal/models"
+)
+
+// InAppNotifier adds print job events to the in-app notification list of
+// the job's requester
+type InAppNotifier struct {
+	svc    *NotificationService
+	logger *slog.Logger
+}
+
+// NewInAppNotifier creates a new InAppNotifier
+func NewInAppNotifier(svc *NotificationService, logger *slog.Logger) *InAppNotifier {
+	return &InAppNotifier{svc: svc, logger: logger}
+}
+
+// Notify implements EventNotifier. The notification is stored in the
+// background; a failure is logged only.
+func (n *InAppNotifier) Notify(ctx context.Context, tenantID string, event models.EventType, data any) {
+	notification := inAppNotification(tenantID, event, data)
+	if notification == nil {
+		return
+	}
+	go func(ctx context.Context) {
+		if _, err := n.svc.Create(ctx, notification); err != nil {
+			n.logger.Error("failed to create notification",
+				"tenant_id", tenantID,
+				"user_id", notification.UserID,
+				"event", event,
+				"error", err,
+			)
+		}
+	}(context.WithoutCancel(ctx))
+}
+
+// inAppNotification builds the notification for an event, or returns nil
+// when the event has no one to notify
+func inAppNotification(tenantID string, event models.EventType, data any) *models.Notification {
+	job, ok := data.(models.PrintJobResponse)
+	if !ok || job.RequestedBy == "" {
+		return nil
+	}
+	jobID := job.ID
+	n := &models.Notification{
+		TenantID:   tenantID,
+		UserID:     job.RequestedBy,
+		Type:       event,
+		EntityType: models.NotificationEntityPrintJob,
+		EntityID:   &jobID,
+	}
+	switch event {
+	case models.EventPrintJobCompleted:
+		n.Title = fmt.Sprintf("Print job #%d is ready", job.ID)
+		n.Body = fmt.Sprintf("Your %s print of contract %d has finished.", job.Format, job.ContractID)
+	case models.EventPrintJobFailed:
+		n.Title = fmt.Sprintf("Print job #%d failed", job.ID)
+		n.Body = fmt.Sprintf("Your %s print of contract %d could not be completed.", job.Format, job.ContractID)
+		if job.ErrorMessage != "" {
+			n.Body += " Error: " + job.ErrorMessage
+		}
+	default:
+		return nil
+	}
+	return n
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/zlovtnik/gprint/internal/models"
+	"github.com/zlovtnik/gprint/internal/repository"
+)
+
+func TestInAppNotification(t *testing.T) {
+	job := models.PrintJobResponse{ID: 9, ContractID: 4, Format: models.PrintFormatPDF, RequestedBy: "alice"}
+	failed := job
+	failed.ErrorMessage = "template not found"
+
+	tests := []struct {
+		name  string
+		event models.EventType
+		data  any
+		title string
+		body  string
+	}{
+		{
+			"completed", models.EventPrintJobCompleted, job,
+			"Print job #9 is ready", "Your PDF print of contract 4 has finished.",
+		},
+		{
+			"failed", models.EventPrintJobFailed, job,
+			"Print job #9 failed", "Your PDF print of contract 4 could not be completed.",
+		},
+		{
+			"failed with an error", models.EventPrintJobFailed, failed,
+			"Print job #9 failed", "Your PDF print of contract 4 could not be completed. Error: template not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := inAppNotification("t1", tt.event, tt.data)
+			if n == nil {
+				t.Fatal("no notification")
+			}
+			if n.TenantID != "t1" || n.UserID != "alice" || n.Type != tt.event {
+				t.Errorf("notification for %s/%s of type %s, want t1/alice of type %s", n.TenantID, n.UserID, n.Type, tt.event)
+			}
+			if n.EntityType != models.NotificationEntityPrintJob || n.EntityID == nil || *n.EntityID != 9 {
+				t.Errorf("notification links to %s %v, want print job 9", n.EntityType, n.EntityID)
+			}
+			if n.Title != tt.title || n.Body != tt.body {
+				t.Errorf("notification = %q: %q, want %q: %q", n.Title, n.Body, tt.title, tt.body)
+			}
+		})
+	}
+
+	// Events without a print job requester notify no one
+	unrequested := job
+	unrequested.RequestedBy = ""
+	for name, tt := range map[string]struct {
+		event models.EventType
+		data  any
+	}{
+		"contract signed": {models.EventContractSigned, job},
+		"no requester":    {models.EventPrintJobCompleted, unrequested},
+		"not a print job": {models.EventPrintJobCompleted, &job},
+	} {
+		if n := inAppNotification("t1", tt.event, tt.data); n != nil {
+			t.Errorf("%s: notified %+v", name, n)
+		}
+	}
+}
+
+// notificationID matches the id out bind of inserting a notification and
+// fills it with the new ID
+type notificationID int64
+
+func (id notificationID) Match(v driver.Value) bool {
+	out, ok := v.(sql.Out)
+	if !ok {
+		return false
+	}
+	dest, ok := out.Dest.(*int64)
+	if ok {
+		*dest = int64(id)
+	}
+	return ok
+}
+
+func TestInAppNotifierNotifiesRequester(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	repo, err := repository.NewNotificationRepository(repository.NewDB(db, repository.DBConfig{}, logger))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The notification goes to the requester within the job's tenant
+	mock.ExpectExec("INSERT INTO NOTIFICATIONS").
+		WithArgs("t1", "alice", "print_job.completed", "Print job #9 is ready", "Your PDF print of contract 4 has finished.",
+			"print_job", int64(9), notificationID(42)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("FROM NOTIFICATIONS\\s+WHERE tenant_id = :1 AND user_id = :2 AND id = :3").
+		WithArgs("t1", "alice", int64(42)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id", "user_id", "type", "title", "body", "entity_type", "entity_id", "created_at", "read_at"}).
+			AddRow(int64(42), "t1", "alice", "print_job.completed", "Print job #9 is ready", nil, "print_job", int64(9), time.Now(), nil))
+
+	job := models.PrintJobResponse{ID: 9, ContractID: 4, Format: models.PrintFormatPDF, RequestedBy: "alice"}
+	NewInAppNotifier(NewNotificationService(repo), logger).Notify(t.Context(), "t1", models.EventPrintJobCompleted, job)
+
+	// Notify stores the notification in the background
+	deadline := time.Now().Add(2 * time.Second)
+	for mock.ExpectationsWereMet() != nil && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zlovtnik/gprint/internal/billing"
+	"github.com/zlovtnik/gprint/internal/models"
+	"github.com/zlovtnik/gprint/internal/repository"
+	"github.com/zlovtnik/gprint/pkg/requestctx"
+)
+
+// invoiceJobBatchSize is the number of active contracts read per query by
+// CreateDueDrafts
+const invoiceJobBatchSize = 200
+
+// InvoiceService creates draft invoices from contract billing schedules and
+// moves them through issue and payment
+type InvoiceService struct {
+	invoiceRepo  *repository.InvoiceRepository
+	contractRepo *repository.ContractRepository
+	settings     *TenantSettingsService
+}
+
+// NewInvoiceService creates a new InvoiceService
+func NewInvoiceService(invoiceRepo *repository.InvoiceRepository, contractRepo *repository.ContractRepository, settings *TenantSettingsService) *InvoiceService {
+	return &InvoiceService{invoiceRepo: invoiceRepo, contractRepo: contractRepo, settings: settings}
+}
+
+// CreateDueDrafts creates the DRAFT invoice of the billing period each
+// ACTIVE contract is in on now's date, unless it has one, and returns the
+// number created. Only the current period is invoiced, so periods that
+// started and ended while the job was not running are left to finance.
+// Contracts whose schedule cannot be computed are logged and skipped. It
+// is safe to run repeatedly and on several instances at once.
+func (s *InvoiceService) CreateDueDrafts(ctx context.Context, now time.Time) (int, error) {
+	year, month, day := now.Date()
+	today := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+
+	created := 0
+	var afterID int64
+	for {
+		contracts, err := s.contractRepo.ListActiveAfter(ctx, afterID, invoiceJobBatchSize)
+		if err != nil {
+			return created, err
+		}
+		for i := range contracts {
+			afterID = contracts[i].ID
+			if ctx.Err() != nil {
+				return created, ctx.Err()
+			}
+			ok, err := s.createCurrentDraft(ctx, &contracts[i], today)
+			if err != nil {
+				if ctx.Err() != nil {
+					return created, ctx.Err()
+				}
+				requestctx.Logger(ctx).Warn("failed to create draft invoice",
+					"tenant_id", contracts[i].TenantID, "contract_id", contracts[i].ID, "error", err)
+				continue
+			}
+			if ok {
+				created++
+			}
+		}
+		if len(contracts) < invoiceJobBatchSize {
+			return created, nil
+		}
+	}
+}
+
+// createCurrentDraft creates the draft invoice of the contract's period
+// containing today, reporting whether one was created
+func (s *InvoiceService) createCurrentDraft(ctx context.Context, contract *models.Contract, today time.Time) (bool, error) {
+	settings, err := s.settings.Get(ctx, contract.TenantID)
+	if err != nil {
+		return false, err
+	}
+	netDays, _ := billing.ParsePaymentTerms(contract.PaymentTerms)
+	periods, err := billing.Schedule(billing.Terms{
+		Start:          contract.StartDate,
+		DurationMonths: contract.DurationMonths,
+		End:            contract.EndDate,
+		Cycle:          contract.BillingCycle,
+		Total:          contract.TotalValue,
+		NetDays:        netDays,
+		Rounding:       settings.RoundingMode,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	for _, p := range periods {
+		if p.Start.After(today) || p.End.Before(today) {
+			continue
+		}
+		return s.invoiceRepo.CreateDraft(ctx, &models.Invoice{
+			TenantID:      contract.TenantID,
+			InvoiceNumber: models.InvoiceNumber(contract.ContractNumber, p.Number),
+			ContractID:    contract.ID,
+			PeriodNumber:  p.Number,
+			PeriodStart:   p.Start,
+			PeriodEnd:     p.End,
+			DueDate:       p.DueDate,
+			Amount:        p.Amount,
+		})
+	}
+	return false, nil
+}
+
+// ListByContract retrieves a page of a contract's invoices in billing order
+func (s *InvoiceService) ListByContract(ctx context.Context, tenantID string, contractID int64, params models.PaginationParams) ([]models.Invoice, int, error) {
+	if _, err := s.contractRepo.GetByID(ctx, tenantID, contractID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, 0, ErrContractNotFound
+		}
+		return nil, 0, err
+	}
+	return s.invoiceRepo.ListByContract(ctx, tenantID, contractID, params)
+}
+
+// UpdateStatus issues a DRAFT invoice or marks an ISSUED one PAID
+func (s *InvoiceService) UpdateStatus(ctx context.Context, tenantID string, id int64, status models.InvoiceStatus, updatedBy string) (*models.Invoice, error) {
+	invoice, err := s.invoiceRepo.GetByID(ctx, tenantID, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrInvoiceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if models.NextInvoiceStatus[invoice.Status] != status {
+		return nil, fmt.Errorf("%w: cannot change a %s invoice to %s", ErrInvalidInvoiceTransition, invoice.Status, status)
+	}
+
+	updated, err := s.invoiceRepo.UpdateStatus(ctx, tenantID, id, invoice.Status, status, updatedBy)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, fmt.Errorf("%w: the invoice was changed concurrently", ErrInvalidInvoiceTransition)
+	}
+	return s.invoiceRepo.GetByID(ctx, tenantID, id)
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/zlovtnik/gprint/internal/models"
+	"github.com/zlovtnik/gprint/internal/repository"
+)
+
+// contractColumns are the columns ListActiveAfter scans, in order
+var contractColumns = []string{
+	"id", "tenant_id", "contract_number", "contract_type", "customer_id",
+	"start_date", "end_date", "duration_months", "auto_renew",
+	"subtotal", "discount_pct", "discount_amount", "tax_pct", "tax_amount",
+	"total_value", "payment_terms", "billing_cycle", "status",
+	"signed_at", "signed_by", "document_path", "document_hash",
+	"notes", "terms_conditions", "created_at", "updated_at", "created_by", "updated_by",
+}
+
+// newInvoiceService builds an InvoiceService over a mocked database
+func newInvoiceService(t *testing.T) (*InvoiceService, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	rdb := repository.NewDB(db, repository.DBConfig{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	tenantRepo, err := repository.NewTenantRepository(rdb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings := NewTenantSettingsService(tenantRepo, models.TenantSettings{})
+	return NewInvoiceService(repository.NewInvoiceRepository(rdb), repository.NewContractRepository(rdb), settings), mock
+}
+
+// expectActiveContracts expects one page of ACTIVE contracts, each a year
+// billed monthly at 100 a month from 2024-01-15 on NET 30 terms
+func expectActiveContracts(mock sqlmock.Sqlmock, ids ...int64) {
+	start := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	rows := sqlmock.NewRows(contractColumns)
+	for _, id := range ids {
+		rows.AddRow(
+			id, "tenant-1", fmt.Sprintf("CTR-%04d", id), "SERVICE", int64(7),
+			start, nil, int64(12), false,
+			"1200", "0", "0", "0", "0",
+			1200.0, "NET 30", "MONTHLY", "ACTIVE",
+			nil, nil, nil, nil,
+			nil, nil, start, start, nil, nil,
+		)
+	}
+	mock.ExpectQuery("SELECT .* FROM contracts").WithArgs("ACTIVE", int64(0), invoiceJobBatchSize).WillReturnRows(rows)
+}
+
+// onDate matches a time bind falling on the given date
+type onDate string
+
+func (d onDate) Match(v driver.Value) bool {
+	t, ok := v.(time.Time)
+	return ok && t.Format(time.DateOnly) == string(d)
+}
+
+// expectDraft expects the MERGE of the third period's draft of contract id,
+// keyed on tenant, contract and period start
+func expectDraft(mock sqlmock.Sqlmock, id int64) *sqlmock.ExpectedExec {
+	return mock.ExpectExec(`(?s)MERGE INTO INVOICES t.*ON \(t.tenant_id = s.tenant_id AND t.contract_id = s.contract_id AND t.period_start = s.period_start\)`).
+		WithArgs("tenant-1", id, onDate("2024-03-15"),
+			fmt.Sprintf("CTR-%04d-003", id), 3, onDate("2024-04-14"), onDate("2024-04-14"), 100.0, "DRAFT")
+}
+
+func TestCreateDueDraftsOncePerPeriod(t *testing.T) {
+	svc, mock := newInvoiceService(t)
+	now := time.Date(2024, 3, 20, 15, 0, 0, 0, time.UTC)
+
+	// First run: contract 1's draft is inserted; contract 2's was inserted
+	// by a concurrent instance between the MERGE's check and its insert
+	expectActiveContracts(mock, 1, 2)
+	mock.ExpectQuery("SELECT currency").WithArgs("tenant-1").WillReturnRows(sqlmock.NewRows(nil))
+	expectDraft(mock, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	expectDraft(mock, 2).WillReturnError(errors.New("ORA-00001: unique constraint (GPRINT.UK_INVOICES_PERIOD) violated"))
+	// Second run later the same day: both drafts exist, so the MERGE inserts nothing
+	expectActiveContracts(mock, 1, 2)
+	expectDraft(mock, 1).WillReturnResult(sqlmock.NewResult(0, 0))
+	expectDraft(mock, 2).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	created, err := svc.CreateDueDrafts(context.Background(), now)
+	if err != nil || created != 1 {
+		t.Errorf("first run created %d, %v; want 1", created, err)
+	}
+	created, err = svc.CreateDueDrafts(context.Background(), now.Add(6*time.Hour))
+	if err != nil || created != 0 {
+		t.Errorf("second run created %d, %v; want 0", created, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCreateDueDraftsSkipsFailedContracts(t *testing.T) {
+	svc, mock := newInvoiceService(t)
+
+	expectActiveContracts(mock, 1, 2)
+	mock.ExpectQuery("SELECT currency").WithArgs("tenant-1").WillReturnRows(sqlmock.NewRows(nil))
+	expectDraft(mock, 1).WillReturnError(errors.New("ORA-03113: end-of-file on communication channel"))
+	expectDraft(mock, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	created, err := svc.CreateDueDrafts(context.Background(), time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC))
+	if err != nil || created != 1 {
+		t.Errorf("created %d, %v; want 1 with contract 1 skipped", created, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCreateDueDraftsOutsideTerm(t *testing.T) {
+	svc, mock := newInvoiceService(t)
+
+	// The year billed from 2024-01-15 has ended; no draft is merged
+	expectActiveContracts(mock, 1)
+	mock.ExpectQuery("SELECT currency").WithArgs("tenant-1").WillReturnRows(sqlmock.NewRows(nil))
+
+	created, err := svc.CreateDueDrafts(context.Background(), time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC))
+	if err != nil || created != 0 {
+		t.Errorf("created %d, %v; want 0", created, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/zlovtnik/gprint/internal/models"
+	"github.com/zlovtnik/gprint/internal/repository"
+)
+
+// NotificationService manages users' notification preferences and their
+// in-app notification lists
+type NotificationService struct {
+	repo *repository.NotificationRepository
+}
+
+// NewNotificationService creates a new NotificationService
+func NewNotificationService(repo *repository.NotificationRepository) *NotificationService {
+	return &NotificationService{repo: repo}
+}
+
+// GetPreference retrieves a user's preference; nil means the user has not opted in
+func (s *NotificationService) GetPreference(ctx context.Context, tenantID, userID string) (*models.NotificationPreference, error) {
+	return s.repo.GetPreference(ctx, tenantID, userID)
+}
+
+// UpdatePreference creates or replaces a user's preference
+func (s *NotificationService) UpdatePreference(ctx context.Context, tenantID, userID string, req *models.UpdateNotificationPreferenceRequest) (*models.NotificationPreference, error) {
+	return s.repo.UpsertPreference(ctx, tenantID, userID, req)
+}
+
+// DeletePreference opts a user out of all notification emails
+func (s *NotificationService) DeletePreference(ctx context.Context, tenantID, userID string) error {
+	return s.repo.DeletePreference(ctx, tenantID, userID)
+}
+
+// Create adds a notification to n.UserID's list
+func (s *NotificationService) Create(ctx context.Context, n *models.Notification) (*models.Notification, error) {
+	return s.repo.CreateNotification(ctx, n)
+}
+
+// List retrieves a page of a user's notifications, unread first, and the
+// number matching. unreadOnly leaves out the ones already read.
+func (s *NotificationService) List(ctx context.Context, tenantID, userID string, unreadOnly bool, page, pageSize int) ([]models.Notification, int, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	return s.repo.ListNotifications(ctx, tenantID, userID, unreadOnly, (page-1)*pageSize, pageSize)
+}
+
+// UnreadCount returns the number of a user's unread notifications
+func (s *NotificationService) UnreadCount(ctx context.Context, tenantID, userID string) (int, error) {
+	return s.repo.CountUnread(ctx, tenantID, userID)
+}
+
+// MarkRead marks one of a user's notifications read and returns it. Marking
+// a read notification again keeps its first read time.
+func (s *NotificationService) MarkRead(ctx context.Context, tenantID, userID string, id int64) (*models.Notification, error) {
+	if err := s.repo.MarkRead(ctx, tenantID, userID, id); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotificationNotFound
+		}
+		return nil, err
+	}
+	return s.repo.GetNotification(ctx, tenantID, userID, id)
+}
+
+// MarkAllRead marks all of a user's unread notifications read and returns
+// how many there were
+func (s *NotificationService) MarkAllRead(ctx context.Context, tenantID, userID string) (int, error) {
+	return s.repo.MarkAllRead(ctx, tenantID, userID)
+}
@@ -0,0 +1,23 @@
+package service
+
+import (
+	"context"
+
+	"github.com/zlovtnik/gprint/internal/models"
+)
+
+// EventNotifier is told about events that tenants can subscribe to. Notify
+// must return promptly; slow or failing receivers must not hold up the caller.
+type EventNotifier interface {
+	Notify(ctx context.Context, tenantID string, event models.EventType, data any)
+}
+
+// Notifiers fans each event out to every notifier in the list
+type Notifiers []EventNotifier
+
+// Notify implements EventNotifier
+func (n Notifiers) Notify(ctx context.Context, tenantID string, event models.EventType, data any) {
+	for _, notifier := range n {
+		notifier.Notify(ctx, tenantID, event, data)
+	}
+}
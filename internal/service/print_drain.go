@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/zlovtnik/gprint/internal/models"
+)
+
+// Drain stops this instance claiming print jobs and waits for the jobs it is
+// rendering to finish. Jobs still rendering when ctx is done are stopped,
+// handed back to the queue and their partial output removed, so another
+// instance or the next start renders them. A job that does not stop within
+// drainStopTimeout of that is left behind; it stays PROCESSING until its
+// lease expires. It returns how many jobs finished while draining and how
+// many were requeued.
+func (s *PrintService) Drain(ctx context.Context) (drained, requeued int) {
+	s.runningMu.Lock()
+	s.draining = true
+	s.runningMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.inflight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.runningMu.Lock()
+		for _, cancel := range s.running {
+			cancel(errShuttingDown)
+		}
+		s.runningMu.Unlock()
+
+		timer := time.NewTimer(s.stopTimeout)
+		defer timer.Stop()
+		select {
+		case <-done:
+		case <-timer.C:
+			s.runningMu.Lock()
+			stuck := len(s.running)
+			s.runningMu.Unlock()
+			s.logger.Warn("print jobs did not stop after the drain deadline; leaving them to their lease",
+				"jobs", stuck,
+				"waited", s.stopTimeout,
+			)
+		}
+	}
+
+	s.runningMu.Lock()
+	defer s.runningMu.Unlock()
+	return s.drained, s.requeued
+}
+
+// RequeueStaleJobs moves PROCESSING jobs whose lease expired back to QUEUED.
+// Such jobs were left by an instance that crashed mid-render; run at startup
+// it shows them as queued again rather than processing until the next claim.
+// Partial output of a crashed render is not recorded on the job, so it cannot
+// be removed here.
+func (s *PrintService) RequeueStaleJobs(ctx context.Context) (int64, error) {
+	return s.printJobRepo.RequeueExpiredLeases(ctx)
+}
+
+// isDraining reports whether Drain was called
+func (s *PrintService) isDraining() bool {
+	s.runningMu.Lock()
+	defer s.runningMu.Unlock()
+	return s.draining
+}
+
+// beginJob registers a job about to be claimed with inflight. It returns
+// false once Drain was called, so no job starts while Drain waits.
+func (s *PrintService) beginJob() bool {
+	s.runningMu.Lock()
+	defer s.runningMu.Unlock()
+	if s.draining {
+		return false
+	}
+	s.inflight.Add(1)
+	return true
+}
+
+// releaseJob hands a job stopped by shutdown back to the queue. A job whose
+// release fails stays PROCESSING until its lease expires.
+func (s *PrintService) releaseJob(ctx context.Context, job *models.ContractPrintJob) {
+	released, err := s.printJobRepo.Release(ctx, job.TenantID, job.ID, s.instanceID)
+	if err != nil {
+		s.logger.Error("failed to requeue print job interrupted by shutdown",
+			"job_id", job.ID,
+			"tenant_id", job.TenantID,
+			"error", err,
+		)
+		return
+	}
+	if !released {
+		return
+	}
+	s.logger.Info("requeued print job interrupted by shutdown",
+		"job_id", job.ID,
+		"tenant_id", job.TenantID,
+	)
+	s.runningMu.Lock()
+	s.requeued++
+	s.runningMu.Unlock()
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zlovtnik/gprint/internal/models"
+)
+
+// drainResult is what Drain returned
+type drainResult struct{ drained, requeued int }
+
+// startDrain runs Drain in the background with ctx
+func startDrain(ctx context.Context, s *PrintService) <-chan drainResult {
+	result := make(chan drainResult, 1)
+	go func() {
+		drained, requeued := s.Drain(ctx)
+		result <- drainResult{drained, requeued}
+	}()
+	return result
+}
+
+func TestDrainWaitsForRunningJobs(t *testing.T) {
+	store := newFakeJobStore(1)
+	output := &fakeStorage{}
+	started, release := make(chan struct{}), make(chan struct{})
+	render := rendererFunc(func(ctx context.Context, html string, format models.PrintFormat) ([]byte, error) {
+		close(started)
+		<-release
+		return []byte(html), nil
+	})
+
+	s := newTestPrintService(store, output, render, "instance-a", time.Minute)
+	processed := make(chan error, 1)
+	go func() { processed <- s.ProcessPendingJobs(context.Background()) }()
+	<-started
+
+	result := startDrain(context.Background(), s)
+	select {
+	case r := <-result:
+		t.Fatalf("Drain returned %+v while a job was rendering", r)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case r := <-result:
+		if r != (drainResult{drained: 1}) {
+			t.Errorf("Drain = %+v, want 1 drained", r)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Drain did not return after the job finished")
+	}
+	if err := <-processed; err != nil {
+		t.Fatalf("ProcessPendingJobs: %v", err)
+	}
+	if status, owner := store.state(1); status != models.PrintJobStatusCompleted || owner != "" {
+		t.Errorf("job status %s, locked by %q; want COMPLETED", status, owner)
+	}
+	if n := output.count(); n != 1 {
+		t.Errorf("stored %d outputs, want 1", n)
+	}
+}
+
+func TestDrainRequeuesJobsAtDeadline(t *testing.T) {
+	store := newFakeJobStore(1)
+	output := &fakeStorage{}
+	started := make(chan struct{})
+	cause := make(chan error, 1)
+	render := rendererFunc(func(ctx context.Context, html string, format models.PrintFormat) ([]byte, error) {
+		close(started)
+		<-ctx.Done()
+		cause <- context.Cause(ctx)
+		return nil, ctx.Err()
+	})
+
+	s := newTestPrintService(store, output, render, "instance-a", time.Minute)
+	processed := make(chan error, 1)
+	go func() { processed <- s.ProcessPendingJobs(context.Background()) }()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	select {
+	case r := <-startDrain(ctx, s):
+		if r != (drainResult{requeued: 1}) {
+			t.Errorf("Drain = %+v, want 1 requeued", r)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Drain did not return after its deadline")
+	}
+	if err := <-cause; !errors.Is(err, errShuttingDown) {
+		t.Errorf("render cancelled with %v, want errShuttingDown", err)
+	}
+	if err := <-processed; err != nil {
+		t.Fatalf("ProcessPendingJobs: %v", err)
+	}
+
+	// Back in the queue for another instance, with nothing stored
+	if status, owner := store.state(1); status != models.PrintJobStatusQueued || owner != "" {
+		t.Errorf("job status %s, locked by %q; want QUEUED and unlocked", status, owner)
+	}
+	if n := output.count(); n != 0 {
+		t.Errorf("stored %d outputs, want none", n)
+	}
+}
+
+func TestDrainRefusesNewClaims(t *testing.T) {
+	store := newFakeJobStore(3)
+	var rendered atomic.Int32
+	render := rendererFunc(func(ctx context.Context, html string, format models.PrintFormat) ([]byte, error) {
+		rendered.Add(1)
+		return []byte(html), nil
+	})
+	s := newTestPrintService(store, &fakeStorage{}, render, "instance-a", time.Minute)
+
+	if r := <-startDrain(context.Background(), s); r != (drainResult{}) {
+		t.Errorf("Drain of an idle processor = %+v, want nothing drained", r)
+	}
+	if err := s.ProcessPendingJobs(context.Background()); err != nil {
+		t.Fatalf("ProcessPendingJobs: %v", err)
+	}
+	// A worker that read the queue before the drain does not claim either
+	s.runJob(context.Background(), &store.jobs[1].job)
+
+	if n := rendered.Load(); n != 0 || len(store.claims) != 0 {
+		t.Errorf("rendered %d jobs and claimed %v after Drain", n, store.claims)
+	}
+	for id := int64(1); id <= 3; id++ {
+		if status, _ := store.state(id); status != models.PrintJobStatusQueued {
+			t.Errorf("job %d status %s, want QUEUED", id, status)
+		}
+	}
+}
+
+func TestDrainGivesUpOnStuckJobs(t *testing.T) {
+	store := newFakeJobStore(1)
+	started, unstick := make(chan struct{}), make(chan struct{})
+	// This renderer ignores cancellation
+	render := rendererFunc(func(ctx context.Context, html string, format models.PrintFormat) ([]byte, error) {
+		close(started)
+		<-unstick
+		return nil, ctx.Err()
+	})
+
+	s := newTestPrintService(store, &fakeStorage{}, render, "instance-a", time.Minute)
+	s.stopTimeout = 20 * time.Millisecond
+	processed := make(chan error, 1)
+	go func() { processed <- s.ProcessPendingJobs(context.Background()) }()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	select {
+	case r := <-startDrain(ctx, s):
+		if r != (drainResult{}) {
+			t.Errorf("Drain = %+v, want nothing drained or requeued", r)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Drain waited on a job that does not stop")
+	}
+	// The job is left to its lease
+	if status, owner := store.state(1); status != models.PrintJobStatusProcessing || owner != "instance-a" {
+		t.Errorf("job status %s, locked by %q; want PROCESSING under instance-a", status, owner)
+	}
+
+	close(unstick)
+	<-processed
+}
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/zlovtnik/gprint/internal/models"
+	"github.com/zlovtnik/gprint/internal/repository"
+	"github.com/zlovtnik/gprint/internal/storage"
+)
+
+// fakeJob is a print job row held by fakeJobStore
+type fakeJob struct {
+	job         models.ContractPrintJob
+	lockedBy    string
+	lockedUntil time.Time
+}
+
+// fakeJobStore keeps print jobs in memory and applies the same lease
+// predicates as the repository's SQL, each update under one lock as the
+// database applies a single-row UPDATE atomically
+type fakeJobStore struct {
+	printJobStore // methods a test does not use panic
+
+	mu     sync.Mutex
+	jobs   map[int64]*fakeJob
+	claims map[int64][]string // instances that claimed each job, in order
+
+	// pendingBarrier, when set, holds every GetPendingJobs call until all
+	// callers have read the queue, so they all try the same jobs
+	pendingBarrier *sync.WaitGroup
+}
+
+func newFakeJobStore(n int) *fakeJobStore {
+	s := &fakeJobStore{jobs: make(map[int64]*fakeJob), claims: make(map[int64][]string)}
+	for id := int64(1); id <= int64(n); id++ {
+		s.jobs[id] = &fakeJob{job: models.ContractPrintJob{
+			ID:         id,
+			TenantID:   "t1",
+			ContractID: id,
+			Format:     models.PrintFormatHTML,
+			Status:     models.PrintJobStatusQueued,
+		}}
+	}
+	return s
+}
+
+func (s *fakeJobStore) GetPendingJobs(ctx context.Context, limit int) ([]models.ContractPrintJob, error) {
+	s.mu.Lock()
+	var jobs []models.ContractPrintJob
+	for _, j := range s.jobs {
+		if len(jobs) < limit && j.job.Status == models.PrintJobStatusQueued {
+			jobs = append(jobs, j.job)
+		}
+	}
+	s.mu.Unlock()
+
+	if s.pendingBarrier != nil {
+		s.pendingBarrier.Done()
+		s.pendingBarrier.Wait()
+	}
+	return jobs, nil
+}
+
+func (s *fakeJobStore) GetRetryableJobs(ctx context.Context, maxRetries, limit int) ([]models.ContractPrintJob, error) {
+	return nil, nil
+}
+
+func (s *fakeJobStore) Claim(ctx context.Context, tenantID string, id int64, instanceID string, lease time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j := s.jobs[id]
+	expired := j.job.Status == models.PrintJobStatusProcessing && j.lockedUntil.Before(time.Now())
+	if j.job.Status != models.PrintJobStatusQueued && !expired {
+		return false, nil
+	}
+	j.job.Status = models.PrintJobStatusProcessing
+	j.lockedBy = instanceID
+	j.lockedUntil = time.Now().Add(lease)
+	s.claims[id] = append(s.claims[id], instanceID)
+	return true, nil
+}
+
+// owned reports whether instanceID holds the lease of a PROCESSING job;
+// callers hold s.mu
+func (s *fakeJobStore) owned(id int64, instanceID string) bool {
+	j := s.jobs[id]
+	return j.lockedBy == instanceID && j.job.Status == models.PrintJobStatusProcessing
+}
+
+func (s *fakeJobStore) RenewLease(ctx context.Context, tenantID string, id int64, instanceID string, lease time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.owned(id, instanceID) {
+		return false, nil
+	}
+	s.jobs[id].lockedUntil = time.Now().Add(lease)
+	return true, nil
+}
+
+func (s *fakeJobStore) UpdateProgress(ctx context.Context, tenantID string, id int64, instanceID string, pct int) error {
+	return nil
+}
+
+func (s *fakeJobStore) Complete(ctx context.Context, tenantID string, id int64, instanceID string, params repository.CompleteParams) (bool, error) {
+	return s.finish(id, instanceID, models.PrintJobStatusCompleted), nil
+}
+
+func (s *fakeJobStore) MarkFailed(ctx context.Context, tenantID string, id int64, instanceID string, params repository.FailParams) (bool, error) {
+	status := models.PrintJobStatusFailed
+	if params.Dead {
+		status = models.PrintJobStatusDead
+	}
+	return s.finish(id, instanceID, status), nil
+}
+
+func (s *fakeJobStore) Release(ctx context.Context, tenantID string, id int64, instanceID string) (bool, error) {
+	return s.finish(id, instanceID, models.PrintJobStatusQueued), nil
+}
+
+// finish moves a job instanceID leases to status and clears the lease
+func (s *fakeJobStore) finish(id int64, instanceID string, status models.PrintJobStatus) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.owned(id, instanceID) {
+		return false
+	}
+	j := s.jobs[id]
+	j.job.Status = status
+	j.lockedBy = ""
+	j.lockedUntil = time.Time{}
+	return true
+}
+
+// takeOver hands a job's lease to another instance, as a claim after the
+// lease expired would
+func (s *fakeJobStore) takeOver(id int64, instanceID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[id].lockedBy = instanceID
+}
+
+// state returns a job's status and lease owner
+func (s *fakeJobStore) state(id int64) (models.PrintJobStatus, string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.jobs[id].job.Status, s.jobs[id].lockedBy
+}
+
+// fakeContracts returns a contract for any ID
+type fakeContracts struct{}
+
+func (fakeContracts) GetByID(ctx context.Context, tenantID string, id int64) (*models.Contract, error) {
+	return &models.Contract{ID: id, TenantID: tenantID, ContractNumber: fmt.Sprintf("C-%d", id)}, nil
+}
+
+// fakeStorage keeps stored objects in memory
+type fakeStorage struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (s *fakeStorage) Name() string { return "fake" }
+
+func (s *fakeStorage) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.objects == nil {
+		s.objects = make(map[string][]byte)
+	}
+	s.objects[key] = data
+	return nil
+}
+
+func (s *fakeStorage) Get(ctx context.Context, key string) (*storage.Object, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.objects[key]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	return &storage.Object{Body: io.NopCloser(bytes.NewReader(data)), Size: int64(len(data))}, nil
+}
+
+func (s *fakeStorage) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, key)
+	return nil
+}
+
+func (s *fakeStorage) SignedURL(ctx context.Context, key string, ttl time.Duration, fileName string) (string, error) {
+	return "", storage.ErrSignedURLUnsupported
+}
+
+func (s *fakeStorage) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.objects)
+}
+
+// rendererFunc adapts a function to Renderer
+type rendererFunc func(ctx context.Context, html string, format models.PrintFormat) ([]byte, error)
+
+func (f rendererFunc) Render(ctx context.Context, w io.Writer, html string, format models.PrintFormat) error {
+	out, err := f(ctx, html, format)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+// newTestPrintService builds a PrintService over the fakes, leasing jobs as
+// instanceID
+func newTestPrintService(jobs printJobStore, store storage.Storage, renderer Renderer, instanceID string, lease time.Duration) *PrintService {
+	return &PrintService{
+		printJobRepo: jobs,
+		contractRepo: fakeContracts{},
+		storage:      store,
+		workers:      4,
+		instanceID:   instanceID,
+		lease:        lease,
+		renderer:     renderer,
+		logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+		running:      make(map[int64]context.CancelCauseFunc),
+		stopTimeout:  drainStopTimeout,
+	}
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zlovtnik/gprint/internal/models"
+)
+
+func TestProcessPendingJobsClaimsEachJobOnce(t *testing.T) {
+	const jobs = 20
+	store := newFakeJobStore(jobs)
+	store.pendingBarrier = &sync.WaitGroup{}
+	store.pendingBarrier.Add(2)
+	output := &fakeStorage{}
+	render := rendererFunc(func(ctx context.Context, html string, format models.PrintFormat) ([]byte, error) {
+		time.Sleep(time.Millisecond) // let the other instance's claims interleave
+		return []byte(html), nil
+	})
+
+	a := newTestPrintService(store, output, render, "instance-a", time.Minute)
+	b := newTestPrintService(store, output, render, "instance-b", time.Minute)
+
+	// Both instances read the same queued jobs before either claims one
+	var wg sync.WaitGroup
+	for _, s := range []*PrintService{a, b} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.ProcessPendingJobs(context.Background()); err != nil {
+				t.Errorf("ProcessPendingJobs: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for id := int64(1); id <= jobs; id++ {
+		if claims := store.claims[id]; len(claims) != 1 {
+			t.Errorf("job %d claimed %d times: %v", id, len(claims), claims)
+		}
+		if status, owner := store.state(id); status != models.PrintJobStatusCompleted || owner != "" {
+			t.Errorf("job %d: status %s, locked by %q; want COMPLETED and unlocked", id, status, owner)
+		}
+	}
+	if n := output.count(); n != jobs {
+		t.Errorf("stored %d outputs, want %d", n, jobs)
+	}
+}
+
+func TestLostLeaseCancelsRender(t *testing.T) {
+	store := newFakeJobStore(1)
+	output := &fakeStorage{}
+	started := make(chan struct{})
+	cause := make(chan error, 1)
+	render := rendererFunc(func(ctx context.Context, html string, format models.PrintFormat) ([]byte, error) {
+		close(started)
+		<-ctx.Done()
+		cause <- context.Cause(ctx)
+		return nil, ctx.Err()
+	})
+
+	// A short lease renews every 10ms, so the takeover is noticed quickly
+	s := newTestPrintService(store, output, render, "instance-a", 30*time.Millisecond)
+	done := make(chan error, 1)
+	go func() { done <- s.ProcessPendingJobs(context.Background()) }()
+
+	<-started
+	store.takeOver(1, "instance-b")
+
+	select {
+	case err := <-cause:
+		if !errors.Is(err, errLeaseLost) {
+			t.Fatalf("render cancelled with %v, want errLeaseLost", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("render was not cancelled after the lease was lost")
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("ProcessPendingJobs: %v", err)
+	}
+
+	// The job belongs to the new owner: nothing was written over it
+	if status, owner := store.state(1); status != models.PrintJobStatusProcessing || owner != "instance-b" {
+		t.Errorf("job status %s, locked by %q; want PROCESSING under instance-b", status, owner)
+	}
+	if n := output.count(); n != 0 {
+		t.Errorf("stored %d outputs, want none", n)
+	}
+}
+
+func TestFailJobAfterLostLeaseWritesNothing(t *testing.T) {
+	store := newFakeJobStore(1)
+	render := rendererFunc(func(ctx context.Context, html string, format models.PrintFormat) ([]byte, error) {
+		store.takeOver(1, "instance-b")
+		return nil, errors.New("renderer crashed")
+	})
+
+	s := newTestPrintService(store, &fakeStorage{}, render, "instance-a", time.Minute)
+	if err := s.ProcessPendingJobs(context.Background()); err != nil {
+		t.Fatalf("ProcessPendingJobs: %v", err)
+	}
+
+	if status, owner := store.state(1); status != models.PrintJobStatusProcessing || owner != "instance-b" {
+		t.Errorf("job status %s, locked by %q; want PROCESSING under instance-b", status, owner)
+	}
+}
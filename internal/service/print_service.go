@@ -1,27 +1,175 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"html"
+	"io"
 	"log/slog"
 	"os"
-	"path/filepath"
+	"path"
 	"regexp"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/zlovtnik/gprint/internal/models"
 	"github.com/zlovtnik/gprint/internal/repository"
+	"github.com/zlovtnik/gprint/internal/storage"
+	"github.com/zlovtnik/gprint/pkg/qrcode"
 )
 
+// maxRetryBackoff caps the exponential delay between automatic retries
+const maxRetryBackoff = time.Hour
+
+// PrintRetryPolicy controls automatic retries of failed print jobs.
+// A MaxRetries of zero disables automatic retries; failed jobs then stay
+// FAILED until retried manually.
+type PrintRetryPolicy struct {
+	MaxRetries  int
+	BaseBackoff time.Duration
+}
+
+// backoff returns the delay before the given retry attempt (0-based),
+// doubling from BaseBackoff and capped at maxRetryBackoff
+func (p PrintRetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseBackoff
+	if delay <= 0 {
+		delay = time.Minute
+	}
+	for i := 0; i < attempt && delay < maxRetryBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryBackoff)
+}
+
+// PrintServiceConfig holds tunables for print job processing
+type PrintServiceConfig struct {
+	OutputDir string          // Local output root; also serves jobs completed before storage backends
+	Storage   storage.Storage // Backend for new output; defaults to local storage in OutputDir
+	// SignedDownloadTTL redirects downloads to signed URLs valid this long when the backend
+	// supports them; zero streams output through the server
+	SignedDownloadTTL time.Duration
+	Workers           int // Number of jobs rendered concurrently; defaults to 1
+	RetryPolicy       PrintRetryPolicy
+	InstanceID        string        // Lease owner ID; generated from host and PID when empty
+	LeaseDuration     time.Duration // How long a claimed job stays locked without renewal
+	// WatermarkAdmins lists users allowed to print unsigned contracts without the draft watermark
+	WatermarkAdmins []string
+	// VerifyBaseURL is the public server address encoded in verification QR codes; empty disables them
+	VerifyBaseURL string
+	// VerifyPayload is the QR content template with {base_url}, {hash} and {contract_id} placeholders
+	VerifyPayload string
+	// Notifier is told when jobs complete or fail for good; nil disables notifications
+	Notifier EventNotifier
+	// Settings supplies each tenant's watermark text and output retention
+	Settings *TenantSettingsService
+	// Renderer converts rendered HTML to the job's format; defaults to HTMLRenderer
+	Renderer Renderer
+}
+
+const (
+	// pendingJobsPerWorker sizes each polling batch relative to the worker pool
+	pendingJobsPerWorker = 5
+	// purgeBatchSize is the number of expired jobs loaded per retention query
+	purgeBatchSize = 100
+	// defaultLeaseDuration is used when PrintServiceConfig.LeaseDuration is unset
+	defaultLeaseDuration = 2 * time.Minute
+	// drainStopTimeout bounds how long Drain waits for jobs it cancelled at
+	// its deadline to stop
+	drainStopTimeout = 10 * time.Second
+)
+
+// Rendering progress reported at each stage of processJob. Reaching 100
+// ("file written") is recorded together with the COMPLETED status.
+const (
+	progressDataLoaded       = 25
+	progressTemplateRendered = 50
+	progressPagesComposed    = 75
+)
+
+var (
+	// errJobCancelled is the cancellation cause set on a job context when a user cancels the job
+	errJobCancelled = errors.New("print job cancelled by user")
+	// errLeaseLost is the cancellation cause set when this instance no longer holds the job lease
+	errLeaseLost = errors.New("print job lease lost")
+	// errShuttingDown is the cancellation cause set on jobs still rendering when a drain times out
+	errShuttingDown = errors.New("print processor shutting down")
+)
+
+// defaultInstanceID builds a lease owner ID that is unique per process
+func defaultInstanceID() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "gprint"
+	}
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), uuid.NewString()[:8])
+}
+
+// printJobStore is the print job storage PrintService works against.
+// *repository.PrintJobRepository implements it; tests substitute a fake.
+type printJobStore interface {
+	Create(ctx context.Context, tenantID string, req *models.CreatePrintJobRequest, requestedBy string) (*models.ContractPrintJob, error)
+	GetByID(ctx context.Context, tenantID string, id int64) (*models.ContractPrintJob, error)
+	FindAll(ctx context.Context, tenantID string, filter models.PrintJobFilter, offset, limit int) ([]models.ContractPrintJob, int64, error)
+	FindKeyset(ctx context.Context, tenantID string, filter models.PrintJobFilter, params models.KeysetParams) ([]models.ContractPrintJob, *models.ListCursor, error)
+	UpdateStatus(ctx context.Context, tenantID string, id int64, params repository.UpdateStatusParams) error
+	GetPendingJobs(ctx context.Context, limit int) ([]models.ContractPrintJob, error)
+	Claim(ctx context.Context, tenantID string, id int64, instanceID string, lease time.Duration) (bool, error)
+	RenewLease(ctx context.Context, tenantID string, id int64, instanceID string, lease time.Duration) (bool, error)
+	Release(ctx context.Context, tenantID string, id int64, instanceID string) (bool, error)
+	RequeueExpiredLeases(ctx context.Context) (int64, error)
+	UpdateProgress(ctx context.Context, tenantID string, id int64, instanceID string, pct int) error
+	Complete(ctx context.Context, tenantID string, id int64, instanceID string, params repository.CompleteParams) (bool, error)
+	MarkFailed(ctx context.Context, tenantID string, id int64, instanceID string, params repository.FailParams) (bool, error)
+	Requeue(ctx context.Context, tenantID string, id int64) error
+	UpdatePriority(ctx context.Context, tenantID string, id int64, priority models.PrintPriority) error
+	Cancel(ctx context.Context, tenantID string, id int64, fromStatus models.PrintJobStatus) error
+	GetRetryableJobs(ctx context.Context, maxRetries, limit int) ([]models.ContractPrintJob, error)
+	GetPurgeableJobs(ctx context.Context, cutoff time.Time, afterID int64, limit int) ([]models.ContractPrintJob, error)
+	MarkPurged(ctx context.Context, tenantID string, id int64) error
+	CountByStatus(ctx context.Context) (map[models.PrintJobStatus]int64, error)
+}
+
+// contractLoader loads the contract a print job renders
+type contractLoader interface {
+	GetByID(ctx context.Context, tenantID string, id int64) (*models.Contract, error)
+}
+
 // PrintService handles print job business logic
 type PrintService struct {
-	printJobRepo *repository.PrintJobRepository
-	contractRepo *repository.ContractRepository
-	historyRepo  *repository.HistoryRepository
-	outputDir    string
-	logger       *slog.Logger
+	printJobRepo   printJobStore
+	contractRepo   contractLoader
+	historyRepo    *repository.HistoryRepository
+	generationRepo *repository.ContractGenerationRepository
+	storage        storage.Storage
+	legacyStorage  *storage.LocalStorage
+	signedURLTTL   time.Duration
+	workers        int
+	retryPolicy    PrintRetryPolicy
+	instanceID     string
+	lease          time.Duration
+	watermarkAdmin map[string]bool
+	verifyBaseURL  string
+	verifyPayload  string
+	notifier       EventNotifier
+	settings       *TenantSettingsService
+	renderer       Renderer
+	logger         *slog.Logger
+
+	// running holds the cancel functions of jobs being rendered by this instance
+	runningMu sync.Mutex
+	running   map[int64]context.CancelCauseFunc
+	// draining is set by Drain to stop new claims; inflight tracks the jobs
+	// being claimed or rendered so Drain can wait for them
+	draining    bool
+	inflight    sync.WaitGroup
+	drained     int
+	requeued    int
+	stopTimeout time.Duration // drainStopTimeout
 }
 
 // NewPrintService creates a new PrintService
@@ -29,25 +177,78 @@ func NewPrintService(
 	printJobRepo *repository.PrintJobRepository,
 	contractRepo *repository.ContractRepository,
 	historyRepo *repository.HistoryRepository,
-	outputDir string,
+	generationRepo *repository.ContractGenerationRepository,
+	cfg PrintServiceConfig,
 	logger *slog.Logger,
 ) (*PrintService, error) {
+	if cfg.Settings == nil {
+		return nil, errors.New("print service requires tenant settings")
+	}
+
 	// Ensure output directory exists
-	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	local, err := storage.NewLocalStorage(cfg.OutputDir)
+	if err != nil {
+		return nil, err
+	}
+	store := cfg.Storage
+	if store == nil {
+		store = local
+	}
+
+	workers := cfg.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	instanceID := cfg.InstanceID
+	if instanceID == "" {
+		instanceID = defaultInstanceID()
+	}
+	lease := cfg.LeaseDuration
+	if lease <= 0 {
+		lease = defaultLeaseDuration
+	}
+	renderer := cfg.Renderer
+	if renderer == nil {
+		renderer = HTMLRenderer{}
+	}
+	watermarkAdmin := make(map[string]bool, len(cfg.WatermarkAdmins))
+	for _, user := range cfg.WatermarkAdmins {
+		watermarkAdmin[user] = true
 	}
 
 	return &PrintService{
-		printJobRepo: printJobRepo,
-		contractRepo: contractRepo,
-		historyRepo:  historyRepo,
-		outputDir:    outputDir,
-		logger:       logger,
+		printJobRepo:   printJobRepo,
+		contractRepo:   contractRepo,
+		historyRepo:    historyRepo,
+		generationRepo: generationRepo,
+		storage:        store,
+		legacyStorage:  local,
+		signedURLTTL:   cfg.SignedDownloadTTL,
+		workers:        workers,
+		retryPolicy:    cfg.RetryPolicy,
+		instanceID:     instanceID,
+		lease:          lease,
+		watermarkAdmin: watermarkAdmin,
+		verifyBaseURL:  cfg.VerifyBaseURL,
+		verifyPayload:  cfg.VerifyPayload,
+		notifier:       cfg.Notifier,
+		settings:       cfg.Settings,
+		renderer:       renderer,
+		logger:         logger,
+		running:        make(map[int64]context.CancelCauseFunc),
+		stopTimeout:    drainStopTimeout,
 	}, nil
 }
 
 // CreateJob creates a new print job
-func (s *PrintService) CreateJob(ctx context.Context, tenantID string, contractID int64, format models.PrintFormat, requestedBy string) (*models.ContractPrintJob, error) {
+func (s *PrintService) CreateJob(ctx context.Context, tenantID string, req *models.CreatePrintJobRequest, requestedBy string) (*models.ContractPrintJob, error) {
+	contractID := req.ContractID
+	format := req.Format
+
+	if req.Watermark != nil && !*req.Watermark && !s.watermarkAdmin[requestedBy] {
+		return nil, ErrWatermarkRequired
+	}
+
 	// Verify contract exists
 	contract, err := s.contractRepo.GetByID(ctx, tenantID, contractID)
 	if err != nil {
@@ -56,10 +257,8 @@ func (s *PrintService) CreateJob(ctx context.Context, tenantID string, contractI
 	if contract == nil {
 		return nil, ErrContractNotFound
 	}
-
-	req := &models.CreatePrintJobRequest{
-		ContractID: contractID,
-		Format:     format,
+	if err := s.checkGeneration(ctx, tenantID, contract, req.Regenerate, requestedBy); err != nil {
+		return nil, err
 	}
 
 	job, err := s.printJobRepo.Create(ctx, tenantID, req, requestedBy)
@@ -85,18 +284,53 @@ func (s *PrintService) CreateJob(ctx context.Context, tenantID string, contractI
 	return job, nil
 }
 
+// checkGeneration makes sure the contract has not changed since its latest
+// generation, whose hash the printed verification code carries. Contracts
+// never generated pass, as the job generates them. A stale contract is
+// generated again when regenerate is set; otherwise a *StaleGenerationError
+// is returned.
+func (s *PrintService) checkGeneration(ctx context.Context, tenantID string, contract *models.Contract, regenerate bool, requestedBy string) error {
+	latest, err := s.generationRepo.GetLatestGeneratedMeta(ctx, tenantID, contract.ID, requestedBy)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !contract.UpdatedAt.After(latest.GeneratedAt) {
+		return nil
+	}
+	if !regenerate {
+		return &StaleGenerationError{models.StaleGeneration{
+			ContractID:        contract.ID,
+			ContractUpdatedAt: contract.UpdatedAt,
+			GeneratedID:       latest.GeneratedID,
+			GeneratedAt:       latest.GeneratedAt,
+		}}
+	}
+
+	generated, err := s.generationRepo.GenerateContract(ctx, repository.GenerateContractParams{
+		TenantID:   tenantID,
+		ContractID: contract.ID,
+		UserID:     requestedBy,
+		Reason:     string(models.GenerationReasonUpdate),
+	})
+	if err != nil {
+		return err
+	}
+	if !generated.Success {
+		return fmt.Errorf("failed to regenerate contract %d: %s: %s", contract.ID, generated.ErrorCode, generated.ErrorMessage)
+	}
+	return nil
+}
+
 // GetJob retrieves a print job by ID
 func (s *PrintService) GetJob(ctx context.Context, tenantID string, id int64) (*models.ContractPrintJob, error) {
 	return s.printJobRepo.GetByID(ctx, tenantID, id)
 }
 
-// GetJobsByContract retrieves print jobs for a contract
-func (s *PrintService) GetJobsByContract(ctx context.Context, tenantID string, contractID int64) ([]models.ContractPrintJob, error) {
-	return s.printJobRepo.GetByContractID(ctx, tenantID, contractID)
-}
-
-// List retrieves all print jobs for a tenant with pagination
-func (s *PrintService) List(ctx context.Context, tenantID string, page, pageSize int) ([]models.ContractPrintJob, int64, error) {
+// List retrieves print jobs for a tenant matching filter, with pagination
+func (s *PrintService) List(ctx context.Context, tenantID string, filter models.PrintJobFilter, page, pageSize int) ([]models.ContractPrintJob, int64, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -107,97 +341,534 @@ func (s *PrintService) List(ctx context.Context, tenantID string, page, pageSize
 	}
 
 	offset := (page - 1) * pageSize
-	return s.printJobRepo.FindAll(ctx, tenantID, offset, pageSize)
+	return s.printJobRepo.FindAll(ctx, tenantID, filter, offset, pageSize)
+}
+
+// ListKeyset retrieves a page of print jobs matching filter, newest first,
+// following params.After, and the cursor of the next page if there is one
+func (s *PrintService) ListKeyset(ctx context.Context, tenantID string, filter models.PrintJobFilter, params models.KeysetParams) ([]models.ContractPrintJob, *models.ListCursor, error) {
+	if params.PageSize < 1 {
+		params.PageSize = 10
+	} else if params.PageSize > 100 {
+		params.PageSize = 100
+	}
+	return s.printJobRepo.FindKeyset(ctx, tenantID, filter, params)
+}
+
+// UpdatePriority changes the priority of a print job that has not been picked up yet
+func (s *PrintService) UpdatePriority(ctx context.Context, tenantID string, id int64, priority models.PrintPriority) (*models.ContractPrintJob, error) {
+	job, err := s.printJobRepo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, ErrPrintJobNotFound
+	}
+	if job.Status != models.PrintJobStatusQueued {
+		return nil, fmt.Errorf("%w: current status is %s", ErrPrintJobNotQueued, job.Status)
+	}
+
+	if err := s.printJobRepo.UpdatePriority(ctx, tenantID, id, priority); err != nil {
+		// A worker claimed the job between the read and the update
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrPrintJobNotQueued
+		}
+		return nil, err
+	}
+
+	return s.printJobRepo.GetByID(ctx, tenantID, id)
 }
 
-// ProcessPendingJobs processes pending print jobs (to be called by a background worker)
+// RetryJob resets a FAILED print job to QUEUED so the background processor picks it up again
+func (s *PrintService) RetryJob(ctx context.Context, tenantID string, id int64) (*models.ContractPrintJob, error) {
+	job, err := s.printJobRepo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, ErrPrintJobNotFound
+	}
+	if job.Status != models.PrintJobStatusFailed {
+		return nil, fmt.Errorf("%w: current status is %s", ErrPrintJobNotRetryable, job.Status)
+	}
+
+	if err := s.printJobRepo.Requeue(ctx, tenantID, id); err != nil {
+		// The job left FAILED between the read and the update
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrPrintJobNotRetryable
+		}
+		return nil, err
+	}
+
+	return s.printJobRepo.GetByID(ctx, tenantID, id)
+}
+
+// CancelJob cancels a print job. Queued and failed jobs move to CANCELLED
+// immediately; a job that is currently rendering is marked CANCELLED and its
+// context is cancelled so processJob aborts and removes any partial output.
+func (s *PrintService) CancelJob(ctx context.Context, tenantID string, id int64) (*models.ContractPrintJob, error) {
+	job, err := s.printJobRepo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, ErrPrintJobNotFound
+	}
+
+	switch job.Status {
+	case models.PrintJobStatusQueued, models.PrintJobStatusProcessing, models.PrintJobStatusFailed:
+	default:
+		return nil, fmt.Errorf("%w: current status is %s", ErrPrintJobNotCancellable, job.Status)
+	}
+
+	if err := s.printJobRepo.Cancel(ctx, tenantID, id, job.Status); err != nil {
+		// The job changed status between the read and the update
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrPrintJobNotCancellable
+		}
+		return nil, err
+	}
+
+	if job.Status == models.PrintJobStatusProcessing {
+		s.runningMu.Lock()
+		if cancel, ok := s.running[id]; ok {
+			cancel(errJobCancelled)
+		}
+		s.runningMu.Unlock()
+	}
+
+	return s.printJobRepo.GetByID(ctx, tenantID, id)
+}
+
+// ProcessPendingJobs processes pending print jobs (to be called by a background worker).
+// Jobs are dispatched to a bounded pool of workers; the call returns once every
+// dispatched job has finished, so callers can rely on it for graceful shutdown.
 func (s *PrintService) ProcessPendingJobs(ctx context.Context) error {
-	jobs, err := s.printJobRepo.GetPendingJobs(ctx, 10)
+	if s.isDraining() {
+		return nil
+	}
+	s.requeueRetryableJobs(ctx)
+
+	jobs, err := s.printJobRepo.GetPendingJobs(ctx, s.workers*pendingJobsPerWorker)
 	if err != nil {
 		return err
 	}
+	if len(jobs) == 0 {
+		return nil
+	}
+
+	jobCh := make(chan models.ContractPrintJob)
+	var wg sync.WaitGroup
+	for range min(s.workers, len(jobs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobCh {
+				s.runJob(ctx, &job)
+			}
+		}()
+	}
 
+dispatch:
 	for _, job := range jobs {
-		if err := s.processJob(ctx, &job); err != nil {
-			s.logger.Error("failed to process print job",
-				"job_id", job.ID,
-				"contract_id", job.ContractID,
-				"error", err,
-			)
+		select {
+		case jobCh <- job:
+		case <-ctx.Done():
+			break dispatch
 		}
 	}
+	close(jobCh)
+	wg.Wait()
 
 	return nil
 }
 
-// processJob processes a single print job
-func (s *PrintService) processJob(ctx context.Context, job *models.ContractPrintJob) error {
-	// Update status to processing
-	if err := s.printJobRepo.UpdateStatus(ctx, job.TenantID, job.ID, repository.UpdateStatusParams{
-		Status: models.PrintJobStatusProcessing,
-	}); err != nil {
-		return err
+// runJob claims a queued job and processes it. Jobs already claimed by another
+// worker are skipped, as is every job once Drain was called.
+func (s *PrintService) runJob(ctx context.Context, job *models.ContractPrintJob) {
+	if !s.beginJob() {
+		return
 	}
+	defer s.inflight.Done()
 
-	// Get contract with items
-	contract, err := s.contractRepo.GetByID(ctx, job.TenantID, job.ContractID)
+	claimed, err := s.printJobRepo.Claim(ctx, job.TenantID, job.ID, s.instanceID, s.lease)
 	if err != nil {
-		if err2 := s.printJobRepo.UpdateStatus(ctx, job.TenantID, job.ID, repository.UpdateStatusParams{
-			Status:   models.PrintJobStatusFailed,
-			ErrorMsg: err.Error(),
-		}); err2 != nil {
-			s.logger.Error("failed to update job status after GetByID error",
+		s.logger.Error("failed to claim print job",
+			"job_id", job.ID,
+			"tenant_id", job.TenantID,
+			"error", err,
+		)
+		return
+	}
+	if !claimed {
+		s.logger.Debug("print job already claimed", "job_id", job.ID, "instance_id", s.instanceID)
+		return
+	}
+
+	if err := s.processJob(ctx, job); err != nil {
+		s.logger.Error("failed to process print job",
+			"job_id", job.ID,
+			"contract_id", job.ContractID,
+			"retry_count", job.RetryCount,
+			"error", err,
+		)
+	}
+}
+
+// requeueRetryableJobs moves failed jobs whose backoff has elapsed back to QUEUED
+func (s *PrintService) requeueRetryableJobs(ctx context.Context) {
+	if s.retryPolicy.MaxRetries <= 0 {
+		return
+	}
+
+	jobs, err := s.printJobRepo.GetRetryableJobs(ctx, s.retryPolicy.MaxRetries, 10)
+	if err != nil {
+		s.logger.Error("failed to get retryable print jobs", "error", err)
+		return
+	}
+
+	for _, job := range jobs {
+		if err := s.printJobRepo.Requeue(ctx, job.TenantID, job.ID); err != nil {
+			s.logger.Error("failed to requeue print job",
 				"job_id", job.ID,
 				"tenant_id", job.TenantID,
-				"original_error", err.Error(),
-				"update_error", err2.Error(),
+				"error", err,
 			)
+			continue
 		}
+		s.logger.Info("requeued failed print job",
+			"job_id", job.ID,
+			"tenant_id", job.TenantID,
+			"attempt", job.RetryCount+1,
+		)
+	}
+}
+
+// processJob renders a single print job that has already been claimed
+func (s *PrintService) processJob(ctx context.Context, job *models.ContractPrintJob) error {
+	jobCtx, cancel := context.WithCancelCause(ctx)
+	s.runningMu.Lock()
+	s.running[job.ID] = cancel
+	s.runningMu.Unlock()
+
+	renewDone := make(chan struct{})
+	go func() {
+		defer close(renewDone)
+		s.renewLease(jobCtx, cancel, job)
+	}()
+
+	defer func() {
+		s.runningMu.Lock()
+		delete(s.running, job.ID)
+		if s.draining && !errors.Is(context.Cause(jobCtx), errShuttingDown) {
+			s.drained++
+		}
+		s.runningMu.Unlock()
+		cancel(nil)
+		<-renewDone
+	}()
+
+	// Get contract with items
+	contract, err := s.contractRepo.GetByID(jobCtx, job.TenantID, job.ContractID)
+	if s.jobAborted(jobCtx, job) {
+		return nil
+	}
+	if err != nil {
+		s.failJob(ctx, job, err)
 		return err
 	}
 	if contract == nil {
-		errMsg := "contract not found"
-		if err2 := s.printJobRepo.UpdateStatus(ctx, job.TenantID, job.ID, repository.UpdateStatusParams{
-			Status:   models.PrintJobStatusFailed,
-			ErrorMsg: errMsg,
-		}); err2 != nil {
-			s.logger.Error("failed to update job status for not found contract",
-				"job_id", job.ID,
-				"tenant_id", job.TenantID,
-				"update_error", err2.Error(),
-			)
-		}
-		return errors.New(errMsg)
+		err := errors.New("contract not found")
+		s.failJob(ctx, job, err)
+		return err
 	}
 
+	s.reportProgress(jobCtx, job, progressDataLoaded)
+
 	// Generate document
-	outputPath, fileSize, pageCount, err := s.generateDocument(contract, job.Format)
+	opts := s.documentOptions(jobCtx, job, contract)
+	progress := func(pct int) { s.reportProgress(jobCtx, job, pct) }
+	outputKey, fileSize, pageCount, err := s.generateDocument(jobCtx, contract, job.Format, opts, progress)
+	if s.jobAborted(jobCtx, job) {
+		s.removeOutput(ctx, outputKey)
+		return nil
+	}
 	if err != nil {
-		if err2 := s.printJobRepo.UpdateStatus(ctx, job.TenantID, job.ID, repository.UpdateStatusParams{
-			Status:   models.PrintJobStatusFailed,
-			ErrorMsg: err.Error(),
-		}); err2 != nil {
-			s.logger.Error("failed to update job status after generateDocument error",
-				"job_id", job.ID,
-				"tenant_id", job.TenantID,
-				"original_error", err.Error(),
-				"update_error", err2.Error(),
-			)
-		}
+		s.failJob(ctx, job, err)
 		return err
 	}
 
-	// Update status to completed
-	return s.printJobRepo.UpdateStatus(ctx, job.TenantID, job.ID, repository.UpdateStatusParams{
-		Status:     models.PrintJobStatusCompleted,
-		OutputPath: outputPath,
-		FileSize:   fileSize,
-		PageCount:  pageCount,
+	// Mark completed, provided we still own the job
+	completed, err := s.printJobRepo.Complete(ctx, job.TenantID, job.ID, s.instanceID, repository.CompleteParams{
+		OutputPath:     outputKey,
+		StorageBackend: s.storage.Name(),
+		FileSize:       fileSize,
+		PageCount:      pageCount,
 	})
+	if err != nil {
+		return err
+	}
+	if !completed {
+		s.logger.Warn("print job lease lost before completion, discarding output",
+			"job_id", job.ID,
+			"tenant_id", job.TenantID,
+			"instance_id", s.instanceID,
+		)
+		s.removeOutput(ctx, outputKey)
+		return nil
+	}
+	s.notifyJob(ctx, job, models.EventPrintJobCompleted)
+	return nil
 }
 
-// generateDocument generates the contract document
-func (s *PrintService) generateDocument(contract *models.Contract, format models.PrintFormat) (string, int64, int, error) {
+// renewLease periodically extends the job lease until jobCtx is done.
+// If the lease is lost the job context is cancelled with errLeaseLost.
+func (s *PrintService) renewLease(jobCtx context.Context, cancel context.CancelCauseFunc, job *models.ContractPrintJob) {
+	ticker := time.NewTicker(s.lease / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-jobCtx.Done():
+			return
+		case <-ticker.C:
+			renewed, err := s.printJobRepo.RenewLease(jobCtx, job.TenantID, job.ID, s.instanceID, s.lease)
+			if err != nil {
+				// Transient errors are tolerated; the lease still has time left
+				s.logger.Warn("failed to renew print job lease",
+					"job_id", job.ID,
+					"tenant_id", job.TenantID,
+					"error", err,
+				)
+				continue
+			}
+			if !renewed {
+				cancel(errLeaseLost)
+				return
+			}
+		}
+	}
+}
+
+// jobAborted reports whether rendering must stop because the job was cancelled
+// by a user, its lease was taken over or the server is shutting down. In the
+// first two cases the job row is owned by someone else, so the caller must not
+// write its status; a job stopped by shutdown is handed back to the queue here.
+func (s *PrintService) jobAborted(jobCtx context.Context, job *models.ContractPrintJob) bool {
+	cause := context.Cause(jobCtx)
+	switch {
+	case errors.Is(cause, errShuttingDown), errors.Is(cause, context.Canceled):
+		s.releaseJob(context.WithoutCancel(jobCtx), job)
+	case errors.Is(cause, errJobCancelled):
+		s.logger.Info("print job cancelled during processing",
+			"job_id", job.ID,
+			"tenant_id", job.TenantID,
+		)
+	case errors.Is(cause, errLeaseLost):
+		s.logger.Warn("print job lease lost during processing",
+			"job_id", job.ID,
+			"tenant_id", job.TenantID,
+			"instance_id", s.instanceID,
+		)
+	default:
+		return false
+	}
+	return true
+}
+
+// reportProgress records a rendering stage. Failures are logged only; progress
+// is informational and must not fail the job.
+func (s *PrintService) reportProgress(ctx context.Context, job *models.ContractPrintJob, pct int) {
+	if err := s.printJobRepo.UpdateProgress(ctx, job.TenantID, job.ID, s.instanceID, pct); err != nil && ctx.Err() == nil {
+		s.logger.Warn("failed to record print job progress",
+			"job_id", job.ID,
+			"tenant_id", job.TenantID,
+			"progress_pct", pct,
+			"error", err,
+		)
+	}
+}
+
+// removeOutput deletes output that will not be recorded on the job. It runs
+// even if ctx is already cancelled so shutdown does not leave orphaned objects.
+func (s *PrintService) removeOutput(ctx context.Context, key string) {
+	if key == "" {
+		return
+	}
+	if err := s.storage.Delete(context.WithoutCancel(ctx), key); err != nil {
+		s.logger.Error("failed to remove partial print output",
+			"key", key,
+			"backend", s.storage.Name(),
+			"error", err,
+		)
+	}
+}
+
+// outputLocation returns the backend and object key holding a job's output.
+// Jobs completed before backends were recorded store a local filesystem path.
+func (s *PrintService) outputLocation(job *models.ContractPrintJob) (storage.Storage, string, error) {
+	switch job.StorageBackend {
+	case "":
+		key, err := s.legacyStorage.KeyFromPath(job.OutputPath)
+		return s.legacyStorage, key, err
+	case s.storage.Name():
+		return s.storage, job.OutputPath, nil
+	case storage.BackendLocal:
+		return s.legacyStorage, job.OutputPath, nil
+	default:
+		return nil, "", fmt.Errorf("print output stored in unconfigured backend %q", job.StorageBackend)
+	}
+}
+
+// failJob records a failed attempt. Jobs with retries left are scheduled for
+// an automatic retry after an exponential backoff; jobs that exhausted their
+// retries move to the terminal DEAD status with the last error kept. Nothing
+// is written once the lease was taken over by another instance.
+func (s *PrintService) failJob(ctx context.Context, job *models.ContractPrintJob, cause error) {
+	params := repository.FailParams{ErrorMsg: cause.Error()}
+	switch {
+	case s.retryPolicy.MaxRetries <= 0:
+		// No automatic retries; the job waits for a manual one
+	case job.RetryCount < s.retryPolicy.MaxRetries:
+		nextRetryAt := time.Now().Add(s.retryPolicy.backoff(job.RetryCount))
+		params.NextRetryAt = &nextRetryAt
+	default:
+		s.logger.Warn("print job exhausted retries",
+			"job_id", job.ID,
+			"tenant_id", job.TenantID,
+			"retry_count", job.RetryCount,
+		)
+		params.Dead = true
+	}
+
+	owned, err := s.printJobRepo.MarkFailed(ctx, job.TenantID, job.ID, s.instanceID, params)
+	if err != nil {
+		s.logger.Error("failed to update job status after processing error",
+			"job_id", job.ID,
+			"tenant_id", job.TenantID,
+			"original_error", cause.Error(),
+			"update_error", err.Error(),
+		)
+		return
+	}
+	if !owned {
+		s.logger.Warn("print job lease lost before failure was recorded",
+			"job_id", job.ID,
+			"tenant_id", job.TenantID,
+			"instance_id", s.instanceID,
+			"original_error", cause.Error(),
+		)
+		return
+	}
+	if params.NextRetryAt == nil {
+		s.notifyJob(ctx, job, models.EventPrintJobFailed)
+	}
+}
+
+// notifyJob publishes a print job event carrying the job's current state
+func (s *PrintService) notifyJob(ctx context.Context, job *models.ContractPrintJob, event models.EventType) {
+	if s.notifier == nil {
+		return
+	}
+	current, err := s.printJobRepo.GetByID(ctx, job.TenantID, job.ID)
+	if err != nil || current == nil {
+		s.logger.Warn("failed to load print job for notification",
+			"job_id", job.ID,
+			"tenant_id", job.TenantID,
+			"event", event,
+			"error", err,
+		)
+		return
+	}
+	s.notifier.Notify(ctx, job.TenantID, event, current.ToResponse())
+}
+
+// documentOptions controls the decorations added to a rendered document
+type documentOptions struct {
+	Watermark   string // empty means no watermark
+	VerifyQR    string // inline SVG; empty when verification is disabled or unavailable
+	GeneratedAt time.Time
+}
+
+// documentOptions decides how a job's document is decorated. Contracts that are
+// neither active nor signed get the draft watermark unless the job opted out.
+func (s *PrintService) documentOptions(ctx context.Context, job *models.ContractPrintJob, contract *models.Contract) documentOptions {
+	opts := documentOptions{GeneratedAt: time.Now(), VerifyQR: s.verificationQR(ctx, job, contract)}
+	if !job.Watermark || contract.Status == models.ContractStatusActive || contract.SignedAt != nil {
+		return opts
+	}
+
+	opts.Watermark = s.settings.Defaults().WatermarkText
+	settings, err := s.settings.Get(ctx, job.TenantID)
+	if err != nil {
+		s.logger.Warn("failed to load tenant watermark text, using default",
+			"tenant_id", job.TenantID,
+			"error", err,
+		)
+	} else {
+		opts.Watermark = settings.WatermarkText
+	}
+	return opts
+}
+
+// verificationQR renders a QR code pointing at the public verification endpoint
+// for the contract's latest generated snapshot, generating one if none exists.
+// Failures are logged and leave the document without a code rather than failing the job.
+func (s *PrintService) verificationQR(ctx context.Context, job *models.ContractPrintJob, contract *models.Contract) string {
+	if s.verifyBaseURL == "" || s.verifyPayload == "" {
+		return ""
+	}
+
+	var contentHash string
+	latest, err := s.generationRepo.GetLatestGeneratedMeta(ctx, job.TenantID, contract.ID, job.RequestedBy)
+	switch {
+	case err == nil:
+		contentHash = latest.ContentHash
+	case errors.Is(err, repository.ErrNotFound):
+		generated, genErr := s.generationRepo.GenerateContract(ctx, repository.GenerateContractParams{
+			TenantID:   job.TenantID,
+			ContractID: contract.ID,
+			UserID:     job.RequestedBy,
+			Reason:     string(models.GenerationReasonInitial),
+		})
+		if genErr != nil {
+			err = genErr
+		} else if !generated.Success {
+			err = fmt.Errorf("%s: %s", generated.ErrorCode, generated.ErrorMessage)
+		} else {
+			contentHash, err = generated.ContentHash, nil
+		}
+	}
+	if err != nil || contentHash == "" {
+		s.logger.Warn("printing without verification code",
+			"job_id", job.ID,
+			"contract_id", contract.ID,
+			"error", err,
+		)
+		return ""
+	}
+
+	payload := strings.NewReplacer(
+		"{base_url}", s.verifyBaseURL,
+		"{hash}", contentHash,
+		"{contract_id}", fmt.Sprintf("%d", contract.ID),
+	).Replace(s.verifyPayload)
+
+	code, err := qrcode.Encode(payload)
+	if err != nil {
+		s.logger.Warn("failed to encode verification code",
+			"job_id", job.ID,
+			"error", err,
+		)
+		return ""
+	}
+	return code.SVG(2)
+}
+
+// generateDocument renders the contract document and stores it, returning its object key.
+// The context is checked between rendering stages; on cancellation the key is
+// still returned so the caller can remove an object that was already stored.
+func (s *PrintService) generateDocument(ctx context.Context, contract *models.Contract, format models.PrintFormat, opts documentOptions, progress func(pct int)) (string, int64, int, error) {
 	// Sanitize contract number for safe filename
 	safeContractNumber := sanitizeFilename(contract.ContractNumber)
 	if safeContractNumber == "" {
@@ -220,38 +891,31 @@ func (s *PrintService) generateDocument(contract *models.Contract, format models
 		ext = ".pdf"
 	}
 
-	outputPath := filepath.Join(s.outputDir, contract.TenantID, filename+ext)
-
-	// Ensure tenant directory exists
-	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
-		return "", 0, 0, fmt.Errorf("failed to create output directory: %w", err)
-	}
+	key := path.Join(sanitizeFilename(contract.TenantID), filename+ext)
 
 	// Generate HTML content (base for all formats)
-	htmlContent := s.generateHTML(contract)
+	htmlContent := s.generateHTML(contract, opts)
+	if err := ctx.Err(); err != nil {
+		return "", 0, 0, err
+	}
+	progress(progressTemplateRendered)
 
-	switch format {
-	case models.PrintFormatHTML:
-		if err := os.WriteFile(outputPath, []byte(htmlContent), 0644); err != nil {
-			return "", 0, 0, fmt.Errorf("failed to write HTML: %w", err)
-		}
-	case models.PrintFormatPDF:
-		// NOTE: PDF conversion requires external dependency (wkhtmltopdf or chromedp)
-		return "", 0, 0, fmt.Errorf("%w: PDF export not implemented", ErrFormatNotSupported)
-	case models.PrintFormatDOCX:
-		// NOTE: DOCX conversion requires external dependency (unioffice)
-		return "", 0, 0, fmt.Errorf("%w: DOCX export not implemented", ErrFormatNotSupported)
-	default:
-		return "", 0, 0, fmt.Errorf("%w: unrecognized format %s", ErrFormatNotSupported, format)
+	var content bytes.Buffer
+	if err := s.renderer.Render(ctx, &content, htmlContent, format); err != nil {
+		return "", 0, 0, err
 	}
+	size := int64(content.Len())
 
-	// Get file info
-	info, err := os.Stat(outputPath)
-	if err != nil {
-		return "", 0, 0, fmt.Errorf("failed to stat output file: %w", err)
+	progress(progressPagesComposed)
+
+	if err := s.storage.Put(ctx, key, &content, size, printFormatContentTypes[format]); err != nil {
+		return "", 0, 0, fmt.Errorf("failed to store print output: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return key, 0, 0, err
 	}
 
-	return outputPath, info.Size(), 1, nil // pageCount is estimated
+	return key, size, 1, nil // pageCount is estimated
 }
 
 // sanitizeFilename removes or replaces characters that are unsafe for filenames
@@ -267,7 +931,7 @@ func sanitizeFilename(name string) string {
 }
 
 // generateHTML generates HTML content for the contract
-func (s *PrintService) generateHTML(contract *models.Contract) string {
+func (s *PrintService) generateHTML(contract *models.Contract, opts documentOptions) string {
 	// Escape user-provided content to prevent XSS
 	escapedContractNumber := html.EscapeString(contract.ContractNumber)
 	escapedContractType := html.EscapeString(string(contract.ContractType))
@@ -287,10 +951,21 @@ func (s *PrintService) generateHTML(contract *models.Contract) string {
         table { width: 100%%; border-collapse: collapse; margin: 20px 0; }
         th, td { border: 1px solid #ddd; padding: 10px; text-align: left; }
         th { background-color: #f5f5f5; }
+        .amount { text-align: right; margin: 4px 0; }
         .total { font-size: 1.2em; font-weight: bold; text-align: right; }
+        @page { margin: 20mm 15mm 25mm 15mm; @bottom-right { content: "Page " counter(page) " of " counter(pages); font-size: 9pt; } }
+        .watermark { position: fixed; top: 45%%; left: 0; width: 100%%; text-align: center; transform: rotate(-35deg);
+            font-size: 56px; font-weight: bold; color: rgba(200, 0, 0, 0.15); z-index: 1000; pointer-events: none; }
+        .footer { position: fixed; bottom: 0; left: 0; right: 0; font-size: 9pt; color: #666;
+            border-top: 1px solid #ddd; padding-top: 4px; }
+        .footer .page-number::after { content: "Page " counter(page) " of " counter(pages); float: right; }
+        .footer .verify { float: right; margin-left: 12px; text-align: center; font-size: 7pt; }
+        .footer .verify svg { display: block; width: 20mm; height: 20mm; }
     </style>
 </head>
 <body>
+    %s
+    <div class="footer">%sContract %s &middot; Generated %s<span class="page-number"></span></div>
     <h1>Service Contract</h1>
     <div class="section">
         <p><span class="label">Contract Number:</span> %s</p>
@@ -309,6 +984,10 @@ func (s *PrintService) generateHTML(contract *models.Contract) string {
             <th>Total</th>
         </tr>`,
 		escapedContractNumber,
+		watermarkHTML(opts.Watermark),
+		verifyHTML(opts.VerifyQR),
+		escapedContractNumber,
+		opts.GeneratedAt.Format("2006-01-02 15:04:05 MST"),
 		escapedContractNumber,
 		escapedContractType,
 		escapedStatus,
@@ -339,7 +1018,10 @@ func (s *PrintService) generateHTML(contract *models.Contract) string {
 
 	htmlContent += fmt.Sprintf(`
     </table>
-    <p class="total">Total: R$ %.2f</p>
+    <p class="amount">Subtotal: R$ %s</p>
+    <p class="amount">Discount (%s%%): - R$ %s</p>
+    <p class="amount">Tax (%s%%): R$ %s</p>
+    <p class="total">Total: R$ %s</p>
     
     <div class="section">
         <h2>Terms and Conditions</h2>
@@ -347,37 +1029,285 @@ func (s *PrintService) generateHTML(contract *models.Contract) string {
     </div>
 </body>
 </html>`,
-		contract.TotalValue.InexactFloat64(),
+		contract.Subtotal.StringFixed(2),
+		contract.DiscountPct.String(), contract.DiscountAmount.StringFixed(2),
+		contract.TaxPct.String(), contract.TaxAmount.StringFixed(2),
+		contract.TotalValue.StringFixed(2),
 		escapedTermsConditions,
 	)
 
 	return htmlContent
 }
 
-// DownloadJob returns the file path for a completed job
-func (s *PrintService) DownloadJob(ctx context.Context, tenantID string, jobID int64) (string, error) {
+// watermarkHTML renders the fixed-position watermark element, repeated on every printed page
+func watermarkHTML(text string) string {
+	if text == "" {
+		return ""
+	}
+	return `<div class="watermark">` + html.EscapeString(text) + `</div>`
+}
+
+// verifyHTML wraps the verification QR code for the footer; svg is generated, not user input
+func verifyHTML(svg string) string {
+	if svg == "" {
+		return ""
+	}
+	return `<div class="verify">` + svg + `Scan to verify</div>`
+}
+
+// PrintJobDownload describes a completed print job output ready to be served.
+// Either RedirectURL is set, or Body is open and must be closed by the caller.
+type PrintJobDownload struct {
+	RedirectURL string
+	Body        io.ReadCloser
+	FileName    string
+	ContentType string
+	Size        int64
+}
+
+// DownloadRequest identifies the job being downloaded and who is downloading it
+type DownloadRequest struct {
+	TenantID  string
+	JobID     int64
+	UserID    string
+	IPAddress string
+	SessionID string
+}
+
+// printFormatContentTypes maps output formats to their MIME types
+var printFormatContentTypes = map[models.PrintFormat]string{
+	models.PrintFormatPDF:  "application/pdf",
+	models.PrintFormatHTML: "text/html; charset=utf-8",
+	models.PrintFormatDOCX: "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+}
+
+// DownloadJob opens the output of a completed job, or signs a URL for it when
+// signed downloads are enabled and the backend supports them, and records the download
+func (s *PrintService) DownloadJob(ctx context.Context, req DownloadRequest) (*PrintJobDownload, error) {
+	job, store, key, download, err := s.locateOutput(ctx, req.TenantID, req.JobID)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.signedURLTTL > 0 {
+		signedURL, err := store.SignedURL(ctx, key, s.signedURLTTL, download.FileName)
+		switch {
+		case err == nil:
+			download.RedirectURL = signedURL
+		case errors.Is(err, storage.ErrSignedURLUnsupported):
+			// Stream through the server instead
+		default:
+			return nil, fmt.Errorf("failed to sign print output URL: %w", err)
+		}
+	}
+	if download.RedirectURL == "" {
+		if err := openOutput(ctx, store, key, download); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.generationRepo.LogContractAction(ctx, repository.LogActionParams{
+		TenantID:   req.TenantID,
+		ContractID: job.ContractID,
+		Action:     string(models.GenerationActionDownload),
+		UserID:     req.UserID,
+		IPAddress:  req.IPAddress,
+		SessionID:  req.SessionID,
+		Status:     "SUCCESS",
+	}); err != nil {
+		// Logging failure shouldn't block the download
+		s.logger.Error("failed to log print job download",
+			"job_id", job.ID,
+			"contract_id", job.ContractID,
+			"error", err,
+		)
+	}
+
+	return download, nil
+}
+
+// OpenOutput opens the output of a completed job for internal consumers such
+// as notification emails. It always streams and does not record a download.
+func (s *PrintService) OpenOutput(ctx context.Context, tenantID string, jobID int64) (*PrintJobDownload, error) {
+	_, store, key, download, err := s.locateOutput(ctx, tenantID, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if err := openOutput(ctx, store, key, download); err != nil {
+		return nil, err
+	}
+	return download, nil
+}
+
+// locateOutput loads a completed job and resolves where its output is stored.
+// The returned download has its file name and content type filled in.
+func (s *PrintService) locateOutput(ctx context.Context, tenantID string, jobID int64) (*models.ContractPrintJob, storage.Storage, string, *PrintJobDownload, error) {
 	job, err := s.printJobRepo.GetByID(ctx, tenantID, jobID)
 	if err != nil {
-		return "", err
+		return nil, nil, "", nil, err
 	}
 	if job == nil {
-		return "", ErrPrintJobNotFound
+		return nil, nil, "", nil, ErrPrintJobNotFound
 	}
 
+	if job.Status == models.PrintJobStatusPurged {
+		return nil, nil, "", nil, ErrOutputPurged
+	}
 	if job.Status != models.PrintJobStatusCompleted {
-		return "", fmt.Errorf("%w: current status is %s", ErrJobNotCompleted, job.Status)
+		return nil, nil, "", nil, fmt.Errorf("%w: current status is %s", ErrJobNotCompleted, job.Status)
 	}
 
 	if job.OutputPath == "" {
-		return "", ErrOutputFileNotFound
+		return nil, nil, "", nil, ErrOutputFileNotFound
 	}
 
-	// Verify the file exists on disk
-	if _, err := os.Stat(job.OutputPath); os.IsNotExist(err) {
-		return "", ErrOutputFileNotFound
+	store, key, err := s.outputLocation(job)
+	if err != nil {
+		s.logger.Warn("rejected print job output path",
+			"job_id", job.ID,
+			"tenant_id", job.TenantID,
+			"path", job.OutputPath,
+			"backend", job.StorageBackend,
+			"error", err,
+		)
+		return nil, nil, "", nil, ErrOutputFileNotFound
+	}
+
+	contentType, ok := printFormatContentTypes[job.Format]
+	if !ok {
+		contentType = "application/octet-stream"
+	}
+
+	fileName := path.Base(key)
+	contract, err := s.contractRepo.GetByID(ctx, tenantID, job.ContractID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		s.logger.Warn("failed to load contract for download filename",
+			"job_id", job.ID,
+			"contract_id", job.ContractID,
+			"error", err,
+		)
+	}
+	if contract != nil {
+		if safe := sanitizeFilename(contract.ContractNumber); safe != "" {
+			fileName = "contract_" + safe + path.Ext(key)
+		}
+	}
+
+	return job, store, key, &PrintJobDownload{FileName: fileName, ContentType: contentType}, nil
+}
+
+// openOutput opens the stored object into download.Body
+func openOutput(ctx context.Context, store storage.Storage, key string, download *PrintJobDownload) error {
+	obj, err := store.Get(ctx, key)
+	if errors.Is(err, storage.ErrNotFound) || errors.Is(err, storage.ErrInvalidKey) {
+		return ErrOutputFileNotFound
 	} else if err != nil {
-		return "", fmt.Errorf("failed to access output file: %w", err)
+		return fmt.Errorf("failed to access output file: %w", err)
 	}
+	download.Body = obj.Body
+	download.Size = obj.Size
+	return nil
+}
+
+// CountJobsByStatus counts print jobs of all tenants grouped by status
+func (s *PrintService) CountJobsByStatus(ctx context.Context) (map[models.PrintJobStatus]int64, error) {
+	return s.printJobRepo.CountByStatus(ctx)
+}
 
-	return job.OutputPath, nil
+// PurgeExpiredOutputs deletes output files of jobs completed longer ago than
+// their tenant's print retention and marks those jobs PURGED. Tenants with a
+// retention of 0 keep outputs forever. Failures on individual files are
+// logged and skipped so one bad file does not stop the batch. Returns the
+// number of jobs purged.
+func (s *PrintService) PurgeExpiredOutputs(ctx context.Context) (int, error) {
+	overrides, defaultDays, err := s.settings.RetentionDays(ctx)
+	if err != nil {
+		return 0, err
+	}
+	retentionDays := func(tenantID string) int {
+		if days, ok := overrides[tenantID]; ok {
+			return days
+		}
+		return defaultDays
+	}
+
+	// Candidates are jobs older than the shortest retention in use; each is
+	// then checked against its own tenant's
+	shortest := defaultDays
+	for _, days := range overrides {
+		if days > 0 && (shortest <= 0 || days < shortest) {
+			shortest = days
+		}
+	}
+	if shortest <= 0 {
+		return 0, nil
+	}
+	now := time.Now()
+	cutoff := now.AddDate(0, 0, -shortest)
+
+	purged := 0
+	var afterID int64
+	for {
+		jobs, err := s.printJobRepo.GetPurgeableJobs(ctx, cutoff, afterID, purgeBatchSize)
+		if err != nil {
+			return purged, err
+		}
+
+		for _, job := range jobs {
+			afterID = job.ID
+			if ctx.Err() != nil {
+				return purged, ctx.Err()
+			}
+			days := retentionDays(job.TenantID)
+			if days <= 0 || job.CompletedAt == nil || job.CompletedAt.After(now.AddDate(0, 0, -days)) {
+				continue
+			}
+			if s.purgeJobOutput(ctx, &job) {
+				purged++
+			}
+		}
+
+		if len(jobs) < purgeBatchSize {
+			return purged, nil
+		}
+	}
+}
+
+// purgeJobOutput removes a single job's output and marks the job PURGED.
+// Legacy paths resolving outside the output directory are never touched.
+func (s *PrintService) purgeJobOutput(ctx context.Context, job *models.ContractPrintJob) bool {
+	if job.OutputPath != "" {
+		store, key, err := s.outputLocation(job)
+		if err != nil {
+			s.logger.Warn("skipping expired print output outside output directory",
+				"job_id", job.ID,
+				"tenant_id", job.TenantID,
+				"path", job.OutputPath,
+				"backend", job.StorageBackend,
+				"error", err,
+			)
+			return false
+		}
+		// Deleting an object that is already gone succeeds, so the job is still
+		// flagged and downloads report it as purged
+		if err := store.Delete(ctx, key); err != nil {
+			s.logger.Error("failed to delete expired print output",
+				"job_id", job.ID,
+				"tenant_id", job.TenantID,
+				"key", key,
+				"error", err,
+			)
+			return false
+		}
+	}
+
+	if err := s.printJobRepo.MarkPurged(ctx, job.TenantID, job.ID); err != nil {
+		s.logger.Error("failed to mark print job purged",
+			"job_id", job.ID,
+			"tenant_id", job.TenantID,
+			"error", err,
+		)
+		return false
+	}
+	return true
 }
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/zlovtnik/gprint/internal/models"
+)
+
+// Renderer converts a finished HTML document to an output format. Print jobs
+// and on-demand renders of generated contracts share one Renderer, so a
+// document looks the same whichever way it was produced. Render streams the
+// document to w; an unsupported format must fail before anything is written.
+type Renderer interface {
+	Render(ctx context.Context, w io.Writer, html string, format models.PrintFormat) error
+}
+
+// HTMLRenderer is the built-in Renderer. It outputs HTML as is; other
+// formats need a conversion engine and fail with ErrFormatNotSupported.
+type HTMLRenderer struct{}
+
+// Render implements Renderer
+func (HTMLRenderer) Render(ctx context.Context, w io.Writer, html string, format models.PrintFormat) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	switch format {
+	case models.PrintFormatHTML:
+		_, err := io.WriteString(w, html)
+		return err
+	case models.PrintFormatPDF:
+		// NOTE: PDF conversion requires external dependency (wkhtmltopdf or chromedp)
+		return fmt.Errorf("%w: PDF export not implemented", ErrFormatNotSupported)
+	case models.PrintFormatDOCX:
+		// NOTE: DOCX conversion requires external dependency (unioffice)
+		return fmt.Errorf("%w: DOCX export not implemented", ErrFormatNotSupported)
+	default:
+		return fmt.Errorf("%w: unrecognized format %s", ErrFormatNotSupported, format)
+	}
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/zlovtnik/gprint/internal/models"
+	"github.com/zlovtnik/gprint/internal/repository"
+	"golang.org/x/sync/errgroup"
+)
+
+// ErrSearchQueryTooShort indicates a search query below models.MinSearchQueryLength
+var ErrSearchQueryTooShort = errors.New("search query is too short")
+
+// Search result limits per type
+const (
+	DefaultSearchLimit = 5
+	MaxSearchLimit     = 20
+)
+
+// SearchService finds customers, contracts and services from one query
+type SearchService struct {
+	customers *repository.CustomerRepository
+	contracts *repository.ContractRepository
+	services  *repository.ServiceRepository
+}
+
+// NewSearchService creates a new SearchService
+func NewSearchService(customers *repository.CustomerRepository, contracts *repository.ContractRepository, services *repository.ServiceRepository) *SearchService {
+	return &SearchService{customers: customers, contracts: contracts, services: services}
+}
+
+// Search looks query up in the tenant's customers, contracts and services
+// concurrently, returning up to limit hits of each type. The first failure
+// cancels the other lookups and is returned.
+func (s *SearchService) Search(ctx context.Context, tenantID, query string, limit int) (*models.SearchResponse, error) {
+	query = strings.TrimSpace(query)
+	if utf8.RuneCountInString(query) < models.MinSearchQueryLength {
+		return nil, ErrSearchQueryTooShort
+	}
+	if limit < 1 {
+		limit = DefaultSearchLimit
+	} else if limit > MaxSearchLimit {
+		limit = MaxSearchLimit
+	}
+
+	resp := &models.SearchResponse{Query: query}
+	lookups := []struct {
+		name   string
+		group  *models.SearchGroup
+		search func(ctx context.Context, tenantID, query string, limit int) ([]models.SearchSummary, int, error)
+	}{
+		{"customers", &resp.Customers, s.customers.SearchSummary},
+		{"contracts", &resp.Contracts, s.contracts.SearchSummary},
+		{"services", &resp.Services, s.services.SearchSummary},
+	}
+
+	g, ctx := errgroup.WithContext(ctx)
+	for _, l := range lookups {
+		g.Go(func() error {
+			results, count, err := l.search(ctx, tenantID, query, limit)
+			if err != nil {
+				return fmt.Errorf("failed to search %s: %w", l.name, err)
+			}
+			*l.group = models.SearchGroup{Count: count, Results: results}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
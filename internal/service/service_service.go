@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 
 	"github.com/zlovtnik/gprint/internal/models"
 	"github.com/zlovtnik/gprint/internal/repository"
@@ -9,22 +11,32 @@ import (
 
 // ServiceService handles service business logic
 type ServiceService struct {
-	repo *repository.ServiceRepository
+	repo     *repository.ServiceRepository
+	settings *TenantSettingsService
 }
 
 // NewServiceService creates a new ServiceService
-func NewServiceService(repo *repository.ServiceRepository) *ServiceService {
-	return &ServiceService{repo: repo}
+func NewServiceService(repo *repository.ServiceRepository, settings *TenantSettingsService) *ServiceService {
+	return &ServiceService{repo: repo, settings: settings}
 }
 
-// Create creates a new service
+// Create creates a new service. A request without a currency gets the
+// tenant's.
 func (s *ServiceService) Create(ctx context.Context, tenantID string, req *models.CreateServiceRequest, createdBy string) (*models.Service, error) {
+	if req.Currency == "" {
+		settings, err := s.settings.Get(ctx, tenantID)
+		if err != nil {
+			return nil, err
+		}
+		req.Currency = settings.Currency
+	}
 	return s.repo.Create(ctx, tenantID, req, createdBy)
 }
 
 // GetByID retrieves a service by ID
 func (s *ServiceService) GetByID(ctx context.Context, tenantID string, id int64) (*models.Service, error) {
-	return s.repo.GetByID(ctx, tenantID, id)
+	svc, err := s.repo.GetByID(ctx, tenantID, id)
+	return svc, notFoundAs(err, ErrServiceNotFound)
 }
 
 // List retrieves services with pagination
@@ -34,15 +46,26 @@ func (s *ServiceService) List(ctx context.Context, tenantID string, params model
 
 // Update updates a service
 func (s *ServiceService) Update(ctx context.Context, tenantID string, id int64, req *models.UpdateServiceRequest, updatedBy string) (*models.Service, error) {
-	return s.repo.Update(ctx, tenantID, id, req, updatedBy)
+	svc, err := s.repo.Update(ctx, tenantID, id, req, updatedBy)
+	return svc, notFoundAs(err, ErrServiceNotFound)
 }
 
 // Delete soft-deletes a service
 func (s *ServiceService) Delete(ctx context.Context, tenantID string, id int64, deletedBy string) error {
-	return s.repo.Delete(ctx, tenantID, id, deletedBy)
+	return notFoundAs(s.repo.Delete(ctx, tenantID, id, deletedBy), ErrServiceNotFound)
 }
 
 // GetCategories retrieves distinct categories
 func (s *ServiceService) GetCategories(ctx context.Context, tenantID string) ([]string, error) {
 	return s.repo.GetCategories(ctx, tenantID)
 }
+
+// notFoundAs replaces sql.ErrNoRows, which the repository returns for a
+// missing row, with the given sentinel so handlers can answer 404 while
+// every other failure stays a 500
+func notFoundAs(err, sentinel error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return sentinel
+	}
+	return err
+}
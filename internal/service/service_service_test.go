@@ -0,0 +1,34 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestNotFoundAs(t *testing.T) {
+	driverErr := errors.New("ORA-03113: end-of-file on communication channel")
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"nil", nil, nil},
+		{"no rows", sql.ErrNoRows, ErrServiceNotFound},
+		{"wrapped no rows", fmt.Errorf("failed to get service: %w", sql.ErrNoRows), ErrServiceNotFound},
+		{"driver error", driverErr, driverErr},
+		{"wrapped driver error", fmt.Errorf("failed to get service: %w", driverErr), driverErr},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := notFoundAs(tt.err, ErrServiceNotFound)
+			if !errors.Is(got, tt.want) || (tt.want == nil && got != nil) {
+				t.Errorf("notFoundAs(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+			if tt.want != ErrServiceNotFound && errors.Is(got, ErrServiceNotFound) {
+				t.Errorf("notFoundAs(%v) reported not found", tt.err)
+			}
+		})
+	}
+}
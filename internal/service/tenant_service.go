@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+
+	"github.com/zlovtnik/gprint/internal/models"
+	"github.com/zlovtnik/gprint/internal/repository"
+)
+
+// TenantService provisions tenants
+type TenantService struct {
+	repo     *repository.TenantRepository
+	genRepo  *repository.ContractGenerationRepository
+	settings *TenantSettingsService
+}
+
+// NewTenantService creates a new TenantService
+func NewTenantService(repo *repository.TenantRepository, genRepo *repository.ContractGenerationRepository, settings *TenantSettingsService) *TenantService {
+	return &TenantService{repo: repo, genRepo: genRepo, settings: settings}
+}
+
+// Provision creates whatever the tenant is missing: the tenant row, the
+// default contract template and the settings row. Steps already done are
+// reported as skipped, so provisioning can be rerun after a failure. An
+// existing tenant keeps its display name and settings.
+func (s *TenantService) Provision(ctx context.Context, req *models.ProvisionTenantRequest, userID string) (*models.ProvisionResult, error) {
+	result := &models.ProvisionResult{TenantID: req.TenantID}
+	record := func(step string, created bool) {
+		status := models.ProvisionSkipped
+		if created {
+			status = models.ProvisionCreated
+		}
+		result.Steps = append(result.Steps, models.ProvisionStep{Step: step, Status: status})
+	}
+
+	created, err := s.repo.CreateTenant(ctx, req.TenantID, req.DisplayName, userID)
+	if err != nil {
+		return nil, err
+	}
+	record(models.ProvisionStepTenant, created)
+
+	// init_default_template is itself a no-op when a default exists, but
+	// checking first lets the result say so
+	hasTemplate, err := s.genRepo.HasDefaultTemplate(ctx, req.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	if !hasTemplate {
+		if err := s.genRepo.InitTenantTemplate(ctx, req.TenantID, userID); err != nil {
+			return nil, err
+		}
+	}
+	record(models.ProvisionStepTemplate, !hasTemplate)
+
+	// New tenants keep today's defaults even if the server's change later
+	defaults := s.settings.Defaults()
+	created, err = s.repo.CreateSettings(ctx, req.TenantID, &models.TenantSettingsOverrides{
+		Currency:              &defaults.Currency,
+		Locale:                &defaults.Locale,
+		ContractNumberPattern: &defaults.ContractNumberPattern,
+		PrintRetentionDays:    &defaults.PrintRetentionDays,
+		RoundingMode:          &defaults.RoundingMode,
+	}, userID)
+	if err != nil {
+		return nil, err
+	}
+	if created {
+		s.settings.Invalidate(req.TenantID)
+	}
+	record(models.ProvisionStepSettings, created)
+
+	return result, nil
+}
+
+// Status reports which provisioning steps the tenant has completed
+func (s *TenantService) Status(ctx context.Context, tenantID string) (*models.TenantStatus, error) {
+	status := &models.TenantStatus{TenantID: tenantID}
+
+	tenant, err := s.repo.GetTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if tenant != nil {
+		status.Tenant = true
+		status.DisplayName = tenant.DisplayName
+	}
+	if status.DefaultTemplate, err = s.genRepo.HasDefaultTemplate(ctx, tenantID); err != nil {
+		return nil, err
+	}
+	if status.Settings, err = s.repo.HasSettings(ctx, tenantID); err != nil {
+		return nil, err
+	}
+	status.Complete = status.Tenant && status.DefaultTemplate && status.Settings
+	return status, nil
+}
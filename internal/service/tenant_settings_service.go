@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/zlovtnik/gprint/internal/models"
+	"github.com/zlovtnik/gprint/internal/repository"
+)
+
+// Server defaults for settings a tenant has not overridden
+const (
+	DefaultCurrency              = "BRL"
+	DefaultLocale                = "pt-BR"
+	DefaultContractNumberPattern = "CT-{YYYY}-" + models.ContractNumberSeq
+	DefaultWatermarkText         = "DRAFT — NOT LEGALLY BINDING"
+	DefaultRoundingMode          = models.RoundingHalfEven
+)
+
+// tenantSettingsCacheTTL bounds how long an update made on another instance
+// takes to apply here; updates on this instance apply at once
+const tenantSettingsCacheTTL = 5 * time.Minute
+
+// TenantSettingsService provides tenants' effective settings to the services
+// that depend on them. Settings are cached per tenant and the cache entry is
+// dropped when the tenant's settings are updated.
+type TenantSettingsService struct {
+	repo     *repository.TenantRepository
+	defaults models.TenantSettings
+
+	mu    sync.Mutex
+	cache map[string]cachedTenantSettings
+}
+
+type cachedTenantSettings struct {
+	overrides *models.TenantSettingsOverrides // nil when the tenant has none
+	expires   time.Time
+}
+
+// NewTenantSettingsService creates a new TenantSettingsService. Empty
+// defaults are replaced by the Default constants; PrintRetentionDays is
+// used as given, 0 keeping outputs forever.
+func NewTenantSettingsService(repo *repository.TenantRepository, defaults models.TenantSettings) *TenantSettingsService {
+	if defaults.Currency == "" {
+		defaults.Currency = DefaultCurrency
+	}
+	if defaults.Locale == "" {
+		defaults.Locale = DefaultLocale
+	}
+	if defaults.ContractNumberPattern == "" {
+		defaults.ContractNumberPattern = DefaultContractNumberPattern
+	}
+	if defaults.WatermarkText == "" {
+		defaults.WatermarkText = DefaultWatermarkText
+	}
+	if defaults.RoundingMode == "" {
+		defaults.RoundingMode = DefaultRoundingMode
+	}
+	defaults.TenantID, defaults.UpdatedAt, defaults.UpdatedBy = "", nil, ""
+	return &TenantSettingsService{repo: repo, defaults: defaults, cache: make(map[string]cachedTenantSettings)}
+}
+
+// Defaults returns the server defaults
+func (s *TenantSettingsService) Defaults() models.TenantSettings {
+	return s.defaults
+}
+
+// Get returns the tenant's effective settings
+func (s *TenantSettingsService) Get(ctx context.Context, tenantID string) (*models.TenantSettings, error) {
+	overrides, err := s.overrides(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	settings := overrides.Apply(s.defaults)
+	settings.TenantID = tenantID
+	return &settings, nil
+}
+
+// Update replaces the tenant's overrides and returns the resulting settings
+func (s *TenantSettingsService) Update(ctx context.Context, tenantID string, overrides *models.TenantSettingsOverrides, updatedBy string) (*models.TenantSettings, error) {
+	err := s.repo.UpsertSettings(ctx, tenantID, overrides, updatedBy)
+	s.Invalidate(tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, tenantID)
+}
+
+// Invalidate drops the tenant's cached settings
+func (s *TenantSettingsService) Invalidate(tenantID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.cache, tenantID)
+}
+
+// RetentionDays returns the effective print retention of every tenant that
+// overrides it, along with the default for all others
+func (s *TenantSettingsService) RetentionDays(ctx context.Context) (overrides map[string]int, defaultDays int, err error) {
+	overrides, err = s.repo.ListRetentionOverrides(ctx)
+	return overrides, s.defaults.PrintRetentionDays, err
+}
+
+func (s *TenantSettingsService) overrides(ctx context.Context, tenantID string) (*models.TenantSettingsOverrides, error) {
+	now := time.Now()
+	s.mu.Lock()
+	entry, ok := s.cache[tenantID]
+	s.mu.Unlock()
+	if ok && now.Before(entry.expires) {
+		return entry.overrides, nil
+	}
+
+	overrides, err := s.repo.GetSettings(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cache[tenantID] = cachedTenantSettings{overrides: overrides, expires: now.Add(tenantSettingsCacheTTL)}
+	return overrides, nil
+}
@@ -0,0 +1,333 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zlovtnik/gprint/internal/models"
+	"github.com/zlovtnik/gprint/internal/repository"
+)
+
+const (
+	// WebhookSignatureHeader carries "sha256=" followed by the hex HMAC-SHA256 of the body
+	WebhookSignatureHeader = "X-Gprint-Signature"
+	// WebhookEventHeader carries the event type
+	WebhookEventHeader = "X-Gprint-Event"
+	// WebhookDeliveryHeader carries the delivery ID, which stays the same across retries
+	WebhookDeliveryHeader = "X-Gprint-Delivery"
+
+	// maxWebhookBackoff caps the exponential delay between delivery attempts
+	maxWebhookBackoff = time.Hour
+	// webhookBatchSize is the number of due deliveries loaded per retry pass
+	webhookBatchSize = 50
+	// webhookResponseLimit bounds how much of a failed response is kept in the delivery log
+	webhookResponseLimit = 512
+	// webhookSecretBytes is the size of generated signing secrets
+	webhookSecretBytes = 32
+)
+
+// WebhookServiceConfig holds tunables for webhook delivery
+type WebhookServiceConfig struct {
+	MaxAttempts int           // Attempts per delivery before it becomes DEAD; defaults to 5
+	Timeout     time.Duration // Per-attempt HTTP timeout; defaults to 10s
+	BaseBackoff time.Duration // Delay after the first failure, doubling per attempt; defaults to 1m
+	// Flags, when set, stops events of tenants without the webhooks feature from being delivered
+	Flags *FlagService
+}
+
+// WebhookService manages webhook subscriptions and delivers events to them
+type WebhookService struct {
+	repo        *repository.WebhookRepository
+	client      *http.Client
+	maxAttempts int
+	timeout     time.Duration
+	baseBackoff time.Duration
+	flags       *FlagService
+	logger      *slog.Logger
+}
+
+// NewWebhookService creates a new WebhookService
+func NewWebhookService(repo *repository.WebhookRepository, cfg WebhookServiceConfig, logger *slog.Logger) *WebhookService {
+	if cfg.MaxAttempts < 1 {
+		cfg.MaxAttempts = 5
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.BaseBackoff <= 0 {
+		cfg.BaseBackoff = time.Minute
+	}
+	return &WebhookService{
+		repo: repo,
+		client: &http.Client{
+			Timeout: cfg.Timeout,
+			// Receivers must answer directly; following redirects would resend the signed body elsewhere
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+		maxAttempts: cfg.MaxAttempts,
+		timeout:     cfg.Timeout,
+		baseBackoff: cfg.BaseBackoff,
+		flags:       cfg.Flags,
+		logger:      logger,
+	}
+}
+
+// Create registers a webhook. When req has no secret one is generated; the
+// returned webhook carries the secret so it can be shown to the caller once.
+func (s *WebhookService) Create(ctx context.Context, tenantID string, req *models.CreateWebhookRequest, createdBy string) (*models.Webhook, error) {
+	if req.Secret == "" {
+		secret, err := generateWebhookSecret()
+		if err != nil {
+			return nil, err
+		}
+		req.Secret = secret
+	}
+	return s.repo.Create(ctx, tenantID, req, createdBy)
+}
+
+// List retrieves a tenant's webhooks
+func (s *WebhookService) List(ctx context.Context, tenantID string) ([]models.Webhook, error) {
+	return s.repo.List(ctx, tenantID)
+}
+
+// Delete removes a webhook and its delivery log
+func (s *WebhookService) Delete(ctx context.Context, tenantID string, id int64) error {
+	err := s.repo.Delete(ctx, tenantID, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrWebhookNotFound
+	}
+	return err
+}
+
+// ListDeliveries retrieves the recent delivery log of a webhook
+func (s *WebhookService) ListDeliveries(ctx context.Context, tenantID string, webhookID int64, limit int) ([]models.WebhookDelivery, error) {
+	webhook, err := s.repo.GetByID(ctx, tenantID, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	if webhook == nil {
+		return nil, ErrWebhookNotFound
+	}
+	return s.repo.ListDeliveries(ctx, tenantID, webhookID, limit)
+}
+
+// Notify implements EventNotifier. Deliveries are recorded and attempted in
+// the background; failures are left for ProcessDueDeliveries to retry.
+func (s *WebhookService) Notify(ctx context.Context, tenantID string, event models.EventType, data any) {
+	go s.dispatch(context.WithoutCancel(ctx), tenantID, event, data)
+}
+
+// dispatch records one delivery per subscribed webhook and makes the first attempt
+func (s *WebhookService) dispatch(ctx context.Context, tenantID string, event models.EventType, data any) {
+	if s.flags != nil {
+		enabled, err := s.flags.IsEnabled(ctx, tenantID, models.FeatureWebhooks)
+		if err != nil {
+			s.logger.Error("failed to check webhooks feature for event",
+				"tenant_id", tenantID,
+				"event", event,
+				"error", err,
+			)
+			return
+		}
+		if !enabled {
+			return
+		}
+	}
+
+	webhooks, err := s.repo.FindActive(ctx, tenantID)
+	if err != nil {
+		s.logger.Error("failed to load webhooks for event",
+			"tenant_id", tenantID,
+			"event", event,
+			"error", err,
+		)
+		return
+	}
+
+	var payload []byte
+	for _, webhook := range webhooks {
+		if !webhook.Subscribes(event) {
+			continue
+		}
+		if payload == nil {
+			if payload, err = buildWebhookPayload(tenantID, event, data); err != nil {
+				s.logger.Error("failed to encode webhook payload",
+					"tenant_id", tenantID,
+					"event", event,
+					"error", err,
+				)
+				return
+			}
+		}
+
+		deliveryID, err := s.repo.CreateDelivery(ctx, tenantID, webhook.ID, event, string(payload))
+		if err != nil {
+			s.logger.Error("failed to record webhook delivery",
+				"tenant_id", tenantID,
+				"webhook_id", webhook.ID,
+				"event", event,
+				"error", err,
+			)
+			continue
+		}
+		s.attempt(ctx, &webhook, &models.WebhookDelivery{
+			ID:        deliveryID,
+			TenantID:  tenantID,
+			WebhookID: webhook.ID,
+			EventType: event,
+			Payload:   string(payload),
+		})
+	}
+}
+
+// ProcessDueDeliveries retries PENDING deliveries whose backoff has elapsed,
+// including first attempts that were interrupted before they could be made
+func (s *WebhookService) ProcessDueDeliveries(ctx context.Context) error {
+	deliveries, err := s.repo.GetDueDeliveries(ctx, webhookBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for i := range deliveries {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		delivery := &deliveries[i]
+		webhook, err := s.repo.GetByID(ctx, delivery.TenantID, delivery.WebhookID)
+		if err != nil {
+			s.logger.Error("failed to load webhook for delivery",
+				"delivery_id", delivery.ID,
+				"webhook_id", delivery.WebhookID,
+				"error", err,
+			)
+			continue
+		}
+		if webhook == nil {
+			continue // deleted since the batch was loaded; the cascade removed the delivery
+		}
+		s.attempt(ctx, webhook, delivery)
+	}
+	return nil
+}
+
+// attempt claims a delivery and POSTs it once, recording the outcome
+func (s *WebhookService) attempt(ctx context.Context, webhook *models.Webhook, delivery *models.WebhookDelivery) {
+	// Hold the claim for longer than one request can take
+	claimed, err := s.repo.ClaimDelivery(ctx, delivery.ID, 2*s.timeout)
+	if err != nil {
+		s.logger.Error("failed to claim webhook delivery", "delivery_id", delivery.ID, "error", err)
+		return
+	}
+	if !claimed {
+		return // another instance is delivering it
+	}
+
+	statusCode, sendErr := s.send(ctx, webhook, delivery)
+	if sendErr == nil {
+		if err := s.repo.MarkDelivered(ctx, delivery.ID, statusCode); err != nil {
+			s.logger.Error("failed to record webhook delivery", "delivery_id", delivery.ID, "error", err)
+		}
+		return
+	}
+
+	attempts := delivery.Attempts + 1
+	var nextAttemptAt *time.Time
+	if attempts < s.maxAttempts {
+		next := time.Now().Add(s.backoff(attempts - 1))
+		nextAttemptAt = &next
+	}
+	s.logger.Warn("webhook delivery failed",
+		"delivery_id", delivery.ID,
+		"webhook_id", webhook.ID,
+		"tenant_id", delivery.TenantID,
+		"event", delivery.EventType,
+		"attempt", attempts,
+		"final", nextAttemptAt == nil,
+		"error", sendErr,
+	)
+	if err := s.repo.MarkAttemptFailed(ctx, delivery.ID, statusCode, sendErr.Error(), nextAttemptAt); err != nil {
+		s.logger.Error("failed to record webhook delivery failure", "delivery_id", delivery.ID, "error", err)
+	}
+}
+
+// send POSTs the signed payload and returns the response status code.
+// Any non-2xx response is an error.
+func (s *WebhookService) send(ctx context.Context, webhook *models.Webhook, delivery *models.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("invalid webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "gprint-webhooks")
+	req.Header.Set(WebhookEventHeader, string(delivery.EventType))
+	req.Header.Set(WebhookDeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(webhook.Secret, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, webhookResponseLimit))
+		return resp.StatusCode, fmt.Errorf("receiver responded %d: %s", resp.StatusCode, bytes.TrimSpace(snippet))
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, webhookResponseLimit))
+	return resp.StatusCode, nil
+}
+
+// backoff returns the delay before the retry following the given failed
+// attempt (0-based), doubling from baseBackoff and capped at maxWebhookBackoff
+func (s *WebhookService) backoff(attempt int) time.Duration {
+	delay := s.baseBackoff
+	for i := 0; i < attempt && delay < maxWebhookBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, maxWebhookBackoff)
+}
+
+// SignWebhookPayload returns the X-Gprint-Signature value for body
+func SignWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// buildWebhookPayload wraps event data in the envelope sent to receivers
+func buildWebhookPayload(tenantID string, event models.EventType, data any) ([]byte, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(models.WebhookEvent{
+		ID:         uuid.NewString(),
+		Event:      event,
+		TenantID:   tenantID,
+		OccurredAt: time.Now().UTC(),
+		Data:       raw,
+	})
+}
+
+// generateWebhookSecret returns a random hex signing secret
+func generateWebhookSecret() (string, error) {
+	b := make([]byte, webhookSecretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
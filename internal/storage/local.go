@@ -0,0 +1,178 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// LocalStorage keeps objects as files below a root directory
+type LocalStorage struct {
+	root string
+}
+
+// NewLocalStorage creates a LocalStorage rooted at dir, creating it if needed
+func NewLocalStorage(dir string) (*LocalStorage, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve storage directory: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+	return &LocalStorage{root: root}, nil
+}
+
+// Name implements Storage
+func (l *LocalStorage) Name() string {
+	return BackendLocal
+}
+
+// Put implements Storage. The file is written under a temporary name and
+// renamed into place so readers never observe a partial file.
+func (l *LocalStorage) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	target, err := l.keyPath(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	if _, err := io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return fmt.Errorf("failed to set output file mode: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return fmt.Errorf("failed to move output file into place: %w", err)
+	}
+	return nil
+}
+
+// Get implements Storage
+func (l *LocalStorage) Get(ctx context.Context, key string) (*Object, error) {
+	p, err := l.resolve(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to open output file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to access output file: %w", err)
+	}
+	if !info.Mode().IsRegular() {
+		f.Close()
+		return nil, ErrNotFound
+	}
+	return &Object{Body: f, Size: info.Size()}, nil
+}
+
+// Delete implements Storage
+func (l *LocalStorage) Delete(ctx context.Context, key string) error {
+	p, err := l.resolve(key)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete output file: %w", err)
+	}
+	return nil
+}
+
+// SignedURL implements Storage; local files can only be streamed through the server
+func (l *LocalStorage) SignedURL(ctx context.Context, key string, ttl time.Duration, fileName string) (string, error) {
+	return "", ErrSignedURLUnsupported
+}
+
+// KeyFromPath converts a filesystem path below the root (as stored on print
+// jobs created before storage backends were recorded) into an object key
+func (l *LocalStorage) KeyFromPath(p string) (string, error) {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidKey, err)
+	}
+	rel, err := filepath.Rel(l.root, abs)
+	if err != nil || !isLocalRel(rel) {
+		// The root itself may have been reached through a symlink
+		resolved, evalErr := filepath.EvalSymlinks(abs)
+		if evalErr != nil {
+			return "", ErrInvalidKey
+		}
+		if rel, err = filepath.Rel(l.root, resolved); err != nil || !isLocalRel(rel) {
+			return "", ErrInvalidKey
+		}
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// keyPath maps a key to its path below the root without touching the filesystem
+func (l *LocalStorage) keyPath(key string) (string, error) {
+	if key == "" || strings.Contains(key, "\\") {
+		return "", ErrInvalidKey
+	}
+	clean := path.Clean("/" + key)[1:]
+	if clean == "" || clean != key {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(l.root, filepath.FromSlash(clean)), nil
+}
+
+// resolve maps a key to an existing file, resolving symlinks and verifying
+// the result stays inside the root
+func (l *LocalStorage) resolve(key string) (string, error) {
+	p, err := l.keyPath(key)
+	if err != nil {
+		return "", err
+	}
+	resolved, err := filepath.EvalSymlinks(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", ErrNotFound
+		}
+		return "", fmt.Errorf("failed to resolve output path: %w", err)
+	}
+	rel, err := filepath.Rel(l.root, resolved)
+	if err != nil || !isLocalRel(rel) {
+		return "", fmt.Errorf("%w: %q resolves outside the storage directory", ErrInvalidKey, key)
+	}
+	return resolved, nil
+}
+
+// isLocalRel reports whether a relative path names something strictly inside its base
+func isLocalRel(rel string) bool {
+	return rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+}
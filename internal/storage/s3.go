@@ -0,0 +1,161 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// maxPresignTTL is the longest expiry SigV4 allows on a presigned URL
+const maxPresignTTL = 7 * 24 * time.Hour
+
+// S3Config configures an S3-compatible backend (AWS S3, MinIO, R2, ...)
+type S3Config struct {
+	Endpoint        string // e.g. https://s3.us-east-1.amazonaws.com or http://minio:9000
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	// PathStyle addresses objects as endpoint/bucket/key instead of bucket.endpoint/key
+	PathStyle bool
+	Timeout   time.Duration
+}
+
+// S3Storage stores objects in an S3-compatible bucket through minio-go,
+// which signs every request with SigV4
+type S3Storage struct {
+	bucket string
+	client *minio.Client
+}
+
+// NewS3Storage validates cfg and creates an S3Storage
+func NewS3Storage(cfg S3Config) (*S3Storage, error) {
+	if cfg.Bucket == "" {
+		return nil, errors.New("s3 storage: bucket is required")
+	}
+	if cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, errors.New("s3 storage: access key and secret key are required")
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "https://s3." + cfg.Region + ".amazonaws.com"
+	}
+	endpoint, err := url.Parse(strings.TrimRight(cfg.Endpoint, "/"))
+	if err != nil || endpoint.Host == "" || endpoint.Path != "" {
+		return nil, fmt.Errorf("s3 storage: invalid endpoint %q", cfg.Endpoint)
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 60 * time.Second
+	}
+
+	secure := endpoint.Scheme == "https"
+	transport, err := minio.DefaultTransport(secure)
+	if err != nil {
+		return nil, fmt.Errorf("s3 storage: %w", err)
+	}
+	transport.ResponseHeaderTimeout = cfg.Timeout
+
+	lookup := minio.BucketLookupDNS
+	if cfg.PathStyle {
+		lookup = minio.BucketLookupPath
+	}
+	client, err := minio.New(endpoint.Host, &minio.Options{
+		Creds:        credentials.NewStaticV4(cfg.AccessKeyID, cfg.SecretAccessKey, ""),
+		Secure:       secure,
+		Transport:    transport,
+		Region:       cfg.Region,
+		BucketLookup: lookup,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("s3 storage: %w", err)
+	}
+	return &S3Storage{bucket: cfg.Bucket, client: client}, nil
+}
+
+// Name implements Storage
+func (s *S3Storage) Name() string {
+	return BackendS3
+}
+
+// Put implements Storage
+func (s *S3Storage) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	_, err := s.client.PutObject(ctx, s.bucket, key, body, size, minio.PutObjectOptions{ContentType: contentType})
+	if err != nil {
+		return fmt.Errorf("s3 storage: put %q failed: %w", key, err)
+	}
+	return nil
+}
+
+// Get implements Storage
+func (s *S3Storage) Get(ctx context.Context, key string) (*Object, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+	obj, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("s3 storage: get %q failed: %w", key, err)
+	}
+	// GetObject is lazy; Stat issues the request and reports a missing key
+	info, err := obj.Stat()
+	if err != nil {
+		obj.Close()
+		if minio.ToErrorResponse(err).Code == minio.NoSuchKey {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("s3 storage: get %q failed: %w", key, err)
+	}
+	return &Object{Body: obj, Size: info.Size}, nil
+}
+
+// Delete implements Storage
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	err := s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+	if err != nil && minio.ToErrorResponse(err).Code != minio.NoSuchKey {
+		return fmt.Errorf("s3 storage: delete %q failed: %w", key, err)
+	}
+	return nil
+}
+
+// SignedURL implements Storage using a SigV4 presigned GET
+func (s *S3Storage) SignedURL(ctx context.Context, key string, ttl time.Duration, fileName string) (string, error) {
+	if err := checkKey(key); err != nil {
+		return "", err
+	}
+	if ttl < time.Second || ttl > maxPresignTTL {
+		return "", fmt.Errorf("s3 storage: signed URL ttl must be between 1s and %s", maxPresignTTL)
+	}
+
+	params := url.Values{}
+	if fileName != "" {
+		params.Set("response-content-disposition", mime.FormatMediaType("attachment", map[string]string{"filename": fileName}))
+	}
+	u, err := s.client.PresignedGetObject(ctx, s.bucket, key, ttl, params)
+	if err != nil {
+		return "", fmt.Errorf("s3 storage: presign %q failed: %w", key, err)
+	}
+	return u.String(), nil
+}
+
+// checkKey rejects keys that are empty or absolute
+func checkKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") {
+		return ErrInvalidKey
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeS3 is a path-style S3 endpoint keeping objects in memory
+type fakeS3 struct {
+	t       *testing.T
+	mu      sync.Mutex
+	objects map[string]string
+	types   map[string]string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/") || !strings.Contains(auth, "/eu-west-1/s3/aws4_request") {
+		f.t.Errorf("%s %s: unexpected Authorization %q", r.Method, r.URL.Path, auth)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := r.URL.Path
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		if strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+			body = decodeAWSChunked(f.t, body)
+		}
+		f.objects[key] = string(body)
+		f.types[key] = r.Header.Get("Content-Type")
+		w.Header().Set("ETag", `"etag"`)
+	case http.MethodGet, http.MethodHead:
+		body, ok := f.objects[key]
+		if !ok {
+			w.Header().Set("Content-Type", "application/xml")
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `<?xml version="1.0" encoding="UTF-8"?><Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message></Error>`)
+			return
+		}
+		w.Header().Set("ETag", `"etag"`)
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		if r.Method == http.MethodGet {
+			io.WriteString(w, body)
+		}
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// decodeAWSChunked strips the chunk headers of a streaming SigV4 upload,
+// which the SDK sends over plain HTTP
+func decodeAWSChunked(t *testing.T, body []byte) []byte {
+	var out []byte
+	for len(body) > 0 {
+		header, rest, ok := bytes.Cut(body, []byte("\r\n"))
+		if !ok {
+			t.Fatalf("malformed aws-chunked body %q", body)
+		}
+		size, _, _ := bytes.Cut(header, []byte(";"))
+		n, err := strconv.ParseInt(string(size), 16, 64)
+		if err != nil || int64(len(rest)) < n+2 {
+			t.Fatalf("malformed chunk header %q", header)
+		}
+		out = append(out, rest[:n]...)
+		body = rest[n+2:]
+	}
+	return out
+}
+
+func newTestS3(t *testing.T) (*S3Storage, *fakeS3) {
+	t.Helper()
+	fake := &fakeS3{t: t, objects: make(map[string]string), types: make(map[string]string)}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	s, err := NewS3Storage(S3Config{
+		Endpoint:        srv.URL,
+		Region:          "eu-west-1",
+		Bucket:          "prints",
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "secret",
+		PathStyle:       true,
+	})
+	if err != nil {
+		t.Fatalf("NewS3Storage: %v", err)
+	}
+	return s, fake
+}
+
+func TestS3PutGetDelete(t *testing.T) {
+	s, fake := newTestS3(t)
+	ctx := context.Background()
+	const key = "tenant-1/contract 42.html"
+	const body = "<html>contract</html>"
+
+	if err := s.Put(ctx, key, strings.NewReader(body), int64(len(body)), "text/html"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if got := fake.types["/prints/"+key]; got != "text/html" {
+		t.Errorf("stored content type %q, want text/html", got)
+	}
+
+	obj, err := s.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	data, err := io.ReadAll(obj.Body)
+	obj.Body.Close()
+	if err != nil {
+		t.Fatalf("reading object: %v", err)
+	}
+	if string(data) != body || obj.Size != int64(len(body)) {
+		t.Errorf("Get = %q (size %d), want %q (size %d)", data, obj.Size, body, len(body))
+	}
+
+	if err := s.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := s.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete = %v, want ErrNotFound", err)
+	}
+	if err := s.Delete(ctx, key); err != nil {
+		t.Errorf("Delete of a missing object = %v, want nil", err)
+	}
+}
+
+func TestS3InvalidKey(t *testing.T) {
+	s, _ := newTestS3(t)
+	ctx := context.Background()
+	for _, key := range []string{"", "/abs"} {
+		if err := s.Put(ctx, key, strings.NewReader("x"), 1, ""); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Put(%q) = %v, want ErrInvalidKey", key, err)
+		}
+		if _, err := s.Get(ctx, key); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Get(%q) = %v, want ErrInvalidKey", key, err)
+		}
+		if _, err := s.SignedURL(ctx, key, time.Minute, ""); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("SignedURL(%q) = %v, want ErrInvalidKey", key, err)
+		}
+	}
+}
+
+func TestS3SignedURL(t *testing.T) {
+	s, _ := newTestS3(t)
+	raw, err := s.SignedURL(context.Background(), "tenant-1/out.pdf", 15*time.Minute, "Contract 42.pdf")
+	if err != nil {
+		t.Fatalf("SignedURL: %v", err)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatalf("parsing %q: %v", raw, err)
+	}
+	if u.Path != "/prints/tenant-1/out.pdf" {
+		t.Errorf("path = %q, want /prints/tenant-1/out.pdf", u.Path)
+	}
+	q := u.Query()
+	checks := map[string]string{
+		"X-Amz-Algorithm":              "AWS4-HMAC-SHA256",
+		"X-Amz-Expires":                "900",
+		"X-Amz-SignedHeaders":          "host",
+		"response-content-disposition": `attachment; filename="Contract 42.pdf"`,
+	}
+	for name, want := range checks {
+		if got := q.Get(name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+	if cred := q.Get("X-Amz-Credential"); !strings.HasPrefix(cred, "AKID/") || !strings.HasSuffix(cred, "/eu-west-1/s3/aws4_request") {
+		t.Errorf("X-Amz-Credential = %q", cred)
+	}
+	if len(q.Get("X-Amz-Signature")) != 64 {
+		t.Errorf("X-Amz-Signature = %q, want a hex SHA-256", q.Get("X-Amz-Signature"))
+	}
+
+	for _, ttl := range []time.Duration{0, 8 * 24 * time.Hour} {
+		if _, err := s.SignedURL(context.Background(), "k", ttl, ""); err == nil {
+			t.Errorf("SignedURL with ttl %s: expected an error", ttl)
+		}
+	}
+}
+
+func TestNewS3StorageValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  S3Config
+	}{
+		{"missing bucket", S3Config{AccessKeyID: "a", SecretAccessKey: "b"}},
+		{"missing credentials", S3Config{Bucket: "b"}},
+		{"endpoint with path", S3Config{Bucket: "b", AccessKeyID: "a", SecretAccessKey: "b", Endpoint: "http://minio:9000/prefix"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewS3Storage(tt.cfg); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+// Package storage abstracts where generated print output is kept so that
+// replicas without a shared disk can still serve each other's files.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// Backend names recorded on print jobs
+const (
+	BackendLocal = "local"
+	BackendS3    = "s3"
+)
+
+var (
+	// ErrNotFound is returned when the requested object does not exist
+	ErrNotFound = errors.New("storage: object not found")
+	// ErrSignedURLUnsupported is returned by backends that cannot issue signed URLs
+	ErrSignedURLUnsupported = errors.New("storage: signed URLs not supported by this backend")
+	// ErrInvalidKey is returned for keys that are empty or escape the storage root
+	ErrInvalidKey = errors.New("storage: invalid object key")
+)
+
+// Object is an open stored object; the caller must close Body
+type Object struct {
+	Body io.ReadCloser
+	Size int64
+}
+
+// Storage persists print output under slash-separated keys such as "tenant/file.html"
+type Storage interface {
+	// Name identifies the backend (BackendLocal, BackendS3)
+	Name() string
+	// Put stores size bytes read from body under key, replacing any existing object
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	// Get opens the object stored under key
+	Get(ctx context.Context, key string) (*Object, error)
+	// Delete removes the object; deleting a missing object is not an error
+	Delete(ctx context.Context, key string) error
+	// SignedURL returns a time-limited URL that downloads the object as fileName
+	SignedURL(ctx context.Context, key string, ttl time.Duration, fileName string) (string, error)
+}
@@ -0,0 +1,23 @@
+// Package version exposes build information injected at link time, e.g.
+//
+//	go build -ldflags "-X github.com/zlovtnik/gprint/internal/version.Version=v1.2.3"
+package version
+
+// Build information; the defaults identify an untagged development build
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildTime = "unknown"
+)
+
+// Info is the build information reported by the /version endpoint
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+}
+
+// Get returns the build information of the running binary
+func Get() Info {
+	return Info{Version: Version, Commit: Commit, BuildTime: BuildTime}
+}
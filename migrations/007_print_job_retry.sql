@@ -0,0 +1,39 @@
+-- Print Job Retry Policy
+-- Migration: 007_print_job_retry.sql
+--
+-- Adds the DEAD terminal status and the next_retry_at schedule column used by
+-- the print service to retry failed jobs with exponential backoff.
+
+-- ==============================================================================
+-- STATUS CONSTRAINT
+-- ==============================================================================
+-- The original CHECK constraint was declared inline and has a system-generated
+-- name, so look it up from the data dictionary before replacing it.
+DECLARE
+    v_condition VARCHAR2(4000);
+BEGIN
+    FOR c IN (
+        SELECT constraint_name, search_condition
+        FROM user_constraints
+        WHERE table_name = 'CONTRACT_PRINT_JOBS'
+          AND constraint_type = 'C'
+    ) LOOP
+        v_condition := c.search_condition;
+        IF v_condition LIKE '%status%' AND v_condition LIKE '%QUEUED%' THEN
+            EXECUTE IMMEDIATE 'ALTER TABLE contract_print_jobs DROP CONSTRAINT ' || c.constraint_name;
+        END IF;
+    END LOOP;
+END;
+/
+
+ALTER TABLE contract_print_jobs ADD CONSTRAINT chk_print_jobs_status
+    CHECK (status IN ('QUEUED', 'PROCESSING', 'COMPLETED', 'FAILED', 'DEAD'));
+
+-- ==============================================================================
+-- RETRY SCHEDULING
+-- ==============================================================================
+ALTER TABLE contract_print_jobs ADD (
+    next_retry_at   TIMESTAMP
+);
+
+CREATE INDEX idx_print_jobs_retry ON contract_print_jobs(status, next_retry_at);
@@ -0,0 +1,10 @@
+-- Print Job Cancellation
+-- Migration: 008_print_job_cancel.sql
+--
+-- Allows print jobs to be cancelled by users while queued, processing, or
+-- waiting for an automatic retry.
+
+ALTER TABLE contract_print_jobs DROP CONSTRAINT chk_print_jobs_status;
+
+ALTER TABLE contract_print_jobs ADD CONSTRAINT chk_print_jobs_status
+    CHECK (status IN ('QUEUED', 'PROCESSING', 'COMPLETED', 'FAILED', 'DEAD', 'CANCELLED'));
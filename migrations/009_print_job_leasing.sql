@@ -0,0 +1,13 @@
+-- Print Job Leasing
+-- Migration: 009_print_job_leasing.sql
+--
+-- Lets several gprint instances share the print queue. A worker claims a job
+-- by writing its instance ID and a lease expiry; jobs whose lease expired
+-- (e.g. the owning instance crashed) become claimable again.
+
+ALTER TABLE contract_print_jobs ADD (
+    locked_by       VARCHAR2(200),
+    locked_until    TIMESTAMP
+);
+
+CREATE INDEX idx_print_jobs_lease ON contract_print_jobs(status, locked_until);
@@ -0,0 +1,11 @@
+-- Print Job Priority
+-- Migration: 010_print_job_priority.sql
+--
+-- Adds a priority to print jobs so urgent contracts can jump the FIFO queue.
+
+ALTER TABLE contract_print_jobs ADD (
+    priority        VARCHAR2(10) DEFAULT 'NORMAL' NOT NULL
+        CONSTRAINT chk_print_jobs_priority CHECK (priority IN ('LOW', 'NORMAL', 'HIGH'))
+);
+
+CREATE INDEX idx_print_jobs_priority_queue ON contract_print_jobs(status, priority, queued_at);
@@ -0,0 +1,12 @@
+-- Print Output Retention
+-- Migration: 011_print_output_retention.sql
+--
+-- Adds the PURGED status for completed jobs whose output file was removed by
+-- the retention cleanup task.
+
+ALTER TABLE contract_print_jobs DROP CONSTRAINT chk_print_jobs_status;
+
+ALTER TABLE contract_print_jobs ADD CONSTRAINT chk_print_jobs_status
+    CHECK (status IN ('QUEUED', 'PROCESSING', 'COMPLETED', 'FAILED', 'DEAD', 'CANCELLED', 'PURGED'));
+
+CREATE INDEX idx_print_jobs_retention ON contract_print_jobs(status, completed_at);
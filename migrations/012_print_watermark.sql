@@ -0,0 +1,19 @@
+-- Print Watermarks
+-- Migration: 012_print_watermark.sql
+--
+-- Records whether a print job is stamped with the draft watermark and lets
+-- each tenant customise the watermark text.
+
+ALTER TABLE contract_print_jobs ADD (
+    watermark       NUMBER(1) DEFAULT 1 NOT NULL
+);
+
+-- ==============================================================================
+-- TENANT PRINT SETTINGS
+-- ==============================================================================
+CREATE TABLE print_settings (
+    tenant_id       VARCHAR2(100) PRIMARY KEY,
+    watermark_text  VARCHAR2(200),
+    updated_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_by      VARCHAR2(100)
+);
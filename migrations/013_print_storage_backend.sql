@@ -0,0 +1,10 @@
+-- Print Output Storage Backend
+-- Migration: 013_print_storage_backend.sql
+--
+-- Records which storage backend holds each job's output. output_path now holds
+-- the object key within that backend. Rows completed before this migration keep
+-- a NULL backend and a local filesystem path; they are not migrated.
+
+ALTER TABLE contract_print_jobs ADD (
+    storage_backend VARCHAR2(20)
+);
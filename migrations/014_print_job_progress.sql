@@ -0,0 +1,10 @@
+-- Print Job Progress
+-- Migration: 014_print_job_progress.sql
+--
+-- Tracks rendering progress (0-100) so clients can show more than PROCESSING
+-- for long-running jobs.
+
+ALTER TABLE contract_print_jobs ADD (
+    progress_pct    NUMBER(3) DEFAULT 0 NOT NULL
+        CONSTRAINT chk_print_jobs_progress CHECK (progress_pct BETWEEN 0 AND 100)
+);
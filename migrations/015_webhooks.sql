@@ -0,0 +1,47 @@
+-- Webhooks
+-- Migration: 015_webhooks.sql
+--
+-- Tenant webhook subscriptions and the delivery log. Each event produces one
+-- delivery row per subscribed webhook; failed deliveries stay PENDING with a
+-- next_attempt_at in the future until they succeed or run out of attempts.
+
+-- ==============================================================================
+-- WEBHOOKS
+-- ==============================================================================
+CREATE TABLE webhooks (
+    id              NUMBER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    tenant_id       VARCHAR2(100) NOT NULL,
+    url             VARCHAR2(2000) NOT NULL,
+    secret          VARCHAR2(200) NOT NULL,
+    event_types     VARCHAR2(1000) NOT NULL,  -- comma-separated, e.g. print_job.completed,contract.signed
+    active          NUMBER(1) DEFAULT 1 NOT NULL,
+    created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    created_by      VARCHAR2(100)
+);
+
+CREATE INDEX idx_webhooks_tenant ON webhooks(tenant_id, active);
+
+-- ==============================================================================
+-- WEBHOOK DELIVERIES
+-- ==============================================================================
+CREATE TABLE webhook_deliveries (
+    id                NUMBER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    tenant_id         VARCHAR2(100) NOT NULL,
+    webhook_id        NUMBER NOT NULL,
+    event_type        VARCHAR2(100) NOT NULL,
+    payload           CLOB NOT NULL,
+    status            VARCHAR2(20) DEFAULT 'PENDING' NOT NULL,
+    attempts          NUMBER(5) DEFAULT 0 NOT NULL,
+    next_attempt_at   TIMESTAMP,
+    last_status_code  NUMBER(3),
+    last_error        VARCHAR2(1000),
+    created_at        TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    delivered_at      TIMESTAMP,
+    CONSTRAINT fk_webhook_deliveries_webhook FOREIGN KEY (webhook_id)
+        REFERENCES webhooks(id) ON DELETE CASCADE,
+    CONSTRAINT chk_webhook_deliveries_status
+        CHECK (status IN ('PENDING', 'DELIVERED', 'DEAD'))
+);
+
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries(status, next_attempt_at);
+CREATE INDEX idx_webhook_deliveries_webhook ON webhook_deliveries(webhook_id, created_at);
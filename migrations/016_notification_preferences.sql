@@ -0,0 +1,15 @@
+-- Notification Preferences
+-- Migration: 016_notification_preferences.sql
+--
+-- Per-tenant, per-user opt-in for print job notification emails. Users without
+-- a row receive no email.
+
+CREATE TABLE notification_preferences (
+    tenant_id             VARCHAR2(100) NOT NULL,
+    user_id               VARCHAR2(100) NOT NULL,
+    email                 VARCHAR2(320) NOT NULL,
+    print_job_completed   NUMBER(1) DEFAULT 1 NOT NULL,
+    print_job_failed      NUMBER(1) DEFAULT 1 NOT NULL,
+    updated_at            TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    CONSTRAINT pk_notification_preferences PRIMARY KEY (tenant_id, user_id)
+);
@@ -0,0 +1,16 @@
+-- API Clients
+-- Migration: 017_api_clients.sql
+--
+-- Machine integrations that authenticate with Keycloak's client_credentials
+-- grant. Each client acts for one tenant; its scopes are the roles its
+-- requests are granted. Requests are recorded as user "svc:<client_id>".
+
+CREATE TABLE api_clients (
+    client_id       VARCHAR2(95) NOT NULL,    -- Keycloak client ID; "svc:" prefix must fit created_by
+    tenant_id       VARCHAR2(100) NOT NULL,
+    scopes          VARCHAR2(1000),            -- comma-separated, e.g. contracts:write,print:manage
+    active          NUMBER(1) DEFAULT 1 NOT NULL,
+    description     VARCHAR2(500),
+    created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    CONSTRAINT pk_api_clients PRIMARY KEY (client_id)
+);
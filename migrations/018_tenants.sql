@@ -0,0 +1,29 @@
+-- Tenants
+-- Migration: 018_tenants.sql
+--
+-- Tenants created through POST /api/v1/admin/tenants and their settings.
+-- Provisioning only inserts missing rows, so it can be rerun safely.
+
+-- ==============================================================================
+-- TENANTS
+-- ==============================================================================
+CREATE TABLE tenants (
+    tenant_id       VARCHAR2(100) NOT NULL,
+    display_name    VARCHAR2(200) NOT NULL,
+    created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    created_by      VARCHAR2(100),
+    CONSTRAINT pk_tenants PRIMARY KEY (tenant_id)
+);
+
+-- ==============================================================================
+-- TENANT SETTINGS
+-- ==============================================================================
+CREATE TABLE tenant_settings (
+    tenant_id                VARCHAR2(100) NOT NULL,
+    currency                 VARCHAR2(3) DEFAULT 'BRL' NOT NULL,
+    contract_number_pattern  VARCHAR2(100) NOT NULL,  -- e.g. CT-{YYYY}-{SEQ}
+    print_retention_days     NUMBER(5) DEFAULT 0 NOT NULL,  -- 0 keeps outputs forever
+    updated_at               TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_by               VARCHAR2(100),
+    CONSTRAINT pk_tenant_settings PRIMARY KEY (tenant_id)
+);
@@ -0,0 +1,24 @@
+-- Tenant Settings
+-- Migration: 019_tenant_settings.sql
+--
+-- Adds locale and watermark text to tenant_settings and makes every setting
+-- optional: NULL means the server default. Custom watermark texts move here
+-- from print_settings, which is no longer read.
+
+ALTER TABLE tenant_settings ADD (
+    locale          VARCHAR2(10),   -- e.g. pt-BR; selects the generation template language
+    watermark_text  VARCHAR2(200)   -- draft watermark on unsigned contracts
+);
+
+ALTER TABLE tenant_settings MODIFY (
+    currency                 DEFAULT NULL NULL,
+    contract_number_pattern  NULL,
+    print_retention_days     DEFAULT NULL NULL
+);
+
+MERGE INTO tenant_settings t
+USING (SELECT tenant_id, watermark_text, updated_by FROM print_settings WHERE watermark_text IS NOT NULL) p
+ON (t.tenant_id = p.tenant_id)
+WHEN MATCHED THEN UPDATE SET t.watermark_text = p.watermark_text
+WHEN NOT MATCHED THEN INSERT (tenant_id, watermark_text, updated_by)
+    VALUES (p.tenant_id, p.watermark_text, p.updated_by);
@@ -0,0 +1,14 @@
+-- Feature Flags
+-- Migration: 020_feature_flags.sql
+--
+-- Per-tenant switches for modules rolled out tenant by tenant. A tenant
+-- without a row for a flag gets the server default (FEATURES_ENABLED).
+
+CREATE TABLE feature_flags (
+    tenant_id       VARCHAR2(100) NOT NULL,
+    flag_name       VARCHAR2(50) NOT NULL,
+    enabled         NUMBER(1) NOT NULL CHECK (enabled IN (0,1)),
+    updated_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_by      VARCHAR2(100),
+    CONSTRAINT pk_feature_flags PRIMARY KEY (tenant_id, flag_name)
+);
@@ -0,0 +1,119 @@
+-- Generic Upsert
+-- Migration: 021_generic_upsert.sql
+--
+-- Insert-or-update by natural key for GenericRepository.Upsert. The row
+-- matching the key columns is locked and updated through pkg_crud.do_update,
+-- or inserted through pkg_crud.do_insert when there is none. If a concurrent
+-- insert of the same key wins, ours fails on the table's unique key and the
+-- winning row is updated instead.
+
+CREATE OR REPLACE PROCEDURE sp_generic_upsert(
+    p_table_name   IN  VARCHAR2,
+    p_tenant_id    IN  VARCHAR2,
+    p_keys         IN  t_column_values,
+    p_columns      IN  t_column_values,
+    p_actor        IN  VARCHAR2 DEFAULT NULL,
+    p_id           OUT NUMBER,
+    p_inserted     OUT NUMBER,
+    p_success      OUT NUMBER,
+    p_error_msg    OUT VARCHAR2
+) AS
+    v_config crud_allowed_tables%ROWTYPE;
+    v_result t_crud_result;
+
+    -- Returns the ID of the row matching p_keys, locking it, or NULL
+    FUNCTION find_id RETURN NUMBER IS
+        v_sql    VARCHAR2(32767);
+        v_meta   t_column_meta;
+        v_cursor NUMBER := NULL;
+        v_id     NUMBER;
+    BEGIN
+        v_sql := 'SELECT ' || DBMS_ASSERT.SIMPLE_SQL_NAME(v_config.id_column) ||
+                 ' FROM ' || DBMS_ASSERT.SIMPLE_SQL_NAME(UPPER(p_table_name)) ||
+                 ' WHERE 1 = 1';
+        IF v_config.require_tenant = 1 THEN
+            v_sql := v_sql || ' AND ' || DBMS_ASSERT.SIMPLE_SQL_NAME(v_config.tenant_column) || ' = :tenant';
+        END IF;
+        FOR i IN 1..p_keys.COUNT LOOP
+            v_meta := pkg_crud.get_column_meta(p_table_name, p_keys(i).column_name);
+            IF v_meta IS NULL THEN
+                RAISE_APPLICATION_ERROR(-20002, 'Column not found in table: ' || p_keys(i).column_name);
+            END IF;
+            v_sql := v_sql || ' AND ' || DBMS_ASSERT.SIMPLE_SQL_NAME(UPPER(p_keys(i).column_name)) ||
+                     ' = ' || v_meta.get_bind_expression(i);
+        END LOOP;
+        v_sql := v_sql || ' FOR UPDATE';
+
+        v_cursor := DBMS_SQL.OPEN_CURSOR;
+        DBMS_SQL.PARSE(v_cursor, v_sql, DBMS_SQL.NATIVE);
+        IF v_config.require_tenant = 1 THEN
+            DBMS_SQL.BIND_VARIABLE(v_cursor, ':tenant', p_tenant_id);
+        END IF;
+        FOR i IN 1..p_keys.COUNT LOOP
+            DBMS_SQL.BIND_VARIABLE(v_cursor, ':' || i, p_keys(i).col_value);
+        END LOOP;
+        DBMS_SQL.DEFINE_COLUMN(v_cursor, 1, v_id);
+
+        IF DBMS_SQL.EXECUTE_AND_FETCH(v_cursor) > 0 THEN
+            DBMS_SQL.COLUMN_VALUE(v_cursor, 1, v_id);
+            IF DBMS_SQL.FETCH_ROWS(v_cursor) > 0 THEN
+                RAISE_APPLICATION_ERROR(-20003, 'Key columns match more than one row');
+            END IF;
+        END IF;
+        DBMS_SQL.CLOSE_CURSOR(v_cursor);
+        RETURN v_id;
+    EXCEPTION
+        WHEN OTHERS THEN
+            IF v_cursor IS NOT NULL AND DBMS_SQL.IS_OPEN(v_cursor) THEN
+                DBMS_SQL.CLOSE_CURSOR(v_cursor);
+            END IF;
+            RAISE;
+    END find_id;
+BEGIN
+    p_inserted := 0;
+
+    v_config := pkg_crud.get_table_config(p_table_name);
+    IF v_config.id IS NULL OR v_config.allow_insert != 1 OR v_config.allow_update != 1 THEN
+        p_success := 0;
+        p_error_msg := 'Table not allowed for UPSERT: ' || p_table_name;
+        RETURN;
+    END IF;
+    IF v_config.require_tenant = 1 AND p_tenant_id IS NULL THEN
+        p_success := 0;
+        p_error_msg := 'tenant_id is required';
+        RETURN;
+    END IF;
+    IF p_keys IS NULL OR p_keys.COUNT = 0 THEN
+        p_success := 0;
+        p_error_msg := 'No key columns';
+        RETURN;
+    END IF;
+
+    p_id := find_id;
+    IF p_id IS NULL THEN
+        v_result := pkg_crud.do_insert(p_table_name, p_tenant_id, p_columns, p_actor);
+        IF v_result.success = 1 THEN
+            p_id := v_result.generated_id;
+            p_inserted := 1;
+            p_success := 1;
+            RETURN;
+        END IF;
+
+        -- Lost the race to a concurrent insert of the same key: update its row
+        p_id := find_id;
+        IF p_id IS NULL THEN
+            p_success := v_result.success;
+            p_error_msg := v_result.error_message;
+            RETURN;
+        END IF;
+    END IF;
+
+    v_result := pkg_crud.do_update(p_table_name, p_tenant_id, p_id, p_columns, p_actor);
+    p_success := v_result.success;
+    p_error_msg := v_result.error_message;
+EXCEPTION
+    WHEN OTHERS THEN
+        p_success := 0;
+        p_error_msg := 'Upsert failed: ' || SQLERRM;
+END sp_generic_upsert;
+/
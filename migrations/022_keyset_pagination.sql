@@ -0,0 +1,8 @@
+-- Keyset Pagination
+-- Migration: 022_keyset_pagination.sql
+--
+-- Lets cursor-paginated contract and print job lists walk the newest-first
+-- index instead of sorting every row of the tenant.
+
+CREATE INDEX idx_contracts_keyset ON contracts(tenant_id, created_at, id);
+CREATE INDEX idx_print_jobs_keyset ON contract_print_jobs(tenant_id, queued_at, id);
@@ -0,0 +1,37 @@
+-- Contract Discount and Tax
+-- Migration: 023_contract_discount_tax.sql
+--
+-- Adds a contract-level discount and tax on top of the item line totals.
+-- The server computes and stores every amount when items or percentages
+-- change:
+--   subtotal        = sum of the item line totals
+--   discount_amount = subtotal * discount_pct / 100
+--   tax_amount      = (subtotal - discount_amount) * tax_pct / 100
+--   total_value     = subtotal - discount_amount + tax_amount
+-- each rounded to cents with the tenant's rounding_mode, banker's rounding
+-- (HALF_EVEN) unless set to HALF_UP.
+
+ALTER TABLE contracts ADD (
+    subtotal         NUMBER(15,2),
+    discount_pct     NUMBER(5,2) DEFAULT 0 NOT NULL CHECK (discount_pct >= 0 AND discount_pct <= 100),
+    discount_amount  NUMBER(15,2),
+    tax_pct          NUMBER(5,2) DEFAULT 0 NOT NULL CHECK (tax_pct >= 0 AND tax_pct <= 100),
+    tax_amount       NUMBER(15,2)
+);
+
+-- Existing contracts have neither, so their total is their subtotal
+UPDATE contracts SET subtotal = total_value, discount_amount = 0, tax_amount = 0;
+
+ALTER TABLE tenant_settings ADD (
+    rounding_mode   VARCHAR2(10) CHECK (rounding_mode IN ('HALF_EVEN', 'HALF_UP'))
+);
+
+-- The contract total is no longer the plain sum of the items, so the
+-- pkg_crud aggregate must not overwrite it
+DELETE FROM crud_allowed_aggregates
+WHERE parent_table = 'CONTRACTS' AND child_table = 'CONTRACT_ITEMS';
+
+COMMIT;
+
+-- load_contract reads the new columns
+ALTER PACKAGE pkg_data_api COMPILE BODY;
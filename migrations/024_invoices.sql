@@ -0,0 +1,36 @@
+-- Invoices
+-- Migration: 024_invoices.sql
+--
+-- Draft invoices created from contract billing schedules. The invoice job
+-- inserts one row per contract billing period when the period starts; the
+-- unique key on (tenant_id, contract_id, period_start) makes a rerun of the
+-- job, or two instances running it at once, create each invoice only once.
+
+CREATE TABLE invoices (
+    id              NUMBER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    tenant_id       VARCHAR2(100) NOT NULL,
+    invoice_number  VARCHAR2(60) NOT NULL,   -- contract number and period, e.g. CT-2026-00042-003
+    contract_id     NUMBER NOT NULL,
+    period_number   NUMBER(5) NOT NULL,
+    period_start    DATE NOT NULL,
+    period_end      DATE NOT NULL,
+    due_date        DATE NOT NULL,
+    amount          NUMBER(15,2) NOT NULL,
+    status          VARCHAR2(10) DEFAULT 'DRAFT' NOT NULL,
+    issued_at       TIMESTAMP,
+    issued_by       VARCHAR2(100),
+    paid_at         TIMESTAMP,
+    paid_by         VARCHAR2(100),
+    created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    CONSTRAINT uk_invoices_period UNIQUE (tenant_id, contract_id, period_start),
+    CONSTRAINT uk_invoices_number UNIQUE (tenant_id, invoice_number),
+    CONSTRAINT fk_invoices_contract FOREIGN KEY (tenant_id, contract_id)
+        REFERENCES contracts(tenant_id, id) ON DELETE CASCADE,
+    CONSTRAINT chk_invoices_status CHECK (status IN ('DRAFT', 'ISSUED', 'PAID'))
+);
+
+CREATE INDEX idx_invoices_status ON invoices(tenant_id, status, due_date);
+
+-- The invoice job walks active contracts across tenants by id
+CREATE INDEX idx_contracts_active ON contracts(status, id);
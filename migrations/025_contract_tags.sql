@@ -0,0 +1,20 @@
+-- Contract Tags
+-- Migration: 025_contract_tags.sql
+--
+-- Free-form labels on contracts ("priority", "legal-review") that contract
+-- lists can be filtered by. Tags are stored lowercase; the server allows at
+-- most 20 per contract and 40 characters each.
+
+CREATE TABLE contract_tags (
+    tenant_id    VARCHAR2(100) NOT NULL,
+    contract_id  NUMBER NOT NULL,
+    tag          VARCHAR2(40) NOT NULL,
+    created_at   TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    CONSTRAINT pk_contract_tags PRIMARY KEY (tenant_id, contract_id, tag),
+    CONSTRAINT fk_contract_tags_contract FOREIGN KEY (tenant_id, contract_id)
+        REFERENCES contracts(tenant_id, id) ON DELETE CASCADE,
+    CONSTRAINT chk_contract_tags_lower CHECK (tag = LOWER(tag))
+);
+
+-- ?tag= filters and the tag list look contracts up by tag
+CREATE INDEX idx_contract_tags_tag ON contract_tags(tenant_id, tag, contract_id);
@@ -0,0 +1,25 @@
+-- Contract Attachments
+-- Migration: 026_contract_attachments.sql
+--
+-- Files uploaded against a contract (signed scans, correspondence). The file
+-- itself lives in the configured storage backend under storage_key; this
+-- table holds its metadata and the SHA-256 checksum verified on download.
+
+CREATE TABLE contract_attachments (
+    id               NUMBER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    tenant_id        VARCHAR2(100) NOT NULL,
+    contract_id      NUMBER NOT NULL,
+    file_name        VARCHAR2(255) NOT NULL,
+    content_type     VARCHAR2(100) NOT NULL,
+    size_bytes       NUMBER(12) NOT NULL,
+    checksum_sha256  VARCHAR2(64) NOT NULL,    -- lowercase hex
+    storage_backend  VARCHAR2(10) NOT NULL,    -- local or s3
+    storage_key      VARCHAR2(500) NOT NULL,
+    uploaded_by      VARCHAR2(100) NOT NULL,
+    created_at       TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    CONSTRAINT uk_contract_attachments_key UNIQUE (storage_backend, storage_key),
+    CONSTRAINT fk_contract_attachments_contract FOREIGN KEY (tenant_id, contract_id)
+        REFERENCES contracts(tenant_id, id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_contract_attachments_contract ON contract_attachments(tenant_id, contract_id, created_at);
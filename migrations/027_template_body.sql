@@ -0,0 +1,12 @@
+-- Contract Template Bodies
+-- Migration: 027_template_body.sql
+--
+-- Templates gain an HTML body managed through /api/v1/templates. The body is
+-- Go template syntax limited to the documented merge fields; the server
+-- checks it on every save and bumps version when it changes. Templates
+-- referenced by generated contracts are retired with active = 0 rather than
+-- deleted, so the generation history keeps pointing at them.
+
+ALTER TABLE contract_templates ADD (
+    body_html   CLOB
+);
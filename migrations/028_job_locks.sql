@@ -0,0 +1,21 @@
+-- Scheduled Job Locks
+-- Migration: 028_job_locks.sql
+--
+-- Lets several gprint instances share scheduled jobs. Each instance wakes up
+-- when a run is due; the first to claim it records the due time and a lease,
+-- and the others skip that run. An instance that crashes mid-run keeps the
+-- lease until it expires, so the next run is not blocked for good.
+
+CREATE TABLE job_locks (
+    job_name        VARCHAR2(100) PRIMARY KEY,
+    locked_by       VARCHAR2(200),
+    locked_until    TIMESTAMP,
+    last_due_at     TIMESTAMP,
+    last_run_at     TIMESTAMP
+);
+
+INSERT INTO job_locks (job_name) VALUES ('generation_cleanup');
+
+CREATE INDEX idx_generated_expires ON generated_contracts(tenant_id, expires_at);
+
+COMMIT;
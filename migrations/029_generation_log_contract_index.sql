@@ -0,0 +1,7 @@
+-- Contract Action Log Index
+-- Migration: 029_generation_log_contract_index.sql
+--
+-- Per-contract generation statistics and the contract action listing read
+-- contract_generation_log by contract, newest first.
+
+CREATE INDEX idx_gen_log_contract ON contract_generation_log(tenant_id, contract_id, performed_at);
@@ -0,0 +1,22 @@
+-- In-App Notifications
+-- Migration: 030_notifications.sql
+--
+-- Notifications shown to a user inside the app, alongside the opt-in emails
+-- of notification_preferences. entity_type and entity_id name what the
+-- notification links to (a print job, a contract); read_at stays NULL until
+-- the user opens it.
+
+CREATE TABLE notifications (
+    id               NUMBER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    tenant_id        VARCHAR2(100) NOT NULL,
+    user_id          VARCHAR2(100) NOT NULL,
+    type             VARCHAR2(50) NOT NULL,     -- event type, e.g. print_job.completed
+    title            VARCHAR2(200) NOT NULL,
+    body             VARCHAR2(2000),
+    entity_type      VARCHAR2(50),
+    entity_id        NUMBER,
+    created_at       TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    read_at          TIMESTAMP
+);
+
+CREATE INDEX idx_notifications_user ON notifications(tenant_id, user_id, read_at, created_at);
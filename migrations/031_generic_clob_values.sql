@@ -0,0 +1,1284 @@
+-- Generic CLOB Column Values
+-- Migration: 031_generic_clob_values.sql
+--
+-- GenericRepository binds every column value instead of inlining it as a
+-- literal. Values longer than the 4000-byte col_value attribute travel in a
+-- new t_column_value.col_clob attribute, which pkg_crud binds in place of
+-- col_value when it is set. The package body is recreated with the
+-- bind_column_value helper; its specification is unchanged.
+
+ALTER TYPE t_column_value ADD ATTRIBUTE (
+    col_clob CLOB  -- Set instead of col_value for values over 4000 bytes
+) CASCADE;
+
+CREATE OR REPLACE PACKAGE BODY pkg_crud AS
+
+    -- Session-scoped caches with no TTL - changes to crud_allowed_tables made outside
+    -- the register_table flow (e.g., direct SQL*Plus edits) will leave other sessions
+    -- with stale entries until they call clear_cache or reconnect. For performance,
+    -- maintain only a few active sessions or use register_table for changes.
+    -- Reference: t_meta_cache, g_meta_cache, t_config_cache, g_config_cache,
+    -- crud_allowed_tables, register_table, clear_cache
+    
+    -- Column metadata cache
+    TYPE t_meta_cache IS TABLE OF t_column_meta INDEX BY VARCHAR2(260);
+    g_meta_cache t_meta_cache;
+    
+    -- Table config cache
+    TYPE t_config_cache IS TABLE OF crud_allowed_tables%ROWTYPE INDEX BY VARCHAR2(128);
+    g_config_cache t_config_cache;
+    
+    -- ========================================================================
+    -- INTERNAL HELPER FUNCTIONS
+    -- ========================================================================
+    
+    -- Log error to persistent table for diagnostics
+    PROCEDURE log_error(p_context VARCHAR2, p_error VARCHAR2) IS
+        PRAGMA AUTONOMOUS_TRANSACTION;
+    BEGIN
+        INSERT INTO error_log (context, message, severity)
+        VALUES (p_context, p_error, 'ERROR');
+        COMMIT;
+    EXCEPTION
+        WHEN OTHERS THEN
+            -- Fallback: try to log the logging failure itself
+            BEGIN
+                INSERT INTO error_log (context, message, severity)
+                VALUES ('LOG_ERROR_FAILURE', 'Failed to log error: ' || SQLERRM || ' (original: ' || p_context || ': ' || p_error || ')', 'CRITICAL');
+                COMMIT;
+            EXCEPTION
+                WHEN OTHERS THEN NULL; -- Silent failure if even fallback fails
+            END;
+    END log_error;
+    
+    -- Safely quote identifier
+    FUNCTION safe_identifier(p_name VARCHAR2) RETURN VARCHAR2 IS
+    BEGIN
+        RETURN DBMS_ASSERT.SIMPLE_SQL_NAME(UPPER(p_name));
+    EXCEPTION
+        WHEN OTHERS THEN
+            RAISE_APPLICATION_ERROR(-20001, 'Invalid identifier: ' || p_name);
+    END safe_identifier;
+    
+    -- Bind a column value, taking long text from col_clob
+    PROCEDURE bind_column_value(p_cursor NUMBER, p_bind_idx NUMBER, p_col t_column_value) IS
+    BEGIN
+        IF p_col.col_clob IS NOT NULL THEN
+            DBMS_SQL.BIND_VARIABLE(p_cursor, ':' || p_bind_idx, p_col.col_clob);
+        ELSE
+            DBMS_SQL.BIND_VARIABLE(p_cursor, ':' || p_bind_idx, p_col.col_value);
+        END IF;
+    END bind_column_value;
+    
+    -- ========================================================================
+    -- TABLE METADATA FUNCTIONS
+    -- ========================================================================
+    
+    FUNCTION table_exists(p_table_name VARCHAR2, p_schema VARCHAR2 DEFAULT USER) RETURN BOOLEAN IS
+        v_count NUMBER;
+    BEGIN
+        SELECT COUNT(*) INTO v_count
+        FROM all_tables
+        WHERE table_name = UPPER(p_table_name)
+          AND owner = UPPER(NVL(p_schema, USER));
+        RETURN v_count > 0;
+    END table_exists;
+    
+    FUNCTION is_allowed(p_table_name VARCHAR2, p_operation VARCHAR2 DEFAULT 'SELECT') RETURN BOOLEAN IS
+        v_config crud_allowed_tables%ROWTYPE;
+    BEGIN
+        v_config := get_table_config(p_table_name);
+        IF v_config.id IS NULL THEN
+            RETURN FALSE;
+        END IF;
+        
+        CASE UPPER(p_operation)
+            WHEN 'INSERT' THEN RETURN v_config.allow_insert = 1;
+            WHEN 'UPDATE' THEN RETURN v_config.allow_update = 1;
+            WHEN 'DELETE' THEN RETURN v_config.allow_delete = 1;
+            WHEN 'SELECT' THEN RETURN v_config.allow_select = 1;
+            ELSE RETURN FALSE;
+        END CASE;
+    END is_allowed;
+    
+    FUNCTION get_table_config(p_table_name VARCHAR2) RETURN crud_allowed_tables%ROWTYPE IS
+        v_config crud_allowed_tables%ROWTYPE;
+        v_key VARCHAR2(128) := UPPER(p_table_name);
+    BEGIN
+        -- Check cache
+        IF g_config_cache.EXISTS(v_key) THEN
+            RETURN g_config_cache(v_key);
+        END IF;
+        
+        -- Query config table
+        BEGIN
+            SELECT * INTO v_config
+            FROM crud_allowed_tables
+            WHERE table_name = UPPER(p_table_name)
+              AND schema_name = USER;
+        EXCEPTION
+            WHEN NO_DATA_FOUND THEN
+                v_config.id := NULL;
+                RETURN v_config;
+        END;
+        
+        -- Cache and return
+        g_config_cache(v_key) := v_config;
+        RETURN v_config;
+    END get_table_config;
+    
+    FUNCTION get_column_meta(p_table_name VARCHAR2, p_column_name VARCHAR2) RETURN t_column_meta IS
+        v_meta t_column_meta;
+        v_key VARCHAR2(260) := UPPER(p_table_name) || '.' || UPPER(p_column_name);
+    BEGIN
+        -- Check cache
+        IF g_meta_cache.EXISTS(v_key) THEN
+            RETURN g_meta_cache(v_key);
+        END IF;
+        
+        -- Query data dictionary
+        SELECT t_column_meta(
+            column_name,
+            data_type,
+            data_length,
+            data_precision,
+            data_scale,
+            nullable
+        ) INTO v_meta
+        FROM user_tab_columns
+        WHERE table_name = UPPER(p_table_name)
+          AND column_name = UPPER(p_column_name);
+        
+        -- Cache and return
+        g_meta_cache(v_key) := v_meta;
+        RETURN v_meta;
+    EXCEPTION
+        WHEN NO_DATA_FOUND THEN
+            RETURN NULL;
+    END get_column_meta;
+    
+    FUNCTION get_table_columns(p_table_name VARCHAR2) RETURN t_column_metas IS
+        v_cols t_column_metas := t_column_metas();
+    BEGIN
+        FOR rec IN (
+            SELECT column_name, data_type, data_length, data_precision, data_scale, nullable
+            FROM user_tab_columns
+            WHERE table_name = UPPER(p_table_name)
+            ORDER BY column_id
+        ) LOOP
+            v_cols.EXTEND;
+            v_cols(v_cols.COUNT) := t_column_meta(
+                rec.column_name,
+                rec.data_type,
+                rec.data_length,
+                rec.data_precision,
+                rec.data_scale,
+                rec.nullable
+            );
+        END LOOP;
+        RETURN v_cols;
+    END get_table_columns;
+    
+    FUNCTION column_exists(p_table_name VARCHAR2, p_column_name VARCHAR2) RETURN BOOLEAN IS
+        v_meta t_column_meta;
+    BEGIN
+        v_meta := get_column_meta(p_table_name, p_column_name);
+        RETURN v_meta IS NOT NULL;
+    END column_exists;
+    
+    FUNCTION build_column_list(p_table_name VARCHAR2, p_exclude_cols VARCHAR2 DEFAULT NULL) RETURN VARCHAR2 IS
+        v_list VARCHAR2(4000) := '';
+        v_exclude VARCHAR2(500) := ',' || UPPER(NVL(p_exclude_cols, '')) || ',';
+    BEGIN
+        FOR rec IN (
+            SELECT column_name
+            FROM user_tab_columns
+            WHERE table_name = UPPER(p_table_name)
+            ORDER BY column_id
+        ) LOOP
+            IF INSTR(v_exclude, ',' || rec.column_name || ',') = 0 THEN
+                IF v_list IS NOT NULL THEN
+                    v_list := v_list || ', ';
+                END IF;
+                v_list := v_list || rec.column_name;
+            END IF;
+        END LOOP;
+        RETURN v_list;
+    END build_column_list;
+    
+    -- ========================================================================
+    -- DYNAMIC CRUD OPERATIONS
+    -- ========================================================================
+    
+    FUNCTION do_insert(
+        p_table_name   VARCHAR2,
+        p_tenant_id    VARCHAR2,
+        p_columns      t_column_values,
+        p_created_by   VARCHAR2 DEFAULT NULL
+    ) RETURN t_crud_result IS
+        v_config       crud_allowed_tables%ROWTYPE;
+        v_sql          CLOB;
+        v_cols         CLOB;
+        v_vals         CLOB;
+        v_bind_idx     NUMBER := 1;
+        v_id           NUMBER;
+        v_cursor       NUMBER := NULL; -- Initialize to NULL for safe cleanup
+        v_rows         NUMBER;
+        v_meta         t_column_meta;
+        v_safe_table   VARCHAR2(128);
+    BEGIN
+        -- Get table config
+        v_config := get_table_config(p_table_name);
+        IF v_config.id IS NULL OR v_config.allow_insert != 1 THEN
+            RETURN t_crud_result.err(c_err_table_not_allowed, 'Table not allowed for INSERT: ' || p_table_name);
+        END IF;
+        
+        v_safe_table := safe_identifier(p_table_name);
+        
+        -- Check tenant requirement
+        IF v_config.require_tenant = 1 THEN
+            IF p_tenant_id IS NULL THEN
+                RETURN t_crud_result.err(c_err_tenant_required, 'tenant_id is required');
+            END IF;
+            v_cols := v_config.tenant_column;
+            v_vals := ':' || v_bind_idx;
+            v_bind_idx := v_bind_idx + 1;
+        END IF;
+        
+        -- Build column list from provided values
+        IF p_columns IS NOT NULL THEN
+            FOR i IN 1..p_columns.COUNT LOOP
+                -- Get column metadata from data dictionary
+                v_meta := get_column_meta(p_table_name, p_columns(i).column_name);
+                IF v_meta IS NULL THEN
+                    RETURN t_crud_result.err(c_err_column_not_found, 
+                        'Column not found in table: ' || p_columns(i).column_name);
+                END IF;
+                
+                IF v_cols IS NOT NULL THEN
+                    v_cols := v_cols || ', ';
+                    v_vals := v_vals || ', ';
+                END IF;
+                
+                v_cols := v_cols || safe_identifier(p_columns(i).column_name);
+                v_vals := v_vals || v_meta.get_bind_expression(v_bind_idx);
+                v_bind_idx := v_bind_idx + 1;
+            END LOOP;
+        END IF;
+        
+        -- Add audit columns if they exist
+        IF p_created_by IS NOT NULL THEN
+            IF column_exists(p_table_name, 'CREATED_BY') THEN
+                v_cols := v_cols || ', CREATED_BY';
+                v_vals := v_vals || ', :' || v_bind_idx;
+                v_bind_idx := v_bind_idx + 1;
+            END IF;
+            IF column_exists(p_table_name, 'UPDATED_BY') THEN
+                v_cols := v_cols || ', UPDATED_BY';
+                v_vals := v_vals || ', :' || v_bind_idx;
+                v_bind_idx := v_bind_idx + 1;
+            END IF;
+        END IF;
+        
+        -- Build and execute SQL
+        v_sql := 'INSERT INTO ' || v_safe_table || 
+                 ' (' || v_cols || ') VALUES (' || v_vals || ')' ||
+                 ' RETURNING ' || NVL(v_config.id_column, 'ID') || ' INTO :out_id';
+        
+        v_cursor := DBMS_SQL.OPEN_CURSOR;
+        DBMS_SQL.PARSE(v_cursor, v_sql, DBMS_SQL.NATIVE);
+        
+        -- Bind values
+        v_bind_idx := 1;
+        
+        -- Bind tenant_id if required
+        IF v_config.require_tenant = 1 THEN
+            DBMS_SQL.BIND_VARIABLE(v_cursor, ':' || v_bind_idx, p_tenant_id);
+            v_bind_idx := v_bind_idx + 1;
+        END IF;
+        
+        -- Bind column values
+        IF p_columns IS NOT NULL THEN
+            FOR i IN 1..p_columns.COUNT LOOP
+                bind_column_value(v_cursor, v_bind_idx, p_columns(i));
+                v_bind_idx := v_bind_idx + 1;
+            END LOOP;
+        END IF;
+        
+        -- Bind audit values
+        IF p_created_by IS NOT NULL THEN
+            IF column_exists(p_table_name, 'CREATED_BY') THEN
+                DBMS_SQL.BIND_VARIABLE(v_cursor, ':' || v_bind_idx, p_created_by);
+                v_bind_idx := v_bind_idx + 1;
+            END IF;
+            IF column_exists(p_table_name, 'UPDATED_BY') THEN
+                DBMS_SQL.BIND_VARIABLE(v_cursor, ':' || v_bind_idx, p_created_by);
+                v_bind_idx := v_bind_idx + 1;
+            END IF;
+        END IF;
+        
+        -- Bind output
+        DBMS_SQL.BIND_VARIABLE(v_cursor, ':out_id', v_id);
+        
+        -- Execute
+        v_rows := DBMS_SQL.EXECUTE(v_cursor);
+        DBMS_SQL.VARIABLE_VALUE(v_cursor, ':out_id', v_id);
+        DBMS_SQL.CLOSE_CURSOR(v_cursor);
+        
+        RETURN t_crud_result.ok(v_id, v_rows);
+        
+    EXCEPTION
+        WHEN OTHERS THEN
+            -- Only close cursor if it was opened (v_cursor is not null and is a valid cursor)
+            IF v_cursor IS NOT NULL THEN
+                BEGIN
+                    IF DBMS_SQL.IS_OPEN(v_cursor) THEN
+                        DBMS_SQL.CLOSE_CURSOR(v_cursor);
+                    END IF;
+                EXCEPTION
+                    WHEN OTHERS THEN NULL; -- Ignore cursor cleanup errors
+                END;
+            END IF;
+            log_error('do_insert:' || p_table_name, SQLERRM);
+            RETURN t_crud_result.err(c_err_execution_failed, 'Insert failed. See server logs.');
+    END do_insert;
+    
+    FUNCTION do_select(
+        p_table_name   VARCHAR2,
+        p_tenant_id    VARCHAR2,
+        p_id           NUMBER,
+        p_columns      VARCHAR2 DEFAULT NULL
+    ) RETURN SYS_REFCURSOR IS
+        v_config     crud_allowed_tables%ROWTYPE;
+        v_cursor     SYS_REFCURSOR;
+        v_sql        VARCHAR2(4000);
+        v_col_list   VARCHAR2(4000);
+        v_safe_table VARCHAR2(128);
+    BEGIN
+        v_config := get_table_config(p_table_name);
+        IF v_config.id IS NULL OR v_config.allow_select != 1 THEN
+            RETURN NULL;
+        END IF;
+        
+        v_safe_table := safe_identifier(p_table_name);
+        
+        -- Build column list from data dictionary if not specified
+        IF p_columns IS NULL THEN
+            v_col_list := build_column_list(p_table_name);
+        ELSE
+            -- Validate each column
+            DECLARE
+                v_cols VARCHAR2(4000) := REPLACE(p_columns, ' ', '');
+                v_start NUMBER := 1;
+                v_pos NUMBER;
+                v_col VARCHAR2(128);
+            BEGIN
+                v_col_list := '';
+                LOOP
+                    v_pos := INSTR(v_cols, ',', v_start);
+                    IF v_pos = 0 THEN
+                        v_col := SUBSTR(v_cols, v_start);
+                    ELSE
+                        v_col := SUBSTR(v_cols, v_start, v_pos - v_start);
+                    END IF;
+                    
+                    IF v_col IS NOT NULL AND LENGTH(TRIM(v_col)) > 0 THEN
+                        IF NOT column_exists(p_table_name, v_col) THEN
+                            RETURN NULL;
+                        END IF;
+                        IF v_col_list IS NOT NULL THEN
+                            v_col_list := v_col_list || ', ';
+                        END IF;
+                        v_col_list := v_col_list || safe_identifier(v_col);
+                    END IF;
+                    
+                    EXIT WHEN v_pos = 0;
+                    v_start := v_pos + 1;
+                END LOOP;
+            END;
+        END IF;
+        
+        -- Build SQL with tenant isolation
+        IF v_config.require_tenant = 1 THEN
+            v_sql := 'SELECT ' || v_col_list || 
+                     ' FROM ' || v_safe_table ||
+                     ' WHERE ' || safe_identifier(v_config.tenant_column) || ' = :1' ||
+                     ' AND ' || safe_identifier(v_config.id_column) || ' = :2';
+            OPEN v_cursor FOR v_sql USING p_tenant_id, p_id;
+        ELSE
+            v_sql := 'SELECT ' || v_col_list || 
+                     ' FROM ' || v_safe_table ||
+                     ' WHERE ' || safe_identifier(v_config.id_column) || ' = :1';
+            OPEN v_cursor FOR v_sql USING p_id;
+        END IF;
+        
+        RETURN v_cursor;
+    EXCEPTION
+        WHEN OTHERS THEN
+            log_error('do_select:' || p_table_name, SQLERRM);
+            RETURN NULL;
+    END do_select;
+    
+    FUNCTION do_query(
+        p_table_name   VARCHAR2,
+        p_tenant_id    VARCHAR2,
+        p_columns      VARCHAR2 DEFAULT NULL,
+        p_filters      t_filter_conditions DEFAULT NULL,
+        p_sort         t_sort_specs DEFAULT NULL,
+        p_offset       NUMBER DEFAULT 0,
+        p_limit        NUMBER DEFAULT 50
+    ) RETURN SYS_REFCURSOR IS
+        v_config     crud_allowed_tables%ROWTYPE;
+        v_cursor     SYS_REFCURSOR;
+        v_sql        CLOB;
+        v_col_list   VARCHAR2(4000);
+        v_where      CLOB := '';
+        v_order      VARCHAR2(1000) := '';
+        v_bind_idx   NUMBER := 1;
+        v_safe_table VARCHAR2(128);
+        v_dbms_cur   NUMBER := NULL;
+        v_dummy      NUMBER;
+    BEGIN
+        v_config := get_table_config(p_table_name);
+        IF v_config.id IS NULL OR v_config.allow_select != 1 THEN
+            RETURN NULL;
+        END IF;
+        
+        v_safe_table := safe_identifier(p_table_name);
+        
+        -- Build column list (validate if provided externally)
+        IF p_columns IS NULL THEN
+            v_col_list := build_column_list(p_table_name);
+        ELSE
+            -- SECURITY: Validate p_columns to prevent SQL injection
+            -- Parse and validate each column identifier
+            DECLARE
+                v_cols VARCHAR2(4000) := REPLACE(p_columns, ' ', '');
+                v_start NUMBER := 1;
+                v_pos NUMBER;
+                v_col VARCHAR2(128);
+                v_validated_list VARCHAR2(4000) := '';
+            BEGIN
+                LOOP
+                    v_pos := INSTR(v_cols, ',', v_start);
+                    IF v_pos = 0 THEN
+                        v_col := SUBSTR(v_cols, v_start);
+                    ELSE
+                        v_col := SUBSTR(v_cols, v_start, v_pos - v_start);
+                    END IF;
+                    
+                    IF v_col IS NOT NULL AND LENGTH(TRIM(v_col)) > 0 THEN
+                        -- Validate column exists in table
+                        IF NOT column_exists(p_table_name, v_col) THEN
+                            log_error('do_query:' || p_table_name, 'Invalid column: ' || v_col);
+                            RETURN NULL;
+                        END IF;
+                        IF v_validated_list IS NOT NULL AND LENGTH(v_validated_list) > 0 THEN
+                            v_validated_list := v_validated_list || ', ';
+                        END IF;
+                        v_validated_list := v_validated_list || safe_identifier(v_col);
+                    END IF;
+                    
+                    EXIT WHEN v_pos = 0;
+                    v_start := v_pos + 1;
+                END LOOP;
+                v_col_list := v_validated_list;
+            END;
+        END IF;
+        
+        -- Build WHERE clause
+        IF v_config.require_tenant = 1 THEN
+            v_where := ' WHERE ' || safe_identifier(v_config.tenant_column) || ' = :' || v_bind_idx;
+            v_bind_idx := v_bind_idx + 1;
+        END IF;
+        
+        -- Add filters
+        IF p_filters IS NOT NULL AND p_filters.COUNT > 0 THEN
+            FOR i IN 1..p_filters.COUNT LOOP
+                IF column_exists(p_table_name, p_filters(i).column_name) THEN
+                    IF v_where IS NULL THEN
+                        v_where := ' WHERE ';
+                    ELSE
+                        v_where := v_where || ' AND ';
+                    END IF;
+                    v_where := v_where || p_filters(i).to_sql(v_bind_idx);
+                    -- Increment bind index if operator uses a bind variable
+                    IF UPPER(p_filters(i).operator) NOT IN ('IS NULL', 'IS NOT NULL') THEN
+                        v_bind_idx := v_bind_idx + 1;
+                    END IF;
+                END IF;
+            END LOOP;
+        END IF;
+        
+        -- Build ORDER BY
+        IF p_sort IS NOT NULL AND p_sort.COUNT > 0 THEN
+            FOR i IN 1..p_sort.COUNT LOOP
+                IF column_exists(p_table_name, p_sort(i).column_name) THEN
+                    -- Validate direction to prevent SQL injection
+                    DECLARE
+                        v_direction VARCHAR2(4);
+                    BEGIN
+                        v_direction := UPPER(TRIM(p_sort(i).direction));
+                        IF v_direction NOT IN ('ASC', 'DESC') THEN
+                            v_direction := 'ASC'; -- Default to ASC for invalid values
+                        END IF;
+                        
+                        IF v_order IS NULL OR LENGTH(v_order) = 0 THEN
+                            v_order := ' ORDER BY ';
+                        ELSE
+                            v_order := v_order || ', ';
+                        END IF;
+                        v_order := v_order || safe_identifier(p_sort(i).column_name) || ' ' || v_direction;
+                    END;
+                END IF;
+            END LOOP;
+        END IF;
+        
+        IF v_order IS NULL OR LENGTH(v_order) = 0 THEN
+            v_order := ' ORDER BY ' || safe_identifier(v_config.id_column) || ' DESC';
+        END IF;
+        
+        -- Build full SQL
+        v_sql := 'SELECT ' || v_col_list ||
+                 ' FROM ' || v_safe_table ||
+                 v_where || v_order ||
+                 ' OFFSET :off ROWS FETCH NEXT :lim ROWS ONLY';
+        
+        -- Execute with DBMS_SQL for dynamic binding
+        v_dbms_cur := DBMS_SQL.OPEN_CURSOR;
+        DBMS_SQL.PARSE(v_dbms_cur, v_sql, DBMS_SQL.NATIVE);
+        
+        v_bind_idx := 1;
+        IF v_config.require_tenant = 1 THEN
+            DBMS_SQL.BIND_VARIABLE(v_dbms_cur, ':' || v_bind_idx, p_tenant_id);
+            v_bind_idx := v_bind_idx + 1;
+        END IF;
+        
+        IF p_filters IS NOT NULL THEN
+            FOR i IN 1..p_filters.COUNT LOOP
+                IF column_exists(p_table_name, p_filters(i).column_name) THEN
+                    IF UPPER(p_filters(i).operator) NOT IN ('IS NULL', 'IS NOT NULL') THEN
+                        DBMS_SQL.BIND_VARIABLE(v_dbms_cur, ':' || v_bind_idx, p_filters(i).filter_val);
+                        v_bind_idx := v_bind_idx + 1;
+                    END IF;
+                END IF;
+            END LOOP;
+        END IF;
+        
+        DBMS_SQL.BIND_VARIABLE(v_dbms_cur, ':off', p_offset);
+        DBMS_SQL.BIND_VARIABLE(v_dbms_cur, ':lim', p_limit);
+        
+        v_dummy := DBMS_SQL.EXECUTE(v_dbms_cur);
+        v_cursor := DBMS_SQL.TO_REFCURSOR(v_dbms_cur);
+        
+        RETURN v_cursor;
+        
+    EXCEPTION
+        WHEN OTHERS THEN
+            IF DBMS_SQL.IS_OPEN(v_dbms_cur) THEN
+                DBMS_SQL.CLOSE_CURSOR(v_dbms_cur);
+            END IF;
+            log_error('do_query:' || p_table_name, SQLERRM);
+            RETURN NULL;
+    END do_query;
+    
+    -- Query with total count in single call (for list operations)
+    FUNCTION do_query_with_total(
+        p_table_name   VARCHAR2,
+        p_tenant_id    VARCHAR2,
+        p_columns      VARCHAR2 DEFAULT NULL,
+        p_filters      t_filter_conditions DEFAULT NULL,
+        p_sort         t_sort_specs DEFAULT NULL,
+        p_offset       NUMBER DEFAULT 0,
+        p_limit        NUMBER DEFAULT 50
+    ) RETURN SYS_REFCURSOR IS
+        v_config     crud_allowed_tables%ROWTYPE;
+        v_cursor     SYS_REFCURSOR;
+        v_sql        CLOB;
+        v_col_list   VARCHAR2(4000);
+        v_where      CLOB := '';
+        v_order      VARCHAR2(1000) := '';
+        v_bind_idx   NUMBER := 1;
+        v_safe_table VARCHAR2(128);
+        v_dbms_cur   NUMBER := NULL;
+        v_dummy      NUMBER;
+    BEGIN
+        v_config := get_table_config(p_table_name);
+        IF v_config.id IS NULL OR v_config.allow_select != 1 THEN
+            RETURN NULL;
+        END IF;
+        
+        v_safe_table := safe_identifier(p_table_name);
+        
+        -- Build column list (validate if provided externally)
+        IF p_columns IS NULL THEN
+            v_col_list := build_column_list(p_table_name);
+        ELSE
+            -- SECURITY: Validate p_columns to prevent SQL injection
+            -- Parse and validate each column identifier
+            DECLARE
+                v_cols VARCHAR2(4000) := REPLACE(p_columns, ' ', '');
+                v_start NUMBER := 1;
+                v_pos NUMBER;
+                v_col VARCHAR2(128);
+                v_validated_list VARCHAR2(4000) := '';
+            BEGIN
+                LOOP
+                    v_pos := INSTR(v_cols, ',', v_start);
+                    IF v_pos = 0 THEN
+                        v_col := SUBSTR(v_cols, v_start);
+                    ELSE
+                        v_col := SUBSTR(v_cols, v_start, v_pos - v_start);
+                    END IF;
+                    
+                    IF v_col IS NOT NULL AND LENGTH(TRIM(v_col)) > 0 THEN
+                        -- Validate column exists in table
+                        IF NOT column_exists(p_table_name, v_col) THEN
+                            log_error('do_query_with_total:' || p_table_name, 'Invalid column: ' || v_col);
+                            RETURN NULL;
+                        END IF;
+                        IF v_validated_list IS NOT NULL AND LENGTH(v_validated_list) > 0 THEN
+                            v_validated_list := v_validated_list || ', ';
+                        END IF;
+                        v_validated_list := v_validated_list || safe_identifier(v_col);
+                    END IF;
+                    
+                    EXIT WHEN v_pos = 0;
+                    v_start := v_bind_idx + 1;
+                END LOOP;
+                v_col_list := v_validated_list;
+            END;
+        END IF;
+        
+        -- Build WHERE clause
+        IF v_config.require_tenant = 1 THEN
+            v_where := ' WHERE ' || safe_identifier(v_config.tenant_column) || ' = :' || v_bind_idx;
+            v_bind_idx := v_bind_idx + 1;
+        END IF;
+        
+        -- Add filters
+        IF p_filters IS NOT NULL AND p_filters.COUNT > 0 THEN
+            FOR i IN 1..p_filters.COUNT LOOP
+                IF column_exists(p_table_name, p_filters(i).column_name) THEN
+                    IF v_where IS NULL THEN
+                        v_where := ' WHERE ';
+                    ELSE
+                        v_where := v_where || ' AND ';
+                    END IF;
+                    v_where := v_where || p_filters(i).to_sql(v_bind_idx);
+                    -- Increment bind index if operator uses a bind variable
+                    IF UPPER(p_filters(i).operator) NOT IN ('IS NULL', 'IS NOT NULL') THEN
+                        v_bind_idx := v_bind_idx + 1;
+                    END IF;
+                END IF;
+            END LOOP;
+        END IF;
+        
+        -- Build ORDER BY
+        IF p_sort IS NOT NULL AND p_sort.COUNT > 0 THEN
+            FOR i IN 1..p_sort.COUNT LOOP
+                IF column_exists(p_table_name, p_sort(i).column_name) THEN
+                    -- Validate direction to prevent SQL injection
+                    DECLARE
+                        v_direction VARCHAR2(4);
+                    BEGIN
+                        v_direction := UPPER(TRIM(p_sort(i).direction));
+                        IF v_direction NOT IN ('ASC', 'DESC') THEN
+                            v_direction := 'ASC'; -- Default to ASC for invalid values
+                        END IF;
+                        
+                        IF v_order IS NULL OR LENGTH(v_order) = 0 THEN
+                            v_order := ' ORDER BY ';
+                        ELSE
+                            v_order := v_order || ', ';
+                        END IF;
+                        v_order := v_order || safe_identifier(p_sort(i).column_name) || ' ' || v_direction;
+                    END;
+                END IF;
+            END LOOP;
+        END IF;
+        
+        IF v_order IS NULL OR LENGTH(v_order) = 0 THEN
+            v_order := ' ORDER BY ' || safe_identifier(v_config.id_column) || ' DESC';
+        END IF;
+        
+        -- Build full SQL with COUNT(*) OVER() as first column for total
+        v_sql := 'SELECT COUNT(*) OVER() AS total_count, ' || v_col_list ||
+                 ' FROM ' || v_safe_table ||
+                 v_where || v_order ||
+                 ' OFFSET :off ROWS FETCH NEXT :lim ROWS ONLY';
+        
+        -- Execute with DBMS_SQL for dynamic binding
+        v_dbms_cur := DBMS_SQL.OPEN_CURSOR;
+        DBMS_SQL.PARSE(v_dbms_cur, v_sql, DBMS_SQL.NATIVE);
+        
+        v_bind_idx := 1;
+        -- Bind tenant filter
+        IF v_config.require_tenant = 1 THEN
+            DBMS_SQL.BIND_VARIABLE(v_dbms_cur, ':' || v_bind_idx, p_tenant_id);
+            v_bind_idx := v_bind_idx + 1;
+        END IF;
+        
+        -- Bind filter values
+        IF p_filters IS NOT NULL AND p_filters.COUNT > 0 THEN
+            FOR i IN 1..p_filters.COUNT LOOP
+                IF column_exists(p_table_name, p_filters(i).column_name) THEN
+                    IF UPPER(p_filters(i).operator) NOT IN ('IS NULL', 'IS NOT NULL') THEN
+                        DBMS_SQL.BIND_VARIABLE(v_dbms_cur, ':' || v_bind_idx, p_filters(i).filter_val);
+                        v_bind_idx := v_bind_idx + 1;
+                    END IF;
+                END IF;
+            END LOOP;
+        END IF;
+        
+        DBMS_SQL.BIND_VARIABLE(v_dbms_cur, ':off', p_offset);
+        DBMS_SQL.BIND_VARIABLE(v_dbms_cur, ':lim', p_limit);
+        
+        v_dummy := DBMS_SQL.EXECUTE(v_dbms_cur);
+        v_cursor := DBMS_SQL.TO_REFCURSOR(v_dbms_cur);
+        
+        RETURN v_cursor;
+        
+    EXCEPTION
+        WHEN OTHERS THEN
+            IF DBMS_SQL.IS_OPEN(v_dbms_cur) THEN
+                DBMS_SQL.CLOSE_CURSOR(v_dbms_cur);
+            END IF;
+            log_error('do_query_with_total:' || p_table_name, SQLERRM);
+            RETURN NULL;
+    END do_query_with_total;
+    
+    FUNCTION do_count(
+        p_table_name   VARCHAR2,
+        p_tenant_id    VARCHAR2,
+        p_filters      t_filter_conditions DEFAULT NULL
+    ) RETURN NUMBER IS
+        v_config     crud_allowed_tables%ROWTYPE;
+        v_sql        CLOB;
+        v_where      CLOB := '';
+        v_bind_idx   NUMBER := 1;
+        v_count      NUMBER;
+        v_cursor     NUMBER;
+        v_dummy      NUMBER;
+        v_safe_table VARCHAR2(128);
+    BEGIN
+        v_config := get_table_config(p_table_name);
+        IF v_config.id IS NULL OR v_config.allow_select != 1 THEN
+            RETURN -1;
+        END IF;
+        
+        v_safe_table := safe_identifier(p_table_name);
+        
+        -- Build WHERE
+        IF v_config.require_tenant = 1 THEN
+            v_where := ' WHERE ' || safe_identifier(v_config.tenant_column) || ' = :' || v_bind_idx;
+            v_bind_idx := v_bind_idx + 1;
+        END IF;
+        
+        IF p_filters IS NOT NULL AND p_filters.COUNT > 0 THEN
+            FOR i IN 1..p_filters.COUNT LOOP
+                IF column_exists(p_table_name, p_filters(i).column_name) THEN
+                    IF v_where IS NULL THEN
+                        v_where := ' WHERE ';
+                    ELSE
+                        v_where := v_where || ' AND ';
+                    END IF;
+                    v_where := v_where || p_filters(i).to_sql(v_bind_idx);
+                    -- Increment bind index if operator uses a bind variable
+                    IF UPPER(p_filters(i).operator) NOT IN ('IS NULL', 'IS NOT NULL') THEN
+                        v_bind_idx := v_bind_idx + 1;
+                    END IF;
+                END IF;
+            END LOOP;
+        END IF;
+        
+        v_sql := 'SELECT COUNT(*) FROM ' || v_safe_table || v_where;
+        
+        v_cursor := DBMS_SQL.OPEN_CURSOR;
+        DBMS_SQL.PARSE(v_cursor, v_sql, DBMS_SQL.NATIVE);
+        
+        v_bind_idx := 1;
+        IF v_config.require_tenant = 1 THEN
+            DBMS_SQL.BIND_VARIABLE(v_cursor, ':' || v_bind_idx, p_tenant_id);
+            v_bind_idx := v_bind_idx + 1;
+        END IF;
+        
+        IF p_filters IS NOT NULL THEN
+            FOR i IN 1..p_filters.COUNT LOOP
+                IF column_exists(p_table_name, p_filters(i).column_name) THEN
+                    IF UPPER(p_filters(i).operator) NOT IN ('IS NULL', 'IS NOT NULL') THEN
+                        DBMS_SQL.BIND_VARIABLE(v_cursor, ':' || v_bind_idx, p_filters(i).filter_val);
+                        v_bind_idx := v_bind_idx + 1;
+                    END IF;
+                END IF;
+            END LOOP;
+        END IF;
+        
+        DBMS_SQL.DEFINE_COLUMN(v_cursor, 1, v_count);
+        v_dummy := DBMS_SQL.EXECUTE(v_cursor);
+        
+        IF DBMS_SQL.FETCH_ROWS(v_cursor) > 0 THEN
+            DBMS_SQL.COLUMN_VALUE(v_cursor, 1, v_count);
+        ELSE
+            v_count := 0;
+        END IF;
+        
+        DBMS_SQL.CLOSE_CURSOR(v_cursor);
+        RETURN v_count;
+        
+    EXCEPTION
+        WHEN OTHERS THEN
+            IF DBMS_SQL.IS_OPEN(v_cursor) THEN
+                DBMS_SQL.CLOSE_CURSOR(v_cursor);
+            END IF;
+            log_error('do_count:' || p_table_name, SQLERRM);
+            RETURN -1;
+    END do_count;
+    
+    FUNCTION do_update(
+        p_table_name   VARCHAR2,
+        p_tenant_id    VARCHAR2,
+        p_id           NUMBER,
+        p_columns      t_column_values,
+        p_updated_by   VARCHAR2 DEFAULT NULL
+    ) RETURN t_crud_result IS
+        v_config     crud_allowed_tables%ROWTYPE;
+        v_sql        CLOB;
+        v_set        CLOB := '';
+        v_bind_idx   NUMBER := 1;
+        v_cursor     NUMBER;
+        v_rows       NUMBER;
+        v_meta       t_column_meta;
+        v_safe_table VARCHAR2(128);
+    BEGIN
+        v_config := get_table_config(p_table_name);
+        IF v_config.id IS NULL OR v_config.allow_update != 1 THEN
+            RETURN t_crud_result.err(c_err_table_not_allowed, 'Table not allowed for UPDATE: ' || p_table_name);
+        END IF;
+        
+        IF p_columns IS NULL OR p_columns.COUNT = 0 THEN
+            RETURN t_crud_result.err(c_err_invalid_operation, 'No columns to update');
+        END IF;
+        
+        v_safe_table := safe_identifier(p_table_name);
+        
+        -- Build SET clause
+        FOR i IN 1..p_columns.COUNT LOOP
+            v_meta := get_column_meta(p_table_name, p_columns(i).column_name);
+            IF v_meta IS NULL THEN
+                RETURN t_crud_result.err(c_err_column_not_found, 
+                    'Column not found: ' || p_columns(i).column_name);
+            END IF;
+            
+            IF v_set IS NOT NULL AND LENGTH(v_set) > 0 THEN
+                v_set := v_set || ', ';
+            END IF;
+            
+            IF (p_columns(i).col_value IS NULL AND p_columns(i).col_clob IS NULL) OR p_columns(i).value_type = 'NULL' THEN
+                v_set := v_set || safe_identifier(p_columns(i).column_name) || ' = NULL';
+            ELSE
+                v_set := v_set || safe_identifier(p_columns(i).column_name) || 
+                         ' = ' || v_meta.get_bind_expression(v_bind_idx);
+                v_bind_idx := v_bind_idx + 1;
+            END IF;
+        END LOOP;
+        
+        -- Add audit columns
+        IF column_exists(p_table_name, 'UPDATED_AT') THEN
+            v_set := v_set || ', UPDATED_AT = CURRENT_TIMESTAMP';
+        END IF;
+        IF p_updated_by IS NOT NULL AND column_exists(p_table_name, 'UPDATED_BY') THEN
+            v_set := v_set || ', UPDATED_BY = :upd_by';
+        END IF;
+        
+        -- Build SQL
+        IF v_config.require_tenant = 1 THEN
+            v_sql := 'UPDATE ' || v_safe_table || 
+                     ' SET ' || v_set ||
+                     ' WHERE ' || safe_identifier(v_config.tenant_column) || ' = :tenant' ||
+                     ' AND ' || safe_identifier(v_config.id_column) || ' = :id';
+        ELSE
+            v_sql := 'UPDATE ' || v_safe_table || 
+                     ' SET ' || v_set ||
+                     ' WHERE ' || safe_identifier(v_config.id_column) || ' = :id';
+        END IF;
+        
+        -- Execute
+        v_cursor := DBMS_SQL.OPEN_CURSOR;
+        DBMS_SQL.PARSE(v_cursor, v_sql, DBMS_SQL.NATIVE);
+        
+        v_bind_idx := 1;
+        FOR i IN 1..p_columns.COUNT LOOP
+            IF (p_columns(i).col_value IS NOT NULL OR p_columns(i).col_clob IS NOT NULL) AND p_columns(i).value_type != 'NULL' THEN
+                bind_column_value(v_cursor, v_bind_idx, p_columns(i));
+                v_bind_idx := v_bind_idx + 1;
+            END IF;
+        END LOOP;
+        
+        IF p_updated_by IS NOT NULL AND column_exists(p_table_name, 'UPDATED_BY') THEN
+            DBMS_SQL.BIND_VARIABLE(v_cursor, ':upd_by', p_updated_by);
+        END IF;
+        
+        IF v_config.require_tenant = 1 THEN
+            DBMS_SQL.BIND_VARIABLE(v_cursor, ':tenant', p_tenant_id);
+        END IF;
+        DBMS_SQL.BIND_VARIABLE(v_cursor, ':id', p_id);
+        
+        v_rows := DBMS_SQL.EXECUTE(v_cursor);
+        DBMS_SQL.CLOSE_CURSOR(v_cursor);
+        
+        IF v_rows = 0 THEN
+            RETURN t_crud_result.err(c_err_not_found, 'Record not found');
+        END IF;
+        
+        RETURN t_crud_result.ok(p_id, v_rows);
+        
+    EXCEPTION
+        WHEN OTHERS THEN
+            IF DBMS_SQL.IS_OPEN(v_cursor) THEN
+                DBMS_SQL.CLOSE_CURSOR(v_cursor);
+            END IF;
+            log_error('do_update:' || p_table_name, SQLERRM);
+            RETURN t_crud_result.err(c_err_execution_failed, 'Update failed. See server logs.');
+    END do_update;
+    
+    FUNCTION do_delete(
+        p_table_name   VARCHAR2,
+        p_tenant_id    VARCHAR2,
+        p_id           NUMBER,
+        p_soft_delete  BOOLEAN DEFAULT TRUE,
+        p_deleted_by   VARCHAR2 DEFAULT NULL
+    ) RETURN t_crud_result IS
+        v_config     crud_allowed_tables%ROWTYPE;
+        v_sql        VARCHAR2(4000);
+        v_rows       NUMBER;
+        v_safe_table VARCHAR2(128);
+    BEGIN
+        v_config := get_table_config(p_table_name);
+        IF v_config.id IS NULL OR v_config.allow_delete != 1 THEN
+            RETURN t_crud_result.err(c_err_table_not_allowed, 'Table not allowed for DELETE: ' || p_table_name);
+        END IF;
+        
+        v_safe_table := safe_identifier(p_table_name);
+        
+        -- Try soft delete first if requested
+        IF p_soft_delete THEN
+            IF column_exists(p_table_name, 'ACTIVE') THEN
+                DECLARE
+                    v_set_clause VARCHAR2(400) := 'ACTIVE = 0';
+                    v_set_clause_no_tenant VARCHAR2(400) := 'ACTIVE = 0';
+                BEGIN
+                    IF column_exists(p_table_name, 'UPDATED_AT') THEN
+                        v_set_clause := v_set_clause || ', UPDATED_AT = CURRENT_TIMESTAMP';
+                        v_set_clause_no_tenant := v_set_clause_no_tenant || ', UPDATED_AT = CURRENT_TIMESTAMP';
+                    END IF;
+                    IF column_exists(p_table_name, 'DELETED_BY') AND p_deleted_by IS NOT NULL THEN
+                        v_set_clause := v_set_clause || ', DELETED_BY = :3';
+                        v_set_clause_no_tenant := v_set_clause_no_tenant || ', DELETED_BY = :2';
+                    END IF;
+
+                IF v_config.require_tenant = 1 THEN
+                    v_sql := 'UPDATE ' || v_safe_table || 
+                             ' SET ' || v_set_clause ||
+                             ' WHERE ' || safe_identifier(v_config.tenant_column) || ' = :1' ||
+                             ' AND ' || safe_identifier(v_config.id_column) || ' = :2';
+                    IF INSTR(v_set_clause, ':3') > 0 THEN
+                        EXECUTE IMMEDIATE v_sql USING p_tenant_id, p_id, p_deleted_by;
+                    ELSE
+                        EXECUTE IMMEDIATE v_sql USING p_tenant_id, p_id;
+                    END IF;
+                ELSE
+                    v_sql := 'UPDATE ' || v_safe_table || 
+                             ' SET ' || v_set_clause_no_tenant ||
+                             ' WHERE ' || safe_identifier(v_config.id_column) || ' = :1';
+                    IF INSTR(v_set_clause_no_tenant, ':2') > 0 THEN
+                        EXECUTE IMMEDIATE v_sql USING p_id, p_deleted_by;
+                    ELSE
+                        EXECUTE IMMEDIATE v_sql USING p_id;
+                    END IF;
+                END IF;
+                END;
+                v_rows := SQL%ROWCOUNT;
+                
+                IF v_rows > 0 THEN
+                    RETURN t_crud_result.ok(p_id, v_rows);
+                END IF;
+            ELSIF column_exists(p_table_name, 'DELETED_AT') THEN
+                -- Build SET clause for DELETED_AT and optionally UPDATED_AT
+                DECLARE
+                    v_set_clause VARCHAR2(200) := 'DELETED_AT = CURRENT_TIMESTAMP';
+                    v_set_clause_no_tenant VARCHAR2(200) := 'DELETED_AT = CURRENT_TIMESTAMP';
+                BEGIN
+                    IF column_exists(p_table_name, 'UPDATED_AT') THEN
+                        v_set_clause := v_set_clause || ', UPDATED_AT = CURRENT_TIMESTAMP';
+                        v_set_clause_no_tenant := v_set_clause_no_tenant || ', UPDATED_AT = CURRENT_TIMESTAMP';
+                    END IF;
+                    IF column_exists(p_table_name, 'DELETED_BY') AND p_deleted_by IS NOT NULL THEN
+                        v_set_clause := v_set_clause || ', DELETED_BY = :3';
+                        v_set_clause_no_tenant := v_set_clause_no_tenant || ', DELETED_BY = :2';
+                    END IF;
+                    
+                    IF v_config.require_tenant = 1 THEN
+                        v_sql := 'UPDATE ' || v_safe_table || 
+                                 ' SET ' || v_set_clause ||
+                                 ' WHERE ' || safe_identifier(v_config.tenant_column) || ' = :1' ||
+                                 ' AND ' || safe_identifier(v_config.id_column) || ' = :2';
+                        IF INSTR(v_set_clause, ':3') > 0 THEN
+                            EXECUTE IMMEDIATE v_sql USING p_tenant_id, p_id, p_deleted_by;
+                        ELSE
+                            EXECUTE IMMEDIATE v_sql USING p_tenant_id, p_id;
+                        END IF;
+                    ELSE
+                        v_sql := 'UPDATE ' || v_safe_table || 
+                                 ' SET ' || v_set_clause_no_tenant ||
+                                 ' WHERE ' || safe_identifier(v_config.id_column) || ' = :1';
+                        IF INSTR(v_set_clause_no_tenant, ':2') > 0 THEN
+                            EXECUTE IMMEDIATE v_sql USING p_id, p_deleted_by;
+                        ELSE
+                            EXECUTE IMMEDIATE v_sql USING p_id;
+                        END IF;
+                    END IF;
+                END;
+                v_rows := SQL%ROWCOUNT;
+                
+                IF v_rows > 0 THEN
+                    RETURN t_crud_result.ok(p_id, v_rows);
+                END IF;
+            END IF;
+        END IF;
+        
+        -- Hard delete
+        IF v_config.require_tenant = 1 THEN
+            v_sql := 'DELETE FROM ' || v_safe_table || 
+                     ' WHERE ' || safe_identifier(v_config.tenant_column) || ' = :1' ||
+                     ' AND ' || safe_identifier(v_config.id_column) || ' = :2';
+            EXECUTE IMMEDIATE v_sql USING p_tenant_id, p_id;
+        ELSE
+            v_sql := 'DELETE FROM ' || v_safe_table || 
+                     ' WHERE ' || safe_identifier(v_config.id_column) || ' = :1';
+            EXECUTE IMMEDIATE v_sql USING p_id;
+        END IF;
+        
+        v_rows := SQL%ROWCOUNT;
+        
+        IF v_rows = 0 THEN
+            RETURN t_crud_result.err(c_err_not_found, 'Record not found');
+        END IF;
+        
+        RETURN t_crud_result.ok(p_id, v_rows);
+        
+    EXCEPTION
+        WHEN OTHERS THEN
+            log_error('do_delete:' || p_table_name, SQLERRM);
+            RETURN t_crud_result.err(c_err_execution_failed, 'Delete failed. See server logs.');
+    END do_delete;
+    
+    -- ========================================================================
+    -- AGGREGATE OPERATIONS
+    -- ========================================================================
+    
+    FUNCTION do_aggregate(
+        p_parent_table VARCHAR2,
+        p_parent_id    NUMBER,
+        p_tenant_id    VARCHAR2,
+        p_child_table  VARCHAR2
+    ) RETURN t_crud_result IS
+        v_agg_config crud_allowed_aggregates%ROWTYPE;
+        v_parent_cfg crud_allowed_tables%ROWTYPE;
+        v_child_cfg  crud_allowed_tables%ROWTYPE;
+        v_sql        VARCHAR2(4000);
+        v_rows       NUMBER;
+    BEGIN
+        -- Get aggregate configuration
+        BEGIN
+            SELECT * INTO v_agg_config
+            FROM crud_allowed_aggregates
+            WHERE parent_table = UPPER(p_parent_table)
+              AND child_table = UPPER(p_child_table);
+        EXCEPTION
+            WHEN NO_DATA_FOUND THEN
+                RETURN t_crud_result.err(c_err_invalid_operation, 
+                    'No aggregate configured for ' || p_parent_table || ' <- ' || p_child_table);
+        END;
+        
+        -- Validate tables
+        v_parent_cfg := get_table_config(p_parent_table);
+        v_child_cfg := get_table_config(p_child_table);
+        
+        IF v_parent_cfg.id IS NULL OR v_child_cfg.id IS NULL THEN
+            RETURN t_crud_result.err(c_err_table_not_allowed, 'Tables not configured');
+        END IF;
+        
+        -- Validate columns exist
+        IF NOT column_exists(p_parent_table, v_agg_config.agg_column) THEN
+            RETURN t_crud_result.err(c_err_column_not_found, 'Aggregate column not found: ' || v_agg_config.agg_column);
+        END IF;
+        IF NOT column_exists(p_child_table, v_agg_config.fk_column) THEN
+            RETURN t_crud_result.err(c_err_column_not_found, 'FK column not found: ' || v_agg_config.fk_column);
+        END IF;
+        
+        -- Build and execute aggregate update
+        v_sql := 'UPDATE ' || safe_identifier(p_parent_table) ||
+                 ' SET ' || safe_identifier(v_agg_config.agg_column) || ' = COALESCE((' ||
+                 'SELECT ' || v_agg_config.expression ||
+                 ' FROM ' || safe_identifier(p_child_table) ||
+                 ' WHERE ' || safe_identifier(v_child_cfg.tenant_column) || ' = :1' ||
+                 ' AND ' || safe_identifier(v_agg_config.fk_column) || ' = :2), 0)';
+        
+        IF column_exists(p_parent_table, 'UPDATED_AT') THEN
+            v_sql := v_sql || ', UPDATED_AT = CURRENT_TIMESTAMP';
+        END IF;
+        
+        v_sql := v_sql || ' WHERE ' || safe_identifier(v_parent_cfg.tenant_column) || ' = :3' ||
+                 ' AND ' || safe_identifier(v_parent_cfg.id_column) || ' = :4';
+        
+        EXECUTE IMMEDIATE v_sql USING p_tenant_id, p_parent_id, p_tenant_id, p_parent_id;
+        v_rows := SQL%ROWCOUNT;
+        
+        IF v_rows = 0 THEN
+            RETURN t_crud_result.err(c_err_not_found, 'Parent record not found');
+        END IF;
+        
+        RETURN t_crud_result.ok(p_parent_id, v_rows);
+        
+    EXCEPTION
+        WHEN OTHERS THEN
+            log_error('do_aggregate:' || p_parent_table, SQLERRM);
+            RETURN t_crud_result.err(c_err_execution_failed, 'Aggregate update failed. See server logs.');
+    END do_aggregate;
+    
+    -- ========================================================================
+    -- UTILITY PROCEDURES
+    -- ========================================================================
+    
+    PROCEDURE register_table(
+        p_table_name    VARCHAR2,
+        p_allow_insert  BOOLEAN DEFAULT TRUE,
+        p_allow_update  BOOLEAN DEFAULT TRUE,
+        p_allow_delete  BOOLEAN DEFAULT TRUE,
+        p_require_tenant BOOLEAN DEFAULT TRUE
+    ) IS
+    BEGIN
+        MERGE INTO crud_allowed_tables t
+        USING (SELECT UPPER(p_table_name) AS table_name, USER AS schema_name FROM dual) s
+        ON (t.table_name = s.table_name AND t.schema_name = s.schema_name)
+        WHEN MATCHED THEN
+            UPDATE SET 
+                allow_insert = CASE WHEN p_allow_insert THEN 1 ELSE 0 END,
+                allow_update = CASE WHEN p_allow_update THEN 1 ELSE 0 END,
+                allow_delete = CASE WHEN p_allow_delete THEN 1 ELSE 0 END,
+                require_tenant = CASE WHEN p_require_tenant THEN 1 ELSE 0 END
+        WHEN NOT MATCHED THEN
+            INSERT (table_name, schema_name, allow_insert, allow_update, allow_delete, require_tenant)
+            VALUES (UPPER(p_table_name), USER, 
+                    CASE WHEN p_allow_insert THEN 1 ELSE 0 END,
+                    CASE WHEN p_allow_update THEN 1 ELSE 0 END,
+                    CASE WHEN p_allow_delete THEN 1 ELSE 0 END,
+                    CASE WHEN p_require_tenant THEN 1 ELSE 0 END);
+        
+        -- Clear cache
+        g_config_cache.DELETE(UPPER(p_table_name));
+    END register_table;
+    
+    -- Validate aggregate expression for safety
+    -- Only allows: aggregate functions, column names, arithmetic, NVL/COALESCE
+    FUNCTION validate_aggregate_expr(p_expr VARCHAR2) RETURN BOOLEAN IS
+        v_upper VARCHAR2(500) := UPPER(TRIM(p_expr));
+        v_dangerous_pattern VARCHAR2(200);
+    BEGIN
+        -- Check for empty expression
+        IF v_upper IS NULL OR LENGTH(v_upper) = 0 THEN
+            RETURN FALSE;
+        END IF;
+        
+        -- Reject dangerous SQL keywords (case-insensitive, word boundaries)
+        -- These patterns check for keywords not preceded/followed by alphanumeric chars
+        FOR i IN 1..12 LOOP
+            CASE i
+                WHEN 1 THEN v_dangerous_pattern := '(^|[^A-Z0-9_])SELECT($|[^A-Z0-9_])';
+                WHEN 2 THEN v_dangerous_pattern := '(^|[^A-Z0-9_])INSERT($|[^A-Z0-9_])';
+                WHEN 3 THEN v_dangerous_pattern := '(^|[^A-Z0-9_])UPDATE($|[^A-Z0-9_])';
+                WHEN 4 THEN v_dangerous_pattern := '(^|[^A-Z0-9_])DELETE($|[^A-Z0-9_])';
+                WHEN 5 THEN v_dangerous_pattern := '(^|[^A-Z0-9_])DROP($|[^A-Z0-9_])';
+                WHEN 6 THEN v_dangerous_pattern := '(^|[^A-Z0-9_])CREATE($|[^A-Z0-9_])';
+                WHEN 7 THEN v_dangerous_pattern := '(^|[^A-Z0-9_])ALTER($|[^A-Z0-9_])';
+                WHEN 8 THEN v_dangerous_pattern := '(^|[^A-Z0-9_])EXEC($|[^A-Z0-9_])';
+                WHEN 9 THEN v_dangerous_pattern := '(^|[^A-Z0-9_])EXECUTE($|[^A-Z0-9_])';
+                WHEN 10 THEN v_dangerous_pattern := '(^|[^A-Z0-9_])TRUNCATE($|[^A-Z0-9_])';
+                WHEN 11 THEN v_dangerous_pattern := '(^|[^A-Z0-9_])GRANT($|[^A-Z0-9_])';
+                WHEN 12 THEN v_dangerous_pattern := '(^|[^A-Z0-9_])REVOKE($|[^A-Z0-9_])';
+            END CASE;
+            
+            IF REGEXP_LIKE(v_upper, v_dangerous_pattern) THEN
+                RETURN FALSE;
+            END IF;
+        END LOOP;
+        
+        -- Reject semicolons (statement terminator)
+        IF INSTR(v_upper, ';') > 0 THEN
+            RETURN FALSE;
+        END IF;
+        
+        -- Reject comments
+        IF INSTR(v_upper, '--') > 0 OR INSTR(v_upper, '/*') > 0 THEN
+            RETURN FALSE;
+        END IF;
+        
+        -- Only allow safe characters: alphanumerics, spaces, (), *, +, -, /, ., _, ,
+        IF NOT REGEXP_LIKE(v_upper, '^[A-Z0-9_\s\(\)\*\+\-\/\.\,]+$') THEN
+            RETURN FALSE;
+        END IF;
+        
+        RETURN TRUE;
+    END validate_aggregate_expr;
+    
+    PROCEDURE register_aggregate(
+        p_parent_table VARCHAR2,
+        p_child_table  VARCHAR2,
+        p_agg_column   VARCHAR2,
+        p_fk_column    VARCHAR2,
+        p_expression   VARCHAR2
+    ) IS
+    BEGIN
+        -- Validate expression before storing
+        IF NOT validate_aggregate_expr(p_expression) THEN
+            RAISE_APPLICATION_ERROR(-20001, 'Invalid aggregate expression: contains disallowed characters or keywords');
+        END IF;
+        
+        -- Validate table and column identifiers
+        IF NOT REGEXP_LIKE(p_parent_table, '^[A-Za-z_][A-Za-z0-9_]*$') THEN
+            RAISE_APPLICATION_ERROR(-20001, 'Invalid parent table name');
+        END IF;
+        IF NOT REGEXP_LIKE(p_child_table, '^[A-Za-z_][A-Za-z0-9_]*$') THEN
+            RAISE_APPLICATION_ERROR(-20001, 'Invalid child table name');
+        END IF;
+        IF NOT REGEXP_LIKE(p_agg_column, '^[A-Za-z_][A-Za-z0-9_]*$') THEN
+            RAISE_APPLICATION_ERROR(-20001, 'Invalid aggregate column name');
+        END IF;
+        IF NOT REGEXP_LIKE(p_fk_column, '^[A-Za-z_][A-Za-z0-9_]*$') THEN
+            RAISE_APPLICATION_ERROR(-20001, 'Invalid FK column name');
+        END IF;
+        
+        MERGE INTO crud_allowed_aggregates t
+        USING (SELECT UPPER(p_parent_table) AS parent_table, 
+                      UPPER(p_child_table) AS child_table,
+                      UPPER(p_agg_column) AS agg_column FROM dual) s
+        ON (t.parent_table = s.parent_table AND t.child_table = s.child_table AND t.agg_column = s.agg_column)
+        WHEN MATCHED THEN
+            UPDATE SET 
+                fk_column = UPPER(p_fk_column),
+                expression = p_expression
+        WHEN NOT MATCHED THEN
+            INSERT (parent_table, child_table, agg_column, fk_column, expression)
+            VALUES (UPPER(p_parent_table), UPPER(p_child_table), UPPER(p_agg_column), 
+                    UPPER(p_fk_column), p_expression);
+    END register_aggregate;
+    
+    PROCEDURE clear_cache IS
+    BEGIN
+        g_meta_cache.DELETE;
+        g_config_cache.DELETE;
+    END clear_cache;
+    
+END pkg_crud;
+/
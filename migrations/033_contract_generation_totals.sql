@@ -0,0 +1,898 @@
+-- Contract Discount and Tax in Generated Contracts
+-- Migration: 033_contract_generation_totals.sql
+--
+-- Carries the contract-level discount and tax added in 023 into contract
+-- generation. t_contract_data gains the subtotal, discount and tax amounts
+-- and a formatted_amount helper; pkg_data_api is recreated so load_contract
+-- reads them, and the pkg_contract_generation body so the summary reports
+-- the stored contract totals. The summary keeps total_discount and
+-- formatted_total_discount, the sum of the item discounts, alongside the
+-- new items_discount keys. The pkg_contract_generation specification is
+-- unchanged.
+
+ALTER TYPE t_contract_data ADD ATTRIBUTE (
+    subtotal        NUMBER(15,2),
+    discount_pct    NUMBER(5,2),
+    discount_amount NUMBER(15,2),
+    tax_pct         NUMBER(5,2),
+    tax_amount      NUMBER(15,2)
+) CASCADE;
+
+-- Amount in the format of formatted_total_value, zero when NULL
+ALTER TYPE t_contract_data ADD MEMBER FUNCTION formatted_amount(p_amount NUMBER) RETURN VARCHAR2 CASCADE;
+
+CREATE OR REPLACE TYPE BODY t_contract_data AS
+    MEMBER FUNCTION to_json RETURN JSON_OBJECT_T IS
+        v_json JSON_OBJECT_T := JSON_OBJECT_T();
+    BEGIN
+        v_json.put('id', SELF.id);
+        v_json.put('contract_number', SELF.contract_number);
+        v_json.put('contract_type', SELF.contract_type);
+        v_json.put('contract_type_label', SELF.contract_type_label());
+        v_json.put('status', SELF.status);
+        v_json.put('status_label', SELF.status_label());
+        v_json.put('start_date', TO_CHAR(SELF.start_date, 'YYYY-MM-DD'));
+        v_json.put('formatted_start_date', SELF.formatted_start_date());
+        v_json.put('end_date', TO_CHAR(SELF.end_date, 'YYYY-MM-DD'));
+        v_json.put('formatted_end_date', SELF.formatted_end_date());
+        v_json.put('duration_months', SELF.duration_months);
+        v_json.put('auto_renew', CASE SELF.auto_renew WHEN 1 THEN TRUE ELSE FALSE END);
+        v_json.put('subtotal', SELF.subtotal);
+        v_json.put('formatted_subtotal', SELF.formatted_amount(SELF.subtotal));
+        v_json.put('discount_pct', SELF.discount_pct);
+        v_json.put('discount_amount', SELF.discount_amount);
+        v_json.put('formatted_discount_amount', SELF.formatted_amount(SELF.discount_amount));
+        v_json.put('tax_pct', SELF.tax_pct);
+        v_json.put('tax_amount', SELF.tax_amount);
+        v_json.put('formatted_tax_amount', SELF.formatted_amount(SELF.tax_amount));
+        v_json.put('total_value', SELF.total_value);
+        v_json.put('formatted_total_value', SELF.formatted_total_value());
+        v_json.put('payment_terms', SELF.payment_terms);
+        v_json.put('billing_cycle', SELF.billing_cycle);
+        v_json.put('billing_cycle_label', SELF.billing_cycle_label());
+        v_json.put('signed_at', TO_CHAR(SELF.signed_at, 'YYYY-MM-DD"T"HH24:MI:SS'));
+        v_json.put('signed_by', SELF.signed_by);
+        v_json.put('notes', SELF.notes);
+        v_json.put('terms_conditions', SELF.terms_conditions);
+        v_json.put('created_at', TO_CHAR(SELF.created_at, 'YYYY-MM-DD"T"HH24:MI:SS'));
+        RETURN v_json;
+    END;
+    
+    MEMBER FUNCTION contract_type_label RETURN VARCHAR2 IS
+    BEGIN
+        RETURN CASE SELF.contract_type
+            WHEN 'SERVICE' THEN 'Prestação de Serviços'
+            WHEN 'RECURRING' THEN 'Recorrente'
+            WHEN 'PROJECT' THEN 'Projeto'
+            ELSE SELF.contract_type
+        END;
+    END;
+    
+    MEMBER FUNCTION status_label RETURN VARCHAR2 IS
+    BEGIN
+        RETURN CASE SELF.status
+            WHEN 'DRAFT' THEN 'Rascunho'
+            WHEN 'PENDING' THEN 'Pendente'
+            WHEN 'ACTIVE' THEN 'Ativo'
+            WHEN 'SUSPENDED' THEN 'Suspenso'
+            WHEN 'CANCELLED' THEN 'Cancelado'
+            WHEN 'COMPLETED' THEN 'Concluído'
+            ELSE SELF.status
+        END;
+    END;
+    
+    MEMBER FUNCTION billing_cycle_label RETURN VARCHAR2 IS
+    BEGIN
+        RETURN CASE SELF.billing_cycle
+            WHEN 'MONTHLY' THEN 'Mensal'
+            WHEN 'QUARTERLY' THEN 'Trimestral'
+            WHEN 'YEARLY' THEN 'Anual'
+            WHEN 'ONCE' THEN 'Pagamento Único'
+            ELSE SELF.billing_cycle
+        END;
+    END;
+    
+    MEMBER FUNCTION formatted_total_value RETURN VARCHAR2 IS
+    BEGIN
+        RETURN SELF.formatted_amount(SELF.total_value);
+    END;
+    
+    MEMBER FUNCTION formatted_amount(p_amount NUMBER) RETURN VARCHAR2 IS
+    BEGIN
+        RETURN 'R$ ' || TO_CHAR(NVL(p_amount, 0), 'FM999G999G999D00', 'NLS_NUMERIC_CHARACTERS='',.''');
+    END;
+    
+    MEMBER FUNCTION formatted_start_date RETURN VARCHAR2 IS
+    BEGIN
+        RETURN TO_CHAR(SELF.start_date, 'DD "de" TMMonth "de" YYYY', 'NLS_DATE_LANGUAGE=PORTUGUESE');
+    END;
+    
+    MEMBER FUNCTION formatted_end_date RETURN VARCHAR2 IS
+    BEGIN
+        IF SELF.end_date IS NULL THEN
+            RETURN NULL;
+        END IF;
+        RETURN TO_CHAR(SELF.end_date, 'DD "de" TMMonth "de" YYYY', 'NLS_DATE_LANGUAGE=PORTUGUESE');
+    END;
+    
+    MEMBER FUNCTION is_active RETURN BOOLEAN IS
+    BEGIN
+        RETURN SELF.status = 'ACTIVE';
+    END;
+END;
+/
+
+CREATE OR REPLACE PACKAGE pkg_data_api AS
+    /*
+    ============================================================================
+    DATA API PACKAGE
+    ============================================================================
+    Provides dynamic, tenant-aware data loading operations.
+    Abstracts direct table access for security and maintainability.
+    ============================================================================
+    */
+    
+    -- Entity types
+    gc_entity_customer  CONSTANT VARCHAR2(30) := 'CUSTOMER';
+    gc_entity_service   CONSTANT VARCHAR2(30) := 'SERVICE';
+    gc_entity_contract  CONSTANT VARCHAR2(30) := 'CONTRACT';
+    
+    -- Load customer data into type
+    FUNCTION load_customer(
+        p_tenant_id   IN VARCHAR2,
+        p_customer_id IN NUMBER
+    ) RETURN t_customer_data;
+    
+    -- Load contract data into type
+    FUNCTION load_contract(
+        p_tenant_id   IN VARCHAR2,
+        p_contract_id IN NUMBER
+    ) RETURN t_contract_data;
+    
+    -- Load contract items into collection
+    FUNCTION load_contract_items(
+        p_tenant_id   IN VARCHAR2,
+        p_contract_id IN NUMBER
+    ) RETURN t_service_items;
+    
+    -- Load template data
+    FUNCTION load_template(
+        p_tenant_id     IN VARCHAR2,
+        p_template_code IN VARCHAR2 DEFAULT NULL
+    ) RETURN t_template_data;
+    
+    -- Get entity as JSON by ID
+    FUNCTION get_entity_json(
+        p_tenant_id   IN VARCHAR2,
+        p_entity_type IN VARCHAR2,
+        p_entity_id   IN NUMBER
+    ) RETURN CLOB;
+    
+    -- Check entity exists
+    FUNCTION entity_exists(
+        p_tenant_id   IN VARCHAR2,
+        p_entity_type IN VARCHAR2,
+        p_entity_id   IN NUMBER
+    ) RETURN BOOLEAN;
+    
+    -- Hash utilities
+    FUNCTION hash_sha256(p_value IN VARCHAR2) RETURN VARCHAR2;
+    FUNCTION hash_clob_sha256(p_value IN CLOB) RETURN VARCHAR2;
+
+END pkg_data_api;
+/
+
+CREATE OR REPLACE PACKAGE BODY pkg_data_api AS
+
+    FUNCTION hash_sha256(p_value IN VARCHAR2) RETURN VARCHAR2 IS
+    BEGIN
+        IF p_value IS NULL THEN
+            RETURN NULL;
+        END IF;
+        RETURN LOWER(RAWTOHEX(DBMS_CRYPTO.HASH(
+            UTL_I18N.STRING_TO_RAW(p_value, 'AL32UTF8'),
+            DBMS_CRYPTO.HASH_SH256
+        )));
+    END;
+    
+    FUNCTION hash_clob_sha256(p_value IN CLOB) RETURN VARCHAR2 IS
+    BEGIN
+        IF p_value IS NULL OR DBMS_LOB.GETLENGTH(p_value) = 0 THEN
+            RETURN NULL;
+        END IF;
+        RETURN LOWER(RAWTOHEX(DBMS_CRYPTO.HASH(p_value, DBMS_CRYPTO.HASH_SH256)));
+    END;
+
+    FUNCTION load_customer(
+        p_tenant_id   IN VARCHAR2,
+        p_customer_id IN NUMBER
+    ) RETURN t_customer_data IS
+        v_cust t_customer_data;
+    BEGIN
+        SELECT t_customer_data(
+            id              => c.id,
+            customer_code   => c.customer_code,
+            customer_type   => c.customer_type,
+            name            => c.name,
+            trade_name      => c.trade_name,
+            tax_id          => c.tax_id,
+            state_reg       => c.state_reg,
+            municipal_reg   => c.municipal_reg,
+            email           => c.email,
+            phone           => c.phone,
+            mobile          => c.mobile,
+            address         => t_address(
+                street      => c.address_street,
+                number_     => c.address_number,
+                complement  => c.address_comp,
+                district    => c.address_district,
+                city        => c.address_city,
+                state       => c.address_state,
+                zip         => c.address_zip,
+                country     => c.address_country
+            )
+        )
+        INTO v_cust
+        FROM customers c
+        WHERE c.tenant_id = p_tenant_id
+          AND c.id = p_customer_id;
+        
+        RETURN v_cust;
+    EXCEPTION
+        WHEN NO_DATA_FOUND THEN
+            RETURN NULL;
+    END;
+    
+    FUNCTION load_contract(
+        p_tenant_id   IN VARCHAR2,
+        p_contract_id IN NUMBER
+    ) RETURN t_contract_data IS
+        v_contract t_contract_data;
+    BEGIN
+        SELECT t_contract_data(
+            id              => c.id,
+            contract_number => c.contract_number,
+            contract_type   => c.contract_type,
+            status          => c.status,
+            start_date      => c.start_date,
+            end_date        => c.end_date,
+            duration_months => c.duration_months,
+            auto_renew      => c.auto_renew,
+            subtotal        => c.subtotal,
+            discount_pct    => c.discount_pct,
+            discount_amount => c.discount_amount,
+            tax_pct         => c.tax_pct,
+            tax_amount      => c.tax_amount,
+            total_value     => c.total_value,
+            payment_terms   => c.payment_terms,
+            billing_cycle   => c.billing_cycle,
+            signed_at       => c.signed_at,
+            signed_by       => c.signed_by,
+            notes           => c.notes,
+            terms_conditions => c.terms_conditions,
+            created_at      => c.created_at
+        )
+        INTO v_contract
+        FROM contracts c
+        WHERE c.tenant_id = p_tenant_id
+          AND c.id = p_contract_id;
+        
+        RETURN v_contract;
+    EXCEPTION
+        WHEN NO_DATA_FOUND THEN
+            RETURN NULL;
+    END;
+    
+    FUNCTION load_contract_items(
+        p_tenant_id   IN VARCHAR2,
+        p_contract_id IN NUMBER
+    ) RETURN t_service_items IS
+        v_items t_service_items := t_service_items();
+    BEGIN
+        SELECT t_service_item(
+            id           => ci.id,
+            service_id   => ci.service_id,
+            service_code => s.service_code,
+            service_name => s.name,
+            description  => NVL(ci.description, s.description),
+            quantity     => ci.quantity,
+            unit_price   => ci.unit_price,
+            discount_pct => ci.discount_pct,
+            line_total   => ci.line_total,
+            price_unit   => s.price_unit,
+            status       => ci.status
+        )
+        BULK COLLECT INTO v_items
+        FROM contract_items ci
+        JOIN services s ON s.tenant_id = ci.tenant_id AND s.id = ci.service_id
+        WHERE ci.tenant_id = p_tenant_id
+          AND ci.contract_id = p_contract_id
+          AND ci.status != 'CANCELLED'
+        ORDER BY ci.id;
+        
+        RETURN v_items;
+    END;
+    
+    FUNCTION load_template(
+        p_tenant_id     IN VARCHAR2,
+        p_template_code IN VARCHAR2 DEFAULT NULL
+    ) RETURN t_template_data IS
+        v_template t_template_data;
+    BEGIN
+        IF p_template_code IS NOT NULL THEN
+            SELECT t_template_data(
+                id                 => t.id,
+                template_code      => t.template_code,
+                template_name      => t.template_name,
+                language           => t.language,
+                intro_text         => t.intro_text,
+                payment_terms_text => t.payment_terms_text,
+                general_terms      => t.general_terms,
+                confidentiality    => t.confidentiality,
+                termination_clause => t.termination_clause,
+                dispute_resolution => t.dispute_resolution
+            )
+            INTO v_template
+            FROM contract_templates t
+            WHERE t.tenant_id = p_tenant_id
+              AND t.template_code = p_template_code
+              AND t.active = 1;
+        ELSE
+            SELECT t_template_data(
+                id                 => t.id,
+                template_code      => t.template_code,
+                template_name      => t.template_name,
+                language           => t.language,
+                intro_text         => t.intro_text,
+                payment_terms_text => t.payment_terms_text,
+                general_terms      => t.general_terms,
+                confidentiality    => t.confidentiality,
+                termination_clause => t.termination_clause,
+                dispute_resolution => t.dispute_resolution
+            )
+            INTO v_template
+            FROM contract_templates t
+            WHERE t.tenant_id = p_tenant_id
+              AND t.is_default = 1
+              AND t.active = 1
+            ORDER BY t.id DESC
+            FETCH FIRST 1 ROW ONLY;
+        END IF;
+        
+        RETURN v_template;
+    EXCEPTION
+        WHEN NO_DATA_FOUND THEN
+            RETURN NULL;
+    END;
+    
+    FUNCTION get_entity_json(
+        p_tenant_id   IN VARCHAR2,
+        p_entity_type IN VARCHAR2,
+        p_entity_id   IN NUMBER
+    ) RETURN CLOB IS
+        v_result CLOB;
+    BEGIN
+        CASE UPPER(p_entity_type)
+            WHEN gc_entity_customer THEN
+                SELECT JSON_OBJECT(
+                    'id' VALUE id,
+                    'customer_code' VALUE customer_code,
+                    'customer_type' VALUE customer_type,
+                    'name' VALUE name,
+                    'trade_name' VALUE trade_name,
+                    'tax_id' VALUE tax_id,
+                    'email' VALUE email,
+                    'phone' VALUE phone,
+                    'active' VALUE CASE active WHEN 1 THEN 'true' ELSE 'false' END FORMAT JSON
+                    RETURNING CLOB
+                )
+                INTO v_result
+                FROM customers
+                WHERE tenant_id = p_tenant_id AND id = p_entity_id;
+                
+            WHEN gc_entity_service THEN
+                SELECT JSON_OBJECT(
+                    'id' VALUE id,
+                    'service_code' VALUE service_code,
+                    'name' VALUE name,
+                    'description' VALUE description,
+                    'category' VALUE category,
+                    'unit_price' VALUE unit_price,
+                    'price_unit' VALUE price_unit,
+                    'active' VALUE CASE active WHEN 1 THEN 'true' ELSE 'false' END FORMAT JSON
+                    RETURNING CLOB
+                )
+                INTO v_result
+                FROM services
+                WHERE tenant_id = p_tenant_id AND id = p_entity_id;
+                
+            WHEN gc_entity_contract THEN
+                SELECT JSON_OBJECT(
+                    'id' VALUE id,
+                    'contract_number' VALUE contract_number,
+                    'contract_type' VALUE contract_type,
+                    'customer_id' VALUE customer_id,
+                    'status' VALUE status,
+                    'start_date' VALUE TO_CHAR(start_date, 'YYYY-MM-DD'),
+                    'end_date' VALUE TO_CHAR(end_date, 'YYYY-MM-DD'),
+                    'total_value' VALUE total_value,
+                    'billing_cycle' VALUE billing_cycle
+                    RETURNING CLOB
+                )
+                INTO v_result
+                FROM contracts
+                WHERE tenant_id = p_tenant_id AND id = p_entity_id;
+                
+            ELSE
+                RAISE_APPLICATION_ERROR(-20001, 'Unknown entity type: ' || p_entity_type);
+        END CASE;
+        
+        RETURN v_result;
+    EXCEPTION
+        WHEN NO_DATA_FOUND THEN
+            RETURN NULL;
+    END;
+    
+    FUNCTION entity_exists(
+        p_tenant_id   IN VARCHAR2,
+        p_entity_type IN VARCHAR2,
+        p_entity_id   IN NUMBER
+    ) RETURN BOOLEAN IS
+        v_count NUMBER;
+    BEGIN
+        CASE UPPER(p_entity_type)
+            WHEN gc_entity_customer THEN
+                SELECT COUNT(*) INTO v_count FROM customers 
+                WHERE tenant_id = p_tenant_id AND id = p_entity_id;
+            WHEN gc_entity_service THEN
+                SELECT COUNT(*) INTO v_count FROM services 
+                WHERE tenant_id = p_tenant_id AND id = p_entity_id;
+            WHEN gc_entity_contract THEN
+                SELECT COUNT(*) INTO v_count FROM contracts 
+                WHERE tenant_id = p_tenant_id AND id = p_entity_id;
+            ELSE
+                RETURN FALSE;
+        END CASE;
+        
+        RETURN v_count > 0;
+    END;
+
+END pkg_data_api;
+/
+
+CREATE OR REPLACE PACKAGE BODY pkg_contract_generation AS
+
+    -- Build the complete JSON structure for a contract
+    FUNCTION build_contract_json(
+        p_tenant_id     IN VARCHAR2,
+        p_contract_id   IN NUMBER,
+        p_template_code IN VARCHAR2
+    ) RETURN CLOB IS
+        v_contract  t_contract_data;
+        v_customer  t_customer_data;
+        v_template  t_template_data;
+        v_items     t_service_items;
+        
+        v_root      JSON_OBJECT_T := JSON_OBJECT_T();
+        v_meta      JSON_OBJECT_T := JSON_OBJECT_T();
+        v_items_arr JSON_ARRAY_T := JSON_ARRAY_T();
+    BEGIN
+        -- Load all data using the API
+        v_contract := pkg_data_api.load_contract(p_tenant_id, p_contract_id);
+        IF v_contract IS NULL THEN
+            RAISE_APPLICATION_ERROR(-20001, gc_err_contract_notfound);
+        END IF;
+        
+        -- Get customer from contract
+        DECLARE
+            v_customer_id NUMBER;
+        BEGIN
+            SELECT customer_id INTO v_customer_id
+            FROM contracts WHERE tenant_id = p_tenant_id AND id = p_contract_id;
+            v_customer := pkg_data_api.load_customer(p_tenant_id, v_customer_id);
+        END;
+        
+        IF v_customer IS NULL THEN
+            RAISE_APPLICATION_ERROR(-20002, gc_err_customer_notfound);
+        END IF;
+        
+        v_items := pkg_data_api.load_contract_items(p_tenant_id, p_contract_id);
+        IF v_items IS NULL OR v_items.COUNT = 0 THEN
+            RAISE_APPLICATION_ERROR(-20003, gc_err_no_items);
+        END IF;
+        
+        v_template := pkg_data_api.load_template(p_tenant_id, p_template_code);
+        
+        -- Build metadata
+        v_meta.put('generated_at', TO_CHAR(SYSTIMESTAMP, 'YYYY-MM-DD"T"HH24:MI:SS.FF3TZH:TZM'));
+        v_meta.put('generated_date_formatted', TO_CHAR(SYSDATE, 'DD "de" TMMonth "de" YYYY', 'NLS_DATE_LANGUAGE=PORTUGUESE'));
+        v_meta.put('template_code', CASE WHEN v_template IS NOT NULL THEN v_template.template_code ELSE 'NONE' END);
+        v_meta.put('template_name', CASE WHEN v_template IS NOT NULL THEN v_template.template_name ELSE NULL END);
+        v_meta.put('version', '1.0');
+        
+        -- Build items array
+        FOR i IN 1..v_items.COUNT LOOP
+            v_items_arr.append(v_items(i).to_json());
+        END LOOP;
+        
+        -- Assemble root object
+        v_root.put('meta', v_meta);
+        v_root.put('contract', v_contract.to_json());
+        v_root.put('customer', v_customer.to_json());
+        v_root.put('items', v_items_arr);
+        v_root.put('items_count', v_items.COUNT);
+        
+        -- Add template text if available
+        IF v_template IS NOT NULL THEN
+            v_root.put('template', v_template.to_json());
+        END IF;
+        
+        -- Summary section: item discounts, then the stored contract totals
+        DECLARE
+            v_summary JSON_OBJECT_T := JSON_OBJECT_T();
+            v_items_discount NUMBER := 0;
+        BEGIN
+            FOR i IN 1..v_items.COUNT LOOP
+                v_items_discount := v_items_discount + v_items(i).discount_amount();
+            END LOOP;
+            
+            v_summary.put('items_discount', v_items_discount);
+            v_summary.put('formatted_items_discount', v_contract.formatted_amount(v_items_discount));
+            -- Kept for templates written before the contract discount
+            v_summary.put('total_discount', v_items_discount);
+            v_summary.put('formatted_total_discount', v_contract.formatted_amount(v_items_discount));
+            v_summary.put('subtotal', v_contract.subtotal);
+            v_summary.put('formatted_subtotal', v_contract.formatted_amount(v_contract.subtotal));
+            v_summary.put('discount_pct', v_contract.discount_pct);
+            v_summary.put('discount_amount', v_contract.discount_amount);
+            v_summary.put('formatted_discount_amount', v_contract.formatted_amount(v_contract.discount_amount));
+            v_summary.put('tax_pct', v_contract.tax_pct);
+            v_summary.put('tax_amount', v_contract.tax_amount);
+            v_summary.put('formatted_tax_amount', v_contract.formatted_amount(v_contract.tax_amount));
+            v_summary.put('total', v_contract.total_value);
+            v_summary.put('formatted_total', v_contract.formatted_total_value());
+            
+            v_root.put('summary', v_summary);
+        END;
+        
+        RETURN v_root.to_clob();
+    END;
+
+    FUNCTION get_contract_json(
+        p_tenant_id     IN VARCHAR2,
+        p_contract_id   IN NUMBER,
+        p_template_code IN VARCHAR2 DEFAULT NULL
+    ) RETURN CLOB IS
+    BEGIN
+        RETURN build_contract_json(p_tenant_id, p_contract_id, p_template_code);
+    END;
+
+    PROCEDURE generate_contract(
+        p_tenant_id     IN  VARCHAR2,
+        p_contract_id   IN  NUMBER,
+        p_user_id       IN  VARCHAR2,
+        p_template_code IN  VARCHAR2 DEFAULT NULL,
+        p_reason        IN  VARCHAR2 DEFAULT 'INITIAL',
+        p_ip_address    IN  VARCHAR2 DEFAULT NULL,
+        p_session_id    IN  VARCHAR2 DEFAULT NULL,
+        p_result        OUT t_gen_result,
+        p_contract_json OUT CLOB
+    ) IS
+        v_json          CLOB;
+        v_hash          VARCHAR2(64);
+        v_gen_id        NUMBER;
+        v_gen_num       NUMBER;
+        v_customer_name VARCHAR2(255);
+        v_total_value   NUMBER;
+        v_items_count   NUMBER;
+        v_template_id   NUMBER;
+    BEGIN
+        -- Validate contract exists
+        IF NOT pkg_data_api.entity_exists(p_tenant_id, 'CONTRACT', p_contract_id) THEN
+            p_result := t_gen_result.err(gc_err_contract_notfound, 'Contract not found');
+            p_contract_json := NULL;
+            -- Do NOT call log_action here: contract doesn't exist, FK would fail
+            RETURN;
+        END IF;
+        
+        -- Build JSON
+        BEGIN
+            v_json := build_contract_json(p_tenant_id, p_contract_id, p_template_code);
+        EXCEPTION
+            WHEN OTHERS THEN
+                p_result := t_gen_result.err(gc_err_generation_failed, SQLERRM);
+                p_contract_json := NULL;
+                log_action(p_tenant_id, p_contract_id, 'GENERATE', p_user_id, p_ip_address, p_session_id, 'FAILED', gc_err_generation_failed);
+                RETURN;
+        END;
+        
+        v_hash := pkg_data_api.hash_clob_sha256(v_json);
+        
+        -- Get snapshot data
+        SELECT c.name, con.total_value
+        INTO v_customer_name, v_total_value
+        FROM contracts con
+        JOIN customers c ON c.tenant_id = con.tenant_id AND c.id = con.customer_id
+        WHERE con.id = p_contract_id AND con.tenant_id = p_tenant_id;
+        
+        SELECT COUNT(*) INTO v_items_count
+        FROM contract_items
+        WHERE contract_id = p_contract_id AND tenant_id = p_tenant_id AND status != 'CANCELLED';
+        
+        -- Get template ID if specified
+        BEGIN
+            IF p_template_code IS NOT NULL THEN
+                SELECT id INTO v_template_id FROM contract_templates
+                WHERE tenant_id = p_tenant_id AND template_code = p_template_code AND active = 1;
+            ELSE
+                SELECT id INTO v_template_id FROM contract_templates
+                WHERE tenant_id = p_tenant_id AND is_default = 1 AND active = 1
+                FETCH FIRST 1 ROW ONLY;
+            END IF;
+        EXCEPTION
+            WHEN NO_DATA_FOUND THEN
+                v_template_id := NULL;
+        END;
+        
+        -- Get next generation number with retry logic for race condition
+        -- Uses unique constraint uk_generated_contract_gennum as guard
+        DECLARE
+            v_retry_count NUMBER := 0;
+            gc_max_retries CONSTANT NUMBER := 5;
+        BEGIN
+            LOOP
+                SELECT NVL(MAX(generation_number), 0) + 1
+                INTO v_gen_num
+                FROM generated_contracts
+                WHERE tenant_id = p_tenant_id AND contract_id = p_contract_id;
+                
+                BEGIN
+                    INSERT INTO generated_contracts (
+                        tenant_id, contract_id, template_id, generation_number,
+                        contract_json, content_hash,
+                        customer_name_snapshot, total_value_snapshot, services_count_snapshot,
+                        generated_by, generation_reason
+                    ) VALUES (
+                        p_tenant_id, p_contract_id, v_template_id, v_gen_num,
+                        v_json, v_hash,
+                        v_customer_name, v_total_value, v_items_count,
+                        p_user_id, p_reason
+                    ) RETURNING id INTO v_gen_id;
+                    
+                    EXIT; -- Success, exit retry loop
+                EXCEPTION
+                    WHEN DUP_VAL_ON_INDEX THEN
+                        v_retry_count := v_retry_count + 1;
+                        IF v_retry_count >= gc_max_retries THEN
+                            RAISE_APPLICATION_ERROR(-20010, 'Failed to generate unique generation_number after ' || gc_max_retries || ' attempts');
+                        END IF;
+                        -- Loop will retry with new MAX+1
+                END;
+            END LOOP;
+        END;
+        
+        -- Note: No COMMIT here - caller manages transaction
+        
+        p_result := t_gen_result.ok(v_gen_id, v_hash);
+        p_contract_json := v_json;
+        
+        log_action(
+            p_tenant_id    => p_tenant_id,
+            p_contract_id  => p_contract_id,
+            p_generated_id => v_gen_id,
+            p_action       => 'GENERATE',
+            p_user_id      => p_user_id,
+            p_ip_address   => p_ip_address,
+            p_session_id   => p_session_id,
+            p_status       => 'SUCCESS',
+            p_error_code   => NULL
+        );
+        
+    EXCEPTION
+        WHEN OTHERS THEN
+            -- Log the failure before rolling back (log_action uses AUTONOMOUS_TRANSACTION)
+            log_action(
+                p_tenant_id    => p_tenant_id,
+                p_contract_id  => p_contract_id,
+                p_generated_id => NULL,
+                p_action       => 'GENERATE',
+                p_user_id      => p_user_id,
+                p_ip_address   => p_ip_address,
+                p_session_id   => p_session_id,
+                p_status       => 'FAILED',
+                p_error_code   => gc_err_generation_failed || ': ' || SQLERRM
+            );
+            ROLLBACK;
+            p_result := t_gen_result.err(gc_err_generation_failed, SQLERRM);
+            p_contract_json := NULL;
+    END;
+    
+    PROCEDURE get_generated(
+        p_tenant_id    IN  VARCHAR2,
+        p_generated_id IN  NUMBER,
+        p_user_id      IN  VARCHAR2,
+        p_json_data    OUT CLOB,
+        p_content_hash OUT VARCHAR2,
+        p_generated_at OUT TIMESTAMP,
+        p_success      OUT NUMBER,
+        p_error_code   OUT VARCHAR2
+    ) IS
+        v_contract_id NUMBER;
+    BEGIN
+        SELECT contract_json, content_hash, generated_at, contract_id
+        INTO p_json_data, p_content_hash, p_generated_at, v_contract_id
+        FROM generated_contracts
+        WHERE id = p_generated_id AND tenant_id = p_tenant_id;
+        
+        p_success := 1;
+        p_error_code := NULL;
+        
+        log_action(p_tenant_id, v_contract_id, p_generated_id, 'VIEW', p_user_id, NULL, NULL, 'SUCCESS', NULL);
+    EXCEPTION
+        WHEN NO_DATA_FOUND THEN
+            p_json_data := NULL;
+            p_content_hash := NULL;
+            p_generated_at := NULL;
+            p_success := 0;
+            p_error_code := gc_err_contract_notfound;
+    END;
+    
+    PROCEDURE get_latest_generated(
+        p_tenant_id    IN  VARCHAR2,
+        p_contract_id  IN  NUMBER,
+        p_user_id      IN  VARCHAR2,
+        p_json_data    OUT CLOB,
+        p_content_hash OUT VARCHAR2,
+        p_gen_id       OUT NUMBER,
+        p_gen_at       OUT TIMESTAMP,
+        p_success      OUT NUMBER,
+        p_error_code   OUT VARCHAR2
+    ) IS
+    BEGIN
+        SELECT contract_json, content_hash, id, generated_at
+        INTO p_json_data, p_content_hash, p_gen_id, p_gen_at
+        FROM generated_contracts
+        WHERE tenant_id = p_tenant_id AND contract_id = p_contract_id
+        ORDER BY generated_at DESC
+        FETCH FIRST 1 ROW ONLY;
+        
+        p_success := 1;
+        p_error_code := NULL;
+        
+        log_action(p_tenant_id, p_contract_id, p_gen_id, 'VIEW', p_user_id, NULL, NULL, 'SUCCESS', NULL);
+    EXCEPTION
+        WHEN NO_DATA_FOUND THEN
+            p_json_data := NULL;
+            p_content_hash := NULL;
+            p_gen_id := NULL;
+            p_gen_at := NULL;
+            p_success := 0;
+            p_error_code := gc_err_contract_notfound;
+    END;
+    
+    PROCEDURE log_action(
+        p_tenant_id    IN VARCHAR2,
+        p_contract_id  IN NUMBER,
+        p_generated_id IN NUMBER   DEFAULT NULL,
+        p_action       IN VARCHAR2,
+        p_user_id      IN VARCHAR2,
+        p_ip_address   IN VARCHAR2 DEFAULT NULL,
+        p_session_id   IN VARCHAR2 DEFAULT NULL,
+        p_status       IN VARCHAR2 DEFAULT 'SUCCESS',
+        p_error_code   IN VARCHAR2 DEFAULT NULL
+    ) IS
+        PRAGMA AUTONOMOUS_TRANSACTION;
+    BEGIN
+        INSERT INTO contract_generation_log (
+            tenant_id, contract_id, generated_id, action, action_status,
+            performed_by, ip_address_hash, session_id_hash,
+            error_code, error_category
+        ) VALUES (
+            p_tenant_id, p_contract_id, p_generated_id, p_action, p_status,
+            p_user_id, 
+            pkg_data_api.hash_sha256(p_ip_address), 
+            pkg_data_api.hash_sha256(p_session_id),
+            p_error_code,
+            CASE 
+                WHEN p_error_code LIKE '%UNAUTHORIZED%' OR p_error_code LIKE '%DENIED%' THEN 'AUTH'
+                WHEN p_error_code LIKE '%NOT_FOUND%' OR p_error_code LIKE '%INVALID%' THEN 'VALIDATION'
+                WHEN p_error_code IS NOT NULL THEN 'SYSTEM'
+                ELSE NULL
+            END
+        );
+        COMMIT;
+    END;
+    
+    PROCEDURE init_default_template(
+        p_tenant_id IN VARCHAR2,
+        p_user_id   IN VARCHAR2
+    ) IS
+        v_exists NUMBER;
+    BEGIN
+        SELECT COUNT(*) INTO v_exists
+        FROM contract_templates
+        WHERE tenant_id = p_tenant_id AND is_default = 1;
+        
+        IF v_exists > 0 THEN
+            RETURN;
+        END IF;
+        
+        BEGIN
+            INSERT INTO contract_templates (
+                tenant_id, template_code, template_name, language, is_default, active,
+                intro_text,
+                payment_terms_text,
+                general_terms,
+                confidentiality,
+                termination_clause,
+                dispute_resolution,
+                created_by
+            ) VALUES (
+                p_tenant_id, 'DEFAULT', 'Contrato Padrão de Prestação de Serviços', 'pt-BR', 1, 1,
+                'Pelo presente instrumento particular, as partes abaixo qualificadas têm entre si justo e contratado o seguinte:',
+                'O não pagamento nas datas avençadas implicará em multa de 2% (dois por cento) sobre o valor devido, acrescido de juros de mora de 1% (um por cento) ao mês, calculados pro rata die.',
+                'a) O CONTRATANTE declara ter ciência de todas as condições estabelecidas neste contrato.
+b) Qualquer alteração das condições aqui pactuadas somente terá validade se feita por escrito e assinada por ambas as partes.
+c) As partes elegem o presente contrato como título executivo extrajudicial, nos termos do Art. 784, III do Código de Processo Civil.',
+                'As partes se comprometem a manter sigilo sobre todas as informações confidenciais a que tiverem acesso em razão do presente contrato. Esta obrigação permanecerá em vigor por 5 (cinco) anos após o término do contrato. O descumprimento ensejará penalidades previstas na LGPD (Lei nº 13.709/2018).',
+                'O presente contrato poderá ser rescindido:
+a) Por mútuo acordo, com aviso prévio de 30 dias;
+b) Por descumprimento contratual, após notificação com prazo de 15 dias para regularização;
+c) Por força maior ou caso fortuito;
+d) Unilateralmente, com aviso de 60 dias e multa de 20% do valor remanescente.',
+                'As partes elegem o Foro da Comarca onde está situada a sede da CONTRATADA para dirimir quaisquer dúvidas ou litígios.',
+                p_user_id
+            );
+            
+            -- Note: No COMMIT here - caller manages transaction
+        EXCEPTION
+            WHEN DUP_VAL_ON_INDEX THEN
+                -- Another session already inserted the default template (race condition)
+                -- This is fine - the template exists now, so just return
+                RETURN;
+        END;
+    END;
+    
+    -- Verify integrity of generated contract
+    -- Returns: 1 = hash matches (valid)
+    --          0 = hash mismatch (tampered)
+    --         -2 = record not found
+    FUNCTION verify_integrity(p_generated_id IN NUMBER) RETURN NUMBER IS
+        v_stored_hash  VARCHAR2(64);
+        v_content      CLOB;
+    BEGIN
+        SELECT content_hash, contract_json
+        INTO v_stored_hash, v_content
+        FROM generated_contracts
+        WHERE id = p_generated_id;
+        
+        IF v_stored_hash = pkg_data_api.hash_clob_sha256(v_content) THEN
+            RETURN 1;  -- Valid: hash matches
+        ELSE
+            RETURN 0;  -- Invalid: hash mismatch (content tampered)
+        END IF;
+    EXCEPTION
+        WHEN NO_DATA_FOUND THEN
+            RETURN -2; -- Not found: record does not exist;
+    END;
+    
+    PROCEDURE get_stats(
+        p_tenant_id   IN  VARCHAR2,
+        p_total       OUT NUMBER,
+        p_today       OUT NUMBER,
+        p_month       OUT NUMBER,
+        p_unique      OUT NUMBER
+    ) IS
+    BEGIN
+        SELECT 
+            COUNT(*),
+            SUM(CASE WHEN TRUNC(generated_at) = TRUNC(SYSDATE) THEN 1 ELSE 0 END),
+            SUM(CASE WHEN generated_at >= TRUNC(SYSDATE, 'MM') THEN 1 ELSE 0 END),
+            COUNT(DISTINCT contract_id)
+        INTO p_total, p_today, p_month, p_unique
+        FROM generated_contracts
+        WHERE tenant_id = p_tenant_id;
+    END;
+
+END pkg_contract_generation;
+/
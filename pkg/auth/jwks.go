@@ -0,0 +1,202 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrUnknownKey is returned when no cached or freshly fetched key matches a token's kid
+var ErrUnknownKey = errors.New("no signing key matches the token")
+
+// maxJWKSBytes bounds the key set document
+const maxJWKSBytes = 1 << 20
+
+// JWKSConfig tunes how a JWKS caches keys
+type JWKSConfig struct {
+	TTL        time.Duration // How long fetched keys are used before a refetch; defaults to 10m
+	MinRefresh time.Duration // Minimum gap between fetches; defaults to 30s
+}
+
+// JWKS verifies RS256 tokens against a realm's JSON Web Key Set. Keys are
+// cached for TTL and refetched when a token names an unknown kid, at most
+// once per MinRefresh. When a fetch fails the cached keys keep being used.
+type JWKS struct {
+	url    string
+	client *http.Client
+	cfg    JWKSConfig
+
+	fetchMu sync.Mutex // serializes fetches
+
+	mu          sync.RWMutex
+	keys        map[string]*rsa.PublicKey
+	fetchedAt   time.Time // last successful fetch
+	attemptedAt time.Time // last fetch, successful or not
+	lastErr     error
+}
+
+// NewJWKS creates a JWKS for the key set at url. Nothing is fetched until
+// the first token is verified or Check is called.
+func NewJWKS(url string, client *http.Client, cfg JWKSConfig) *JWKS {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = 10 * time.Minute
+	}
+	if cfg.MinRefresh <= 0 {
+		cfg.MinRefresh = 30 * time.Second
+	}
+	return &JWKS{url: url, client: client, cfg: cfg}
+}
+
+// Key returns the public key for kid
+func (s *JWKS) Key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	if key, fresh := s.cached(kid); key != nil && fresh {
+		return key, nil
+	}
+
+	s.fetchMu.Lock()
+	defer s.fetchMu.Unlock()
+	// Another caller may have fetched while this one waited
+	key, fresh := s.cached(kid)
+	if key != nil && fresh {
+		return key, nil
+	}
+	if s.canFetch() {
+		_ = s.fetch(ctx)
+		key, _ = s.cached(kid)
+	}
+	if key == nil {
+		if err := s.Err(); err != nil {
+			return nil, fmt.Errorf("%w (kid %q): %w", ErrUnknownKey, kid, err)
+		}
+		return nil, fmt.Errorf("%w (kid %q)", ErrUnknownKey, kid)
+	}
+	return key, nil
+}
+
+// Check implements the readiness check: it fetches the key set if it has
+// never been fetched and reports the last fetch error, if any
+func (s *JWKS) Check(ctx context.Context) error {
+	s.mu.RLock()
+	never := s.attemptedAt.IsZero()
+	s.mu.RUnlock()
+	if never {
+		s.fetchMu.Lock()
+		defer s.fetchMu.Unlock()
+		return s.fetch(ctx)
+	}
+	return s.Err()
+}
+
+// Err returns the error of the last fetch, or nil if it succeeded
+func (s *JWKS) Err() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.lastErr
+}
+
+// cached returns the cached key for kid and whether the cache is within its TTL
+func (s *JWKS) cached(kid string) (*rsa.PublicKey, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.keys[kid], time.Since(s.fetchedAt) < s.cfg.TTL
+}
+
+func (s *JWKS) canFetch() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.attemptedAt.IsZero() || time.Since(s.attemptedAt) >= s.cfg.MinRefresh
+}
+
+// fetch replaces the cached keys with the current key set. On failure the
+// old keys are kept and the error is recorded. Callers hold fetchMu.
+func (s *JWKS) fetch(ctx context.Context) error {
+	// The fetch outlives a cancelled request so its result is still cached
+	keys, err := s.download(context.WithoutCancel(ctx))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attemptedAt = time.Now()
+	s.lastErr = err
+	if err == nil {
+		s.keys = keys
+		s.fetchedAt = s.attemptedAt
+	}
+	return err
+}
+
+// jsonWebKey is the subset of RFC 7517 fields needed for RSA signing keys
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+func (s *JWKS) download(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create JWKS request: %w", err)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("JWKS request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("JWKS request failed with status %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxJWKSBytes)).Decode(&set); err != nil {
+		return nil, fmt.Errorf("failed to decode JWKS: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, k := range set.Keys {
+		// Keycloak also publishes encryption keys, which must not verify signatures
+		if k.Kty != "RSA" || k.Use == "enc" {
+			continue
+		}
+		key, err := k.rsaKey()
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWKS key %q: %w", k.Kid, err)
+		}
+		keys[k.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS has no RSA signing keys")
+	}
+	return keys, nil
+}
+
+func (k jsonWebKey) rsaKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("modulus: %w", err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("exponent: %w", err)
+	}
+	if len(n) == 0 || len(e) == 0 || len(e) > 4 {
+		return nil, errors.New("malformed modulus or exponent")
+	}
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(new(big.Int).SetBytes(e).Int64()),
+	}, nil
+}
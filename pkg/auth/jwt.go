@@ -4,56 +4,203 @@
 package auth
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// DefaultLeeway is the clock skew tolerated when checking exp, nbf and iat
+const DefaultLeeway = 30 * time.Second
+
+// Verification errors beyond those of the jwt package
+var (
+	ErrMissingTenant   = errors.New("token has no tenant_id claim")
+	ErrInvalidAudience = errors.New("token was not issued for this client")
+)
+
 // Claims represents the claims in the JWT token.
 // This is the standard claims structure used across the application.
 type Claims struct {
-	User         string `json:"user"`
-	LoginSession string `json:"login_session"`
-	TenantID     string `json:"tenant_id"`
+	User            string `json:"user"`
+	LoginSession    string `json:"login_session"`
+	TenantID        string `json:"tenant_id"`
+	AuthorizedParty string `json:"azp,omitempty"`
+	// PreferredUsername stands in for User in tokens issued by Keycloak itself
+	PreferredUsername string `json:"preferred_username,omitempty"`
+	// Email and Name come from Keycloak's email and profile scopes
+	Email string `json:"email,omitempty"`
+	Name  string `json:"name,omitempty"`
+	// RealmAccess and ResourceAccess carry Keycloak realm and per-client roles
+	RealmAccess    *RoleSet           `json:"realm_access,omitempty"`
+	ResourceAccess map[string]RoleSet `json:"resource_access,omitempty"`
+	// ServiceClient is set by Verifier for client_credentials tokens of a
+	// registered service client
+	ServiceClient *ServiceClient `json:"-"`
 	jwt.RegisteredClaims
 }
 
+// RoleSet is a Keycloak role list
+type RoleSet struct {
+	Roles []string `json:"roles"`
+}
+
+// Roles returns the realm roles together with the roles of clientID,
+// without duplicates. A service client has exactly its registered scopes.
+func (c *Claims) Roles(clientID string) []string {
+	if c.ServiceClient != nil {
+		return c.ServiceClient.Scopes
+	}
+	var roles []string
+	if c.RealmAccess != nil {
+		roles = append(roles, c.RealmAccess.Roles...)
+	}
+	for _, role := range c.ResourceAccess[clientID].Roles {
+		if !slices.Contains(roles, role) {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
 // ParseToken parses a JWT token string without validating the signature.
 // Use this only when you need to inspect claims before validation.
 // For secure validation, use ValidateToken instead.
 func ParseToken(tokenString string) (*jwt.Token, error) {
-	return jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		// Return nil key - this is for parsing only, not validation
-		return nil, nil
-	}, jwt.WithoutClaimsValidation())
+	token, _, err := jwt.NewParser().ParseUnverified(tokenString, &Claims{})
+	return token, err
 }
 
-// ValidateToken validates a JWT token string and returns the claims if valid.
-// It verifies the signature using the provided secret and ensures the token
-// uses the expected HS256 signing method.
-func ValidateToken(tokenString, secret string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		// Verify the signing method to prevent algorithm confusion attacks
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+// VerifierConfig describes the tokens a Verifier accepts
+type VerifierConfig struct {
+	// JWKS verifies RS256 tokens signed by Keycloak. Nil disables RS256.
+	JWKS *JWKS
+	// Secret verifies HS256 tokens minted by this service or the legacy auth
+	// backend. Empty disables HS256.
+	Secret string
+	// Issuer is the required iss claim, normally the Keycloak realm URL.
+	// Empty accepts any issuer.
+	Issuer string
+	// Audience is the client ID the token must name in aud or azp. Empty
+	// accepts any audience.
+	Audience string
+	Leeway   time.Duration // Clock skew for exp, nbf and iat; defaults to DefaultLeeway
+	// Clients resolves tokens whose azp is a registered service client
+	// rather than Audience. Nil disables service accounts.
+	Clients ServiceClientStore
+}
+
+// Verifier checks token signatures and the claims that tie a token to this
+// deployment: issuer, audience, lifetime and tenant
+type Verifier struct {
+	cfg    VerifierConfig
+	parser *jwt.Parser
+}
+
+// NewVerifier creates a Verifier
+func NewVerifier(cfg VerifierConfig) *Verifier {
+	if cfg.Leeway <= 0 {
+		cfg.Leeway = DefaultLeeway
+	}
+	// Pin the signing methods to prevent algorithm confusion attacks
+	var methods []string
+	if cfg.JWKS != nil {
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+	if cfg.Secret != "" {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithLeeway(cfg.Leeway),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	return &Verifier{cfg: cfg, parser: jwt.NewParser(opts...)}
+}
+
+// Config returns the configuration the Verifier was created with
+func (v *Verifier) Config() VerifierConfig {
+	return v.cfg
+}
+
+// Verify validates a token and returns its claims. Failures wrap the jwt
+// package errors (jwt.ErrTokenExpired, jwt.ErrTokenInvalidIssuer, ...),
+// ErrUnknownKey, ErrInvalidAudience, ErrMissingTenant or ErrClientLookup so
+// callers can tell them apart. ctx bounds any JWKS fetch.
+func (v *Verifier) Verify(ctx context.Context, tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := v.parser.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		// WithValidMethods has already rejected disabled algorithms, but
+		// treats an empty list as allowing any
+		switch {
+		case token.Method.Alg() == jwt.SigningMethodHS256.Alg() && v.cfg.Secret != "":
+			return []byte(v.cfg.Secret), nil
+		case token.Method.Alg() != jwt.SigningMethodRS256.Alg() || v.cfg.JWKS == nil:
+			return nil, fmt.Errorf("%w: signing method %s is not enabled", jwt.ErrTokenSignatureInvalid, token.Method.Alg())
 		}
-		if token.Method.Alg() != "HS256" {
-			return nil, fmt.Errorf("expected HS256 signing method, got %s", token.Method.Alg())
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			return nil, fmt.Errorf("%w: token has no kid", ErrUnknownKey)
 		}
-		return []byte(secret), nil
+		return v.cfg.JWKS.Key(ctx, kid)
 	})
 	if err != nil {
 		return nil, err
 	}
-
-	claims, ok := token.Claims.(*Claims)
-	if !ok || !token.Valid {
+	if !token.Valid {
 		return nil, jwt.ErrTokenInvalidClaims
 	}
-
+	client, err := v.serviceClient(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+	if client != nil {
+		// The registration, not the token, decides who the client acts for
+		claims.ServiceClient = client
+		claims.TenantID = client.TenantID
+		claims.User = client.User()
+		return claims, nil
+	}
+	// Keycloak access tokens often carry aud "account" and name the client in azp
+	if v.cfg.Audience != "" && claims.AuthorizedParty != v.cfg.Audience && !slices.Contains(claims.Audience, v.cfg.Audience) {
+		return nil, ErrInvalidAudience
+	}
+	if claims.TenantID == "" {
+		return nil, ErrMissingTenant
+	}
+	if claims.User == "" {
+		claims.User = claims.PreferredUsername
+	}
 	return claims, nil
 }
 
+// serviceClient returns the registered service client that obtained the
+// token, or nil for tokens issued to users of this deployment's client
+func (v *Verifier) serviceClient(ctx context.Context, claims *Claims) (*ServiceClient, error) {
+	azp := claims.AuthorizedParty
+	if v.cfg.Clients == nil || azp == "" || azp == v.cfg.Audience {
+		return nil, nil
+	}
+	client, err := v.cfg.Clients.ServiceClient(ctx, azp)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrClientLookup, err)
+	}
+	return client, nil
+}
+
+// ValidateToken validates a token signed with secret, checking its lifetime
+// and tenant but not its issuer or audience. Prefer a Verifier.
+func ValidateToken(tokenString, secret string) (*Claims, error) {
+	return NewVerifier(VerifierConfig{Secret: secret}).Verify(context.Background(), tokenString)
+}
+
 // ClaimsFromToken extracts claims from an already-parsed token.
 // Returns nil if the token is nil or claims cannot be extracted.
 func ClaimsFromToken(token *jwt.Token) *Claims {
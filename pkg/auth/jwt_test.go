@@ -0,0 +1,261 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testIssuer   = "https://keycloak.example.com/realms/gprint"
+	testClientID = "gprint-api"
+	testSecret   = "0123456789abcdef0123456789abcdef"
+	testKid      = "sig-1"
+)
+
+// testKeys is an RSA signing key published by a stub JWKS endpoint
+type testKeys struct {
+	private *rsa.PrivateKey
+	jwks    *JWKS
+}
+
+func newTestKeys(t *testing.T) *testKeys {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+	set := map[string]any{"keys": []map[string]string{
+		// Encryption keys share the set but must never verify signatures
+		{"kid": "enc-1", "kty": "RSA", "use": "enc", "n": "AQAB", "e": "AQAB"},
+		{
+			"kid": testKid,
+			"kty": "RSA",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		},
+	}}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(set)
+	}))
+	t.Cleanup(srv.Close)
+	return &testKeys{private: key, jwks: NewJWKS(srv.URL, srv.Client(), JWKSConfig{})}
+}
+
+func (k *testKeys) signRS256(t *testing.T, claims jwt.Claims, kid string) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(k.private)
+	if err != nil {
+		t.Fatalf("signing RS256: %v", err)
+	}
+	return signed
+}
+
+func signHMAC(t *testing.T, method jwt.SigningMethod, claims jwt.Claims, secret []byte) string {
+	t.Helper()
+	signed, err := jwt.NewWithClaims(method, claims).SignedString(secret)
+	if err != nil {
+		t.Fatalf("signing %s: %v", method.Alg(), err)
+	}
+	return signed
+}
+
+// validClaims returns claims a Verifier configured with testIssuer and
+// testClientID accepts
+func validClaims() *Claims {
+	now := time.Now()
+	return &Claims{
+		TenantID:          "tenant-1",
+		AuthorizedParty:   testClientID,
+		PreferredUsername: "alice",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    testIssuer,
+			Subject:   "user-1",
+			Audience:  jwt.ClaimStrings{"account"},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(5 * time.Minute)),
+		},
+	}
+}
+
+func TestVerifierAcceptsValidTokens(t *testing.T) {
+	keys := newTestKeys(t)
+	v := NewVerifier(VerifierConfig{JWKS: keys.jwks, Secret: testSecret, Issuer: testIssuer, Audience: testClientID})
+
+	for name, token := range map[string]string{
+		"RS256": keys.signRS256(t, validClaims(), testKid),
+		"HS256": signHMAC(t, jwt.SigningMethodHS256, validClaims(), []byte(testSecret)),
+	} {
+		t.Run(name, func(t *testing.T) {
+			claims, err := v.Verify(context.Background(), token)
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if claims.TenantID != "tenant-1" || claims.User != "alice" {
+				t.Errorf("claims = tenant %q, user %q; want tenant-1, alice", claims.TenantID, claims.User)
+			}
+		})
+	}
+}
+
+func TestVerifierRejectsClaims(t *testing.T) {
+	keys := newTestKeys(t)
+	v := NewVerifier(VerifierConfig{JWKS: keys.jwks, Issuer: testIssuer, Audience: testClientID})
+
+	tests := []struct {
+		name   string
+		modify func(c *Claims)
+		want   error // nil means the token is accepted
+	}{
+		{"wrong issuer", func(c *Claims) { c.Issuer = "https://keycloak.example.com/realms/other" }, jwt.ErrTokenInvalidIssuer},
+		{"missing issuer", func(c *Claims) { c.Issuer = "" }, jwt.ErrTokenRequiredClaimMissing},
+		{"wrong aud and azp", func(c *Claims) { c.AuthorizedParty = "other-client" }, ErrInvalidAudience},
+		{"client named in aud only", func(c *Claims) {
+			c.AuthorizedParty = "other-client"
+			c.Audience = jwt.ClaimStrings{"account", testClientID}
+		}, nil},
+		{"expired", func(c *Claims) { c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute)) }, jwt.ErrTokenExpired},
+		{"expired within leeway", func(c *Claims) { c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-10 * time.Second)) }, nil},
+		{"missing exp", func(c *Claims) { c.ExpiresAt = nil }, jwt.ErrTokenRequiredClaimMissing},
+		{"not yet valid", func(c *Claims) { c.NotBefore = jwt.NewNumericDate(time.Now().Add(time.Minute)) }, jwt.ErrTokenNotValidYet},
+		{"issued in the future", func(c *Claims) { c.IssuedAt = jwt.NewNumericDate(time.Now().Add(time.Minute)) }, jwt.ErrTokenUsedBeforeIssued},
+		{"missing tenant", func(c *Claims) { c.TenantID = "" }, ErrMissingTenant},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := validClaims()
+			tt.modify(claims)
+			_, err := v.Verify(context.Background(), keys.signRS256(t, claims, testKid))
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("Verify: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Verify error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifierPinsAlgorithms(t *testing.T) {
+	keys := newTestKeys(t)
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: mustMarshalPKIX(t, &keys.private.PublicKey)})
+
+	rs384 := jwt.NewWithClaims(jwt.SigningMethodRS384, validClaims())
+	rs384.Header["kid"] = testKid
+	rs384Token, err := rs384.SignedString(keys.private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	noneToken, err := jwt.NewWithClaims(jwt.SigningMethodNone, validClaims()).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rsOnly := NewVerifier(VerifierConfig{JWKS: keys.jwks, Issuer: testIssuer, Audience: testClientID})
+	hsOnly := NewVerifier(VerifierConfig{Secret: testSecret, Issuer: testIssuer, Audience: testClientID})
+
+	tests := []struct {
+		name     string
+		verifier *Verifier
+		token    string
+	}{
+		// The public key is public: an HS256 token keyed with it must not pass as RS256
+		{"HS256 keyed with the RSA public key", rsOnly, signHMAC(t, jwt.SigningMethodHS256, validClaims(), publicPEM)},
+		{"HS256 without a secret configured", rsOnly, signHMAC(t, jwt.SigningMethodHS256, validClaims(), []byte(testSecret))},
+		{"RS256 without a JWKS configured", hsOnly, keys.signRS256(t, validClaims(), testKid)},
+		{"RS384", rsOnly, rs384Token},
+		{"HS512", hsOnly, signHMAC(t, jwt.SigningMethodHS512, validClaims(), []byte(testSecret))},
+		{"none", rsOnly, noneToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.verifier.Verify(context.Background(), tt.token); !errors.Is(err, jwt.ErrTokenSignatureInvalid) && !errors.Is(err, jwt.ErrTokenUnverifiable) {
+				t.Errorf("Verify error = %v, want a signature error", err)
+			}
+		})
+	}
+}
+
+func TestVerifierUnknownKey(t *testing.T) {
+	keys := newTestKeys(t)
+	v := NewVerifier(VerifierConfig{JWKS: keys.jwks, Issuer: testIssuer, Audience: testClientID})
+
+	for _, kid := range []string{"", "enc-1", "rotated-away"} {
+		if _, err := v.Verify(context.Background(), keys.signRS256(t, validClaims(), kid)); !errors.Is(err, ErrUnknownKey) {
+			t.Errorf("kid %q: Verify error = %v, want ErrUnknownKey", kid, err)
+		}
+	}
+}
+
+// stubClients is a ServiceClientStore with fixed registrations
+type stubClients map[string]*ServiceClient
+
+func (s stubClients) ServiceClient(ctx context.Context, clientID string) (*ServiceClient, error) {
+	return s[clientID], nil
+}
+
+func TestVerifierServiceClientTenant(t *testing.T) {
+	keys := newTestKeys(t)
+	clients := stubClients{"billing-sync": {ClientID: "billing-sync", TenantID: "tenant-1", Scopes: []string{"contracts:read"}}}
+	v := NewVerifier(VerifierConfig{JWKS: keys.jwks, Issuer: testIssuer, Audience: testClientID, Clients: clients})
+
+	// The token claims another tenant; the registration decides
+	claims := validClaims()
+	claims.AuthorizedParty = "billing-sync"
+	claims.TenantID = "tenant-2"
+	got, err := v.Verify(context.Background(), keys.signRS256(t, claims, testKid))
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if got.TenantID != "tenant-1" || got.User != ServiceUserPrefix+"billing-sync" {
+		t.Errorf("claims = tenant %q, user %q; want the registered tenant-1 and svc:billing-sync", got.TenantID, got.User)
+	}
+	if roles := got.Roles(testClientID); len(roles) != 1 || roles[0] != "contracts:read" {
+		t.Errorf("roles = %v, want the registered scopes", roles)
+	}
+
+	// An unregistered client falls back to the audience check
+	claims.AuthorizedParty = "unknown-client"
+	if _, err := v.Verify(context.Background(), keys.signRS256(t, claims, testKid)); !errors.Is(err, ErrInvalidAudience) {
+		t.Errorf("unregistered client: Verify error = %v, want ErrInvalidAudience", err)
+	}
+}
+
+func TestValidateToken(t *testing.T) {
+	claims := validClaims()
+	claims.Issuer = "legacy-auth"
+	if _, err := ValidateToken(signHMAC(t, jwt.SigningMethodHS256, claims, []byte(testSecret)), testSecret); err != nil {
+		t.Errorf("ValidateToken: %v", err)
+	}
+	if _, err := ValidateToken(signHMAC(t, jwt.SigningMethodHS256, claims, []byte("another secret of thirty-two chars")), testSecret); !errors.Is(err, jwt.ErrTokenSignatureInvalid) {
+		t.Errorf("wrong secret: ValidateToken error = %v, want ErrTokenSignatureInvalid", err)
+	}
+}
+
+func mustMarshalPKIX(t *testing.T, key *rsa.PublicKey) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der
+}
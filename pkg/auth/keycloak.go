@@ -140,6 +140,13 @@ func (k *KeycloakClient) logoutEndpoint() string {
 }
 
 // authorizationEndpoint returns the OAuth2 authorization endpoint URL
+func (k *KeycloakClient) discoveryEndpoint() string {
+	return fmt.Sprintf("%s/realms/%s/.well-known/openid-configuration",
+		strings.TrimSuffix(k.config.BaseURL, "/"),
+		k.config.Realm,
+	)
+}
+
 func (k *KeycloakClient) authorizationEndpoint() string {
 	return fmt.Sprintf("%s/realms/%s/protocol/openid-connect/auth",
 		strings.TrimSuffix(k.config.BaseURL, "/"),
@@ -247,6 +254,26 @@ func (k *KeycloakClient) RefreshToken(ctx context.Context, refreshToken string)
 	return k.doTokenRequest(ctx, data)
 }
 
+// ClientCredentialsToken obtains an access token for the client itself using
+// the client_credentials grant. Machine integrations use it to call the API
+// as a service account; the client must be confidential and registered in
+// API_CLIENTS. No refresh token is issued, so request a new token when the
+// current one expires.
+func (k *KeycloakClient) ClientCredentialsToken(ctx context.Context, scopes ...string) (*TokenResponse, error) {
+	if k.config.ClientSecret == "" {
+		return nil, fmt.Errorf("client credentials grant requires a client secret")
+	}
+	data := url.Values{}
+	data.Set("grant_type", "client_credentials")
+	data.Set("client_id", k.config.ClientID)
+	data.Set("client_secret", k.config.ClientSecret)
+	if len(scopes) > 0 {
+		data.Set("scope", strings.Join(scopes, " "))
+	}
+
+	return k.doTokenRequest(ctx, data)
+}
+
 // Logout invalidates the refresh token
 func (k *KeycloakClient) Logout(ctx context.Context, refreshToken string) error {
 	data := url.Values{}
@@ -383,3 +410,24 @@ func (k *KeycloakClient) IntrospectToken(ctx context.Context, token string) (map
 
 	return result, nil
 }
+
+// Ping checks that Keycloak is reachable by fetching the realm's OpenID
+// discovery document
+func (k *KeycloakClient) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, k.discoveryEndpoint(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create discovery request: %w", err)
+	}
+
+	resp, err := k.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("discovery request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("discovery failed with status %d", resp.StatusCode)
+	}
+	return nil
+}
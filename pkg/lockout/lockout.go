@@ -0,0 +1,137 @@
+// Package lockout locks out keys, such as a username and client IP, after
+// repeated failed attempts. Tracking sits behind a Tracker interface so the
+// in-memory implementation used by a single instance can be replaced by a
+// shared store (such as Redis) for multi-instance deployments.
+package lockout
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Policy decides when a key is locked out: MaxFailures failures within
+// Window lock it for Lockout. A zero MaxFailures disables lockouts.
+type Policy struct {
+	MaxFailures int
+	Window      time.Duration
+	Lockout     time.Duration
+}
+
+// Tracker records failed attempts per key
+type Tracker interface {
+	// Locked returns how long key remains locked out, or 0 when it is not
+	Locked(ctx context.Context, key string) (time.Duration, error)
+	// Fail records a failed attempt and returns the lockout it started, or 0
+	Fail(ctx context.Context, key string) (time.Duration, error)
+	// Reset forgets key's failures after a successful attempt
+	Reset(ctx context.Context, key string) error
+}
+
+// entry is the state of one key
+type entry struct {
+	failures    int
+	windowStart time.Time // first failure counted in the current window
+	lockedUntil time.Time
+}
+
+// expired reports whether the entry no longer affects anything at now
+func (e *entry) expired(now time.Time, p Policy) bool {
+	return !now.Before(e.lockedUntil) && !now.Before(e.windowStart.Add(p.Window))
+}
+
+// Memory is an in-process Tracker. Entries whose window and lockout have
+// both passed are dropped periodically.
+type Memory struct {
+	policy Policy
+
+	mu        sync.Mutex
+	entries   map[string]*entry
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// sweepInterval is how often stale entries are dropped
+const sweepInterval = time.Minute
+
+// NewMemory creates an empty in-memory Tracker enforcing policy
+func NewMemory(policy Policy) *Memory {
+	return NewMemoryWithClock(policy, time.Now)
+}
+
+// NewMemoryWithClock creates an in-memory Tracker that reads the time from
+// now. Useful for testing window and lockout expiry without sleeping.
+func NewMemoryWithClock(policy Policy, now func() time.Time) *Memory {
+	if now == nil {
+		now = time.Now
+	}
+	return &Memory{policy: policy, entries: make(map[string]*entry), now: now}
+}
+
+// Locked implements Tracker
+func (m *Memory) Locked(_ context.Context, key string) (time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[key]
+	if !ok {
+		return 0, nil
+	}
+	if remaining := e.lockedUntil.Sub(m.now()); remaining > 0 {
+		return remaining, nil
+	}
+	return 0, nil
+}
+
+// Fail implements Tracker
+func (m *Memory) Fail(_ context.Context, key string) (time.Duration, error) {
+	if m.policy.MaxFailures <= 0 {
+		return 0, nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	if now.Sub(m.lastSweep) >= sweepInterval {
+		m.sweep(now)
+	}
+
+	e, ok := m.entries[key]
+	if !ok {
+		e = &entry{}
+		m.entries[key] = e
+	}
+	if now.Before(e.lockedUntil) {
+		// Attempts are refused while locked, so this only happens when
+		// callers race; it neither extends nor restarts the lockout
+		return e.lockedUntil.Sub(now), nil
+	}
+	if e.failures == 0 || !now.Before(e.windowStart.Add(m.policy.Window)) {
+		e.failures, e.windowStart = 0, now
+	}
+	e.failures++
+	if e.failures < m.policy.MaxFailures {
+		return 0, nil
+	}
+	e.failures = 0
+	e.lockedUntil = now.Add(m.policy.Lockout)
+	return m.policy.Lockout, nil
+}
+
+// Reset implements Tracker
+func (m *Memory) Reset(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.entries, key)
+	return nil
+}
+
+// sweep drops entries whose window and lockout have passed
+func (m *Memory) sweep(now time.Time) {
+	m.lastSweep = now
+	for key, e := range m.entries {
+		if e.expired(now, m.policy) {
+			delete(m.entries, key)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package lockout
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// fakeClock is a manually advanced clock
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+var testPolicy = Policy{MaxFailures: 3, Window: 10 * time.Minute, Lockout: 15 * time.Minute}
+
+func newTestMemory(policy Policy) (*Memory, *fakeClock) {
+	clock := &fakeClock{t: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	return NewMemoryWithClock(policy, clock.now), clock
+}
+
+// fail records n failures and returns the lockout started by the last one
+func fail(t *testing.T, m *Memory, key string, n int) time.Duration {
+	t.Helper()
+	var locked time.Duration
+	for i := 0; i < n; i++ {
+		var err error
+		if locked, err = m.Fail(context.Background(), key); err != nil {
+			t.Fatalf("Fail: %v", err)
+		}
+	}
+	return locked
+}
+
+func locked(t *testing.T, m *Memory, key string) time.Duration {
+	t.Helper()
+	remaining, err := m.Locked(context.Background(), key)
+	if err != nil {
+		t.Fatalf("Locked: %v", err)
+	}
+	return remaining
+}
+
+func TestMemoryLocksAtMaxFailures(t *testing.T) {
+	m, clock := newTestMemory(testPolicy)
+
+	if got := fail(t, m, "alice|10.0.0.1", testPolicy.MaxFailures-1); got != 0 {
+		t.Fatalf("lockout after %d failures = %v, want none", testPolicy.MaxFailures-1, got)
+	}
+	if got := locked(t, m, "alice|10.0.0.1"); got != 0 {
+		t.Fatalf("Locked before MaxFailures = %v, want 0", got)
+	}
+	if got := fail(t, m, "alice|10.0.0.1", 1); got != testPolicy.Lockout {
+		t.Fatalf("lockout at MaxFailures = %v, want %v", got, testPolicy.Lockout)
+	}
+
+	clock.advance(time.Minute)
+	if got, want := locked(t, m, "alice|10.0.0.1"), testPolicy.Lockout-time.Minute; got != want {
+		t.Errorf("Locked a minute in = %v, want %v", got, want)
+	}
+	if got := locked(t, m, "alice|10.0.0.2"); got != 0 {
+		t.Errorf("another key is locked for %v", got)
+	}
+
+	// A racing failure neither extends nor restarts the lockout
+	if got, want := fail(t, m, "alice|10.0.0.1", 1), testPolicy.Lockout-time.Minute; got != want {
+		t.Errorf("failure while locked = %v, want the remaining %v", got, want)
+	}
+}
+
+func TestMemoryLockoutExpires(t *testing.T) {
+	m, clock := newTestMemory(testPolicy)
+	fail(t, m, "k", testPolicy.MaxFailures)
+
+	clock.advance(testPolicy.Lockout - time.Second)
+	if locked(t, m, "k") != time.Second {
+		t.Fatalf("Locked one second before expiry = %v, want 1s", locked(t, m, "k"))
+	}
+	clock.advance(time.Second)
+	if got := locked(t, m, "k"); got != 0 {
+		t.Fatalf("Locked after expiry = %v, want 0", got)
+	}
+
+	// The count starts over after a lockout
+	if got := fail(t, m, "k", testPolicy.MaxFailures-1); got != 0 {
+		t.Errorf("lockout %v after the previous one expired, want a fresh count", got)
+	}
+}
+
+func TestMemoryWindowExpires(t *testing.T) {
+	m, clock := newTestMemory(testPolicy)
+	fail(t, m, "k", testPolicy.MaxFailures-1)
+
+	// The window runs from the first failure, not the latest
+	clock.advance(testPolicy.Window)
+	if got := fail(t, m, "k", 1); got != 0 {
+		t.Fatalf("failure after the window = lockout %v, want a new window", got)
+	}
+	if got := fail(t, m, "k", testPolicy.MaxFailures-2); got != 0 {
+		t.Fatalf("lockout %v before MaxFailures in the new window", got)
+	}
+	if got := fail(t, m, "k", 1); got != testPolicy.Lockout {
+		t.Errorf("lockout at MaxFailures in the new window = %v, want %v", got, testPolicy.Lockout)
+	}
+}
+
+func TestMemoryReset(t *testing.T) {
+	m, _ := newTestMemory(testPolicy)
+	fail(t, m, "k", testPolicy.MaxFailures-1)
+	if err := m.Reset(context.Background(), "k"); err != nil {
+		t.Fatalf("Reset: %v", err)
+	}
+	if got := fail(t, m, "k", testPolicy.MaxFailures-1); got != 0 {
+		t.Errorf("lockout %v after Reset, want a fresh count", got)
+	}
+}
+
+func TestMemoryDisabled(t *testing.T) {
+	m, _ := newTestMemory(Policy{Window: time.Minute, Lockout: time.Minute})
+	if got := fail(t, m, "k", 100); got != 0 {
+		t.Errorf("lockout %v with MaxFailures 0, want none", got)
+	}
+}
+
+func TestMemorySweepsStaleEntries(t *testing.T) {
+	m, clock := newTestMemory(testPolicy)
+	fail(t, m, "locked", testPolicy.MaxFailures)
+	fail(t, m, "failed-once", 1)
+
+	// Past the window but inside the lockout: only the locked key stays
+	clock.advance(testPolicy.Window)
+	fail(t, m, "trigger", 1)
+	if _, ok := m.entries["failed-once"]; ok {
+		t.Error("an entry past its window was not swept")
+	}
+	if _, ok := m.entries["locked"]; !ok {
+		t.Fatal("a locked entry was swept")
+	}
+
+	clock.advance(testPolicy.Lockout)
+	fail(t, m, "trigger", 1)
+	if _, ok := m.entries["locked"]; ok {
+		t.Error("an entry past its lockout was not swept")
+	}
+}
@@ -0,0 +1,64 @@
+// Package qrcode renders short payloads (such as verification URLs) as QR
+// codes in inline SVG, so printed documents can embed them without image
+// files. Encoding is done by github.com/skip2/go-qrcode at error correction
+// level M.
+package qrcode
+
+import (
+	"fmt"
+	"strings"
+
+	skip2 "github.com/skip2/go-qrcode"
+)
+
+// quietZone is the number of light modules required around the symbol
+const quietZone = 4
+
+// Code is an encoded QR symbol
+type Code struct {
+	Size    int // modules per side, excluding the quiet zone
+	modules [][]bool
+}
+
+// Encode encodes data at error correction level M in the smallest version
+// that fits it
+func Encode(data string) (*Code, error) {
+	q, err := skip2.New(data, skip2.Medium)
+	if err != nil {
+		return nil, fmt.Errorf("qrcode: %w", err)
+	}
+	q.DisableBorder = true
+	modules := q.Bitmap()
+	return &Code{Size: len(modules), modules: modules}, nil
+}
+
+// Dark reports whether the module at column x, row y is dark
+func (c *Code) Dark(x, y int) bool {
+	if x < 0 || y < 0 || x >= c.Size || y >= c.Size {
+		return false
+	}
+	return c.modules[y][x]
+}
+
+// SVG renders the code as a standalone SVG element, scale pixels per module
+func (c *Code) SVG(scale int) string {
+	if scale < 1 {
+		scale = 1
+	}
+	dim := c.Size + 2*quietZone
+
+	var path strings.Builder
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.modules[y][x] {
+				fmt.Fprintf(&path, "M%d,%dh1v1h-1z", x+quietZone, y+quietZone)
+			}
+		}
+	}
+
+	return fmt.Sprintf(
+		`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`+
+			`<rect width="100%%" height="100%%" fill="#fff"/><path d="%s" fill="#000"/></svg>`,
+		dim*scale, dim*scale, dim, dim, path.String(),
+	)
+}
@@ -0,0 +1,93 @@
+package qrcode
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestEncodeSize(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		size int
+	}{
+		{"version 1", "https://x.io", 21},
+		{"verification URL", "https://gprint.example.com/api/v1/public/verify/0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef", 41},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := Encode(tt.data)
+			if err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+			if c.Size != tt.size {
+				t.Errorf("Size = %d, want %d", c.Size, tt.size)
+			}
+		})
+	}
+}
+
+// TestEncodeFinderPatterns checks the three 7x7 finder patterns are in the
+// corners, with no quiet zone in the module grid
+func TestEncodeFinderPatterns(t *testing.T) {
+	c, err := Encode("https://x.io")
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	corners := [][2]int{{0, 0}, {c.Size - 7, 0}, {0, c.Size - 7}}
+	for _, corner := range corners {
+		for y := 0; y < 7; y++ {
+			for x := 0; x < 7; x++ {
+				ring := max(abs(x-3), abs(y-3))
+				want := ring != 2 // dark outer ring and 3x3 centre, light ring between
+				if got := c.Dark(corner[0]+x, corner[1]+y); got != want {
+					t.Fatalf("finder at %v: module (%d,%d) dark = %v, want %v", corner, x, y, got, want)
+				}
+			}
+		}
+	}
+	if c.Dark(-1, 0) || c.Dark(0, c.Size) {
+		t.Error("modules outside the symbol should be light")
+	}
+}
+
+func TestEncodeTooLong(t *testing.T) {
+	if _, err := Encode(strings.Repeat("x", 3000)); err == nil {
+		t.Fatal("expected an error for a payload over the version 40 capacity")
+	}
+}
+
+func TestSVG(t *testing.T) {
+	c, err := Encode("https://x.io")
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	svg := c.SVG(2)
+	dim := c.Size + 2*quietZone
+	if want := fmt.Sprintf(`width="%d" height="%d" viewBox="0 0 %d %d"`, dim*2, dim*2, dim, dim); !strings.Contains(svg, want) {
+		t.Errorf("SVG missing %q:\n%s", want, svg)
+	}
+	// The top-left finder's first module sits just inside the quiet zone
+	if !strings.Contains(svg, fmt.Sprintf("M%d,%dh1v1h-1z", quietZone, quietZone)) {
+		t.Error("SVG does not offset modules by the quiet zone")
+	}
+	dark := 0
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.Dark(x, y) {
+				dark++
+			}
+		}
+	}
+	if got := strings.Count(svg, "h1v1h-1z"); got != dark {
+		t.Errorf("SVG draws %d modules, want %d", got, dark)
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
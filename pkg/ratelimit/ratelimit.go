@@ -0,0 +1,96 @@
+// Package ratelimit implements token bucket rate limiting behind a Limiter
+// interface, so the in-memory implementation used by a single instance can be
+// replaced by a shared store (such as Redis) for multi-instance deployments.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit is a token bucket: Burst requests at once, refilled at Rate per second
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Limiter decides whether a request identified by key may proceed. When it
+// may not, retryAfter says how long until a token is available.
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit Limit) (ok bool, retryAfter time.Duration, err error)
+}
+
+// bucket is the state of one key
+type bucket struct {
+	tokens float64
+	last   time.Time
+	full   time.Time // when the bucket will have refilled completely
+}
+
+// Memory is an in-process Limiter. Buckets that have refilled completely are
+// indistinguishable from new ones and are dropped periodically.
+type Memory struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// sweepInterval is how often idle buckets are dropped
+const sweepInterval = time.Minute
+
+// NewMemory creates an empty in-memory Limiter
+func NewMemory() *Memory {
+	return &Memory{buckets: make(map[string]*bucket), now: time.Now}
+}
+
+// Allow implements Limiter
+func (m *Memory) Allow(_ context.Context, key string, limit Limit) (bool, time.Duration, error) {
+	if limit.Rate <= 0 || limit.Burst <= 0 {
+		return true, 0, nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	if now.Sub(m.lastSweep) >= sweepInterval {
+		m.sweep(now)
+	}
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), last: now}
+		m.buckets[key] = b
+	} else {
+		b.tokens = math.Min(float64(limit.Burst), b.tokens+now.Sub(b.last).Seconds()*limit.Rate)
+		b.last = now
+	}
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	b.full = now.Add(refillTime(float64(limit.Burst)-b.tokens, limit.Rate))
+	if allowed {
+		return true, 0, nil
+	}
+	return false, refillTime(1-b.tokens, limit.Rate), nil
+}
+
+// refillTime is how long it takes to gain tokens at rate per second
+func refillTime(tokens, rate float64) time.Duration {
+	return time.Duration(tokens / rate * float64(time.Second))
+}
+
+// sweep drops buckets that have refilled completely
+func (m *Memory) sweep(now time.Time) {
+	m.lastSweep = now
+	for key, b := range m.buckets {
+		if now.After(b.full) {
+			delete(m.buckets, key)
+		}
+	}
+}
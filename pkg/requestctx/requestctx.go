@@ -0,0 +1,96 @@
+// Package requestctx stores request-scoped values in a context.Context under
+// unexported typed keys, so values set by the HTTP middleware are found by
+// every layer that reads them and cannot collide with other packages' keys.
+package requestctx
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/zlovtnik/gprint/pkg/auth"
+)
+
+type (
+	traceIDKey  struct{}
+	tenantIDKey struct{}
+	userKey     struct{}
+	claimsKey   struct{}
+	rolesKey    struct{}
+	loggerKey   struct{}
+)
+
+// WithTraceID returns a context carrying the trace ID, which is the request ID
+// echoed to clients in X-Request-ID
+func WithTraceID(ctx context.Context, traceID string) context.Context {
+	return context.WithValue(ctx, traceIDKey{}, traceID)
+}
+
+// TraceID returns the trace ID, or "" when none is set
+func TraceID(ctx context.Context) string {
+	v, _ := ctx.Value(traceIDKey{}).(string)
+	return v
+}
+
+// WithTenantID returns a context carrying the authenticated tenant ID
+func WithTenantID(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantIDKey{}, tenantID)
+}
+
+// TenantID returns the tenant ID, or "" when none is set
+func TenantID(ctx context.Context) string {
+	v, _ := ctx.Value(tenantIDKey{}).(string)
+	return v
+}
+
+// WithUser returns a context carrying the authenticated user
+func WithUser(ctx context.Context, user string) context.Context {
+	return context.WithValue(ctx, userKey{}, user)
+}
+
+// User returns the authenticated user, or "" when none is set
+func User(ctx context.Context) string {
+	v, _ := ctx.Value(userKey{}).(string)
+	return v
+}
+
+// WithClaims returns a context carrying the validated token claims
+func WithClaims(ctx context.Context, claims *auth.Claims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+// Claims returns the token claims, or nil when none are set
+func Claims(ctx context.Context) *auth.Claims {
+	v, _ := ctx.Value(claimsKey{}).(*auth.Claims)
+	return v
+}
+
+// WithRoles returns a context carrying the authenticated user's roles
+func WithRoles(ctx context.Context, roles []string) context.Context {
+	return context.WithValue(ctx, rolesKey{}, roles)
+}
+
+// Roles returns the user's roles, or nil when none are set
+func Roles(ctx context.Context) []string {
+	v, _ := ctx.Value(rolesKey{}).([]string)
+	return v
+}
+
+// WithLogger returns a context carrying the request-scoped logger
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// Logger returns the request-scoped logger, which carries the trace ID and,
+// once authenticated, the tenant ID. Falls back to slog.Default() so callers
+// outside a request, such as background jobs, can use it unconditionally.
+func Logger(ctx context.Context) *slog.Logger {
+	return LoggerOr(ctx, slog.Default())
+}
+
+// LoggerOr returns the request-scoped logger, or fallback when none is set
+func LoggerOr(ctx context.Context, fallback *slog.Logger) *slog.Logger {
+	if v, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return v
+	}
+	return fallback
+}